@@ -109,6 +109,18 @@ func StartAPI(ctx context.Context, container *infra.ContainerDI) {
 	zonasRisco.PUT("/delete/:id", container.HandlerZonasRisco.DeleteZonaRiscoHandler)
 	zonasRisco.GET("/list/all/:id", container.HandlerZonasRisco.GetAllZonasRiscoHandler)
 	zonasRisco.GET("/list/:id", container.HandlerZonasRisco.GetZonaRiscoByIdHandler)
+	zonasRisco.POST("/import", container.HandlerZonasRisco.ImportZonasRiscoHandler, _midlleware.CheckAuthorization)
+	zonasRisco.GET("/export", container.HandlerZonasRisco.ExportZonasRiscoHandler, _midlleware.CheckAuthorization)
+
+	freeFlow := e.Group("/free-flow", _midlleware.CheckUserAuthorization)
+	freeFlow.POST("/register", container.HandlerFreeFlow.RegisterPassagesHandler)
+	freeFlow.GET("/pending", container.HandlerFreeFlow.GetPendingPassagesHandler)
+	freeFlow.PUT("/paid/:id", container.HandlerFreeFlow.MarkPassagePaidHandler)
+	e.GET("/free-flow/pending-simpplify", container.HandlerFreeFlow.GetPendingPassagesHandler, _midlleware.CheckAuthorization)
+
+	fuelPrice := e.Group("/fuel-price", _midlleware.CheckUserAuthorization)
+	fuelPrice.GET("/latest", container.HandlerFuelPrice.GetLatestPricesHandler)
+	fuelPrice.POST("/import", container.HandlerFuelPrice.ImportPricesHandler)
 
 	e.POST("/recover-password", container.UserHandler.RecoverPassword)
 	e.PUT("/recover-password/confirm", container.UserHandler.ConfirmRecoverPassword, _midlleware.CheckUserAuthorization)
@@ -137,6 +149,17 @@ func StartAPI(ctx context.Context, container *infra.ContainerDI) {
 	route.GET("/favorite/list", container.HandlerNewRoutes.GetFavoriteRouteHandler)
 	route.PUT("/favorite/remove/:id", container.HandlerNewRoutes.RemoveFavoriteRouteHandler)
 	route.POST("/simple", container.HandlerNewRoutes.GetSimpleRoute)
+	route.POST("/fleet-plan", container.HandlerNewRoutes.PlanFleetHandler)
+	route.POST("/isochrone", container.HandlerNewRoutes.CalculateIsochronesHandler)
+	route.POST("/matrix", container.HandlerNewRoutes.CalculateMatrixHandler)
+	route.POST("/tag-analysis", container.HandlerNewRoutes.AnalyzeTagsHandler)
+	route.POST("/refuel-plan", container.HandlerNewRoutes.PlanRefuelHandler)
+	route.GET("/export/:source/:id", container.HandlerNewRoutes.ExportRouteHandler)
+	route.POST("/rotograma", container.HandlerNewRoutes.GenerateRotogramaHandler)
+	route.GET("/rotograma/:id", container.HandlerNewRoutes.GetRotogramaHandler)
+	route.POST("/reprice/:source/:id", container.HandlerNewRoutes.RepriceRouteHandler)
+	route.GET("/versions/:source/:id", container.HandlerNewRoutes.GetRouteVersionsHandler)
+	route.GET("/emissions/report", container.HandlerNewRoutes.GetEmissionsReportHandler)
 
 	chat := e.Group("/chat", _midlleware.CheckUserAuthorization)
 	chat.POST("/create-room", container.WsHandler.CreateChatRoom)
@@ -147,10 +170,16 @@ func StartAPI(ctx context.Context, container *infra.ContainerDI) {
 	// simpplify
 	e.POST("/check-route-tolls-simpplify", container.HandlerNewRoutes.CalculateRoutes, _midlleware.CheckAuthorization)
 	e.POST("/check-route-tolls-simpplify-cep", container.HandlerNewRoutes.CalculateRoutesWithCEP, _midlleware.CheckAuthorization)
+	e.GET("/route/emissions/report-simpplify", container.HandlerNewRoutes.GetEmissionsReportHandler, _midlleware.CheckAuthorization)
+	e.POST("/route/reprice-simpplify/:source/:id", container.HandlerNewRoutes.RepriceRouteHandler, _midlleware.CheckAuthorization)
+	e.GET("/route/versions-simpplify/:source/:id", container.HandlerNewRoutes.GetRouteVersionsHandler, _midlleware.CheckAuthorization)
 	e.POST("/route-cep", container.HandlerNewRoutes.CalculateRoutesCEP)
 	e.POST("/v2/route-cep", container.HandlerNewRoutes.CalculateRoutesCEPV2)
 	e.POST("/route-cep-avoidance", container.HandlerNewRoutes.CalculateDistancesBetweenPointsWithRiskAvoidanceHandler)
 	e.POST("/route-coordinate-avoidance", container.HandlerNewRoutes.CalculateDistancesBetweenPointsWithRiskAvoidanceFromCoordinatesHandler)
+	e.POST("/route-cep-simpplify", container.HandlerNewRoutes.CalculateRoutesCEP, _midlleware.CheckAuthorization)
+	e.POST("/route-cep-avoidance-simpplify", container.HandlerNewRoutes.CalculateDistancesBetweenPointsWithRiskAvoidanceHandler, _midlleware.CheckAuthorization)
+	e.POST("/route-coordinate-avoidance-simpplify", container.HandlerNewRoutes.CalculateDistancesBetweenPointsWithRiskAvoidanceFromCoordinatesHandler, _midlleware.CheckAuthorization)
 	e.POST("/nearby-location", container.HandlerNewRoutes.CalculateDistancesFromOrigin)
 
 	// easyfrete
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS road_restrictions;
@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS road_restrictions (
+  id          BIGSERIAL PRIMARY KEY,
+  name        VARCHAR(255) NOT NULL,
+  type        VARCHAR(50)  NOT NULL,
+  road        VARCHAR(100) NULL,
+  city        VARCHAR(100) NULL,
+  state       VARCHAR(2)   NULL,
+  lat         FLOAT NOT NULL,
+  lng         FLOAT NOT NULL,
+  radius      BIGINT NOT NULL DEFAULT 50,
+  max_height  FLOAT NULL,
+  max_weight  FLOAT NULL,
+  max_length  FLOAT NULL,
+  status      BOOLEAN NOT NULL DEFAULT TRUE,
+  created_at  TIMESTAMP NOT NULL DEFAULT now(),
+  updated_at  TIMESTAMP NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_road_restrictions_lat_lng ON road_restrictions (lat, lng);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS toll_tag_discounts;
+DROP TABLE IF EXISTS toll_tariffs;
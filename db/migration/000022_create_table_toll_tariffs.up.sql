@@ -0,0 +1,31 @@
+CREATE TABLE IF NOT EXISTS toll_tariffs (
+  id              BIGSERIAL PRIMARY KEY,
+  toll_id         BIGINT NOT NULL,
+  category        INT    NOT NULL,
+  amount          FLOAT  NOT NULL,
+  effective_from  DATE   NOT NULL,
+  effective_to    DATE   NULL,
+  source          VARCHAR(100) NULL,
+  created_at      TIMESTAMP NOT NULL DEFAULT now(),
+  updated_at      TIMESTAMP NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_toll_tariffs_toll_category ON toll_tariffs (toll_id, category, effective_from DESC);
+
+CREATE TABLE IF NOT EXISTS toll_tag_discounts (
+  id                BIGSERIAL PRIMARY KEY,
+  toll_tag_id       BIGINT NOT NULL REFERENCES toll_tags (id),
+  concessionaria    VARCHAR(50) NULL,
+  category          INT   NULL,
+  discount_percent  FLOAT NOT NULL,
+  effective_from    DATE  NOT NULL,
+  effective_to      DATE  NULL,
+  created_at        TIMESTAMP NOT NULL DEFAULT now(),
+  updated_at        TIMESTAMP NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_toll_tag_discounts_tag ON toll_tag_discounts (toll_tag_id);
+
+-- Regra geral equivalente ao desconto fixo de 5% usado até aqui; regras por concessionária/categoria têm prioridade
+INSERT INTO toll_tag_discounts (toll_tag_id, discount_percent, effective_from)
+SELECT id, 5, DATE '2025-01-01' FROM toll_tags;
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS free_flow_passages;
@@ -0,0 +1,26 @@
+CREATE TABLE IF NOT EXISTS free_flow_passages (
+  id                BIGSERIAL PRIMARY KEY,
+  user_id           BIGINT NOT NULL REFERENCES users (id),
+  organization_id   BIGINT NULL,
+  advertisement_id  BIGINT NULL REFERENCES advertisement (id),
+  route_hist_id     BIGINT NOT NULL REFERENCES route_hist (id),
+  route_index       INT    NOT NULL,
+  toll_id           BIGINT NOT NULL,
+  toll_name         VARCHAR(255) NOT NULL,
+  concessionaria    VARCHAR(100) NOT NULL,
+  road              VARCHAR(50)  NOT NULL DEFAULT '',
+  payment_link      VARCHAR(255) NOT NULL DEFAULT '',
+  amount            FLOAT  NOT NULL,
+  passed_at         TIMESTAMPTZ NOT NULL,
+  due_at            TIMESTAMPTZ NOT NULL,
+  status            VARCHAR(20) NOT NULL DEFAULT 'pending',
+  paid_at           TIMESTAMPTZ NULL,
+  reminders_sent    INT NOT NULL DEFAULT 0,
+  last_reminder_at  TIMESTAMPTZ NULL,
+  created_at        TIMESTAMP NOT NULL DEFAULT now(),
+  updated_at        TIMESTAMP NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_free_flow_passages_user ON free_flow_passages (user_id, status, due_at);
+CREATE INDEX IF NOT EXISTS idx_free_flow_passages_organization ON free_flow_passages (organization_id, status, due_at);
+CREATE INDEX IF NOT EXISTS idx_free_flow_passages_advertisement ON free_flow_passages (advertisement_id);
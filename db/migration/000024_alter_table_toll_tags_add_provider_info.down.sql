@@ -0,0 +1,2 @@
+ALTER TABLE toll_tags DROP COLUMN IF EXISTS monthly_fee;
+ALTER TABLE toll_tags DROP COLUMN IF EXISTS image_url;
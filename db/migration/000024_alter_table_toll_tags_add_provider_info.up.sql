@@ -0,0 +1,17 @@
+ALTER TABLE toll_tags ADD COLUMN IF NOT EXISTS image_url VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE toll_tags ADD COLUMN IF NOT EXISTS monthly_fee FLOAT NOT NULL DEFAULT 0;
+
+-- Imagens que ficavam fixas no código
+UPDATE toll_tags SET image_url = CASE name
+    WHEN 'veloe' THEN 'https://tags-tolls.s3.us-east-1.amazonaws.com/veloe.png'
+    WHEN 'semParar' THEN 'https://tags-tolls.s3.us-east-1.amazonaws.com/semparar.png'
+    WHEN 'moveMais' THEN 'https://tags-tolls.s3.us-east-1.amazonaws.com/moveMais.png'
+    WHEN 'greenPass' THEN 'https://tags-tolls.s3.us-east-1.amazonaws.com/greenpass.png'
+    WHEN 'ecotaggy' THEN 'https://tags-tolls.s3.us-east-1.amazonaws.com/ecotaggy.png'
+    WHEN 'autoExpresso' THEN 'https://tags-tolls.s3.us-east-1.amazonaws.com/auto-expresso.png'
+    WHEN 'c6Taggy' THEN 'https://tags-tolls.s3.us-east-1.amazonaws.com/c6-tag.png'
+    WHEN 'dBTrans' THEN 'https://tags-tolls.s3.us-east-1.amazonaws.com/dbTrans.png'
+    WHEN 'taggy' THEN 'https://tags-tolls.s3.us-east-1.amazonaws.com/taggy.png'
+    WHEN 'conectCar' THEN 'https://tags-tolls.s3.us-east-1.amazonaws.com/conectcar.png'
+    ELSE image_url
+END;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS fuel_prices;
+DROP TABLE IF EXISTS fuel_price_imports;
@@ -0,0 +1,27 @@
+CREATE TABLE IF NOT EXISTS fuel_price_imports (
+  id             BIGSERIAL PRIMARY KEY,
+  file_name      VARCHAR(255) NOT NULL,
+  checksum       VARCHAR(64)  NOT NULL UNIQUE,
+  rows_imported  INT NOT NULL DEFAULT 0,
+  created_at     TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS fuel_prices (
+  id                 BIGSERIAL PRIMARY KEY,
+  import_id          BIGINT NULL REFERENCES fuel_price_imports (id) ON DELETE SET NULL,
+  product            VARCHAR(20)  NOT NULL,
+  state              VARCHAR(2)   NOT NULL,
+  municipio          VARCHAR(100) NOT NULL,
+  municipio_key      VARCHAR(100) NOT NULL,
+  average_price      FLOAT NOT NULL,
+  min_price          FLOAT NOT NULL DEFAULT 0,
+  max_price          FLOAT NOT NULL DEFAULT 0,
+  stations_surveyed  INT NOT NULL DEFAULT 0,
+  survey_start       DATE NOT NULL,
+  survey_end         DATE NOT NULL,
+  created_at         TIMESTAMP NOT NULL DEFAULT now(),
+  updated_at         TIMESTAMP NOT NULL DEFAULT now(),
+  CONSTRAINT fuel_prices_product_state_municipio_week_key UNIQUE (product, state, municipio_key, survey_end)
+);
+
+CREATE INDEX IF NOT EXISTS idx_fuel_prices_latest ON fuel_prices (product, municipio_key, survey_end DESC);
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS route_versions (
+  id           BIGSERIAL PRIMARY KEY,
+  source       VARCHAR(20) NOT NULL,
+  route_id     BIGINT NOT NULL,
+  version      INT NOT NULL,
+  response     JSONB NOT NULL,
+  diff         JSONB NOT NULL,
+  material     BOOLEAN NOT NULL DEFAULT false,
+  notified_at  TIMESTAMP NULL,
+  created_at   TIMESTAMP NOT NULL DEFAULT now(),
+  CONSTRAINT route_versions_source_route_version_key UNIQUE (source, route_id, version)
+);
@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS route_emissions (
+  id               BIGSERIAL PRIMARY KEY,
+  organization_id  BIGINT NULL,
+  user_id          BIGINT NULL,
+  route_hist_id    BIGINT NULL,
+  origin           TEXT NOT NULL,
+  destination      TEXT NOT NULL,
+  vehicle_type     VARCHAR(20) NOT NULL,
+  fuel             VARCHAR(20) NOT NULL,
+  distance_km      FLOAT NOT NULL,
+  km_city          FLOAT NOT NULL,
+  km_hwy           FLOAT NOT NULL,
+  fuel_liters      FLOAT NOT NULL,
+  co2e_kg          FLOAT NOT NULL,
+  co2e_wtw_kg      FLOAT NOT NULL,
+  biogenic_co2_kg  FLOAT NOT NULL,
+  created_at       TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_route_emissions_organization ON route_emissions (organization_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_route_emissions_user ON route_emissions (user_id, created_at);
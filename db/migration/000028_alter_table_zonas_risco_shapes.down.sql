@@ -0,0 +1,4 @@
+ALTER TABLE zonas_risco
+    DROP COLUMN IF EXISTS buffer_meters,
+    DROP COLUMN IF EXISTS geometry,
+    DROP COLUMN IF EXISTS shape;
@@ -0,0 +1,4 @@
+ALTER TABLE zonas_risco
+    ADD COLUMN IF NOT EXISTS shape VARCHAR(20) NOT NULL DEFAULT 'circle',
+    ADD COLUMN IF NOT EXISTS geometry JSONB NULL,
+    ADD COLUMN IF NOT EXISTS buffer_meters BIGINT NOT NULL DEFAULT 0;
@@ -0,0 +1,4 @@
+ALTER TABLE zonas_risco
+    DROP COLUMN IF EXISTS expires_at,
+    DROP COLUMN IF EXISTS active_windows,
+    DROP COLUMN IF EXISTS severity;
@@ -0,0 +1,4 @@
+ALTER TABLE zonas_risco
+    ADD COLUMN IF NOT EXISTS severity BIGINT NOT NULL DEFAULT 3,
+    ADD COLUMN IF NOT EXISTS active_windows JSONB NULL,
+    ADD COLUMN IF NOT EXISTS expires_at TIMESTAMPTZ NULL;
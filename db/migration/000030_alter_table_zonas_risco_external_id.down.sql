@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_zonas_risco_org_external_id;
+
+ALTER TABLE zonas_risco
+    DROP COLUMN IF EXISTS external_id;
@@ -0,0 +1,5 @@
+ALTER TABLE zonas_risco
+    ADD COLUMN IF NOT EXISTS external_id VARCHAR(100) NULL;
+
+-- external_id identifica a zona ativa da organização na reimportação
+CREATE UNIQUE INDEX IF NOT EXISTS idx_zonas_risco_org_external_id ON zonas_risco (organization_id, external_id) WHERE status;
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_free_flow_passages_route_toll;
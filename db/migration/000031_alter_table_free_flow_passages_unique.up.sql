@@ -0,0 +1,8 @@
+DELETE FROM free_flow_passages f
+    USING free_flow_passages d
+WHERE f.user_id = d.user_id
+  AND f.route_hist_id = d.route_hist_id
+  AND f.toll_id = d.toll_id
+  AND f.id > d.id;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_free_flow_passages_route_toll ON free_flow_passages (user_id, route_hist_id, toll_id);
@@ -0,0 +1,8 @@
+DROP TRIGGER IF EXISTS trg_gas_station_updated_at ON gas_station;
+DROP TRIGGER IF EXISTS trg_balanca_updated_at ON balanca;
+DROP TRIGGER IF EXISTS trg_tolls_updated_at ON tolls;
+DROP FUNCTION IF EXISTS set_updated_at();
+
+ALTER TABLE gas_station DROP COLUMN IF EXISTS updated_at;
+ALTER TABLE balanca DROP COLUMN IF EXISTS updated_at;
+ALTER TABLE tolls DROP COLUMN IF EXISTS updated_at;
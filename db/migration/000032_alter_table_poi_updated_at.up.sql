@@ -0,0 +1,21 @@
+ALTER TABLE tolls ADD COLUMN IF NOT EXISTS updated_at TIMESTAMP NOT NULL DEFAULT now();
+ALTER TABLE balanca ADD COLUMN IF NOT EXISTS updated_at TIMESTAMP NOT NULL DEFAULT now();
+ALTER TABLE gas_station ADD COLUMN IF NOT EXISTS updated_at TIMESTAMP NOT NULL DEFAULT now();
+
+-- Essas tabelas são atualizadas por cargas fora da aplicação; o gatilho mantém updated_at em dia
+-- para que o índice de POIs perceba a mudança
+CREATE OR REPLACE FUNCTION set_updated_at() RETURNS TRIGGER AS $$
+BEGIN
+    NEW.updated_at = now();
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_tolls_updated_at ON tolls;
+CREATE TRIGGER trg_tolls_updated_at BEFORE UPDATE ON tolls FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+
+DROP TRIGGER IF EXISTS trg_balanca_updated_at ON balanca;
+CREATE TRIGGER trg_balanca_updated_at BEFORE UPDATE ON balanca FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+
+DROP TRIGGER IF EXISTS trg_gas_station_updated_at ON gas_station;
+CREATE TRIGGER trg_gas_station_updated_at BEFORE UPDATE ON gas_station FOR EACH ROW EXECUTE FUNCTION set_updated_at();
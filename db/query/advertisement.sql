@@ -40,6 +40,19 @@ FROM public.advertisement
 WHERE id=$1 AND
     status=true;
 
+-- name: GetAdvertisementByIdForUser :one
+SELECT a.*
+FROM public.advertisement a
+WHERE a.id = $1
+  AND a.status = true
+  AND (a.user_id = $2 OR EXISTS (
+      SELECT 1
+      FROM appointments ap
+      WHERE ap.advertisement_id = a.id
+        AND ap.interested_user_id = $2
+        AND ap.status = true
+  ));
+
 -- name: GetAllAdvertisementUsers :many
 SELECT a.id, a.user_id, u.name as user_name, u.created_at as active_there, u.city as user_city, u.state as user_state, u.phone as user_phone, u.email as user_email, u.profile_picture as user_profile_picture,
        a.destination, a.origin, destination_lat, destination_lng, origin_lat, origin_lng, distance, pickup_date, delivery_date, expiration_date, title, cargo_type, cargo_species, cargo_weight, vehicles_accepted, trailer, requires_tarp, tracking, agency, description, payment_type, advance, toll, situation, price, a.created_at, created_who, a.updated_at, updated_who,
@@ -14,6 +14,13 @@ SELECT *
 FROM public.attachments
 WHERE user_id=$1 AND type=$2 AND status=true;
 
+-- name: GetLatestAttachmentByDescription :one
+SELECT *
+FROM public.attachments
+WHERE user_id=$1 AND type=$2 AND description=$3 AND status=true
+ORDER BY created_at DESC
+LIMIT 1;
+
 -- name: UpdateAttachmentLogicDelete :exec
 UPDATE public.attachments
 SET status=false, updated_at=now()
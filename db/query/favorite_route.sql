@@ -15,3 +15,25 @@ SELECT *
 FROM public.favorite_route
 WHERE id_user = $1 AND
       status=true;
+
+-- name: GetFavoriteRouteByID :one
+SELECT *
+FROM public.favorite_route
+WHERE id = $1 AND
+      id_user = $2 AND
+      status=true;
+
+-- name: GetFavoriteRoutesForRepricing :many
+SELECT f.id, f.id_user, f.origin, f.destination, f.waypoints, f.response,
+       u.name AS user_name, u.email AS user_email
+FROM public.favorite_route f
+         INNER JOIN users u ON u.id = f.id_user
+WHERE f.status = true AND
+      f.id > $1
+ORDER BY f.id
+LIMIT $2;
+
+-- name: UpdateFavoriteRouteResponse :exec
+UPDATE public.favorite_route
+SET response=$2, updated_at=now()
+WHERE id = $1;
@@ -0,0 +1,61 @@
+-- name: CreateFreeFlowPassage :one
+INSERT INTO free_flow_passages (user_id, organization_id, advertisement_id, route_hist_id, route_index, toll_id, toll_name, concessionaria, road, payment_link, amount, passed_at, due_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+ON CONFLICT (user_id, route_hist_id, toll_id) DO NOTHING
+RETURNING *;
+
+-- name: CountFreeFlowPassagesByAdvertisement :one
+SELECT COUNT(*)
+FROM free_flow_passages
+WHERE advertisement_id = $1;
+
+-- name: GetFreeFlowPassagesByRouteHist :many
+SELECT *
+FROM free_flow_passages
+WHERE user_id = $1
+  AND route_hist_id = $2
+ORDER BY passed_at;
+
+-- name: GetPendingFreeFlowPassagesByUser :many
+SELECT *
+FROM free_flow_passages
+WHERE user_id = $1
+  AND status = 'pending'
+ORDER BY due_at;
+
+-- name: GetPendingFreeFlowPassagesByOrganization :many
+SELECT *
+FROM free_flow_passages
+WHERE organization_id = $1
+  AND status = 'pending'
+ORDER BY due_at;
+
+-- name: MarkFreeFlowPassagePaid :one
+UPDATE free_flow_passages
+SET status = 'paid', paid_at = now(), updated_at = now()
+WHERE id = $1
+  AND user_id = $2
+  AND status = 'pending'
+RETURNING *;
+
+-- name: GetFreeFlowPassagesDueForReminder :many
+SELECT f.id, f.user_id, f.toll_name, f.concessionaria, f.road, f.payment_link, f.amount, f.passed_at, f.due_at, f.reminders_sent,
+       u.name AS user_name, u.email AS user_email
+FROM free_flow_passages f
+         INNER JOIN users u ON u.id = f.user_id
+WHERE f.status = 'pending'
+  AND f.due_at > now()
+  AND f.due_at <= $1
+ORDER BY f.user_id, f.due_at;
+
+-- name: GetFreightRouteByAdvertisement :one
+SELECT route_hist_id, route_choose
+FROM advertisement_route
+WHERE advertisement_id = $1
+ORDER BY created_at DESC
+LIMIT 1;
+
+-- name: UpdateFreeFlowPassageReminder :exec
+UPDATE free_flow_passages
+SET reminders_sent = $2, last_reminder_at = now(), updated_at = now()
+WHERE id = $1;
@@ -0,0 +1,41 @@
+-- name: CreateFuelPriceImport :one
+INSERT INTO fuel_price_imports (file_name, checksum)
+VALUES ($1, $2)
+RETURNING *;
+
+-- name: GetFuelPriceImportByChecksum :one
+SELECT *
+FROM fuel_price_imports
+WHERE checksum = $1;
+
+-- name: UpdateFuelPriceImportRows :exec
+UPDATE fuel_price_imports
+SET rows_imported = $2
+WHERE id = $1;
+
+-- name: UpsertFuelPrice :exec
+INSERT INTO fuel_prices (import_id, product, state, municipio, municipio_key, average_price, min_price, max_price, stations_surveyed, survey_start, survey_end)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+ON CONFLICT (product, state, municipio_key, survey_end) DO UPDATE
+SET import_id         = EXCLUDED.import_id,
+    municipio         = EXCLUDED.municipio,
+    average_price     = EXCLUDED.average_price,
+    min_price         = EXCLUDED.min_price,
+    max_price         = EXCLUDED.max_price,
+    stations_surveyed = EXCLUDED.stations_surveyed,
+    survey_start      = EXCLUDED.survey_start,
+    updated_at        = now();
+
+-- name: GetLatestFuelPrices :many
+SELECT DISTINCT ON (product, state, municipio_key) *
+FROM fuel_prices
+WHERE (sqlc.arg(product)::text = '' OR product = sqlc.arg(product))
+  AND (sqlc.arg(state)::text = '' OR state = sqlc.arg(state))
+  AND (sqlc.arg(municipio_key)::text = '' OR municipio_key = sqlc.arg(municipio_key))
+ORDER BY product, state, municipio_key, survey_end DESC;
+
+-- name: GetLatestFuelPriceAverages :many
+SELECT f.product, AVG(f.average_price)::float AS average_price, MAX(f.survey_end)::date AS survey_end
+FROM fuel_prices f
+WHERE f.survey_end = (SELECT MAX(l.survey_end) FROM fuel_prices l WHERE l.product = f.product)
+GROUP BY f.product;
@@ -17,3 +17,7 @@ SELECT id, latitude, longitude, address_name, municipio, specific_point, name
 FROM gas_station
 WHERE CAST(latitude AS FLOAT) BETWEEN CAST($1 AS FLOAT) AND CAST($2 AS FLOAT)
   AND CAST(longitude AS FLOAT) BETWEEN CAST($3 AS FLOAT) AND CAST($4 AS FLOAT);
+
+-- name: GetAllGasStations :many
+SELECT id, latitude, longitude, address_name, municipio, specific_point, name
+FROM gas_station;
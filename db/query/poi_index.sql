@@ -0,0 +1,13 @@
+-- name: GetPOIFingerprint :one
+SELECT
+    (SELECT COUNT(*)::text || ':' || COALESCE(MAX(t.updated_at)::text, '') FROM public.tolls t)::text       AS tolls,
+    (SELECT COUNT(*)::text || ':' || COALESCE(MAX(b.updated_at)::text, '') FROM public.balanca b)::text     AS balancas,
+    (SELECT COUNT(*)::text || ':' || COALESCE(MAX(g.updated_at)::text, '') FROM public.gas_station g)::text AS gas_stations,
+    (SELECT COUNT(*)::text || ':' || COALESCE(MAX(f.updated_at)::text, '') FROM public.fuel_prices f)::text AS fuel_prices,
+    (SELECT COUNT(*)::text || ':' || COALESCE(MAX(COALESCE(tt.updated_at, tt.created_at))::text, '') FROM public.toll_tariffs tt)::text AS toll_tariffs,
+    (SELECT COUNT(*)::text || ':' || COALESCE(MAX(COALESCE(td.updated_at, td.created_at))::text, '') FROM public.toll_tag_discounts td)::text AS toll_tag_discounts;
+
+-- name: GetCityStates :many
+SELECT c.name, s.uf, c.lat, c.lon
+FROM cities c
+JOIN states s ON s.id = c.state_id;
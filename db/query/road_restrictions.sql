@@ -0,0 +1,5 @@
+-- name: GetRoadRestrictionsByBoundingBox :many
+SELECT * FROM road_restrictions
+WHERE status = true
+  AND lat BETWEEN $1 AND $2
+  AND lng BETWEEN $3 AND $4;
@@ -0,0 +1,37 @@
+-- name: CreateRouteEmission :exec
+INSERT INTO route_emissions (organization_id, user_id, route_hist_id, origin, destination, vehicle_type, fuel, distance_km, km_city, km_hwy, fuel_liters, co2e_kg, co2e_wtw_kg, biogenic_co2_kg)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14);
+
+-- name: GetRouteEmissionsReportByOrganization :many
+SELECT date_trunc('month', created_at)::timestamp AS month,
+       COUNT(*)::bigint AS routes,
+       SUM(distance_km)::float8 AS distance_km,
+       SUM(km_city)::float8 AS km_city,
+       SUM(km_hwy)::float8 AS km_hwy,
+       SUM(fuel_liters)::float8 AS fuel_liters,
+       SUM(co2e_kg)::float8 AS co2e_kg,
+       SUM(co2e_wtw_kg)::float8 AS co2e_wtw_kg,
+       SUM(biogenic_co2_kg)::float8 AS biogenic_co2_kg
+FROM route_emissions
+WHERE organization_id = sqlc.arg(organization_id)
+  AND created_at >= sqlc.arg(start_date)
+  AND created_at < sqlc.arg(end_date)
+GROUP BY 1
+ORDER BY 1;
+
+-- name: GetRouteEmissionsReportByUser :many
+SELECT date_trunc('month', created_at)::timestamp AS month,
+       COUNT(*)::bigint AS routes,
+       SUM(distance_km)::float8 AS distance_km,
+       SUM(km_city)::float8 AS km_city,
+       SUM(km_hwy)::float8 AS km_hwy,
+       SUM(fuel_liters)::float8 AS fuel_liters,
+       SUM(co2e_kg)::float8 AS co2e_kg,
+       SUM(co2e_wtw_kg)::float8 AS co2e_wtw_kg,
+       SUM(biogenic_co2_kg)::float8 AS biogenic_co2_kg
+FROM route_emissions
+WHERE user_id = sqlc.arg(user_id)
+  AND created_at >= sqlc.arg(start_date)
+  AND created_at < sqlc.arg(end_date)
+GROUP BY 1
+ORDER BY 1;
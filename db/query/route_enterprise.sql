@@ -9,4 +9,25 @@ UPDATE public.route_enterprise
 SET status=false
 WHERE id=$1 AND
       tenant_id=$2 AND
-      access_id=$3;
\ No newline at end of file
+      access_id=$3;
+
+-- name: GetRouteEnterpriseByID :one
+SELECT *
+FROM public.route_enterprise
+WHERE id=$1 AND
+      tenant_id=$2 AND
+      access_id=$3 AND
+      status=true;
+
+-- name: GetRouteEnterprisesForRepricing :many
+SELECT id, origin, destination, waypoints, response
+FROM public.route_enterprise
+WHERE status=true AND
+      id > $1
+ORDER BY id
+LIMIT $2;
+
+-- name: UpdateRouteEnterpriseResponse :exec
+UPDATE public.route_enterprise
+SET response=$2
+WHERE id=$1;
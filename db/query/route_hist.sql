@@ -21,3 +21,17 @@ WHERE id_user = $1
   AND waypoints = $4
   AND is_public = $5
     LIMIT 1;
+
+-- name: GetRouteHistByID :one
+SELECT *
+FROM route_hist
+WHERE id = $1;
+
+-- name: GetUserRouteHistByPeriod :many
+SELECT rh.id, rh.response, COALESCE(ar.route_choose, 0)::bigint AS route_choose
+FROM route_hist rh
+         LEFT JOIN advertisement_route ar ON ar.route_hist_id = rh.id AND ar.user_id = sqlc.arg(user_id)
+WHERE (rh.id_user = sqlc.arg(user_id) OR ar.user_id = sqlc.arg(user_id))
+  AND rh.created_at >= sqlc.arg(start_date)
+  AND rh.created_at < sqlc.arg(end_date)
+ORDER BY rh.created_at;
@@ -0,0 +1,19 @@
+-- name: CreateRouteVersion :one
+INSERT INTO public.route_versions
+(source, route_id, version, response, diff, material, created_at)
+VALUES($1, $2,
+       (SELECT COALESCE(MAX(v.version), 0) + 1 FROM public.route_versions v WHERE v.source = $1 AND v.route_id = $2),
+       $3, $4, $5, now())
+    RETURNING *;
+
+-- name: GetRouteVersions :many
+SELECT *
+FROM public.route_versions
+WHERE source = $1 AND
+      route_id = $2
+ORDER BY version DESC;
+
+-- name: UpdateRouteVersionNotified :exec
+UPDATE public.route_versions
+SET notified_at=now()
+WHERE id = $1;
@@ -15,4 +15,13 @@ WHERE origin = $1 AND
 -- name: GetSavedRouteById :one
 SELECT *
 FROM public.saved_routes
-WHERE ID = $1;
\ No newline at end of file
+WHERE ID = $1;
+
+-- name: GetSavedRouteRequestByRoute :one
+SELECT request
+FROM public.saved_routes
+WHERE origin = $1 AND
+      destination = $2 AND
+      waypoints = $3
+ORDER BY created_at DESC
+LIMIT 1;
@@ -0,0 +1,26 @@
+-- name: GetEffectiveTollTariffs :many
+SELECT * FROM toll_tariffs
+WHERE effective_from <= sqlc.arg('ref_date')::date
+  AND (effective_to IS NULL OR effective_to > sqlc.arg('ref_date')::date)
+ORDER BY toll_id, category, effective_from DESC;
+
+-- name: GetEffectiveTollTagDiscounts :many
+SELECT d.id, d.toll_tag_id, t.name AS tag_name, d.concessionaria, d.category, d.discount_percent, d.effective_from
+FROM toll_tag_discounts d
+JOIN toll_tags t ON t.id = d.toll_tag_id
+WHERE d.effective_from <= sqlc.arg('ref_date')::date
+  AND (d.effective_to IS NULL OR d.effective_to > sqlc.arg('ref_date')::date)
+ORDER BY d.toll_tag_id, d.effective_from DESC;
+
+-- name: GetTollPricingVersion :one
+SELECT (
+    (SELECT COUNT(*)::text || ':' || COALESCE(MAX(COALESCE(t.updated_at, t.created_at))::text, '') || ':' || COALESCE(MAX(t.effective_from)::text, '')
+     FROM toll_tariffs t
+     WHERE t.effective_from <= sqlc.arg('ref_date')::date
+       AND (t.effective_to IS NULL OR t.effective_to > sqlc.arg('ref_date')::date))
+    || '|' ||
+    (SELECT COUNT(*)::text || ':' || COALESCE(MAX(COALESCE(d.updated_at, d.created_at))::text, '') || ':' || COALESCE(MAX(d.effective_from)::text, '')
+     FROM toll_tag_discounts d
+     WHERE d.effective_from <= sqlc.arg('ref_date')::date
+       AND (d.effective_to IS NULL OR d.effective_to > sqlc.arg('ref_date')::date))
+)::text AS version;
@@ -2,4 +2,9 @@
 INSERT INTO public.truck
 (id, tractor_unit_id, trailer_id, driver_id)
 VALUES(nextval('truck_id_seq'::regclass), $1, $2, $3)
-    RETURNING *;
\ No newline at end of file
+    RETURNING *;
+
+-- name: GetTruckById :one
+SELECT *
+FROM public.truck
+WHERE id=$1;
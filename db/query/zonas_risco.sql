@@ -1,11 +1,11 @@
 -- name: CreateZonaRisco :one
-INSERT INTO zonas_risco (name, cep, lat, lng, radius, type, organization_id, status, zona_atencao)
-VALUES ($1, $2, $3, $4, $5, $6, $7, true, $8)
+INSERT INTO zonas_risco (name, cep, lat, lng, radius, type, organization_id, status, zona_atencao, shape, geometry, buffer_meters, severity, active_windows, expires_at, external_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7, true, $8, $9, $10, $11, $12, $13, $14, $15)
 RETURNING *;
 
 -- name: UpdateZonaRisco :one
 UPDATE zonas_risco
-SET name = $2, cep = $3, lat = $4, lng = $5, radius = $6, type= $7, organization_id=$8, zona_atencao=$9
+SET name = $2, cep = $3, lat = $4, lng = $5, radius = $6, type= $7, organization_id=$8, zona_atencao=$9, shape = $10, geometry = $11, buffer_meters = $12, severity = $13, active_windows = $14, expires_at = $15, external_id = $16
 WHERE id = $1 and status = true
 RETURNING *;
 
@@ -19,3 +19,9 @@ SELECT * FROM zonas_risco WHERE id = $1 AND status = true;
 
 -- name: GetAllZonasRisco :many
 SELECT * FROM zonas_risco WHERE status = true and organization_id=$1;
+
+-- name: GetZonaRiscoByExternalID :one
+SELECT * FROM zonas_risco
+WHERE organization_id = $1 AND external_id = $2 AND status = true
+ORDER BY id DESC
+LIMIT 1;
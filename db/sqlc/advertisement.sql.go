@@ -282,6 +282,79 @@ func (q *Queries) GetAdvertisementById(ctx context.Context, id int64) (Advertise
 	return i, err
 }
 
+const getAdvertisementByIdForUser = `-- name: GetAdvertisementByIdForUser :one
+SELECT a.id, a.user_id, a.destination, a.origin, a.destination_lat, a.destination_lng, a.origin_lat, a.origin_lng, a.distance, a.pickup_date, a.delivery_date, a.expiration_date, a.title, a.cargo_type, a.cargo_species, a.cargo_weight, a.vehicles_accepted, a.trailer, a.requires_tarp, a.tracking, a.agency, a.description, a.payment_type, a.advance, a.toll, a.situation, a.price, a.state_origin, a.city_origin, a.complement_origin, a.neighborhood_origin, a.street_origin, a.street_number_origin, a.cep_origin, a.state_destination, a.city_destination, a.complement_destination, a.neighborhood_destination, a.street_destination, a.street_number_destination, a.cep_destination, a.status, a.created_at, a.created_who, a.updated_at, a.updated_who
+FROM public.advertisement a
+WHERE a.id = $1
+  AND a.status = true
+  AND (a.user_id = $2 OR EXISTS (
+      SELECT 1
+      FROM appointments ap
+      WHERE ap.advertisement_id = a.id
+        AND ap.interested_user_id = $2
+        AND ap.status = true
+  ))
+`
+
+type GetAdvertisementByIdForUserParams struct {
+	ID     int64 `json:"id"`
+	UserID int64 `json:"user_id"`
+}
+
+func (q *Queries) GetAdvertisementByIdForUser(ctx context.Context, arg GetAdvertisementByIdForUserParams) (Advertisement, error) {
+	row := q.db.QueryRowContext(ctx, getAdvertisementByIdForUser, arg.ID, arg.UserID)
+	var i Advertisement
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Destination,
+		&i.Origin,
+		&i.DestinationLat,
+		&i.DestinationLng,
+		&i.OriginLat,
+		&i.OriginLng,
+		&i.Distance,
+		&i.PickupDate,
+		&i.DeliveryDate,
+		&i.ExpirationDate,
+		&i.Title,
+		&i.CargoType,
+		&i.CargoSpecies,
+		&i.CargoWeight,
+		&i.VehiclesAccepted,
+		&i.Trailer,
+		&i.RequiresTarp,
+		&i.Tracking,
+		&i.Agency,
+		&i.Description,
+		&i.PaymentType,
+		&i.Advance,
+		&i.Toll,
+		&i.Situation,
+		&i.Price,
+		&i.StateOrigin,
+		&i.CityOrigin,
+		&i.ComplementOrigin,
+		&i.NeighborhoodOrigin,
+		&i.StreetOrigin,
+		&i.StreetNumberOrigin,
+		&i.CepOrigin,
+		&i.StateDestination,
+		&i.CityDestination,
+		&i.ComplementDestination,
+		&i.NeighborhoodDestination,
+		&i.StreetDestination,
+		&i.StreetNumberDestination,
+		&i.CepDestination,
+		&i.Status,
+		&i.CreatedAt,
+		&i.CreatedWho,
+		&i.UpdatedAt,
+		&i.UpdatedWho,
+	)
+	return i, err
+}
+
 const getAdvertisementExist = `-- name: GetAdvertisementExist :one
 SELECT id, advertisement_id, route_hist_id, user_id, route_choose, created_at
 FROM advertisement_route
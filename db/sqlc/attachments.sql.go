@@ -125,6 +125,38 @@ func (q *Queries) GetAttachmentById(ctx context.Context, arg GetAttachmentByIdPa
 	return i, err
 }
 
+const getLatestAttachmentByDescription = `-- name: GetLatestAttachmentByDescription :one
+SELECT id, user_id, description, url, name_file, size_file, type, status, created_at, updated_at
+FROM public.attachments
+WHERE user_id=$1 AND type=$2 AND description=$3 AND status=true
+ORDER BY created_at DESC
+LIMIT 1
+`
+
+type GetLatestAttachmentByDescriptionParams struct {
+	UserID      int64          `json:"user_id"`
+	Type        string         `json:"type"`
+	Description sql.NullString `json:"description"`
+}
+
+func (q *Queries) GetLatestAttachmentByDescription(ctx context.Context, arg GetLatestAttachmentByDescriptionParams) (Attachment, error) {
+	row := q.db.QueryRowContext(ctx, getLatestAttachmentByDescription, arg.UserID, arg.Type, arg.Description)
+	var i Attachment
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Description,
+		&i.Url,
+		&i.NameFile,
+		&i.SizeFile,
+		&i.Type,
+		&i.Status,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
 const updateAttachmentLogicDelete = `-- name: UpdateAttachmentLogicDelete :exec
 UPDATE public.attachments
 SET status=false, updated_at=now()
@@ -10,7 +10,7 @@ import (
 )
 
 const getBalanca = `-- name: GetBalanca :many
-SELECT id, concessionaria, km, lat, lng, nome, rodovia, sentido, uf
+SELECT id, concessionaria, km, lat, lng, nome, rodovia, sentido, uf, updated_at
 FROM public.balanca
 `
 
@@ -33,6 +33,7 @@ func (q *Queries) GetBalanca(ctx context.Context) ([]Balanca, error) {
 			&i.Rodovia,
 			&i.Sentido,
 			&i.Uf,
+			&i.UpdatedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -89,6 +89,95 @@ func (q *Queries) GetFavoriteByUserId(ctx context.Context, idUser int64) ([]Favo
 	return items, nil
 }
 
+const getFavoriteRouteByID = `-- name: GetFavoriteRouteByID :one
+SELECT id, id_user, origin, destination, waypoints, response, status, created_at, updated_at
+FROM public.favorite_route
+WHERE id = $1 AND
+      id_user = $2 AND
+      status=true
+`
+
+type GetFavoriteRouteByIDParams struct {
+	ID     int64 `json:"id"`
+	IDUser int64 `json:"id_user"`
+}
+
+func (q *Queries) GetFavoriteRouteByID(ctx context.Context, arg GetFavoriteRouteByIDParams) (FavoriteRoute, error) {
+	row := q.db.QueryRowContext(ctx, getFavoriteRouteByID, arg.ID, arg.IDUser)
+	var i FavoriteRoute
+	err := row.Scan(
+		&i.ID,
+		&i.IDUser,
+		&i.Origin,
+		&i.Destination,
+		&i.Waypoints,
+		&i.Response,
+		&i.Status,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getFavoriteRoutesForRepricing = `-- name: GetFavoriteRoutesForRepricing :many
+SELECT f.id, f.id_user, f.origin, f.destination, f.waypoints, f.response,
+       u.name AS user_name, u.email AS user_email
+FROM public.favorite_route f
+         INNER JOIN users u ON u.id = f.id_user
+WHERE f.status = true AND
+      f.id > $1
+ORDER BY f.id
+LIMIT $2
+`
+
+type GetFavoriteRoutesForRepricingParams struct {
+	ID    int64 `json:"id"`
+	Limit int32 `json:"limit"`
+}
+
+type GetFavoriteRoutesForRepricingRow struct {
+	ID          int64           `json:"id"`
+	IDUser      int64           `json:"id_user"`
+	Origin      string          `json:"origin"`
+	Destination string          `json:"destination"`
+	Waypoints   sql.NullString  `json:"waypoints"`
+	Response    json.RawMessage `json:"response"`
+	UserName    string          `json:"user_name"`
+	UserEmail   string          `json:"user_email"`
+}
+
+func (q *Queries) GetFavoriteRoutesForRepricing(ctx context.Context, arg GetFavoriteRoutesForRepricingParams) ([]GetFavoriteRoutesForRepricingRow, error) {
+	rows, err := q.db.QueryContext(ctx, getFavoriteRoutesForRepricing, arg.ID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetFavoriteRoutesForRepricingRow
+	for rows.Next() {
+		var i GetFavoriteRoutesForRepricingRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.IDUser,
+			&i.Origin,
+			&i.Destination,
+			&i.Waypoints,
+			&i.Response,
+			&i.UserName,
+			&i.UserEmail,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const removeFavorite = `-- name: RemoveFavorite :exec
 UPDATE public.favorite_route
 SET status=false, updated_at=now()
@@ -105,3 +194,19 @@ func (q *Queries) RemoveFavorite(ctx context.Context, arg RemoveFavoriteParams)
 	_, err := q.db.ExecContext(ctx, removeFavorite, arg.ID, arg.IDUser)
 	return err
 }
+
+const updateFavoriteRouteResponse = `-- name: UpdateFavoriteRouteResponse :exec
+UPDATE public.favorite_route
+SET response=$2, updated_at=now()
+WHERE id = $1
+`
+
+type UpdateFavoriteRouteResponseParams struct {
+	ID       int64           `json:"id"`
+	Response json.RawMessage `json:"response"`
+}
+
+func (q *Queries) UpdateFavoriteRouteResponse(ctx context.Context, arg UpdateFavoriteRouteResponseParams) error {
+	_, err := q.db.ExecContext(ctx, updateFavoriteRouteResponse, arg.ID, arg.Response)
+	return err
+}
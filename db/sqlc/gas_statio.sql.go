@@ -13,7 +13,7 @@ const createGasStations = `-- name: CreateGasStations :one
 INSERT INTO public.gas_station
 (id, name, latitude, longitude, address_name, municipio, specific_point)
 VALUES(nextval('gas_station_id_seq'::regclass), $1, $2, $3, $4, $5, $6)
-    RETURNING id, name, latitude, longitude, address_name, municipio, specific_point
+    RETURNING id, name, latitude, longitude, address_name, municipio, specific_point, updated_at
 `
 
 type CreateGasStationsParams struct {
@@ -43,10 +43,57 @@ func (q *Queries) CreateGasStations(ctx context.Context, arg CreateGasStationsPa
 		&i.AddressName,
 		&i.Municipio,
 		&i.SpecificPoint,
+		&i.UpdatedAt,
 	)
 	return i, err
 }
 
+const getAllGasStations = `-- name: GetAllGasStations :many
+SELECT id, latitude, longitude, address_name, municipio, specific_point, name
+FROM gas_station
+`
+
+type GetAllGasStationsRow struct {
+	ID            int64  `json:"id"`
+	Latitude      string `json:"latitude"`
+	Longitude     string `json:"longitude"`
+	AddressName   string `json:"address_name"`
+	Municipio     string `json:"municipio"`
+	SpecificPoint string `json:"specific_point"`
+	Name          string `json:"name"`
+}
+
+func (q *Queries) GetAllGasStations(ctx context.Context) ([]GetAllGasStationsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getAllGasStations)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetAllGasStationsRow
+	for rows.Next() {
+		var i GetAllGasStationsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Latitude,
+			&i.Longitude,
+			&i.AddressName,
+			&i.Municipio,
+			&i.SpecificPoint,
+			&i.Name,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getGasStation = `-- name: GetGasStation :many
 SELECT id, latitude, longitude, address_name, municipio, specific_point, name
 FROM gas_station
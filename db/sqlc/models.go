@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/sqlc-dev/pqtype"
 )
 
 type ActiveFreight struct {
@@ -140,15 +141,16 @@ type Attachment struct {
 }
 
 type Balanca struct {
-	ID             int64  `json:"id"`
-	Concessionaria string `json:"concessionaria"`
-	Km             string `json:"km"`
-	Lat            string `json:"lat"`
-	Lng            string `json:"lng"`
-	Nome           string `json:"nome"`
-	Rodovia        string `json:"rodovia"`
-	Sentido        string `json:"sentido"`
-	Uf             string `json:"uf"`
+	ID             int64     `json:"id"`
+	Concessionaria string    `json:"concessionaria"`
+	Km             string    `json:"km"`
+	Lat            string    `json:"lat"`
+	Lng            string    `json:"lng"`
+	Nome           string    `json:"nome"`
+	Rodovia        string    `json:"rodovia"`
+	Sentido        string    `json:"sentido"`
+	Uf             string    `json:"uf"`
+	UpdatedAt      time.Time `json:"updated_at"`
 }
 
 type ChatMessage struct {
@@ -218,6 +220,29 @@ type FavoriteRoute struct {
 	UpdatedAt   sql.NullTime    `json:"updated_at"`
 }
 
+type FreeFlowPassage struct {
+	ID              int64         `json:"id"`
+	UserID          int64         `json:"user_id"`
+	OrganizationID  sql.NullInt64 `json:"organization_id"`
+	AdvertisementID sql.NullInt64 `json:"advertisement_id"`
+	RouteHistID     int64         `json:"route_hist_id"`
+	RouteIndex      int32         `json:"route_index"`
+	TollID          int64         `json:"toll_id"`
+	TollName        string        `json:"toll_name"`
+	Concessionaria  string        `json:"concessionaria"`
+	Road            string        `json:"road"`
+	PaymentLink     string        `json:"payment_link"`
+	Amount          float64       `json:"amount"`
+	PassedAt        time.Time     `json:"passed_at"`
+	DueAt           time.Time     `json:"due_at"`
+	Status          string        `json:"status"`
+	PaidAt          sql.NullTime  `json:"paid_at"`
+	RemindersSent   int32         `json:"reminders_sent"`
+	LastReminderAt  sql.NullTime  `json:"last_reminder_at"`
+	CreatedAt       time.Time     `json:"created_at"`
+	UpdatedAt       sql.NullTime  `json:"updated_at"`
+}
+
 type FreightLoad struct {
 	TypeOfLoad  sql.NullString `json:"type_of_load"`
 	TwoAxes     sql.NullString `json:"two_axes"`
@@ -231,14 +256,40 @@ type FreightLoad struct {
 	Description sql.NullString `json:"description"`
 }
 
+type FuelPrice struct {
+	ID               int64         `json:"id"`
+	ImportID         sql.NullInt64 `json:"import_id"`
+	Product          string        `json:"product"`
+	State            string        `json:"state"`
+	Municipio        string        `json:"municipio"`
+	MunicipioKey     string        `json:"municipio_key"`
+	AveragePrice     float64       `json:"average_price"`
+	MinPrice         float64       `json:"min_price"`
+	MaxPrice         float64       `json:"max_price"`
+	StationsSurveyed int32         `json:"stations_surveyed"`
+	SurveyStart      time.Time     `json:"survey_start"`
+	SurveyEnd        time.Time     `json:"survey_end"`
+	CreatedAt        time.Time     `json:"created_at"`
+	UpdatedAt        time.Time     `json:"updated_at"`
+}
+
+type FuelPriceImport struct {
+	ID           int64     `json:"id"`
+	FileName     string    `json:"file_name"`
+	Checksum     string    `json:"checksum"`
+	RowsImported int32     `json:"rows_imported"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
 type GasStation struct {
-	ID            int64  `json:"id"`
-	Name          string `json:"name"`
-	Latitude      string `json:"latitude"`
-	Longitude     string `json:"longitude"`
-	AddressName   string `json:"address_name"`
-	Municipio     string `json:"municipio"`
-	SpecificPoint string `json:"specific_point"`
+	ID            int64     `json:"id"`
+	Name          string    `json:"name"`
+	Latitude      string    `json:"latitude"`
+	Longitude     string    `json:"longitude"`
+	AddressName   string    `json:"address_name"`
+	Municipio     string    `json:"municipio"`
+	SpecificPoint string    `json:"specific_point"`
+	UpdatedAt     time.Time `json:"updated_at"`
 }
 
 type HistoryRecoverPassword struct {
@@ -336,6 +387,43 @@ type Profile struct {
 	Name string `json:"name"`
 }
 
+type RoadRestriction struct {
+	ID        int64           `json:"id"`
+	Name      string          `json:"name"`
+	Type      string          `json:"type"`
+	Road      sql.NullString  `json:"road"`
+	City      sql.NullString  `json:"city"`
+	State     sql.NullString  `json:"state"`
+	Lat       float64         `json:"lat"`
+	Lng       float64         `json:"lng"`
+	Radius    int64           `json:"radius"`
+	MaxHeight sql.NullFloat64 `json:"max_height"`
+	MaxWeight sql.NullFloat64 `json:"max_weight"`
+	MaxLength sql.NullFloat64 `json:"max_length"`
+	Status    bool            `json:"status"`
+	CreatedAt time.Time       `json:"created_at"`
+	UpdatedAt sql.NullTime    `json:"updated_at"`
+}
+
+type RouteEmission struct {
+	ID             int64         `json:"id"`
+	OrganizationID sql.NullInt64 `json:"organization_id"`
+	UserID         sql.NullInt64 `json:"user_id"`
+	RouteHistID    sql.NullInt64 `json:"route_hist_id"`
+	Origin         string        `json:"origin"`
+	Destination    string        `json:"destination"`
+	VehicleType    string        `json:"vehicle_type"`
+	Fuel           string        `json:"fuel"`
+	DistanceKm     float64       `json:"distance_km"`
+	KmCity         float64       `json:"km_city"`
+	KmHwy          float64       `json:"km_hwy"`
+	FuelLiters     float64       `json:"fuel_liters"`
+	Co2eKg         float64       `json:"co2e_kg"`
+	Co2eWtwKg      float64       `json:"co2e_wtw_kg"`
+	BiogenicCo2Kg  float64       `json:"biogenic_co2_kg"`
+	CreatedAt      time.Time     `json:"created_at"`
+}
+
 type RouteEnterprise struct {
 	ID          int64           `json:"id"`
 	Origin      string          `json:"origin"`
@@ -361,6 +449,18 @@ type RouteHist struct {
 	CreatedAt     time.Time       `json:"created_at"`
 }
 
+type RouteVersion struct {
+	ID         int64           `json:"id"`
+	Source     string          `json:"source"`
+	RouteID    int64           `json:"route_id"`
+	Version    int32           `json:"version"`
+	Response   json.RawMessage `json:"response"`
+	Diff       json.RawMessage `json:"diff"`
+	Material   bool            `json:"material"`
+	NotifiedAt sql.NullTime    `json:"notified_at"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
 type SavedRoute struct {
 	ID          int32           `json:"id"`
 	Origin      string          `json:"origin"`
@@ -416,12 +516,39 @@ type Toll struct {
 	Tarifa           sql.NullString `json:"tarifa"`
 	FreeFlow         sql.NullBool   `json:"free_flow"`
 	PayFreeFlow      sql.NullString `json:"pay_free_flow"`
+	UpdatedAt        time.Time      `json:"updated_at"`
 }
 
 type TollTag struct {
-	ID                int64  `json:"id"`
-	Name              string `json:"name"`
-	DealershipAccepts string `json:"dealership_accepts"`
+	ID                int64   `json:"id"`
+	Name              string  `json:"name"`
+	DealershipAccepts string  `json:"dealership_accepts"`
+	ImageUrl          string  `json:"image_url"`
+	MonthlyFee        float64 `json:"monthly_fee"`
+}
+
+type TollTagDiscount struct {
+	ID              int64          `json:"id"`
+	TollTagID       int64          `json:"toll_tag_id"`
+	Concessionaria  sql.NullString `json:"concessionaria"`
+	Category        sql.NullInt32  `json:"category"`
+	DiscountPercent float64        `json:"discount_percent"`
+	EffectiveFrom   time.Time      `json:"effective_from"`
+	EffectiveTo     sql.NullTime   `json:"effective_to"`
+	CreatedAt       time.Time      `json:"created_at"`
+	UpdatedAt       sql.NullTime   `json:"updated_at"`
+}
+
+type TollTariff struct {
+	ID            int64          `json:"id"`
+	TollID        int64          `json:"toll_id"`
+	Category      int32          `json:"category"`
+	Amount        float64        `json:"amount"`
+	EffectiveFrom time.Time      `json:"effective_from"`
+	EffectiveTo   sql.NullTime   `json:"effective_to"`
+	Source        sql.NullString `json:"source"`
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     sql.NullTime   `json:"updated_at"`
 }
 
 type TractorUnit struct {
@@ -571,14 +698,21 @@ type ViewRouteRequest struct {
 }
 
 type ZonasRisco struct {
-	ID             int64         `json:"id"`
-	Name           string        `json:"name"`
-	Cep            string        `json:"cep"`
-	Lat            float64       `json:"lat"`
-	Lng            float64       `json:"lng"`
-	Radius         int64         `json:"radius"`
-	Type           sql.NullInt64 `json:"type"`
-	OrganizationID sql.NullInt64 `json:"organization_id"`
-	ZonaAtencao    bool          `json:"zona_atencao"`
-	Status         bool          `json:"status"`
+	ID             int64                 `json:"id"`
+	Name           string                `json:"name"`
+	Cep            string                `json:"cep"`
+	Lat            float64               `json:"lat"`
+	Lng            float64               `json:"lng"`
+	Radius         int64                 `json:"radius"`
+	Type           sql.NullInt64         `json:"type"`
+	OrganizationID sql.NullInt64         `json:"organization_id"`
+	ZonaAtencao    bool                  `json:"zona_atencao"`
+	Status         bool                  `json:"status"`
+	Shape          string                `json:"shape"`
+	Geometry       pqtype.NullRawMessage `json:"geometry"`
+	BufferMeters   int64                 `json:"buffer_meters"`
+	Severity       int64                 `json:"severity"`
+	ActiveWindows  pqtype.NullRawMessage `json:"active_windows"`
+	ExpiresAt      sql.NullTime          `json:"expires_at"`
+	ExternalID     sql.NullString        `json:"external_id"`
 }
@@ -74,3 +74,103 @@ func (q *Queries) DeleteRouteEnterprise(ctx context.Context, arg DeleteRouteEnte
 	_, err := q.db.ExecContext(ctx, deleteRouteEnterprise, arg.ID, arg.TenantID, arg.AccessID)
 	return err
 }
+
+const getRouteEnterpriseByID = `-- name: GetRouteEnterpriseByID :one
+SELECT id, origin, destination, waypoints, response, status, created_at, created_who, tenant_id, access_id
+FROM public.route_enterprise
+WHERE id=$1 AND
+      tenant_id=$2 AND
+      access_id=$3 AND
+      status=true
+`
+
+type GetRouteEnterpriseByIDParams struct {
+	ID       int64     `json:"id"`
+	TenantID uuid.UUID `json:"tenant_id"`
+	AccessID int64     `json:"access_id"`
+}
+
+func (q *Queries) GetRouteEnterpriseByID(ctx context.Context, arg GetRouteEnterpriseByIDParams) (RouteEnterprise, error) {
+	row := q.db.QueryRowContext(ctx, getRouteEnterpriseByID, arg.ID, arg.TenantID, arg.AccessID)
+	var i RouteEnterprise
+	err := row.Scan(
+		&i.ID,
+		&i.Origin,
+		&i.Destination,
+		&i.Waypoints,
+		&i.Response,
+		&i.Status,
+		&i.CreatedAt,
+		&i.CreatedWho,
+		&i.TenantID,
+		&i.AccessID,
+	)
+	return i, err
+}
+
+const getRouteEnterprisesForRepricing = `-- name: GetRouteEnterprisesForRepricing :many
+SELECT id, origin, destination, waypoints, response
+FROM public.route_enterprise
+WHERE status=true AND
+      id > $1
+ORDER BY id
+LIMIT $2
+`
+
+type GetRouteEnterprisesForRepricingParams struct {
+	ID    int64 `json:"id"`
+	Limit int32 `json:"limit"`
+}
+
+type GetRouteEnterprisesForRepricingRow struct {
+	ID          int64           `json:"id"`
+	Origin      string          `json:"origin"`
+	Destination string          `json:"destination"`
+	Waypoints   sql.NullString  `json:"waypoints"`
+	Response    json.RawMessage `json:"response"`
+}
+
+func (q *Queries) GetRouteEnterprisesForRepricing(ctx context.Context, arg GetRouteEnterprisesForRepricingParams) ([]GetRouteEnterprisesForRepricingRow, error) {
+	rows, err := q.db.QueryContext(ctx, getRouteEnterprisesForRepricing, arg.ID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetRouteEnterprisesForRepricingRow
+	for rows.Next() {
+		var i GetRouteEnterprisesForRepricingRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Origin,
+			&i.Destination,
+			&i.Waypoints,
+			&i.Response,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateRouteEnterpriseResponse = `-- name: UpdateRouteEnterpriseResponse :exec
+UPDATE public.route_enterprise
+SET response=$2
+WHERE id=$1
+`
+
+type UpdateRouteEnterpriseResponseParams struct {
+	ID       int64           `json:"id"`
+	Response json.RawMessage `json:"response"`
+}
+
+func (q *Queries) UpdateRouteEnterpriseResponse(ctx context.Context, arg UpdateRouteEnterpriseResponseParams) error {
+	_, err := q.db.ExecContext(ctx, updateRouteEnterpriseResponse, arg.ID, arg.Response)
+	return err
+}
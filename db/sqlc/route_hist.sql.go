@@ -9,6 +9,7 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"time"
 )
 
 const createRouteHist = `-- name: CreateRouteHist :one
@@ -53,6 +54,29 @@ func (q *Queries) CreateRouteHist(ctx context.Context, arg CreateRouteHistParams
 	return i, err
 }
 
+const getRouteHistByID = `-- name: GetRouteHistByID :one
+SELECT id, id_user, origin, destination, waypoints, response, is_public, number_request, created_at
+FROM route_hist
+WHERE id = $1
+`
+
+func (q *Queries) GetRouteHistByID(ctx context.Context, id int64) (RouteHist, error) {
+	row := q.db.QueryRowContext(ctx, getRouteHistByID, id)
+	var i RouteHist
+	err := row.Scan(
+		&i.ID,
+		&i.IDUser,
+		&i.Origin,
+		&i.Destination,
+		&i.Waypoints,
+		&i.Response,
+		&i.IsPublic,
+		&i.NumberRequest,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
 const getRouteHistByUnique = `-- name: GetRouteHistByUnique :one
 SELECT id, id_user, origin, destination, waypoints, response, is_public, number_request, created_at
 FROM route_hist
@@ -95,6 +119,51 @@ func (q *Queries) GetRouteHistByUnique(ctx context.Context, arg GetRouteHistByUn
 	return i, err
 }
 
+const getUserRouteHistByPeriod = `-- name: GetUserRouteHistByPeriod :many
+SELECT rh.id, rh.response, COALESCE(ar.route_choose, 0)::bigint AS route_choose
+FROM route_hist rh
+         LEFT JOIN advertisement_route ar ON ar.route_hist_id = rh.id AND ar.user_id = $1
+WHERE (rh.id_user = $1 OR ar.user_id = $1)
+  AND rh.created_at >= $2
+  AND rh.created_at < $3
+ORDER BY rh.created_at
+`
+
+type GetUserRouteHistByPeriodParams struct {
+	UserID    int64     `json:"user_id"`
+	StartDate time.Time `json:"start_date"`
+	EndDate   time.Time `json:"end_date"`
+}
+
+type GetUserRouteHistByPeriodRow struct {
+	ID          int64           `json:"id"`
+	Response    json.RawMessage `json:"response"`
+	RouteChoose int64           `json:"route_choose"`
+}
+
+func (q *Queries) GetUserRouteHistByPeriod(ctx context.Context, arg GetUserRouteHistByPeriodParams) ([]GetUserRouteHistByPeriodRow, error) {
+	rows, err := q.db.QueryContext(ctx, getUserRouteHistByPeriod, arg.UserID, arg.StartDate, arg.EndDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetUserRouteHistByPeriodRow
+	for rows.Next() {
+		var i GetUserRouteHistByPeriodRow
+		if err := rows.Scan(&i.ID, &i.Response, &i.RouteChoose); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const updateRouteHistCount = `-- name: UpdateRouteHistCount :one
 UPDATE route_hist
 SET number_request = number_request + 1
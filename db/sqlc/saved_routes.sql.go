@@ -77,6 +77,29 @@ func (q *Queries) GetSavedRouteById(ctx context.Context, id int32) (SavedRoute,
 	return i, err
 }
 
+const getSavedRouteRequestByRoute = `-- name: GetSavedRouteRequestByRoute :one
+SELECT request
+FROM public.saved_routes
+WHERE origin = $1 AND
+      destination = $2 AND
+      waypoints = $3
+ORDER BY created_at DESC
+LIMIT 1
+`
+
+type GetSavedRouteRequestByRouteParams struct {
+	Origin      string         `json:"origin"`
+	Destination string         `json:"destination"`
+	Waypoints   sql.NullString `json:"waypoints"`
+}
+
+func (q *Queries) GetSavedRouteRequestByRoute(ctx context.Context, arg GetSavedRouteRequestByRouteParams) (json.RawMessage, error) {
+	row := q.db.QueryRowContext(ctx, getSavedRouteRequestByRoute, arg.Origin, arg.Destination, arg.Waypoints)
+	var request json.RawMessage
+	err := row.Scan(&request)
+	return request, err
+}
+
 const getSavedRoutes = `-- name: GetSavedRoutes :one
 SELECT id, origin, destination, waypoints, request, response, created_at, updated_at, favorite, expired_at
 FROM public.saved_routes
@@ -10,7 +10,7 @@ import (
 )
 
 const getTollTags = `-- name: GetTollTags :many
-SELECT id, name, dealership_accepts
+SELECT id, name, dealership_accepts, image_url, monthly_fee
 FROM public.toll_tags
 `
 
@@ -23,7 +23,13 @@ func (q *Queries) GetTollTags(ctx context.Context) ([]TollTag, error) {
 	var items []TollTag
 	for rows.Next() {
 		var i TollTag
-		if err := rows.Scan(&i.ID, &i.Name, &i.DealershipAccepts); err != nil {
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.DealershipAccepts,
+			&i.ImageUrl,
+			&i.MonthlyFee,
+		); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
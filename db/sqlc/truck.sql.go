@@ -34,3 +34,21 @@ func (q *Queries) CreateTruck(ctx context.Context, arg CreateTruckParams) (Truck
 	)
 	return i, err
 }
+
+const getTruckById = `-- name: GetTruckById :one
+SELECT id, tractor_unit_id, trailer_id, driver_id
+FROM public.truck
+WHERE id=$1
+`
+
+func (q *Queries) GetTruckById(ctx context.Context, id int64) (Truck, error) {
+	row := q.db.QueryRowContext(ctx, getTruckById, id)
+	var i Truck
+	err := row.Scan(
+		&i.ID,
+		&i.TractorUnitID,
+		&i.TrailerID,
+		&i.DriverID,
+	)
+	return i, err
+}
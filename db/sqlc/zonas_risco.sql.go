@@ -8,23 +8,32 @@ package db
 import (
 	"context"
 	"database/sql"
+
+	"github.com/sqlc-dev/pqtype"
 )
 
 const createZonaRisco = `-- name: CreateZonaRisco :one
-INSERT INTO zonas_risco (name, cep, lat, lng, radius, type, organization_id, status, zona_atencao)
-VALUES ($1, $2, $3, $4, $5, $6, $7, true, $8)
-RETURNING id, name, cep, lat, lng, radius, type, organization_id, zona_atencao, status
+INSERT INTO zonas_risco (name, cep, lat, lng, radius, type, organization_id, status, zona_atencao, shape, geometry, buffer_meters, severity, active_windows, expires_at, external_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7, true, $8, $9, $10, $11, $12, $13, $14, $15)
+RETURNING id, name, cep, lat, lng, radius, type, organization_id, zona_atencao, status, shape, geometry, buffer_meters, severity, active_windows, expires_at, external_id
 `
 
 type CreateZonaRiscoParams struct {
-	Name           string        `json:"name"`
-	Cep            string        `json:"cep"`
-	Lat            float64       `json:"lat"`
-	Lng            float64       `json:"lng"`
-	Radius         int64         `json:"radius"`
-	Type           sql.NullInt64 `json:"type"`
-	OrganizationID sql.NullInt64 `json:"organization_id"`
-	ZonaAtencao    bool          `json:"zona_atencao"`
+	Name           string                `json:"name"`
+	Cep            string                `json:"cep"`
+	Lat            float64               `json:"lat"`
+	Lng            float64               `json:"lng"`
+	Radius         int64                 `json:"radius"`
+	Type           sql.NullInt64         `json:"type"`
+	OrganizationID sql.NullInt64         `json:"organization_id"`
+	ZonaAtencao    bool                  `json:"zona_atencao"`
+	Shape          string                `json:"shape"`
+	Geometry       pqtype.NullRawMessage `json:"geometry"`
+	BufferMeters   int64                 `json:"buffer_meters"`
+	Severity       int64                 `json:"severity"`
+	ActiveWindows  pqtype.NullRawMessage `json:"active_windows"`
+	ExpiresAt      sql.NullTime          `json:"expires_at"`
+	ExternalID     sql.NullString        `json:"external_id"`
 }
 
 func (q *Queries) CreateZonaRisco(ctx context.Context, arg CreateZonaRiscoParams) (ZonasRisco, error) {
@@ -37,6 +46,13 @@ func (q *Queries) CreateZonaRisco(ctx context.Context, arg CreateZonaRiscoParams
 		arg.Type,
 		arg.OrganizationID,
 		arg.ZonaAtencao,
+		arg.Shape,
+		arg.Geometry,
+		arg.BufferMeters,
+		arg.Severity,
+		arg.ActiveWindows,
+		arg.ExpiresAt,
+		arg.ExternalID,
 	)
 	var i ZonasRisco
 	err := row.Scan(
@@ -50,6 +66,13 @@ func (q *Queries) CreateZonaRisco(ctx context.Context, arg CreateZonaRiscoParams
 		&i.OrganizationID,
 		&i.ZonaAtencao,
 		&i.Status,
+		&i.Shape,
+		&i.Geometry,
+		&i.BufferMeters,
+		&i.Severity,
+		&i.ActiveWindows,
+		&i.ExpiresAt,
+		&i.ExternalID,
 	)
 	return i, err
 }
@@ -66,7 +89,7 @@ func (q *Queries) DeleteZonaRisco(ctx context.Context, id int64) error {
 }
 
 const getAllZonasRisco = `-- name: GetAllZonasRisco :many
-SELECT id, name, cep, lat, lng, radius, type, organization_id, zona_atencao, status FROM zonas_risco WHERE status = true and organization_id=$1
+SELECT id, name, cep, lat, lng, radius, type, organization_id, zona_atencao, status, shape, geometry, buffer_meters, severity, active_windows, expires_at, external_id FROM zonas_risco WHERE status = true and organization_id=$1
 `
 
 func (q *Queries) GetAllZonasRisco(ctx context.Context, organizationID sql.NullInt64) ([]ZonasRisco, error) {
@@ -89,6 +112,13 @@ func (q *Queries) GetAllZonasRisco(ctx context.Context, organizationID sql.NullI
 			&i.OrganizationID,
 			&i.ZonaAtencao,
 			&i.Status,
+			&i.Shape,
+			&i.Geometry,
+			&i.BufferMeters,
+			&i.Severity,
+			&i.ActiveWindows,
+			&i.ExpiresAt,
+			&i.ExternalID,
 		); err != nil {
 			return nil, err
 		}
@@ -103,8 +133,45 @@ func (q *Queries) GetAllZonasRisco(ctx context.Context, organizationID sql.NullI
 	return items, nil
 }
 
+const getZonaRiscoByExternalID = `-- name: GetZonaRiscoByExternalID :one
+SELECT id, name, cep, lat, lng, radius, type, organization_id, zona_atencao, status, shape, geometry, buffer_meters, severity, active_windows, expires_at, external_id FROM zonas_risco
+WHERE organization_id = $1 AND external_id = $2 AND status = true
+ORDER BY id DESC
+LIMIT 1
+`
+
+type GetZonaRiscoByExternalIDParams struct {
+	OrganizationID sql.NullInt64  `json:"organization_id"`
+	ExternalID     sql.NullString `json:"external_id"`
+}
+
+func (q *Queries) GetZonaRiscoByExternalID(ctx context.Context, arg GetZonaRiscoByExternalIDParams) (ZonasRisco, error) {
+	row := q.db.QueryRowContext(ctx, getZonaRiscoByExternalID, arg.OrganizationID, arg.ExternalID)
+	var i ZonasRisco
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Cep,
+		&i.Lat,
+		&i.Lng,
+		&i.Radius,
+		&i.Type,
+		&i.OrganizationID,
+		&i.ZonaAtencao,
+		&i.Status,
+		&i.Shape,
+		&i.Geometry,
+		&i.BufferMeters,
+		&i.Severity,
+		&i.ActiveWindows,
+		&i.ExpiresAt,
+		&i.ExternalID,
+	)
+	return i, err
+}
+
 const getZonaRiscoById = `-- name: GetZonaRiscoById :one
-SELECT id, name, cep, lat, lng, radius, type, organization_id, zona_atencao, status FROM zonas_risco WHERE id = $1 AND status = true
+SELECT id, name, cep, lat, lng, radius, type, organization_id, zona_atencao, status, shape, geometry, buffer_meters, severity, active_windows, expires_at, external_id FROM zonas_risco WHERE id = $1 AND status = true
 `
 
 func (q *Queries) GetZonaRiscoById(ctx context.Context, id int64) (ZonasRisco, error) {
@@ -121,27 +188,41 @@ func (q *Queries) GetZonaRiscoById(ctx context.Context, id int64) (ZonasRisco, e
 		&i.OrganizationID,
 		&i.ZonaAtencao,
 		&i.Status,
+		&i.Shape,
+		&i.Geometry,
+		&i.BufferMeters,
+		&i.Severity,
+		&i.ActiveWindows,
+		&i.ExpiresAt,
+		&i.ExternalID,
 	)
 	return i, err
 }
 
 const updateZonaRisco = `-- name: UpdateZonaRisco :one
 UPDATE zonas_risco
-SET name = $2, cep = $3, lat = $4, lng = $5, radius = $6, type= $7, organization_id=$8, zona_atencao=$9
+SET name = $2, cep = $3, lat = $4, lng = $5, radius = $6, type= $7, organization_id=$8, zona_atencao=$9, shape = $10, geometry = $11, buffer_meters = $12, severity = $13, active_windows = $14, expires_at = $15, external_id = $16
 WHERE id = $1 and status = true
-RETURNING id, name, cep, lat, lng, radius, type, organization_id, zona_atencao, status
+RETURNING id, name, cep, lat, lng, radius, type, organization_id, zona_atencao, status, shape, geometry, buffer_meters, severity, active_windows, expires_at, external_id
 `
 
 type UpdateZonaRiscoParams struct {
-	ID             int64         `json:"id"`
-	Name           string        `json:"name"`
-	Cep            string        `json:"cep"`
-	Lat            float64       `json:"lat"`
-	Lng            float64       `json:"lng"`
-	Radius         int64         `json:"radius"`
-	Type           sql.NullInt64 `json:"type"`
-	OrganizationID sql.NullInt64 `json:"organization_id"`
-	ZonaAtencao    bool          `json:"zona_atencao"`
+	ID             int64                 `json:"id"`
+	Name           string                `json:"name"`
+	Cep            string                `json:"cep"`
+	Lat            float64               `json:"lat"`
+	Lng            float64               `json:"lng"`
+	Radius         int64                 `json:"radius"`
+	Type           sql.NullInt64         `json:"type"`
+	OrganizationID sql.NullInt64         `json:"organization_id"`
+	ZonaAtencao    bool                  `json:"zona_atencao"`
+	Shape          string                `json:"shape"`
+	Geometry       pqtype.NullRawMessage `json:"geometry"`
+	BufferMeters   int64                 `json:"buffer_meters"`
+	Severity       int64                 `json:"severity"`
+	ActiveWindows  pqtype.NullRawMessage `json:"active_windows"`
+	ExpiresAt      sql.NullTime          `json:"expires_at"`
+	ExternalID     sql.NullString        `json:"external_id"`
 }
 
 func (q *Queries) UpdateZonaRisco(ctx context.Context, arg UpdateZonaRiscoParams) (ZonasRisco, error) {
@@ -155,6 +236,13 @@ func (q *Queries) UpdateZonaRisco(ctx context.Context, arg UpdateZonaRiscoParams
 		arg.Type,
 		arg.OrganizationID,
 		arg.ZonaAtencao,
+		arg.Shape,
+		arg.Geometry,
+		arg.BufferMeters,
+		arg.Severity,
+		arg.ActiveWindows,
+		arg.ExpiresAt,
+		arg.ExternalID,
 	)
 	var i ZonasRisco
 	err := row.Scan(
@@ -168,6 +256,13 @@ func (q *Queries) UpdateZonaRisco(ctx context.Context, arg UpdateZonaRiscoParams
 		&i.OrganizationID,
 		&i.ZonaAtencao,
 		&i.Status,
+		&i.Shape,
+		&i.Geometry,
+		&i.BufferMeters,
+		&i.Severity,
+		&i.ActiveWindows,
+		&i.ExpiresAt,
+		&i.ExternalID,
 	)
 	return i, err
 }
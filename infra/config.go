@@ -2,6 +2,7 @@ package infra
 
 import (
 	"os"
+	"strings"
 
 	"github.com/joho/godotenv"
 )
@@ -39,6 +40,17 @@ type Config struct {
 	EmailPort          string
 	MeiliHttp          string
 	MeiliKey           string
+	RoutingEngine      string
+	RoutingEngineURLs  []string
+	RoutingEngineKey   string
+	POIIndexRefresh    string
+	FreeFlowDueDays    string
+	FreeFlowReminders  string
+	FreeFlowCheckEvery string
+	FuelPriceDir       string
+	FuelPriceScan      string
+	RepricingEvery     string
+	RepricingThreshold string
 }
 
 func NewConfig() Config {
@@ -81,5 +93,16 @@ func NewConfig() Config {
 		AwsBucketName:      os.Getenv("AWS_BUCKET_NAME"),
 		MeiliHttp:          os.Getenv("MEILI_HTTP_ADDR"),
 		MeiliKey:           os.Getenv("MEILI_MASTER_KEY"),
+		RoutingEngine:      os.Getenv("ROUTING_ENGINE"),
+		RoutingEngineURLs:  strings.Split(os.Getenv("ROUTING_ENGINE_URLS"), ","),
+		RoutingEngineKey:   os.Getenv("ROUTING_ENGINE_KEY"),
+		POIIndexRefresh:    os.Getenv("POI_INDEX_REFRESH"),
+		FreeFlowDueDays:    os.Getenv("FREE_FLOW_PAYMENT_DAYS"),
+		FreeFlowReminders:  os.Getenv("FREE_FLOW_REMINDER_DAYS"),
+		FreeFlowCheckEvery: os.Getenv("FREE_FLOW_REMINDER_INTERVAL"),
+		FuelPriceDir:       os.Getenv("FUEL_PRICE_DIR"),
+		FuelPriceScan:      os.Getenv("FUEL_PRICE_SCAN_INTERVAL"),
+		RepricingEvery:     os.Getenv("ROUTE_REPRICING_INTERVAL"),
+		RepricingThreshold: os.Getenv("ROUTE_REPRICING_THRESHOLD"),
 	}
 }
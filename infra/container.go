@@ -1,6 +1,7 @@
 package infra
 
 import (
+	"context"
 	"database/sql"
 	meiliaddress "geolocation/internal/meili_address"
 	"geolocation/internal/route_enterprise"
@@ -14,6 +15,8 @@ import (
 	"geolocation/internal/attachment"
 	"geolocation/internal/dashboard"
 	"geolocation/internal/drivers"
+	"geolocation/internal/free_flow"
+	"geolocation/internal/fuel_price"
 	"geolocation/internal/hist"
 	"geolocation/internal/location"
 	"geolocation/internal/login"
@@ -39,6 +42,8 @@ type ContainerDI struct {
 	RepositoryRoutes          *routes.Repository
 	HandlerNewRoutes          *new_routes.Handler
 	ServiceNewRoutes          *new_routes.Service
+	RoutingEngine             new_routes.RoutingEngine
+	POIIndex                  *new_routes.POIIndex
 	HandlerHist               *hist.Handler
 	ServiceHist               *hist.Service
 	RepositoryHist            *hist.Repository
@@ -93,6 +98,12 @@ type ContainerDI struct {
 	HandlerZonasRisco         *zonas_risco.Handler
 	ServiceZonasRisco         *zonas_risco.Service
 	RepositoryZonasRisco      *zonas_risco.Repository
+	HandlerFreeFlow           *free_flow.Handler
+	ServiceFreeFlow           *free_flow.Service
+	RepositoryFreeFlow        *free_flow.Repository
+	HandlerFuelPrice          *fuel_price.Handler
+	ServiceFuelPrice          *fuel_price.Service
+	RepositoryFuelPrice       *fuel_price.Repository
 }
 
 func NewContainerDI(config Config) *ContainerDI {
@@ -162,13 +173,19 @@ func (c *ContainerDI) buildRepository() {
 	c.RepositoryLocation = location.NewLocationsRepository(c.ConnDB)
 	c.RepositoryRouteEnterprise = route_enterprise.NewRouteEnterpriseRepository(c.ConnDBSP)
 	c.RepositoryZonasRisco = zonas_risco.NewZonasRiscoRepository(c.ConnDB)
+	c.RepositoryFreeFlow = free_flow.NewFreeFlowRepository(c.ConnDB)
+	c.RepositoryFuelPrice = fuel_price.NewFuelPriceRepository(c.ConnDB)
 
 }
 
 func (c *ContainerDI) buildService() {
 	c.ServiceRoutes = routes.NewRoutesService(c.RepositoryRoutes, c.Config.GoogleMapsKey)
-	c.ServiceZonasRisco = zonas_risco.NewZonasRiscoService(c.RepositoryZonasRisco)
-	c.ServiceNewRoutes = new_routes.NewRoutesNewService(c.RepositoryRoutes, c.RepositoryRouteEnterprise, c.Config.GoogleMapsKey, c.ServiceZonasRisco, c.RepositoryAddress)
+	c.ServiceZonasRisco = zonas_risco.NewZonasRiscoService(c.RepositoryZonasRisco, c.RepositoryAddress)
+	c.RoutingEngine = new_routes.NewRoutingEngine(c.Config.RoutingEngine, c.Config.RoutingEngineURLs, c.Config.RoutingEngineKey)
+	c.POIIndex = new_routes.NewPOIIndex(c.RepositoryRoutes, c.Config.POIIndexRefresh)
+	go c.POIIndex.Watch(context.Background())
+	c.ServiceNewRoutes = new_routes.NewRoutesNewService(c.RepositoryRoutes, c.RepositoryRouteEnterprise, c.Config.GoogleMapsKey, c.ServiceZonasRisco, c.RepositoryAddress, c.RoutingEngine, c.RepositoryTractorUnit, c.RepositoryTrailer, c.POIIndex, c.RepositoryAttachment, c.Config.AwsBucketName, c.SendEmail, c.Config.RepricingEvery, c.Config.RepricingThreshold)
+	go c.ServiceNewRoutes.WatchRepricing(context.Background())
 	c.ServiceHist = hist.NewHistService(c.RepositoryHist, c.Config.SignatureToken)
 	c.ServiceDriver = drivers.NewDriversService(c.RepositoryDriver)
 	c.ServiceTractorUnit = tractor_unit.NewTractorUnitsService(c.RepositoryTractorUnit)
@@ -188,7 +205,17 @@ func (c *ContainerDI) buildService() {
 		*c.PasetoMaker,
 		c.Config.GoogleClientId,
 	)
-	c.WsService = ws.NewWsService(c.WsRepository, c.RepositoryAdvertisement, c.ServiceNewRoutes)
+	c.ServiceFreeFlow = free_flow.NewFreeFlowService(
+		c.RepositoryFreeFlow,
+		c.SendEmail,
+		c.Config.FreeFlowDueDays,
+		c.Config.FreeFlowReminders,
+		c.Config.FreeFlowCheckEvery,
+	)
+	go c.ServiceFreeFlow.WatchReminders(context.Background())
+	c.ServiceFuelPrice = fuel_price.NewFuelPriceService(c.RepositoryFuelPrice, c.Config.FuelPriceDir, c.Config.FuelPriceScan)
+	go c.ServiceFuelPrice.WatchImports(context.Background())
+	c.WsService = ws.NewWsService(c.WsRepository, c.RepositoryAdvertisement, c.ServiceNewRoutes, c.ServiceFreeFlow)
 	c.ServiceAppointment = appointments.NewAppointmentsService(c.RepositoryAppointment)
 	c.ServiceAddress = address.NewAddressService(c.RepositoryAddress, c.RepositoryMeiliAddress, c.Config.GoogleMapsKey)
 	c.ServiceLocation = location.NewLocationsService(c.RepositoryLocation)
@@ -215,4 +242,6 @@ func (c *ContainerDI) buildHandler() {
 	c.HandlerAddress = address.NewAddressHandler(c.ServiceAddress)
 	c.HandlerLocation = location.NewLocationHandler(c.ServiceLocation)
 	c.HandlerZonasRisco = zonas_risco.NewZonasRiscoHandler(c.ServiceZonasRisco)
+	c.HandlerFreeFlow = free_flow.NewFreeFlowHandler(c.ServiceFreeFlow)
+	c.HandlerFuelPrice = fuel_price.NewFuelPriceHandler(c.ServiceFuelPrice)
 }
@@ -12,6 +12,7 @@ type InterfaceRepository interface {
 	UpdateAttachmentLogicDelete(ctx context.Context, arg db.UpdateAttachmentLogicDeleteParams) error
 	UpdateProfilePictureByUserId(ctx context.Context, arg db.UpdateProfilePictureByUserIdParams) error
 	GetAllAttachmentById(ctx context.Context, arg db.GetAllAttachmentByIdParams) ([]db.Attachment, error)
+	GetLatestAttachmentByDescription(ctx context.Context, arg db.GetLatestAttachmentByDescriptionParams) (db.Attachment, error)
 }
 type Repository struct {
 	Conn    *sql.DB
@@ -45,3 +46,6 @@ func (r *Repository) UpdateProfilePictureByUserId(ctx context.Context, arg db.Up
 func (r *Repository) GetAllAttachmentById(ctx context.Context, arg db.GetAllAttachmentByIdParams) ([]db.Attachment, error) {
 	return r.Queries.GetAllAttachmentById(ctx, arg)
 }
+func (r *Repository) GetLatestAttachmentByDescription(ctx context.Context, arg db.GetLatestAttachmentByDescriptionParams) (db.Attachment, error) {
+	return r.Queries.GetLatestAttachmentByDescription(ctx, arg)
+}
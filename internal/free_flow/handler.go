@@ -0,0 +1,125 @@
+package free_flow
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"geolocation/internal/get_token"
+	"geolocation/validation"
+)
+
+type Handler struct {
+	InterfaceService InterfaceService
+}
+
+func NewFreeFlowHandler(InterfaceService InterfaceService) *Handler {
+	return &Handler{InterfaceService}
+}
+
+// RegisterPassagesHandler godoc
+// @Summary Registrar passagens free flow
+// @Description Registra as passagens pelos pórticos free flow da rota escolhida, com horário estimado e vencimento do pagamento.
+// @Description organization_id é opcional e precisa ser a organização do token.
+// @Tags FreeFlow
+// @Accept json
+// @Produce json
+// @Param request body RegisterPassagesRequest true "Rota escolhida e início da viagem"
+// @Success 200 {array} PassageResponse "Passagens registradas"
+// @Failure 400 {string} string "Requisição Inválida"
+// @Failure 403 {string} string "Organização não pertence ao usuário"
+// @Failure 500 {string} string "Erro Interno do Servidor"
+// @Router /free-flow/register [post]
+// @Security ApiKeyAuth
+func (h *Handler) RegisterPassagesHandler(c echo.Context) error {
+	var request RegisterPassagesRequest
+	if err := c.Bind(&request); err != nil {
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+
+	if err := validation.Validate(request); err != nil {
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+
+	if request.OrganizationID != 0 && request.OrganizationID != get_token.GetPayloadToken(c).UserOrgId {
+		return c.JSON(http.StatusForbidden, "organização não pertence ao usuário")
+	}
+
+	payload := get_token.GetUserPayloadToken(c)
+	result, err := h.InterfaceService.RegisterPassagesService(c.Request().Context(), RegisterPassagesDTO{
+		Request: request,
+		UserID:  payload.ID,
+	})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+// GetPendingPassagesHandler godoc
+// @Summary Listar pagamentos free flow pendentes
+// @Description Lista as passagens free flow ainda não pagas, ordenadas pelo vencimento: do usuário ou, na rota simpplify,
+// @Description da organização do token. organization_id é opcional e precisa ser a organização do token.
+// @Tags FreeFlow
+// @Accept json
+// @Produce json
+// @Param organization_id query int false "ID da organização"
+// @Success 200 {object} PendingPassagesResponse "Passagens pendentes"
+// @Failure 400 {string} string "Requisição Inválida"
+// @Failure 403 {string} string "Organização não pertence ao usuário"
+// @Failure 500 {string} string "Erro Interno do Servidor"
+// @Router /free-flow/pending [get]
+// @Router /free-flow/pending-simpplify [get]
+// @Security ApiKeyAuth
+func (h *Handler) GetPendingPassagesHandler(c echo.Context) error {
+	// Só o token da organização (simpplify) carrega a organização do usuário; o token de usuário não dá acesso a organizações
+	organizationID := get_token.GetPayloadToken(c).UserOrgId
+	if orgStr := c.QueryParam("organization_id"); orgStr != "" {
+		id, err := validation.ParseStringToInt64(orgStr)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, err.Error())
+		}
+		if id != organizationID {
+			return c.JSON(http.StatusForbidden, "organização não pertence ao usuário")
+		}
+	}
+
+	payload := get_token.GetUserPayloadToken(c)
+	if organizationID == 0 && payload.ID == 0 {
+		return c.JSON(http.StatusBadRequest, "organização não informada")
+	}
+	result, err := h.InterfaceService.GetPendingPassagesService(c.Request().Context(), payload.ID, organizationID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+// MarkPassagePaidHandler godoc
+// @Summary Marcar passagem free flow como paga
+// @Description Marca como paga uma passagem free flow pendente do usuário, encerrando os lembretes.
+// @Tags FreeFlow
+// @Accept json
+// @Produce json
+// @Param id path int true "ID da passagem"
+// @Success 200 {object} PassageResponse "Passagem paga"
+// @Failure 400 {string} string "Requisição Inválida"
+// @Failure 500 {string} string "Erro Interno do Servidor"
+// @Router /free-flow/paid/{id} [put]
+// @Security ApiKeyAuth
+func (h *Handler) MarkPassagePaidHandler(c echo.Context) error {
+	id, err := validation.ParseStringToInt64(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+
+	payload := get_token.GetUserPayloadToken(c)
+	result, err := h.InterfaceService.MarkPassagePaidService(c.Request().Context(), id, payload.ID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,128 @@
+package free_flow
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// recordingService guarda os parâmetros recebidos pelo handler
+type recordingService struct {
+	called         bool
+	userID, orgID  int64
+	registeredOrg  int64
+	registeredUser int64
+}
+
+func (r *recordingService) RegisterPassagesService(_ context.Context, data RegisterPassagesDTO) ([]PassageResponse, error) {
+	r.called, r.registeredOrg, r.registeredUser = true, data.Request.OrganizationID, data.UserID
+	return []PassageResponse{}, nil
+}
+
+func (r *recordingService) RegisterFreightPassagesService(context.Context, int64, int64, time.Time) error {
+	return nil
+}
+
+func (r *recordingService) GetPendingPassagesService(_ context.Context, userID, organizationID int64) (PendingPassagesResponse, error) {
+	r.called, r.userID, r.orgID = true, userID, organizationID
+	return PendingPassagesResponse{}, nil
+}
+
+func (r *recordingService) MarkPassagePaidService(context.Context, int64, int64) (PassageResponse, error) {
+	return PassageResponse{}, nil
+}
+
+func (r *recordingService) SendRemindersService(context.Context) error {
+	return nil
+}
+
+// tokenContext monta o contexto como os middlewares deixam: token de usuário (token_id int64) ou da organização
+func tokenContext(req *http.Request, userID, tokenOrgID int64) (echo.Context, *httptest.ResponseRecorder) {
+	rec := httptest.NewRecorder()
+	c := echo.New().NewContext(req, rec)
+	if userID > 0 {
+		c.Set("token_id", userID)
+	}
+	if tokenOrgID > 0 {
+		c.Set("token_user_org_id", tokenOrgID)
+	}
+	return c, rec
+}
+
+func TestGetPendingPassagesHandlerOrganization(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		userID     int64
+		tokenOrgID int64
+		wantStatus int
+		wantUser   int64
+		wantOrg    int64
+	}{
+		{name: "usuário vê as suas", userID: 7, wantStatus: http.StatusOK, wantUser: 7},
+		{name: "token de usuário não escolhe organização", query: "?organization_id=3", userID: 7, wantStatus: http.StatusForbidden},
+		{name: "organização do token por padrão", tokenOrgID: 3, wantStatus: http.StatusOK, wantOrg: 3},
+		{name: "organização do token informada", query: "?organization_id=3", tokenOrgID: 3, wantStatus: http.StatusOK, wantOrg: 3},
+		{name: "outra organização", query: "?organization_id=4", tokenOrgID: 3, wantStatus: http.StatusForbidden},
+		{name: "organization_id inválido", query: "?organization_id=x", userID: 7, wantStatus: http.StatusBadRequest},
+		{name: "sem usuário nem organização", wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &recordingService{}
+			c, rec := tokenContext(httptest.NewRequest(http.MethodGet, "/free-flow/pending"+tt.query, nil), tt.userID, tt.tokenOrgID)
+			if err := NewFreeFlowHandler(service).GetPendingPassagesHandler(c); err != nil {
+				t.Fatalf("GetPendingPassagesHandler() erro = %v", err)
+			}
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, esperado %d", rec.Code, tt.wantStatus)
+			}
+			if service.called != (tt.wantStatus == http.StatusOK) {
+				t.Fatalf("serviço chamado = %v com status %d", service.called, rec.Code)
+			}
+			if service.userID != tt.wantUser || service.orgID != tt.wantOrg {
+				t.Errorf("serviço recebeu usuário %d e organização %d, esperado %d e %d", service.userID, service.orgID, tt.wantUser, tt.wantOrg)
+			}
+		})
+	}
+}
+
+func TestRegisterPassagesHandlerOrganization(t *testing.T) {
+	tests := []struct {
+		name       string
+		orgID      int64
+		tokenOrgID int64
+		wantStatus int
+	}{
+		{name: "sem organização", wantStatus: http.StatusOK},
+		{name: "organização do token", orgID: 3, tokenOrgID: 3, wantStatus: http.StatusOK},
+		{name: "organização alheia", orgID: 4, tokenOrgID: 3, wantStatus: http.StatusForbidden},
+		{name: "token de usuário não vincula organização", orgID: 3, wantStatus: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := `{"route_hist_id":1,"started_at":"2026-10-16T08:00:00Z","organization_id":` + strconv.FormatInt(tt.orgID, 10) + `}`
+			req := httptest.NewRequest(http.MethodPost, "/free-flow/register", strings.NewReader(body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			service := &recordingService{}
+			c, rec := tokenContext(req, 7, tt.tokenOrgID)
+			if err := NewFreeFlowHandler(service).RegisterPassagesHandler(c); err != nil {
+				t.Fatalf("RegisterPassagesHandler() erro = %v", err)
+			}
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, esperado %d", rec.Code, tt.wantStatus)
+			}
+			if tt.wantStatus == http.StatusOK && (service.registeredOrg != tt.orgID || service.registeredUser != 7) {
+				t.Errorf("serviço recebeu organização %d e usuário %d", service.registeredOrg, service.registeredUser)
+			}
+		})
+	}
+}
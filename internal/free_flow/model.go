@@ -0,0 +1,75 @@
+package free_flow
+
+import (
+	"math"
+	"time"
+
+	db "geolocation/db/sqlc"
+)
+
+const (
+	PassageStatusPending = "pending"
+	PassageStatusPaid    = "paid"
+)
+
+// RegisterPassagesRequest registra as passagens por pórticos free flow da rota escolhida, a partir do início da viagem
+type RegisterPassagesRequest struct {
+	RouteHistID     int64     `json:"route_hist_id" validate:"required"`
+	RouteIndex      int64     `json:"route_index"`
+	AdvertisementID int64     `json:"advertisement_id"`
+	OrganizationID  int64     `json:"organization_id"`
+	StartedAt       time.Time `json:"started_at" validate:"required"`
+}
+
+type RegisterPassagesDTO struct {
+	Request RegisterPassagesRequest
+	UserID  int64
+}
+
+type PassageResponse struct {
+	ID              int64      `json:"id"`
+	AdvertisementID int64      `json:"advertisement_id,omitempty"`
+	OrganizationID  int64      `json:"organization_id,omitempty"`
+	RouteHistID     int64      `json:"route_hist_id"`
+	TollID          int64      `json:"toll_id"`
+	TollName        string     `json:"toll_name"`
+	Concessionaria  string     `json:"concessionaria"`
+	Road            string     `json:"road"`
+	PaymentLink     string     `json:"payment_link"`
+	Amount          float64    `json:"amount"`
+	PassedAt        time.Time  `json:"passed_at"`
+	DueAt           time.Time  `json:"due_at"`
+	DaysLeft        int64      `json:"days_left"`
+	Overdue         bool       `json:"overdue"`
+	Status          string     `json:"status"`
+	PaidAt          *time.Time `json:"paid_at,omitempty"`
+}
+
+type PendingPassagesResponse struct {
+	TotalAmount  float64           `json:"total_amount"`
+	OverdueCount int64             `json:"overdue_count"`
+	Passages     []PassageResponse `json:"passages"`
+}
+
+func (p *PassageResponse) ParseFromDb(result db.FreeFlowPassage, now time.Time) {
+	p.ID = result.ID
+	p.AdvertisementID = result.AdvertisementID.Int64
+	p.OrganizationID = result.OrganizationID.Int64
+	p.RouteHistID = result.RouteHistID
+	p.TollID = result.TollID
+	p.TollName = result.TollName
+	p.Concessionaria = result.Concessionaria
+	p.Road = result.Road
+	p.PaymentLink = result.PaymentLink
+	p.Amount = result.Amount
+	p.PassedAt = result.PassedAt
+	p.DueAt = result.DueAt
+	p.Status = result.Status
+	if result.PaidAt.Valid {
+		p.PaidAt = &result.PaidAt.Time
+	}
+	if result.Status == PassageStatusPending {
+		p.DaysLeft = int64(math.Ceil(result.DueAt.Sub(now).Hours() / 24))
+		p.Overdue = !result.DueAt.After(now)
+	}
+}
@@ -0,0 +1,85 @@
+package free_flow
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	db "geolocation/db/sqlc"
+)
+
+type InterfaceRepository interface {
+	CreateFreeFlowPassage(ctx context.Context, arg db.CreateFreeFlowPassageParams) (db.FreeFlowPassage, error)
+	CountFreeFlowPassagesByAdvertisement(ctx context.Context, advertisementID int64) (int64, error)
+	GetPendingFreeFlowPassagesByUser(ctx context.Context, userID int64) ([]db.FreeFlowPassage, error)
+	GetPendingFreeFlowPassagesByOrganization(ctx context.Context, organizationID int64) ([]db.FreeFlowPassage, error)
+	MarkFreeFlowPassagePaid(ctx context.Context, arg db.MarkFreeFlowPassagePaidParams) (db.FreeFlowPassage, error)
+	GetFreeFlowPassagesDueForReminder(ctx context.Context, dueAt time.Time) ([]db.GetFreeFlowPassagesDueForReminderRow, error)
+	GetFreeFlowPassagesByRouteHist(ctx context.Context, userID, routeHistID int64) ([]db.FreeFlowPassage, error)
+	UpdateFreeFlowPassageReminder(ctx context.Context, id int64, remindersSent int32) error
+	GetFreightRouteByAdvertisement(ctx context.Context, advertisementID int64) (db.GetFreightRouteByAdvertisementRow, error)
+	GetRouteHistByID(ctx context.Context, id int64) (db.RouteHist, error)
+}
+
+type Repository struct {
+	Conn    *sql.DB
+	DBtx    db.DBTX
+	Queries *db.Queries
+	SqlConn *sql.DB
+}
+
+func NewFreeFlowRepository(Conn *sql.DB) *Repository {
+	q := db.New(Conn)
+	return &Repository{
+		Conn:    Conn,
+		DBtx:    Conn,
+		Queries: q,
+		SqlConn: Conn,
+	}
+}
+
+func (r *Repository) CreateFreeFlowPassage(ctx context.Context, arg db.CreateFreeFlowPassageParams) (db.FreeFlowPassage, error) {
+	return r.Queries.CreateFreeFlowPassage(ctx, arg)
+}
+
+func (r *Repository) CountFreeFlowPassagesByAdvertisement(ctx context.Context, advertisementID int64) (int64, error) {
+	return r.Queries.CountFreeFlowPassagesByAdvertisement(ctx, sql.NullInt64{Int64: advertisementID, Valid: true})
+}
+
+func (r *Repository) GetPendingFreeFlowPassagesByUser(ctx context.Context, userID int64) ([]db.FreeFlowPassage, error) {
+	return r.Queries.GetPendingFreeFlowPassagesByUser(ctx, userID)
+}
+
+func (r *Repository) GetPendingFreeFlowPassagesByOrganization(ctx context.Context, organizationID int64) ([]db.FreeFlowPassage, error) {
+	return r.Queries.GetPendingFreeFlowPassagesByOrganization(ctx, sql.NullInt64{Int64: organizationID, Valid: true})
+}
+
+func (r *Repository) MarkFreeFlowPassagePaid(ctx context.Context, arg db.MarkFreeFlowPassagePaidParams) (db.FreeFlowPassage, error) {
+	return r.Queries.MarkFreeFlowPassagePaid(ctx, arg)
+}
+
+func (r *Repository) GetFreeFlowPassagesDueForReminder(ctx context.Context, dueAt time.Time) ([]db.GetFreeFlowPassagesDueForReminderRow, error) {
+	return r.Queries.GetFreeFlowPassagesDueForReminder(ctx, dueAt)
+}
+
+func (r *Repository) GetFreeFlowPassagesByRouteHist(ctx context.Context, userID, routeHistID int64) ([]db.FreeFlowPassage, error) {
+	return r.Queries.GetFreeFlowPassagesByRouteHist(ctx, db.GetFreeFlowPassagesByRouteHistParams{
+		UserID:      userID,
+		RouteHistID: routeHistID,
+	})
+}
+
+func (r *Repository) UpdateFreeFlowPassageReminder(ctx context.Context, id int64, remindersSent int32) error {
+	return r.Queries.UpdateFreeFlowPassageReminder(ctx, db.UpdateFreeFlowPassageReminderParams{
+		ID:            id,
+		RemindersSent: remindersSent,
+	})
+}
+
+func (r *Repository) GetFreightRouteByAdvertisement(ctx context.Context, advertisementID int64) (db.GetFreightRouteByAdvertisementRow, error) {
+	return r.Queries.GetFreightRouteByAdvertisement(ctx, advertisementID)
+}
+
+func (r *Repository) GetRouteHistByID(ctx context.Context, id int64) (db.RouteHist, error) {
+	return r.Queries.GetRouteHistByID(ctx, id)
+}
@@ -0,0 +1,347 @@
+package free_flow
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	db "geolocation/db/sqlc"
+	new_routes "geolocation/internal/new_routes"
+	"geolocation/pkg/email"
+)
+
+const (
+	// defaultPaymentDays é o prazo padrão, em dias, para pagar a passagem no free flow sem multa
+	defaultPaymentDays = 30
+	// defaultReminderInterval é o intervalo padrão entre as verificações de lembretes
+	defaultReminderInterval = time.Hour
+)
+
+// defaultReminderDays são os dias antes do vencimento em que um lembrete é enviado
+var defaultReminderDays = []int{7, 3, 1}
+
+type InterfaceService interface {
+	RegisterPassagesService(ctx context.Context, data RegisterPassagesDTO) ([]PassageResponse, error)
+	RegisterFreightPassagesService(ctx context.Context, advertisementID, userID int64, startedAt time.Time) error
+	GetPendingPassagesService(ctx context.Context, userID, organizationID int64) (PendingPassagesResponse, error)
+	MarkPassagePaidService(ctx context.Context, id, userID int64) (PassageResponse, error)
+	SendRemindersService(ctx context.Context) error
+}
+
+type Service struct {
+	InterfaceService InterfaceRepository
+	sendEmail        *email.SendEmail
+	paymentDays      int
+	reminderDays     []int
+	reminderInterval time.Duration
+}
+
+// NewFreeFlowService cria o serviço. paymentDays é o prazo de pagamento em dias, reminderDays a lista de
+// dias antes do vencimento para os lembretes ("7,3,1") e reminderInterval a frequência da verificação ("1h");
+// valores vazios ou inválidos usam os padrões.
+func NewFreeFlowService(InterfaceService InterfaceRepository, sendEmail *email.SendEmail, paymentDays, reminderDays, reminderInterval string) *Service {
+	s := &Service{
+		InterfaceService: InterfaceService,
+		sendEmail:        sendEmail,
+		paymentDays:      defaultPaymentDays,
+		reminderDays:     defaultReminderDays,
+		reminderInterval: defaultReminderInterval,
+	}
+	if paymentDays != "" {
+		if d, err := strconv.Atoi(paymentDays); err == nil && d > 0 {
+			s.paymentDays = d
+		} else {
+			log.Printf("FREE_FLOW_PAYMENT_DAYS inválido (%q), usando %d", paymentDays, defaultPaymentDays)
+		}
+	}
+	if reminderDays != "" {
+		var days []int
+		for _, part := range strings.Split(reminderDays, ",") {
+			if d, err := strconv.Atoi(strings.TrimSpace(part)); err == nil && d > 0 {
+				days = append(days, d)
+			}
+		}
+		if len(days) > 0 {
+			s.reminderDays = days
+		} else {
+			log.Printf("FREE_FLOW_REMINDER_DAYS inválido (%q), usando %v", reminderDays, defaultReminderDays)
+		}
+	}
+	if reminderInterval != "" {
+		if d, err := time.ParseDuration(reminderInterval); err == nil && d > 0 {
+			s.reminderInterval = d
+		} else {
+			log.Printf("FREE_FLOW_REMINDER_INTERVAL inválido (%q), usando %s", reminderInterval, defaultReminderInterval)
+		}
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(s.reminderDays)))
+	return s
+}
+
+// RegisterPassagesService registra uma passagem para cada pórtico free flow da rota escolhida, que precisa
+// pertencer ao usuário. O horário da passagem é o início da viagem somado à chegada estimada ao pórtico, e o
+// vencimento conta a partir dele. Se a rota já tiver passagens do usuário, devolve as já registradas.
+func (s *Service) RegisterPassagesService(ctx context.Context, data RegisterPassagesDTO) ([]PassageResponse, error) {
+	routeHist, err := s.InterfaceService.GetRouteHistByID(ctx, data.Request.RouteHistID)
+	if err == nil && routeHist.IDUser != data.UserID {
+		err = sql.ErrNoRows
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errors.New("rota não encontrada")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar rota: %w", err)
+	}
+	return s.registerPassages(ctx, routeHist, data)
+}
+
+// registerPassages registra as passagens da rota para data.UserID sem verificar o dono da rota, já que no frete
+// quem executa a viagem é o motorista e não o anunciante
+func (s *Service) registerPassages(ctx context.Context, routeHist db.RouteHist, data RegisterPassagesDTO) ([]PassageResponse, error) {
+	now := time.Now()
+	existing, err := s.InterfaceService.GetFreeFlowPassagesByRouteHist(ctx, data.UserID, routeHist.ID)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar passagens da rota: %w", err)
+	}
+	if len(existing) > 0 {
+		passages := make([]PassageResponse, 0, len(existing))
+		for _, result := range existing {
+			var response PassageResponse
+			response.ParseFromDb(result, now)
+			passages = append(passages, response)
+		}
+		return passages, nil
+	}
+
+	var output new_routes.FinalOutput
+	if err := json.Unmarshal(routeHist.Response, &output); err != nil {
+		return nil, fmt.Errorf("erro ao ler rota: %w", err)
+	}
+	index := int(data.Request.RouteIndex)
+	if index < 0 || index >= len(output.Routes) {
+		return nil, errors.New("rota escolhida inválida")
+	}
+
+	passages := []PassageResponse{}
+	for _, toll := range output.Routes[index].Tolls {
+		if !toll.FreeFlow {
+			continue
+		}
+
+		// Rotas salvas antes da chegada por rota não têm duração; nesse caso usa o início da viagem
+		passedAt := data.Request.StartedAt.Add(time.Duration(toll.ArrivalResponse.DurationSeconds * float64(time.Second)))
+		amount := toll.PaidCost
+		if amount == 0 {
+			amount = toll.CashCost
+		}
+
+		passage, err := s.InterfaceService.CreateFreeFlowPassage(ctx, db.CreateFreeFlowPassageParams{
+			UserID:          data.UserID,
+			OrganizationID:  sql.NullInt64{Int64: data.Request.OrganizationID, Valid: data.Request.OrganizationID > 0},
+			AdvertisementID: sql.NullInt64{Int64: data.Request.AdvertisementID, Valid: data.Request.AdvertisementID > 0},
+			RouteHistID:     routeHist.ID,
+			RouteIndex:      int32(index),
+			TollID:          int64(toll.ID),
+			TollName:        toll.Name,
+			Concessionaria:  toll.Concession,
+			Road:            toll.Road,
+			PaymentLink:     toll.PayFreeFlow,
+			Amount:          amount,
+			PassedAt:        passedAt,
+			DueAt:           passedAt.AddDate(0, 0, s.paymentDays),
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			// Passagem já registrada por outra requisição concorrente
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("erro ao registrar passagem free flow: %w", err)
+		}
+
+		var response PassageResponse
+		response.ParseFromDb(passage, now)
+		passages = append(passages, response)
+	}
+	return passages, nil
+}
+
+// RegisterFreightPassagesService registra as passagens free flow da rota escolhida no anúncio quando o frete
+// começa a ser executado. Não registra de novo se o frete já tiver passagens.
+func (s *Service) RegisterFreightPassagesService(ctx context.Context, advertisementID, userID int64, startedAt time.Time) error {
+	count, err := s.InterfaceService.CountFreeFlowPassagesByAdvertisement(ctx, advertisementID)
+	if err != nil {
+		return fmt.Errorf("erro ao verificar passagens do frete: %w", err)
+	}
+	if count > 0 {
+		return nil
+	}
+
+	freightRoute, err := s.InterfaceService.GetFreightRouteByAdvertisement(ctx, advertisementID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("erro ao buscar rota do frete: %w", err)
+	}
+
+	routeHist, err := s.InterfaceService.GetRouteHistByID(ctx, freightRoute.RouteHistID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("erro ao buscar rota do frete: %w", err)
+	}
+
+	_, err = s.registerPassages(ctx, routeHist, RegisterPassagesDTO{
+		Request: RegisterPassagesRequest{
+			RouteHistID:     freightRoute.RouteHistID,
+			RouteIndex:      freightRoute.RouteChoose,
+			AdvertisementID: advertisementID,
+			StartedAt:       startedAt,
+		},
+		UserID: userID,
+	})
+	return err
+}
+
+// GetPendingPassagesService lista as passagens ainda não pagas do usuário ou, se informada, da organização
+func (s *Service) GetPendingPassagesService(ctx context.Context, userID, organizationID int64) (PendingPassagesResponse, error) {
+	var results []db.FreeFlowPassage
+	var err error
+	if organizationID > 0 {
+		results, err = s.InterfaceService.GetPendingFreeFlowPassagesByOrganization(ctx, organizationID)
+	} else {
+		results, err = s.InterfaceService.GetPendingFreeFlowPassagesByUser(ctx, userID)
+	}
+	if err != nil {
+		return PendingPassagesResponse{}, fmt.Errorf("erro ao buscar passagens pendentes: %w", err)
+	}
+
+	now := time.Now()
+	response := PendingPassagesResponse{Passages: []PassageResponse{}}
+	for _, result := range results {
+		var passage PassageResponse
+		passage.ParseFromDb(result, now)
+		response.TotalAmount += passage.Amount
+		if passage.Overdue {
+			response.OverdueCount++
+		}
+		response.Passages = append(response.Passages, passage)
+	}
+	response.TotalAmount = math.Round(response.TotalAmount*100) / 100
+	return response, nil
+}
+
+func (s *Service) MarkPassagePaidService(ctx context.Context, id, userID int64) (PassageResponse, error) {
+	result, err := s.InterfaceService.MarkFreeFlowPassagePaid(ctx, db.MarkFreeFlowPassagePaidParams{
+		ID:     id,
+		UserID: userID,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return PassageResponse{}, errors.New("passagem pendente não encontrada")
+	}
+	if err != nil {
+		return PassageResponse{}, fmt.Errorf("erro ao marcar passagem como paga: %w", err)
+	}
+
+	var response PassageResponse
+	response.ParseFromDb(result, time.Now())
+	return response, nil
+}
+
+// SendRemindersService envia um e-mail por usuário com as passagens que atingiram um dos dias de lembrete
+// antes do vencimento. Cada passagem recebe no máximo um lembrete por vez, e os marcos já ultrapassados são
+// dados como enviados para que uma passagem registrada tarde não receba vários e-mails seguidos.
+func (s *Service) SendRemindersService(ctx context.Context) error {
+	now := time.Now()
+	until := now.AddDate(0, 0, s.reminderDays[0])
+	rows, err := s.InterfaceService.GetFreeFlowPassagesDueForReminder(ctx, until)
+	if err != nil {
+		return fmt.Errorf("erro ao buscar passagens a vencer: %w", err)
+	}
+
+	byUser := make(map[int64][]db.GetFreeFlowPassagesDueForReminderRow)
+	dueByPassage := make(map[int64]int)
+	var users []int64
+	for _, row := range rows {
+		due := s.remindersDue(row.DueAt.Sub(now))
+		if int(row.RemindersSent) >= due {
+			continue
+		}
+		dueByPassage[row.ID] = due
+		if _, ok := byUser[row.UserID]; !ok {
+			users = append(users, row.UserID)
+		}
+		byUser[row.UserID] = append(byUser[row.UserID], row)
+	}
+
+	for _, userID := range users {
+		passages := byUser[userID]
+		if err := s.sendReminder(passages); err != nil {
+			log.Printf("Erro ao enviar lembrete de free flow para o usuário %d: %v", userID, err)
+			continue
+		}
+		for _, p := range passages {
+			if err := s.InterfaceService.UpdateFreeFlowPassageReminder(ctx, p.ID, int32(dueByPassage[p.ID])); err != nil {
+				log.Printf("Erro ao registrar lembrete da passagem %d: %v", p.ID, err)
+			}
+		}
+	}
+	return nil
+}
+
+// WatchReminders verifica periodicamente as passagens a vencer até o contexto ser cancelado
+func (s *Service) WatchReminders(ctx context.Context) {
+	ticker := time.NewTicker(s.reminderInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.SendRemindersService(ctx); err != nil {
+				log.Printf("Erro ao enviar lembretes de free flow: %v", err)
+			}
+		}
+	}
+}
+
+// remindersDue conta quantos marcos de lembrete já foram atingidos com o tempo restante até o vencimento
+func (s *Service) remindersDue(left time.Duration) int {
+	due := 0
+	for _, days := range s.reminderDays {
+		if left <= time.Duration(days)*24*time.Hour {
+			due++
+		}
+	}
+	return due
+}
+
+func (s *Service) sendReminder(passages []db.GetFreeFlowPassagesDueForReminderRow) error {
+	placeHolder := email.EmailPlaceHolder{NameProvider: passages[0].UserName}
+	for _, p := range passages {
+		placeHolder.FreeFlowPassages = append(placeHolder.FreeFlowPassages, email.FreeFlowPassagePlaceHolder{
+			TollName:       p.TollName,
+			Concessionaria: p.Concessionaria,
+			Road:           p.Road,
+			Amount:         fmt.Sprintf("R$ %.2f", p.Amount),
+			PassedAt:       p.PassedAt.Format("02/01/2006 15:04"),
+			DueAt:          p.DueAt.Format("02/01/2006"),
+			Link:           p.PaymentLink,
+		})
+	}
+
+	tmp, err := s.sendEmail.NewTemplate(placeHolder, "free_flow_reminder.html")
+	if err != nil {
+		return err
+	}
+	return s.sendEmail.SendEmailNew(*tmp, passages[0].UserEmail, "Pagamento de pedágio free flow")
+}
@@ -0,0 +1,41 @@
+package free_flow
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRemindersDue(t *testing.T) {
+	s := NewFreeFlowService(nil, nil, "", "", "")
+	day := 24 * time.Hour
+
+	tests := []struct {
+		name string
+		left time.Duration
+		want int
+	}{
+		{name: "longe do vencimento", left: 10 * day, want: 0},
+		{name: "exatamente no primeiro marco", left: 7 * day, want: 1},
+		{name: "entre o primeiro e o segundo marco", left: 5 * day, want: 1},
+		{name: "no segundo marco", left: 3 * day, want: 2},
+		{name: "no último marco", left: 12 * time.Hour, want: 3},
+		{name: "já vencida", left: -day, want: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := s.remindersDue(tt.left); got != tt.want {
+				t.Errorf("remindersDue(%s) = %d, esperado %d", tt.left, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRemindersDueCustomDays(t *testing.T) {
+	s := NewFreeFlowService(nil, nil, "", "1, 5", "")
+	if got := s.remindersDue(2 * 24 * time.Hour); got != 1 {
+		t.Errorf("remindersDue com marcos 5,1 = %d, esperado 1", got)
+	}
+	if got := s.remindersDue(0); got != 2 {
+		t.Errorf("remindersDue no vencimento = %d, esperado 2", got)
+	}
+}
@@ -0,0 +1,62 @@
+package fuel_price
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+type Handler struct {
+	InterfaceService InterfaceService
+}
+
+func NewFuelPriceHandler(InterfaceService InterfaceService) *Handler {
+	return &Handler{InterfaceService}
+}
+
+// GetLatestPricesHandler godoc
+// @Summary Listar preços de diesel da ANP
+// @Description Lista o preço médio mais recente de diesel S10/S500 por município, importado do levantamento semanal da ANP.
+// @Tags FuelPrice
+// @Accept json
+// @Produce json
+// @Param product query string false "diesel_s10 ou diesel_s500"
+// @Param state query string false "Sigla do estado (SP)"
+// @Param municipio query string false "Nome do município"
+// @Success 200 {array} FuelPriceResponse "Preços mais recentes"
+// @Failure 400 {string} string "Requisição Inválida"
+// @Failure 500 {string} string "Erro Interno do Servidor"
+// @Router /fuel-price/latest [get]
+// @Security ApiKeyAuth
+func (h *Handler) GetLatestPricesHandler(c echo.Context) error {
+	product := c.QueryParam("product")
+	if product != "" && product != ProductDieselS10 && product != ProductDieselS500 {
+		return c.JSON(http.StatusBadRequest, "product deve ser diesel_s10 ou diesel_s500")
+	}
+
+	result, err := h.InterfaceService.GetLatestPricesService(c.Request().Context(), product, c.QueryParam("state"), c.QueryParam("municipio"))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+// ImportPricesHandler godoc
+// @Summary Importar preços de diesel da ANP
+// @Description Importa agora os arquivos CSV/XLSX da pasta configurada em FUEL_PRICE_DIR, sem esperar a verificação periódica.
+// @Tags FuelPrice
+// @Accept json
+// @Produce json
+// @Success 200 {array} ImportResult "Arquivos processados"
+// @Failure 500 {string} string "Erro Interno do Servidor"
+// @Router /fuel-price/import [post]
+// @Security ApiKeyAuth
+func (h *Handler) ImportPricesHandler(c echo.Context) error {
+	result, err := h.InterfaceService.ImportDirService(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
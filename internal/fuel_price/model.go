@@ -0,0 +1,56 @@
+package fuel_price
+
+import (
+	"time"
+
+	db "geolocation/db/sqlc"
+)
+
+const (
+	ProductDieselS10  = "diesel_s10"
+	ProductDieselS500 = "diesel_s500"
+)
+
+type FuelPriceResponse struct {
+	Product          string    `json:"product"`
+	State            string    `json:"state"`
+	Municipio        string    `json:"municipio"`
+	AveragePrice     float64   `json:"average_price"`
+	MinPrice         float64   `json:"min_price"`
+	MaxPrice         float64   `json:"max_price"`
+	StationsSurveyed int32     `json:"stations_surveyed"`
+	SurveyStart      time.Time `json:"survey_start"`
+	SurveyEnd        time.Time `json:"survey_end"`
+}
+
+type ImportResult struct {
+	FileName string `json:"file_name"`
+	Rows     int    `json:"rows"`
+	Skipped  bool   `json:"skipped"`
+}
+
+// priceObservation é uma linha lida do arquivo da ANP: o resumo semanal de um município
+// ou a coleta de um posto, que é agregada por município e semana
+type priceObservation struct {
+	product   string
+	state     string
+	municipio string
+	start     time.Time
+	end       time.Time
+	average   float64
+	min       float64
+	max       float64
+	stations  int32
+}
+
+func (p *FuelPriceResponse) ParseFromDb(result db.FuelPrice) {
+	p.Product = result.Product
+	p.State = result.State
+	p.Municipio = result.Municipio
+	p.AveragePrice = result.AveragePrice
+	p.MinPrice = result.MinPrice
+	p.MaxPrice = result.MaxPrice
+	p.StationsSurveyed = result.StationsSurveyed
+	p.SurveyStart = result.SurveyStart
+	p.SurveyEnd = result.SurveyEnd
+}
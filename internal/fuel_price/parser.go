@@ -0,0 +1,404 @@
+package fuel_price
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"geolocation/validation"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+// headerSearchRows é quantas linhas iniciais são procuradas pelo cabeçalho (as planilhas da ANP têm título antes)
+const headerSearchRows = 30
+
+// stateCodes converte o nome do estado usado nas planilhas da ANP para a sigla
+var stateCodes = map[string]string{
+	"ACRE": "AC", "ALAGOAS": "AL", "AMAPA": "AP", "AMAZONAS": "AM", "BAHIA": "BA", "CEARA": "CE",
+	"DISTRITO FEDERAL": "DF", "ESPIRITO SANTO": "ES", "GOIAS": "GO", "MARANHAO": "MA", "MATO GROSSO": "MT",
+	"MATO GROSSO DO SUL": "MS", "MINAS GERAIS": "MG", "PARA": "PA", "PARAIBA": "PB", "PARANA": "PR",
+	"PERNAMBUCO": "PE", "PIAUI": "PI", "RIO DE JANEIRO": "RJ", "RIO GRANDE DO NORTE": "RN",
+	"RIO GRANDE DO SUL": "RS", "RONDONIA": "RO", "RORAIMA": "RR", "SANTA CATARINA": "SC",
+	"SAO PAULO": "SP", "SERGIPE": "SE", "TOCANTINS": "TO",
+}
+
+// priceColumns são as posições das colunas usadas, encontradas pelo cabeçalho; -1 quando ausente
+type priceColumns struct {
+	product, state, municipio, start, end, date, average, min, max, stations int
+}
+
+// parsePriceFile lê o levantamento semanal (resumo por município) ou a série histórica por posto da ANP,
+// em CSV ou XLSX, e devolve os preços de diesel S10/S500 agregados por município e semana
+func parsePriceFile(name string, content []byte) ([]priceObservation, error) {
+	var rows [][]string
+	var err error
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".csv":
+		rows, err = readCSV(content)
+	case ".xlsx":
+		rows, err = readXLSX(content)
+	default:
+		return nil, fmt.Errorf("formato não suportado: %s", name)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	header, columns, err := findHeader(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	var observations []priceObservation
+	for _, row := range rows[header+1:] {
+		observation, ok := parseRow(row, columns)
+		if ok {
+			observations = append(observations, observation)
+		}
+	}
+	if len(observations) == 0 {
+		return nil, errors.New("nenhum preço de diesel encontrado no arquivo")
+	}
+	return aggregateObservations(observations), nil
+}
+
+func findHeader(rows [][]string) (int, priceColumns, error) {
+	for i := 0; i < len(rows) && i < headerSearchRows; i++ {
+		columns := priceColumns{-1, -1, -1, -1, -1, -1, -1, -1, -1, -1}
+		for j, cell := range rows[i] {
+			switch validation.NormalizeName(cell) {
+			case "PRODUTO":
+				columns.product = j
+			case "ESTADO", "ESTADO - SIGLA", "UF":
+				columns.state = j
+			case "MUNICIPIO":
+				columns.municipio = j
+			case "DATA INICIAL":
+				columns.start = j
+			case "DATA FINAL":
+				columns.end = j
+			case "DATA DA COLETA":
+				columns.date = j
+			case "PRECO MEDIO REVENDA", "VALOR DE VENDA":
+				columns.average = j
+			case "PRECO MINIMO REVENDA":
+				columns.min = j
+			case "PRECO MAXIMO REVENDA":
+				columns.max = j
+			case "NUMERO DE POSTOS PESQUISADOS":
+				columns.stations = j
+			}
+		}
+		if columns.product >= 0 && columns.state >= 0 && columns.municipio >= 0 && columns.average >= 0 &&
+			(columns.end >= 0 || columns.date >= 0) {
+			return i, columns, nil
+		}
+	}
+	return 0, priceColumns{}, errors.New("cabeçalho do levantamento da ANP não encontrado (produto, estado, município, preço e data)")
+}
+
+func parseRow(row []string, columns priceColumns) (priceObservation, bool) {
+	cell := func(i int) string {
+		if i < 0 || i >= len(row) {
+			return ""
+		}
+		return strings.TrimSpace(row[i])
+	}
+
+	product := productCode(cell(columns.product))
+	state := stateCode(cell(columns.state))
+	municipio := cell(columns.municipio)
+	average, err := parseNumber(cell(columns.average))
+	if product == "" || state == "" || municipio == "" || err != nil || average <= 0 {
+		return priceObservation{}, false
+	}
+
+	observation := priceObservation{product: product, state: state, municipio: municipio, average: average, min: average, max: average, stations: 1}
+	if columns.end >= 0 {
+		end, err := parseDate(cell(columns.end))
+		if err != nil {
+			return priceObservation{}, false
+		}
+		start, err := parseDate(cell(columns.start))
+		if err != nil {
+			start = end.AddDate(0, 0, -6)
+		}
+		observation.start, observation.end = start, end
+	} else {
+		// Coleta por posto: agrupa na semana do levantamento (domingo a sábado)
+		date, err := parseDate(cell(columns.date))
+		if err != nil {
+			return priceObservation{}, false
+		}
+		observation.start = date.AddDate(0, 0, -int(date.Weekday()))
+		observation.end = observation.start.AddDate(0, 0, 6)
+	}
+	if v, err := parseNumber(cell(columns.min)); err == nil && v > 0 {
+		observation.min = v
+	}
+	if v, err := parseNumber(cell(columns.max)); err == nil && v > 0 {
+		observation.max = v
+	}
+	if v, err := parseNumber(cell(columns.stations)); err == nil && v > 0 {
+		observation.stations = int32(v)
+	}
+	return observation, true
+}
+
+// aggregateObservations junta as linhas do mesmo produto, município e semana; a média é ponderada pelos postos
+func aggregateObservations(observations []priceObservation) []priceObservation {
+	index := make(map[string]int)
+	var result []priceObservation
+	var weighted []float64
+	for _, o := range observations {
+		key := o.product + "|" + o.state + "|" + validation.NormalizeName(o.municipio) + "|" + o.end.Format("2006-01-02")
+		i, ok := index[key]
+		if !ok {
+			index[key] = len(result)
+			result = append(result, o)
+			weighted = append(weighted, o.average*float64(o.stations))
+			continue
+		}
+		agg := &result[i]
+		weighted[i] += o.average * float64(o.stations)
+		agg.stations += o.stations
+		if o.min < agg.min {
+			agg.min = o.min
+		}
+		if o.max > agg.max {
+			agg.max = o.max
+		}
+		if o.start.Before(agg.start) {
+			agg.start = o.start
+		}
+	}
+	for i := range result {
+		result[i].average = math.Round(weighted[i]/float64(result[i].stations)*1000) / 1000
+	}
+	return result
+}
+
+// productCode identifica o diesel S10 e o S500 ("ÓLEO DIESEL", "DIESEL S500", "ÓLEO DIESEL S10"...)
+func productCode(product string) string {
+	p := validation.NormalizeName(product)
+	if !strings.Contains(p, "DIESEL") {
+		return ""
+	}
+	switch {
+	case strings.Contains(p, "S10") && !strings.Contains(p, "S100"):
+		return ProductDieselS10
+	case strings.Contains(p, "S500"), p == "DIESEL", p == "OLEO DIESEL":
+		return ProductDieselS500
+	}
+	return ""
+}
+
+func stateCode(state string) string {
+	s := validation.NormalizeName(state)
+	if len(s) == 2 {
+		return s
+	}
+	return stateCodes[s]
+}
+
+// parseNumber aceita "5,89", "5.89" e "1.234,56"
+func parseNumber(value string) (float64, error) {
+	value = strings.TrimSpace(value)
+	if value == "" || value == "-" {
+		return 0, errors.New("valor vazio")
+	}
+	if strings.Contains(value, ",") {
+		value = strings.ReplaceAll(value, ".", "")
+		value = strings.ReplaceAll(value, ",", ".")
+	}
+	return strconv.ParseFloat(value, 64)
+}
+
+// parseDate aceita datas em texto (02/01/2006, 2006-01-02) e o número serial das planilhas Excel
+func parseDate(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	for _, layout := range []string{"02/01/2006", "2006-01-02", "2006-01-02 15:04:05", "2006-01-02T15:04:05"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	if serial, err := strconv.ParseFloat(value, 64); err == nil && serial > 0 {
+		return time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC).AddDate(0, 0, int(serial)), nil
+	}
+	return time.Time{}, fmt.Errorf("data inválida: %q", value)
+}
+
+// readCSV lê o CSV da ANP, que costuma vir em ISO-8859-1 e separado por ponto e vírgula
+func readCSV(content []byte) ([][]string, error) {
+	if !utf8.Valid(content) {
+		decoded, err := charmap.ISO8859_1.NewDecoder().Bytes(content)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao converter o arquivo para UTF-8: %w", err)
+		}
+		content = decoded
+	}
+	content = bytes.TrimPrefix(content, []byte("\xef\xbb\xbf"))
+
+	firstLine := content
+	if i := bytes.IndexByte(content, '\n'); i >= 0 {
+		firstLine = content[:i]
+	}
+	reader := csv.NewReader(bytes.NewReader(content))
+	reader.Comma = ','
+	if bytes.Count(firstLine, []byte(";")) > bytes.Count(firstLine, []byte(",")) {
+		reader.Comma = ';'
+	}
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler CSV: %w", err)
+	}
+	return rows, nil
+}
+
+type xlsxSharedStrings struct {
+	Items []struct {
+		Text string `xml:"t"`
+		Runs []struct {
+			Text string `xml:"t"`
+		} `xml:"r"`
+	} `xml:"si"`
+}
+
+type xlsxWorksheet struct {
+	Rows []struct {
+		Cells []struct {
+			Ref    string `xml:"r,attr"`
+			Type   string `xml:"t,attr"`
+			Value  string `xml:"v"`
+			Inline struct {
+				Text string `xml:"t"`
+			} `xml:"is"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+// readXLSX lê a primeira planilha de um arquivo XLSX (zip com XML) sem depender de biblioteca externa
+func readXLSX(content []byte) ([][]string, error) {
+	archive, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		return nil, fmt.Errorf("erro ao abrir XLSX: %w", err)
+	}
+
+	var sheets []*zip.File
+	var sharedFile *zip.File
+	for _, f := range archive.File {
+		switch {
+		case f.Name == "xl/sharedStrings.xml":
+			sharedFile = f
+		case strings.HasPrefix(f.Name, "xl/worksheets/") && strings.HasSuffix(f.Name, ".xml"):
+			sheets = append(sheets, f)
+		}
+	}
+	if len(sheets) == 0 {
+		return nil, errors.New("XLSX sem planilhas")
+	}
+	sort.Slice(sheets, func(i, j int) bool {
+		return sheetNumber(sheets[i].Name) < sheetNumber(sheets[j].Name)
+	})
+
+	var shared []string
+	if sharedFile != nil {
+		var sst xlsxSharedStrings
+		if err := decodeZipXML(sharedFile, &sst); err != nil {
+			return nil, fmt.Errorf("erro ao ler textos do XLSX: %w", err)
+		}
+		shared = make([]string, len(sst.Items))
+		for i, item := range sst.Items {
+			text := item.Text
+			for _, run := range item.Runs {
+				text += run.Text
+			}
+			shared[i] = text
+		}
+	}
+
+	var sheet xlsxWorksheet
+	if err := decodeZipXML(sheets[0], &sheet); err != nil {
+		return nil, fmt.Errorf("erro ao ler planilha do XLSX: %w", err)
+	}
+
+	rows := make([][]string, 0, len(sheet.Rows))
+	for _, r := range sheet.Rows {
+		var row []string
+		for i, c := range r.Cells {
+			col := columnIndex(c.Ref)
+			if col < 0 {
+				col = i
+			}
+			for len(row) <= col {
+				row = append(row, "")
+			}
+			switch c.Type {
+			case "s":
+				if n, err := strconv.Atoi(c.Value); err == nil && n >= 0 && n < len(shared) {
+					row[col] = shared[n]
+				}
+			case "inlineStr":
+				row[col] = c.Inline.Text
+			default:
+				row[col] = c.Value
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func decodeZipXML(f *zip.File, v interface{}) error {
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	data, err := io.ReadAll(rc)
+	if err != nil {
+		return err
+	}
+	return xml.Unmarshal(data, v)
+}
+
+// columnIndex converte a referência da célula ("C12") no índice da coluna (2)
+func columnIndex(ref string) int {
+	col := 0
+	n := 0
+	for _, r := range ref {
+		if r < 'A' || r > 'Z' {
+			break
+		}
+		col = col*26 + int(r-'A') + 1
+		n++
+	}
+	if n == 0 {
+		return -1
+	}
+	return col - 1
+}
+
+func sheetNumber(name string) int {
+	base := strings.TrimSuffix(filepath.Base(name), ".xml")
+	n, err := strconv.Atoi(strings.TrimPrefix(base, "sheet"))
+	if err != nil {
+		return 1 << 30
+	}
+	return n
+}
@@ -0,0 +1,72 @@
+package fuel_price
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	db "geolocation/db/sqlc"
+)
+
+type InterfaceRepository interface {
+	GetFuelPriceImportByChecksum(ctx context.Context, checksum string) (db.FuelPriceImport, error)
+	ImportFuelPrices(ctx context.Context, arg db.CreateFuelPriceImportParams, prices []db.UpsertFuelPriceParams) (db.FuelPriceImport, error)
+	GetLatestFuelPrices(ctx context.Context, arg db.GetLatestFuelPricesParams) ([]db.FuelPrice, error)
+}
+
+type Repository struct {
+	Conn    *sql.DB
+	DBtx    db.DBTX
+	Queries *db.Queries
+	SqlConn *sql.DB
+}
+
+func NewFuelPriceRepository(Conn *sql.DB) *Repository {
+	q := db.New(Conn)
+	return &Repository{
+		Conn:    Conn,
+		DBtx:    Conn,
+		Queries: q,
+		SqlConn: Conn,
+	}
+}
+
+func (r *Repository) GetFuelPriceImportByChecksum(ctx context.Context, checksum string) (db.FuelPriceImport, error) {
+	return r.Queries.GetFuelPriceImportByChecksum(ctx, checksum)
+}
+
+// ImportFuelPrices registra a importação e grava os preços numa única transação: se algum preço falhar, nem
+// os preços nem o checksum ficam gravados e o arquivo é importado de novo na próxima verificação
+func (r *Repository) ImportFuelPrices(ctx context.Context, arg db.CreateFuelPriceImportParams, prices []db.UpsertFuelPriceParams) (db.FuelPriceImport, error) {
+	tx, err := r.Conn.BeginTx(ctx, nil)
+	if err != nil {
+		return db.FuelPriceImport{}, fmt.Errorf("erro ao iniciar transação: %w", err)
+	}
+	defer tx.Rollback()
+
+	q := r.Queries.WithTx(tx)
+	imported, err := q.CreateFuelPriceImport(ctx, arg)
+	if err != nil {
+		return db.FuelPriceImport{}, fmt.Errorf("erro ao registrar importação: %w", err)
+	}
+	for _, price := range prices {
+		price.ImportID = sql.NullInt64{Int64: imported.ID, Valid: true}
+		if err := q.UpsertFuelPrice(ctx, price); err != nil {
+			return db.FuelPriceImport{}, fmt.Errorf("erro ao gravar preço de %s/%s (%s): %w", price.Municipio, price.State, price.Product, err)
+		}
+	}
+	err = q.UpdateFuelPriceImportRows(ctx, db.UpdateFuelPriceImportRowsParams{
+		ID:           imported.ID,
+		RowsImported: int32(len(prices)),
+	})
+	if err != nil {
+		return db.FuelPriceImport{}, fmt.Errorf("erro ao atualizar importação %d: %w", imported.ID, err)
+	}
+	imported.RowsImported = int32(len(prices))
+
+	return imported, tx.Commit()
+}
+
+func (r *Repository) GetLatestFuelPrices(ctx context.Context, arg db.GetLatestFuelPricesParams) ([]db.FuelPrice, error) {
+	return r.Queries.GetLatestFuelPrices(ctx, arg)
+}
@@ -0,0 +1,178 @@
+package fuel_price
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	db "geolocation/db/sqlc"
+	"geolocation/validation"
+)
+
+// defaultScanInterval é o intervalo padrão de verificação da pasta de arquivos da ANP
+const defaultScanInterval = 6 * time.Hour
+
+type InterfaceService interface {
+	ImportDirService(ctx context.Context) ([]ImportResult, error)
+	ImportFileService(ctx context.Context, name string, content []byte) (ImportResult, error)
+	GetLatestPricesService(ctx context.Context, product, state, municipio string) ([]FuelPriceResponse, error)
+}
+
+type Service struct {
+	InterfaceService InterfaceRepository
+	dir              string
+	scanInterval     time.Duration
+}
+
+// NewFuelPriceService cria o serviço. dir é a pasta onde os arquivos do levantamento semanal da ANP
+// (CSV/XLSX) são deixados e scanInterval a frequência da verificação ("6h"); vazio usa o padrão.
+func NewFuelPriceService(InterfaceService InterfaceRepository, dir, scanInterval string) *Service {
+	s := &Service{
+		InterfaceService: InterfaceService,
+		dir:              dir,
+		scanInterval:     defaultScanInterval,
+	}
+	if scanInterval != "" {
+		if d, err := time.ParseDuration(scanInterval); err == nil && d > 0 {
+			s.scanInterval = d
+		} else {
+			log.Printf("FUEL_PRICE_SCAN_INTERVAL inválido (%q), usando %s", scanInterval, defaultScanInterval)
+		}
+	}
+	return s
+}
+
+// ImportDirService importa os arquivos CSV/XLSX da pasta configurada; arquivos já importados
+// (mesmo conteúdo) são ignorados pelo checksum
+func (s *Service) ImportDirService(ctx context.Context) ([]ImportResult, error) {
+	if s.dir == "" {
+		return nil, errors.New("pasta de preços da ANP não configurada (FUEL_PRICE_DIR)")
+	}
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao listar pasta de preços: %w", err)
+	}
+
+	var names []string
+	for _, entry := range entries {
+		ext := strings.ToLower(filepath.Ext(entry.Name()))
+		if !entry.IsDir() && (ext == ".csv" || ext == ".xlsx") {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+
+	results := []ImportResult{}
+	for _, name := range names {
+		content, err := os.ReadFile(filepath.Join(s.dir, name))
+		if err != nil {
+			log.Printf("Erro ao ler arquivo de preços %s: %v", name, err)
+			continue
+		}
+		result, err := s.ImportFileService(ctx, name, content)
+		if err != nil {
+			log.Printf("Erro ao importar arquivo de preços %s: %v", name, err)
+			continue
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// ImportFileService grava os preços de diesel do arquivo, substituindo os da mesma semana e município.
+// O arquivo entra inteiro ou não entra: só um arquivo gravado por completo fica registrado pelo checksum.
+func (s *Service) ImportFileService(ctx context.Context, name string, content []byte) (ImportResult, error) {
+	sum := sha256.Sum256(content)
+	checksum := hex.EncodeToString(sum[:])
+
+	_, err := s.InterfaceService.GetFuelPriceImportByChecksum(ctx, checksum)
+	if err == nil {
+		return ImportResult{FileName: name, Skipped: true}, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return ImportResult{}, fmt.Errorf("erro ao verificar importação: %w", err)
+	}
+
+	observations, err := parsePriceFile(name, content)
+	if err != nil {
+		return ImportResult{}, err
+	}
+
+	prices := make([]db.UpsertFuelPriceParams, 0, len(observations))
+	for _, o := range observations {
+		prices = append(prices, db.UpsertFuelPriceParams{
+			Product:          o.product,
+			State:            o.state,
+			Municipio:        o.municipio,
+			MunicipioKey:     validation.NormalizeName(o.municipio),
+			AveragePrice:     o.average,
+			MinPrice:         o.min,
+			MaxPrice:         o.max,
+			StationsSurveyed: o.stations,
+			SurveyStart:      o.start,
+			SurveyEnd:        o.end,
+		})
+	}
+
+	imported, err := s.InterfaceService.ImportFuelPrices(ctx, db.CreateFuelPriceImportParams{
+		FileName: name,
+		Checksum: checksum,
+	}, prices)
+	if err != nil {
+		return ImportResult{}, err
+	}
+
+	log.Printf("Preços da ANP importados de %s: %d registros", name, imported.RowsImported)
+	return ImportResult{FileName: name, Rows: int(imported.RowsImported)}, nil
+}
+
+func (s *Service) GetLatestPricesService(ctx context.Context, product, state, municipio string) ([]FuelPriceResponse, error) {
+	results, err := s.InterfaceService.GetLatestFuelPrices(ctx, db.GetLatestFuelPricesParams{
+		Product:      product,
+		State:        strings.ToUpper(strings.TrimSpace(state)),
+		MunicipioKey: validation.NormalizeName(municipio),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar preços: %w", err)
+	}
+
+	prices := make([]FuelPriceResponse, 0, len(results))
+	for _, result := range results {
+		var price FuelPriceResponse
+		price.ParseFromDb(result)
+		prices = append(prices, price)
+	}
+	return prices, nil
+}
+
+// WatchImports importa a pasta ao iniciar e depois periodicamente, até o contexto ser cancelado
+func (s *Service) WatchImports(ctx context.Context) {
+	if s.dir == "" {
+		return
+	}
+	if _, err := s.ImportDirService(ctx); err != nil {
+		log.Printf("Erro ao importar preços da ANP: %v", err)
+	}
+
+	ticker := time.NewTicker(s.scanInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.ImportDirService(ctx); err != nil {
+				log.Printf("Erro ao importar preços da ANP: %v", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,235 @@
+package new_routes
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	DirectionCrescente   = "crescente"
+	DirectionDecrescente = "decrescente"
+
+	DirectionConfidenceHigh   = "high"
+	DirectionConfidenceMedium = "medium"
+	DirectionConfidenceLow    = "low"
+
+	// directionWindowMeters é o trecho antes e depois do ponto casado usado para medir o rumo da rota
+	directionWindowMeters = 300.0
+	// kmNeighbourMaxMeters limita a distância do marco vizinho usado para achar o rumo crescente da rodovia
+	kmNeighbourMaxMeters = 30000.0
+	// kmProgressionMaxAlong limita a distância, ao longo da rota, entre dois marcos comparados
+	kmProgressionMaxAlong = 80000.0
+	// kmMinDelta ignora marcos no mesmo km (praças dos dois sentidos lado a lado)
+	kmMinDelta = 0.5
+)
+
+var kmNumberRegex = regexp.MustCompile(`\d+(?:[.,+]\d+)?`)
+
+// roadMarker é um pedágio ou balança com rodovia e km conhecidos
+type roadMarker struct {
+	key string
+	km  float64
+	pos LatLng
+}
+
+// alongMarker é um marco casado com a rota na posição along (m)
+type alongMarker struct {
+	km    float64
+	along float64
+}
+
+// directionMatch é o sentido de tráfego da rota no ponto casado
+type directionMatch struct {
+	Crescente  bool
+	Confidence string
+}
+
+func (d directionMatch) direction() string {
+	if d.Crescente {
+		return DirectionCrescente
+	}
+	return DirectionDecrescente
+}
+
+// accepts compara o sentido da praça/balança com o sentido de tráfego; sem sentido cadastrado aceita sempre
+func (d directionMatch) accepts(sentido string) bool {
+	switch strings.ToLower(strings.TrimSpace(sentido)) {
+	case DirectionCrescente:
+		return d.Crescente
+	case DirectionDecrescente:
+		return !d.Crescente
+	default:
+		return true
+	}
+}
+
+// roadKey normaliza rodovia + UF, já que o km das rodovias federais recomeça em cada estado
+func roadKey(road, uf string) string {
+	r := strings.ToUpper(strings.TrimSpace(road))
+	r = strings.NewReplacer("-", "", " ", "", "/", "").Replace(r)
+	if r == "" {
+		return ""
+	}
+	return r + ":" + strings.ToUpper(strings.TrimSpace(uf))
+}
+
+// parseKmMarker interpreta o km cadastrado ("123", "123,4", "123+400", "km 123.4")
+func parseKmMarker(km string) (float64, bool) {
+	m := kmNumberRegex.FindString(km)
+	if m == "" {
+		return 0, false
+	}
+	if strings.Contains(m, "+") {
+		parts := strings.SplitN(m, "+", 2)
+		whole, err1 := strconv.ParseFloat(parts[0], 64)
+		meters, err2 := strconv.ParseFloat(parts[1], 64)
+		if err1 != nil || err2 != nil {
+			return 0, false
+		}
+		return whole + meters/1000, true
+	}
+	value, err := strconv.ParseFloat(strings.Replace(m, ",", ".", 1), 64)
+	if err != nil {
+		return 0, false
+	}
+	return value, true
+}
+
+// pointAt devolve o ponto da rota a along metros da origem
+func (g *RouteGeometry) pointAt(along float64) LatLng {
+	if len(g.Points) == 0 {
+		return LatLng{}
+	}
+	if along <= 0 {
+		return g.Points[0]
+	}
+	for i := 1; i < len(g.Points); i++ {
+		if g.Cumulative[i] >= along {
+			segLen := g.Cumulative[i] - g.Cumulative[i-1]
+			if segLen == 0 {
+				return g.Points[i]
+			}
+			t := (along - g.Cumulative[i-1]) / segLen
+			return LatLng{
+				Lat: g.Points[i-1].Lat + t*(g.Points[i].Lat-g.Points[i-1].Lat),
+				Lng: g.Points[i-1].Lng + t*(g.Points[i].Lng-g.Points[i-1].Lng),
+			}
+		}
+	}
+	return g.Points[len(g.Points)-1]
+}
+
+// bearingAt mede o rumo da rota (graus) num trecho centrado em along, suavizando curvas e trevos
+func (g *RouteGeometry) bearingAt(along float64) float64 {
+	from := g.pointAt(along - directionWindowMeters)
+	to := g.pointAt(along + directionWindowMeters)
+	return bearingBetween(from, to)
+}
+
+func bearingBetween(from, to LatLng) float64 {
+	φ1 := from.Lat * math.Pi / 180
+	φ2 := to.Lat * math.Pi / 180
+	Δλ := (to.Lng - from.Lng) * math.Pi / 180
+	y := math.Sin(Δλ) * math.Cos(φ2)
+	x := math.Cos(φ1)*math.Sin(φ2) - math.Sin(φ1)*math.Cos(φ2)*math.Cos(Δλ)
+	return math.Mod(math.Atan2(y, x)*180/math.Pi+360, 360)
+}
+
+func bearingDiff(a, b float64) float64 {
+	d := math.Abs(a - b)
+	if d > 180 {
+		d = 360 - d
+	}
+	return d
+}
+
+// highwayBearing estima o rumo do sentido crescente da rodovia perto do marco, a partir do marco
+// vizinho mais próximo da mesma rodovia com km diferente
+func (snap *poiSnapshot) highwayBearing(m roadMarker) (float64, bool) {
+	if m.key == "" {
+		return 0, false
+	}
+	best := math.MaxFloat64
+	var neighbour roadMarker
+	for _, other := range snap.markersByRoad[m.key] {
+		if math.Abs(other.km-m.km) < kmMinDelta {
+			continue
+		}
+		d := haversineDistanceTolls(m.pos.Lat, m.pos.Lng, other.pos.Lat, other.pos.Lng)
+		if d < best && d <= kmNeighbourMaxMeters {
+			best, neighbour = d, other
+		}
+	}
+	if best == math.MaxFloat64 {
+		return 0, false
+	}
+	if neighbour.km > m.km {
+		return bearingBetween(m.pos, neighbour.pos), true
+	}
+	return bearingBetween(neighbour.pos, m.pos), true
+}
+
+// routeMarkers casa com a rota todos os pedágios e balanças com km conhecido, sem filtrar sentido,
+// para medir a progressão do km ao longo do trajeto em cada rodovia
+func (snap *poiSnapshot) routeMarkers(g *RouteGeometry) map[string][]alongMarker {
+	out := make(map[string][]alongMarker)
+	for _, m := range g.match(snap.tollGrid, snap.tollPositions, tollRouteTolerance) {
+		if marker := snap.tollMarkers[m.Index]; marker.key != "" {
+			out[marker.key] = append(out[marker.key], alongMarker{km: marker.km, along: m.Along})
+		}
+	}
+	for _, m := range g.match(snap.balancaGrid, snap.balancaPositions, tollRouteTolerance) {
+		if marker := snap.balancaMarkers[m.Index]; marker.key != "" {
+			out[marker.key] = append(out[marker.key], alongMarker{km: marker.km, along: m.Along})
+		}
+	}
+	return out
+}
+
+// travelDirection determina o sentido de tráfego no ponto casado. A progressão do km entre marcos da
+// mesma rodovia ao longo da rota tem prioridade; o rumo do segmento comparado ao rumo crescente da
+// rodovia confirma ou substitui. Sem nenhum dos dois, usa o rumo local (norte = crescente) com confiança baixa.
+func (snap *poiSnapshot) travelDirection(g *RouteGeometry, match RouteMatch, marker roadMarker, markers map[string][]alongMarker) directionMatch {
+	kmKnown, kmCrescente := false, false
+	if marker.key != "" {
+		closest := math.MaxFloat64
+		for _, other := range markers[marker.key] {
+			if math.Abs(other.km-marker.km) < kmMinDelta {
+				continue
+			}
+			alongDelta := other.along - match.Along
+			if alongDelta == 0 || math.Abs(alongDelta) > kmProgressionMaxAlong {
+				continue
+			}
+			if math.Abs(alongDelta) < closest {
+				closest = math.Abs(alongDelta)
+				kmKnown = true
+				kmCrescente = (other.km-marker.km)/alongDelta > 0
+			}
+		}
+	}
+
+	routeBearing := g.bearingAt(match.Along)
+	bearingKnown, bearingCrescente := false, false
+	if highway, ok := snap.highwayBearing(marker); ok {
+		diff := bearingDiff(routeBearing, highway)
+		// Perto de 90° o rumo não distingue os sentidos
+		if diff < 70 || diff > 110 {
+			bearingKnown = true
+			bearingCrescente = diff < 90
+		}
+	}
+
+	switch {
+	case kmKnown && bearingKnown && kmCrescente == bearingCrescente:
+		return directionMatch{Crescente: kmCrescente, Confidence: DirectionConfidenceHigh}
+	case kmKnown:
+		return directionMatch{Crescente: kmCrescente, Confidence: DirectionConfidenceMedium}
+	case bearingKnown:
+		return directionMatch{Crescente: bearingCrescente, Confidence: DirectionConfidenceMedium}
+	default:
+		return directionMatch{Crescente: routeBearing < 90 || routeBearing > 270, Confidence: DirectionConfidenceLow}
+	}
+}
@@ -0,0 +1,97 @@
+package new_routes
+
+import "testing"
+
+// directionTestGeometry é uma rota reta de ~11 km a partir de (0, 0), um ponto a cada 0,01 grau
+func directionTestGeometry(dLat, dLng float64) *RouteGeometry {
+	g := &RouteGeometry{}
+	for i := 0; i <= 10; i++ {
+		g.Points = append(g.Points, LatLng{Lat: float64(i) * dLat, Lng: float64(i) * dLng})
+		g.Cumulative = append(g.Cumulative, float64(i)*1113.2)
+	}
+	return g
+}
+
+func TestTravelDirection(t *testing.T) {
+	const key = "BR116:SP"
+	east := directionTestGeometry(0, 0.01)
+	north := directionTestGeometry(0.01, 0)
+	match := RouteMatch{Along: 5 * 1113.2}
+	marker := roadMarker{key: key, km: 100, pos: LatLng{Lat: 0, Lng: 0.05}}
+
+	// Vizinhos da mesma rodovia definem o rumo crescente: km 101 a leste (crescente para leste),
+	// km 99 a leste (crescente para oeste) ou km 101 ao norte (perpendicular à rota)
+	eastUp := []roadMarker{{key: key, km: 101, pos: LatLng{Lat: 0, Lng: 0.06}}}
+	westUp := []roadMarker{{key: key, km: 99, pos: LatLng{Lat: 0, Lng: 0.06}}}
+	northUp := []roadMarker{{key: key, km: 101, pos: LatLng{Lat: 0.01, Lng: 0.05}}}
+
+	tests := []struct {
+		name           string
+		geometry       *RouteGeometry
+		marker         roadMarker
+		neighbours     []roadMarker
+		along          []alongMarker
+		wantCrescente  bool
+		wantConfidence string
+	}{
+		{
+			name: "km e rumo concordam", geometry: east, marker: marker, neighbours: eastUp,
+			along:         []alongMarker{{km: 105, along: match.Along + 5000}},
+			wantCrescente: true, wantConfidence: DirectionConfidenceHigh,
+		},
+		{
+			name: "só a progressão do km", geometry: east, marker: marker,
+			along:         []alongMarker{{km: 95, along: match.Along + 5000}},
+			wantCrescente: false, wantConfidence: DirectionConfidenceMedium,
+		},
+		{
+			name: "marco anterior na rota", geometry: east, marker: marker,
+			along:         []alongMarker{{km: 95, along: match.Along - 5000}},
+			wantCrescente: true, wantConfidence: DirectionConfidenceMedium,
+		},
+		{
+			name: "km vale mais que o rumo quando discordam", geometry: east, marker: marker, neighbours: westUp,
+			along:         []alongMarker{{km: 105, along: match.Along + 5000}},
+			wantCrescente: true, wantConfidence: DirectionConfidenceMedium,
+		},
+		{
+			name: "marco mais próximo na rota decide", geometry: east, marker: marker,
+			along: []alongMarker{
+				{km: 90, along: match.Along + 20000},
+				{km: 103, along: match.Along + 3000},
+			},
+			wantCrescente: true, wantConfidence: DirectionConfidenceMedium,
+		},
+		{
+			name: "só o rumo da rodovia", geometry: east, marker: marker, neighbours: westUp,
+			wantCrescente: false, wantConfidence: DirectionConfidenceMedium,
+		},
+		{
+			name: "mesmo km e marco distante são ignorados", geometry: east, marker: marker, neighbours: westUp,
+			along: []alongMarker{
+				{km: 100.2, along: match.Along + 2000},
+				{km: 150, along: match.Along + kmProgressionMaxAlong + 1},
+			},
+			wantCrescente: false, wantConfidence: DirectionConfidenceMedium,
+		},
+		{
+			name: "rumo perpendicular não decide", geometry: east, marker: marker, neighbours: northUp,
+			wantCrescente: false, wantConfidence: DirectionConfidenceLow,
+		},
+		{
+			name: "sem rodovia: rumo para o norte é crescente", geometry: north, marker: roadMarker{pos: LatLng{Lat: 0.05}},
+			wantCrescente: true, wantConfidence: DirectionConfidenceLow,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			snap := &poiSnapshot{markersByRoad: map[string][]roadMarker{key: append([]roadMarker{marker}, tt.neighbours...)}}
+			markers := map[string][]alongMarker{key: tt.along}
+			got := snap.travelDirection(tt.geometry, match, tt.marker, markers)
+			if got.Crescente != tt.wantCrescente || got.Confidence != tt.wantConfidence {
+				t.Errorf("travelDirection() = %s/%s, want crescente=%v/%s", got.direction(), got.Confidence, tt.wantCrescente, tt.wantConfidence)
+			}
+		})
+	}
+}
@@ -0,0 +1,247 @@
+package new_routes
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"strconv"
+	"time"
+)
+
+// Limites da Lei 13.103/2015 (motorista profissional), em minutos
+const (
+	// lawMaxContinuousMinutes é o máximo de direção contínua antes da pausa obrigatória (5h30)
+	lawMaxContinuousMinutes = 330.0
+	// lawBreakMinutes é a pausa mínima a cada 5h30 de direção
+	lawBreakMinutes = 30.0
+	// lawDailyDrivingMinutes é a jornada de 8h mais 2h extras; acordo coletivo permite até 4h extras
+	lawDailyDrivingMinutes    = 600.0
+	lawMaxDailyDrivingMinutes = 720.0
+	// lawDailyRestMinutes é o descanso mínimo de 11h a cada 24h
+	lawDailyRestMinutes = 660.0
+	// minDrivingLimitMinutes é o menor limite de direção aceito; abaixo disso o itinerário viraria só paradas
+	minDrivingLimitMinutes = 30.0
+
+	// defaultStopWindowMinutes é quanto antes do limite um posto ainda é aceito como parada
+	defaultStopWindowMinutes = 45.0
+	// driverStopTolerance é a distância máxima (m) entre o posto e a rota para servir de parada
+	driverStopTolerance = 500.0
+
+	DriverStopBreak     = "break"
+	DriverStopDailyRest = "daily_rest"
+)
+
+// DriverRules ativa o cálculo do itinerário com as pausas e descansos obrigatórios. Os limites podem ser
+// apertados, mas nunca afrouxados além da lei; o estado inicial (já dirigido hoje e desde a última pausa)
+// permite planejar uma viagem que começa no meio da jornada.
+type DriverRules struct {
+	Enabled                  bool    `json:"enabled"`
+	DrivenTodayMinutes       float64 `json:"driven_today_minutes"`
+	ContinuousDrivingMinutes float64 `json:"continuous_driving_minutes"`
+	MaxContinuousMinutes     float64 `json:"max_continuous_minutes"`
+	BreakMinutes             float64 `json:"break_minutes"`
+	MaxDailyDrivingMinutes   float64 `json:"max_daily_driving_minutes"`
+	DailyRestMinutes         float64 `json:"daily_rest_minutes"`
+	StopWindowMinutes        float64 `json:"stop_window_minutes"`
+}
+
+// DriverItinerary é a viagem com as paradas obrigatórias e a chegada legal (direção + paradas)
+type DriverItinerary struct {
+	Stops           []DriverStop `json:"stops"`
+	DrivingDuration Duration     `json:"driving_duration"`
+	RestDuration    Duration     `json:"rest_duration"`
+	LegalDuration   Duration     `json:"legal_duration"`
+	LegalETA        *time.Time   `json:"legal_eta,omitempty"`
+	Warnings        []string     `json:"warnings,omitempty"`
+}
+
+// DriverStop é uma pausa ou descanso diário. Sem posto próximo ao limite, a parada fica no ponto da rota
+// onde o limite é atingido (OnRoute) e o motorista deve procurar local seguro antes dele.
+type DriverStop struct {
+	Type               string     `json:"type"`
+	Name               string     `json:"name,omitempty"`
+	Address            string     `json:"address,omitempty"`
+	Location           Location   `json:"location"`
+	OnRoute            bool       `json:"on_route,omitempty"`
+	DistanceFromOrigin float64    `json:"distance_from_origin_km"`
+	DrivingBefore      Duration   `json:"driving_before"`
+	StopDuration       Duration   `json:"stop_duration"`
+	Elapsed            Duration   `json:"elapsed"`
+	ETA                *time.Time `json:"eta,omitempty"`
+}
+
+// driverStopCandidate é um posto na rota com o tempo de direção (s) desde a origem
+type driverStopCandidate struct {
+	station GasStation
+	seconds float64
+	meters  float64
+}
+
+// normalized aplica os padrões e os limites da lei; valores fora da lei são trocados pelo limite legal e
+// limites de direção muito curtos sobem para minDrivingLimitMinutes
+func (r DriverRules) normalized() DriverRules {
+	if r.MaxContinuousMinutes <= 0 || r.MaxContinuousMinutes > lawMaxContinuousMinutes {
+		r.MaxContinuousMinutes = lawMaxContinuousMinutes
+	}
+	r.MaxContinuousMinutes = math.Max(r.MaxContinuousMinutes, minDrivingLimitMinutes)
+	if r.BreakMinutes < lawBreakMinutes {
+		r.BreakMinutes = lawBreakMinutes
+	}
+	if r.MaxDailyDrivingMinutes <= 0 {
+		r.MaxDailyDrivingMinutes = lawDailyDrivingMinutes
+	}
+	if r.MaxDailyDrivingMinutes > lawMaxDailyDrivingMinutes {
+		r.MaxDailyDrivingMinutes = lawMaxDailyDrivingMinutes
+	}
+	r.MaxDailyDrivingMinutes = math.Max(r.MaxDailyDrivingMinutes, minDrivingLimitMinutes)
+	if r.DailyRestMinutes < lawDailyRestMinutes {
+		r.DailyRestMinutes = lawDailyRestMinutes
+	}
+	if r.StopWindowMinutes <= 0 {
+		r.StopWindowMinutes = defaultStopWindowMinutes
+	}
+	r.DrivenTodayMinutes = math.Max(r.DrivenTodayMinutes, 0)
+	r.ContinuousDrivingMinutes = math.Max(r.ContinuousDrivingMinutes, 0)
+	return r
+}
+
+// driverRulesCacheKey compõe a parte da chave de cache referente às regras do motorista; vazio quando desativadas
+func driverRulesCacheKey(rules *DriverRules) string {
+	if rules == nil || !rules.Enabled {
+		return ""
+	}
+	r := rules.normalized()
+	return fmt.Sprintf(":driver:%s:%s:%s:%s:%s:%s:%s",
+		strconv.FormatFloat(r.DrivenTodayMinutes, 'f', -1, 64),
+		strconv.FormatFloat(r.ContinuousDrivingMinutes, 'f', -1, 64),
+		strconv.FormatFloat(r.MaxContinuousMinutes, 'f', -1, 64),
+		strconv.FormatFloat(r.BreakMinutes, 'f', -1, 64),
+		strconv.FormatFloat(r.MaxDailyDrivingMinutes, 'f', -1, 64),
+		strconv.FormatFloat(r.DailyRestMinutes, 'f', -1, 64),
+		strconv.FormatFloat(r.StopWindowMinutes, 'f', -1, 64),
+	)
+}
+
+// driverItinerary insere na rota as pausas de 30 min a cada 5h30 de direção e o descanso diário de 11h
+// ao fim da jornada, escolhendo o último posto antes de cada limite. Devolve nil quando as regras não foram pedidas.
+func (s *Service) driverItinerary(ctx context.Context, route OSRMRoute, rules *DriverRules, departure *time.Time) *DriverItinerary {
+	if rules == nil || !rules.Enabled || route.Duration <= 0 {
+		return nil
+	}
+	r := rules.normalized()
+
+	geometry, err := routeGeometryFromPolyline(route.Geometry)
+	if err != nil {
+		log.Printf("Erro ao ler geometria para o itinerário do motorista: %v", err)
+		return nil
+	}
+	timeline := newRouteTimeline(route, geometry.Length(), nil)
+	total := timeline.durations[len(timeline.durations)-1]
+	candidates := s.driverStopCandidates(ctx, geometry, timeline)
+
+	itinerary := &DriverItinerary{Stops: []DriverStop{}}
+	var position, elapsed, rest float64
+	continuous := r.ContinuousDrivingMinutes * 60
+	daily := r.DrivenTodayMinutes * 60
+	next := 0
+
+	for {
+		untilBreak := r.MaxContinuousMinutes*60 - continuous
+		untilRest := r.MaxDailyDrivingMinutes*60 - daily
+		limit := math.Max(math.Min(untilBreak, untilRest), 0)
+		if total-position <= limit {
+			break
+		}
+
+		stopType, stopMinutes := DriverStopBreak, r.BreakMinutes
+		if untilRest <= untilBreak {
+			stopType, stopMinutes = DriverStopDailyRest, r.DailyRestMinutes
+		}
+
+		deadline := position + limit
+		chosen := -1
+		for i := next; i < len(candidates) && candidates[i].seconds <= deadline; i++ {
+			if candidates[i].seconds > position && candidates[i].seconds >= deadline-r.StopWindowMinutes*60 {
+				chosen = i
+			}
+		}
+
+		stop := DriverStop{Type: stopType}
+		at := deadline
+		if chosen >= 0 {
+			c := candidates[chosen]
+			at = c.seconds
+			stop.Name = c.station.Name
+			stop.Address = c.station.Address
+			stop.Location = c.station.Location
+			stop.DistanceFromOrigin = roundCents(c.meters / 1000)
+			next = chosen + 1
+		} else {
+			meters := timeline.distanceAtDuration(at)
+			along := meters
+			if timeline.totalDistance() > 0 {
+				along = meters * geometry.Length() / timeline.totalDistance()
+			}
+			point := geometry.pointAt(along)
+			stop.Location = Location{Latitude: point.Lat, Longitude: point.Lng}
+			stop.OnRoute = true
+			stop.DistanceFromOrigin = roundCents(meters / 1000)
+			itinerary.Warnings = append(itinerary.Warnings, fmt.Sprintf("sem posto nos %.0f min antes do limite de direção; parada no km %.0f da rota", r.StopWindowMinutes, meters/1000))
+		}
+
+		elapsed += at - position
+		daily += at - position
+		position = at
+
+		stop.DrivingBefore = newDuration(position)
+		stop.StopDuration = newDuration(stopMinutes * 60)
+		elapsed += stopMinutes * 60
+		rest += stopMinutes * 60
+		stop.Elapsed = newDuration(elapsed)
+		if departure != nil {
+			eta := departure.Add(time.Duration(elapsed * float64(time.Second)))
+			stop.ETA = &eta
+		}
+		itinerary.Stops = append(itinerary.Stops, stop)
+
+		continuous = 0
+		if stopType == DriverStopDailyRest {
+			daily = 0
+		}
+	}
+
+	elapsed += total - position
+	itinerary.DrivingDuration = newDuration(total)
+	itinerary.RestDuration = newDuration(rest)
+	itinerary.LegalDuration = newDuration(elapsed)
+	if departure != nil {
+		eta := departure.Add(time.Duration(elapsed * float64(time.Second)))
+		itinerary.LegalETA = &eta
+	}
+	return itinerary
+}
+
+// driverStopCandidates lista os postos próximos à rota ordenados pelo tempo de direção desde a origem
+func (s *Service) driverStopCandidates(ctx context.Context, geometry *RouteGeometry, timeline RouteTimeline) []driverStopCandidate {
+	snap, err := s.POIIndex.current(ctx)
+	if err != nil {
+		log.Printf("Erro ao consultar postos para o itinerário do motorista: %v", err)
+		return nil
+	}
+	var candidates []driverStopCandidate
+	for _, m := range geometry.match(snap.stationGrid, snap.stationPositions, driverStopTolerance) {
+		arrival := timeline.arrivalAtAlong(m.Along)
+		candidates = append(candidates, driverStopCandidate{
+			station: snap.stations[m.Index],
+			seconds: arrival.DurationSeconds,
+			meters:  arrival.DistanceMeters,
+		})
+	}
+	return candidates
+}
+
+func newDuration(seconds float64) Duration {
+	text, value := formatDuration(seconds)
+	return Duration{Text: text, Value: value}
+}
@@ -0,0 +1,158 @@
+package new_routes
+
+import (
+	"context"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// driverTestRoute é uma rota reta de ~1000 km para leste sobre o equador, sem passos: o tempo é
+// proporcional à distância
+func driverTestRoute(duration float64) OSRMRoute {
+	points := make([]LatLng, 0, 91)
+	for i := 0; i <= 90; i++ {
+		points = append(points, LatLng{Lat: 0, Lng: float64(i) * 0.1})
+	}
+	return OSRMRoute{Geometry: encodePolyline(points), Distance: 1000000, Duration: duration}
+}
+
+// driverTestService tem um único posto na rota, a fraction do caminho
+func driverTestService(fraction float64) *Service {
+	snap := &poiSnapshot{}
+	if fraction > 0 {
+		station := GasStation{Name: "Posto", Location: Location{Latitude: 0, Longitude: 9 * fraction}}
+		snap.stations = []GasStation{station}
+		snap.stationPositions = []LatLng{{Lat: 0, Lng: station.Location.Longitude}}
+	}
+	snap.stationGrid = newGridIndex(poiGridCellSize, snap.stationPositions)
+	return &Service{POIIndex: &POIIndex{snapshot: snap}}
+}
+
+func TestDriverRulesNormalized(t *testing.T) {
+	tests := []struct {
+		name                    string
+		rules                   DriverRules
+		wantContinuous, wantDay float64
+	}{
+		{name: "padrões da lei", rules: DriverRules{}, wantContinuous: lawMaxContinuousMinutes, wantDay: lawDailyDrivingMinutes},
+		{name: "acima da lei", rules: DriverRules{MaxContinuousMinutes: 400, MaxDailyDrivingMinutes: 800}, wantContinuous: lawMaxContinuousMinutes, wantDay: lawMaxDailyDrivingMinutes},
+		{name: "mais apertado que a lei", rules: DriverRules{MaxContinuousMinutes: 240, MaxDailyDrivingMinutes: 480}, wantContinuous: 240, wantDay: 480},
+		{name: "curtos demais sobem ao mínimo", rules: DriverRules{MaxContinuousMinutes: 0.1, MaxDailyDrivingMinutes: 5}, wantContinuous: minDrivingLimitMinutes, wantDay: minDrivingLimitMinutes},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.rules.normalized()
+			if got.MaxContinuousMinutes != tt.wantContinuous || got.MaxDailyDrivingMinutes != tt.wantDay {
+				t.Errorf("normalized() = %v/%v, want %v/%v", got.MaxContinuousMinutes, got.MaxDailyDrivingMinutes, tt.wantContinuous, tt.wantDay)
+			}
+			if got.BreakMinutes < lawBreakMinutes || got.DailyRestMinutes < lawDailyRestMinutes {
+				t.Errorf("normalized() afrouxou pausa/descanso: %v/%v", got.BreakMinutes, got.DailyRestMinutes)
+			}
+		})
+	}
+}
+
+func TestDriverItinerary(t *testing.T) {
+	departure := time.Date(2026, 10, 16, 6, 0, 0, 0, time.UTC)
+	hours := func(h float64) float64 { return h * 3600 }
+
+	tests := []struct {
+		name        string
+		duration    float64
+		rules       *DriverRules
+		station     float64 // fração da rota onde fica o posto; 0 = sem posto
+		wantNil     bool
+		wantTypes   []string
+		wantBefore  []float64 // direção (h) antes de cada parada
+		wantOnRoute []bool
+		wantLegal   float64 // h
+	}{
+		{name: "desativado", duration: hours(12), rules: &DriverRules{}, wantNil: true},
+		{name: "sem regras", duration: hours(12), rules: nil, wantNil: true},
+		{
+			name: "viagem curta sem paradas", duration: hours(4), rules: &DriverRules{Enabled: true},
+			wantTypes: []string{}, wantBefore: []float64{}, wantOnRoute: []bool{}, wantLegal: 4,
+		},
+		{
+			name: "pausa e descanso diário sem postos", duration: hours(12), rules: &DriverRules{Enabled: true},
+			wantTypes:   []string{DriverStopBreak, DriverStopDailyRest},
+			wantBefore:  []float64{5.5, 10},
+			wantOnRoute: []bool{true, true},
+			wantLegal:   12 + 0.5 + 11,
+		},
+		{
+			name: "pausa no posto antes do limite", duration: hours(12), rules: &DriverRules{Enabled: true}, station: 5.0 / 12,
+			wantTypes:   []string{DriverStopBreak, DriverStopDailyRest},
+			wantBefore:  []float64{5, 10},
+			wantOnRoute: []bool{false, true},
+			wantLegal:   12 + 0.5 + 11,
+		},
+		{
+			name: "começa no fim da jornada", duration: hours(4), rules: &DriverRules{Enabled: true, DrivenTodayMinutes: 580},
+			wantTypes:   []string{DriverStopDailyRest},
+			wantBefore:  []float64{20.0 / 60},
+			wantOnRoute: []bool{true},
+			wantLegal:   4 + 11,
+		},
+		{
+			name: "limite contínuo mínimo de 30 min", duration: hours(2), rules: &DriverRules{Enabled: true, MaxContinuousMinutes: 1},
+			wantTypes:   []string{DriverStopBreak, DriverStopBreak, DriverStopBreak},
+			wantBefore:  []float64{0.5, 1, 1.5},
+			wantOnRoute: []bool{true, true, true},
+			wantLegal:   2 + 1.5,
+		},
+		{
+			name: "limite diário mínimo de 30 min", duration: hours(1), rules: &DriverRules{Enabled: true, MaxDailyDrivingMinutes: 1},
+			wantTypes:   []string{DriverStopDailyRest},
+			wantBefore:  []float64{0.5},
+			wantOnRoute: []bool{true},
+			wantLegal:   1 + 11,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := driverTestService(tt.station).driverItinerary(context.Background(), driverTestRoute(tt.duration), tt.rules, &departure)
+			if tt.wantNil {
+				if got != nil {
+					t.Fatalf("driverItinerary() = %+v, want nil", got)
+				}
+				return
+			}
+			if got == nil {
+				t.Fatal("driverItinerary() = nil")
+			}
+
+			types := make([]string, 0, len(got.Stops))
+			before := make([]float64, 0, len(got.Stops))
+			onRoute := make([]bool, 0, len(got.Stops))
+			for _, stop := range got.Stops {
+				types = append(types, stop.Type)
+				before = append(before, math.Round(stop.DrivingBefore.Value/36)/100)
+				onRoute = append(onRoute, stop.OnRoute)
+			}
+			if !reflect.DeepEqual(types, tt.wantTypes) {
+				t.Errorf("tipos = %v, want %v", types, tt.wantTypes)
+			}
+			wantBefore := make([]float64, len(tt.wantBefore))
+			for i, h := range tt.wantBefore {
+				wantBefore[i] = math.Round(h*100) / 100
+			}
+			if !reflect.DeepEqual(before, wantBefore) {
+				t.Errorf("direção antes das paradas (h) = %v, want %v", before, wantBefore)
+			}
+			if !reflect.DeepEqual(onRoute, tt.wantOnRoute) {
+				t.Errorf("on_route = %v, want %v", onRoute, tt.wantOnRoute)
+			}
+			if legal := got.LegalDuration.Value / 3600; math.Abs(legal-tt.wantLegal) > 0.01 {
+				t.Errorf("duração legal = %.2f h, want %.2f h", legal, tt.wantLegal)
+			}
+			if got.LegalETA == nil || !got.LegalETA.Equal(departure.Add(time.Duration(got.LegalDuration.Value*float64(time.Second)))) {
+				t.Errorf("legal_eta = %v, want saída + duração legal", got.LegalETA)
+			}
+		})
+	}
+}
@@ -0,0 +1,406 @@
+package new_routes
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	db "geolocation/db/sqlc"
+	"log"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	FuelDiesel   = "diesel"
+	FuelGasoline = "gasolina"
+
+	EmissionsFormatJSON = "json"
+	EmissionsFormatCSV  = "csv"
+
+	// referenceLoadFactor é a ocupação da carga em que o consumo informado é considerado medido (meia carga)
+	referenceLoadFactor = 0.5
+	// loadSensitivity é a variação do consumo entre a ocupação de referência e o caminhão vazio ou lotado (±15%)
+	loadSensitivity = 0.3
+	// emissionsReportMonths é o período padrão do relatório: o mês atual e os 11 anteriores
+	emissionsReportMonths = 12
+
+	emissionsMethodology = "GHG Protocol, escopo 1 (tanque-à-roda) e poço-à-roda; fatores DESNZ 2024 para a parcela fóssil; diesel B15 e gasolina C E30"
+)
+
+// emissionFactor descreve o combustível vendido no posto: a parcela fóssil emite CO2e na queima (TTW) e na
+// produção e transporte (WTT); a parcela de biocombustível é CO2 biogênico e fica fora do CO2e fóssil
+type emissionFactor struct {
+	FossilTTW   float64 // kgCO2e por litro do combustível fóssil queimado
+	FossilWTT   float64 // kgCO2e por litro do combustível fóssil, da extração até a bomba
+	BiogenicCO2 float64 // kgCO2 por litro do biocombustível queimado (combustão estequiométrica)
+	Blend       float64 // fração de biocombustível na mistura obrigatória
+}
+
+var emissionFactors = map[string]emissionFactor{
+	// diesel S10 com 15% de biodiesel (Lei 14.993/2024); biodiesel metílico ~0,88 kg/L
+	FuelDiesel: {FossilTTW: 2.66155, FossilWTT: 0.62409, BiogenicCO2: 2.50, Blend: 0.15},
+	// gasolina C com 30% de etanol anidro; etanol 0,789 kg/L
+	FuelGasoline: {FossilTTW: 2.35372, FossilWTT: 0.60283, BiogenicCO2: 1.51, Blend: 0.30},
+}
+
+// Emissions é o combustível queimado e as emissões da rota. FuelLiters já inclui o ajuste pela carga.
+type Emissions struct {
+	Fuel           string  `json:"fuel"`
+	FuelLiters     float64 `json:"fuel_liters"`
+	LoadFactor     float64 `json:"load_factor,omitempty"` // Ocupação da capacidade de carga (0 a 1), quando conhecida
+	LoadAdjustment float64 `json:"load_adjustment"`       // Multiplicador aplicado ao consumo informado
+	CO2eKg         float64 `json:"co2e_kg"`               // Tanque-à-roda, parcela fóssil
+	CO2eWTWKg      float64 `json:"co2e_wtw_kg"`           // Poço-à-roda, parcela fóssil
+	BiogenicCO2Kg  float64 `json:"biogenic_co2_kg"`
+	CO2ePerKm      float64 `json:"co2e_per_km"`
+	Methodology    string  `json:"methodology"`
+}
+
+// EmissionsReportRequest filtra o relatório ESG. Sem organization_id o relatório é das rotas do próprio usuário.
+// StartMonth e EndMonth no formato AAAA-MM, inclusivos.
+type EmissionsReportRequest struct {
+	OrganizationID int64  `json:"organization_id"`
+	StartMonth     string `json:"start_month"`
+	EndMonth       string `json:"end_month"`
+	Format         string `json:"format" validate:"oneof=json csv"`
+	UserID         int64  `json:"-"`
+}
+
+type EmissionsReportMonth struct {
+	Month         string  `json:"month"`
+	Routes        int64   `json:"routes"`
+	DistanceKm    float64 `json:"distance_km"`
+	KmCity        float64 `json:"km_city"`
+	KmHwy         float64 `json:"km_hwy"`
+	FuelLiters    float64 `json:"fuel_liters"`
+	CO2eKg        float64 `json:"co2e_kg"`
+	CO2eWTWKg     float64 `json:"co2e_wtw_kg"`
+	BiogenicCO2Kg float64 `json:"biogenic_co2_kg"`
+	CO2ePerKm     float64 `json:"co2e_per_km"`
+}
+
+type EmissionsReport struct {
+	OrganizationID int64                  `json:"organization_id,omitempty"`
+	StartMonth     string                 `json:"start_month"`
+	EndMonth       string                 `json:"end_month"`
+	Months         []EmissionsReportMonth `json:"months"`
+	Total          EmissionsReportMonth   `json:"total"`
+	Methodology    string                 `json:"methodology"`
+}
+
+// emissionRecord é o registro gravado em route_emissions a cada rota calculada
+type emissionRecord struct {
+	OrganizationID int64
+	UserID         int64
+	RouteHistID    int64
+	Origin         string
+	Destination    string
+	VehicleType    string
+	Distance       float64 // metros
+	FuelSplit      *FuelSplit
+	Emissions      *Emissions
+}
+
+// routeEmissions calcula o combustível e as emissões a partir da divisão cidade/rodovia da rota. Sem consumo
+// informado não há litros e a rota fica sem emissões.
+func routeEmissions(split FuelSplit, vehicleType string, axles int64, vehicle VehicleInfo) *Emissions {
+	liters := split.LitersCity + split.LitersHwy
+	if liters <= 0 {
+		return nil
+	}
+
+	fuel := vehicleFuel(vehicleType)
+	factor := emissionFactors[fuel]
+	loadFactor, adjustment := loadAdjustment(vehicleType, axles, vehicle)
+	liters *= adjustment
+
+	fossil := liters * (1 - factor.Blend)
+	emissions := &Emissions{
+		Fuel:           fuel,
+		FuelLiters:     roundCents(liters),
+		LoadFactor:     roundThousandths(loadFactor),
+		LoadAdjustment: roundThousandths(adjustment),
+		CO2eKg:         roundCents(fossil * factor.FossilTTW),
+		CO2eWTWKg:      roundCents(fossil * (factor.FossilTTW + factor.FossilWTT)),
+		BiogenicCO2Kg:  roundCents(liters * factor.Blend * factor.BiogenicCO2),
+		Methodology:    emissionsMethodology,
+	}
+	if km := split.KmCity + split.KmHwy; km > 0 {
+		emissions.CO2ePerKm = roundThousandths(fossil * factor.FossilTTW / km)
+	}
+	return emissions
+}
+
+// vehicleFuel assume diesel para caminhões e ônibus e gasolina C para carros e motos
+func vehicleFuel(vehicleType string) string {
+	switch strings.ToLower(vehicleType) {
+	case "truck", "bus":
+		return FuelDiesel
+	}
+	return FuelGasoline
+}
+
+// loadAdjustment devolve a ocupação da carga e o multiplicador do consumo. Só se aplica a caminhões com peso
+// da carga informado: a capacidade é o PBT legal pelo número de eixos menos a tara estimada.
+func loadAdjustment(vehicleType string, axles int64, vehicle VehicleInfo) (float64, float64) {
+	if strings.ToLower(vehicleType) != "truck" || vehicle.CargoWeight <= 0 || axles <= 0 {
+		return 0, 1
+	}
+	capacity := legalGrossWeight(axles) - float64(axles)*tarePerAxle
+	if capacity <= 0 {
+		return 0, 1
+	}
+	loadFactor := math.Min(vehicle.CargoWeight/capacity, 1)
+	return loadFactor, 1 + loadSensitivity*(loadFactor-referenceLoadFactor)
+}
+
+func roundThousandths(value float64) float64 {
+	return math.Round(value*1000) / 1000
+}
+
+// recordRouteEmissions grava as emissões da rota para o relatório ESG; falhas só são registradas em log
+// para não interromper o cálculo da rota
+func (s *Service) recordRouteEmissions(ctx context.Context, record emissionRecord) {
+	if record.Emissions == nil {
+		return
+	}
+	var kmCity, kmHwy float64
+	if record.FuelSplit != nil {
+		kmCity, kmHwy = record.FuelSplit.KmCity, record.FuelSplit.KmHwy
+	}
+
+	err := s.InterfaceService.CreateRouteEmission(ctx, db.CreateRouteEmissionParams{
+		OrganizationID: sql.NullInt64{Int64: record.OrganizationID, Valid: record.OrganizationID > 0},
+		UserID:         sql.NullInt64{Int64: record.UserID, Valid: record.UserID > 0},
+		RouteHistID:    sql.NullInt64{Int64: record.RouteHistID, Valid: record.RouteHistID > 0},
+		Origin:         record.Origin,
+		Destination:    record.Destination,
+		VehicleType:    strings.ToLower(record.VehicleType),
+		Fuel:           record.Emissions.Fuel,
+		DistanceKm:     roundCents(record.Distance / 1000),
+		KmCity:         kmCity,
+		KmHwy:          kmHwy,
+		FuelLiters:     record.Emissions.FuelLiters,
+		Co2eKg:         record.Emissions.CO2eKg,
+		Co2eWtwKg:      record.Emissions.CO2eWTWKg,
+		BiogenicCo2Kg:  record.Emissions.BiogenicCO2Kg,
+	})
+	if err != nil {
+		log.Printf("Erro ao gravar emissões da rota: %v", err)
+	}
+}
+
+// recordSavedRouteEmissions grava as emissões da primeira rota da resposta (a recomendada) junto do histórico.
+// organizationID vem sempre do token, nunca do body
+func (s *Service) recordSavedRouteEmissions(ctx context.Context, routeHistID, userID, organizationID int64, responseJSON, requestJSON json.RawMessage) {
+	var output FinalOutput
+	if err := json.Unmarshal(responseJSON, &output); err != nil || len(output.Routes) == 0 {
+		return
+	}
+	var request struct {
+		Type string `json:"type"`
+	}
+	_ = json.Unmarshal(requestJSON, &request)
+
+	summary := output.Routes[0].Summary
+	s.recordRouteEmissions(ctx, emissionRecord{
+		OrganizationID: organizationID,
+		UserID:         userID,
+		RouteHistID:    routeHistID,
+		Origin:         output.Summary.LocationOrigin.Address,
+		Destination:    output.Summary.LocationDestination.Address,
+		VehicleType:    request.Type,
+		Distance:       summary.Distance.Value,
+		FuelSplit:      summary.FuelSplit,
+		Emissions:      summary.Emissions,
+	})
+}
+
+// recordTotalRouteEmissions grava as emissões da rota total das requisições com várias paradas. Essas rotas
+// não têm usuário nem histórico: sem a organização do token não há a quem atribuir e nada é gravado
+func (s *Service) recordTotalRouteEmissions(ctx context.Context, total TotalSummary, organizationID int64, vehicleType string) {
+	if organizationID <= 0 {
+		return
+	}
+	s.recordRouteEmissions(ctx, emissionRecord{
+		OrganizationID: organizationID,
+		Origin:         total.LocationOrigin.Address,
+		Destination:    total.LocationDestination.Address,
+		VehicleType:    vehicleType,
+		Distance:       total.TotalDistance.Value,
+		FuelSplit:      total.FuelSplit,
+		Emissions:      total.Emissions,
+	})
+}
+
+// GetEmissionsReport soma as emissões das rotas calculadas por mês, da organização ou do usuário
+func (s *Service) GetEmissionsReport(ctx context.Context, data EmissionsReportRequest) (EmissionsReport, error) {
+	start, end, err := emissionsReportPeriod(data.StartMonth, data.EndMonth, time.Now())
+	if err != nil {
+		return EmissionsReport{}, err
+	}
+
+	byMonth := make(map[string]EmissionsReportMonth)
+	if data.OrganizationID > 0 {
+		rows, err := s.InterfaceService.GetRouteEmissionsReportByOrganization(ctx, db.GetRouteEmissionsReportByOrganizationParams{
+			OrganizationID: sql.NullInt64{Int64: data.OrganizationID, Valid: true},
+			StartDate:      start,
+			EndDate:        end,
+		})
+		if err != nil {
+			return EmissionsReport{}, fmt.Errorf("erro ao buscar emissões da organização: %w", err)
+		}
+		for _, row := range rows {
+			byMonth[row.Month.Format("2006-01")] = newEmissionsReportMonth(row.Month, row.Routes, row.DistanceKm, row.KmCity, row.KmHwy, row.FuelLiters, row.Co2eKg, row.Co2eWtwKg, row.BiogenicCo2Kg)
+		}
+	} else {
+		rows, err := s.InterfaceService.GetRouteEmissionsReportByUser(ctx, db.GetRouteEmissionsReportByUserParams{
+			UserID:    sql.NullInt64{Int64: data.UserID, Valid: true},
+			StartDate: start,
+			EndDate:   end,
+		})
+		if err != nil {
+			return EmissionsReport{}, fmt.Errorf("erro ao buscar emissões do usuário: %w", err)
+		}
+		for _, row := range rows {
+			byMonth[row.Month.Format("2006-01")] = newEmissionsReportMonth(row.Month, row.Routes, row.DistanceKm, row.KmCity, row.KmHwy, row.FuelLiters, row.Co2eKg, row.Co2eWtwKg, row.BiogenicCo2Kg)
+		}
+	}
+
+	report := EmissionsReport{
+		OrganizationID: data.OrganizationID,
+		StartMonth:     start.Format("2006-01"),
+		EndMonth:       end.AddDate(0, -1, 0).Format("2006-01"),
+		Total:          EmissionsReportMonth{Month: "total"},
+		Methodology:    emissionsMethodology,
+	}
+	// meses sem rotas entram zerados para a série ficar contínua
+	for month := start; month.Before(end); month = month.AddDate(0, 1, 0) {
+		key := month.Format("2006-01")
+		item, ok := byMonth[key]
+		if !ok {
+			item = EmissionsReportMonth{Month: key}
+		}
+		report.Months = append(report.Months, item)
+
+		report.Total.Routes += item.Routes
+		report.Total.DistanceKm += item.DistanceKm
+		report.Total.KmCity += item.KmCity
+		report.Total.KmHwy += item.KmHwy
+		report.Total.FuelLiters += item.FuelLiters
+		report.Total.CO2eKg += item.CO2eKg
+		report.Total.CO2eWTWKg += item.CO2eWTWKg
+		report.Total.BiogenicCO2Kg += item.BiogenicCO2Kg
+	}
+	report.Total = newEmissionsReportMonth(time.Time{}, report.Total.Routes, report.Total.DistanceKm, report.Total.KmCity,
+		report.Total.KmHwy, report.Total.FuelLiters, report.Total.CO2eKg, report.Total.CO2eWTWKg, report.Total.BiogenicCO2Kg)
+	report.Total.Month = "total"
+
+	return report, nil
+}
+
+// ExportEmissionsReport devolve o relatório ESG em CSV, um mês por linha e o total no fim
+func (s *Service) ExportEmissionsReport(ctx context.Context, data EmissionsReportRequest) (RouteExportFile, error) {
+	report, err := s.GetEmissionsReport(ctx, data)
+	if err != nil {
+		return RouteExportFile{}, err
+	}
+
+	content, err := emissionsReportCSV(report)
+	if err != nil {
+		return RouteExportFile{}, fmt.Errorf("erro ao gerar CSV de emissões: %w", err)
+	}
+
+	name := "emissoes"
+	if report.OrganizationID > 0 {
+		name = fmt.Sprintf("emissoes-organizacao-%d", report.OrganizationID)
+	}
+	return RouteExportFile{
+		FileName:    fmt.Sprintf("%s-%s-a-%s.csv", name, report.StartMonth, report.EndMonth),
+		ContentType: "text/csv; charset=utf-8",
+		Content:     content,
+	}, nil
+}
+
+func newEmissionsReportMonth(month time.Time, routes int64, distanceKm, kmCity, kmHwy, fuelLiters, co2eKg, co2eWTWKg, biogenicCO2Kg float64) EmissionsReportMonth {
+	item := EmissionsReportMonth{
+		Month:         month.Format("2006-01"),
+		Routes:        routes,
+		DistanceKm:    roundCents(distanceKm),
+		KmCity:        roundCents(kmCity),
+		KmHwy:         roundCents(kmHwy),
+		FuelLiters:    roundCents(fuelLiters),
+		CO2eKg:        roundCents(co2eKg),
+		CO2eWTWKg:     roundCents(co2eWTWKg),
+		BiogenicCO2Kg: roundCents(biogenicCO2Kg),
+	}
+	if distanceKm > 0 {
+		item.CO2ePerKm = roundThousandths(co2eKg / distanceKm)
+	}
+	return item
+}
+
+// emissionsReportPeriod interpreta os meses (AAAA-MM) e devolve o intervalo [primeiro dia do início, primeiro
+// dia do mês seguinte ao fim); sem datas usa os últimos 12 meses até o atual
+func emissionsReportPeriod(startMonth, endMonth string, now time.Time) (time.Time, time.Time, error) {
+	end := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	if endMonth != "" {
+		parsed, err := time.Parse("2006-01", endMonth)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("end_month inválido, use AAAA-MM: %w", err)
+		}
+		end = parsed
+	}
+	end = end.AddDate(0, 1, 0)
+
+	start := end.AddDate(0, -emissionsReportMonths, 0)
+	if startMonth != "" {
+		parsed, err := time.Parse("2006-01", startMonth)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("start_month inválido, use AAAA-MM: %w", err)
+		}
+		start = parsed
+	}
+
+	if !start.Before(end) {
+		return time.Time{}, time.Time{}, fmt.Errorf("start_month deve ser anterior ou igual a end_month")
+	}
+	if start.AddDate(5, 0, 0).Before(end) {
+		return time.Time{}, time.Time{}, fmt.Errorf("o período do relatório é limitado a 60 meses")
+	}
+	return start, end, nil
+}
+
+func emissionsReportCSV(report EmissionsReport) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	rows := [][]string{{"mes", "rotas", "distancia_km", "km_urbano", "km_rodovia", "combustivel_litros",
+		"co2e_kg", "co2e_poco_a_roda_kg", "co2_biogenico_kg", "co2e_kg_por_km"}}
+	for _, item := range append(report.Months, report.Total) {
+		rows = append(rows, []string{
+			item.Month,
+			strconv.FormatInt(item.Routes, 10),
+			formatCSVNumber(item.DistanceKm),
+			formatCSVNumber(item.KmCity),
+			formatCSVNumber(item.KmHwy),
+			formatCSVNumber(item.FuelLiters),
+			formatCSVNumber(item.CO2eKg),
+			formatCSVNumber(item.CO2eWTWKg),
+			formatCSVNumber(item.BiogenicCO2Kg),
+			formatCSVNumber(item.CO2ePerKm),
+		})
+	}
+	if err := writer.WriteAll(rows); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func formatCSVNumber(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
@@ -0,0 +1,170 @@
+package new_routes
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	db "geolocation/db/sqlc"
+	"geolocation/internal/routes"
+	"testing"
+)
+
+func TestRouteEmissions(t *testing.T) {
+	tests := []struct {
+		name        string
+		split       FuelSplit
+		vehicleType string
+		axles       int64
+		vehicle     VehicleInfo
+		want        *Emissions
+	}{
+		{name: "sem consumo não há emissões", split: FuelSplit{KmCity: 100, KmHwy: 300}, vehicleType: "auto"},
+		{
+			name: "carro queima gasolina C", split: FuelSplit{KmCity: 100, KmHwy: 300, LitersCity: 10, LitersHwy: 30}, vehicleType: "Auto",
+			want: &Emissions{Fuel: FuelGasoline, FuelLiters: 40, LoadAdjustment: 1, CO2eKg: 65.9, CO2eWTWKg: 82.78, BiogenicCO2Kg: 18.12, CO2ePerKm: 0.165},
+		},
+		{
+			name: "caminhão vazio sem ajuste de carga", split: FuelSplit{KmHwy: 400, LitersHwy: 100}, vehicleType: "truck", axles: 5,
+			want: &Emissions{Fuel: FuelDiesel, FuelLiters: 100, LoadAdjustment: 1, CO2eKg: 226.23, CO2eWTWKg: 279.28, BiogenicCO2Kg: 37.5, CO2ePerKm: 0.566},
+		},
+		{
+			// 26,5 t de capacidade em 5 eixos: lotado consome 15% a mais que à meia carga
+			name: "caminhão lotado", split: FuelSplit{KmHwy: 400, LitersHwy: 100}, vehicleType: "truck", axles: 5, vehicle: VehicleInfo{CargoWeight: 26.5},
+			want: &Emissions{Fuel: FuelDiesel, FuelLiters: 115, LoadFactor: 1, LoadAdjustment: 1.15, CO2eKg: 260.17, CO2eWTWKg: 321.17, BiogenicCO2Kg: 43.12, CO2ePerKm: 0.65},
+		},
+		{name: "ônibus usa diesel", split: FuelSplit{KmCity: 10, LitersCity: 4}, vehicleType: "bus", want: &Emissions{Fuel: FuelDiesel, FuelLiters: 4, LoadAdjustment: 1, CO2eKg: 9.05, CO2eWTWKg: 11.17, BiogenicCO2Kg: 1.5, CO2ePerKm: 0.905}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := routeEmissions(tt.split, tt.vehicleType, tt.axles, tt.vehicle)
+			if tt.want == nil {
+				if got != nil {
+					t.Fatalf("routeEmissions() = %+v, want nil", got)
+				}
+				return
+			}
+			if got == nil {
+				t.Fatal("routeEmissions() = nil")
+			}
+			tt.want.Methodology = emissionsMethodology
+			if *got != *tt.want {
+				t.Errorf("routeEmissions() = %+v\nwant %+v", *got, *tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadAdjustment(t *testing.T) {
+	tests := []struct {
+		name           string
+		vehicleType    string
+		axles          int64
+		cargo          float64
+		wantLoad       float64
+		wantAdjustment float64
+	}{
+		{name: "carro ignora a carga", vehicleType: "auto", axles: 2, cargo: 1, wantAdjustment: 1},
+		{name: "caminhão sem peso da carga", vehicleType: "truck", axles: 5, wantAdjustment: 1},
+		{name: "caminhão sem eixos", vehicleType: "truck", cargo: 10, wantAdjustment: 1},
+		{name: "meia carga é a referência", vehicleType: "Truck", axles: 5, cargo: 13.25, wantLoad: 0.5, wantAdjustment: 1},
+		{name: "um quarto da carga em 2 eixos", vehicleType: "truck", axles: 2, cargo: 2.5, wantLoad: 0.25, wantAdjustment: 0.925},
+		{name: "excesso limitado à capacidade", vehicleType: "truck", axles: 5, cargo: 40, wantLoad: 1, wantAdjustment: 1.15},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			load, adjustment := loadAdjustment(tt.vehicleType, tt.axles, VehicleInfo{CargoWeight: tt.cargo})
+			if roundThousandths(load) != tt.wantLoad || roundThousandths(adjustment) != tt.wantAdjustment {
+				t.Errorf("loadAdjustment() = %v, %v, want %v, %v", load, adjustment, tt.wantLoad, tt.wantAdjustment)
+			}
+		})
+	}
+}
+
+// emissionsRepository simula o histórico de rotas e guarda as emissões gravadas
+type emissionsRepository struct {
+	routes.InterfaceRepository
+	existing  bool
+	emissions []db.CreateRouteEmissionParams
+}
+
+func (r *emissionsRepository) GetRouteHistByUnique(context.Context, db.GetRouteHistByUniqueParams) (db.RouteHist, error) {
+	if !r.existing {
+		return db.RouteHist{}, sql.ErrNoRows
+	}
+	return db.RouteHist{ID: 5, NumberRequest: 3}, nil
+}
+
+func (r *emissionsRepository) CreateRouteHist(context.Context, db.CreateRouteHistParams) (db.RouteHist, error) {
+	return db.RouteHist{ID: 9, NumberRequest: 1}, nil
+}
+
+func (r *emissionsRepository) UpdateNumberOfRequestRequest(context.Context, db.UpdateNumberOfRequestParams) error {
+	return nil
+}
+
+func (r *emissionsRepository) CreateSavedRoutes(context.Context, db.CreateSavedRoutesParams) (db.SavedRoute, error) {
+	return db.SavedRoute{}, nil
+}
+
+func (r *emissionsRepository) CreateRouteEmission(_ context.Context, arg db.CreateRouteEmissionParams) error {
+	r.emissions = append(r.emissions, arg)
+	return nil
+}
+
+func TestSavedRoutesRecordsEmissionsOncePerHistory(t *testing.T) {
+	response, _ := json.Marshal(FinalOutput{Routes: []RouteOutput{{Summary: RouteSummary{
+		Distance:  Distance{Value: 400000},
+		Emissions: &Emissions{Fuel: FuelDiesel, FuelLiters: 100, CO2eKg: 226.23},
+	}}}})
+	// organization_id no body não vale: a organização vem do token
+	request := json.RawMessage(`{"type":"Truck","organization_id":99}`)
+
+	tests := []struct {
+		name      string
+		existing  bool
+		orgID     int64
+		wantCount int
+		wantHist  int64
+		wantOrg   sql.NullInt64
+	}{
+		{name: "rota nova grava com a organização do token", orgID: 3, wantCount: 1, wantHist: 9, wantOrg: sql.NullInt64{Int64: 3, Valid: true}},
+		{name: "rota nova sem token de organização fica do usuário", wantCount: 1, wantHist: 9},
+		{name: "repetição da rota não grava de novo", existing: true, orgID: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &emissionsRepository{existing: tt.existing}
+			s := &Service{InterfaceService: repo}
+			if _, err := s.savedRoutes(context.Background(), "private", "a", "b", "", 0, 7, response, request, false, tt.orgID); err != nil {
+				t.Fatalf("savedRoutes() erro = %v", err)
+			}
+			if len(repo.emissions) != tt.wantCount {
+				t.Fatalf("emissões gravadas = %d, want %d", len(repo.emissions), tt.wantCount)
+			}
+			if tt.wantCount == 0 {
+				return
+			}
+			got := repo.emissions[0]
+			if got.RouteHistID.Int64 != tt.wantHist || got.OrganizationID != tt.wantOrg || got.UserID.Int64 != 7 {
+				t.Errorf("emissão gravada com histórico %d, organização %+v e usuário %d", got.RouteHistID.Int64, got.OrganizationID, got.UserID.Int64)
+			}
+			if got.VehicleType != "truck" || got.DistanceKm != 400 {
+				t.Errorf("emissão gravada = %+v", got)
+			}
+		})
+	}
+}
+
+func TestRecordTotalRouteEmissionsNeedsTokenOrganization(t *testing.T) {
+	total := TotalSummary{Emissions: &Emissions{Fuel: FuelDiesel, FuelLiters: 10}}
+	for orgID, want := range map[int64]int{0: 0, 4: 1} {
+		repo := &emissionsRepository{}
+		(&Service{InterfaceService: repo}).recordTotalRouteEmissions(context.Background(), total, orgID, "truck")
+		if len(repo.emissions) != want {
+			t.Errorf("organização %d: emissões gravadas = %d, want %d", orgID, len(repo.emissions), want)
+		}
+	}
+}
@@ -0,0 +1,318 @@
+package new_routes
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	neturl "net/url"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	EngineOSRM        = "osrm"
+	EngineGraphHopper = "graphhopper"
+	EngineFake        = "fake"
+
+	defaultOSRMURL = "http://34.207.174.233:5000"
+	defaultProfile = "driving"
+)
+
+// ErrNoRoute indica que o motor respondeu, mas não encontrou nenhuma rota
+var ErrNoRoute = errors.New("nenhuma rota encontrada")
+
+// RoutingEngine abstrai o motor de roteamento (OSRM, GraphHopper, fake em memória).
+// As respostas são sempre devolvidas no formato OSRM, que é o formato usado pelo restante do serviço.
+type RoutingEngine interface {
+	Route(ctx context.Context, req RouteRequest) (OSRMResponse, error)
+	Alternatives(ctx context.Context, req RouteRequest, n int) (OSRMResponse, error)
+	Nearest(ctx context.Context, loc Location, profile string) (Location, error)
+	Table(ctx context.Context, sources, destinations []Location, profile string) (RouteTable, error)
+}
+
+// RouteRequest descreve uma rota a ser calculada pelo motor
+type RouteRequest struct {
+	Coordinates []Location
+	Profile     string
+	Exclude     []string
+	// AllowUTurn permite retorno nos waypoints (continue_straight=false no OSRM)
+	AllowUTurn bool
+}
+
+// vehicleProfile devolve o perfil do motor para o tipo de veículo da requisição. Veículos leves usam o
+// perfil padrão; caminhão e ônibus usam o perfil próprio, com queda para o padrão quando a instância não o tiver.
+func vehicleProfile(vehicleType string) string {
+	switch strings.ToLower(strings.TrimSpace(vehicleType)) {
+	case "truck":
+		return "truck"
+	case "bus":
+		return "bus"
+	default:
+		return defaultProfile
+	}
+}
+
+// RouteTable guarda a matriz de distâncias (metros) e durações (segundos).
+// Pares sem rota possível ficam com valor -1.
+type RouteTable struct {
+	Distances [][]float64
+	Durations [][]float64
+	// Profile é o perfil usado de fato; difere do pedido quando a instância não o tem e o motor cai no padrão
+	Profile string
+}
+
+// NewRoutingEngine cria o motor configurado para o deploy. Tipos desconhecidos caem no OSRM.
+func NewRoutingEngine(kind string, urls []string, apiKey string) RoutingEngine {
+	var clean []string
+	for _, u := range urls {
+		if u = strings.TrimRight(strings.TrimSpace(u), "/"); u != "" {
+			clean = append(clean, u)
+		}
+	}
+
+	switch strings.ToLower(strings.TrimSpace(kind)) {
+	case EngineFake:
+		return NewFakeEngine()
+	case EngineGraphHopper:
+		return NewGraphHopperEngine(clean, apiKey)
+	default:
+		if len(clean) == 0 {
+			clean = []string{defaultOSRMURL}
+		}
+		return NewOSRMEngine(clean)
+	}
+}
+
+// OSRMEngine consulta uma ou mais instâncias OSRM, com failover entre elas
+type OSRMEngine struct {
+	BaseURLs []string
+	Client   *http.Client
+	current  uint32
+}
+
+func NewOSRMEngine(baseURLs []string) *OSRMEngine {
+	return &OSRMEngine{
+		BaseURLs: baseURLs,
+		Client: &http.Client{
+			Timeout: 120 * time.Second,
+			Transport: &http.Transport{
+				MaxIdleConns:        100,
+				MaxIdleConnsPerHost: 20,
+				IdleConnTimeout:     30 * time.Second,
+				DisableCompression:  true, // OSRM não usa compressão
+				MaxConnsPerHost:     50,
+			},
+		},
+	}
+}
+
+func (o *OSRMEngine) Route(ctx context.Context, req RouteRequest) (OSRMResponse, error) {
+	return o.route(ctx, req, 0)
+}
+
+func (o *OSRMEngine) Alternatives(ctx context.Context, req RouteRequest, n int) (OSRMResponse, error) {
+	return o.route(ctx, req, n)
+}
+
+func (o *OSRMEngine) route(ctx context.Context, req RouteRequest, alternatives int) (OSRMResponse, error) {
+	if len(req.Coordinates) < 2 {
+		return OSRMResponse{}, fmt.Errorf("são necessárias ao menos duas coordenadas")
+	}
+
+	params := neturl.Values{
+		"alternatives": {fmt.Sprintf("%d", alternatives)},
+		"steps":        {"true"},
+		"overview":     {"full"},
+	}
+	if req.AllowUTurn {
+		params.Set("continue_straight", "false")
+	}
+	if len(req.Exclude) > 0 {
+		params.Set("exclude", strings.Join(req.Exclude, ","))
+	}
+
+	profile := req.Profile
+	if profile == "" {
+		profile = defaultProfile
+	}
+	coords := neturl.PathEscape(osrmCoordinates(req.Coordinates))
+
+	var osrmResp OSRMResponse
+	err := o.get(ctx, fmt.Sprintf("/route/v1/%s/%s?%s", profile, coords, params.Encode()), &osrmResp)
+	if (err != nil || osrmResp.Code != "Ok") && profile != defaultProfile {
+		// Perfis específicos (ex: "truck") podem não existir na instância; usa o perfil padrão
+		osrmResp = OSRMResponse{}
+		err = o.get(ctx, fmt.Sprintf("/route/v1/%s/%s?%s", defaultProfile, coords, params.Encode()), &osrmResp)
+	}
+	if err != nil {
+		return OSRMResponse{}, err
+	}
+	if osrmResp.Code != "Ok" || len(osrmResp.Routes) == 0 {
+		return osrmResp, ErrNoRoute
+	}
+	return osrmResp, nil
+}
+
+// Nearest encaixa o ponto na via mais próxima transitável pelo perfil, caindo no padrão como a rota
+func (o *OSRMEngine) Nearest(ctx context.Context, loc Location, profile string) (Location, error) {
+	type nearestResponse struct {
+		Code      string `json:"code"`
+		Waypoints []struct {
+			Location []float64 `json:"location"`
+		} `json:"waypoints"`
+	}
+	if profile == "" {
+		profile = defaultProfile
+	}
+
+	var r nearestResponse
+	err := o.get(ctx, fmt.Sprintf("/nearest/v1/%s/%.6f,%.6f?number=1", profile, loc.Longitude, loc.Latitude), &r)
+	if (err != nil || r.Code != "Ok") && profile != defaultProfile {
+		r = nearestResponse{}
+		err = o.get(ctx, fmt.Sprintf("/nearest/v1/%s/%.6f,%.6f?number=1", defaultProfile, loc.Longitude, loc.Latitude), &r)
+	}
+	if err != nil {
+		return loc, err
+	}
+	if len(r.Waypoints) == 0 || len(r.Waypoints[0].Location) < 2 {
+		return loc, ErrNoRoute
+	}
+	return Location{Latitude: r.Waypoints[0].Location[1], Longitude: r.Waypoints[0].Location[0]}, nil
+}
+
+func (o *OSRMEngine) Table(ctx context.Context, sources, destinations []Location, profile string) (RouteTable, error) {
+	if len(sources) == 0 || len(destinations) == 0 {
+		return RouteTable{}, fmt.Errorf("origens e destinos são obrigatórios")
+	}
+
+	all := append(append([]Location{}, sources...), destinations...)
+	src := make([]string, len(sources))
+	for i := range sources {
+		src[i] = fmt.Sprintf("%d", i)
+	}
+	dst := make([]string, len(destinations))
+	for i := range destinations {
+		dst[i] = fmt.Sprintf("%d", len(sources)+i)
+	}
+
+	params := neturl.Values{
+		"sources":      {strings.Join(src, ";")},
+		"destinations": {strings.Join(dst, ";")},
+		"annotations":  {"distance,duration"},
+	}
+
+	if profile == "" {
+		profile = defaultProfile
+	}
+	type tableResponse struct {
+		Code      string       `json:"code"`
+		Distances [][]*float64 `json:"distances"`
+		Durations [][]*float64 `json:"durations"`
+	}
+	coords := neturl.PathEscape(osrmCoordinates(all))
+
+	var r tableResponse
+	err := o.get(ctx, fmt.Sprintf("/table/v1/%s/%s?%s", profile, coords, params.Encode()), &r)
+	if (err != nil || r.Code != "Ok") && profile != defaultProfile {
+		// Mesmo fallback da rota: a instância pode não ter o perfil do veículo
+		r = tableResponse{}
+		profile = defaultProfile
+		err = o.get(ctx, fmt.Sprintf("/table/v1/%s/%s?%s", defaultProfile, coords, params.Encode()), &r)
+	}
+	if err != nil {
+		return RouteTable{}, err
+	}
+	if r.Code != "Ok" {
+		return RouteTable{}, fmt.Errorf("OSRM table retornou código %s", r.Code)
+	}
+
+	return RouteTable{
+		Distances: flattenNullMatrix(r.Distances),
+		Durations: flattenNullMatrix(r.Durations),
+		Profile:   profile,
+	}, nil
+}
+
+// get executa a requisição na instância atual e, em caso de falha, tenta as demais
+func (o *OSRMEngine) get(ctx context.Context, path string, out interface{}) error {
+	n := len(o.BaseURLs)
+	if n == 0 {
+		return fmt.Errorf("nenhuma instância OSRM configurada")
+	}
+
+	start := int(atomic.LoadUint32(&o.current))
+	var lastErr error
+	for i := 0; i < n; i++ {
+		idx := (start + i) % n
+		lastErr = getJSON(ctx, o.Client, o.BaseURLs[idx]+path, out)
+		if lastErr == nil {
+			if idx != start {
+				log.Printf("⚠️ OSRM: failover para %s", o.BaseURLs[idx])
+				atomic.StoreUint32(&o.current, uint32(idx))
+			}
+			return nil
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return fmt.Errorf("erro na requisição OSRM: %w", lastErr)
+}
+
+func getJSON(ctx context.Context, client *http.Client, url string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// 4xx do OSRM (ex: NoRoute) ainda traz corpo JSON válido; só 5xx indica instância com problema
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return fmt.Errorf("status %d", resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+func osrmCoordinates(locs []Location) string {
+	parts := make([]string, len(locs))
+	for i, l := range locs {
+		parts[i] = fmt.Sprintf("%f,%f", l.Longitude, l.Latitude)
+	}
+	return strings.Join(parts, ";")
+}
+
+func flattenNullMatrix(m [][]*float64) [][]float64 {
+	out := make([][]float64, len(m))
+	for i, row := range m {
+		out[i] = make([]float64, len(row))
+		for j, v := range row {
+			if v == nil {
+				out[i][j] = -1
+				continue
+			}
+			out[i][j] = *v
+		}
+	}
+	return out
+}
+
+// parseOSRMCoordinates converte a lista "lon,lat;lon,lat" usada nas URLs do OSRM em localizações
+func parseOSRMCoordinates(coords string) []Location {
+	var locs []Location
+	for _, part := range strings.Split(coords, ";") {
+		var lon, lat float64
+		if _, err := fmt.Sscanf(part, "%f,%f", &lon, &lat); err != nil {
+			continue
+		}
+		locs = append(locs, Location{Latitude: lat, Longitude: lon})
+	}
+	return locs
+}
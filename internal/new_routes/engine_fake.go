@@ -0,0 +1,84 @@
+package new_routes
+
+import (
+	"context"
+	"fmt"
+)
+
+// fakeSpeedMps é a velocidade média usada pelo motor fake (60 km/h)
+const fakeSpeedMps = 60.0 / 3.6
+
+// FakeEngine é um motor em memória que liga os pontos em linha reta.
+// Serve para rodar o serviço e os testes sem depender de uma instância de roteamento.
+type FakeEngine struct{}
+
+func NewFakeEngine() *FakeEngine {
+	return &FakeEngine{}
+}
+
+func (f *FakeEngine) Route(ctx context.Context, req RouteRequest) (OSRMResponse, error) {
+	if len(req.Coordinates) < 2 {
+		return OSRMResponse{}, fmt.Errorf("são necessárias ao menos duas coordenadas")
+	}
+
+	route := OSRMRoute{}
+	points := make([]LatLng, 0, len(req.Coordinates))
+	for i, c := range req.Coordinates {
+		points = append(points, LatLng{Lat: c.Latitude, Lng: c.Longitude})
+		if i == 0 {
+			continue
+		}
+		prev := req.Coordinates[i-1]
+		dist := haversineDistanceTolls(prev.Latitude, prev.Longitude, c.Latitude, c.Longitude)
+		dur := dist / fakeSpeedMps
+
+		depart := OSRMStep{Distance: dist, Duration: dur}
+		depart.Maneuver.Type = "depart"
+		depart.Maneuver.Location = [2]float64{prev.Longitude, prev.Latitude}
+		depart.Geometry = encodePolyline([]LatLng{{Lat: prev.Latitude, Lng: prev.Longitude}, {Lat: c.Latitude, Lng: c.Longitude}})
+
+		arrive := OSRMStep{}
+		arrive.Maneuver.Type = "arrive"
+		arrive.Maneuver.Location = [2]float64{c.Longitude, c.Latitude}
+		arrive.Geometry = encodePolyline([]LatLng{{Lat: c.Latitude, Lng: c.Longitude}})
+
+		route.Legs = append(route.Legs, OSRMLeg{
+			Distance: dist,
+			Duration: dur,
+			Steps:    []OSRMStep{depart, arrive},
+		})
+		route.Distance += dist
+		route.Duration += dur
+	}
+	route.Geometry = encodePolyline(points)
+
+	return OSRMResponse{Code: "Ok", Routes: []OSRMRoute{route}}, nil
+}
+
+// Alternatives devolve sempre uma única rota: em linha reta não há alternativa
+func (f *FakeEngine) Alternatives(ctx context.Context, req RouteRequest, n int) (OSRMResponse, error) {
+	return f.Route(ctx, req)
+}
+
+func (f *FakeEngine) Nearest(ctx context.Context, loc Location, profile string) (Location, error) {
+	return loc, nil
+}
+
+func (f *FakeEngine) Table(ctx context.Context, sources, destinations []Location, profile string) (RouteTable, error) {
+	// Em linha reta não há perfil por veículo: todos andam como o perfil padrão
+	table := RouteTable{
+		Distances: make([][]float64, len(sources)),
+		Durations: make([][]float64, len(sources)),
+		Profile:   defaultProfile,
+	}
+	for i, src := range sources {
+		table.Distances[i] = make([]float64, len(destinations))
+		table.Durations[i] = make([]float64, len(destinations))
+		for j, dst := range destinations {
+			dist := haversineDistanceTolls(src.Latitude, src.Longitude, dst.Latitude, dst.Longitude)
+			table.Distances[i][j] = dist
+			table.Durations[i][j] = dist / fakeSpeedMps
+		}
+	}
+	return table, nil
+}
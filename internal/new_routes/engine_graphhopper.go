@@ -0,0 +1,338 @@
+package new_routes
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// GraphHopperEngine adapta a API do GraphHopper (/route, /nearest, /matrix) para o formato OSRM
+type GraphHopperEngine struct {
+	BaseURLs []string
+	APIKey   string
+	Client   *http.Client
+	current  uint32
+}
+
+func NewGraphHopperEngine(baseURLs []string, apiKey string) *GraphHopperEngine {
+	return &GraphHopperEngine{
+		BaseURLs: baseURLs,
+		APIKey:   apiKey,
+		Client:   &http.Client{Timeout: 120 * time.Second},
+	}
+}
+
+type ghInstruction struct {
+	Distance   float64 `json:"distance"`
+	Time       float64 `json:"time"`
+	Text       string  `json:"text"`
+	Sign       int     `json:"sign"`
+	Interval   [2]int  `json:"interval"`
+	StreetName string  `json:"street_name"`
+	StreetRef  string  `json:"street_ref"`
+}
+
+type ghPath struct {
+	Distance     float64         `json:"distance"`
+	Time         float64         `json:"time"`
+	Points       string          `json:"points"`
+	Instructions []ghInstruction `json:"instructions"`
+}
+
+type ghRouteResponse struct {
+	Paths   []ghPath `json:"paths"`
+	Message string   `json:"message"`
+}
+
+func (g *GraphHopperEngine) Route(ctx context.Context, req RouteRequest) (OSRMResponse, error) {
+	return g.route(ctx, req, 0)
+}
+
+func (g *GraphHopperEngine) Alternatives(ctx context.Context, req RouteRequest, n int) (OSRMResponse, error) {
+	return g.route(ctx, req, n)
+}
+
+func (g *GraphHopperEngine) route(ctx context.Context, req RouteRequest, alternatives int) (OSRMResponse, error) {
+	if len(req.Coordinates) < 2 {
+		return OSRMResponse{}, fmt.Errorf("são necessárias ao menos duas coordenadas")
+	}
+
+	body := map[string]interface{}{
+		"points":         ghPoints(req.Coordinates),
+		"profile":        ghProfile(req.Profile),
+		"instructions":   true,
+		"calc_points":    true,
+		"points_encoded": true,
+		"locale":         "pt_BR",
+	}
+	if alternatives > 0 && len(req.Coordinates) == 2 {
+		body["algorithm"] = "alternative_route"
+		body["alternative_route.max_paths"] = alternatives + 1
+	}
+	if priority := ghExclusions(req.Exclude); len(priority) > 0 {
+		// custom_model exige o modo flexível (sem contraction hierarchies)
+		body["ch.disable"] = true
+		body["custom_model"] = map[string]interface{}{"priority": priority}
+	}
+
+	var r ghRouteResponse
+	if err := g.post(ctx, "/route", body, &r); err != nil {
+		return OSRMResponse{}, err
+	}
+	if len(r.Paths) == 0 {
+		return OSRMResponse{}, ErrNoRoute
+	}
+
+	out := OSRMResponse{Code: "Ok"}
+	for _, p := range r.Paths {
+		out.Routes = append(out.Routes, ghPathToOSRM(p))
+	}
+	return out, nil
+}
+
+// Nearest usa o /nearest do GraphHopper, que encaixa na via mais próxima sem distinguir perfil
+func (g *GraphHopperEngine) Nearest(ctx context.Context, loc Location, profile string) (Location, error) {
+	var r struct {
+		Coordinates []float64 `json:"coordinates"`
+	}
+	path := fmt.Sprintf("/nearest?point=%.6f,%.6f", loc.Latitude, loc.Longitude)
+	if err := g.get(ctx, path, &r); err != nil {
+		return loc, err
+	}
+	if len(r.Coordinates) < 2 {
+		return loc, ErrNoRoute
+	}
+	return Location{Latitude: r.Coordinates[1], Longitude: r.Coordinates[0]}, nil
+}
+
+func (g *GraphHopperEngine) Table(ctx context.Context, sources, destinations []Location, profile string) (RouteTable, error) {
+	if len(sources) == 0 || len(destinations) == 0 {
+		return RouteTable{}, fmt.Errorf("origens e destinos são obrigatórios")
+	}
+	if profile == "" {
+		profile = defaultProfile
+	}
+
+	body := map[string]interface{}{
+		"from_points": ghPoints(sources),
+		"to_points":   ghPoints(destinations),
+		"out_arrays":  []string{"distances", "times"},
+		"profile":     ghProfile(profile),
+	}
+	var r struct {
+		Distances [][]*float64 `json:"distances"`
+		Times     [][]*float64 `json:"times"`
+	}
+	if err := g.post(ctx, "/matrix", body, &r); err != nil {
+		return RouteTable{}, err
+	}
+
+	return RouteTable{
+		Distances: flattenNullMatrix(r.Distances),
+		Durations: flattenNullMatrix(r.Times),
+		Profile:   profile,
+	}, nil
+}
+
+func (g *GraphHopperEngine) get(ctx context.Context, path string, out interface{}) error {
+	return g.do(ctx, func(base string) (*http.Request, error) {
+		return http.NewRequestWithContext(ctx, http.MethodGet, g.withKey(base+path), nil)
+	}, out)
+}
+
+func (g *GraphHopperEngine) post(ctx context.Context, path string, body interface{}, out interface{}) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	return g.do(ctx, func(base string) (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, g.withKey(base+path), bytes.NewReader(payload))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		return req, nil
+	}, out)
+}
+
+// do executa a requisição na instância atual e, em caso de falha, tenta as demais
+func (g *GraphHopperEngine) do(ctx context.Context, build func(base string) (*http.Request, error), out interface{}) error {
+	n := len(g.BaseURLs)
+	if n == 0 {
+		return fmt.Errorf("nenhuma instância GraphHopper configurada")
+	}
+
+	start := int(atomic.LoadUint32(&g.current))
+	var lastErr error
+	for i := 0; i < n; i++ {
+		idx := (start + i) % n
+		req, err := build(g.BaseURLs[idx])
+		if err != nil {
+			return err
+		}
+		lastErr = g.send(req, out)
+		if lastErr == nil {
+			if idx != start {
+				log.Printf("⚠️ GraphHopper: failover para %s", g.BaseURLs[idx])
+				atomic.StoreUint32(&g.current, uint32(idx))
+			}
+			return nil
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return fmt.Errorf("erro na requisição GraphHopper: %w", lastErr)
+}
+
+func (g *GraphHopperEngine) send(req *http.Request, out interface{}) error {
+	resp, err := g.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return fmt.Errorf("status %d", resp.StatusCode)
+	}
+	if resp.StatusCode != http.StatusOK {
+		var e struct {
+			Message string `json:"message"`
+		}
+		_ = json.NewDecoder(resp.Body).Decode(&e)
+		log.Printf("GraphHopper retornou status %d: %s", resp.StatusCode, e.Message)
+		return ErrNoRoute
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+func (g *GraphHopperEngine) withKey(url string) string {
+	if g.APIKey == "" {
+		return url
+	}
+	sep := "?"
+	if bytes.ContainsRune([]byte(url), '?') {
+		sep = "&"
+	}
+	return url + sep + "key=" + g.APIKey
+}
+
+func ghPoints(locs []Location) [][2]float64 {
+	points := make([][2]float64, len(locs))
+	for i, l := range locs {
+		points[i] = [2]float64{l.Longitude, l.Latitude}
+	}
+	return points
+}
+
+func ghProfile(profile string) string {
+	switch profile {
+	case "", defaultProfile, "car":
+		return "car"
+	default:
+		return profile
+	}
+}
+
+// ghExclusions traduz os "exclude" do OSRM para regras de prioridade do custom_model
+func ghExclusions(exclude []string) []map[string]string {
+	var rules []map[string]string
+	for _, ex := range exclude {
+		var cond string
+		switch ex {
+		case "toll":
+			cond = "toll != NO"
+		case "motorway":
+			cond = "road_class == MOTORWAY"
+		case "ferry":
+			cond = "road_environment == FERRY"
+		default:
+			continue
+		}
+		rules = append(rules, map[string]string{"if": cond, "multiply_by": "0"})
+	}
+	return rules
+}
+
+// ghPathToOSRM converte um path do GraphHopper em rota OSRM, separando as pernas nos pontos de passagem
+func ghPathToOSRM(p ghPath) OSRMRoute {
+	points, _ := decodePolyline(p.Points)
+	route := OSRMRoute{
+		Distance: p.Distance,
+		Duration: p.Time / 1000,
+		Geometry: p.Points,
+	}
+
+	var leg OSRMLeg
+	for i, ins := range p.Instructions {
+		step := OSRMStep{
+			Distance: ins.Distance,
+			Duration: ins.Time / 1000,
+			Name:     ins.StreetName,
+			Ref:      ins.StreetRef,
+		}
+		from, to := ins.Interval[0], ins.Interval[1]
+		if from >= 0 && from < len(points) {
+			step.Maneuver.Location = [2]float64{points[from].Lng, points[from].Lat}
+		}
+		if from >= 0 && to < len(points) && from <= to {
+			step.Geometry = encodePolyline(points[from : to+1])
+		}
+		step.Maneuver.Type, step.Maneuver.Modifier = ghManeuver(ins.Sign, i == 0)
+
+		leg.Steps = append(leg.Steps, step)
+		leg.Distance += step.Distance
+		leg.Duration += step.Duration
+
+		// sign 5 = ponto de passagem alcançado, sign 4 = destino final
+		if ins.Sign == 5 || ins.Sign == 4 {
+			route.Legs = append(route.Legs, leg)
+			leg = OSRMLeg{}
+		}
+	}
+	if len(leg.Steps) > 0 {
+		route.Legs = append(route.Legs, leg)
+	}
+	return route
+}
+
+func ghManeuver(sign int, first bool) (string, string) {
+	if first {
+		return "depart", ""
+	}
+	switch sign {
+	case -3:
+		return "turn", "sharp left"
+	case -2:
+		return "turn", "left"
+	case -1:
+		return "turn", "slight left"
+	case 0:
+		return "new name", "straight"
+	case 1:
+		return "turn", "slight right"
+	case 2:
+		return "turn", "right"
+	case 3:
+		return "turn", "sharp right"
+	case 4, 5:
+		return "arrive", ""
+	case 6:
+		return "roundabout", ""
+	case -6:
+		return "exit roundabout", ""
+	case -7:
+		return "fork", "slight left"
+	case 7:
+		return "fork", "slight right"
+	case -8, 8, -98:
+		return "turn", "uturn"
+	default:
+		return "continue", ""
+	}
+}
@@ -0,0 +1,218 @@
+package new_routes
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// engineServer é uma instância do motor em memória: responde pelo handler e guarda os caminhos pedidos
+type engineServer struct {
+	*httptest.Server
+	mu    sync.Mutex
+	paths []string
+}
+
+func newEngineServer(t *testing.T, handler func(w http.ResponseWriter, r *http.Request)) *engineServer {
+	t.Helper()
+	srv := &engineServer{}
+	srv.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		srv.mu.Lock()
+		srv.paths = append(srv.paths, r.URL.Path)
+		srv.mu.Unlock()
+		handler(w, r)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func (s *engineServer) requested() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string{}, s.paths...)
+}
+
+func failing(w http.ResponseWriter, _ *http.Request) {
+	w.WriteHeader(http.StatusServiceUnavailable)
+}
+
+const osrmRouteOK = `{"code":"Ok","routes":[{"distance":1200,"duration":90,"geometry":"abc","legs":[{"distance":1200,"duration":90}]}]}`
+
+var twoPoints = []Location{{Latitude: -23.5, Longitude: -46.6}, {Latitude: -23.6, Longitude: -46.7}}
+
+func TestOSRMEngineFailover(t *testing.T) {
+	down := newEngineServer(t, failing)
+	up := newEngineServer(t, func(w http.ResponseWriter, _ *http.Request) {
+		io.WriteString(w, osrmRouteOK)
+	})
+	engine := NewOSRMEngine([]string{down.URL, up.URL})
+
+	resp, err := engine.Route(context.Background(), RouteRequest{Coordinates: twoPoints})
+	if err != nil {
+		t.Fatalf("Route() erro = %v", err)
+	}
+	if len(resp.Routes) != 1 || resp.Routes[0].Distance != 1200 {
+		t.Fatalf("Route() = %+v", resp)
+	}
+
+	// Depois do failover a instância que respondeu passa a ser a primeira tentativa
+	if _, err := engine.Route(context.Background(), RouteRequest{Coordinates: twoPoints}); err != nil {
+		t.Fatalf("segunda Route() erro = %v", err)
+	}
+	if got := len(down.requested()); got != 1 {
+		t.Errorf("instância fora do ar recebeu %d requisições, want 1", got)
+	}
+	if got := len(up.requested()); got != 2 {
+		t.Errorf("instância ativa recebeu %d requisições, want 2", got)
+	}
+
+	all := NewOSRMEngine([]string{down.URL})
+	if _, err := all.Route(context.Background(), RouteRequest{Coordinates: twoPoints}); err == nil {
+		t.Error("Route() sem instância no ar não retornou erro")
+	}
+}
+
+func TestOSRMEngineProfileFallback(t *testing.T) {
+	// A instância só tem o perfil padrão: os demais respondem InvalidUrl
+	srv := newEngineServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if !strings.Contains(r.URL.Path, "/"+defaultProfile+"/") {
+			w.WriteHeader(http.StatusBadRequest)
+			io.WriteString(w, `{"code":"InvalidUrl"}`)
+			return
+		}
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/route/"):
+			io.WriteString(w, osrmRouteOK)
+		case strings.HasPrefix(r.URL.Path, "/nearest/"):
+			io.WriteString(w, `{"code":"Ok","waypoints":[{"location":[-46.61,-23.51]}]}`)
+		case strings.HasPrefix(r.URL.Path, "/table/"):
+			io.WriteString(w, `{"code":"Ok","distances":[[0,null]],"durations":[[0,null]]}`)
+		}
+	})
+	engine := NewOSRMEngine([]string{srv.URL})
+	ctx := context.Background()
+
+	if _, err := engine.Route(ctx, RouteRequest{Coordinates: twoPoints, Profile: "truck"}); err != nil {
+		t.Fatalf("Route() erro = %v", err)
+	}
+	loc, err := engine.Nearest(ctx, twoPoints[0], "truck")
+	if err != nil {
+		t.Fatalf("Nearest() erro = %v", err)
+	}
+	if loc.Latitude != -23.51 || loc.Longitude != -46.61 {
+		t.Errorf("Nearest() = %+v", loc)
+	}
+	table, err := engine.Table(ctx, twoPoints[:1], twoPoints, "truck")
+	if err != nil {
+		t.Fatalf("Table() erro = %v", err)
+	}
+	if table.Profile != defaultProfile || table.Distances[0][1] != -1 || table.Durations[0][1] != -1 {
+		t.Errorf("Table() = %+v, want perfil padrão e par sem rota em -1", table)
+	}
+
+	var profiles []string
+	for _, path := range srv.requested() {
+		profiles = append(profiles, strings.Split(path, "/")[3])
+	}
+	want := []string{"truck", defaultProfile, "truck", defaultProfile, "truck", defaultProfile}
+	if strings.Join(profiles, ",") != strings.Join(want, ",") {
+		t.Errorf("perfis pedidos = %v, want %v", profiles, want)
+	}
+}
+
+func TestOSRMEngineNoRoute(t *testing.T) {
+	srv := newEngineServer(t, func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		io.WriteString(w, `{"code":"NoRoute","routes":[]}`)
+	})
+	_, err := NewOSRMEngine([]string{srv.URL}).Route(context.Background(), RouteRequest{Coordinates: twoPoints})
+	if !errors.Is(err, ErrNoRoute) {
+		t.Errorf("Route() erro = %v, want ErrNoRoute", err)
+	}
+	// NoRoute é resposta válida da instância: não há failover nem nova tentativa
+	if got := len(srv.requested()); got != 1 {
+		t.Errorf("requisições = %d, want 1", got)
+	}
+}
+
+func TestGraphHopperEngineRoute(t *testing.T) {
+	var body map[string]interface{}
+	down := newEngineServer(t, failing)
+	up := newEngineServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("key") != "chave" {
+			t.Errorf("requisição sem a chave da API: %s", r.URL)
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		io.WriteString(w, `{"paths":[{"distance":5000,"time":300000,"points":"`+encodePolyline([]LatLng{{Lat: -23.5, Lng: -46.6}, {Lat: -23.6, Lng: -46.7}})+`",
+			"instructions":[{"distance":5000,"time":300000,"text":"Siga","sign":0,"interval":[0,1],"street_name":"Rodovia","street_ref":"SP-330"},
+			{"distance":0,"time":0,"text":"Chegou","sign":4,"interval":[1,1]}]}]}`)
+	})
+	engine := NewGraphHopperEngine([]string{down.URL, up.URL}, "chave")
+
+	resp, err := engine.Route(context.Background(), RouteRequest{Coordinates: twoPoints, Profile: defaultProfile, Exclude: []string{"toll"}})
+	if err != nil {
+		t.Fatalf("Route() erro = %v", err)
+	}
+	route := resp.Routes[0]
+	if route.Distance != 5000 || route.Duration != 300 || len(route.Legs) != 1 || route.Legs[0].Steps[0].Ref != "SP-330" {
+		t.Errorf("rota convertida = %+v", route)
+	}
+	if body["profile"] != "car" || body["ch.disable"] != true {
+		t.Errorf("corpo enviado = %v, want perfil car e custom_model sem CH", body)
+	}
+	if len(down.requested()) != 1 {
+		t.Errorf("instância fora do ar recebeu %d requisições, want 1", len(down.requested()))
+	}
+}
+
+func TestGraphHopperEngineRejectedRequest(t *testing.T) {
+	srv := newEngineServer(t, func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		io.WriteString(w, `{"message":"Cannot find point 0"}`)
+	})
+	_, err := NewGraphHopperEngine([]string{srv.URL}, "").Route(context.Background(), RouteRequest{Coordinates: twoPoints})
+	if !errors.Is(err, ErrNoRoute) {
+		t.Errorf("Route() erro = %v, want ErrNoRoute", err)
+	}
+}
+
+func TestFakeEngineMatchesTable(t *testing.T) {
+	engine := NewRoutingEngine(EngineFake, nil, "")
+	ctx := context.Background()
+	stops := []Location{twoPoints[0], twoPoints[1], {Latitude: -23.7, Longitude: -46.6}}
+
+	resp, err := engine.Route(ctx, RouteRequest{Coordinates: stops})
+	if err != nil {
+		t.Fatalf("Route() erro = %v", err)
+	}
+	route := resp.Routes[0]
+	if len(route.Legs) != 2 {
+		t.Fatalf("pernas = %d, want 2", len(route.Legs))
+	}
+
+	table, err := engine.Table(ctx, stops[:2], stops[1:], "truck")
+	if err != nil {
+		t.Fatalf("Table() erro = %v", err)
+	}
+	// A rota soma as pernas em linha reta, as mesmas distâncias da matriz
+	if sum := table.Distances[0][0] + table.Distances[1][1]; math.Abs(route.Distance-sum) > 1e-6 {
+		t.Errorf("distância da rota = %v, want %v", route.Distance, sum)
+	}
+	if math.Abs(route.Duration-route.Distance/fakeSpeedMps) > 1e-6 {
+		t.Errorf("duração = %v, want %v a 60 km/h", route.Duration, route.Distance/fakeSpeedMps)
+	}
+
+	if _, err := engine.Route(ctx, RouteRequest{Coordinates: stops[:1]}); err == nil {
+		t.Error("Route() com um ponto não retornou erro")
+	}
+	if loc, _ := engine.Nearest(ctx, stops[2], "truck"); loc != stops[2] {
+		t.Errorf("Nearest() = %+v, want o próprio ponto", loc)
+	}
+}
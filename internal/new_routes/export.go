@@ -0,0 +1,539 @@
+package new_routes
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	db "geolocation/db/sqlc"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	ExportFormatGPX     = "gpx"
+	ExportFormatKML     = "kml"
+	ExportFormatGeoJSON = "geojson"
+
+	ExportSourceRouteHist = "route_hist"
+	ExportSourceFavorite  = "favorite"
+
+	exportKindOrigin      = "origin"
+	exportKindDestination = "destination"
+	exportKindToll        = "toll"
+	exportKindBalanca     = "balanca"
+	exportKindGasStation  = "gas_station"
+	exportKindRoute       = "route"
+	exportKindAttention   = "attention_zone"
+)
+
+// RouteExportRequest identifica a rota gravada a exportar. Source indica a tabela de origem:
+// route_hist (histórico) ou favorite (favorite_route do usuário). saved_routes não tem dono e não é exportada.
+type RouteExportRequest struct {
+	Source     string `json:"source" validate:"required,oneof=route_hist favorite"`
+	ID         int64  `json:"id" validate:"required,gt=0"`
+	Format     string `json:"format" validate:"required,oneof=gpx kml geojson"`
+	RouteIndex int64  `json:"route_index" validate:"gte=0"`
+	UserID     int64  `json:"-"`
+}
+
+// RouteExportFile é o arquivo gerado, pronto para ser devolvido como anexo
+type RouteExportFile struct {
+	FileName    string
+	ContentType string
+	Content     []byte
+}
+
+// routeExport reúne o que vai para qualquer formato: a linha da rota, os pontos (origem, destino,
+// pedágios, balanças e postos) e os trechos dentro de zonas de atenção
+type routeExport struct {
+	Name     string
+	Line     []LatLng
+	Distance float64
+	Duration float64
+	Points   []exportPoint
+	Segments []exportSegment
+}
+
+type exportPoint struct {
+	Kind        string
+	Name        string
+	Description string
+	Location    Location
+	Properties  map[string]interface{}
+}
+
+type exportSegment struct {
+	Name       string
+	Points     []LatLng
+	Properties map[string]interface{}
+}
+
+// ExportRoute gera o GPX, KML ou GeoJSON da rota escolhida numa resposta gravada
+func (s *Service) ExportRoute(ctx context.Context, data RouteExportRequest) (RouteExportFile, error) {
+	response, err := s.exportedResponse(ctx, data)
+	if err != nil {
+		return RouteExportFile{}, err
+	}
+
+	var output FinalOutput
+	if err := json.Unmarshal(response, &output); err != nil {
+		return RouteExportFile{}, fmt.Errorf("erro ao ler rota: %w", err)
+	}
+	if data.RouteIndex < 0 || int(data.RouteIndex) >= len(output.Routes) {
+		return RouteExportFile{}, errors.New("rota escolhida inválida")
+	}
+
+	export, err := newRouteExport(output.Summary, output.Routes[data.RouteIndex])
+	if err != nil {
+		return RouteExportFile{}, err
+	}
+
+	file := RouteExportFile{FileName: fmt.Sprintf("rota-%s-%d.%s", strings.ReplaceAll(data.Source, "_", "-"), data.ID, data.Format)}
+	switch data.Format {
+	case ExportFormatGPX:
+		file.ContentType = "application/gpx+xml"
+		file.Content, err = export.gpx()
+	case ExportFormatKML:
+		file.ContentType = "application/vnd.google-earth.kml+xml"
+		file.Content, err = export.kml()
+	default:
+		file.ContentType = "application/geo+json"
+		file.Content, err = json.Marshal(export.featureCollection())
+	}
+	if err != nil {
+		return RouteExportFile{}, fmt.Errorf("erro ao gerar arquivo %s: %w", data.Format, err)
+	}
+	return file, nil
+}
+
+// exportedResponse busca a resposta gravada. Histórico privado e favoritos só são exportados pelo dono.
+func (s *Service) exportedResponse(ctx context.Context, data RouteExportRequest) (json.RawMessage, error) {
+	var response json.RawMessage
+	var err error
+	switch data.Source {
+	case ExportSourceRouteHist:
+		routeHist, err := s.routeHistForUser(ctx, data.ID, data.UserID)
+		if err != nil {
+			return nil, err
+		}
+		return routeHist.Response, nil
+	case ExportSourceFavorite:
+		var favorite db.FavoriteRoute
+		favorite, err = s.InterfaceService.GetFavoriteRouteByID(ctx, db.GetFavoriteRouteByIDParams{ID: data.ID, IDUser: data.UserID})
+		response = favorite.Response
+	default:
+		return nil, fmt.Errorf("origem de rota inválida: %s", data.Source)
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errors.New("rota não encontrada")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar rota: %w", err)
+	}
+	return response, nil
+}
+
+// routeHistForUser busca a rota do histórico; rotas privadas de outros usuários são tratadas como inexistentes
+func (s *Service) routeHistForUser(ctx context.Context, id, userID int64) (db.RouteHist, error) {
+	routeHist, err := s.InterfaceService.GetRouteHistByID(ctx, id)
+	if err == nil && !routeHist.IsPublic && routeHist.IDUser != userID {
+		err = sql.ErrNoRows
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		return db.RouteHist{}, errors.New("rota não encontrada")
+	}
+	if err != nil {
+		return db.RouteHist{}, fmt.Errorf("erro ao buscar rota: %w", err)
+	}
+	return routeHist, nil
+}
+
+func newRouteExport(summary Summary, route RouteOutput) (routeExport, error) {
+	polyline := route.Polyline
+	if polyline == "" {
+		polyline = route.Summary.Polyline
+	}
+	if polyline == "" {
+		return routeExport{}, errors.New("a rota não tem polyline para exportar; calcule novamente com include_polyline")
+	}
+	geometry, err := routeGeometryFromPolyline(polyline)
+	if err != nil {
+		return routeExport{}, fmt.Errorf("erro ao ler polyline: %w", err)
+	}
+
+	export := routeExport{
+		Name:     fmt.Sprintf("%s → %s", summary.LocationOrigin.Address, summary.LocationDestination.Address),
+		Line:     geometry.Points,
+		Distance: route.Summary.Distance.Value,
+		Duration: route.Summary.Duration.Value,
+	}
+	if route.Summary.RouteType != "" {
+		export.Name += fmt.Sprintf(" (%s)", route.Summary.RouteType)
+	}
+
+	export.Points = append(export.Points,
+		exportPoint{Kind: exportKindOrigin, Name: "Origem", Description: summary.LocationOrigin.Address, Location: summary.LocationOrigin.Location},
+		exportPoint{Kind: exportKindDestination, Name: "Destino", Description: summary.LocationDestination.Address, Location: summary.LocationDestination.Location},
+	)
+
+	tolls := route.Tolls
+	if len(tolls) == 0 {
+		tolls = route.Summary.Tolls
+	}
+	for _, toll := range tolls {
+		export.Points = append(export.Points, exportPoint{
+			Kind:        exportKindToll,
+			Name:        toll.Name,
+			Description: fmt.Sprintf("%s - %s/%s - dinheiro R$ %.2f, tag R$ %.2f", toll.Concession, toll.Road, toll.State, toll.CashCost, toll.TagCost),
+			Location:    Location{Latitude: toll.Latitude, Longitude: toll.Longitude},
+			Properties: map[string]interface{}{
+				"id":         toll.ID,
+				"concession": toll.Concession,
+				"road":       toll.Road,
+				"state":      toll.State,
+				"cash_cost":  toll.CashCost,
+				"tag_cost":   toll.TagCost,
+				"free_flow":  toll.FreeFlow,
+			},
+		})
+	}
+
+	for _, balanca := range exportBalancas(route.Balances) {
+		lat, errLat := strconv.ParseFloat(strings.TrimSpace(balanca.Lat), 64)
+		lng, errLng := strconv.ParseFloat(strings.TrimSpace(balanca.Lng), 64)
+		if errLat != nil || errLng != nil {
+			continue
+		}
+		export.Points = append(export.Points, exportPoint{
+			Kind:        exportKindBalanca,
+			Name:        balanca.Nome,
+			Description: fmt.Sprintf("%s km %s - %s (%s)", balanca.Rodovia, balanca.Km, balanca.Uf, balanca.Sentido),
+			Location:    Location{Latitude: lat, Longitude: lng},
+			Properties: map[string]interface{}{
+				"id":             balanca.ID,
+				"concessionaria": balanca.Concessionaria,
+				"rodovia":        balanca.Rodovia,
+				"km":             balanca.Km,
+				"uf":             balanca.Uf,
+				"sentido":        balanca.Sentido,
+			},
+		})
+	}
+
+	for _, station := range route.GasStations {
+		properties := map[string]interface{}{"address": station.Address}
+		if station.ID > 0 {
+			properties["id"] = station.ID
+		}
+		if station.Price != nil {
+			properties["price"] = *station.Price
+		}
+		export.Points = append(export.Points, exportPoint{
+			Kind:        exportKindGasStation,
+			Name:        station.Name,
+			Description: station.Address,
+			Location:    station.Location,
+			Properties:  properties,
+		})
+	}
+
+	if zones := route.Summary.AttentionZones; zones != nil {
+		entries := make(map[int64]AttentionZoneEvent)
+		for _, event := range zones.Events {
+			if event.Type == "entry" {
+				entries[event.ZoneID] = event
+				continue
+			}
+			entry, ok := entries[event.ZoneID]
+			if !ok || event.Type != "exit" {
+				continue
+			}
+			delete(entries, event.ZoneID)
+			points := geometry.slice(entry.Distance, event.Distance)
+			if len(points) < 2 {
+				continue
+			}
+			export.Segments = append(export.Segments, exportSegment{
+				Name:   entry.ZoneName,
+				Points: points,
+				Properties: map[string]interface{}{
+					"zone_id":        entry.ZoneID,
+					"zone_name":      entry.ZoneName,
+					"detection_type": entry.DetectionType,
+					"entry_m":        entry.Distance,
+					"exit_m":         event.Distance,
+				},
+			})
+		}
+	}
+
+	return export, nil
+}
+
+// exportBalancas lê as balanças gravadas na resposta (Balances é interface{} e volta do JSON como mapa)
+func exportBalancas(balances interface{}) []RouteBalanca {
+	if balances == nil {
+		return nil
+	}
+	raw, err := json.Marshal(balances)
+	if err != nil {
+		return nil
+	}
+	var result []RouteBalanca
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return nil
+	}
+	return result
+}
+
+func (e routeExport) featureCollection() GeoJSONFeatureCollection {
+	collection := newFeatureCollection()
+	collection.Features = append(collection.Features, lineStringFeature(e.Line, map[string]interface{}{
+		"kind":       exportKindRoute,
+		"name":       e.Name,
+		"distance_m": e.Distance,
+		"duration_s": e.Duration,
+	}))
+	for _, point := range e.Points {
+		properties := map[string]interface{}{"kind": point.Kind, "name": point.Name, "description": point.Description}
+		for k, v := range point.Properties {
+			properties[k] = v
+		}
+		collection.Features = append(collection.Features, pointFeature(point.Location, properties))
+	}
+	for _, segment := range e.Segments {
+		properties := map[string]interface{}{"kind": exportKindAttention, "name": segment.Name}
+		for k, v := range segment.Properties {
+			properties[k] = v
+		}
+		collection.Features = append(collection.Features, lineStringFeature(segment.Points, properties))
+	}
+	return collection
+}
+
+// GPX 1.1: a rota vai como trilha (trk), os pontos como waypoints com símbolos reconhecidos pelos
+// GPS veiculares e cada trecho em zona de atenção como uma trilha à parte
+type gpxFile struct {
+	XMLName   xml.Name      `xml:"gpx"`
+	Version   string        `xml:"version,attr"`
+	Creator   string        `xml:"creator,attr"`
+	Xmlns     string        `xml:"xmlns,attr"`
+	Metadata  gpxMetadata   `xml:"metadata"`
+	Waypoints []gpxWaypoint `xml:"wpt"`
+	Tracks    []gpxTrack    `xml:"trk"`
+}
+
+type gpxMetadata struct {
+	Name string `xml:"name"`
+	Time string `xml:"time"`
+}
+
+type gpxWaypoint struct {
+	Lat  string `xml:"lat,attr"`
+	Lon  string `xml:"lon,attr"`
+	Name string `xml:"name"`
+	Desc string `xml:"desc,omitempty"`
+	Sym  string `xml:"sym,omitempty"`
+	Type string `xml:"type,omitempty"`
+}
+
+type gpxTrack struct {
+	Name     string       `xml:"name"`
+	Type     string       `xml:"type,omitempty"`
+	Segments []gpxSegment `xml:"trkseg"`
+}
+
+type gpxSegment struct {
+	Points []gpxPoint `xml:"trkpt"`
+}
+
+type gpxPoint struct {
+	Lat string `xml:"lat,attr"`
+	Lon string `xml:"lon,attr"`
+}
+
+var gpxSymbols = map[string]string{
+	exportKindOrigin:      "Flag, Green",
+	exportKindDestination: "Flag, Red",
+	exportKindToll:        "Toll Booth",
+	exportKindBalanca:     "Scales",
+	exportKindGasStation:  "Gas Station",
+}
+
+func (e routeExport) gpx() ([]byte, error) {
+	file := gpxFile{
+		Version:  "1.1",
+		Creator:  "geolocation",
+		Xmlns:    "http://www.topografix.com/GPX/1/1",
+		Metadata: gpxMetadata{Name: e.Name, Time: time.Now().UTC().Format(time.RFC3339)},
+	}
+	for _, point := range e.Points {
+		file.Waypoints = append(file.Waypoints, gpxWaypoint{
+			Lat:  coordinateText(point.Location.Latitude),
+			Lon:  coordinateText(point.Location.Longitude),
+			Name: point.Name,
+			Desc: point.Description,
+			Sym:  gpxSymbols[point.Kind],
+			Type: point.Kind,
+		})
+	}
+	file.Tracks = append(file.Tracks, gpxTrack{Name: e.Name, Type: exportKindRoute, Segments: []gpxSegment{gpxTrackSegment(e.Line)}})
+	for _, segment := range e.Segments {
+		file.Tracks = append(file.Tracks, gpxTrack{Name: segment.Name, Type: exportKindAttention, Segments: []gpxSegment{gpxTrackSegment(segment.Points)}})
+	}
+	return marshalXML(file)
+}
+
+func gpxTrackSegment(points []LatLng) gpxSegment {
+	segment := gpxSegment{Points: make([]gpxPoint, 0, len(points))}
+	for _, p := range points {
+		segment.Points = append(segment.Points, gpxPoint{Lat: coordinateText(p.Lat), Lon: coordinateText(p.Lng)})
+	}
+	return segment
+}
+
+// KML 2.2: uma pasta por tipo de ponto, com estilos próprios para a rota e as zonas de atenção.
+// As cores seguem o formato aabbggrr do KML.
+type kmlFile struct {
+	XMLName  xml.Name    `xml:"kml"`
+	Xmlns    string      `xml:"xmlns,attr"`
+	Document kmlDocument `xml:"Document"`
+}
+
+type kmlDocument struct {
+	Name    string      `xml:"name"`
+	Styles  []kmlStyle  `xml:"Style"`
+	Folders []kmlFolder `xml:"Folder"`
+}
+
+type kmlStyle struct {
+	ID        string        `xml:"id,attr"`
+	IconStyle *kmlIconStyle `xml:"IconStyle,omitempty"`
+	LineStyle *kmlLineStyle `xml:"LineStyle,omitempty"`
+}
+
+type kmlIconStyle struct {
+	Color string `xml:"color"`
+}
+
+type kmlLineStyle struct {
+	Color string `xml:"color"`
+	Width int    `xml:"width"`
+}
+
+type kmlFolder struct {
+	Name       string         `xml:"name"`
+	Placemarks []kmlPlacemark `xml:"Placemark"`
+}
+
+type kmlPlacemark struct {
+	Name        string         `xml:"name"`
+	Description string         `xml:"description,omitempty"`
+	StyleURL    string         `xml:"styleUrl,omitempty"`
+	Point       *kmlPoint      `xml:"Point,omitempty"`
+	LineString  *kmlLineString `xml:"LineString,omitempty"`
+}
+
+type kmlPoint struct {
+	Coordinates string `xml:"coordinates"`
+}
+
+type kmlLineString struct {
+	Tessellate  int    `xml:"tessellate"`
+	Coordinates string `xml:"coordinates"`
+}
+
+// kmlFolders define a ordem e o nome das pastas de pontos
+var kmlFolders = []struct {
+	kind string
+	name string
+}{
+	{exportKindOrigin, "Origem e destino"},
+	{exportKindToll, "Pedágios"},
+	{exportKindBalanca, "Balanças"},
+	{exportKindGasStation, "Postos"},
+}
+
+func (e routeExport) kml() ([]byte, error) {
+	doc := kmlDocument{
+		Name: e.Name,
+		Styles: []kmlStyle{
+			{ID: exportKindRoute, LineStyle: &kmlLineStyle{Color: "ffff0000", Width: 4}},
+			{ID: exportKindAttention, LineStyle: &kmlLineStyle{Color: "ff0000ff", Width: 6}},
+			{ID: exportKindOrigin, IconStyle: &kmlIconStyle{Color: "ff00ff00"}},
+			{ID: exportKindDestination, IconStyle: &kmlIconStyle{Color: "ff0000ff"}},
+			{ID: exportKindToll, IconStyle: &kmlIconStyle{Color: "ff00ffff"}},
+			{ID: exportKindBalanca, IconStyle: &kmlIconStyle{Color: "ff0080ff"}},
+			{ID: exportKindGasStation, IconStyle: &kmlIconStyle{Color: "ffff8000"}},
+		},
+	}
+
+	doc.Folders = append(doc.Folders, kmlFolder{Name: "Rota", Placemarks: []kmlPlacemark{{
+		Name:        e.Name,
+		Description: fmt.Sprintf("%.0f km", e.Distance/1000),
+		StyleURL:    "#" + exportKindRoute,
+		LineString:  &kmlLineString{Tessellate: 1, Coordinates: kmlCoordinates(e.Line)},
+	}}})
+
+	for _, folder := range kmlFolders {
+		f := kmlFolder{Name: folder.name}
+		for _, point := range e.Points {
+			kind := point.Kind
+			if kind == exportKindDestination {
+				kind = exportKindOrigin
+			}
+			if kind != folder.kind {
+				continue
+			}
+			f.Placemarks = append(f.Placemarks, kmlPlacemark{
+				Name:        point.Name,
+				Description: point.Description,
+				StyleURL:    "#" + point.Kind,
+				Point:       &kmlPoint{Coordinates: kmlCoordinates([]LatLng{{Lat: point.Location.Latitude, Lng: point.Location.Longitude}})},
+			})
+		}
+		if len(f.Placemarks) > 0 {
+			doc.Folders = append(doc.Folders, f)
+		}
+	}
+
+	if len(e.Segments) > 0 {
+		f := kmlFolder{Name: "Zonas de atenção"}
+		for _, segment := range e.Segments {
+			f.Placemarks = append(f.Placemarks, kmlPlacemark{
+				Name:       segment.Name,
+				StyleURL:   "#" + exportKindAttention,
+				LineString: &kmlLineString{Tessellate: 1, Coordinates: kmlCoordinates(segment.Points)},
+			})
+		}
+		doc.Folders = append(doc.Folders, f)
+	}
+
+	return marshalXML(kmlFile{Xmlns: "http://www.opengis.net/kml/2.2", Document: doc})
+}
+
+// kmlCoordinates monta a lista "lng,lat,0" separada por espaços
+func kmlCoordinates(points []LatLng) string {
+	parts := make([]string, 0, len(points))
+	for _, p := range points {
+		parts = append(parts, coordinateText(p.Lng)+","+coordinateText(p.Lat)+",0")
+	}
+	return strings.Join(parts, " ")
+}
+
+// coordinateText formata a coordenada com 6 casas (~10 cm), sem notação científica
+func coordinateText(v float64) string {
+	return strconv.FormatFloat(v, 'f', 6, 64)
+}
+
+func marshalXML(v interface{}) ([]byte, error) {
+	body, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), body...), nil
+}
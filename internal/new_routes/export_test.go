@@ -0,0 +1,176 @@
+package new_routes
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	db "geolocation/db/sqlc"
+	"geolocation/internal/routes"
+)
+
+// favoriteRepository guarda as rotas favoritas por usuário
+type favoriteRepository struct {
+	routes.InterfaceRepository
+	favorites map[db.GetFavoriteRouteByIDParams]json.RawMessage
+}
+
+func (r favoriteRepository) GetFavoriteRouteByID(_ context.Context, arg db.GetFavoriteRouteByIDParams) (db.FavoriteRoute, error) {
+	response, ok := r.favorites[arg]
+	if !ok {
+		return db.FavoriteRoute{}, sql.ErrNoRows
+	}
+	return db.FavoriteRoute{ID: arg.ID, IDUser: arg.IDUser, Response: response}, nil
+}
+
+// exportedOutput é uma rota de ~22 km para o sul com um pedágio, duas balanças (uma sem coordenada),
+// um posto e uma zona de atenção entre os km 5 e 15
+func exportedOutput(t *testing.T) json.RawMessage {
+	t.Helper()
+	price := 6.09
+	route := RouteOutput{
+		Summary: RouteSummary{
+			RouteType: "fastest",
+			Distance:  Distance{Value: 22239},
+			Duration:  Duration{Value: 1200},
+			AttentionZones: &AttentionZoneInfo{Events: []AttentionZoneEvent{
+				{Type: "entry", ZoneID: 4, ZoneName: "Zona Leste", Distance: 5000, DetectionType: "area"},
+				{Type: "exit", ZoneID: 4, ZoneName: "Zona Leste", Distance: 15000, DetectionType: "area"},
+			}},
+		},
+		Polyline: encodePolyline([]LatLng{{Lat: -23.5, Lng: -46.6}, {Lat: -23.6, Lng: -46.6}, {Lat: -23.7, Lng: -46.6}}),
+		Tolls:    []Toll{{ID: 9, Name: "Praça Sul", Concession: "CCR", Latitude: -23.55, Longitude: -46.6, CashCost: 12.3}},
+		Balances: []RouteBalanca{
+			{Balanca: db.Balanca{ID: 1, Nome: "Balança km 20", Lat: "-23.65", Lng: "-46.6"}},
+			{Balanca: db.Balanca{ID: 2, Nome: "Balança sem posição"}},
+		},
+		GasStations: []GasStation{{ID: 3, Name: "Posto Sul", Location: Location{Latitude: -23.68, Longitude: -46.6}, Price: &price}},
+	}
+	raw, err := json.Marshal(FinalOutput{
+		Summary: Summary{
+			LocationOrigin:      AddressInfo{Address: "São Paulo", Location: Location{Latitude: -23.5, Longitude: -46.6}},
+			LocationDestination: AddressInfo{Address: "Santo André", Location: Location{Latitude: -23.7, Longitude: -46.6}},
+		},
+		Routes: []RouteOutput{route},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return raw
+}
+
+func TestExportRouteGeoJSON(t *testing.T) {
+	s := &Service{InterfaceService: favoriteRepository{favorites: map[db.GetFavoriteRouteByIDParams]json.RawMessage{
+		{ID: 5, IDUser: 7}: exportedOutput(t),
+	}}}
+
+	file, err := s.ExportRoute(context.Background(), RouteExportRequest{Source: ExportSourceFavorite, ID: 5, Format: ExportFormatGeoJSON, UserID: 7})
+	if err != nil {
+		t.Fatalf("ExportRoute() erro = %v", err)
+	}
+	if file.FileName != "rota-favorite-5.geojson" || file.ContentType != "application/geo+json" {
+		t.Errorf("arquivo = %s (%s)", file.FileName, file.ContentType)
+	}
+
+	var collection GeoJSONFeatureCollection
+	if err := json.Unmarshal(file.Content, &collection); err != nil {
+		t.Fatalf("GeoJSON inválido: %v", err)
+	}
+	var kinds []string
+	for _, feature := range collection.Features {
+		kinds = append(kinds, feature.Geometry.Type+":"+feature.Properties["kind"].(string))
+	}
+	want := "LineString:route Point:origin Point:destination Point:toll Point:balanca Point:gas_station LineString:attention_zone"
+	if got := strings.Join(kinds, " "); got != want {
+		t.Errorf("features = %s, want %s", got, want)
+	}
+
+	route := collection.Features[0]
+	if route.Properties["name"] != "São Paulo → Santo André (fastest)" || len(route.Geometry.Coordinates.([]interface{})) != 3 {
+		t.Errorf("linha da rota = %+v", route)
+	}
+	if price := collection.Features[5].Properties["price"]; price != 6.09 {
+		t.Errorf("preço do posto = %v, want 6.09", price)
+	}
+	zone := collection.Features[6].Properties
+	if zone["zone_id"] != float64(4) || zone["entry_m"] != float64(5000) || zone["exit_m"] != float64(15000) {
+		t.Errorf("trecho da zona de atenção = %v", zone)
+	}
+}
+
+func TestExportRouteGPXAndKML(t *testing.T) {
+	s := &Service{InterfaceService: favoriteRepository{favorites: map[db.GetFavoriteRouteByIDParams]json.RawMessage{
+		{ID: 5, IDUser: 7}: exportedOutput(t),
+	}}}
+	request := RouteExportRequest{Source: ExportSourceFavorite, ID: 5, UserID: 7}
+
+	request.Format = ExportFormatGPX
+	file, err := s.ExportRoute(context.Background(), request)
+	if err != nil {
+		t.Fatalf("ExportRoute(gpx) erro = %v", err)
+	}
+	var gpx gpxFile
+	if err := xml.Unmarshal(file.Content, &gpx); err != nil {
+		t.Fatalf("GPX inválido: %v", err)
+	}
+	if gpx.Version != "1.1" || len(gpx.Waypoints) != 5 || len(gpx.Tracks) != 2 {
+		t.Fatalf("GPX com %d waypoints e %d trilhas, want 5 e 2", len(gpx.Waypoints), len(gpx.Tracks))
+	}
+	if toll := gpx.Waypoints[2]; toll.Sym != "Toll Booth" || toll.Lat != "-23.550000" || toll.Lon != "-46.600000" {
+		t.Errorf("waypoint do pedágio = %+v", toll)
+	}
+	if points := gpx.Tracks[0].Segments[0].Points; len(points) != 3 || points[2].Lat != "-23.700000" {
+		t.Errorf("trilha da rota = %+v", points)
+	}
+	if zone := gpx.Tracks[1]; zone.Type != exportKindAttention || zone.Name != "Zona Leste" {
+		t.Errorf("trilha da zona de atenção = %+v", zone)
+	}
+
+	request.Format = ExportFormatKML
+	file, err = s.ExportRoute(context.Background(), request)
+	if err != nil {
+		t.Fatalf("ExportRoute(kml) erro = %v", err)
+	}
+	var kml kmlFile
+	if err := xml.Unmarshal(file.Content, &kml); err != nil {
+		t.Fatalf("KML inválido: %v", err)
+	}
+	var folders []string
+	for _, folder := range kml.Document.Folders {
+		folders = append(folders, folder.Name)
+	}
+	if got := strings.Join(folders, ", "); got != "Rota, Origem e destino, Pedágios, Balanças, Postos, Zonas de atenção" {
+		t.Errorf("pastas = %s", got)
+	}
+	// O KML usa lng,lat,altitude
+	if got := kml.Document.Folders[2].Placemarks[0].Point.Coordinates; got != "-46.600000,-23.550000,0" {
+		t.Errorf("coordenadas do pedágio = %s", got)
+	}
+	if got := kml.Document.Folders[1].Placemarks[1].StyleURL; got != "#"+exportKindDestination {
+		t.Errorf("estilo do destino = %s", got)
+	}
+}
+
+func TestExportRouteRejects(t *testing.T) {
+	noPolyline, _ := json.Marshal(FinalOutput{Routes: []RouteOutput{{}}})
+	s := &Service{InterfaceService: favoriteRepository{favorites: map[db.GetFavoriteRouteByIDParams]json.RawMessage{
+		{ID: 5, IDUser: 7}: exportedOutput(t),
+		{ID: 6, IDUser: 7}: noPolyline,
+	}}}
+	ctx := context.Background()
+
+	cases := map[string]RouteExportRequest{
+		"favorito de outro usuário": {Source: ExportSourceFavorite, ID: 5, Format: ExportFormatGPX, UserID: 8},
+		"índice fora da resposta":   {Source: ExportSourceFavorite, ID: 5, Format: ExportFormatGPX, UserID: 7, RouteIndex: 1},
+		"rota sem polyline":         {Source: ExportSourceFavorite, ID: 6, Format: ExportFormatKML, UserID: 7},
+		"origem desconhecida":       {Source: "saved_routes", ID: 5, Format: ExportFormatKML, UserID: 7},
+	}
+	for name, request := range cases {
+		if _, err := s.ExportRoute(ctx, request); err == nil {
+			t.Errorf("%s: ExportRoute() não retornou erro", name)
+		}
+	}
+}
@@ -0,0 +1,472 @@
+package new_routes
+
+import (
+	"context"
+	"fmt"
+	db "geolocation/db/sqlc"
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	FleetStopPickup   = "pickup"
+	FleetStopDelivery = "delivery"
+
+	// defaultServiceMinutes é o tempo padrão de carga/descarga em cada parada
+	defaultServiceMinutes = 60
+	// maxFleetPoints limita a matriz (caminhões + coletas + entregas) pedida ao motor
+	maxFleetPoints = 100
+)
+
+type FleetPlanRequest struct {
+	Trucks           []FleetTruckRequest `json:"trucks" validate:"required,min=1,dive"`
+	AdvertisementIDs []int64             `json:"advertisement_ids" validate:"required,min=1"`
+	ConsumptionCity  float64             `json:"consumptionCity"`
+	ConsumptionHwy   float64             `json:"consumptionHwy"`
+	Price            float64             `json:"price"`
+	ServiceMinutes   int64               `json:"service_minutes"`
+	DepartureTime    *time.Time          `json:"departure_time"`
+	UserID           int64               `json:"-"`
+}
+
+// FleetTruckRequest identifica o caminhão pelo cadastro (truck) ou pelo conjunto cavalo/carreta.
+// Capacity (t) e Axles sobrescrevem os valores cadastrados quando informados.
+type FleetTruckRequest struct {
+	TruckID       int64      `json:"truck_id"`
+	TractorUnitID int64      `json:"tractor_unit_id"`
+	TrailerID     int64      `json:"trailer_id"`
+	Lat           float64    `json:"lat" validate:"required"`
+	Lng           float64    `json:"lng" validate:"required"`
+	Capacity      float64    `json:"capacity"`
+	Axles         int64      `json:"axles"`
+	Type          string     `json:"type"`
+	AvailableFrom *time.Time `json:"available_from"`
+}
+
+type FleetPlanResponse struct {
+	Itineraries   []FleetItinerary     `json:"itineraries"`
+	Unassigned    []FleetUnassignedAdv `json:"unassigned,omitempty"`
+	TotalDistance Distance             `json:"distance"`
+	TotalTolls    float64              `json:"total_tolls"`
+	TotalFuelCost float64              `json:"total_fuel_cost"`
+	TotalCost     float64              `json:"total_cost"`
+}
+
+type FleetItinerary struct {
+	TruckID          int64       `json:"truck_id,omitempty"`
+	TractorUnitID    int64       `json:"tractor_unit_id,omitempty"`
+	TrailerID        int64       `json:"trailer_id,omitempty"`
+	DriverID         int64       `json:"driver_id,omitempty"`
+	Capacity         float64     `json:"capacity"`
+	AdvertisementIDs []int64     `json:"advertisement_ids"`
+	Stops            []FleetStop `json:"stops"`
+	Distance         Distance    `json:"distance"`
+	Duration         Duration    `json:"duration"`
+	Tolls            []Toll      `json:"tolls,omitempty"`
+	TotalTolls       float64     `json:"total_tolls"`
+	TotalFuelCost    float64     `json:"total_fuel_cost"`
+	FuelSplit        *FuelSplit  `json:"fuel_split,omitempty"`
+	TotalCost        float64     `json:"total_cost"`
+	Polyline         string      `json:"polyline,omitempty"`
+}
+
+type FleetStop struct {
+	Type            string    `json:"type"`
+	AdvertisementID int64     `json:"advertisement_id"`
+	Address         string    `json:"address"`
+	Location        Location  `json:"location"`
+	Arrival         time.Time `json:"arrival"`
+	Departure       time.Time `json:"departure"`
+	LoadAfter       float64   `json:"load_after"`
+}
+
+type FleetUnassignedAdv struct {
+	AdvertisementID int64  `json:"advertisement_id"`
+	Reason          string `json:"reason"`
+}
+
+// fleetTruck e fleetJob guardam o estado interno do planejamento; os índices apontam para a matriz
+type fleetTruck struct {
+	req       FleetTruckRequest
+	driverID  int64
+	capacity  float64
+	axles     int64
+	vehicle   string
+	point     int
+	available time.Time
+	stops     []fleetStopRef
+}
+
+type fleetJob struct {
+	id       int64
+	weight   float64
+	pickup   time.Time
+	deadline time.Time
+	origin   int
+	dest     int
+	fromAddr string
+	toAddr   string
+}
+
+type fleetStopRef struct {
+	job    int
+	pickup bool
+}
+
+// PlanFleet distribui os anúncios entre os caminhões por inserção de menor custo, respeitando a capacidade
+// de carga e as janelas de coleta (não antes de pickup_date) e entrega (até delivery_date)
+func (s *Service) PlanFleet(ctx context.Context, data FleetPlanRequest) (FleetPlanResponse, error) {
+	start := time.Now()
+	if data.DepartureTime != nil {
+		start = *data.DepartureTime
+	}
+	service := time.Duration(data.ServiceMinutes) * time.Minute
+	if data.ServiceMinutes <= 0 {
+		service = defaultServiceMinutes * time.Minute
+	}
+
+	var points []Location
+	trucks := make([]*fleetTruck, 0, len(data.Trucks))
+	for _, t := range data.Trucks {
+		truck, err := s.loadFleetTruck(ctx, t, data.UserID)
+		if err != nil {
+			return FleetPlanResponse{}, err
+		}
+		truck.point = len(points)
+		truck.available = start
+		if t.AvailableFrom != nil && t.AvailableFrom.After(start) {
+			truck.available = *t.AvailableFrom
+		}
+		points = append(points, Location{Latitude: t.Lat, Longitude: t.Lng})
+		trucks = append(trucks, truck)
+	}
+
+	var jobs []fleetJob
+	var unassigned []FleetUnassignedAdv
+	for _, id := range data.AdvertisementIDs {
+		// Só entram anúncios do próprio usuário ou fretes em que ele é o transportador com agendamento ativo
+		adv, err := s.InterfaceService.GetAdvertisementByIdForUser(ctx, db.GetAdvertisementByIdForUserParams{ID: id, UserID: data.UserID})
+		if err != nil {
+			unassigned = append(unassigned, FleetUnassignedAdv{AdvertisementID: id, Reason: "anúncio não encontrado"})
+			continue
+		}
+
+		origin, err := s.advertisementLocation(ctx, adv.OriginLat.Float64, adv.OriginLng.Float64, adv.OriginLat.Valid && adv.OriginLng.Valid, adv.CepOrigin)
+		if err != nil {
+			unassigned = append(unassigned, FleetUnassignedAdv{AdvertisementID: id, Reason: "origem sem coordenadas"})
+			continue
+		}
+		dest, err := s.advertisementLocation(ctx, adv.DestinationLat.Float64, adv.DestinationLng.Float64, adv.DestinationLat.Valid && adv.DestinationLng.Valid, adv.CepDestination)
+		if err != nil {
+			unassigned = append(unassigned, FleetUnassignedAdv{AdvertisementID: id, Reason: "destino sem coordenadas"})
+			continue
+		}
+
+		jobs = append(jobs, fleetJob{
+			id:       adv.ID,
+			weight:   adv.CargoWeight,
+			pickup:   adv.PickupDate,
+			deadline: adv.DeliveryDate,
+			origin:   len(points),
+			dest:     len(points) + 1,
+			fromAddr: adv.Origin,
+			toAddr:   adv.Destination,
+		})
+		points = append(points, origin, dest)
+	}
+
+	if len(points) > maxFleetPoints {
+		return FleetPlanResponse{}, fmt.Errorf("o planejamento aceita no máximo %d pontos (caminhões + coletas + entregas)", maxFleetPoints)
+	}
+	if len(jobs) == 0 {
+		return FleetPlanResponse{Unassigned: unassigned}, nil
+	}
+
+	tableCtx, cancel := context.WithTimeout(ctx, 60*time.Second)
+	defer cancel()
+	// A matriz é única para a frota, então usa o perfil de caminhão para todos os veículos
+	table, err := s.Engine.Table(tableCtx, points, points, vehicleProfile("truck"))
+	if err != nil {
+		return FleetPlanResponse{}, fmt.Errorf("erro ao calcular matriz de distâncias: %w", err)
+	}
+
+	// Anúncios com coleta mais cedo são inseridos primeiro
+	order := make([]int, len(jobs))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return jobs[order[a]].pickup.Before(jobs[order[b]].pickup)
+	})
+
+	for _, j := range order {
+		job := jobs[j]
+		bestTruck, bestP, bestD := -1, 0, 0
+		bestDelta := math.MaxFloat64
+		reason := "nenhum caminhão com capacidade disponível"
+
+		for ti, truck := range trucks {
+			if job.weight > truck.capacity {
+				continue
+			}
+			reason = "janela de coleta/entrega não atendida"
+			baseCost, _ := fleetScheduleCost(truck, jobs, truck.stops, table, service)
+			for p := 0; p <= len(truck.stops); p++ {
+				for d := p; d <= len(truck.stops); d++ {
+					candidate := insertFleetStops(truck.stops, j, p, d)
+					cost, ok := fleetScheduleCost(truck, jobs, candidate, table, service)
+					if !ok {
+						continue
+					}
+					if delta := cost - baseCost; delta < bestDelta {
+						bestTruck, bestP, bestD, bestDelta = ti, p, d, delta
+					}
+				}
+			}
+		}
+
+		if bestTruck < 0 {
+			unassigned = append(unassigned, FleetUnassignedAdv{AdvertisementID: job.id, Reason: reason})
+			continue
+		}
+		trucks[bestTruck].stops = insertFleetStops(trucks[bestTruck].stops, j, bestP, bestD)
+	}
+
+	response := FleetPlanResponse{Unassigned: unassigned}
+	var totalDistance float64
+	for _, truck := range trucks {
+		if len(truck.stops) == 0 {
+			continue
+		}
+		itinerary := s.buildFleetItinerary(ctx, truck, jobs, points, table, service, data)
+		totalDistance += itinerary.Distance.Value
+		response.TotalTolls += itinerary.TotalTolls
+		response.TotalFuelCost += itinerary.TotalFuelCost
+		response.Itineraries = append(response.Itineraries, itinerary)
+	}
+
+	distText, _ := formatDistance(totalDistance)
+	response.TotalDistance = Distance{Text: distText, Value: totalDistance}
+	response.TotalTolls = math.Round(response.TotalTolls*100) / 100
+	response.TotalCost = math.Round((response.TotalTolls+response.TotalFuelCost)*100) / 100
+	return response, nil
+}
+
+// loadFleetTruck busca o conjunto cadastrado do usuário e define capacidade, eixos e tipo do caminhão.
+// O caminhão não tem dono próprio; vale o dono do cavalo que o compõe.
+func (s *Service) loadFleetTruck(ctx context.Context, t FleetTruckRequest, userID int64) (*fleetTruck, error) {
+	truck := &fleetTruck{req: t, capacity: t.Capacity, axles: t.Axles, vehicle: strings.ToLower(t.Type)}
+	if truck.vehicle == "" {
+		truck.vehicle = "truck"
+	}
+
+	if t.TruckID > 0 {
+		row, err := s.InterfaceService.GetTruckById(ctx, t.TruckID)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao buscar caminhão %d: %w", t.TruckID, err)
+		}
+		truck.req.TractorUnitID = row.TractorUnitID
+		truck.req.TrailerID = row.TrailerID.Int64
+		truck.driverID = row.DriverID
+	}
+
+	var capacity float64
+	var axles int64
+	if truck.req.TractorUnitID > 0 && s.TractorUnitRepository != nil {
+		tractor, err := s.userTractorUnit(ctx, truck.req.TractorUnitID, userID)
+		if err != nil {
+			return nil, err
+		}
+		capacity = parseCapacity(tractor.Capacity)
+		axles = tractor.Axles
+	}
+	if truck.req.TrailerID > 0 && s.TrailerRepository != nil {
+		trailer, err := s.userTrailer(ctx, truck.req.TrailerID, userID)
+		if err != nil {
+			return nil, err
+		}
+		if trailer.LoadCapacity.Valid && trailer.LoadCapacity.Float64 > 0 {
+			capacity = trailer.LoadCapacity.Float64
+		}
+		axles += trailer.Axles
+	}
+
+	if truck.capacity == 0 {
+		truck.capacity = capacity
+	}
+	if truck.axles == 0 {
+		truck.axles = axles
+	}
+	if truck.capacity <= 0 {
+		return nil, fmt.Errorf("capacidade do caminhão não informada (truck_id %d, tractor_unit_id %d)", t.TruckID, t.TractorUnitID)
+	}
+	return truck, nil
+}
+
+func (s *Service) advertisementLocation(ctx context.Context, lat, lng float64, valid bool, cep string) (Location, error) {
+	if valid && (lat != 0 || lng != 0) {
+		return Location{Latitude: lat, Longitude: lng}, nil
+	}
+	cepLat, cepLng, _, err := s.getCoordByCEP(ctx, cep)
+	if err != nil {
+		return Location{}, err
+	}
+	return Location{Latitude: cepLat, Longitude: cepLng}, nil
+}
+
+// insertFleetStops insere a coleta na posição p e a entrega na posição d (relativa à lista original)
+func insertFleetStops(stops []fleetStopRef, job, p, d int) []fleetStopRef {
+	out := make([]fleetStopRef, 0, len(stops)+2)
+	for i := 0; i <= len(stops); i++ {
+		if i == p {
+			out = append(out, fleetStopRef{job: job, pickup: true})
+		}
+		if i == d {
+			out = append(out, fleetStopRef{job: job, pickup: false})
+		}
+		if i < len(stops) {
+			out = append(out, stops[i])
+		}
+	}
+	return out
+}
+
+// fleetScheduleCost simula o itinerário e devolve a distância total, ou false se violar capacidade ou janelas
+func fleetScheduleCost(truck *fleetTruck, jobs []fleetJob, stops []fleetStopRef, table RouteTable, service time.Duration) (float64, bool) {
+	_, distance, ok := simulateFleetStops(truck, jobs, stops, table, service)
+	return distance, ok
+}
+
+type fleetStopTiming struct {
+	arrival   time.Time
+	departure time.Time
+	load      float64
+}
+
+func simulateFleetStops(truck *fleetTruck, jobs []fleetJob, stops []fleetStopRef, table RouteTable, service time.Duration) ([]fleetStopTiming, float64, bool) {
+	timings := make([]fleetStopTiming, len(stops))
+	current := truck.point
+	clock := truck.available
+	var load, distance float64
+
+	for i, stop := range stops {
+		job := jobs[stop.job]
+		next := job.dest
+		if stop.pickup {
+			next = job.origin
+		}
+
+		dist := table.Distances[current][next]
+		dur := table.Durations[current][next]
+		if dist < 0 || dur < 0 {
+			return nil, 0, false
+		}
+		distance += dist
+		clock = clock.Add(time.Duration(dur * float64(time.Second)))
+		timings[i].arrival = clock
+
+		if stop.pickup {
+			if clock.Before(job.pickup) {
+				clock = job.pickup
+			}
+			load += job.weight
+			if load > truck.capacity+1e-9 {
+				return nil, 0, false
+			}
+		} else {
+			if !job.deadline.IsZero() && clock.After(job.deadline) {
+				return nil, 0, false
+			}
+			load -= job.weight
+		}
+
+		clock = clock.Add(service)
+		timings[i].departure = clock
+		timings[i].load = math.Max(load, 0)
+		current = next
+	}
+	return timings, distance, true
+}
+
+// buildFleetItinerary roteia a sequência escolhida e calcula pedágios e combustível do caminhão
+func (s *Service) buildFleetItinerary(ctx context.Context, truck *fleetTruck, jobs []fleetJob, points []Location, table RouteTable, service time.Duration, data FleetPlanRequest) FleetItinerary {
+	timings, distance, _ := simulateFleetStops(truck, jobs, truck.stops, table, service)
+
+	itinerary := FleetItinerary{
+		TruckID:       truck.req.TruckID,
+		TractorUnitID: truck.req.TractorUnitID,
+		TrailerID:     truck.req.TrailerID,
+		DriverID:      truck.driverID,
+		Capacity:      truck.capacity,
+	}
+
+	coordinates := []Location{points[truck.point]}
+	seen := make(map[int64]bool)
+	for i, stop := range truck.stops {
+		job := jobs[stop.job]
+		fs := FleetStop{
+			AdvertisementID: job.id,
+			Arrival:         timings[i].arrival,
+			Departure:       timings[i].departure,
+			LoadAfter:       timings[i].load,
+		}
+		if stop.pickup {
+			fs.Type = FleetStopPickup
+			fs.Address = job.fromAddr
+			fs.Location = points[job.origin]
+		} else {
+			fs.Type = FleetStopDelivery
+			fs.Address = job.toAddr
+			fs.Location = points[job.dest]
+		}
+		itinerary.Stops = append(itinerary.Stops, fs)
+		coordinates = append(coordinates, fs.Location)
+		if !seen[job.id] {
+			seen[job.id] = true
+			itinerary.AdvertisementIDs = append(itinerary.AdvertisementIDs, job.id)
+		}
+	}
+
+	duration := timings[len(timings)-1].arrival.Sub(truck.available).Seconds()
+	var route OSRMRoute
+	resp, err := s.engineRoute(ctx, 60*time.Second, RouteRequest{Coordinates: coordinates})
+	if err != nil {
+		log.Printf("Erro ao rotear itinerário da frota: %v", err)
+	} else {
+		route = resp.Routes[0]
+		distance = route.Distance
+	}
+	geometry := route.Geometry
+
+	distText, distVal := formatDistance(distance)
+	durText, durVal := formatDuration(duration)
+	itinerary.Distance = Distance{Text: distText, Value: distVal}
+	itinerary.Duration = Duration{Text: durText, Value: durVal}
+	itinerary.Polyline = geometry
+
+	if geometry != "" {
+		tolls, err := s.findTollsOnRoute(ctx, route, newTollVehicle(truck.vehicle, truck.axles, VehicleInfo{}), &truck.available)
+		if err != nil {
+			log.Printf("Erro ao filtrar pedágios: %v", err)
+		}
+		itinerary.Tolls = tolls
+		for _, t := range tolls {
+			itinerary.TotalTolls += t.PaidCost
+		}
+	}
+	itinerary.TotalTolls = math.Round(itinerary.TotalTolls*100) / 100
+
+	// Como nas rotas: consumo urbano e rodoviário por trecho, um único consumo vale para os dois e sem preço usa a ANP
+	if route.Distance == 0 {
+		route.Distance = distance
+	}
+	fuelSplit := s.routeFuelSplit(ctx, route, truck.vehicle, data.Price, data.ConsumptionCity, data.ConsumptionHwy)
+	itinerary.FuelSplit = &fuelSplit
+	itinerary.TotalFuelCost = fuelSplit.TotalCost()
+	itinerary.TotalCost = math.Round((itinerary.TotalTolls+itinerary.TotalFuelCost)*100) / 100
+
+	return itinerary
+}
@@ -0,0 +1,220 @@
+package new_routes
+
+import (
+	"context"
+	"database/sql"
+	db "geolocation/db/sqlc"
+	"geolocation/internal/routes"
+	"math"
+	"testing"
+	"time"
+)
+
+// uniformFleetTable monta uma matriz n×n com a mesma distância (m) e duração (s) entre pontos distintos
+func uniformFleetTable(n int, distance, duration float64) RouteTable {
+	table := RouteTable{Distances: make([][]float64, n), Durations: make([][]float64, n)}
+	for i := 0; i < n; i++ {
+		table.Distances[i] = make([]float64, n)
+		table.Durations[i] = make([]float64, n)
+		for j := 0; j < n; j++ {
+			if i != j {
+				table.Distances[i][j] = distance
+				table.Durations[i][j] = duration
+			}
+		}
+	}
+	return table
+}
+
+func TestSimulateFleetStops(t *testing.T) {
+	start := time.Date(2025, 3, 10, 8, 0, 0, 0, time.UTC)
+	service := time.Hour
+	// Ponto 0 é o caminhão; anúncio 0 vai de 1 para 2 e anúncio 1 de 3 para 4, com 10 km e 10 min entre pontos
+	table := uniformFleetTable(5, 10000, 600)
+	unreachable := uniformFleetTable(5, 10000, 600)
+	unreachable.Distances[0][1] = -1
+
+	pickup0 := fleetStopRef{job: 0, pickup: true}
+	delivery0 := fleetStopRef{job: 0}
+	pickup1 := fleetStopRef{job: 1, pickup: true}
+
+	tests := []struct {
+		name          string
+		jobs          []fleetJob
+		stops         []fleetStopRef
+		table         RouteTable
+		wantOK        bool
+		wantDistance  float64
+		wantDeparture time.Time // saída da primeira parada
+		wantLoads     []float64
+	}{
+		{
+			name:          "coleta e entrega dentro da capacidade",
+			jobs:          []fleetJob{{weight: 10, origin: 1, dest: 2}},
+			stops:         []fleetStopRef{pickup0, delivery0},
+			table:         table,
+			wantOK:        true,
+			wantDistance:  20000,
+			wantDeparture: start.Add(70 * time.Minute),
+			wantLoads:     []float64{10, 0},
+		},
+		{
+			name:          "espera a janela de coleta",
+			jobs:          []fleetJob{{weight: 10, origin: 1, dest: 2, pickup: start.Add(2 * time.Hour)}},
+			stops:         []fleetStopRef{pickup0, delivery0},
+			table:         table,
+			wantOK:        true,
+			wantDistance:  20000,
+			wantDeparture: start.Add(3 * time.Hour),
+			wantLoads:     []float64{10, 0},
+		},
+		{
+			name:   "excede a capacidade",
+			jobs:   []fleetJob{{weight: 15, origin: 1, dest: 2}, {weight: 15, origin: 3, dest: 4}},
+			stops:  []fleetStopRef{pickup0, pickup1},
+			table:  table,
+			wantOK: false,
+		},
+		{
+			name:   "entrega depois do prazo",
+			jobs:   []fleetJob{{weight: 10, origin: 1, dest: 2, deadline: start.Add(30 * time.Minute)}},
+			stops:  []fleetStopRef{pickup0, delivery0},
+			table:  table,
+			wantOK: false,
+		},
+		{
+			name:   "ponto sem rota",
+			jobs:   []fleetJob{{weight: 10, origin: 1, dest: 2}},
+			stops:  []fleetStopRef{pickup0, delivery0},
+			table:  unreachable,
+			wantOK: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			truck := &fleetTruck{capacity: 20, point: 0, available: start}
+			timings, distance, ok := simulateFleetStops(truck, tt.jobs, tt.stops, tt.table, service)
+			if ok != tt.wantOK {
+				t.Fatalf("simulateFleetStops() ok = %v, esperado %v", ok, tt.wantOK)
+			}
+			if !ok {
+				return
+			}
+			if distance != tt.wantDistance {
+				t.Errorf("distância = %v, esperado %v", distance, tt.wantDistance)
+			}
+			if !timings[0].departure.Equal(tt.wantDeparture) {
+				t.Errorf("saída da primeira parada = %s, esperado %s", timings[0].departure, tt.wantDeparture)
+			}
+			for i, want := range tt.wantLoads {
+				if timings[i].load != want {
+					t.Errorf("carga após a parada %d = %v, esperado %v", i, timings[i].load, want)
+				}
+			}
+		})
+	}
+}
+
+// fleetRepository expõe ao usuário 7 só os anúncios de visible e guarda quem pediu cada anúncio
+type fleetRepository struct {
+	routes.InterfaceRepository
+	visible  map[int64]db.Advertisement
+	askedFor []int64
+}
+
+func (r *fleetRepository) GetAdvertisementByIdForUser(_ context.Context, arg db.GetAdvertisementByIdForUserParams) (db.Advertisement, error) {
+	r.askedFor = append(r.askedFor, arg.UserID)
+	adv, ok := r.visible[arg.ID]
+	if !ok || arg.UserID != 7 {
+		return db.Advertisement{}, sql.ErrNoRows
+	}
+	return adv, nil
+}
+
+func (r *fleetRepository) GetTollTags(context.Context) ([]db.TollTag, error) {
+	return nil, nil
+}
+
+func (r *fleetRepository) GetEffectiveTollTariffs(context.Context, time.Time) ([]db.TollTariff, error) {
+	return nil, nil
+}
+
+func (r *fleetRepository) GetEffectiveTollTagDiscounts(context.Context, time.Time) ([]db.GetEffectiveTollTagDiscountsRow, error) {
+	return nil, nil
+}
+
+func TestPlanFleetVisibleAdvertisementsAndFuel(t *testing.T) {
+	start := time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)
+	point := func(v float64) sql.NullFloat64 { return sql.NullFloat64{Float64: v, Valid: true} }
+	visible := map[int64]db.Advertisement{
+		10: {
+			ID: 10, CargoWeight: 5, PickupDate: start, DeliveryDate: start.Add(48 * time.Hour),
+			OriginLat: point(-23.5), OriginLng: point(-46.6), DestinationLat: point(-22.9), DestinationLng: point(-47.1),
+		},
+	}
+
+	plan := func(t *testing.T, data FleetPlanRequest) (FleetPlanResponse, *fleetRepository) {
+		t.Helper()
+		repo := &fleetRepository{visible: visible}
+		s := &Service{InterfaceService: repo, Engine: NewFakeEngine(), POIIndex: &POIIndex{snapshot: &poiSnapshot{fuelPriceAverage: 6}}}
+		data.AdvertisementIDs = []int64{10, 11}
+		data.Trucks = []FleetTruckRequest{{Lat: -23.55, Lng: -46.63, Capacity: 10, Axles: 5}}
+		data.DepartureTime = &start
+		resp, err := s.PlanFleet(context.Background(), data)
+		if err != nil {
+			t.Fatalf("PlanFleet() erro = %v", err)
+		}
+		return resp, repo
+	}
+
+	t.Run("anúncio alheio não entra no plano", func(t *testing.T) {
+		resp, repo := plan(t, FleetPlanRequest{UserID: 7, ConsumptionHwy: 3, Price: 6})
+		if len(repo.askedFor) != 2 || repo.askedFor[0] != 7 || repo.askedFor[1] != 7 {
+			t.Errorf("anúncios buscados para os usuários %v, want [7 7]", repo.askedFor)
+		}
+		if len(resp.Unassigned) != 1 || resp.Unassigned[0].AdvertisementID != 11 || resp.Unassigned[0].Reason != "anúncio não encontrado" {
+			t.Errorf("não atribuídos = %+v, want só o anúncio 11", resp.Unassigned)
+		}
+		if len(resp.Itineraries) != 1 || len(resp.Itineraries[0].AdvertisementIDs) != 1 || resp.Itineraries[0].AdvertisementIDs[0] != 10 {
+			t.Fatalf("itinerários = %+v, want o anúncio 10", resp.Itineraries)
+		}
+	})
+
+	t.Run("outro usuário não vê nenhum anúncio", func(t *testing.T) {
+		resp, _ := plan(t, FleetPlanRequest{UserID: 8})
+		if len(resp.Itineraries) != 0 || len(resp.Unassigned) != 2 {
+			t.Errorf("plano = %+v, want os dois anúncios sem atribuição", resp)
+		}
+	})
+
+	fuelTests := []struct {
+		name      string
+		data      FleetPlanRequest
+		wantPrice float64
+	}{
+		{name: "só o consumo rodoviário vale para a rota toda", data: FleetPlanRequest{ConsumptionHwy: 3, Price: 5.5}, wantPrice: 5.5},
+		{name: "só o consumo urbano", data: FleetPlanRequest{ConsumptionCity: 3, Price: 5.5}, wantPrice: 5.5},
+		{name: "sem preço usa a média da ANP", data: FleetPlanRequest{ConsumptionCity: 3, ConsumptionHwy: 3}, wantPrice: 6},
+	}
+	for _, tt := range fuelTests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.data.UserID = 7
+			resp, _ := plan(t, tt.data)
+			if len(resp.Itineraries) != 1 {
+				t.Fatalf("itinerários = %d, want 1", len(resp.Itineraries))
+			}
+			itinerary := resp.Itineraries[0]
+			split := itinerary.FuelSplit
+			if split == nil || split.Price != tt.wantPrice {
+				t.Fatalf("divisão de combustível = %+v, want preço %v", split, tt.wantPrice)
+			}
+			liters := itinerary.Distance.Value / 1000 / 3
+			if math.Abs(split.LitersCity+split.LitersHwy-liters) > 0.05 {
+				t.Errorf("litros = %v, want %.2f (3 km/l na distância toda)", split.LitersCity+split.LitersHwy, liters)
+			}
+			if itinerary.TotalFuelCost != math.Round(liters*tt.wantPrice) || resp.TotalFuelCost != itinerary.TotalFuelCost {
+				t.Errorf("custo de combustível = %v (total %v), want %v", itinerary.TotalFuelCost, resp.TotalFuelCost, math.Round(liters*tt.wantPrice))
+			}
+		})
+	}
+}
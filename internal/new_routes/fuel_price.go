@@ -0,0 +1,81 @@
+package new_routes
+
+import (
+	"context"
+	"log"
+	"strings"
+)
+
+const (
+	// produtos gravados pelo importador da ANP (internal/fuel_price)
+	fuelProductDieselS10  = "diesel_s10"
+	fuelProductDieselS500 = "diesel_s500"
+
+	// fuelPriceRouteTolerance é a distância máxima (m) entre o posto e a rota para o preço entrar na média regional
+	fuelPriceRouteTolerance = 2000.0
+)
+
+// routeFuelSplit calcula a divisão de combustível da rota; sem preço informado usa a média regional da ANP
+// para veículos a diesel e deixa o custo zerado para os demais
+func (s *Service) routeFuelSplit(ctx context.Context, route OSRMRoute, vehicleType string, price, consumptionCity, consumptionHwy float64) FuelSplit {
+	if price <= 0 {
+		price = s.regionalFuelPrice(ctx, route, vehicleType)
+	}
+	return fuelSplitForRoute(route, price, consumptionCity, consumptionHwy)
+}
+
+// withRoutePrice devolve o preço do resumo: o informado ou, quando ele ficou vazio e a ANP preencheu o preço,
+// o usado na primeira rota
+func (p FuelPrice) withRoutePrice(routes []RouteOutput) FuelPrice {
+	if p.Price > 0 {
+		return p
+	}
+	for _, route := range routes {
+		if split := route.Summary.FuelSplit; split != nil && split.Price > 0 {
+			p.Price = split.Price
+			return p
+		}
+	}
+	return p
+}
+
+// regionalFuelPrice é a média do preço do diesel nos municípios dos postos ao longo da rota, do último
+// levantamento da ANP importado. Sem postos com preço na rota, usa a média nacional; sem preços, 0.
+// Só há série de diesel importada, então veículos leves (gasolina/etanol) ficam sem preço regional.
+func (s *Service) regionalFuelPrice(ctx context.Context, route OSRMRoute, vehicleType string) float64 {
+	if !isDieselVehicle(vehicleType) {
+		return 0
+	}
+	snap, err := s.POIIndex.current(ctx)
+	if err != nil {
+		log.Printf("Erro ao consultar preços de combustível: %v", err)
+		return 0
+	}
+	geometry, err := routeGeometryFromPolyline(route.Geometry)
+	if err != nil {
+		return snap.fuelPriceAverage
+	}
+
+	var sum float64
+	var count int
+	for _, m := range geometry.match(snap.stationGrid, snap.stationPositions, fuelPriceRouteTolerance) {
+		if price := snap.stations[m.Index].Price; price != nil {
+			sum += *price
+			count++
+		}
+	}
+	if count == 0 {
+		return snap.fuelPriceAverage
+	}
+	return roundCents(sum / float64(count))
+}
+
+// isDieselVehicle informa se o tipo de veículo abastece com diesel (caminhão e ônibus)
+func isDieselVehicle(vehicleType string) bool {
+	switch strings.ToLower(strings.TrimSpace(vehicleType)) {
+	case "truck", "bus":
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,74 @@
+package new_routes
+
+import "testing"
+
+func TestStationFuelPrice(t *testing.T) {
+	// Bom Jesus existe no PI e no RS; Campinas só tem preço em SP
+	cities := cityStates{
+		"bom jesus": {
+			{uf: "PI", pos: LatLng{Lat: -9.07, Lng: -44.36}, located: true},
+			{uf: "RS", pos: LatLng{Lat: -28.67, Lng: -50.43}, located: true},
+		},
+		"campinas":   {{uf: "SP", pos: LatLng{Lat: -22.9, Lng: -47.06}, located: true}},
+		"santa rosa": {{uf: "RS"}, {uf: "PR"}},
+	}
+	prices := fuelPriceTable{
+		byCity: map[string]float64{"PI|bom jesus": 6.49, "RS|bom jesus": 6.19, "SP|campinas": 5.99, "RS|santa rosa": 6.05, "PR|santa rosa": 6.15},
+		states: map[string][]string{"bom jesus": {"PI", "RS"}, "campinas": {"SP"}, "santa rosa": {"RS", "PR"}},
+	}
+
+	tests := []struct {
+		name      string
+		municipio string
+		pos       LatLng
+		unknown   bool
+		wantPrice float64
+		wantOK    bool
+	}{
+		{name: "homônimo no Piauí", municipio: "bom jesus", pos: LatLng{Lat: -9.1, Lng: -44.3}, wantPrice: 6.49, wantOK: true},
+		{name: "homônimo no Rio Grande do Sul", municipio: "bom jesus", pos: LatLng{Lat: -28.6, Lng: -50.4}, wantPrice: 6.19, wantOK: true},
+		{name: "posto sem coordenada não escolhe homônimo", municipio: "bom jesus"},
+		{name: "cidades sem coordenada não desempatam", municipio: "santa rosa", pos: LatLng{Lat: -27.87, Lng: -54.48}},
+		{name: "município de uma só UF", municipio: "campinas", pos: LatLng{Lat: -22.8, Lng: -47.1}, wantPrice: 5.99, wantOK: true},
+		{name: "fora do cadastro usa a única UF com preço", municipio: "campinas", unknown: true, wantPrice: 5.99, wantOK: true},
+		{name: "sem preço", municipio: "sorocaba", pos: LatLng{Lat: -23.5, Lng: -47.45}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			known := cities
+			if tt.unknown {
+				known = cityStates{}
+			}
+			state := known.state(tt.municipio, tt.pos)
+			price, ok := prices.lookup(state, tt.municipio)
+			if price != tt.wantPrice || ok != tt.wantOK {
+				t.Errorf("preço (UF %q) = %v, %v, want %v, %v", state, price, ok, tt.wantPrice, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestFuelPriceWithRoutePrice(t *testing.T) {
+	anp := &FuelSplit{Price: 6.12}
+	tests := []struct {
+		name   string
+		price  float64
+		routes []RouteOutput
+		want   float64
+	}{
+		{name: "preço informado prevalece", price: 5.5, routes: []RouteOutput{{Summary: RouteSummary{FuelSplit: anp}}}, want: 5.5},
+		{name: "preço da ANP da primeira rota", routes: []RouteOutput{{Summary: RouteSummary{FuelSplit: anp}}, {Summary: RouteSummary{FuelSplit: &FuelSplit{Price: 7}}}}, want: 6.12},
+		{name: "pula rotas sem preço", routes: []RouteOutput{{}, {Summary: RouteSummary{FuelSplit: &FuelSplit{}}}, {Summary: RouteSummary{FuelSplit: anp}}}, want: 6.12},
+		{name: "sem preço em lugar nenhum", routes: []RouteOutput{{}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := FuelPrice{Price: tt.price, Currency: "BRL"}.withRoutePrice(tt.routes)
+			if got.Price != tt.want || got.Currency != "BRL" {
+				t.Errorf("withRoutePrice() = %+v, want preço %v", got, tt.want)
+			}
+		})
+	}
+}
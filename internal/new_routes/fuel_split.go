@@ -0,0 +1,129 @@
+package new_routes
+
+import (
+	"math"
+	"regexp"
+	"strings"
+)
+
+const (
+	// velocidade média (km/h) a partir da qual um trecho sem identificação de rodovia é tratado como estrada
+	highwayMinSpeedKmh = 60.0
+	// velocidade média (km/h) abaixo da qual mesmo um trecho de rodovia é tratado como perímetro urbano
+	urbanMaxSpeedKmh = 35.0
+)
+
+// highwayRefRegex identifica rodovias federais e estaduais (BR-116, SP 330, MG-050...)
+var highwayRefRegex = regexp.MustCompile(`(?i)\b(BR|AC|AL|AM|AP|BA|CE|DF|ES|GO|MA|MG|MS|MT|PA|PB|PE|PI|PR|RJ|RN|RO|RR|RS|SC|SE|SP|TO)[- ]?\d{2,3}\b`)
+
+// highwayNameKeywords são trechos de nome que indicam rodovia mesmo sem o código
+var highwayNameKeywords = []string{"rodovia", "rodoanel", "autoestrada", "autopista", "estrada"}
+
+// FuelSplit separa a quilometragem e o consumo de combustível da rota entre perímetro urbano e rodovia
+type FuelSplit struct {
+	KmCity     float64 `json:"km_city"`
+	KmHwy      float64 `json:"km_hwy"`
+	LitersCity float64 `json:"liters_city"`
+	LitersHwy  float64 `json:"liters_hwy"`
+	CostCity   float64 `json:"cost_city"`
+	CostHwy    float64 `json:"cost_hwy"`
+	Price      float64 `json:"price"`
+}
+
+// TotalCost devolve o custo total de combustível arredondado, no mesmo formato de TotalFuelCost
+func (f FuelSplit) TotalCost() float64 {
+	return math.Round(f.CostCity + f.CostHwy)
+}
+
+// fuelSplitForRoute classifica os passos da rota em urbano ou rodovia e aplica o consumo
+// correspondente a cada parte. Quando só um dos consumos é informado, ele vale para a rota inteira.
+func fuelSplitForRoute(route OSRMRoute, price, consumptionCity, consumptionHwy float64) FuelSplit {
+	var cityMeters, hwyMeters float64
+	for _, leg := range route.Legs {
+		if len(leg.Steps) == 0 {
+			if isHighwaySpeed(leg.Distance, leg.Duration) {
+				hwyMeters += leg.Distance
+			} else {
+				cityMeters += leg.Distance
+			}
+			continue
+		}
+		for _, step := range leg.Steps {
+			if isHighwayStep(step) {
+				hwyMeters += step.Distance
+			} else {
+				cityMeters += step.Distance
+			}
+		}
+	}
+
+	// rota sem legs (estimativas diretas): classifica pela velocidade média
+	if cityMeters+hwyMeters == 0 {
+		if isHighwaySpeed(route.Distance, route.Duration) {
+			hwyMeters = route.Distance
+		} else {
+			cityMeters = route.Distance
+		}
+	}
+
+	// os passos nem sempre somam exatamente a distância da rota; ajusta proporcionalmente
+	if total := cityMeters + hwyMeters; total > 0 && route.Distance > 0 {
+		cityMeters = cityMeters * route.Distance / total
+		hwyMeters = hwyMeters * route.Distance / total
+	}
+
+	if consumptionCity <= 0 {
+		consumptionCity = consumptionHwy
+	}
+	if consumptionHwy <= 0 {
+		consumptionHwy = consumptionCity
+	}
+
+	split := FuelSplit{Price: price}
+	split.KmCity = roundCents(cityMeters / 1000)
+	split.KmHwy = roundCents(hwyMeters / 1000)
+	if consumptionCity > 0 {
+		split.LitersCity = roundCents(cityMeters / 1000 / consumptionCity)
+		split.LitersHwy = roundCents(hwyMeters / 1000 / consumptionHwy)
+		split.CostCity = roundCents(price * cityMeters / 1000 / consumptionCity)
+		split.CostHwy = roundCents(price * hwyMeters / 1000 / consumptionHwy)
+	}
+	return split
+}
+
+// isHighwayStep decide se um passo da rota é rodovia pelo código/nome da via e pela velocidade média
+func isHighwayStep(step OSRMStep) bool {
+	speed := stepSpeedKmh(step.Distance, step.Duration)
+	if isHighwayName(step.Ref) || isHighwayName(step.Name) {
+		// travessias urbanas de rodovias (avenidas marginais, trechos com semáforo) contam como cidade
+		return speed == 0 || speed >= urbanMaxSpeedKmh
+	}
+	return speed >= highwayMinSpeedKmh
+}
+
+func isHighwayName(name string) bool {
+	if name == "" {
+		return false
+	}
+	if highwayRefRegex.MatchString(name) {
+		return true
+	}
+	lower := strings.ToLower(name)
+	for _, keyword := range highwayNameKeywords {
+		if strings.Contains(lower, keyword) {
+			return true
+		}
+	}
+	return false
+}
+
+func isHighwaySpeed(distance, duration float64) bool {
+	return stepSpeedKmh(distance, duration) >= highwayMinSpeedKmh
+}
+
+func stepSpeedKmh(distance, duration float64) float64 {
+	if duration <= 0 {
+		return 0
+	}
+	return distance / duration * 3.6
+}
@@ -0,0 +1,72 @@
+package new_routes
+
+import "testing"
+
+func fuelTestStep(name, ref string, distance, duration float64) OSRMStep {
+	return OSRMStep{Name: name, Ref: ref, Distance: distance, Duration: duration}
+}
+
+func TestFuelSplitForRoute(t *testing.T) {
+	// 10 km de BR-116 a 90 km/h e 2 km de rua a 30 km/h
+	mixed := OSRMRoute{Distance: 12000, Duration: 640, Legs: []OSRMLeg{{Steps: []OSRMStep{
+		fuelTestStep("Rodovia Presidente Dutra", "BR-116", 10000, 400),
+		fuelTestStep("Rua Augusta", "", 2000, 240),
+	}}}}
+
+	tests := []struct {
+		name                 string
+		route                OSRMRoute
+		price, city, highway float64
+		want                 FuelSplit
+	}{
+		{
+			name: "rodovia e cidade com consumos diferentes", route: mixed, price: 6, city: 4, highway: 5,
+			want: FuelSplit{KmCity: 2, KmHwy: 10, LitersCity: 0.5, LitersHwy: 2, CostCity: 3, CostHwy: 12, Price: 6},
+		},
+		{
+			name: "travessia urbana de rodovia conta como cidade",
+			route: OSRMRoute{Distance: 6000, Legs: []OSRMLeg{{Steps: []OSRMStep{
+				fuelTestStep("Rodovia Anhanguera", "SP 330", 1000, 200),
+				fuelTestStep("", "", 5000, 250),
+			}}}},
+			price: 5, city: 2, highway: 5,
+			want: FuelSplit{KmCity: 1, KmHwy: 5, LitersCity: 0.5, LitersHwy: 1, CostCity: 2.5, CostHwy: 5, Price: 5},
+		},
+		{
+			name: "passos ajustados à distância da rota",
+			route: OSRMRoute{Distance: 6000, Legs: []OSRMLeg{{Steps: []OSRMStep{
+				fuelTestStep("", "BR-381", 5000, 200),
+			}}}},
+			price: 5, city: 3, highway: 3,
+			want: FuelSplit{KmHwy: 6, LitersHwy: 2, CostHwy: 10, Price: 5},
+		},
+		{
+			name:  "perna sem passos pela velocidade",
+			route: OSRMRoute{Distance: 10000, Legs: []OSRMLeg{{Distance: 10000, Duration: 400}}},
+			price: 5, city: 2, highway: 5,
+			want: FuelSplit{KmHwy: 10, LitersHwy: 2, CostHwy: 10, Price: 5},
+		},
+		{
+			name:  "rota sem pernas lenta é cidade",
+			route: OSRMRoute{Distance: 10000, Duration: 1200},
+			price: 5, city: 2, highway: 5,
+			want: FuelSplit{KmCity: 10, LitersCity: 5, CostCity: 25, Price: 5},
+		},
+		{
+			name: "só o consumo de rodovia vale para tudo", route: mixed, price: 6, highway: 4,
+			want: FuelSplit{KmCity: 2, KmHwy: 10, LitersCity: 0.5, LitersHwy: 2.5, CostCity: 3, CostHwy: 15, Price: 6},
+		},
+		{
+			name: "sem consumo só divide os km", route: mixed, price: 6,
+			want: FuelSplit{KmCity: 2, KmHwy: 10, Price: 6},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := fuelSplitForRoute(tt.route, tt.price, tt.city, tt.highway); got != tt.want {
+				t.Errorf("fuelSplitForRoute() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
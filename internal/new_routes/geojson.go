@@ -0,0 +1,68 @@
+package new_routes
+
+// Tipos GeoJSON (RFC 7946) usados nas respostas de áreas e exportações.
+// As coordenadas seguem a ordem [longitude, latitude].
+
+const (
+	GeoJSONFeatureCollectionType = "FeatureCollection"
+	GeoJSONFeatureType           = "Feature"
+	GeoJSONPolygon               = "Polygon"
+	GeoJSONLineString            = "LineString"
+	GeoJSONPoint                 = "Point"
+)
+
+type GeoJSONFeatureCollection struct {
+	Type     string           `json:"type"`
+	Features []GeoJSONFeature `json:"features"`
+}
+
+type GeoJSONFeature struct {
+	Type       string                 `json:"type"`
+	Geometry   GeoJSONGeometry        `json:"geometry"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+type GeoJSONGeometry struct {
+	Type        string      `json:"type"`
+	Coordinates interface{} `json:"coordinates"`
+}
+
+func newFeatureCollection() GeoJSONFeatureCollection {
+	return GeoJSONFeatureCollection{Type: GeoJSONFeatureCollectionType, Features: []GeoJSONFeature{}}
+}
+
+// polygonFeature fecha o anel (primeiro ponto repetido no final) e monta a feature
+func polygonFeature(ring []Location, properties map[string]interface{}) GeoJSONFeature {
+	coords := make([][2]float64, 0, len(ring)+1)
+	for _, p := range ring {
+		coords = append(coords, [2]float64{p.Longitude, p.Latitude})
+	}
+	if len(ring) > 0 {
+		coords = append(coords, [2]float64{ring[0].Longitude, ring[0].Latitude})
+	}
+	return GeoJSONFeature{
+		Type:       GeoJSONFeatureType,
+		Geometry:   GeoJSONGeometry{Type: GeoJSONPolygon, Coordinates: [][][2]float64{coords}},
+		Properties: properties,
+	}
+}
+
+func pointFeature(p Location, properties map[string]interface{}) GeoJSONFeature {
+	return GeoJSONFeature{
+		Type:       GeoJSONFeatureType,
+		Geometry:   GeoJSONGeometry{Type: GeoJSONPoint, Coordinates: [2]float64{p.Longitude, p.Latitude}},
+		Properties: properties,
+	}
+}
+
+func lineStringFeature(points []LatLng, properties map[string]interface{}) GeoJSONFeature {
+	coords := make([][2]float64, 0, len(points))
+	for _, p := range points {
+		coords = append(coords, [2]float64{p.Lng, p.Lat})
+	}
+	return GeoJSONFeature{
+		Type:       GeoJSONFeatureType,
+		Geometry:   GeoJSONGeometry{Type: GeoJSONLineString, Coordinates: coords},
+		Properties: properties,
+	}
+}
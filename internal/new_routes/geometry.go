@@ -0,0 +1,200 @@
+package new_routes
+
+import (
+	"math"
+	"sort"
+	"sync"
+)
+
+const (
+	// geometryCacheSize limita as polylines decodificadas mantidas em memória
+	geometryCacheSize = 256
+	metersPerDegree   = 111320.0
+)
+
+// RouteGeometry é a polyline de uma rota decodificada uma única vez, com a distância acumulada (m)
+// até cada ponto. É a referência linear usada para casar pedágios, balanças e postos com a rota.
+type RouteGeometry struct {
+	Points     []LatLng
+	Cumulative []float64
+	MinLat     float64
+	MinLng     float64
+	MaxLat     float64
+	MaxLng     float64
+}
+
+// RouteMatch é um item do índice espacial casado com a rota
+type RouteMatch struct {
+	Index    int
+	Distance float64
+	Along    float64
+	Segment  int
+}
+
+var geometryCache = struct {
+	sync.Mutex
+	items map[string]*RouteGeometry
+}{items: make(map[string]*RouteGeometry)}
+
+// routeGeometryFromPolyline decodifica a polyline ou reaproveita a decodificação feita para a mesma rota
+func routeGeometryFromPolyline(encoded string) (*RouteGeometry, error) {
+	geometryCache.Lock()
+	if g, ok := geometryCache.items[encoded]; ok {
+		geometryCache.Unlock()
+		return g, nil
+	}
+	geometryCache.Unlock()
+
+	points, err := decodePolyline(encoded)
+	if err != nil {
+		return nil, err
+	}
+	g := newRouteGeometry(points)
+
+	geometryCache.Lock()
+	if len(geometryCache.items) >= geometryCacheSize {
+		geometryCache.items = make(map[string]*RouteGeometry)
+	}
+	geometryCache.items[encoded] = g
+	geometryCache.Unlock()
+	return g, nil
+}
+
+func newRouteGeometry(points []LatLng) *RouteGeometry {
+	g := &RouteGeometry{Points: points, Cumulative: make([]float64, len(points))}
+	if len(points) == 0 {
+		return g
+	}
+	g.MinLat, g.MaxLat = points[0].Lat, points[0].Lat
+	g.MinLng, g.MaxLng = points[0].Lng, points[0].Lng
+	for i := 1; i < len(points); i++ {
+		g.Cumulative[i] = g.Cumulative[i-1] + haversineDistanceTolls(points[i-1].Lat, points[i-1].Lng, points[i].Lat, points[i].Lng)
+		g.MinLat = math.Min(g.MinLat, points[i].Lat)
+		g.MaxLat = math.Max(g.MaxLat, points[i].Lat)
+		g.MinLng = math.Min(g.MinLng, points[i].Lng)
+		g.MaxLng = math.Max(g.MaxLng, points[i].Lng)
+	}
+	return g
+}
+
+// Length devolve o comprimento total da rota em metros
+func (g *RouteGeometry) Length() float64 {
+	if len(g.Cumulative) == 0 {
+		return 0
+	}
+	return g.Cumulative[len(g.Cumulative)-1]
+}
+
+// locate devolve a posição (m desde a origem) da projeção do ponto no segmento mais próximo da rota
+func (g *RouteGeometry) locate(p LatLng) float64 {
+	best, along := math.Inf(1), 0.0
+	for i := 0; i < len(g.Points)-1; i++ {
+		dist, t := projectPointToSegment(p, g.Points[i], g.Points[i+1])
+		if dist < best {
+			best = dist
+			along = g.Cumulative[i] + t*(g.Cumulative[i+1]-g.Cumulative[i])
+		}
+	}
+	return along
+}
+
+// slice devolve o trecho da rota entre from e to metros da origem, com as pontas interpoladas
+func (g *RouteGeometry) slice(from, to float64) []LatLng {
+	if len(g.Points) == 0 || to <= from {
+		return nil
+	}
+	points := []LatLng{g.pointAt(from)}
+	for i, cum := range g.Cumulative {
+		if cum > from && cum < to {
+			points = append(points, g.Points[i])
+		}
+	}
+	return append(points, g.pointAt(to))
+}
+
+// match casa os itens do índice com a rota. Para cada segmento só as células da grade próximas
+// são consultadas, então o custo cresce com o tamanho da rota e não com o total de itens.
+// Devolve a melhor projeção de cada item, ordenada pela posição ao longo da rota.
+func (g *RouteGeometry) match(grid *gridIndex, positions []LatLng, tolerance float64) []RouteMatch {
+	if grid == nil || len(g.Points) < 2 {
+		return nil
+	}
+
+	best := make(map[int]RouteMatch)
+	latPad := tolerance / metersPerDegree
+	for i := 0; i < len(g.Points)-1; i++ {
+		v, w := g.Points[i], g.Points[i+1]
+		lngPad := tolerance / (metersPerDegree * math.Max(math.Cos(v.Lat*math.Pi/180), 0.01))
+		grid.query(
+			math.Min(v.Lat, w.Lat)-latPad, math.Min(v.Lng, w.Lng)-lngPad,
+			math.Max(v.Lat, w.Lat)+latPad, math.Max(v.Lng, w.Lng)+lngPad,
+			func(idx int) {
+				dist, t := projectPointToSegment(positions[idx], v, w)
+				if dist > tolerance {
+					return
+				}
+				if current, ok := best[idx]; ok && current.Distance <= dist {
+					return
+				}
+				best[idx] = RouteMatch{
+					Index:    idx,
+					Distance: dist,
+					Along:    g.Cumulative[i] + t*(g.Cumulative[i+1]-g.Cumulative[i]),
+					Segment:  i,
+				}
+			},
+		)
+	}
+
+	matches := make([]RouteMatch, 0, len(best))
+	for _, m := range best {
+		matches = append(matches, m)
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Along == matches[j].Along {
+			return matches[i].Index < matches[j].Index
+		}
+		return matches[i].Along < matches[j].Along
+	})
+	return matches
+}
+
+// gridIndex é um índice espacial em grade regular (graus) sobre posições indexadas por inteiro
+type gridIndex struct {
+	cellSize float64
+	cells    map[gridCell][]int
+}
+
+type gridCell struct {
+	x, y int
+}
+
+func newGridIndex(cellSize float64, positions []LatLng) *gridIndex {
+	g := &gridIndex{cellSize: cellSize, cells: make(map[gridCell][]int)}
+	for i, p := range positions {
+		if p.Lat == 0 && p.Lng == 0 {
+			// Coordenada ausente ou inválida no cadastro
+			continue
+		}
+		c := g.cell(p.Lat, p.Lng)
+		g.cells[c] = append(g.cells[c], i)
+	}
+	return g
+}
+
+func (g *gridIndex) cell(lat, lng float64) gridCell {
+	return gridCell{x: int(math.Floor(lng / g.cellSize)), y: int(math.Floor(lat / g.cellSize))}
+}
+
+// query visita os itens das células que cobrem o retângulo; itens podem estar fora do retângulo
+func (g *gridIndex) query(minLat, minLng, maxLat, maxLng float64, visit func(idx int)) {
+	lo := g.cell(minLat, minLng)
+	hi := g.cell(maxLat, maxLng)
+	for x := lo.x; x <= hi.x; x++ {
+		for y := lo.y; y <= hi.y; y++ {
+			for _, idx := range g.cells[gridCell{x: x, y: y}] {
+				visit(idx)
+			}
+		}
+	}
+}
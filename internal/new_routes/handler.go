@@ -2,9 +2,11 @@ package new_routes
 
 import (
 	"errors"
+	"fmt"
 	"geolocation/internal/get_token"
 	"geolocation/validation"
 	"net/http"
+	"strings"
 
 	"github.com/labstack/echo/v4"
 )
@@ -27,7 +29,7 @@ func NewRoutesNewHandler(InterfaceService InterfaceService) *Handler {
 // @Description - axles: 2 (Quantidade de eixos, possível somente: 2, 4, 6, 8, 9)
 // @Description - consumptionCity: 20 (Consumo de combustível na cidade)
 // @Description - consumptionHwy: 22 (Consumo de combustível na estrada)
-// @Description - price: 6.20 (Preço da gasolina)
+// @Description - price: 6.20 (Preço do diesel; omitido ou 0 usa a média regional da ANP ao longo da rota)
 // @Description - waypoints: ["Rio de Janeiro", "Vitória da Conquista"] (Lista de pontos de parada)
 // @Description - favorite: true (Se deseja favoritar essa rota)
 // @Description - type: "Auto" (Tipo do automóvel, possíveis: Truck, Bus, Auto, Motorcycle)
@@ -40,6 +42,8 @@ func NewRoutesNewHandler(InterfaceService InterfaceService) *Handler {
 // @Description       include_freight_calc: false, (traz frestes, segundo a ANTT calculados)
 // @Description       include_polyline: false (traz polyline para renderizar em mapas)
 // @Description   } (Opções adicionais para a rota)
+// @Description - driver_rules: {"enabled": true, "driven_today_minutes": 120} (Opcional; planeja pausas e descanso da Lei 13.103 e devolve driver_itinerary com a chegada legal)
+// @Description - locale: "es" (Opcional; idioma das instruções: pt-BR, es ou en. Sem ele usa o cabeçalho Accept-Language)
 // @Tags Routes
 // @Accept json
 // @Produce json
@@ -55,12 +59,14 @@ func (h *Handler) CalculateRoutes(e echo.Context) error {
 	if err := e.Bind(&frontInfo); err != nil {
 		return e.JSON(http.StatusBadRequest, err.Error())
 	}
+	frontInfo.Locale = requestLocale(e, frontInfo.Locale)
 
 	err := validation.Validate(frontInfo)
 	if err != nil {
 		return e.JSON(http.StatusBadRequest, err.Error())
 	}
 
+	frontInfo.TokenOrgID = get_token.GetPayloadToken(e).UserOrgId
 	payloadPublic := get_token.GetPublicPayloadToken(e)
 	payload := get_token.GetUserPayloadToken(e)
 	result, err := h.InterfaceService.CalculateRoutes(e.Request().Context(), frontInfo, payloadPublic.ID, payload.ID)
@@ -87,7 +93,7 @@ func (h *Handler) CalculateRoutes(e echo.Context) error {
 // @Description - axles: 2 (Quantidade de eixos, possível somente: 2, 4, 6, 8, 9)
 // @Description - consumptionCity: 20 (Consumo de combustível na cidade)
 // @Description - consumptionHwy: 22 (Consumo de combustível na estrada)
-// @Description - price: 6.20 (Preço da gasolina)
+// @Description - price: 6.20 (Preço do diesel; omitido ou 0 usa a média regional da ANP ao longo da rota)
 // @Description - waypoints: [{\"lat\": \"-23.223701\",\"lng\": \"-45.900907\"},{\"lat\": \"-22.755611\",\"lng\": \"-44.168869\"}] (Lista de pontos de parada, definida pelas coordenadas)
 // @Description - favorite: true (Se deseja favoritar essa rota)
 // @Description - type: \"Auto\" (Tipo do automóvel, possível: Truck, Bus, Auto, Motorcycle)
@@ -100,6 +106,8 @@ func (h *Handler) CalculateRoutes(e echo.Context) error {
 // @Description       include_freight_calc: false, (traz frestes, segundo a ANTT calculados)
 // @Description       include_polyline: false (traz polyline para renderizar em mapas)
 // @Description   } (Opções adicionais para a rota)
+// @Description - driver_rules: {\"enabled\": true, \"driven_today_minutes\": 120} (Opcional; planeja pausas e descanso da Lei 13.103 e devolve driver_itinerary com a chegada legal)
+// @Description - locale: \"es\" (Opcional; idioma das instruções: pt-BR, es ou en. Sem ele usa o cabeçalho Accept-Language)
 // @Tags Routes
 // @Accept json
 // @Produce json
@@ -115,12 +123,14 @@ func (h *Handler) CalculateRoutesWithCoordinate(e echo.Context) error {
 	if err := e.Bind(&frontInfo); err != nil {
 		return e.JSON(http.StatusBadRequest, err.Error())
 	}
+	frontInfo.Locale = requestLocale(e, frontInfo.Locale)
 
 	err := validation.Validate(frontInfo)
 	if err != nil {
 		return e.JSON(http.StatusBadRequest, err.Error())
 	}
 
+	frontInfo.TokenOrgID = get_token.GetPayloadToken(e).UserOrgId
 	payloadPublic := get_token.GetPublicPayloadToken(e)
 	payload := get_token.GetUserPayloadToken(e)
 	result, err := h.InterfaceService.CalculateRoutesWithCoordinate(e.Request().Context(), frontInfo, payloadPublic.ID, payload.ID)
@@ -144,7 +154,7 @@ func (h *Handler) CalculateRoutesWithCoordinate(e echo.Context) error {
 // @Description   - destination_cep: \"20040002\" (CEP de destino)
 // @Description   - consumptionCity: 20         (Consumo de combustível na cidade, em km/l)
 // @Description   - consumptionHwy: 22         (Consumo de combustível na estrada, em km/l)
-// @Description   - price: 6.20                (Preço do combustível em BRL)
+// @Description   - price: 6.20                (Preço do combustível em BRL; omitido usa a média regional da ANP)
 // @Description   - axles: 2                  (Quantidade de eixos: 2, 4, 6, 8, 9)
 // @Description   - waypoints: [\"01310940\",\"20050013\"] (Lista de CEPs para pontos de parada)
 // @Description   - public_or_private: \"public\" | \"private\" (Define se conta na cota pública ou privada)
@@ -159,6 +169,7 @@ func (h *Handler) CalculateRoutesWithCoordinate(e echo.Context) error {
 // @Description         include_freight_calc: false,     (retorna cálculo de frete ANTT)
 // @Description         include_polyline: false          (retorna polyline para mapas)
 // @Description     } (Opções adicionais para a rota)
+// @Description   - locale: \"es\"              (Opcional; idioma das instruções: pt-BR, es ou en. Sem ele usa o Accept-Language)
 // @Tags Routes
 // @Accept json
 // @Produce json
@@ -174,6 +185,7 @@ func (h *Handler) CalculateRoutesWithCEP(e echo.Context) error {
 	if err := e.Bind(&frontInfo); err != nil {
 		return e.JSON(http.StatusBadRequest, err.Error())
 	}
+	frontInfo.Locale = requestLocale(e, frontInfo.Locale)
 
 	err := validation.Validate(frontInfo)
 	if err != nil {
@@ -183,6 +195,7 @@ func (h *Handler) CalculateRoutesWithCEP(e echo.Context) error {
 	payloadPublic := get_token.GetPublicPayloadToken(e)
 	payloadSimp := get_token.GetPayloadToken(e)
 	payload := get_token.GetUserPayloadToken(e)
+	frontInfo.TokenOrgID = payloadSimp.UserOrgId
 	result, err := h.InterfaceService.CalculateRoutesWithCEP(e.Request().Context(), frontInfo, payloadPublic.ID, payload.ID, payloadSimp)
 	if err != nil {
 		statusCode := http.StatusInternalServerError
@@ -212,6 +225,7 @@ func (h *Handler) CalculateRoutesWithCEPOnly(e echo.Context) error {
 	if err := e.Bind(&frontInfo); err != nil {
 		return e.JSON(http.StatusBadRequest, err.Error())
 	}
+	frontInfo.Locale = requestLocale(e, frontInfo.Locale)
 
 	err := validation.Validate(frontInfo)
 	if err != nil {
@@ -221,6 +235,7 @@ func (h *Handler) CalculateRoutesWithCEPOnly(e echo.Context) error {
 	payloadPublic := get_token.GetPublicPayloadToken(e)
 	payloadSimp := get_token.GetPayloadToken(e)
 	payload := get_token.GetUserPayloadToken(e)
+	frontInfo.TokenOrgID = payloadSimp.UserOrgId
 
 	result, err := h.InterfaceService.CalculateRoutesWithCEPOnly(e.Request().Context(), frontInfo, payloadPublic.ID, payload.ID, payloadSimp)
 	if err != nil {
@@ -239,6 +254,8 @@ func (h *Handler) CalculateRoutesCEP(e echo.Context) error {
 	if err := e.Bind(&frontInfo); err != nil {
 		return e.JSON(http.StatusBadRequest, err.Error())
 	}
+	frontInfo.Locale = requestLocale(e, frontInfo.Locale)
+	frontInfo.TokenOrgID = get_token.GetPayloadToken(e).UserOrgId
 
 	result, err := h.InterfaceService.CalculateDistancesBetweenPoints(e.Request().Context(), frontInfo)
 	if err != nil {
@@ -257,6 +274,7 @@ func (h *Handler) CalculateRoutesCEPV2(e echo.Context) error {
 	if err := e.Bind(&frontInfo); err != nil {
 		return e.JSON(http.StatusBadRequest, err.Error())
 	}
+	frontInfo.Locale = requestLocale(e, frontInfo.Locale)
 
 	result, err := h.InterfaceService.CalculateDistancesBetweenPointsV2(e.Request().Context(), frontInfo)
 	if err != nil {
@@ -275,6 +293,7 @@ func (h *Handler) CalculateDistancesFromOrigin(e echo.Context) error {
 	if err := e.Bind(&frontInfo); err != nil {
 		return e.JSON(http.StatusBadRequest, err.Error())
 	}
+	frontInfo.Locale = requestLocale(e, frontInfo.Locale)
 
 	result, err := h.InterfaceService.CalculateDistancesFromOrigin(e.Request().Context(), frontInfo)
 	if err != nil {
@@ -388,6 +407,8 @@ func (h *Handler) CalculateDistancesBetweenPointsWithRiskAvoidanceHandler(c echo
 			"error": "Dados de entrada inválidos: " + err.Error(),
 		})
 	}
+	req.Locale = requestLocale(c, req.Locale)
+	req.TokenOrgID = get_token.GetPayloadToken(c).UserOrgId
 
 	if len(req.CEPs) < 2 {
 		return c.JSON(http.StatusBadRequest, map[string]string{
@@ -413,6 +434,8 @@ func (h *Handler) CalculateDistancesBetweenPointsWithRiskAvoidanceFromCoordinate
 			"error": "Dados de entrada inválidos: " + err.Error(),
 		})
 	}
+	req.Locale = requestLocale(c, req.Locale)
+	req.TokenOrgID = get_token.GetPayloadToken(c).UserOrgId
 
 	if len(req.Coordinates) < 2 {
 		return c.JSON(http.StatusBadRequest, map[string]string{
@@ -465,3 +488,472 @@ func (h *Handler) GetCoordinatesFromAddress(c echo.Context) error {
 
 	return c.JSON(http.StatusOK, location)
 }
+
+// PlanFleetHandler godoc
+// @Summary Planejar frota.
+// @Description Distribui anúncios entre caminhões respeitando capacidade e janelas de coleta/entrega.
+// @Description
+// @Description Campos esperados no body:
+// @Description - trucks: [{truck_id, tractor_unit_id, trailer_id, lat, lng, capacity, axles, type, available_from}] (Caminhões e posição atual)
+// @Description - advertisement_ids: [1, 2, 3] (Anúncios do usuário ou com agendamento ativo dele como transportador)
+// @Description - consumptionCity / consumptionHwy / price (Cálculo de combustível)
+// @Description - service_minutes: 60 (Tempo de carga/descarga por parada)
+// @Description - departure_time: "2025-01-01T08:00:00Z" (Saída dos caminhões, padrão agora)
+// @Tags Routes
+// @Accept json
+// @Produce json
+// @Param request body FleetPlanRequest true "Requisição de planejamento"
+// @Success 200 {object} FleetPlanResponse "Itinerários por caminhão"
+// @Failure 400 {string} string "Requisição Inválida"
+// @Failure 500 {string} string "Erro Interno do Servidor"
+// @Router /route/fleet-plan [post]
+// @Security ApiKeyAuth
+func (h *Handler) PlanFleetHandler(e echo.Context) error {
+	var request FleetPlanRequest
+	if err := e.Bind(&request); err != nil {
+		return e.JSON(http.StatusBadRequest, err.Error())
+	}
+
+	err := validation.Validate(request)
+	if err != nil {
+		return e.JSON(http.StatusBadRequest, err.Error())
+	}
+
+	request.UserID = get_token.GetUserPayloadToken(e).ID
+	result, err := h.InterfaceService.PlanFleet(e.Request().Context(), request)
+	if err != nil {
+		return e.JSON(http.StatusInternalServerError, err.Error())
+	}
+
+	return e.JSON(http.StatusOK, result)
+}
+
+// CalculateIsochronesHandler godoc
+// @Summary Calcular área alcançável (isócrona).
+// @Description Retorna polígonos GeoJSON com a área alcançável a partir de uma origem por tempo ou distância.
+// @Description
+// @Description Campos esperados no body:
+// @Description - origin / origin_cep / coordinate: {lat, lng} (Origem, basta um deles)
+// @Description - time_budgets: [60, 240] (Tempos em minutos)
+// @Description - distance_budgets: [300] (Distâncias em km)
+// @Description - type: "Truck" (Tipo do veículo)
+// @Description - axles: 6 (Quantidade de eixos)
+// @Description - directions: 24 (Quantidade de direções amostradas, máximo 72)
+// @Tags Routes
+// @Accept json
+// @Produce json
+// @Param request body IsochroneRequest true "Requisição de isócrona"
+// @Success 200 {object} IsochroneResponse "Áreas alcançáveis"
+// @Failure 400 {string} string "Requisição Inválida"
+// @Failure 500 {string} string "Erro Interno do Servidor"
+// @Router /route/isochrone [post]
+// @Security ApiKeyAuth
+func (h *Handler) CalculateIsochronesHandler(e echo.Context) error {
+	var request IsochroneRequest
+	if err := e.Bind(&request); err != nil {
+		return e.JSON(http.StatusBadRequest, err.Error())
+	}
+
+	err := validation.Validate(request)
+	if err != nil {
+		return e.JSON(http.StatusBadRequest, err.Error())
+	}
+
+	result, err := h.InterfaceService.CalculateIsochrones(e.Request().Context(), request)
+	if err != nil {
+		return e.JSON(http.StatusInternalServerError, err.Error())
+	}
+
+	return e.JSON(http.StatusOK, result)
+}
+
+// CalculateMatrixHandler godoc
+// @Summary Calcular matriz de distâncias e custos.
+// @Description Calcula distância, duração, pedágio e combustível para cada par origem × destino.
+// @Description
+// @Description Campos esperados no body:
+// @Description - origins / destinations: [{cep, address, lat, lng}] (Basta um campo por ponto)
+// @Description - consumptionCity / consumptionHwy / price (Cálculo de combustível)
+// @Description - axles: 6 (Quantidade de eixos)
+// @Description - type: "Truck" (Tipo do veículo)
+// @Tags Routes
+// @Accept json
+// @Produce json
+// @Param request body MatrixRequest true "Requisição da matriz"
+// @Success 200 {object} MatrixResponse "Matriz de distâncias e custos"
+// @Failure 400 {string} string "Requisição Inválida"
+// @Failure 500 {string} string "Erro Interno do Servidor"
+// @Router /route/matrix [post]
+// @Security ApiKeyAuth
+func (h *Handler) CalculateMatrixHandler(e echo.Context) error {
+	var request MatrixRequest
+	if err := e.Bind(&request); err != nil {
+		return e.JSON(http.StatusBadRequest, err.Error())
+	}
+
+	err := validation.Validate(request)
+	if err != nil {
+		return e.JSON(http.StatusBadRequest, err.Error())
+	}
+
+	result, err := h.InterfaceService.CalculateMatrix(e.Request().Context(), request)
+	if err != nil {
+		return e.JSON(http.StatusInternalServerError, err.Error())
+	}
+
+	return e.JSON(http.StatusOK, result)
+}
+
+// AnalyzeTagsHandler godoc
+// @Summary Recomendação de tag de pedágio.
+// @Description Compara os fornecedores de tag pela cobertura e pelo custo estimado nas rotas do usuário.
+// @Description
+// @Description Campos esperados no body:
+// @Description - route_hist_id / route_index: analisa uma única rota salva
+// @Description - start_date / end_date: "2025-01-31" (Período analisado; padrão: últimos 30 dias)
+// @Description - vehicles: 10 (Veículos da frota, multiplica a mensalidade)
+// @Description - type / axles: recalcula as tarifas para o veículo; sem eles vale o valor gravado na rota
+// @Tags Routes
+// @Accept json
+// @Produce json
+// @Param request body TagAnalysisRequest true "Requisição da análise de tags"
+// @Success 200 {object} TagAnalysisResponse "Cobertura e custo por tag"
+// @Failure 400 {string} string "Requisição Inválida"
+// @Failure 500 {string} string "Erro Interno do Servidor"
+// @Router /route/tag-analysis [post]
+// @Security ApiKeyAuth
+func (h *Handler) AnalyzeTagsHandler(e echo.Context) error {
+	var request TagAnalysisRequest
+	if err := e.Bind(&request); err != nil {
+		return e.JSON(http.StatusBadRequest, err.Error())
+	}
+
+	payload := get_token.GetUserPayloadToken(e)
+	result, err := h.InterfaceService.AnalyzeTags(e.Request().Context(), TagAnalysisDTO{
+		Request: request,
+		UserID:  payload.ID,
+	})
+	if err != nil {
+		return e.JSON(http.StatusInternalServerError, err.Error())
+	}
+
+	return e.JSON(http.StatusOK, result)
+}
+
+// PlanRefuelHandler godoc
+// @Summary Planejar paradas de abastecimento.
+// @Description Recomenda postos ao longo da rota escolhida e quantos litros comprar em cada um.
+// @Description
+// @Description Campos esperados no body:
+// @Description - route_hist_id / route_index: usa uma rota salva (precisa ter polyline)
+// @Description - points: [{cep, address, lat, lng}] (Origem, paradas e destino, quando não há rota salva)
+// @Description - tank_capacity: 600 / current_level: 250 (Litros)
+// @Description - consumptionCity / consumptionHwy (km/l) e price (Preço do litro quando o posto não tem preço; padrão: média regional da ANP)
+// @Description - reserve_percent: 15 (Margem do tanque que não é consumida; padrão 15)
+// @Description - tolerance: 300 (Distância máxima em metros entre o posto e a rota)
+// @Tags Routes
+// @Accept json
+// @Produce json
+// @Param request body RefuelPlanRequest true "Requisição do plano de abastecimento"
+// @Success 200 {object} RefuelPlanResponse "Paradas de abastecimento"
+// @Failure 400 {string} string "Requisição Inválida"
+// @Failure 500 {string} string "Erro Interno do Servidor"
+// @Router /route/refuel-plan [post]
+// @Security ApiKeyAuth
+func (h *Handler) PlanRefuelHandler(e echo.Context) error {
+	var request RefuelPlanRequest
+	if err := e.Bind(&request); err != nil {
+		return e.JSON(http.StatusBadRequest, err.Error())
+	}
+
+	err := validation.Validate(request)
+	if err != nil {
+		return e.JSON(http.StatusBadRequest, err.Error())
+	}
+
+	request.UserID = get_token.GetUserPayloadToken(e).ID
+	result, err := h.InterfaceService.PlanRefuel(e.Request().Context(), request)
+	if err != nil {
+		return e.JSON(http.StatusInternalServerError, err.Error())
+	}
+
+	return e.JSON(http.StatusOK, result)
+}
+
+// ExportRouteHandler godoc
+// @Summary Exportar rota em GPX, KML ou GeoJSON.
+// @Description Gera o arquivo da rota gravada com a linha da rota, pedágios, balanças, postos e trechos em zonas de atenção.
+// @Description
+// @Description - source: route_hist (histórico) ou favorite (rota favorita)
+// @Description - format: gpx (GPS de caminhão), kml (Google Earth) ou geojson (padrão; ferramentas de GIS)
+// @Description - route_index: 0 (Rota escolhida dentro da resposta gravada)
+// @Tags Routes
+// @Produce application/gpx+xml
+// @Produce application/vnd.google-earth.kml+xml
+// @Produce application/geo+json
+// @Param source path string true "Origem da rota: route_hist ou favorite"
+// @Param id path int true "ID da rota"
+// @Param format query string false "gpx, kml ou geojson"
+// @Param route_index query int false "Índice da rota na resposta"
+// @Success 200 {file} file "Arquivo da rota"
+// @Failure 400 {string} string "Requisição Inválida"
+// @Failure 500 {string} string "Erro Interno do Servidor"
+// @Router /route/export/{source}/{id} [get]
+// @Security ApiKeyAuth
+func (h *Handler) ExportRouteHandler(e echo.Context) error {
+	id, err := validation.ParseStringToInt64(e.Param("id"))
+	if err != nil {
+		return e.JSON(http.StatusBadRequest, err.Error())
+	}
+	routeIndex, err := validation.ParseStringToInt64(e.QueryParam("route_index"))
+	if err != nil {
+		return e.JSON(http.StatusBadRequest, err.Error())
+	}
+
+	request := RouteExportRequest{
+		Source:     e.Param("source"),
+		ID:         id,
+		Format:     strings.ToLower(e.QueryParam("format")),
+		RouteIndex: routeIndex,
+		UserID:     get_token.GetUserPayloadToken(e).ID,
+	}
+	if request.Format == "" {
+		request.Format = ExportFormatGeoJSON
+	}
+
+	err = validation.Validate(request)
+	if err != nil {
+		return e.JSON(http.StatusBadRequest, err.Error())
+	}
+
+	file, err := h.InterfaceService.ExportRoute(e.Request().Context(), request)
+	if err != nil {
+		return e.JSON(http.StatusInternalServerError, err.Error())
+	}
+
+	e.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", file.FileName))
+	return e.Blob(http.StatusOK, file.ContentType, file.Content)
+}
+
+// GenerateRotogramaHandler godoc
+// @Summary Gerar rotograma em PDF.
+// @Description Gera o PDF do rotograma da rota do histórico e guarda no armazenamento de anexos (S3).
+// @Description
+// @Description Campos esperados no body:
+// @Description - route_hist_id / route_index: rota do histórico e rota escolhida dentro dela
+// @Description - tractor_unit_id / trailer_id: 10 (Opcional; placa e modelo no cabeçalho)
+// @Description - driver_name: "João" (Opcional; motorista no cabeçalho)
+// @Tags Routes
+// @Accept json
+// @Produce json
+// @Param request body RotogramaRequest true "Requisição do rotograma"
+// @Success 200 {object} RotogramaResponse "Rotograma gerado"
+// @Failure 400 {string} string "Requisição Inválida"
+// @Failure 500 {string} string "Erro Interno do Servidor"
+// @Router /route/rotograma [post]
+// @Security ApiKeyAuth
+func (h *Handler) GenerateRotogramaHandler(e echo.Context) error {
+	var request RotogramaRequest
+	if err := e.Bind(&request); err != nil {
+		return e.JSON(http.StatusBadRequest, err.Error())
+	}
+
+	err := validation.Validate(request)
+	if err != nil {
+		return e.JSON(http.StatusBadRequest, err.Error())
+	}
+
+	request.Locale = requestLocale(e, request.Locale)
+	request.UserID = get_token.GetUserPayloadToken(e).ID
+	result, err := h.InterfaceService.GenerateRotograma(e.Request().Context(), request)
+	if err != nil {
+		return e.JSON(http.StatusInternalServerError, err.Error())
+	}
+
+	return e.JSON(http.StatusOK, result)
+}
+
+// GetRotogramaHandler godoc
+// @Summary Buscar rotograma da rota.
+// @Description Retorna o link do último rotograma gerado pelo usuário para a rota do histórico.
+// @Tags Routes
+// @Produce json
+// @Param id path int true "ID da rota no histórico (route_hist_id)"
+// @Param route_index query int false "Índice da rota na resposta"
+// @Success 200 {object} RotogramaResponse "Rotograma"
+// @Failure 400 {string} string "Requisição Inválida"
+// @Failure 500 {string} string "Erro Interno do Servidor"
+// @Router /route/rotograma/{id} [get]
+// @Security ApiKeyAuth
+func (h *Handler) GetRotogramaHandler(e echo.Context) error {
+	id, err := validation.ParseStringToInt64(e.Param("id"))
+	if err != nil {
+		return e.JSON(http.StatusBadRequest, err.Error())
+	}
+	routeIndex, err := validation.ParseStringToInt64(e.QueryParam("route_index"))
+	if err != nil {
+		return e.JSON(http.StatusBadRequest, err.Error())
+	}
+
+	payload := get_token.GetUserPayloadToken(e)
+	result, err := h.InterfaceService.GetRotograma(e.Request().Context(), id, routeIndex, payload.ID)
+	if err != nil {
+		return e.JSON(http.StatusInternalServerError, err.Error())
+	}
+
+	return e.JSON(http.StatusOK, result)
+}
+
+// RepriceRouteHandler godoc
+// @Summary Reprecificar rota gravada.
+// @Description Recalcula a rota gravada com as tarifas de pedágio e o preço do diesel atuais e devolve o diff (custo de
+// @Description pedágio, combustível, distância e duração). A versão só é gravada quando o custo total varia ao menos o limite configurado.
+// @Description
+// @Description - source: favorite (rota favorita, token de usuário) ou enterprise (rota da empresa, rota simpplify)
+// @Tags Routes
+// @Produce json
+// @Param source path string true "Origem da rota: favorite ou enterprise"
+// @Param id path int true "ID da rota"
+// @Success 200 {object} RepriceResponse "Diff e resposta atualizada"
+// @Failure 400 {string} string "Requisição Inválida"
+// @Failure 500 {string} string "Erro Interno do Servidor"
+// @Router /route/reprice/{source}/{id} [post]
+// @Router /route/reprice-simpplify/{source}/{id} [post]
+// @Security ApiKeyAuth
+func (h *Handler) RepriceRouteHandler(e echo.Context) error {
+	request, err := repriceRequest(e)
+	if err != nil {
+		return e.JSON(http.StatusBadRequest, err.Error())
+	}
+
+	result, err := h.InterfaceService.RepriceRoute(e.Request().Context(), request)
+	if err != nil {
+		return e.JSON(http.StatusInternalServerError, err.Error())
+	}
+
+	return e.JSON(http.StatusOK, result)
+}
+
+// GetRouteVersionsHandler godoc
+// @Summary Listar versões de uma rota gravada.
+// @Description Lista as versões gravadas pela reprecificação, da mais recente para a mais antiga, com o diff de cada uma.
+// @Tags Routes
+// @Produce json
+// @Param source path string true "Origem da rota: favorite ou enterprise"
+// @Param id path int true "ID da rota"
+// @Success 200 {array} RouteVersionResponse "Versões da rota"
+// @Failure 400 {string} string "Requisição Inválida"
+// @Failure 500 {string} string "Erro Interno do Servidor"
+// @Router /route/versions/{source}/{id} [get]
+// @Router /route/versions-simpplify/{source}/{id} [get]
+// @Security ApiKeyAuth
+func (h *Handler) GetRouteVersionsHandler(e echo.Context) error {
+	request, err := repriceRequest(e)
+	if err != nil {
+		return e.JSON(http.StatusBadRequest, err.Error())
+	}
+
+	result, err := h.InterfaceService.GetRouteVersions(e.Request().Context(), request)
+	if err != nil {
+		return e.JSON(http.StatusInternalServerError, err.Error())
+	}
+
+	return e.JSON(http.StatusOK, result)
+}
+
+func repriceRequest(e echo.Context) (RepriceRequest, error) {
+	id, err := validation.ParseStringToInt64(e.Param("id"))
+	if err != nil {
+		return RepriceRequest{}, err
+	}
+
+	// Rotas de empresa só são encontradas pelo token da organização (simpplify), que traz tenant e acesso
+	payloadSimp := get_token.GetPayloadToken(e)
+	request := RepriceRequest{
+		Source:   e.Param("source"),
+		ID:       id,
+		UserID:   get_token.GetUserPayloadToken(e).ID,
+		TenantID: payloadSimp.TenantID,
+		AccessID: payloadSimp.AccessID,
+	}
+	if request.Source == RepriceSourceEnterprise && request.AccessID == 0 {
+		return RepriceRequest{}, errors.New("rotas de empresa exigem o token da organização (rota simpplify)")
+	}
+	return request, validation.Validate(request)
+}
+
+// GetEmissionsReportHandler godoc
+// @Summary Relatório ESG de emissões das rotas.
+// @Description Soma por mês o combustível e as emissões (CO2e) das rotas calculadas pela organização ou pelo usuário.
+// @Description O cálculo é determinístico: litros pela divisão cidade/rodovia e consumo informados, ajustados pela
+// @Description ocupação da carga, com fatores de emissão publicados (GHG Protocol / DESNZ) para diesel B15 e gasolina C.
+// @Description
+// @Description - organization_id: 10 (Opcional; precisa ser a organização do token. Sem ele o relatório é das rotas do usuário
+// @Description   ou, na rota simpplify, da organização do token)
+// @Description - start_month / end_month: "2025-01" (Período inclusivo; padrão: últimos 12 meses)
+// @Description - format: json ou csv
+// @Tags Routes
+// @Produce json
+// @Produce text/csv
+// @Param organization_id query int false "ID da organização"
+// @Param start_month query string false "Mês inicial (AAAA-MM)"
+// @Param end_month query string false "Mês final (AAAA-MM)"
+// @Param format query string false "json ou csv"
+// @Success 200 {object} EmissionsReport "Emissões por mês"
+// @Failure 400 {string} string "Requisição Inválida"
+// @Failure 403 {string} string "Organização não pertence ao usuário"
+// @Failure 500 {string} string "Erro Interno do Servidor"
+// @Router /route/emissions/report [get]
+// @Router /route/emissions/report-simpplify [get]
+// @Security ApiKeyAuth
+func (h *Handler) GetEmissionsReportHandler(e echo.Context) error {
+	// Só o token da organização (simpplify) carrega a organização do usuário; o token de usuário não dá acesso a organizações
+	payloadSimp := get_token.GetPayloadToken(e)
+	organizationID := payloadSimp.UserOrgId
+	if orgStr := e.QueryParam("organization_id"); orgStr != "" {
+		id, err := validation.ParseStringToInt64(orgStr)
+		if err != nil {
+			return e.JSON(http.StatusBadRequest, err.Error())
+		}
+		if id != payloadSimp.UserOrgId {
+			return e.JSON(http.StatusForbidden, "organização não pertence ao usuário")
+		}
+		organizationID = id
+	}
+
+	request := EmissionsReportRequest{
+		OrganizationID: organizationID,
+		StartMonth:     e.QueryParam("start_month"),
+		EndMonth:       e.QueryParam("end_month"),
+		Format:         strings.ToLower(e.QueryParam("format")),
+		UserID:         get_token.GetUserPayloadToken(e).ID,
+	}
+	if request.Format == "" {
+		request.Format = EmissionsFormatJSON
+	}
+	if request.OrganizationID == 0 && request.UserID == 0 {
+		return e.JSON(http.StatusBadRequest, "organização não informada")
+	}
+
+	err := validation.Validate(request)
+	if err != nil {
+		return e.JSON(http.StatusBadRequest, err.Error())
+	}
+
+	if request.Format == EmissionsFormatCSV {
+		file, err := h.InterfaceService.ExportEmissionsReport(e.Request().Context(), request)
+		if err != nil {
+			return e.JSON(http.StatusInternalServerError, err.Error())
+		}
+
+		e.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", file.FileName))
+		return e.Blob(http.StatusOK, file.ContentType, file.Content)
+	}
+
+	result, err := h.InterfaceService.GetEmissionsReport(e.Request().Context(), request)
+	if err != nil {
+		return e.JSON(http.StatusInternalServerError, err.Error())
+	}
+
+	return e.JSON(http.StatusOK, result)
+}
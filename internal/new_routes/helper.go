@@ -81,121 +81,27 @@ func capitalize(s string) string {
 	return string(unicode.ToUpper(r)) + s[size:]
 }
 
-func translateInstruction(step OSRMStep) string {
+func translateInstruction(step OSRMStep, locale string) string {
 	typ := strings.ToLower(step.Maneuver.Type)
 	modifier := strings.ToLower(step.Maneuver.Modifier)
 	street := strings.TrimSpace(step.Name)
 
-	switch typ {
-	case "depart":
+	key := instructionKey(typ, modifier)
+	if key == "" {
 		if street != "" {
-			return fmt.Sprintf("Inicie sua viagem na %s", street)
-		}
-		return "Inicie sua viagem"
-	case "turn":
-		switch modifier {
-		case "left":
-			if street != "" {
-				return fmt.Sprintf("Vire à esquerda na %s", street)
-			}
-			return "Vire à esquerda"
-		case "right":
-			if street != "" {
-				return fmt.Sprintf("Vire à direita na %s", street)
-			}
-			return "Vire à direita"
-		case "sharp left":
-			if street != "" {
-				return fmt.Sprintf("Vire fortemente à esquerda na %s", street)
-			}
-			return "Vire fortemente à esquerda"
-		case "sharp right":
-			if street != "" {
-				return fmt.Sprintf("Vire fortemente à direita na %s", street)
-			}
-			return "Vire fortemente à direita"
-		case "slight left":
-			if street != "" {
-				return fmt.Sprintf("Vire suavemente à esquerda na %s", street)
-			}
-			return "Vire suavemente à esquerda"
-		case "slight right":
-			if street != "" {
-				return fmt.Sprintf("Vire suavemente à direita na %s", street)
-			}
-			return "Vire suavemente à direita"
-		case "Rotary":
-			if street != "" {
-				return fmt.Sprintf("Rotatória %s", street)
-			}
-			return "Rotatória"
-		case "Exit":
-			if street != "" {
-				return fmt.Sprintf("Exit %s", street)
-			}
-			return "Exit"
-		default:
-			if street != "" {
-				return fmt.Sprintf("Vire na direção de %s", street)
-			}
-			return "Vire"
-		}
-	case "new name":
-		if street != "" {
-			return fmt.Sprintf("Continue na %s", street)
-		}
-		return "Continue em frente"
-	case "roundabout":
-		if street != "" {
-			return fmt.Sprintf("Na rotatória, pegue a primeira saída para a %s", street)
-		}
-		return "Na rotatória, pegue a primeira saída"
-	case "exit roundabout":
-		if street != "" {
-			return fmt.Sprintf("Saia da rotatória em direção à %s", street)
-		}
-		return "Saia da rotatória"
-	case "end of road":
-		if street != "" {
-			return fmt.Sprintf("No final da estrada, siga para a %s", street)
-		}
-		return "No final da estrada, siga em frente"
-	case "fork":
-		if street != "" {
-			return fmt.Sprintf("Na bifurcação, siga em direção à %s", street)
-		}
-		return "Na bifurcação, siga em frente"
-	case "on ramp":
-		if street != "" {
-			return fmt.Sprintf("Pegue a rampa de entrada para a %s", street)
-		}
-		return "Pegue a rampa de entrada"
-	case "off ramp":
-		if street != "" {
-			return fmt.Sprintf("Pegue a rampa de saída para a %s", street)
-		}
-		return "Pegue a rampa de saída"
-	case "merge":
-		if street != "" {
-			return fmt.Sprintf("Faça a fusão para a %s", street)
-		}
-		return "Faça a fusão com a via"
-	case "arrive":
-		if street != "" {
-			return fmt.Sprintf("Chegue à %s", street)
-		}
-		return "Você chegou ao destino"
-	case "continue":
-		if street != "" {
-			return fmt.Sprintf("Continue na %s", street)
-		}
-		return "Continue em frente"
-	default:
-		if street != "" {
-			return fmt.Sprintf("%s na %s", capitalize(typ), street)
+			return fmt.Sprintf(message(locale, "other_on"), capitalize(typ), street)
 		}
 		return capitalize(typ)
 	}
+	if street != "" {
+		return fmt.Sprintf(message(locale, key+"_on"), street)
+	}
+	return message(locale, key)
+}
+
+// instructionImage escolhe o ícone pelo texto em pt-BR, que é o vocabulário do selectImage
+func instructionImage(step OSRMStep) string {
+	return selectImage(translateInstruction(step, LocalePTBR))
 }
 
 func decodePolyline(encoded string) ([]LatLng, error) {
@@ -244,7 +150,38 @@ func decodePolyline(encoded string) ([]LatLng, error) {
 	return points, nil
 }
 
+// encodePolyline faz o caminho inverso de decodePolyline (precisão 1e5)
+func encodePolyline(points []LatLng) string {
+	var sb strings.Builder
+	prevLat, prevLng := 0, 0
+	writeValue := func(v int) {
+		u := uint(v << 1)
+		if v < 0 {
+			u = ^u
+		}
+		for u >= 0x20 {
+			sb.WriteByte(byte((0x20 | (u & 0x1f)) + 63))
+			u >>= 5
+		}
+		sb.WriteByte(byte(u + 63))
+	}
+	for _, p := range points {
+		lat := int(math.Round(p.Lat * 1e5))
+		lng := int(math.Round(p.Lng * 1e5))
+		writeValue(lat - prevLat)
+		writeValue(lng - prevLng)
+		prevLat, prevLng = lat, lng
+	}
+	return sb.String()
+}
+
 func distancePointToSegment(p, v, w LatLng) float64 {
+	dist, _ := projectPointToSegment(p, v, w)
+	return dist
+}
+
+// projectPointToSegment devolve a distância (m) do ponto ao segmento e a fração t (0..1) da projeção sobre ele
+func projectPointToSegment(p, v, w LatLng) (float64, float64) {
 	const latFactor = 111320.0
 	lngFactor := 111320.0 * math.Cos(v.Lat*math.Pi/180)
 
@@ -256,7 +193,7 @@ func distancePointToSegment(p, v, w LatLng) float64 {
 
 	segLenSq := dx*dx + dy*dy
 	if segLenSq == 0 {
-		return math.Sqrt(dxp*dxp + dyp*dyp)
+		return math.Sqrt(dxp*dxp + dyp*dyp), 0
 	}
 
 	dot := dxp*dx + dyp*dy
@@ -274,38 +211,7 @@ func distancePointToSegment(p, v, w LatLng) float64 {
 	distX := dxp - projX
 	distY := dyp - projY
 
-	return math.Sqrt(distX*distX + distY*distY)
-}
-
-func PriceTollsFromVehicle(vehicle string, price, axes float64) (float64, error) {
-	var calculation float64
-	switch os := vehicle; os {
-	case "motorcycle":
-		calculation = price / 2
-		return calculation, nil
-	case "auto":
-		if int(axes)%2 != 0 {
-			price = price / 2
-		}
-		calculation = price
-		return calculation, nil
-	case "bus":
-		if int(axes)%2 != 0 {
-			price = price / 2
-		}
-		calculation = price * axes
-		return calculation, nil
-	case "truck":
-		if int(axes)%2 != 0 {
-			price = price / 2
-		}
-		calculation = price * axes
-		return calculation, nil
-	default:
-		// Valor incorreto
-	}
-
-	return calculation, nil
+	return math.Sqrt(distX*distX + distY*distY), t
 }
 
 func haversineDistanceTolls(lat1, lng1, lat2, lng2 float64) float64 {
@@ -335,6 +241,7 @@ func convertGasStation(row db.GetGasStationRow) GasStation {
 	latitude, _ := validation.ParseStringToFloat(row.Latitude)
 	longitude, _ := validation.ParseStringToFloat(row.Longitude)
 	return GasStation{
+		ID:      row.ID,
 		Name:    row.Name,
 		Address: row.AddressName,
 		Location: Location{
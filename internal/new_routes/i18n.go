@@ -0,0 +1,250 @@
+package new_routes
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	LocalePTBR = "pt-BR"
+	LocaleES   = "es"
+	LocaleEN   = "en"
+)
+
+// instructionCatalog mapeia a chave da manobra para o texto; a variante "_on" recebe o nome da via
+type instructionCatalog map[string]string
+
+var instructionCatalogs = map[string]instructionCatalog{
+	LocalePTBR: {
+		"depart":               "Inicie sua viagem",
+		"depart_on":            "Inicie sua viagem na %s",
+		"turn_left":            "Vire à esquerda",
+		"turn_left_on":         "Vire à esquerda na %s",
+		"turn_right":           "Vire à direita",
+		"turn_right_on":        "Vire à direita na %s",
+		"turn_sharp_left":      "Vire fortemente à esquerda",
+		"turn_sharp_left_on":   "Vire fortemente à esquerda na %s",
+		"turn_sharp_right":     "Vire fortemente à direita",
+		"turn_sharp_right_on":  "Vire fortemente à direita na %s",
+		"turn_slight_left":     "Vire suavemente à esquerda",
+		"turn_slight_left_on":  "Vire suavemente à esquerda na %s",
+		"turn_slight_right":    "Vire suavemente à direita",
+		"turn_slight_right_on": "Vire suavemente à direita na %s",
+		"turn":                 "Vire",
+		"turn_on":              "Vire na direção de %s",
+		"continue":             "Continue em frente",
+		"continue_on":          "Continue na %s",
+		"roundabout":           "Na rotatória, pegue a primeira saída",
+		"roundabout_on":        "Na rotatória, pegue a primeira saída para a %s",
+		"exit_roundabout":      "Saia da rotatória",
+		"exit_roundabout_on":   "Saia da rotatória em direção à %s",
+		"end_of_road":          "No final da estrada, siga em frente",
+		"end_of_road_on":       "No final da estrada, siga para a %s",
+		"fork":                 "Na bifurcação, siga em frente",
+		"fork_on":              "Na bifurcação, siga em direção à %s",
+		"on_ramp":              "Pegue a rampa de entrada",
+		"on_ramp_on":           "Pegue a rampa de entrada para a %s",
+		"off_ramp":             "Pegue a rampa de saída",
+		"off_ramp_on":          "Pegue a rampa de saída para a %s",
+		"merge":                "Faça a fusão com a via",
+		"merge_on":             "Faça a fusão para a %s",
+		"arrive":               "Você chegou ao destino",
+		"arrive_on":            "Chegue à %s",
+		"other_on":             "%s na %s",
+		"estimated_depart":     "Inicie sua viagem",
+		"estimated_follow":     "Siga em direção ao destino - rota estimada",
+		"estimated_arrive":     "Chegue ao seu destino",
+	},
+	LocaleES: {
+		"depart":               "Inicie su viaje",
+		"depart_on":            "Inicie su viaje en %s",
+		"turn_left":            "Gire a la izquierda",
+		"turn_left_on":         "Gire a la izquierda en %s",
+		"turn_right":           "Gire a la derecha",
+		"turn_right_on":        "Gire a la derecha en %s",
+		"turn_sharp_left":      "Gire bruscamente a la izquierda",
+		"turn_sharp_left_on":   "Gire bruscamente a la izquierda en %s",
+		"turn_sharp_right":     "Gire bruscamente a la derecha",
+		"turn_sharp_right_on":  "Gire bruscamente a la derecha en %s",
+		"turn_slight_left":     "Gire levemente a la izquierda",
+		"turn_slight_left_on":  "Gire levemente a la izquierda en %s",
+		"turn_slight_right":    "Gire levemente a la derecha",
+		"turn_slight_right_on": "Gire levemente a la derecha en %s",
+		"turn":                 "Gire",
+		"turn_on":              "Gire hacia %s",
+		"continue":             "Continúe recto",
+		"continue_on":          "Continúe por %s",
+		"roundabout":           "En la rotonda, tome la primera salida",
+		"roundabout_on":        "En la rotonda, tome la primera salida hacia %s",
+		"exit_roundabout":      "Salga de la rotonda",
+		"exit_roundabout_on":   "Salga de la rotonda hacia %s",
+		"end_of_road":          "Al final de la vía, siga recto",
+		"end_of_road_on":       "Al final de la vía, siga hacia %s",
+		"fork":                 "En la bifurcación, siga recto",
+		"fork_on":              "En la bifurcación, siga hacia %s",
+		"on_ramp":              "Tome el acceso",
+		"on_ramp_on":           "Tome el acceso hacia %s",
+		"off_ramp":             "Tome la salida",
+		"off_ramp_on":          "Tome la salida hacia %s",
+		"merge":                "Incorpórese a la vía",
+		"merge_on":             "Incorpórese a %s",
+		"arrive":               "Ha llegado a su destino",
+		"arrive_on":            "Llegue a %s",
+		"other_on":             "%s en %s",
+		"estimated_depart":     "Inicie su viaje",
+		"estimated_follow":     "Siga en dirección al destino - ruta estimada",
+		"estimated_arrive":     "Llegue a su destino",
+	},
+	LocaleEN: {
+		"depart":               "Start your trip",
+		"depart_on":            "Start your trip on %s",
+		"turn_left":            "Turn left",
+		"turn_left_on":         "Turn left onto %s",
+		"turn_right":           "Turn right",
+		"turn_right_on":        "Turn right onto %s",
+		"turn_sharp_left":      "Make a sharp left",
+		"turn_sharp_left_on":   "Make a sharp left onto %s",
+		"turn_sharp_right":     "Make a sharp right",
+		"turn_sharp_right_on":  "Make a sharp right onto %s",
+		"turn_slight_left":     "Bear left",
+		"turn_slight_left_on":  "Bear left onto %s",
+		"turn_slight_right":    "Bear right",
+		"turn_slight_right_on": "Bear right onto %s",
+		"turn":                 "Turn",
+		"turn_on":              "Turn towards %s",
+		"continue":             "Continue straight",
+		"continue_on":          "Continue on %s",
+		"roundabout":           "At the roundabout, take the first exit",
+		"roundabout_on":        "At the roundabout, take the first exit onto %s",
+		"exit_roundabout":      "Exit the roundabout",
+		"exit_roundabout_on":   "Exit the roundabout onto %s",
+		"end_of_road":          "At the end of the road, continue straight",
+		"end_of_road_on":       "At the end of the road, continue onto %s",
+		"fork":                 "At the fork, continue straight",
+		"fork_on":              "At the fork, keep towards %s",
+		"on_ramp":              "Take the ramp",
+		"on_ramp_on":           "Take the ramp onto %s",
+		"off_ramp":             "Take the exit",
+		"off_ramp_on":          "Take the exit towards %s",
+		"merge":                "Merge onto the road",
+		"merge_on":             "Merge onto %s",
+		"arrive":               "You have arrived at your destination",
+		"arrive_on":            "Arrive at %s",
+		"other_on":             "%s on %s",
+		"estimated_depart":     "Start your trip",
+		"estimated_follow":     "Head towards the destination - estimated route",
+		"estimated_arrive":     "Arrive at your destination",
+	},
+}
+
+// normalizeLocale aceita o valor do campo locale ou um cabeçalho Accept-Language completo
+// ("es-AR,es;q=0.9,en;q=0.8") e devolve o primeiro idioma suportado, com pt-BR como padrão
+func normalizeLocale(value string) string {
+	best, bestWeight := LocalePTBR, -1.0
+	for _, part := range strings.Split(value, ",") {
+		fields := strings.Split(strings.TrimSpace(part), ";")
+		locale := supportedLocale(fields[0])
+		if locale == "" {
+			continue
+		}
+		weight := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if q, ok := strings.CutPrefix(param, "q="); ok {
+				if parsed, err := strconv.ParseFloat(q, 64); err == nil {
+					weight = parsed
+				}
+			}
+		}
+		if weight > bestWeight {
+			best, bestWeight = locale, weight
+		}
+	}
+	return best
+}
+
+func supportedLocale(tag string) string {
+	language, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(tag)), "-")
+	language, _, _ = strings.Cut(language, "_")
+	switch language {
+	case "pt":
+		return LocalePTBR
+	case "es":
+		return LocaleES
+	case "en":
+		return LocaleEN
+	}
+	return ""
+}
+
+// requestLocale prioriza o campo locale do body; sem ele usa o cabeçalho Accept-Language
+func requestLocale(e echo.Context, locale string) string {
+	if strings.TrimSpace(locale) != "" {
+		return normalizeLocale(locale)
+	}
+	return normalizeLocale(e.Request().Header.Get("Accept-Language"))
+}
+
+// localeCacheKey é vazio para pt-BR para manter as chaves de cache já gravadas
+func localeCacheKey(locale string) string {
+	locale = normalizeLocale(locale)
+	if locale == LocalePTBR {
+		return ""
+	}
+	return ":locale:" + locale
+}
+
+func catalogFor(locale string) instructionCatalog {
+	return instructionCatalogs[normalizeLocale(locale)]
+}
+
+// message devolve o texto da chave no idioma, com o pt-BR como reserva
+func message(locale, key string) string {
+	if text, ok := catalogFor(locale)[key]; ok {
+		return text
+	}
+	return instructionCatalogs[LocalePTBR][key]
+}
+
+// instructionKey traduz o tipo e o modificador da manobra do OSRM para a chave do catálogo
+func instructionKey(typ, modifier string) string {
+	switch typ {
+	case "depart", "roundabout", "fork", "merge", "arrive":
+		return typ
+	case "turn":
+		switch modifier {
+		case "left", "right", "sharp left", "sharp right", "slight left", "slight right":
+			return "turn_" + strings.ReplaceAll(modifier, " ", "_")
+		}
+		return "turn"
+	case "new name", "continue":
+		return "continue"
+	case "exit roundabout", "end of road", "on ramp", "off ramp":
+		return strings.ReplaceAll(typ, " ", "_")
+	}
+	return ""
+}
+
+// formatInstructionDistance formata a distância do passo: metros abaixo de 1 km, quilômetros com uma casa
+// decimal acima disso; pt-BR e es usam vírgula decimal e en usa ponto
+func formatInstructionDistance(meters float64, locale string) string {
+	if meters <= 0 {
+		return ""
+	}
+	if meters < 1000 {
+		rounded := math.Round(meters/10) * 10
+		if rounded < 10 {
+			rounded = math.Round(meters)
+		}
+		return fmt.Sprintf("%.0f m", rounded)
+	}
+	text := strconv.FormatFloat(math.Round(meters/100)/10, 'f', 1, 64)
+	if normalizeLocale(locale) != LocaleEN {
+		text = strings.Replace(text, ".", ",", 1)
+	}
+	return text + " km"
+}
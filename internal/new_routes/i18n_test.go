@@ -0,0 +1,151 @@
+package new_routes
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestNormalizeLocale(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  string
+	}{
+		{name: "vazio usa pt-BR", value: "", want: LocalePTBR},
+		{name: "pt-BR", value: "pt-BR", want: LocalePTBR},
+		{name: "português de Portugal", value: "pt-PT", want: LocalePTBR},
+		{name: "espanhol com região", value: "es-AR", want: LocaleES},
+		{name: "sublinhado e caixa", value: " EN_us ", want: LocaleEN},
+		{name: "não suportado usa pt-BR", value: "fr-FR", want: LocalePTBR},
+		{name: "Accept-Language pela ordem", value: "es-AR,es;q=0.9,en;q=0.8", want: LocaleES},
+		{name: "Accept-Language pelo peso", value: "en;q=0.5, es;q=0.9", want: LocaleES},
+		{name: "pula idiomas não suportados", value: "fr-FR,de;q=0.9,en;q=0.8", want: LocaleEN},
+		{name: "peso inválido conta 1", value: "es;q=0.7, en;q=abc", want: LocaleEN},
+		{name: "empate fica com o primeiro", value: "en, es", want: LocaleEN},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := normalizeLocale(tt.value); got != tt.want {
+				t.Errorf("normalizeLocale(%q) = %q, want %q", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRequestLocale(t *testing.T) {
+	tests := []struct {
+		name           string
+		locale         string
+		acceptLanguage string
+		want           string
+	}{
+		{name: "campo do body vale mais", locale: "en", acceptLanguage: "es", want: LocaleEN},
+		{name: "sem campo usa o cabeçalho", acceptLanguage: "es-MX,es;q=0.9", want: LocaleES},
+		{name: "campo em branco usa o cabeçalho", locale: "  ", acceptLanguage: "en-US", want: LocaleEN},
+		{name: "sem nenhum usa pt-BR", want: LocalePTBR},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", nil)
+			if tt.acceptLanguage != "" {
+				req.Header.Set("Accept-Language", tt.acceptLanguage)
+			}
+			e := echo.New().NewContext(req, httptest.NewRecorder())
+			if got := requestLocale(e, tt.locale); got != tt.want {
+				t.Errorf("requestLocale(%q) = %q, want %q", tt.locale, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInstructionCatalogsComplete(t *testing.T) {
+	base := instructionCatalogs[LocalePTBR]
+	for _, locale := range []string{LocaleES, LocaleEN} {
+		catalog := instructionCatalogs[locale]
+		if len(catalog) != len(base) {
+			t.Errorf("%s tem %d chaves, pt-BR tem %d", locale, len(catalog), len(base))
+		}
+		for key, text := range base {
+			translated, ok := catalog[key]
+			if !ok {
+				t.Errorf("%s sem a chave %q", locale, key)
+				continue
+			}
+			// As variantes "_on" recebem o nome da via: o número de %s precisa bater com o pt-BR
+			if got, want := strings.Count(translated, "%s"), strings.Count(text, "%s"); got != want {
+				t.Errorf("%s[%q] = %q com %d %%s, want %d", locale, key, translated, got, want)
+			}
+		}
+	}
+}
+
+func TestTranslateInstruction(t *testing.T) {
+	step := func(typ, modifier, name string) OSRMStep {
+		var s OSRMStep
+		s.Maneuver.Type, s.Maneuver.Modifier, s.Name = typ, modifier, name
+		return s
+	}
+
+	tests := []struct {
+		step OSRMStep
+		want map[string]string
+	}{
+		{step: step("depart", "", ""), want: map[string]string{
+			LocalePTBR: "Inicie sua viagem", LocaleES: "Inicie su viaje", LocaleEN: "Start your trip"}},
+		{step: step("turn", "left", "Rua Augusta"), want: map[string]string{
+			LocalePTBR: "Vire à esquerda na Rua Augusta", LocaleES: "Gire a la izquierda en Rua Augusta", LocaleEN: "Turn left onto Rua Augusta"}},
+		{step: step("Turn", "Slight Right", ""), want: map[string]string{
+			LocalePTBR: "Vire suavemente à direita", LocaleES: "Gire levemente a la derecha", LocaleEN: "Bear right"}},
+		{step: step("turn", "uturn", "Av. Paulista"), want: map[string]string{
+			LocalePTBR: "Vire na direção de Av. Paulista", LocaleES: "Gire hacia Av. Paulista", LocaleEN: "Turn towards Av. Paulista"}},
+		{step: step("new name", "straight", " Rodovia Anhanguera "), want: map[string]string{
+			LocalePTBR: "Continue na Rodovia Anhanguera", LocaleES: "Continúe por Rodovia Anhanguera", LocaleEN: "Continue on Rodovia Anhanguera"}},
+		// Manobras fora do catálogo usam o próprio tipo do OSRM
+		{step: step("notification", "", "BR-116"), want: map[string]string{
+			LocalePTBR: "Notification na BR-116", LocaleES: "Notification en BR-116", LocaleEN: "Notification on BR-116"}},
+		{step: step("arrive", "", ""), want: map[string]string{
+			LocalePTBR: "Você chegou ao destino", LocaleES: "Ha llegado a su destino", LocaleEN: "You have arrived at your destination"}},
+	}
+
+	for _, tt := range tests {
+		for _, locale := range []string{LocalePTBR, LocaleES, LocaleEN} {
+			name := fmt.Sprintf("%s/%s/%s", locale, tt.step.Maneuver.Type, tt.step.Maneuver.Modifier)
+			t.Run(name, func(t *testing.T) {
+				if got := translateInstruction(tt.step, locale); got != tt.want[locale] {
+					t.Errorf("translateInstruction() = %q, want %q", got, tt.want[locale])
+				}
+			})
+		}
+	}
+
+	// Idioma não suportado cai no pt-BR
+	if got := translateInstruction(step("turn", "right", ""), "fr-FR"); got != "Vire à direita" {
+		t.Errorf("translateInstruction(fr-FR) = %q, want o texto em pt-BR", got)
+	}
+}
+
+func TestFormatInstructionDistance(t *testing.T) {
+	for _, c := range []struct {
+		meters float64
+		locale string
+		want   string
+	}{
+		{0, LocalePTBR, ""},
+		{4.4, LocalePTBR, "4 m"},
+		{347, LocaleEN, "350 m"},
+		{1250, LocalePTBR, "1,3 km"},
+		{1250, LocaleES, "1,3 km"},
+		{1250, LocaleEN, "1.3 km"},
+	} {
+		if got := formatInstructionDistance(c.meters, c.locale); got != c.want {
+			t.Errorf("formatInstructionDistance(%v, %s) = %q, want %q", c.meters, c.locale, got, c.want)
+		}
+	}
+}
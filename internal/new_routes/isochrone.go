@@ -0,0 +1,288 @@
+package new_routes
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	cache "geolocation/pkg"
+	"log"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+const (
+	IsochroneBudgetTime     = "time"
+	IsochroneBudgetDistance = "distance"
+
+	defaultIsochroneDirections = 24
+	maxIsochroneDirections     = 72
+	isochroneRings             = 6
+	// isochroneMaxSpeedKmh é a velocidade usada para estimar o raio máximo a amostrar nos orçamentos de tempo
+	isochroneMaxSpeedKmh = 100.0
+	// tableChunkSize respeita o max-table-size padrão do OSRM (100 coordenadas por chamada)
+	tableChunkSize = 90
+
+	// Limites de velocidade (km/h) aplicados quando o motor não tem o perfil do veículo e calcula como carro:
+	// 90 km/h para caminhão e ônibus em rodovia (CTB, art. 61) e 80 km/h para combinações de 7 eixos ou mais
+	busMaxSpeedKmh        = 90.0
+	truckMaxSpeedKmh      = 90.0
+	heavyTruckMaxSpeedKmh = 80.0
+	heavyTruckMinAxles    = 7
+)
+
+type IsochroneRequest struct {
+	Origin          string      `json:"origin"`
+	OriginCEP       string      `json:"origin_cep"`
+	Coordinate      *Coordinate `json:"coordinate"`
+	TimeBudgets     []float64   `json:"time_budgets"`
+	DistanceBudgets []float64   `json:"distance_budgets"`
+	Type            string      `json:"type" validate:"omitempty,oneof=Truck Bus Auto Motorcycle truck bus auto motorcycle"`
+	Axles           int64       `json:"axles"`
+	Directions      int         `json:"directions"`
+}
+
+type IsochroneResponse struct {
+	Origin     AddressInfo              `json:"origin"`
+	Isochrones GeoJSONFeatureCollection `json:"isochrones"`
+	// Profile é o perfil do motor usado nas áreas. ProfileFallback indica que a instância não tem o perfil do
+	// veículo: as durações vieram do perfil padrão, limitadas à velocidade máxima do veículo e dos eixos.
+	Profile         string `json:"profile"`
+	ProfileFallback bool   `json:"profile_fallback,omitempty"`
+}
+
+type isochroneBudget struct {
+	kind  string
+	value float64
+}
+
+// CalculateIsochrones devolve as áreas alcançáveis a partir da origem para cada orçamento de tempo
+// (minutos) ou distância (km). As áreas são aproximadas por raios: para cada direção o motor calcula
+// o custo até pontos em anéis concêntricos e o limite é interpolado entre o último anel alcançável e o próximo.
+func (s *Service) CalculateIsochrones(ctx context.Context, data IsochroneRequest) (IsochroneResponse, error) {
+	var budgets []isochroneBudget
+	for _, b := range data.TimeBudgets {
+		if b > 0 {
+			budgets = append(budgets, isochroneBudget{kind: IsochroneBudgetTime, value: b})
+		}
+	}
+	for _, b := range data.DistanceBudgets {
+		if b > 0 {
+			budgets = append(budgets, isochroneBudget{kind: IsochroneBudgetDistance, value: b})
+		}
+	}
+	if len(budgets) == 0 {
+		return IsochroneResponse{}, fmt.Errorf("informe ao menos um orçamento de tempo ou distância")
+	}
+
+	origin, address, err := s.resolvePoint(ctx, data.Origin, data.OriginCEP, data.Coordinate)
+	if err != nil {
+		return IsochroneResponse{}, err
+	}
+
+	directions := data.Directions
+	if directions <= 0 {
+		directions = defaultIsochroneDirections
+	}
+	if directions > maxIsochroneDirections {
+		directions = maxIsochroneDirections
+	}
+
+	cacheKey := fmt.Sprintf("isochrone:%.5f,%.5f:type:%s:axles:%d:time:%v:dist:%v:dir:%d",
+		origin.Latitude, origin.Longitude,
+		strings.ToLower(data.Type), data.Axles,
+		data.TimeBudgets, data.DistanceBudgets, directions,
+	)
+	cached, err := cache.Rdb.Get(ctx, cacheKey).Result()
+	if err == nil {
+		var cachedOutput IsochroneResponse
+		if json.Unmarshal([]byte(cached), &cachedOutput) == nil {
+			return cachedOutput, nil
+		}
+	} else if !errors.Is(err, redis.Nil) {
+		log.Printf("Erro ao recuperar cache do Redis (isochrone): %v", err)
+	}
+
+	var maxRadius float64
+	for _, b := range budgets {
+		maxRadius = math.Max(maxRadius, b.radiusMeters())
+	}
+
+	// samples[d][r] é o ponto da direção d no anel r
+	samples := make([][]Location, directions)
+	var flat []Location
+	for d := 0; d < directions; d++ {
+		bearing := float64(d) * 360 / float64(directions)
+		samples[d] = make([]Location, isochroneRings)
+		for r := 0; r < isochroneRings; r++ {
+			lat, lng := s.calculateDestination(origin.Latitude, origin.Longitude, bearing, maxRadius*float64(r+1)/isochroneRings)
+			samples[d][r] = Location{Latitude: lat, Longitude: lng}
+			flat = append(flat, samples[d][r])
+		}
+	}
+
+	profile := vehicleProfile(data.Type)
+	distances, durations, usedProfile, err := s.oneToManyTable(ctx, origin, flat, profile)
+	if err != nil {
+		return IsochroneResponse{}, err
+	}
+	fallback := usedProfile != profile
+	if fallback {
+		limitVehicleSpeed(distances, durations, data.Type, data.Axles)
+	}
+
+	// Maiores primeiro para que as áreas menores fiquem por cima ao desenhar no mapa
+	sort.SliceStable(budgets, func(i, j int) bool {
+		return budgets[i].radiusMeters() > budgets[j].radiusMeters()
+	})
+
+	collection := newFeatureCollection()
+	for _, b := range budgets {
+		ring := make([]Location, 0, directions)
+		for d := 0; d < directions; d++ {
+			bearing := float64(d) * 360 / float64(directions)
+			prevRadius, prevCost := 0.0, 0.0
+			reach := 0.0
+			for r := 0; r < isochroneRings; r++ {
+				idx := d*isochroneRings + r
+				if distances[idx] < 0 || durations[idx] < 0 {
+					// Ponto sem rota (mar, área sem vias): segue para o próximo anel
+					continue
+				}
+				cost := distances[idx] / 1000
+				if b.kind == IsochroneBudgetTime {
+					cost = durations[idx] / 60
+				}
+				radius := maxRadius * float64(r+1) / isochroneRings
+				if cost <= b.value {
+					reach, prevRadius, prevCost = radius, radius, cost
+					continue
+				}
+				// Interpola entre o último anel alcançável e o primeiro que estoura o orçamento
+				if cost > prevCost {
+					reach = math.Max(reach, prevRadius+(radius-prevRadius)*(b.value-prevCost)/(cost-prevCost))
+				}
+				break
+			}
+			lat, lng := s.calculateDestination(origin.Latitude, origin.Longitude, bearing, reach)
+			ring = append(ring, Location{Latitude: lat, Longitude: lng})
+		}
+
+		unit := "min"
+		if b.kind == IsochroneBudgetDistance {
+			unit = "km"
+		}
+		collection.Features = append(collection.Features, polygonFeature(ring, map[string]interface{}{
+			"budget":      b.value,
+			"budget_type": b.kind,
+			"unit":        unit,
+		}))
+	}
+
+	result := IsochroneResponse{
+		Origin:          AddressInfo{Location: origin, Address: address},
+		Isochrones:      collection,
+		Profile:         usedProfile,
+		ProfileFallback: fallback,
+	}
+	if payload, err := json.Marshal(result); err == nil {
+		if err := cache.Rdb.Set(ctx, cacheKey, payload, 30*24*time.Hour).Err(); err != nil {
+			log.Printf("Erro ao salvar cache do Redis (isochrone): %v", err)
+		}
+	}
+	return result, nil
+}
+
+func (b isochroneBudget) radiusMeters() float64 {
+	if b.kind == IsochroneBudgetTime {
+		return b.value / 60 * isochroneMaxSpeedKmh * 1000
+	}
+	return b.value * 1000
+}
+
+// vehicleMaxSpeedKmh é a velocidade máxima do veículo em rodovia; 0 para veículos leves, que já andam como o perfil padrão
+func vehicleMaxSpeedKmh(vehicleType string, axles int64) float64 {
+	switch strings.ToLower(strings.TrimSpace(vehicleType)) {
+	case "truck":
+		if axles >= heavyTruckMinAxles {
+			return heavyTruckMaxSpeedKmh
+		}
+		return truckMaxSpeedKmh
+	case "bus":
+		return busMaxSpeedKmh
+	default:
+		return 0
+	}
+}
+
+// limitVehicleSpeed alonga as durações cuja velocidade média passa da máxima do veículo
+func limitVehicleSpeed(distances, durations []float64, vehicleType string, axles int64) {
+	maxSpeed := vehicleMaxSpeedKmh(vehicleType, axles) / 3.6
+	if maxSpeed <= 0 {
+		return
+	}
+	for i := range durations {
+		if distances[i] < 0 || durations[i] < 0 {
+			continue
+		}
+		durations[i] = math.Max(durations[i], distances[i]/maxSpeed)
+	}
+}
+
+// oneToManyTable consulta a matriz origem → destinos em blocos, para respeitar o limite do motor.
+// As durações vêm do perfil informado; o perfil devolvido é o usado de fato, que pode ser o padrão se o motor não tiver o pedido.
+func (s *Service) oneToManyTable(ctx context.Context, origin Location, destinations []Location, profile string) ([]float64, []float64, string, error) {
+	used := profile
+	distances := make([]float64, 0, len(destinations))
+	durations := make([]float64, 0, len(destinations))
+	for start := 0; start < len(destinations); start += tableChunkSize {
+		end := start + tableChunkSize
+		if end > len(destinations) {
+			end = len(destinations)
+		}
+		tableCtx, cancel := context.WithTimeout(ctx, 60*time.Second)
+		table, err := s.Engine.Table(tableCtx, []Location{origin}, destinations[start:end], profile)
+		cancel()
+		if err != nil {
+			return nil, nil, "", fmt.Errorf("erro ao calcular matriz de distâncias: %w", err)
+		}
+		if table.Profile != "" && table.Profile != profile {
+			used = table.Profile
+		}
+		distances = append(distances, table.Distances[0]...)
+		durations = append(durations, table.Durations[0]...)
+	}
+	return distances, durations, used, nil
+}
+
+// resolvePoint localiza um ponto informado como coordenada, CEP ou endereço (nessa ordem de prioridade)
+func (s *Service) resolvePoint(ctx context.Context, address, cep string, coord *Coordinate) (Location, string, error) {
+	if coord != nil && coord.Lat != "" && coord.Lng != "" {
+		lat, errLat := strconv.ParseFloat(strings.TrimSpace(coord.Lat), 64)
+		lng, errLng := strconv.ParseFloat(strings.TrimSpace(coord.Lng), 64)
+		if errLat != nil || errLng != nil {
+			return Location{}, "", fmt.Errorf("coordenada inválida")
+		}
+		return Location{Latitude: lat, Longitude: lng}, fmt.Sprintf("%s,%s", coord.Lat, coord.Lng), nil
+	}
+	if cep != "" {
+		lat, lng, end, err := s.getCoordByCEP(ctx, cep)
+		if err != nil {
+			return Location{}, "", fmt.Errorf("erro ao buscar coordenadas do CEP %s: %w", cep, err)
+		}
+		return Location{Latitude: lat, Longitude: lng}, end, nil
+	}
+	if address != "" {
+		geo, err := s.getGeocodeAddress(ctx, address)
+		if err != nil {
+			return Location{}, "", fmt.Errorf("erro ao geocodificar endereço: %w", err)
+		}
+		return geo.Location, normalizeAddress(geo.FormattedAddress), nil
+	}
+	return Location{}, "", fmt.Errorf("informe endereço, CEP ou coordenada")
+}
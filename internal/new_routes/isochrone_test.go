@@ -0,0 +1,83 @@
+package new_routes
+
+import (
+	"context"
+	"math"
+	"testing"
+)
+
+// profileEngine responde a matriz em linha reta, como o FakeEngine, informando o perfil que a instância tem
+type profileEngine struct {
+	FakeEngine
+	profiles map[string]bool
+}
+
+func (e *profileEngine) Table(ctx context.Context, sources, destinations []Location, profile string) (RouteTable, error) {
+	table, err := e.FakeEngine.Table(ctx, sources, destinations, profile)
+	// Carro a 120 km/h; sem o perfil pedido a instância cai no padrão
+	for i := range table.Durations {
+		for j := range table.Durations[i] {
+			table.Durations[i][j] = table.Distances[i][j] / (120 / 3.6)
+		}
+	}
+	if e.profiles[profile] {
+		table.Profile = profile
+	}
+	return table, err
+}
+
+func TestOneToManyTableProfileFallback(t *testing.T) {
+	origin := Location{Latitude: -23.5, Longitude: -46.6}
+	// 100 destinos: a matriz vai ao motor em dois blocos
+	destinations := make([]Location, 100)
+	for i := range destinations {
+		destinations[i] = Location{Latitude: -23.5, Longitude: -46.6 + float64(i+1)*0.01}
+	}
+
+	tests := []struct {
+		name        string
+		profiles    map[string]bool
+		vehicle     string
+		axles       int64
+		wantProfile string
+		wantSpeed   float64 // km/h depois do limite do veículo
+	}{
+		{name: "instância com perfil de caminhão", profiles: map[string]bool{"truck": true}, vehicle: "Truck", axles: 5, wantProfile: "truck", wantSpeed: 120},
+		{name: "caminhão cai no perfil padrão", vehicle: "Truck", axles: 5, wantProfile: defaultProfile, wantSpeed: 90},
+		{name: "rodotrem cai no perfil padrão", vehicle: "truck", axles: 9, wantProfile: defaultProfile, wantSpeed: 80},
+		{name: "ônibus cai no perfil padrão", vehicle: "Bus", wantProfile: defaultProfile, wantSpeed: 90},
+		{name: "carro usa o perfil padrão", vehicle: "Auto", wantProfile: defaultProfile, wantSpeed: 120},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &Service{Engine: &profileEngine{profiles: tt.profiles}}
+			profile := vehicleProfile(tt.vehicle)
+			distances, durations, used, err := s.oneToManyTable(context.Background(), origin, destinations, profile)
+			if err != nil {
+				t.Fatalf("oneToManyTable() erro = %v", err)
+			}
+			if used != tt.wantProfile {
+				t.Fatalf("perfil usado = %q, want %q", used, tt.wantProfile)
+			}
+			if used != profile {
+				limitVehicleSpeed(distances, durations, tt.vehicle, tt.axles)
+			}
+			for _, i := range []int{0, 99} {
+				if speed := distances[i] / durations[i] * 3.6; math.Abs(speed-tt.wantSpeed) > 0.01 {
+					t.Errorf("destino %d a %.2f km/h, want %v", i, speed, tt.wantSpeed)
+				}
+			}
+		})
+	}
+}
+
+func TestLimitVehicleSpeedKeepsUnreachable(t *testing.T) {
+	distances := []float64{-1, 9000, 9000}
+	durations := []float64{-1, 300, 600}
+	limitVehicleSpeed(distances, durations, "truck", 3)
+	// 9 km a 108 km/h sobe para 360 s (90 km/h); a 54 km/h já está abaixo do limite
+	if durations[0] != -1 || durations[1] != 360 || durations[2] != 600 {
+		t.Errorf("durações = %v, want [-1 360 600]", durations)
+	}
+}
@@ -0,0 +1,343 @@
+package new_routes
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/json"
+	"errors"
+	"fmt"
+	db "geolocation/db/sqlc"
+	cache "geolocation/pkg"
+	"log"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+const (
+	// maxMatrixCells limita N×M, já que o pedágio de cada célula exige uma rota completa
+	maxMatrixCells = 625
+	// maxMatrixOrigins garante espaço para destinos em cada chamada de matriz ao motor
+	maxMatrixOrigins = 50
+	// matrixConcurrency é o número de rotas calculadas em paralelo para os pedágios
+	matrixConcurrency = 8
+	// tollRouteTolerance é a distância máxima (m) entre a praça e a rota, a mesma de findTollsOnRoute
+	tollRouteTolerance = 50.0
+)
+
+type MatrixRequest struct {
+	Origins         []MatrixPoint `json:"origins" validate:"required,min=1"`
+	Destinations    []MatrixPoint `json:"destinations" validate:"required,min=1"`
+	ConsumptionCity float64       `json:"consumptionCity"`
+	ConsumptionHwy  float64       `json:"consumptionHwy"`
+	Price           float64       `json:"price"`
+	Axles           int64         `json:"axles"`
+	Type            string        `json:"type" validate:"required,oneof=Truck Bus Auto Motorcycle truck bus auto motorcycle"`
+	VehicleInfo
+}
+
+// MatrixPoint aceita CEP, endereço ou coordenada; a coordenada tem prioridade
+type MatrixPoint struct {
+	CEP     string `json:"cep"`
+	Address string `json:"address"`
+	Lat     string `json:"lat"`
+	Lng     string `json:"lng"`
+}
+
+type MatrixResponse struct {
+	Origins      []AddressInfo  `json:"origins"`
+	Destinations []AddressInfo  `json:"destinations"`
+	Rows         [][]MatrixCell `json:"rows"`
+}
+
+type MatrixCell struct {
+	Reachable bool     `json:"reachable"`
+	Distance  Distance `json:"distance"`
+	Duration  Duration `json:"duration"`
+	TollCost  float64  `json:"toll_cost"`
+	// TollCostUnknown marca a célula cujo pedágio não pôde ser calculado; TollCost e TotalCost ficam sem o pedágio
+	TollCostUnknown bool    `json:"toll_cost_unknown,omitempty"`
+	FuelCost        float64 `json:"fuel_cost"`
+	TotalCost       float64 `json:"total_cost"`
+}
+
+// matrixCellCache é o que fica no Redis por par origem/destino; o combustível depende do preço e é recalculado
+type matrixCellCache struct {
+	Distance float64 `json:"distance"`
+	Duration float64 `json:"duration"`
+	TollCost float64 `json:"toll_cost"`
+	// TollCostUnknown só vale na resposta: células sem pedágio calculado não vão para o cache
+	TollCostUnknown bool `json:"-"`
+}
+
+// tollPrice é a praça com a tarifa já calculada para o veículo
+type tollPrice struct {
+	Position LatLng
+	Price    float64
+	Sentido  string
+	index    int
+}
+
+// tollPriceTable reúne as praças precificadas e a grade usada para casá-las com as rotas
+type tollPriceTable struct {
+	items     []tollPrice
+	positions []LatLng
+	grid      *gridIndex
+	snap      *poiSnapshot
+}
+
+// CalculateMatrix calcula distância, duração, pedágio e combustível para cada par origem × destino.
+// Distâncias e durações vêm da matriz do motor; o pedágio de cada célula vem da rota completa do par.
+func (s *Service) CalculateMatrix(ctx context.Context, data MatrixRequest) (MatrixResponse, error) {
+	if len(data.Origins)*len(data.Destinations) > maxMatrixCells {
+		return MatrixResponse{}, fmt.Errorf("a matriz aceita no máximo %d células", maxMatrixCells)
+	}
+	if len(data.Origins) > maxMatrixOrigins {
+		return MatrixResponse{}, fmt.Errorf("a matriz aceita no máximo %d origens", maxMatrixOrigins)
+	}
+
+	origins, originInfo, err := s.resolveMatrixPoints(ctx, data.Origins)
+	if err != nil {
+		return MatrixResponse{}, err
+	}
+	destinations, destinationInfo, err := s.resolveMatrixPoints(ctx, data.Destinations)
+	if err != nil {
+		return MatrixResponse{}, err
+	}
+
+	// Sem a versão das tarifas o cache não é consultado nem gravado, para não servir pedágio desatualizado
+	pricingVersion, err := s.matrixPricingVersion(ctx)
+	if err != nil {
+		log.Printf("Erro ao verificar versão das tarifas para a matriz: %v", err)
+	}
+
+	cells := make([][]*matrixCellCache, len(origins))
+	keys := make([][]string, len(origins))
+	missing := false
+	for i, o := range origins {
+		cells[i] = make([]*matrixCellCache, len(destinations))
+		keys[i] = make([]string, len(destinations))
+		for j, d := range destinations {
+			if pricingVersion == "" {
+				missing = true
+				continue
+			}
+			keys[i][j] = fmt.Sprintf("matrix_cell:%s:%.5f,%.5f:%.5f,%.5f:type:%s:axles:%d", pricingVersion,
+				o.Latitude, o.Longitude, d.Latitude, d.Longitude, strings.ToLower(data.Type), data.Axles) + data.VehicleInfo.cacheKey()
+			cached, err := cache.Rdb.Get(ctx, keys[i][j]).Result()
+			if err == nil {
+				var cell matrixCellCache
+				if json.Unmarshal([]byte(cached), &cell) == nil {
+					cells[i][j] = &cell
+					continue
+				}
+			} else if !errors.Is(err, redis.Nil) {
+				log.Printf("Erro ao recuperar cache do Redis (matrix): %v", err)
+			}
+			missing = true
+		}
+	}
+
+	if missing {
+		if err := s.fillMatrixCells(ctx, origins, destinations, cells, keys, data); err != nil {
+			return MatrixResponse{}, err
+		}
+	}
+
+	avgConsumption := (data.ConsumptionCity + data.ConsumptionHwy) / 2
+	rows := make([][]MatrixCell, len(origins))
+	for i := range origins {
+		rows[i] = make([]MatrixCell, len(destinations))
+		for j := range destinations {
+			c := cells[i][j]
+			if c == nil || c.Distance < 0 {
+				continue
+			}
+			distText, distVal := formatDistance(c.Distance)
+			durText, durVal := formatDuration(c.Duration)
+			var fuel float64
+			if avgConsumption > 0 {
+				fuel = math.Round((data.Price / avgConsumption) * (c.Distance / 1000))
+			}
+			rows[i][j] = MatrixCell{
+				Reachable:       true,
+				Distance:        Distance{Text: distText, Value: distVal},
+				Duration:        Duration{Text: durText, Value: durVal},
+				TollCost:        c.TollCost,
+				TollCostUnknown: c.TollCostUnknown,
+				FuelCost:        fuel,
+				TotalCost:       math.Round((c.TollCost+fuel)*100) / 100,
+			}
+		}
+	}
+
+	return MatrixResponse{
+		Origins:      originInfo,
+		Destinations: destinationInfo,
+		Rows:         rows,
+	}, nil
+}
+
+// matrixPricingVersion resume as tarifas e descontos vigentes hoje e o cadastro de praças em um hash curto.
+// Reajustes, novas vigências e mudanças de praça geram outra chave de cache para as células.
+func (s *Service) matrixPricingVersion(ctx context.Context) (string, error) {
+	snap, err := s.POIIndex.current(ctx)
+	if err != nil {
+		return "", err
+	}
+	version, err := s.InterfaceService.GetTollPricingVersion(ctx, time.Now())
+	if err != nil {
+		return "", fmt.Errorf("erro ao buscar versão das tarifas: %w", err)
+	}
+	return fmt.Sprintf("%x", md5.Sum([]byte(version+"|"+snap.fingerprint.Tolls)))[:12], nil
+}
+
+func (s *Service) resolveMatrixPoints(ctx context.Context, points []MatrixPoint) ([]Location, []AddressInfo, error) {
+	locs := make([]Location, len(points))
+	info := make([]AddressInfo, len(points))
+	for i, p := range points {
+		loc, address, err := s.resolvePoint(ctx, p.Address, p.CEP, &Coordinate{Lat: p.Lat, Lng: p.Lng})
+		if err != nil {
+			return nil, nil, fmt.Errorf("ponto %d: %w", i, err)
+		}
+		locs[i] = loc
+		info[i] = AddressInfo{Location: loc, Address: address}
+	}
+	return locs, info, nil
+}
+
+// fillMatrixCells completa as células fora do cache: uma chamada de matriz no motor (em blocos) e uma rota por par para os pedágios
+func (s *Service) fillMatrixCells(ctx context.Context, origins, destinations []Location, cells [][]*matrixCellCache, keys [][]string, data MatrixRequest) error {
+	chunk := tableChunkSize - len(origins)
+	distances := make([][]float64, len(origins))
+	durations := make([][]float64, len(origins))
+	for start := 0; start < len(destinations); start += chunk {
+		end := start + chunk
+		if end > len(destinations) {
+			end = len(destinations)
+		}
+		tableCtx, cancel := context.WithTimeout(ctx, 60*time.Second)
+		table, err := s.Engine.Table(tableCtx, origins, destinations[start:end], vehicleProfile(data.Type))
+		cancel()
+		if err != nil {
+			return fmt.Errorf("erro ao calcular matriz de distâncias: %w", err)
+		}
+		for i := range origins {
+			distances[i] = append(distances[i], table.Distances[i]...)
+			durations[i] = append(durations[i], table.Durations[i]...)
+		}
+	}
+
+	// Sem a tabela de pedágios as células são devolvidas, mas não vão para o cache
+	tolls, err := s.loadTollPrices(ctx, newTollVehicle(data.Type, data.Axles, data.VehicleInfo), nil)
+	cacheable := err == nil
+	if err != nil {
+		log.Printf("Erro ao buscar pedágios para a matriz: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, matrixConcurrency)
+	for i := range origins {
+		for j := range destinations {
+			if cells[i][j] != nil {
+				continue
+			}
+			cell := &matrixCellCache{Distance: distances[i][j], Duration: durations[i][j], TollCostUnknown: !cacheable}
+			cells[i][j] = cell
+			if cell.Distance < 0 {
+				continue
+			}
+
+			wg.Add(1)
+			go func(i, j int, cell *matrixCellCache) {
+				defer wg.Done()
+				sem <- struct{}{}
+				defer func() { <-sem }()
+
+				if len(tolls.items) > 0 && distances[i][j] > 0 {
+					route, err := s.engineRoute(ctx, 30*time.Second, RouteRequest{Coordinates: []Location{origins[i], destinations[j]}, Profile: vehicleProfile(data.Type)})
+					if err != nil {
+						log.Printf("Erro ao calcular rota da célula %d,%d: %v", i, j, err)
+						cell.TollCostUnknown = true
+						return
+					}
+					cell.TollCost = math.Round(tollCostOnGeometry(route.Routes[0].Geometry, tolls)*100) / 100
+				}
+
+				if !cacheable || keys[i][j] == "" {
+					return
+				}
+				if payload, err := json.Marshal(cell); err == nil {
+					if err := cache.Rdb.Set(ctx, keys[i][j], payload, 30*24*time.Hour).Err(); err != nil {
+						log.Printf("Erro ao salvar cache do Redis (matrix): %v", err)
+					}
+				}
+			}(i, j, cell)
+		}
+	}
+	wg.Wait()
+	return nil
+}
+
+// loadTollPrices carrega as praças com o valor pago pelo veículo, pela categoria ANTT e pela tag informada,
+// nas tarifas vigentes no dia da saída
+func (s *Service) loadTollPrices(ctx context.Context, vehicle TollVehicle, departure *time.Time) (tollPriceTable, error) {
+	snap, err := s.POIIndex.current(ctx)
+	if err != nil {
+		return tollPriceTable{}, fmt.Errorf("erro ao buscar pedágios: %w", err)
+	}
+	pricing, err := s.tollPricingAt(ctx, snap, departure)
+	if err != nil {
+		return tollPriceTable{}, err
+	}
+	var tagRecords []db.TollTag
+	if vehicle.Tag != "" {
+		tagRecords, err = s.InterfaceService.GetTollTags(ctx)
+		if err != nil {
+			return tollPriceTable{}, fmt.Errorf("erro ao buscar tags: %w", err)
+		}
+	}
+	category := vehicle.category()
+
+	table := tollPriceTable{snap: snap}
+	for i, t := range snap.tolls {
+		pos := snap.tollPositions[i]
+		if pos.Lat == 0 && pos.Lng == 0 {
+			continue
+		}
+		value := pricing.cashPrice(t, category)
+		if vehicle.Tag != "" {
+			concession := t.Concessionaria.String
+			value = paidPrice(pricing.tagPrices(acceptedTags(tagRecords, concession), concession, category.Code, value), vehicle.Tag, value)
+		}
+		if value > 0 {
+			table.items = append(table.items, tollPrice{Position: pos, Price: value, Sentido: t.Sentido.String, index: i})
+			table.positions = append(table.positions, pos)
+		}
+	}
+	table.grid = newGridIndex(poiGridCellSize, table.positions)
+	return table, nil
+}
+
+// tollCostOnGeometry soma as tarifas das praças sobre a rota, com o mesmo casamento de sentido de findTollsOnRoute
+func tollCostOnGeometry(routeGeometry string, tolls tollPriceTable) float64 {
+	geometry, err := routeGeometryFromPolyline(routeGeometry)
+	if err != nil || len(geometry.Points) < 2 {
+		return 0
+	}
+	markers := tolls.snap.routeMarkers(geometry)
+
+	var total float64
+	for _, m := range geometry.match(tolls.grid, tolls.positions, tollRouteTolerance) {
+		t := tolls.items[m.Index]
+		direction := tolls.snap.travelDirection(geometry, m, tolls.snap.tollMarkers[t.index], markers)
+		if !direction.accepts(t.Sentido) {
+			continue
+		}
+		total += t.Price
+	}
+	return total
+}
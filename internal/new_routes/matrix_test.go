@@ -0,0 +1,78 @@
+package new_routes
+
+import (
+	"context"
+	"errors"
+	db "geolocation/db/sqlc"
+	"geolocation/internal/routes"
+	"testing"
+	"time"
+)
+
+// flakyRouteEngine liga os pontos em linha reta, mas não encontra rota até os destinos de unreachable
+type flakyRouteEngine struct {
+	FakeEngine
+	unreachable map[Location]bool
+}
+
+func (e *flakyRouteEngine) Route(ctx context.Context, req RouteRequest) (OSRMResponse, error) {
+	if e.unreachable[req.Coordinates[len(req.Coordinates)-1]] {
+		return OSRMResponse{}, ErrNoRoute
+	}
+	return e.FakeEngine.Route(ctx, req)
+}
+
+// brokenTariffRepository falha ao buscar as tarifas vigentes
+type brokenTariffRepository struct {
+	tariffRepository
+}
+
+func (r *brokenTariffRepository) GetEffectiveTollTariffs(context.Context, time.Time) ([]db.TollTariff, error) {
+	return nil, errors.New("conexão recusada")
+}
+
+func TestFillMatrixCellsTollCostUnknown(t *testing.T) {
+	origin := Location{Latitude: 0, Longitude: 0}
+	// A praça 1 fica no meio do caminho até o primeiro destino
+	throughToll := Location{Latitude: 0, Longitude: 0.1}
+	noRoute := Location{Latitude: 0.1, Longitude: 0}
+
+	tests := []struct {
+		name        string
+		repository  routes.InterfaceRepository
+		wantToll    []float64
+		wantUnknown []bool
+	}{
+		{name: "célula sem rota fica com pedágio desconhecido", repository: &tariffRepository{}, wantToll: []float64{10, 0}, wantUnknown: []bool{false, true}},
+		{name: "sem tarifas nenhuma célula tem pedágio conhecido", repository: &brokenTariffRepository{}, wantToll: []float64{0, 0}, wantUnknown: []bool{true, true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &Service{
+				InterfaceService: tt.repository,
+				Engine:           &flakyRouteEngine{unreachable: map[Location]bool{noRoute: true}},
+				POIIndex: &POIIndex{snapshot: &poiSnapshot{
+					tolls:         []db.Toll{{ID: 1}},
+					tollPositions: []LatLng{{Lat: 0, Lng: 0.05}},
+					tollMarkers:   []roadMarker{{}},
+				}},
+			}
+
+			destinations := []Location{throughToll, noRoute}
+			cells := [][]*matrixCellCache{make([]*matrixCellCache, len(destinations))}
+			keys := [][]string{make([]string, len(destinations))}
+			if err := s.fillMatrixCells(context.Background(), []Location{origin}, destinations, cells, keys, MatrixRequest{Type: "Auto"}); err != nil {
+				t.Fatalf("fillMatrixCells() erro = %v", err)
+			}
+			for j, cell := range cells[0] {
+				if cell.TollCost != tt.wantToll[j] || cell.TollCostUnknown != tt.wantUnknown[j] {
+					t.Errorf("célula %d: pedágio %v (desconhecido %v), want %v (%v)", j, cell.TollCost, cell.TollCostUnknown, tt.wantToll[j], tt.wantUnknown[j])
+				}
+				if cell.Distance <= 0 {
+					t.Errorf("célula %d sem distância da matriz", j)
+				}
+			}
+		})
+	}
+}
@@ -3,6 +3,7 @@ package new_routes
 import (
 	"encoding/json"
 	db "geolocation/db/sqlc"
+	"geolocation/internal/zonas_risco"
 	"time"
 )
 
@@ -56,6 +57,8 @@ type Response struct {
 	Routes      []DetailedRoute `json:"routes"`
 	TotalRoute  TotalSummary    `json:"total_route"`
 	TotalRoutes []TotalSummary  `json:"total_routes_all,omitempty"`
+	StopOrder   []int           `json:"stop_order,omitempty"`
+	OptimizedBy string          `json:"optimized_by,omitempty"`
 }
 
 type DetailedRoute struct {
@@ -87,6 +90,11 @@ type TotalSummary struct {
 	Instructions        []Instruction      `json:"instructions,omitempty"`
 	AttentionZones      *AttentionZoneInfo `json:"attention_zones"`
 	RouteType           string             `json:"route_type,omitempty"`
+	Restrictions        []RestrictionAlert `json:"restrictions,omitempty"`
+	DriverItinerary     *DriverItinerary   `json:"driver_itinerary,omitempty"`
+	FuelSplit           *FuelSplit         `json:"fuel_split,omitempty"`
+	Emissions           *Emissions         `json:"emissions,omitempty"`
+	RiskExposure        *RiskExposure      `json:"risk_exposure,omitempty"`
 }
 type SummaryResponse struct {
 	LocationOrigin      AddressInfo    `json:"location_origin"`
@@ -117,15 +125,22 @@ type Toll struct {
 	State           string          `json:"state"`
 	Country         string          `json:"country"`
 	Type            string          `json:"type"`
+	Category        int             `json:"category"`
 	TagCost         float64         `json:"tagCost"`
 	CashCost        float64         `json:"cashCost"`
+	PaidCost        float64         `json:"paidCost"`
 	Currency        string          `json:"currency"`
 	PrepaidCardCost float64         `json:"prepaidCardCost"`
 	ArrivalResponse ArrivalResponse `json:"arrival"`
 	TagPrimary      []string        `json:"tagPrimary"`
 	TagImg          []string        `json:"tagImg"`
+	TagPrices       []TollTagPrice  `json:"tagPrices"`
 	FreeFlow        bool            `json:"free_flow"`
 	PayFreeFlow     string          `json:"pay_free_flow"`
+	KmMarker        float64         `json:"km_marker"`
+	RouteKm         float64         `json:"route_km"`
+	Direction       string          `json:"direction"`
+	DirectionConf   string          `json:"direction_confidence"`
 }
 
 type Distance struct {
@@ -148,23 +163,40 @@ type RiskZone struct {
 	Status         bool    `json:"status"`
 	ZonasAtencao   bool    `json:"zonas_atencao"`
 	OrganizationID int64   `json:"organization_id"`
+	// Shape, Geometry e BufferMeters descrevem zonas em polígono ou corredor; Lat, Lng e Radius
+	// guardam o círculo que envolve a forma
+	Shape        string          `json:"shape,omitempty"`
+	Geometry     json.RawMessage `json:"geometry,omitempty"`
+	BufferMeters int64           `json:"buffer_meters,omitempty"`
+	Severity     int64           `json:"severity,omitempty"`
+	// Action (avoid, warn ou ignore) e PassageTime são definidos pelo horário previsto de passagem na zona
+	Action      string     `json:"action,omitempty"`
+	PassageTime *time.Time `json:"passage_time,omitempty"`
+
+	area     *zonas_risco.Area
+	schedule zonas_risco.Schedule
 }
 
 type RouteSummary struct {
-	RouteType      string             `json:"route_type"`
-	HasTolls       bool               `json:"hasTolls"`
-	Distance       Distance           `json:"distance"`
-	Duration       Duration           `json:"duration"`
-	URL            string             `json:"url"`
-	URLWaze        string             `json:"url_waze"`
-	TotalFuelCost  float64            `json:"total_fuel_cost,omitempty"`
-	Tolls          []Toll             `json:"tolls,omitempty"`
-	TotalTolls     float64            `json:"total_tolls,omitempty"`
-	Polyline       string             `json:"polyline,omitempty"`
-	Instructions   []Instruction      `json:"instructions,omitempty"`
-	AttentionZones *AttentionZoneInfo `json:"attention_zones"`
-	RiskInfo       *RiskOffsets       `json:"risk_info,omitempty"`
-	Detour         *DetourPlan        `json:"detour,omitempty"`
+	RouteType       string             `json:"route_type"`
+	HasTolls        bool               `json:"hasTolls"`
+	Distance        Distance           `json:"distance"`
+	Duration        Duration           `json:"duration"`
+	URL             string             `json:"url"`
+	URLWaze         string             `json:"url_waze"`
+	TotalFuelCost   float64            `json:"total_fuel_cost,omitempty"`
+	Tolls           []Toll             `json:"tolls,omitempty"`
+	TotalTolls      float64            `json:"total_tolls,omitempty"`
+	Polyline        string             `json:"polyline,omitempty"`
+	Instructions    []Instruction      `json:"instructions,omitempty"`
+	AttentionZones  *AttentionZoneInfo `json:"attention_zones"`
+	RiskInfo        *RiskOffsets       `json:"risk_info,omitempty"`
+	Detour          *DetourPlan        `json:"detour,omitempty"`
+	Restrictions    []RestrictionAlert `json:"restrictions,omitempty"`
+	DriverItinerary *DriverItinerary   `json:"driver_itinerary,omitempty"`
+	FuelSplit       *FuelSplit         `json:"fuel_split,omitempty"`
+	Emissions       *Emissions         `json:"emissions,omitempty"`
+	RiskExposure    *RiskExposure      `json:"risk_exposure,omitempty"`
 }
 type DetourPlan struct {
 	Source string        `json:"source"`
@@ -176,20 +208,23 @@ type DetourPoint struct {
 	Location Location `json:"location"`
 }
 type Costs struct {
-	TagAndCash      float64 `json:"tagAndCash"`
-	FuelInTheCity   float64 `json:"fuel_in_the_city"`
-	FuelInTheHwy    float64 `json:"fuel_in_the_hwy"`
-	Tag             float64 `json:"tag"`
-	Cash            float64 `json:"cash"`
-	PrepaidCard     float64 `json:"prepaidCard"`
-	MaximumTollCost float64 `json:"maximumTollCost"`
-	MinimumTollCost float64 `json:"minimumTollCost"`
-	Axles           int     `json:"axles"`
+	TagAndCash      float64    `json:"tagAndCash"`
+	FuelInTheCity   float64    `json:"fuel_in_the_city"`
+	FuelInTheHwy    float64    `json:"fuel_in_the_hwy"`
+	Tag             float64    `json:"tag"`
+	Cash            float64    `json:"cash"`
+	PrepaidCard     float64    `json:"prepaidCard"`
+	MaximumTollCost float64    `json:"maximumTollCost"`
+	MinimumTollCost float64    `json:"minimumTollCost"`
+	Axles           int        `json:"axles"`
+	FuelSplit       *FuelSplit `json:"fuel_split,omitempty"`
 }
 
 type Instruction struct {
-	Text string `json:"text"`
-	Img  string `json:"img"`
+	Text         string  `json:"text"`
+	Img          string  `json:"img"`
+	Distance     float64 `json:"distance,omitempty"`      // Metros percorridos neste passo
+	DistanceText string  `json:"distance_text,omitempty"` // Distância formatada no idioma da requisição
 }
 
 type RouteOutput struct {
@@ -208,9 +243,12 @@ type RouteOutputSimp struct {
 }
 
 type GasStation struct {
-	Name     string   `json:"name"`
-	Address  string   `json:"address"`
-	Location Location `json:"location"`
+	ID       int64            `json:"id,omitempty"`
+	Name     string           `json:"name"`
+	Address  string           `json:"address"`
+	Location Location         `json:"location"`
+	Price    *float64         `json:"price,omitempty"` // Preço do litro, quando conhecido
+	Arrival  *ArrivalResponse `json:"arrival,omitempty"`
 }
 
 type FinalOutput struct {
@@ -246,6 +284,7 @@ type OSRMStep struct {
 	Distance float64 `json:"distance"`
 	Duration float64 `json:"duration"`
 	Name     string  `json:"name"`
+	Ref      string  `json:"ref"`
 	Maneuver struct {
 		Location [2]float64 `json:"location"`
 		Type     string     `json:"type"`
@@ -281,6 +320,11 @@ type FrontInfo struct {
 	PublicOrPrivate string       `json:"public_or_private"`
 	Favorite        bool         `json:"favorite"`
 	RouteOptions    RouteOptions `json:"route_options"`
+	DepartureTime   *time.Time   `json:"departure_time"`
+	DriverRules     *DriverRules `json:"driver_rules,omitempty"`
+	TokenOrgID      int64        `json:"-"`      // Organização do token; soma as emissões da rota no relatório da organização
+	Locale          string       `json:"locale"` // pt-BR, es ou en; vazio usa o Accept-Language
+	VehicleInfo
 }
 
 type FrontInfoCEP struct {
@@ -297,6 +341,11 @@ type FrontInfoCEP struct {
 	Favorite        bool         `json:"favorite"`
 	RouteOptions    RouteOptions `json:"route_options"`
 	Enterprise      bool         `json:"enterprise"`
+	DepartureTime   *time.Time   `json:"departure_time"`
+	DriverRules     *DriverRules `json:"driver_rules,omitempty"`
+	TokenOrgID      int64        `json:"-"`      // Organização do token; soma as emissões da rota no relatório da organização
+	Locale          string       `json:"locale"` // pt-BR, es ou en; vazio usa o Accept-Language
+	VehicleInfo
 }
 
 type FrontInfoCEPRequest struct {
@@ -310,6 +359,12 @@ type FrontInfoCEPRequest struct {
 	RouteOptions    RouteOptions `json:"route_options"`
 	Waypoints       []Coordinate `json:"waypoints"`
 	OrganizationID  int64        `json:"organization_id" validate:"required"`
+	TokenOrgID      int64        `json:"-"` // Organização do token; só ela registra as emissões da rota total
+	DepartureTime   *time.Time   `json:"departure_time"`
+	DriverRules     *DriverRules `json:"driver_rules,omitempty"`
+	Locale          string       `json:"locale"` // pt-BR, es ou en; vazio usa o Accept-Language
+	VehicleInfo
+	StopOptimization
 }
 
 type FrontInfoCEPRequestV2 struct {
@@ -322,6 +377,11 @@ type FrontInfoCEPRequestV2 struct {
 	TypeRoute       string       `json:"typeRoute"`
 	RouteOptions    RouteOptions `json:"route_options"`
 	Waypoints       []Coordinate `json:"waypoints"`
+	OrganizationID  int64        `json:"organization_id"` // Zonas de risco da organização usadas na exposição ao risco
+	DepartureTime   *time.Time   `json:"departure_time"`
+	DriverRules     *DriverRules `json:"driver_rules,omitempty"`
+	Locale          string       `json:"locale"` // pt-BR, es ou en; vazio usa o Accept-Language
+	VehicleInfo
 }
 
 type FrontInfoCoordinate struct {
@@ -339,6 +399,11 @@ type FrontInfoCoordinate struct {
 	PublicOrPrivate string       `json:"public_or_private"`
 	Favorite        bool         `json:"favorite"`
 	RouteOptions    RouteOptions `json:"route_options"`
+	DepartureTime   *time.Time   `json:"departure_time"`
+	DriverRules     *DriverRules `json:"driver_rules,omitempty"`
+	TokenOrgID      int64        `json:"-"`      // Organização do token; soma as emissões da rota no relatório da organização
+	Locale          string       `json:"locale"` // pt-BR, es ou en; vazio usa o Accept-Language
+	VehicleInfo
 }
 
 type FrontInfoCoordinatesRequest struct {
@@ -352,6 +417,12 @@ type FrontInfoCoordinatesRequest struct {
 	RouteOptions    RouteOptions `json:"route_options"`
 	Waypoints       []Coordinate `json:"waypoints"`
 	OrganizationID  int64        `json:"organization_id" validate:"required"`
+	TokenOrgID      int64        `json:"-"` // Organização do token; só ela registra as emissões da rota total
+	DepartureTime   *time.Time   `json:"departure_time"`
+	DriverRules     *DriverRules `json:"driver_rules,omitempty"`
+	Locale          string       `json:"locale"` // pt-BR, es ou en; vazio usa o Accept-Language
+	VehicleInfo
+	StopOptimization
 }
 
 type Coordinate struct {
@@ -365,13 +436,18 @@ type GeocodeResult struct {
 	Location         Location
 }
 
-type Arrival struct {
-	Distance string        `json:"distance"`
-	Time     time.Duration `json:"time"`
-}
 type ArrivalResponse struct {
-	Distance string `json:"distance"`
-	Time     string `json:"time"`
+	Distance        string     `json:"distance"`
+	Time            string     `json:"time"`
+	DistanceMeters  float64    `json:"distance_meters"`
+	DurationSeconds float64    `json:"duration_seconds"`
+	ETA             *time.Time `json:"eta,omitempty"`
+}
+
+// RouteBalanca é a balança encontrada na rota com a chegada estimada
+type RouteBalanca struct {
+	db.Balanca
+	Arrival ArrivalResponse `json:"arrival"`
 }
 
 type SimpleRouteRequest struct {
@@ -470,14 +546,16 @@ func (p *FavoriteRouteResponse) ParseFromFavoriteRouteObject(result db.FavoriteR
 
 // Ponto de entrada/saída da zona de atenção
 type AttentionZoneEvent struct {
-	Type          string   `json:"type"`           // "entry" ou "exit"
-	ZoneName      string   `json:"zone_name"`      // Nome da zona
-	ZoneID        int64    `json:"zone_id"`        // ID da zona
-	Distance      float64  `json:"distance"`       // Distância do início da rota até este ponto
-	Coordinates   Location `json:"coordinates"`    // Coordenadas onde acontece a entrada/saída
-	Message       string   `json:"message"`        // Mensagem a ser exibida
-	DetectionType string   `json:"detection_type"` // "area" ou "street" - como foi detectada
-	StreetName    string   `json:"street_name"`    // Nome da rua (se detectada por rua)
+	Type          string           `json:"type"`               // "entry" ou "exit"
+	ZoneName      string           `json:"zone_name"`          // Nome da zona
+	ZoneID        int64            `json:"zone_id"`            // ID da zona
+	Distance      float64          `json:"distance"`           // Distância do início da rota até este ponto
+	Coordinates   Location         `json:"coordinates"`        // Coordenadas onde acontece a entrada/saída
+	Message       string           `json:"message"`            // Mensagem a ser exibida
+	DetectionType string           `json:"detection_type"`     // "area" ou "street" - como foi detectada
+	StreetName    string           `json:"street_name"`        // Nome da rua (se detectada por rua)
+	Arrival       *ArrivalResponse `json:"arrival,omitempty"`  // Chegada estimada ao ponto
+	Severity      int64            `json:"severity,omitempty"` // Severidade da zona, de 1 (baixa) a 4 (crítica)
 }
 
 // Informações sobre zonas de atenção encontradas na rota
@@ -0,0 +1,235 @@
+package new_routes
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	OptimizeByDistance = "distance"
+	OptimizeByDuration = "duration"
+	OptimizeByCost     = "cost"
+
+	// maxOptimizeStops limita o tamanho da matriz pedida ao motor de roteamento
+	maxOptimizeStops = 50
+	// tollCorridorMeters é a distância máxima entre a praça e a reta entre duas paradas para estimar o pedágio
+	tollCorridorMeters = 3000.0
+	// unreachableCost penaliza pares sem rota possível na matriz
+	unreachableCost = 1e12
+)
+
+// StopOptimization habilita a reordenação das paradas intermediárias.
+// A primeira parada é sempre fixa; a última só é fixa quando FixedEnd for verdadeiro.
+type StopOptimization struct {
+	Optimize   bool   `json:"optimize"`
+	OptimizeBy string `json:"optimize_by"`
+	FixedEnd   bool   `json:"fixed_end"`
+}
+
+func (o StopOptimization) criteria() string {
+	switch strings.ToLower(strings.TrimSpace(o.OptimizeBy)) {
+	case OptimizeByDuration:
+		return OptimizeByDuration
+	case OptimizeByCost:
+		return OptimizeByCost
+	default:
+		return OptimizeByDistance
+	}
+}
+
+// optimizeCEPOrder geocodifica os CEPs e devolve a lista reordenada junto com a ordem escolhida (índices da entrada)
+func (s *Service) optimizeCEPOrder(ctx context.Context, data FrontInfoCEPRequest) ([]string, []int, error) {
+	locs := make([]Location, len(data.CEPs))
+	for i, cep := range data.CEPs {
+		lat, lon, _, err := s.getCoordByCEP(ctx, cep)
+		if err != nil {
+			return nil, nil, fmt.Errorf("erro ao buscar coordenadas do CEP %s: %w", cep, err)
+		}
+		locs[i] = Location{Latitude: lat, Longitude: lon}
+	}
+
+	order, err := s.optimizeStopOrder(ctx, locs, data.StopOptimization, data.Price, (data.ConsumptionCity+data.ConsumptionHwy)/2, newTollVehicle(data.Type, data.Axles, data.VehicleInfo), data.DepartureTime)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ceps := make([]string, len(order))
+	for i, idx := range order {
+		ceps[i] = data.CEPs[idx]
+	}
+	return ceps, order, nil
+}
+
+// optimizeCoordinateOrder reordena as coordenadas informadas, devolvendo também a ordem escolhida
+func (s *Service) optimizeCoordinateOrder(ctx context.Context, data FrontInfoCoordinatesRequest) ([]Coordinate, []int, error) {
+	locs := make([]Location, len(data.Coordinates))
+	for i, c := range data.Coordinates {
+		lat, errLat := strconv.ParseFloat(strings.TrimSpace(c.Lat), 64)
+		lng, errLng := strconv.ParseFloat(strings.TrimSpace(c.Lng), 64)
+		if errLat != nil || errLng != nil {
+			return nil, nil, fmt.Errorf("coordenada inválida na posição %d", i)
+		}
+		locs[i] = Location{Latitude: lat, Longitude: lng}
+	}
+
+	order, err := s.optimizeStopOrder(ctx, locs, data.StopOptimization, data.Price, (data.ConsumptionCity+data.ConsumptionHwy)/2, newTollVehicle(data.Type, data.Axles, data.VehicleInfo), data.DepartureTime)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	coords := make([]Coordinate, len(order))
+	for i, idx := range order {
+		coords[i] = data.Coordinates[idx]
+	}
+	return coords, order, nil
+}
+
+// optimizeStopOrder monta a matriz de custos com o motor de roteamento e resolve a ordem das paradas
+func (s *Service) optimizeStopOrder(ctx context.Context, locs []Location, opts StopOptimization, price, avgConsumption float64, vehicle TollVehicle, departure *time.Time) ([]int, error) {
+	if len(locs) > maxOptimizeStops {
+		return nil, fmt.Errorf("a otimização aceita no máximo %d paradas", maxOptimizeStops)
+	}
+	identity := make([]int, len(locs))
+	for i := range identity {
+		identity[i] = i
+	}
+	movable := len(locs) - 1
+	if opts.FixedEnd {
+		movable--
+	}
+	if movable < 2 {
+		// Com menos de duas paradas livres não há o que reordenar
+		return identity, nil
+	}
+
+	tableCtx, cancel := context.WithTimeout(ctx, 60*time.Second)
+	defer cancel()
+	table, err := s.Engine.Table(tableCtx, locs, locs, vehicleProfile(vehicle.Type))
+	if err != nil {
+		return nil, fmt.Errorf("erro ao calcular matriz de distâncias: %w", err)
+	}
+
+	var costs [][]float64
+	switch opts.criteria() {
+	case OptimizeByDuration:
+		costs = table.Durations
+	case OptimizeByCost:
+		costs, err = s.costMatrix(ctx, locs, table.Distances, price, avgConsumption, vehicle, departure)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		costs = table.Distances
+	}
+
+	return solveStopOrder(costs, opts.FixedEnd), nil
+}
+
+// costMatrix combina combustível (pela distância rodoviária) e uma estimativa dos pedágios entre cada par de paradas
+func (s *Service) costMatrix(ctx context.Context, locs []Location, distances [][]float64, price, avgConsumption float64, vehicle TollVehicle, departure *time.Time) ([][]float64, error) {
+	tolls, err := s.loadTollPrices(ctx, vehicle, departure)
+	if err != nil {
+		return nil, err
+	}
+
+	costs := make([][]float64, len(locs))
+	for i := range locs {
+		costs[i] = make([]float64, len(locs))
+		for j := range locs {
+			if i == j {
+				continue
+			}
+			if distances[i][j] < 0 {
+				costs[i][j] = -1
+				continue
+			}
+
+			var fuel float64
+			if avgConsumption > 0 {
+				fuel = (price / avgConsumption) * (distances[i][j] / 1000)
+			}
+
+			a := LatLng{Lat: locs[i].Latitude, Lng: locs[i].Longitude}
+			b := LatLng{Lat: locs[j].Latitude, Lng: locs[j].Longitude}
+			var tollCost float64
+			for _, t := range tolls.items {
+				if distancePointToSegment(t.Position, a, b) <= tollCorridorMeters {
+					tollCost += t.Price
+				}
+			}
+
+			costs[i][j] = fuel + tollCost
+		}
+	}
+	return costs, nil
+}
+
+// solveStopOrder aplica vizinho mais próximo seguido de 2-opt. O índice 0 é sempre a origem e,
+// com fixedEnd, o último índice é mantido como destino. A matriz pode ser assimétrica.
+func solveStopOrder(costs [][]float64, fixedEnd bool) []int {
+	n := len(costs)
+	cost := func(i, j int) float64 {
+		if costs[i][j] < 0 {
+			return unreachableCost
+		}
+		return costs[i][j]
+	}
+
+	last := n
+	if fixedEnd {
+		last = n - 1
+	}
+
+	visited := make([]bool, n)
+	visited[0] = true
+	order := []int{0}
+	for len(order) < last {
+		current := order[len(order)-1]
+		best, bestCost := -1, math.MaxFloat64
+		for j := 1; j < last; j++ {
+			if !visited[j] && cost(current, j) < bestCost {
+				best, bestCost = j, cost(current, j)
+			}
+		}
+		visited[best] = true
+		order = append(order, best)
+	}
+	if fixedEnd {
+		order = append(order, n-1)
+	}
+
+	pathCost := func(o []int) float64 {
+		var total float64
+		for k := 0; k < len(o)-1; k++ {
+			total += cost(o[k], o[k+1])
+		}
+		return total
+	}
+
+	// Trechos invertidos mudam o sentido dos arcos; por isso o custo é recalculado por inteiro
+	end := len(order)
+	if fixedEnd {
+		end = len(order) - 1
+	}
+	bestCost := pathCost(order)
+	for improved := true; improved; {
+		improved = false
+		for i := 1; i < end-1; i++ {
+			for k := i + 1; k < end; k++ {
+				candidate := append([]int{}, order...)
+				for a, b := i, k; a < b; a, b = a+1, b-1 {
+					candidate[a], candidate[b] = candidate[b], candidate[a]
+				}
+				if c := pathCost(candidate); c < bestCost-1e-9 {
+					order, bestCost, improved = candidate, c, true
+				}
+			}
+		}
+	}
+
+	return order
+}
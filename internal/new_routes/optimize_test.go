@@ -0,0 +1,57 @@
+package new_routes
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+// lineCosts monta a matriz simétrica de pontos sobre uma reta: o custo é a distância entre as posições
+func lineCosts(positions ...float64) [][]float64 {
+	costs := make([][]float64, len(positions))
+	for i := range positions {
+		costs[i] = make([]float64, len(positions))
+		for j := range positions {
+			costs[i][j] = math.Abs(positions[i] - positions[j])
+		}
+	}
+	return costs
+}
+
+func TestSolveStopOrder(t *testing.T) {
+	unreachable := [][]float64{
+		{0, -1, 5},
+		{1, 0, 1},
+		{1, 5, 0},
+	}
+	// Ida barata e volta cara: o sentido dos arcos decide a ordem
+	asymmetric := [][]float64{
+		{0, 1, 10, 10},
+		{10, 0, 1, 10},
+		{10, 10, 0, 1},
+		{1, 10, 10, 0},
+	}
+
+	tests := []struct {
+		name     string
+		costs    [][]float64
+		fixedEnd bool
+		want     []int
+	}{
+		{name: "só a origem", costs: lineCosts(0), want: []int{0}},
+		{name: "origem e destino fixo", costs: lineCosts(0, 5), fixedEnd: true, want: []int{0, 1}},
+		{name: "vizinho mais próximo na reta", costs: lineCosts(0, 3, 1, 2), want: []int{0, 2, 3, 1}},
+		{name: "destino fixo fica no fim", costs: lineCosts(0, 2, 1, 3), fixedEnd: true, want: []int{0, 2, 1, 3}},
+		{name: "2-opt corrige o vizinho mais próximo", costs: lineCosts(0, 1, -1.5, 10), want: []int{0, 2, 1, 3}},
+		{name: "par sem rota é evitado", costs: unreachable, want: []int{0, 2, 1}},
+		{name: "matriz assimétrica", costs: asymmetric, want: []int{0, 1, 2, 3}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := solveStopOrder(tt.costs, tt.fixedEnd); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("solveStopOrder() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,327 @@
+package new_routes
+
+import (
+	"context"
+	"fmt"
+	db "geolocation/db/sqlc"
+	"geolocation/internal/routes"
+	"geolocation/validation"
+	"log"
+	"math"
+	"sync"
+	"time"
+)
+
+const (
+	// poiGridCellSize é o lado da célula da grade em graus (~5,5 km no equador)
+	poiGridCellSize = 0.05
+	// defaultPOIRefresh é o intervalo padrão de verificação de mudanças nas tabelas
+	defaultPOIRefresh = time.Minute
+)
+
+// poiSnapshot é uma carga completa de pedágios, balanças e postos com as grades de cada um
+type poiSnapshot struct {
+	fingerprint db.GetPOIFingerprintRow
+
+	tolls         []db.Toll
+	tollPositions []LatLng
+	tollMarkers   []roadMarker
+	tollGrid      *gridIndex
+
+	balancas         []db.Balanca
+	balancaPositions []LatLng
+	balancaMarkers   []roadMarker
+	balancaGrid      *gridIndex
+
+	// markersByRoad agrupa pedágios e balanças com km conhecido por rodovia + UF
+	markersByRoad map[string][]roadMarker
+
+	stations         []GasStation
+	stationPositions []LatLng
+	stationGrid      *gridIndex
+
+	// fuelPriceAverage é a média nacional do diesel no último levantamento da ANP (0 sem preços importados)
+	fuelPriceAverage float64
+
+	// pricing guarda as tarifas e descontos por dia de vigência, carregados sob demanda. A impressão digital
+	// inclui toll_tariffs e toll_tag_discounts, então qualquer alteração nelas descarta o cache com a carga.
+	pricingMu sync.Mutex
+	pricing   map[string]tollPricing
+}
+
+// POIIndex mantém em memória pedágios, balanças e postos indexados em grade. O conteúdo é recarregado
+// quando a impressão digital das tabelas (quantidade de linhas e último updated_at) muda, verificada periodicamente por Watch.
+type POIIndex struct {
+	repository routes.InterfaceRepository
+	refresh    time.Duration
+
+	mu       sync.RWMutex
+	snapshot *poiSnapshot
+	loadMu   sync.Mutex
+}
+
+// NewPOIIndex cria o índice; refresh é uma duração ("30s", "5m") e vazio usa o padrão de 1 minuto
+func NewPOIIndex(repository routes.InterfaceRepository, refresh string) *POIIndex {
+	interval := defaultPOIRefresh
+	if refresh != "" {
+		if d, err := time.ParseDuration(refresh); err == nil && d > 0 {
+			interval = d
+		} else {
+			log.Printf("POI_INDEX_REFRESH inválido (%q), usando %s", refresh, defaultPOIRefresh)
+		}
+	}
+	return &POIIndex{repository: repository, refresh: interval}
+}
+
+// Watch verifica periodicamente se as tabelas mudaram e recarrega o índice até o contexto ser cancelado
+func (p *POIIndex) Watch(ctx context.Context) {
+	ticker := time.NewTicker(p.refresh)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := p.refreshIfChanged(ctx); err != nil {
+				log.Printf("Erro ao atualizar índice de pedágios, balanças e postos: %v", err)
+			}
+		}
+	}
+}
+
+// Invalidate descarta a carga atual; a próxima consulta recarrega as tabelas
+func (p *POIIndex) Invalidate() {
+	p.mu.Lock()
+	p.snapshot = nil
+	p.mu.Unlock()
+}
+
+func (p *POIIndex) current(ctx context.Context) (*poiSnapshot, error) {
+	p.mu.RLock()
+	snap := p.snapshot
+	p.mu.RUnlock()
+	if snap != nil {
+		return snap, nil
+	}
+
+	p.loadMu.Lock()
+	defer p.loadMu.Unlock()
+	p.mu.RLock()
+	snap = p.snapshot
+	p.mu.RUnlock()
+	if snap != nil {
+		return snap, nil
+	}
+
+	fingerprint, err := p.repository.GetPOIFingerprint(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao verificar versão dos pontos de interesse: %w", err)
+	}
+	return p.load(ctx, fingerprint)
+}
+
+func (p *POIIndex) refreshIfChanged(ctx context.Context) error {
+	fingerprint, err := p.repository.GetPOIFingerprint(ctx)
+	if err != nil {
+		return err
+	}
+
+	p.mu.RLock()
+	snap := p.snapshot
+	p.mu.RUnlock()
+	if snap != nil && snap.fingerprint == fingerprint {
+		return nil
+	}
+
+	p.loadMu.Lock()
+	defer p.loadMu.Unlock()
+	_, err = p.load(ctx, fingerprint)
+	return err
+}
+
+func (p *POIIndex) load(ctx context.Context, fingerprint db.GetPOIFingerprintRow) (*poiSnapshot, error) {
+	tolls, err := p.repository.GetTollsByLonAndLat(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao carregar pedágios: %w", err)
+	}
+	balancas, err := p.repository.GetBalanca(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao carregar balanças: %w", err)
+	}
+	stationRows, err := p.repository.GetAllGasStations(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao carregar postos: %w", err)
+	}
+	// Sem os preços da ANP os postos ficam sem preço, mas o índice continua válido
+	fuelPrices, err := p.loadFuelPrices(ctx)
+	if err != nil {
+		log.Printf("Erro ao carregar preços de combustível: %v", err)
+	}
+	// Sem o cadastro de cidades a UF do posto fica desconhecida e só valem os municípios sem homônimos
+	cities, err := p.loadCityStates(ctx)
+	if err != nil {
+		log.Printf("Erro ao carregar UF das cidades: %v", err)
+	}
+
+	snap := &poiSnapshot{
+		fingerprint:      fingerprint,
+		tolls:            tolls,
+		tollPositions:    make([]LatLng, len(tolls)),
+		tollMarkers:      make([]roadMarker, len(tolls)),
+		balancas:         balancas,
+		balancaPositions: make([]LatLng, len(balancas)),
+		balancaMarkers:   make([]roadMarker, len(balancas)),
+		markersByRoad:    make(map[string][]roadMarker),
+		stations:         make([]GasStation, len(stationRows)),
+		stationPositions: make([]LatLng, len(stationRows)),
+		fuelPriceAverage: fuelPrices.average,
+	}
+	for i, t := range tolls {
+		lat, errLat := validation.ParseNullStringToFloat(t.Latitude)
+		lng, errLng := validation.ParseNullStringToFloat(t.Longitude)
+		if errLat == nil && errLng == nil {
+			snap.tollPositions[i] = LatLng{Lat: lat, Lng: lng}
+			snap.tollMarkers[i] = snap.addMarker(t.Rodovia.String, t.Uf.String, t.KmM.String, snap.tollPositions[i])
+		}
+	}
+	for i, b := range balancas {
+		lat, errLat := validation.ParseStringToFloat(b.Lat)
+		lng, errLng := validation.ParseStringToFloat(b.Lng)
+		if errLat == nil && errLng == nil {
+			snap.balancaPositions[i] = LatLng{Lat: lat, Lng: lng}
+			snap.balancaMarkers[i] = snap.addMarker(b.Rodovia, b.Uf, b.Km, snap.balancaPositions[i])
+		}
+	}
+	for i, row := range stationRows {
+		snap.stations[i] = convertGasStation(db.GetGasStationRow(row))
+		snap.stationPositions[i] = LatLng{Lat: snap.stations[i].Location.Latitude, Lng: snap.stations[i].Location.Longitude}
+		municipioKey := validation.NormalizeName(row.Municipio)
+		if price, ok := fuelPrices.lookup(cities.state(municipioKey, snap.stationPositions[i]), municipioKey); ok {
+			snap.stations[i].Price = &price
+		}
+	}
+	snap.tollGrid = newGridIndex(poiGridCellSize, snap.tollPositions)
+	snap.balancaGrid = newGridIndex(poiGridCellSize, snap.balancaPositions)
+	snap.stationGrid = newGridIndex(poiGridCellSize, snap.stationPositions)
+
+	p.mu.Lock()
+	p.snapshot = snap
+	p.mu.Unlock()
+	return snap, nil
+}
+
+// fuelPriceTable guarda o preço mais recente do diesel por UF + município e a média nacional
+type fuelPriceTable struct {
+	byCity  map[string]float64 // chave UF|município normalizado
+	states  map[string][]string
+	average float64
+}
+
+// lookup devolve o preço do município na UF. Sem UF conhecida só responde quando o município tem preço em
+// uma única UF, para não dar a um posto o preço de um homônimo de outro estado.
+func (t fuelPriceTable) lookup(state, municipioKey string) (float64, bool) {
+	if state == "" {
+		states := t.states[municipioKey]
+		if len(states) != 1 {
+			return 0, false
+		}
+		state = states[0]
+	}
+	price, ok := t.byCity[state+"|"+municipioKey]
+	return price, ok
+}
+
+// loadFuelPrices carrega os preços por UF + município e a média nacional.
+// O S10 tem prioridade; o S500 só é usado onde o S10 não foi pesquisado.
+func (p *POIIndex) loadFuelPrices(ctx context.Context) (fuelPriceTable, error) {
+	table := fuelPriceTable{byCity: make(map[string]float64), states: make(map[string][]string)}
+	rows, err := p.repository.GetLatestFuelPrices(ctx, db.GetLatestFuelPricesParams{})
+	if err != nil {
+		return table, err
+	}
+	for _, product := range []string{fuelProductDieselS500, fuelProductDieselS10} {
+		for _, row := range rows {
+			if row.Product != product {
+				continue
+			}
+			key := row.State + "|" + row.MunicipioKey
+			if _, ok := table.byCity[key]; !ok {
+				table.states[row.MunicipioKey] = append(table.states[row.MunicipioKey], row.State)
+			}
+			table.byCity[key] = row.AveragePrice
+		}
+	}
+
+	averages, err := p.repository.GetLatestFuelPriceAverages(ctx)
+	if err != nil {
+		return table, err
+	}
+	for _, product := range []string{fuelProductDieselS500, fuelProductDieselS10} {
+		for _, row := range averages {
+			if row.Product == product {
+				table.average = row.AveragePrice
+			}
+		}
+	}
+	return table, nil
+}
+
+// cityStates lista, por nome normalizado, as cidades do cadastro com a UF e a posição
+type cityStates map[string][]cityState
+
+type cityState struct {
+	uf      string
+	pos     LatLng
+	located bool
+}
+
+// state resolve a UF do município. Homônimos em mais de uma UF são desempatados pela cidade mais próxima
+// da posição; sem coordenadas para desempatar a UF fica desconhecida.
+func (c cityStates) state(municipioKey string, pos LatLng) string {
+	candidates := c[municipioKey]
+	if len(candidates) == 1 {
+		return candidates[0].uf
+	}
+	if pos == (LatLng{}) {
+		return ""
+	}
+	uf, best := "", math.MaxFloat64
+	for _, city := range candidates {
+		if !city.located {
+			continue
+		}
+		if d := haversineDistanceTolls(pos.Lat, pos.Lng, city.pos.Lat, city.pos.Lng); d < best {
+			uf, best = city.uf, d
+		}
+	}
+	return uf
+}
+
+func (p *POIIndex) loadCityStates(ctx context.Context) (cityStates, error) {
+	rows, err := p.repository.GetCityStates(ctx)
+	if err != nil {
+		return nil, err
+	}
+	cities := make(cityStates)
+	for _, row := range rows {
+		key := validation.NormalizeName(row.Name)
+		cities[key] = append(cities[key], cityState{
+			uf:      row.Uf,
+			pos:     LatLng{Lat: row.Lat.Float64, Lng: row.Lon.Float64},
+			located: row.Lat.Valid && row.Lon.Valid,
+		})
+	}
+	return cities, nil
+}
+
+// addMarker registra o marco quando rodovia e km são conhecidos; senão devolve um marco sem chave
+func (snap *poiSnapshot) addMarker(road, uf, km string, pos LatLng) roadMarker {
+	key := roadKey(road, uf)
+	value, ok := parseKmMarker(km)
+	if key == "" || !ok {
+		return roadMarker{pos: pos}
+	}
+	marker := roadMarker{key: key, km: value, pos: pos}
+	snap.markersByRoad[key] = append(snap.markersByRoad[key], marker)
+	return marker
+}
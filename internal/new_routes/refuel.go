@@ -0,0 +1,294 @@
+package new_routes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+const (
+	// defaultRefuelReservePercent é a margem do tanque que nunca deve ser consumida entre paradas
+	defaultRefuelReservePercent = 15.0
+	// defaultRefuelTolerance é a distância máxima (m) entre o posto e a rota para ele entrar no plano
+	defaultRefuelTolerance = 300.0
+	// maxRefuelTolerance evita recomendar postos que exigem desvio grande
+	maxRefuelTolerance = 2000.0
+)
+
+// RefuelPlanRequest pede o plano de abastecimento para uma rota salva (route_hist_id + route_index)
+// ou para a rota entre os pontos informados (origem, paradas e destino, na ordem).
+type RefuelPlanRequest struct {
+	RouteHistID     int64         `json:"route_hist_id"`
+	RouteIndex      int64         `json:"route_index"`
+	Points          []MatrixPoint `json:"points"`
+	TankCapacity    float64       `json:"tank_capacity" validate:"required,gt=0"`
+	CurrentLevel    float64       `json:"current_level" validate:"gte=0"`
+	ConsumptionCity float64       `json:"consumptionCity"`
+	ConsumptionHwy  float64       `json:"consumptionHwy"`
+	ReservePercent  float64       `json:"reserve_percent" validate:"gte=0,lt=100"`
+	Tolerance       float64       `json:"tolerance"`
+	Price           float64       `json:"price"` // Sem preço, usa a média regional da ANP
+	Type            string        `json:"type" validate:"omitempty,oneof=Truck Bus Auto Motorcycle truck bus auto motorcycle"`
+	DepartureTime   *time.Time    `json:"departure_time"`
+	UserID          int64         `json:"-"`
+}
+
+type RefuelPlanResponse struct {
+	Distance           Distance     `json:"distance"`
+	AverageConsumption float64      `json:"average_consumption"`
+	RequiredLiters     float64      `json:"required_liters"`
+	TotalLiters        float64      `json:"total_liters"`
+	TotalCost          float64      `json:"total_cost"`
+	ArrivalLevel       float64      `json:"arrival_level"`
+	Stops              []RefuelStop `json:"stops"`
+	Warning            string       `json:"warning,omitempty"`
+}
+
+// RefuelStop é uma parada recomendada: posto, distância da origem, combustível na chegada e litros a comprar
+type RefuelStop struct {
+	Order              int        `json:"order"`
+	Station            GasStation `json:"station"`
+	DistanceFromOrigin float64    `json:"distance_from_origin_km"`
+	LevelOnArrival     float64    `json:"level_on_arrival"`
+	Liters             float64    `json:"liters"`
+	Price              float64    `json:"price,omitempty"`
+	Cost               float64    `json:"cost,omitempty"`
+}
+
+// refuelCandidate é um posto casado na rota com a distância (m) desde a origem
+type refuelCandidate struct {
+	station  GasStation
+	distance float64
+}
+
+// PlanRefuel recomenda as paradas de abastecimento ao longo da rota. O consumo por km vem da divisão
+// cidade/rodovia da rota; a cada parada o plano compra só o necessário para chegar a um posto mais barato
+// ao alcance, ou enche o tanque quando não há posto mais barato à frente. Sem preço nos postos, prefere o
+// posto mais distante ao alcance para reduzir o número de paradas.
+func (s *Service) PlanRefuel(ctx context.Context, data RefuelPlanRequest) (RefuelPlanResponse, error) {
+	if data.CurrentLevel > data.TankCapacity {
+		return RefuelPlanResponse{}, errors.New("current_level maior que a capacidade do tanque")
+	}
+	if data.ConsumptionCity <= 0 && data.ConsumptionHwy <= 0 {
+		return RefuelPlanResponse{}, errors.New("informe consumptionCity ou consumptionHwy")
+	}
+	reservePercent := data.ReservePercent
+	if reservePercent == 0 {
+		reservePercent = defaultRefuelReservePercent
+	}
+	tolerance := data.Tolerance
+	if tolerance <= 0 {
+		tolerance = defaultRefuelTolerance
+	}
+	if tolerance > maxRefuelTolerance {
+		tolerance = maxRefuelTolerance
+	}
+
+	// O planejador nasceu para caminhões; sem tipo informado segue como veículo a diesel
+	if data.Type == "" {
+		data.Type = "Truck"
+	}
+
+	route, err := s.refuelRoute(ctx, data)
+	if err != nil {
+		return RefuelPlanResponse{}, err
+	}
+	if route.Distance <= 0 {
+		return RefuelPlanResponse{}, errors.New("rota sem distância para planejar abastecimento")
+	}
+
+	if data.Price <= 0 {
+		data.Price = s.regionalFuelPrice(ctx, route, data.Type)
+	}
+	split := fuelSplitForRoute(route, data.Price, data.ConsumptionCity, data.ConsumptionHwy)
+	litersPerMeter := (split.LitersCity + split.LitersHwy) / route.Distance
+
+	candidates, err := s.refuelCandidates(ctx, route, tolerance, data.DepartureTime)
+	if err != nil {
+		return RefuelPlanResponse{}, err
+	}
+	if !isDieselVehicle(data.Type) {
+		// Os preços dos postos são do diesel e não servem para comparar postos para veículos leves
+		for i := range candidates {
+			candidates[i].station.Price = nil
+		}
+	}
+
+	distText, distVal := formatDistance(route.Distance)
+	response := RefuelPlanResponse{
+		Distance:       Distance{Text: distText, Value: distVal},
+		RequiredLiters: roundCents(split.LitersCity + split.LitersHwy),
+		Stops:          []RefuelStop{},
+	}
+	if litersPerMeter > 0 {
+		response.AverageConsumption = roundCents(1 / (litersPerMeter * 1000))
+	}
+
+	reserve := data.TankCapacity * reservePercent / 100
+	level := data.CurrentLevel
+	position := 0.0
+	current := -1
+
+	for {
+		toDestination := (route.Distance - position) * litersPerMeter
+		if level-toDestination >= reserve {
+			break
+		}
+
+		if current >= 0 {
+			stop := planRefuelAt(candidates, current, level, reserve, data.TankCapacity, litersPerMeter, route.Distance)
+			// Com combustível suficiente para um posto mais barato à frente, o posto atual é só passagem
+			if liters := stop - level; liters > 0.01 {
+				price := data.Price
+				if p := candidates[current].station.Price; p != nil {
+					price = *p
+				}
+				response.Stops = append(response.Stops, RefuelStop{
+					Order:              len(response.Stops) + 1,
+					Station:            candidates[current].station,
+					DistanceFromOrigin: roundCents(position / 1000),
+					LevelOnArrival:     roundCents(level),
+					Liters:             roundCents(liters),
+					Price:              price,
+					Cost:               roundCents(liters * price),
+				})
+				response.TotalLiters = roundCents(response.TotalLiters + liters)
+				response.TotalCost = roundCents(response.TotalCost + liters*price)
+				level = stop
+			}
+			if level-toDestination >= reserve {
+				break
+			}
+		}
+
+		next := nextRefuelStation(candidates, current, position, (level-reserve)/litersPerMeter)
+		if next < 0 {
+			response.Warning = "não há postos suficientes ao longo da rota para completar o trajeto com a reserva informada"
+			break
+		}
+		level -= (candidates[next].distance - position) * litersPerMeter
+		position = candidates[next].distance
+		current = next
+	}
+
+	response.ArrivalLevel = roundCents(level - (route.Distance-position)*litersPerMeter)
+	return response, nil
+}
+
+// planRefuelAt devolve o nível do tanque ao sair do posto atual: o suficiente para chegar ao primeiro
+// posto mais barato ao alcance de um tanque cheio, o suficiente para o destino, ou o tanque cheio
+func planRefuelAt(candidates []refuelCandidate, current int, level, reserve, capacity, litersPerMeter, routeDistance float64) float64 {
+	position := candidates[current].distance
+	maxRange := (capacity - reserve) / litersPerMeter
+	price := candidates[current].station.Price
+
+	for i := current + 1; i < len(candidates); i++ {
+		if candidates[i].distance-position > maxRange {
+			break
+		}
+		if cheaperStation(candidates[i].station.Price, price) {
+			return clampLevel(reserve+(candidates[i].distance-position)*litersPerMeter, level, capacity)
+		}
+	}
+	if routeDistance-position <= maxRange {
+		return clampLevel(reserve+(routeDistance-position)*litersPerMeter, level, capacity)
+	}
+	return capacity
+}
+
+// nextRefuelStation escolhe, entre os postos ao alcance, o mais barato (empate: o mais distante).
+// Postos sem preço só são escolhidos quando nenhum posto com preço está ao alcance.
+func nextRefuelStation(candidates []refuelCandidate, current int, position, reach float64) int {
+	best := -1
+	for i := current + 1; i < len(candidates); i++ {
+		if candidates[i].distance <= position {
+			continue
+		}
+		if candidates[i].distance-position > reach {
+			break
+		}
+		if best < 0 || cheaperStation(candidates[i].station.Price, candidates[best].station.Price) || !cheaperStation(candidates[best].station.Price, candidates[i].station.Price) {
+			best = i
+		}
+	}
+	return best
+}
+
+// cheaperStation informa se o preço a é menor que b; preço desconhecido é tratado como o mais caro
+func cheaperStation(a, b *float64) bool {
+	if a == nil {
+		return false
+	}
+	if b == nil {
+		return true
+	}
+	return *a < *b
+}
+
+func clampLevel(target, level, capacity float64) float64 {
+	if target < level {
+		return level
+	}
+	if target > capacity {
+		return capacity
+	}
+	return target
+}
+
+// refuelRoute carrega a rota escolhida do histórico do usuário ou calcula a rota entre os pontos informados
+func (s *Service) refuelRoute(ctx context.Context, data RefuelPlanRequest) (OSRMRoute, error) {
+	if data.RouteHistID > 0 {
+		routeHist, err := s.routeHistForUser(ctx, data.RouteHistID, data.UserID)
+		if err != nil {
+			return OSRMRoute{}, err
+		}
+		route, err := chosenRoute(routeHist.Response, data.RouteIndex)
+		if err != nil {
+			return OSRMRoute{}, err
+		}
+		if route.Polyline == "" {
+			return OSRMRoute{}, errors.New("rota salva sem polyline; calcule a rota com include_polyline")
+		}
+		return OSRMRoute{Geometry: route.Polyline, Distance: route.Summary.Distance.Value, Duration: route.Summary.Duration.Value}, nil
+	}
+
+	if len(data.Points) < 2 {
+		return OSRMRoute{}, errors.New("informe route_hist_id ou ao menos dois pontos")
+	}
+	locs, _, err := s.resolveMatrixPoints(ctx, data.Points)
+	if err != nil {
+		return OSRMRoute{}, err
+	}
+	resp, err := s.engineRoute(ctx, 30*time.Second, RouteRequest{Coordinates: locs})
+	if err != nil {
+		return OSRMRoute{}, fmt.Errorf("erro ao calcular rota: %w", err)
+	}
+	return resp.Routes[0], nil
+}
+
+// refuelCandidates lista os postos próximos à rota, com a chegada estimada, ordenados pela distância desde a origem
+func (s *Service) refuelCandidates(ctx context.Context, route OSRMRoute, tolerance float64, departure *time.Time) ([]refuelCandidate, error) {
+	snap, err := s.POIIndex.current(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao consultar postos: %w", err)
+	}
+	geometry, err := routeGeometryFromPolyline(route.Geometry)
+	if err != nil {
+		return nil, err
+	}
+	timeline := newRouteTimeline(route, geometry.Length(), departure)
+
+	var candidates []refuelCandidate
+	for _, m := range geometry.match(snap.stationGrid, snap.stationPositions, tolerance) {
+		station := snap.stations[m.Index]
+		arrival := timeline.arrivalAtAlong(m.Along)
+		station.Arrival = &arrival
+		candidates = append(candidates, refuelCandidate{station: station, distance: arrival.DistanceMeters})
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].distance < candidates[j].distance
+	})
+	return candidates, nil
+}
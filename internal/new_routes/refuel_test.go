@@ -0,0 +1,155 @@
+package new_routes
+
+import "testing"
+
+func refuelPrice(v float64) *float64 {
+	return &v
+}
+
+func TestPlanRefuelAt(t *testing.T) {
+	// 2 km/l, tanque de 100 l e reserva de 10 l: alcance de 180 km com o tanque cheio
+	const (
+		litersPerMeter = 0.0005
+		reserve        = 10.0
+		capacity       = 100.0
+	)
+
+	tests := []struct {
+		name          string
+		candidates    []refuelCandidate
+		level         float64
+		routeDistance float64
+		want          float64
+	}{
+		{
+			name: "compra só o necessário até o posto mais barato",
+			candidates: []refuelCandidate{
+				{station: GasStation{Price: refuelPrice(6)}, distance: 0},
+				{station: GasStation{Price: refuelPrice(5)}, distance: 100000},
+			},
+			level:         20,
+			routeDistance: 500000,
+			want:          60,
+		},
+		{
+			name: "mantém o nível quando já chega ao posto mais barato",
+			candidates: []refuelCandidate{
+				{station: GasStation{Price: refuelPrice(6)}, distance: 0},
+				{station: GasStation{Price: refuelPrice(5)}, distance: 100000},
+			},
+			level:         70,
+			routeDistance: 500000,
+			want:          70,
+		},
+		{
+			name: "compra só o necessário até o destino ao alcance",
+			candidates: []refuelCandidate{
+				{station: GasStation{Price: refuelPrice(5)}, distance: 0},
+				{station: GasStation{Price: refuelPrice(6)}, distance: 50000},
+			},
+			level:         20,
+			routeDistance: 150000,
+			want:          85,
+		},
+		{
+			name: "enche o tanque sem posto mais barato ao alcance",
+			candidates: []refuelCandidate{
+				{station: GasStation{Price: refuelPrice(6)}, distance: 0},
+				{station: GasStation{Price: refuelPrice(5)}, distance: 200000},
+			},
+			level:         20,
+			routeDistance: 500000,
+			want:          capacity,
+		},
+		{
+			name: "posto sem preço não é considerado mais barato",
+			candidates: []refuelCandidate{
+				{station: GasStation{Price: refuelPrice(6)}, distance: 0},
+				{station: GasStation{}, distance: 100000},
+			},
+			level:         20,
+			routeDistance: 500000,
+			want:          capacity,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := planRefuelAt(tt.candidates, 0, tt.level, reserve, capacity, litersPerMeter, tt.routeDistance)
+			if got != tt.want {
+				t.Errorf("planRefuelAt() = %v, esperado %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNextRefuelStation(t *testing.T) {
+	tests := []struct {
+		name       string
+		candidates []refuelCandidate
+		current    int
+		position   float64
+		reach      float64
+		want       int
+	}{
+		{
+			name: "escolhe o mais barato ao alcance",
+			candidates: []refuelCandidate{
+				{station: GasStation{Price: refuelPrice(6)}, distance: 50000},
+				{station: GasStation{Price: refuelPrice(5.5)}, distance: 100000},
+				{station: GasStation{}, distance: 150000},
+				{station: GasStation{Price: refuelPrice(5)}, distance: 250000},
+			},
+			current: -1,
+			reach:   200000,
+			want:    1,
+		},
+		{
+			name: "empate de preço fica com o mais distante",
+			candidates: []refuelCandidate{
+				{station: GasStation{Price: refuelPrice(6)}, distance: 50000},
+				{station: GasStation{Price: refuelPrice(6)}, distance: 100000},
+			},
+			current: -1,
+			reach:   200000,
+			want:    1,
+		},
+		{
+			name: "sem preço prefere o mais distante",
+			candidates: []refuelCandidate{
+				{station: GasStation{}, distance: 50000},
+				{station: GasStation{}, distance: 100000},
+			},
+			current: -1,
+			reach:   200000,
+			want:    1,
+		},
+		{
+			name: "ignora postos já percorridos",
+			candidates: []refuelCandidate{
+				{station: GasStation{Price: refuelPrice(4)}, distance: 50000},
+				{station: GasStation{Price: refuelPrice(6)}, distance: 100000},
+				{station: GasStation{Price: refuelPrice(7)}, distance: 150000},
+			},
+			current:  0,
+			position: 50000,
+			reach:    200000,
+			want:     1,
+		},
+		{
+			name: "nenhum posto ao alcance",
+			candidates: []refuelCandidate{
+				{station: GasStation{Price: refuelPrice(6)}, distance: 50000},
+			},
+			current: -1,
+			reach:   40000,
+			want:    -1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := nextRefuelStation(tt.candidates, tt.current, tt.position, tt.reach); got != tt.want {
+				t.Errorf("nextRefuelStation() = %d, esperado %d", got, tt.want)
+			}
+		})
+	}
+}
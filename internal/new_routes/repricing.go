@@ -0,0 +1,648 @@
+package new_routes
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	db "geolocation/db/sqlc"
+	"geolocation/pkg/email"
+	"log"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	RepriceSourceFavorite   = ExportSourceFavorite
+	RepriceSourceEnterprise = "enterprise"
+
+	// defaultRepricingInterval é a frequência padrão do job que reprecifica as rotas gravadas
+	defaultRepricingInterval = 24 * time.Hour
+	// defaultRepricingThreshold é a variação (%) do custo total a partir da qual a mudança é relevante e o dono é avisado
+	defaultRepricingThreshold = 5.0
+	// repricingBatchSize é o tamanho da página lida de cada tabela pelo job
+	repricingBatchSize     = 100
+	repricingEngineTimeout = 60 * time.Second
+)
+
+// RepriceRequest identifica a rota gravada a reprecificar. Source indica a tabela de origem:
+// favorite (favorite_route do usuário) ou enterprise (route_enterprise da organização).
+type RepriceRequest struct {
+	Source   string    `json:"source" validate:"required,oneof=favorite enterprise"`
+	ID       int64     `json:"id" validate:"required,gt=0"`
+	UserID   int64     `json:"-"`
+	TenantID uuid.UUID `json:"-"`
+	AccessID int64     `json:"-"`
+}
+
+// ValueChange compara um valor da resposta gravada com o valor atual
+type ValueChange struct {
+	Old     float64 `json:"old"`
+	New     float64 `json:"new"`
+	Change  float64 `json:"change"`
+	Percent float64 `json:"percent"`
+}
+
+// RouteCostChange é a diferença de uma das rotas da resposta. Distance em metros e Duration em segundos.
+// TollCost fica vazio quando a resposta gravada não trazia os custos de pedágio.
+type RouteCostChange struct {
+	RouteIndex   int          `json:"route_index"`
+	RouteType    string       `json:"route_type"`
+	Repriced     bool         `json:"repriced"`
+	TollCost     *ValueChange `json:"toll_cost,omitempty"`
+	FuelCost     ValueChange  `json:"fuel_cost"`
+	TotalCost    ValueChange  `json:"total_cost"`
+	Distance     ValueChange  `json:"distance"`
+	Duration     ValueChange  `json:"duration"`
+	FuelPrice    ValueChange  `json:"fuel_price"`
+	AddedTolls   []string     `json:"added_tolls,omitempty"`
+	RemovedTolls []string     `json:"removed_tolls,omitempty"`
+}
+
+// RouteRepricingDiff reúne as diferenças das rotas; Material indica que o custo total de alguma rota
+// variou pelo menos ThresholdPercent
+type RouteRepricingDiff struct {
+	Routes           []RouteCostChange `json:"routes"`
+	Material         bool              `json:"material"`
+	ThresholdPercent float64           `json:"threshold_percent"`
+}
+
+// RepriceResponse é o resultado da reprecificação. Changed indica diferença de custo ou de praças; a versão só é
+// gravada (e a resposta atualizada) quando a diferença é relevante (Diff.Material). Version é 0 caso contrário.
+type RepriceResponse struct {
+	Source    string             `json:"source"`
+	ID        int64              `json:"id"`
+	Version   int32              `json:"version,omitempty"`
+	Changed   bool               `json:"changed"`
+	Diff      RouteRepricingDiff `json:"diff"`
+	Response  FinalOutput        `json:"response"`
+	versionID int64
+}
+
+type RouteVersionResponse struct {
+	Version    int32              `json:"version"`
+	Material   bool               `json:"material"`
+	Diff       RouteRepricingDiff `json:"diff"`
+	NotifiedAt *time.Time         `json:"notified_at,omitempty"`
+	CreatedAt  time.Time          `json:"created_at"`
+}
+
+// storedRoute é a linha de qualquer uma das tabelas de rotas gravadas
+type storedRoute struct {
+	Source      string
+	ID          int64
+	Origin      string
+	Destination string
+	Waypoints   sql.NullString
+	Request     json.RawMessage
+	Response    json.RawMessage
+}
+
+// repricingVehicle são os parâmetros do veículo usados no cálculo original, lidos do request gravado
+type repricingVehicle struct {
+	Type            string  `json:"type"`
+	Axles           int64   `json:"axles"`
+	Price           float64 `json:"price"`
+	ConsumptionCity float64 `json:"consumptionCity"`
+	ConsumptionHwy  float64 `json:"consumptionHwy"`
+	Locale          string  `json:"locale"`
+	VehicleInfo
+}
+
+// RepriceRoute reavalia a rota gravada com as tarifas de pedágio e o preço do diesel atuais. Havendo
+// diferença relevante no custo, grava uma nova versão com o diff e atualiza a resposta gravada.
+func (s *Service) RepriceRoute(ctx context.Context, data RepriceRequest) (RepriceResponse, error) {
+	stored, err := s.storedRoute(ctx, data)
+	if err != nil {
+		return RepriceResponse{}, err
+	}
+	return s.repriceStoredRoute(ctx, stored)
+}
+
+// GetRouteVersions lista as versões gravadas pela reprecificação, da mais recente para a mais antiga
+func (s *Service) GetRouteVersions(ctx context.Context, data RepriceRequest) ([]RouteVersionResponse, error) {
+	if _, err := s.storedRoute(ctx, data); err != nil {
+		return nil, err
+	}
+
+	versions, err := s.InterfaceService.GetRouteVersions(ctx, db.GetRouteVersionsParams{Source: data.Source, RouteID: data.ID})
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar versões da rota: %w", err)
+	}
+
+	result := make([]RouteVersionResponse, 0, len(versions))
+	for _, v := range versions {
+		item := RouteVersionResponse{Version: v.Version, Material: v.Material, CreatedAt: v.CreatedAt}
+		if err := json.Unmarshal(v.Diff, &item.Diff); err != nil {
+			log.Printf("Erro ao ler diff da versão %d: %v", v.ID, err)
+		}
+		if v.NotifiedAt.Valid {
+			item.NotifiedAt = &v.NotifiedAt.Time
+		}
+		result = append(result, item)
+	}
+	return result, nil
+}
+
+// storedRoute busca a rota gravada. Favoritas só são vistas pelo dono e rotas de empresa pela organização.
+func (s *Service) storedRoute(ctx context.Context, data RepriceRequest) (storedRoute, error) {
+	var stored storedRoute
+	var err error
+	switch data.Source {
+	case RepriceSourceFavorite:
+		var favorite db.FavoriteRoute
+		favorite, err = s.InterfaceService.GetFavoriteRouteByID(ctx, db.GetFavoriteRouteByIDParams{ID: data.ID, IDUser: data.UserID})
+		stored = storedRoute{ID: favorite.ID, Origin: favorite.Origin, Destination: favorite.Destination, Waypoints: favorite.Waypoints, Response: favorite.Response}
+	case RepriceSourceEnterprise:
+		var enterprise db.RouteEnterprise
+		enterprise, err = s.InterfaceRouteEnterprise.GetRouteEnterpriseByID(ctx, db.GetRouteEnterpriseByIDParams{ID: data.ID, TenantID: data.TenantID, AccessID: data.AccessID})
+		stored = storedRoute{ID: enterprise.ID, Origin: enterprise.Origin, Destination: enterprise.Destination, Waypoints: enterprise.Waypoints, Response: enterprise.Response}
+	default:
+		return storedRoute{}, fmt.Errorf("origem de rota inválida: %s", data.Source)
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		return storedRoute{}, errors.New("rota não encontrada")
+	}
+	if err != nil {
+		return storedRoute{}, fmt.Errorf("erro ao buscar rota: %w", err)
+	}
+	stored.Source = data.Source
+	return stored, nil
+}
+
+func (s *Service) repriceStoredRoute(ctx context.Context, stored storedRoute) (RepriceResponse, error) {
+	var output FinalOutput
+	if err := json.Unmarshal(stored.Response, &output); err != nil {
+		return RepriceResponse{}, fmt.Errorf("erro ao ler rota: %w", err)
+	}
+
+	vehicle := s.repricingVehicle(ctx, stored, output)
+	updated, diff := s.repriceOutput(ctx, output, vehicle)
+	result := RepriceResponse{Source: stored.Source, ID: stored.ID, Diff: diff, Response: updated, Changed: diff.changed()}
+	// Variações pequenas (centavos de pedágio, segundos de duração) não geram versão: o job roda todo dia
+	if !result.Changed || !diff.Material {
+		return result, nil
+	}
+
+	responseJSON, err := json.Marshal(updated)
+	if err != nil {
+		return RepriceResponse{}, err
+	}
+	diffJSON, err := json.Marshal(diff)
+	if err != nil {
+		return RepriceResponse{}, err
+	}
+
+	version, err := s.InterfaceService.CreateRouteVersion(ctx, db.CreateRouteVersionParams{
+		Source:   stored.Source,
+		RouteID:  stored.ID,
+		Response: responseJSON,
+		Diff:     diffJSON,
+		Material: diff.Material,
+	})
+	if err != nil {
+		return RepriceResponse{}, fmt.Errorf("erro ao gravar versão da rota: %w", err)
+	}
+
+	switch stored.Source {
+	case RepriceSourceFavorite:
+		err = s.InterfaceService.UpdateFavoriteRouteResponse(ctx, db.UpdateFavoriteRouteResponseParams{ID: stored.ID, Response: responseJSON})
+	case RepriceSourceEnterprise:
+		err = s.InterfaceRouteEnterprise.UpdateRouteEnterpriseResponse(ctx, db.UpdateRouteEnterpriseResponseParams{ID: stored.ID, Response: responseJSON})
+	}
+	if err != nil {
+		return RepriceResponse{}, fmt.Errorf("erro ao atualizar rota: %w", err)
+	}
+
+	result.Version = version.Version
+	result.versionID = version.ID
+	return result, nil
+}
+
+// repricingVehicle lê o veículo do request da mesma rota em saved_routes, já que favoritas e rotas de empresa
+// não guardam o request. O que faltar vem da própria resposta.
+func (s *Service) repricingVehicle(ctx context.Context, stored storedRoute, output FinalOutput) repricingVehicle {
+	request := stored.Request
+	if len(request) == 0 {
+		var err error
+		request, err = s.InterfaceService.GetSavedRouteRequestByRoute(ctx, db.GetSavedRouteRequestByRouteParams{
+			Origin:      stored.Origin,
+			Destination: stored.Destination,
+			Waypoints:   stored.Waypoints,
+		})
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			log.Printf("Erro ao buscar request da rota %s %d: %v", stored.Source, stored.ID, err)
+		}
+	}
+
+	var vehicle repricingVehicle
+	if len(request) > 0 {
+		if err := json.Unmarshal(request, &vehicle); err != nil {
+			log.Printf("Erro ao ler request da rota %s %d: %v", stored.Source, stored.ID, err)
+		}
+	}
+	if vehicle.ConsumptionCity <= 0 {
+		vehicle.ConsumptionCity = output.Summary.FuelEfficiency.City
+	}
+	if vehicle.ConsumptionHwy <= 0 {
+		vehicle.ConsumptionHwy = output.Summary.FuelEfficiency.Hwy
+	}
+	if vehicle.Price <= 0 {
+		vehicle.Price = output.Summary.FuelPrice.Price
+	}
+	if vehicle.Axles <= 0 {
+		for _, route := range output.Routes {
+			if route.Costs != nil && route.Costs.Axles > 0 {
+				vehicle.Axles = int64(route.Costs.Axles)
+				break
+			}
+		}
+	}
+	if vehicle.Type == "" {
+		vehicle.Type = "Truck"
+	}
+	return vehicle
+}
+
+// repriceOutput recalcula cada rota da resposta no motor, com o mesmo perfil do cálculo original, e
+// aplica as tarifas e o preço médio regional do diesel atuais. Rotas que não puderem ser recalculadas ficam como estavam.
+func (s *Service) repriceOutput(ctx context.Context, output FinalOutput, vehicle repricingVehicle) (FinalOutput, RouteRepricingDiff) {
+	coordinates := summaryCoordinates(output.Summary)
+	alternatives := make(map[string]OSRMResponse)
+	diff := RouteRepricingDiff{ThresholdPercent: s.repricingThreshold}
+
+	updated := output
+	updated.Routes = make([]RouteOutput, len(output.Routes))
+	for i, route := range output.Routes {
+		updated.Routes[i] = route
+		change := RouteCostChange{RouteIndex: i, RouteType: route.Summary.RouteType}
+
+		osrmRoute, ok := s.repricingRoute(ctx, coordinates, route, alternatives)
+		var tolls []Toll
+		var err error
+		if ok {
+			tolls, err = s.findTollsOnRoute(ctx, osrmRoute, newTollVehicle(vehicle.Type, vehicle.Axles, vehicle.VehicleInfo), nil)
+			if err != nil {
+				log.Printf("Erro ao reprecificar pedágios da rota %d: %v", i, err)
+				ok = false
+			}
+		}
+		if !ok {
+			change.FuelCost = newValueChange(route.Summary.TotalFuelCost, route.Summary.TotalFuelCost)
+			change.TotalCost = change.FuelCost
+			change.Distance = newValueChange(route.Summary.Distance.Value, route.Summary.Distance.Value)
+			change.Duration = newValueChange(route.Summary.Duration.Value, route.Summary.Duration.Value)
+			diff.Routes = append(diff.Routes, change)
+			continue
+		}
+
+		price := s.regionalFuelPrice(ctx, osrmRoute, vehicle.Type)
+		if price <= 0 {
+			price = vehicle.Price
+		}
+		fuelSplit := fuelSplitForRoute(osrmRoute, price, vehicle.ConsumptionCity, vehicle.ConsumptionHwy)
+		repriced := applyRepricing(route, osrmRoute, tolls, fuelSplit, vehicle)
+		if len(route.Instructions) > 0 && len(osrmRoute.Legs) > 0 {
+			repriced.Instructions = s.processOSRMStepsToInstructions(osrmRoute, vehicle.Locale)
+		}
+		updated.Routes[i] = repriced
+		if i == 0 {
+			updated.Summary.FuelPrice.Price = price
+		}
+
+		change.Repriced = true
+		oldFuel, newFuel := route.Summary.TotalFuelCost, repriced.Summary.TotalFuelCost
+		change.FuelCost = newValueChange(oldFuel, newFuel)
+		change.TotalCost = newValueChange(oldFuel, newFuel)
+		if oldToll, known := routeTollCost(route); known {
+			newToll := tollsCost(tolls)
+			tollChange := newValueChange(oldToll, newToll)
+			change.TollCost = &tollChange
+			change.TotalCost = newValueChange(oldToll+oldFuel, newToll+newFuel)
+			change.AddedTolls, change.RemovedTolls = tollListChanges(routeTolls(route), tolls)
+		}
+		change.Distance = newValueChange(route.Summary.Distance.Value, repriced.Summary.Distance.Value)
+		change.Duration = newValueChange(route.Summary.Duration.Value, repriced.Summary.Duration.Value)
+		change.FuelPrice = newValueChange(output.Summary.FuelPrice.Price, price)
+		if math.Abs(change.TotalCost.Percent) >= s.repricingThreshold {
+			diff.Material = true
+		}
+		diff.Routes = append(diff.Routes, change)
+	}
+	return updated, diff
+}
+
+// repricingRoute escolhe, entre as alternativas atuais do mesmo perfil (route_type), a de distância mais
+// próxima da gravada. Sem resposta do motor, reaproveita a polyline gravada.
+func (s *Service) repricingRoute(ctx context.Context, coordinates []Location, route RouteOutput, alternatives map[string]OSRMResponse) (OSRMRoute, bool) {
+	routeType := route.Summary.RouteType
+	resp, cached := alternatives[routeType]
+	if !cached && len(coordinates) >= 2 {
+		var err error
+		resp, err = s.engineAlternatives(ctx, repricingEngineTimeout, routeTypeRequest(coordinates, routeType), 3)
+		if err != nil {
+			log.Printf("Erro ao recalcular rota (%s) para reprecificação: %v", routeType, err)
+		}
+		alternatives[routeType] = resp
+	}
+
+	if len(resp.Routes) > 0 {
+		return closestRoute(resp.Routes, route.Summary.Distance.Value), true
+	}
+
+	polyline := route.Polyline
+	if polyline == "" {
+		polyline = route.Summary.Polyline
+	}
+	if polyline == "" {
+		return OSRMRoute{}, false
+	}
+	return OSRMRoute{Distance: route.Summary.Distance.Value, Duration: route.Summary.Duration.Value, Geometry: polyline}, true
+}
+
+// routeTypeRequest monta a requisição com as mesmas exclusões usadas no cálculo do perfil (route_type)
+func routeTypeRequest(coordinates []Location, routeType string) RouteRequest {
+	req := RouteRequest{Coordinates: coordinates}
+	switch routeType {
+	case "cheapest":
+		req.Exclude = []string{"toll"}
+	case "efficient":
+		req.Exclude = []string{"motorway"}
+	case "fatest", "fastest":
+		req.AllowUTurn = true
+	}
+	return req
+}
+
+// closestRoute devolve a rota de distância mais próxima da informada (em metros)
+func closestRoute(routes []OSRMRoute, distance float64) OSRMRoute {
+	best := routes[0]
+	for _, candidate := range routes[1:] {
+		if math.Abs(candidate.Distance-distance) < math.Abs(best.Distance-distance) {
+			best = candidate
+		}
+	}
+	return best
+}
+
+// applyRepricing atualiza distância, duração, pedágios e combustível da rota gravada, mantendo só os
+// blocos que a resposta original já trazia (custos, lista de pedágios, polyline)
+func applyRepricing(route RouteOutput, osrmRoute OSRMRoute, tolls []Toll, fuelSplit FuelSplit, vehicle repricingVehicle) RouteOutput {
+	distText, distVal := formatDistance(osrmRoute.Distance)
+	durText, durVal := formatDuration(osrmRoute.Duration)
+	totalTollCost := tollsCost(tolls)
+
+	route.Summary.Distance = Distance{Text: distText, Value: distVal}
+	route.Summary.Duration = Duration{Text: durText, Value: durVal}
+	route.Summary.HasTolls = len(tolls) > 0
+	route.Summary.TotalFuelCost = fuelSplit.TotalCost()
+	route.Summary.FuelSplit = &fuelSplit
+	route.Summary.Emissions = routeEmissions(fuelSplit, vehicle.Type, vehicle.Axles, vehicle.VehicleInfo)
+	if route.Summary.Tolls != nil || route.Summary.TotalTolls != 0 {
+		route.Summary.Tolls = tolls
+		route.Summary.TotalTolls = totalTollCost
+	}
+	if route.Summary.Polyline != "" {
+		route.Summary.Polyline = osrmRoute.Geometry
+	}
+	if route.Polyline != "" {
+		route.Polyline = osrmRoute.Geometry
+	}
+	if route.Tolls != nil {
+		route.Tolls = tolls
+	}
+
+	if route.Costs != nil {
+		costs := *route.Costs
+		costs.setTollCosts(tolls)
+		if vehicle.ConsumptionCity > 0 {
+			costs.FuelInTheCity = math.Round((fuelSplit.Price / vehicle.ConsumptionCity) * (distVal / 1000))
+		}
+		if vehicle.ConsumptionHwy > 0 {
+			costs.FuelInTheHwy = math.Round((fuelSplit.Price / vehicle.ConsumptionHwy) * (distVal / 1000))
+		}
+		costs.FuelSplit = &fuelSplit
+		route.Costs = &costs
+	}
+	return route
+}
+
+// routeTollCost devolve o custo de pedágio gravado; known é falso quando a resposta não trazia pedágios
+func routeTollCost(route RouteOutput) (float64, bool) {
+	switch {
+	case route.Costs != nil:
+		return route.Costs.TagAndCash, true
+	case route.Tolls != nil:
+		return tollsCost(route.Tolls), true
+	case route.Summary.Tolls != nil || route.Summary.TotalTolls != 0:
+		return route.Summary.TotalTolls, true
+	}
+	return 0, false
+}
+
+func routeTolls(route RouteOutput) []Toll {
+	if route.Tolls != nil {
+		return route.Tolls
+	}
+	return route.Summary.Tolls
+}
+
+func tollsCost(tolls []Toll) float64 {
+	var total float64
+	for _, toll := range tolls {
+		total += toll.PaidCost
+	}
+	return roundCents(total)
+}
+
+// tollListChanges compara as praças pelo ID e devolve os nomes das que entraram e saíram da rota
+func tollListChanges(before, after []Toll) ([]string, []string) {
+	seen := make(map[int]bool, len(before))
+	for _, toll := range before {
+		seen[toll.ID] = true
+	}
+	var added, removed []string
+	current := make(map[int]bool, len(after))
+	for _, toll := range after {
+		current[toll.ID] = true
+		if !seen[toll.ID] {
+			added = append(added, toll.Name)
+		}
+	}
+	for _, toll := range before {
+		if !current[toll.ID] {
+			removed = append(removed, toll.Name)
+		}
+	}
+	sort.Strings(added)
+	sort.Strings(removed)
+	return added, removed
+}
+
+// newValueChange arredonda a diferença em centavos; sem valor anterior, qualquer valor novo conta como 100%
+func newValueChange(before, after float64) ValueChange {
+	change := ValueChange{Old: before, New: after, Change: roundCents(after - before)}
+	switch {
+	case before != 0:
+		change.Percent = roundCents(change.Change / before * 100)
+	case change.Change != 0:
+		change.Percent = 100
+	}
+	return change
+}
+
+// changed indica se alguma rota teve diferença de custo ou de praças; distância e duração variam a cada
+// cálculo do motor e sozinhas não contam
+func (d RouteRepricingDiff) changed() bool {
+	for _, route := range d.Routes {
+		if !route.Repriced {
+			continue
+		}
+		if route.TotalCost.Change != 0 || route.FuelCost.Change != 0 || len(route.AddedTolls) > 0 || len(route.RemovedTolls) > 0 {
+			return true
+		}
+		if route.TollCost != nil && route.TollCost.Change != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// summaryCoordinates monta origem, paradas e destino da resposta gravada
+func summaryCoordinates(summary Summary) []Location {
+	coordinates := []Location{summary.LocationOrigin.Location}
+	var stops []GeocodeResult
+	if raw, err := json.Marshal(summary.AllStoppingPoints); err == nil {
+		_ = json.Unmarshal(raw, &stops)
+	}
+	for _, stop := range stops {
+		coordinates = append(coordinates, stop.Location)
+	}
+	return append(coordinates, summary.LocationDestination.Location)
+}
+
+// RepriceStoredRoutes reprecifica as rotas favoritas e as rotas de empresa ativas. As rotas de saved_routes
+// (uma por cálculo) ficam de fora. Falhas numa rota são registradas no log e não interrompem as demais.
+func (s *Service) RepriceStoredRoutes(ctx context.Context) error {
+	return errors.Join(
+		s.repriceFavoriteRoutes(ctx),
+		s.repriceEnterpriseRoutes(ctx),
+	)
+}
+
+func (s *Service) repriceFavoriteRoutes(ctx context.Context) error {
+	var lastID int64
+	for {
+		rows, err := s.InterfaceService.GetFavoriteRoutesForRepricing(ctx, db.GetFavoriteRoutesForRepricingParams{ID: lastID, Limit: repricingBatchSize})
+		if err != nil {
+			return fmt.Errorf("erro ao listar rotas favoritas: %w", err)
+		}
+		for _, row := range rows {
+			lastID = row.ID
+			result, err := s.repriceStoredRoute(ctx, storedRoute{
+				Source:      RepriceSourceFavorite,
+				ID:          row.ID,
+				Origin:      row.Origin,
+				Destination: row.Destination,
+				Waypoints:   row.Waypoints,
+				Response:    row.Response,
+			})
+			if err != nil {
+				log.Printf("Erro ao reprecificar rota favorita %d: %v", row.ID, err)
+				continue
+			}
+			if result.versionID > 0 && result.Diff.Material {
+				s.notifyRepricing(ctx, row, result)
+			}
+		}
+		if len(rows) < repricingBatchSize {
+			return nil
+		}
+	}
+}
+
+func (s *Service) repriceEnterpriseRoutes(ctx context.Context) error {
+	var lastID int64
+	for {
+		rows, err := s.InterfaceRouteEnterprise.GetRouteEnterprisesForRepricing(ctx, db.GetRouteEnterprisesForRepricingParams{ID: lastID, Limit: repricingBatchSize})
+		if err != nil {
+			return fmt.Errorf("erro ao listar rotas de empresa: %w", err)
+		}
+		for _, row := range rows {
+			lastID = row.ID
+			_, err := s.repriceStoredRoute(ctx, storedRoute{
+				Source:      RepriceSourceEnterprise,
+				ID:          row.ID,
+				Origin:      row.Origin,
+				Destination: row.Destination,
+				Waypoints:   row.Waypoints,
+				Response:    row.Response,
+			})
+			if err != nil {
+				log.Printf("Erro ao reprecificar rota de empresa %d: %v", row.ID, err)
+			}
+		}
+		if len(rows) < repricingBatchSize {
+			return nil
+		}
+	}
+}
+
+// notifyRepricing avisa por e-mail o dono da rota favorita e marca a versão como notificada
+func (s *Service) notifyRepricing(ctx context.Context, row db.GetFavoriteRoutesForRepricingRow, result RepriceResponse) {
+	if s.SendEmail == nil || row.UserEmail == "" {
+		return
+	}
+
+	placeHolder := email.EmailPlaceHolder{
+		NameProvider:     row.UserName,
+		RouteOrigin:      row.Origin,
+		RouteDestination: row.Destination,
+	}
+	for _, change := range result.Diff.Routes {
+		if !change.Repriced {
+			continue
+		}
+		placeHolder.RouteCostChanges = append(placeHolder.RouteCostChanges, email.RouteCostChangePlaceHolder{
+			RouteType: change.RouteType,
+			OldCost:   fmt.Sprintf("R$ %.2f", change.TotalCost.Old),
+			NewCost:   fmt.Sprintf("R$ %.2f", change.TotalCost.New),
+			Change:    fmt.Sprintf("%+.2f%%", change.TotalCost.Percent),
+			Distance:  fmt.Sprintf("%.0f km", change.Distance.New/1000),
+			Duration:  newDuration(change.Duration.New).Text,
+		})
+	}
+
+	tmp, err := s.SendEmail.NewTemplate(placeHolder, "route_repricing.html")
+	if err != nil {
+		log.Printf("Erro ao montar aviso de reprecificação da rota favorita %d: %v", row.ID, err)
+		return
+	}
+	if err := s.SendEmail.SendEmailNew(*tmp, row.UserEmail, "O custo da sua rota favorita mudou"); err != nil {
+		log.Printf("Erro ao enviar aviso de reprecificação da rota favorita %d: %v", row.ID, err)
+		return
+	}
+	if err := s.InterfaceService.UpdateRouteVersionNotified(ctx, result.versionID); err != nil {
+		log.Printf("Erro ao registrar aviso da versão %d: %v", result.versionID, err)
+	}
+}
+
+// WatchRepricing reprecifica as rotas gravadas periodicamente até o contexto ser cancelado
+func (s *Service) WatchRepricing(ctx context.Context) {
+	ticker := time.NewTicker(s.repricingInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.RepriceStoredRoutes(ctx); err != nil {
+				log.Printf("Erro ao reprecificar rotas gravadas: %v", err)
+			}
+		}
+	}
+}
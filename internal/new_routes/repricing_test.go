@@ -0,0 +1,111 @@
+package new_routes
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNewValueChange(t *testing.T) {
+	tests := []struct {
+		name          string
+		before, after float64
+		want          ValueChange
+	}{
+		{name: "aumento", before: 200, after: 250, want: ValueChange{Old: 200, New: 250, Change: 50, Percent: 25}},
+		{name: "redução", before: 80, after: 60, want: ValueChange{Old: 80, New: 60, Change: -20, Percent: -25}},
+		{name: "sem mudança", before: 99.9, after: 99.9, want: ValueChange{Old: 99.9, New: 99.9}},
+		{name: "arredonda em centavos", before: 3, after: 4.004, want: ValueChange{Old: 3, New: 4.004, Change: 1, Percent: 33.33}},
+		{name: "sem valor anterior conta 100%", before: 0, after: 12.5, want: ValueChange{New: 12.5, Change: 12.5, Percent: 100}},
+		{name: "zero para zero", before: 0, after: 0, want: ValueChange{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := newValueChange(tt.before, tt.after); got != tt.want {
+				t.Errorf("newValueChange(%v, %v) = %+v, want %+v", tt.before, tt.after, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyRepricing(t *testing.T) {
+	oldTolls := []Toll{{ID: 1, Name: "Praça A", PaidCost: 10}}
+	newTolls := []Toll{{ID: 1, Name: "Praça A", PaidCost: 11.5}, {ID: 2, Name: "Praça B", PaidCost: 7.25}}
+	osrmRoute := OSRMRoute{Distance: 150000, Duration: 7200, Geometry: "nova"}
+	split := FuelSplit{KmHwy: 150, LitersHwy: 50, CostHwy: 300.4, Price: 6}
+	vehicle := repricingVehicle{Type: "truck", Axles: 5, ConsumptionCity: 2, ConsumptionHwy: 3}
+
+	route := RouteOutput{
+		Summary:  RouteSummary{Tolls: oldTolls, TotalTolls: 10, Polyline: "antiga"},
+		Tolls:    oldTolls,
+		Costs:    &Costs{TagAndCash: 10, Axles: 5},
+		Polyline: "antiga",
+	}
+	got := applyRepricing(route, osrmRoute, newTolls, split, vehicle)
+
+	if got.Summary.Distance.Value != 150000 || got.Summary.Duration.Value != 7200 || !got.Summary.HasTolls {
+		t.Errorf("resumo = %+v", got.Summary)
+	}
+	if got.Summary.TotalTolls != 18.75 || !reflect.DeepEqual(got.Tolls, newTolls) || !reflect.DeepEqual(got.Summary.Tolls, newTolls) {
+		t.Errorf("pedágios = %v (total %v), want as 2 praças novas e 18.75", got.Tolls, got.Summary.TotalTolls)
+	}
+	if got.Summary.TotalFuelCost != 300 || got.Summary.FuelSplit.Price != 6 || got.Summary.Emissions == nil {
+		t.Errorf("combustível = %v, divisão %+v, emissões %v", got.Summary.TotalFuelCost, got.Summary.FuelSplit, got.Summary.Emissions)
+	}
+	if got.Polyline != "nova" || got.Summary.Polyline != "nova" {
+		t.Errorf("polylines = %q e %q, want a geometria nova", got.Polyline, got.Summary.Polyline)
+	}
+	// R$ 6/l: 2 km/l na cidade e 3 km/l na rodovia para 150 km
+	if c := got.Costs; c.TagAndCash != 18.75 || c.FuelInTheCity != 450 || c.FuelInTheHwy != 300 || c.Axles != 5 {
+		t.Errorf("custos = %+v", c)
+	}
+	if route.Costs.TagAndCash != 10 {
+		t.Error("applyRepricing() alterou os custos da rota gravada")
+	}
+
+	// Resposta gravada sem pedágios nem polyline: os campos continuam ausentes
+	bare := applyRepricing(RouteOutput{}, osrmRoute, newTolls, split, vehicle)
+	if bare.Tolls != nil || bare.Summary.Tolls != nil || bare.Summary.TotalTolls != 0 || bare.Polyline != "" || bare.Costs != nil {
+		t.Errorf("rota sem pedágios ganhou campos novos: %+v", bare)
+	}
+}
+
+func TestTollListChanges(t *testing.T) {
+	before := []Toll{{ID: 1, Name: "Praça A"}, {ID: 2, Name: "Praça B"}, {ID: 3, Name: "Praça C"}}
+	after := []Toll{{ID: 3, Name: "Praça C"}, {ID: 5, Name: "Praça E"}, {ID: 4, Name: "Praça D"}}
+
+	added, removed := tollListChanges(before, after)
+	if !reflect.DeepEqual(added, []string{"Praça D", "Praça E"}) || !reflect.DeepEqual(removed, []string{"Praça A", "Praça B"}) {
+		t.Errorf("tollListChanges() = %v, %v", added, removed)
+	}
+	// A praça é identificada pelo ID: renomear não conta como troca
+	if added, removed := tollListChanges(before[:1], []Toll{{ID: 1, Name: "Praça A (nova)"}}); added != nil || removed != nil {
+		t.Errorf("renomeada = %v, %v, want sem mudança", added, removed)
+	}
+}
+
+func TestRepricingDiffChanged(t *testing.T) {
+	tests := []struct {
+		name  string
+		route RouteCostChange
+		want  bool
+	}{
+		{name: "custo total", route: RouteCostChange{Repriced: true, TotalCost: ValueChange{Change: 0.01}}, want: true},
+		{name: "combustível", route: RouteCostChange{Repriced: true, FuelCost: ValueChange{Change: -3}}, want: true},
+		{name: "pedágio", route: RouteCostChange{Repriced: true, TollCost: &ValueChange{Change: 2}}, want: true},
+		{name: "praça nova com o mesmo custo", route: RouteCostChange{Repriced: true, AddedTolls: []string{"Praça D"}}, want: true},
+		{name: "praça removida", route: RouteCostChange{Repriced: true, RemovedTolls: []string{"Praça A"}}, want: true},
+		{name: "só distância e duração", route: RouteCostChange{Repriced: true, Distance: ValueChange{Change: 120}, Duration: ValueChange{Change: 30}}},
+		{name: "rota não reprecificada", route: RouteCostChange{TotalCost: ValueChange{Change: 50}}},
+		{name: "pedágio sem diferença", route: RouteCostChange{Repriced: true, TollCost: &ValueChange{}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diff := RouteRepricingDiff{Routes: []RouteCostChange{{Repriced: true}, tt.route}}
+			if got := diff.changed(); got != tt.want {
+				t.Errorf("changed() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
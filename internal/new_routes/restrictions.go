@@ -0,0 +1,442 @@
+package new_routes
+
+import (
+	"context"
+	"fmt"
+	db "geolocation/db/sqlc"
+	"log"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	RestrictionModeAvoid = "avoid"
+	RestrictionModeFlag  = "flag"
+
+	RestrictionHeight = "altura"
+	RestrictionWeight = "peso"
+	RestrictionLength = "comprimento"
+
+	// tarePerAxle é a tara média estimada por eixo (t) quando o peso bruto não é informado
+	tarePerAxle = 3.0
+	// restrictionBBoxMargin amplia o retângulo da rota para não perder restrições nas bordas (graus)
+	restrictionBBoxMargin = 0.01
+)
+
+// VehicleInfo identifica a composição (cavalo + carreta) ou traz as dimensões informadas manualmente.
+// Dimensões em metros e pesos em toneladas; valores explícitos têm prioridade sobre os cadastrados.
+// Eixos suspensos, rodagem, categoria ANTT e tag definem a cobrança dos pedágios.
+type VehicleInfo struct {
+	TractorUnitID   int64   `json:"tractor_unit_id"`
+	TrailerID       int64   `json:"trailer_id"`
+	Height          float64 `json:"height"`
+	Width           float64 `json:"width"`
+	Length          float64 `json:"length"`
+	GrossWeight     float64 `json:"gross_weight"`
+	CargoWeight     float64 `json:"cargo_weight"`
+	RestrictionMode string  `json:"restriction_mode"`
+	SuspendedAxles  int64   `json:"suspended_axles"`
+	SingleWheels    bool    `json:"single_wheels"`
+	TollCategory    int64   `json:"toll_category"`
+	TollTag         string  `json:"toll_tag"`
+}
+
+// RestrictionAlert é uma restrição (ponte, viaduto, túnel...) no trajeto que a composição não atende
+type RestrictionAlert struct {
+	ID           int64    `json:"id"`
+	Name         string   `json:"name"`
+	Type         string   `json:"type"`
+	Road         string   `json:"road,omitempty"`
+	City         string   `json:"city,omitempty"`
+	State        string   `json:"state,omitempty"`
+	Location     Location `json:"location"`
+	Restriction  string   `json:"restriction"`
+	Limit        float64  `json:"limit"`
+	VehicleValue float64  `json:"vehicle_value"`
+}
+
+func (v VehicleInfo) hasDimensions() bool {
+	return v.Height > 0 || v.Length > 0 || v.GrossWeight > 0
+}
+
+func (v VehicleInfo) avoid() bool {
+	return strings.ToLower(strings.TrimSpace(v.RestrictionMode)) != RestrictionModeFlag
+}
+
+// cacheKey compõe a parte da chave de cache referente ao veículo; vazio quando não há dimensões nem dados de pedágio
+func (v VehicleInfo) cacheKey() string {
+	var key string
+	if v.hasDimensions() {
+		key = fmt.Sprintf(":vehicle:%.2f:%.2f:%.2f:%s", v.Height, v.Length, v.GrossWeight, strings.ToLower(v.RestrictionMode))
+	}
+	if v.SuspendedAxles > 0 || v.SingleWheels || v.TollCategory > 0 || v.TollTag != "" {
+		key += fmt.Sprintf(":toll:%d:%t:%d:%s", v.SuspendedAxles, v.SingleWheels, v.TollCategory, strings.ToLower(v.TollTag))
+	}
+	return key
+}
+
+// userTractorUnit busca o cavalo cadastrado, desde que pertença ao usuário
+func (s *Service) userTractorUnit(ctx context.Context, id, userID int64) (db.TractorUnit, error) {
+	tractor, err := s.TractorUnitRepository.GetTractorUnitById(ctx, id)
+	if err != nil {
+		return db.TractorUnit{}, fmt.Errorf("erro ao buscar cavalo %d: %w", id, err)
+	}
+	if userID == 0 || tractor.UserID != userID {
+		return db.TractorUnit{}, fmt.Errorf("cavalo %d não encontrado", id)
+	}
+	return tractor, nil
+}
+
+// userTrailer busca a carreta cadastrada, desde que pertença ao usuário
+func (s *Service) userTrailer(ctx context.Context, id, userID int64) (db.Trailer, error) {
+	trailer, err := s.TrailerRepository.GetTrailerById(ctx, id)
+	if err != nil {
+		return db.Trailer{}, fmt.Errorf("erro ao buscar carreta %d: %w", id, err)
+	}
+	if userID == 0 || trailer.UserID != userID {
+		return db.Trailer{}, fmt.Errorf("carreta %d não encontrada", id)
+	}
+	return trailer, nil
+}
+
+// resolveVehicleInfo completa as dimensões da composição a partir do cavalo e da carreta cadastrados.
+// A altura e a largura são as maiores entre os dois, o comprimento é a soma e o peso bruto é estimado
+// pela tara por eixo mais a carga, limitado ao PBTC legal para o número de eixos. Só usa veículos do
+// usuário userID; sem usuário autenticado (0) as dimensões precisam vir na requisição.
+func (s *Service) resolveVehicleInfo(ctx context.Context, v VehicleInfo, axles int64, userID int64) (VehicleInfo, error) {
+	var height, width, length, capacity float64
+	totalAxles := axles
+
+	if v.TractorUnitID > 0 && s.TractorUnitRepository != nil {
+		tractor, err := s.userTractorUnit(ctx, v.TractorUnitID, userID)
+		if err != nil {
+			return v, err
+		}
+		height, width, length = tractor.Height, tractor.Width, tractor.Length
+		capacity = parseCapacity(tractor.Capacity)
+		if totalAxles == 0 {
+			totalAxles = tractor.Axles
+		}
+	}
+
+	if v.TrailerID > 0 && s.TrailerRepository != nil {
+		trailer, err := s.userTrailer(ctx, v.TrailerID, userID)
+		if err != nil {
+			return v, err
+		}
+		if trailer.Height.Valid {
+			height = math.Max(height, trailer.Height.Float64)
+		}
+		if trailer.Width.Valid {
+			width = math.Max(width, trailer.Width.Float64)
+		}
+		if trailer.Length.Valid {
+			length += trailer.Length.Float64
+		}
+		if trailer.LoadCapacity.Valid && trailer.LoadCapacity.Float64 > 0 {
+			capacity = trailer.LoadCapacity.Float64
+		}
+		if axles == 0 {
+			totalAxles += trailer.Axles
+		}
+	}
+
+	if v.Height == 0 {
+		v.Height = height
+	}
+	if v.Width == 0 {
+		v.Width = width
+	}
+	if v.Length == 0 {
+		v.Length = length
+	}
+	if v.GrossWeight == 0 && (v.TractorUnitID > 0 || v.TrailerID > 0 || v.CargoWeight > 0) {
+		cargo := v.CargoWeight
+		if cargo == 0 {
+			cargo = capacity
+		}
+		v.GrossWeight = estimateGrossWeight(totalAxles, cargo)
+	}
+
+	return v, nil
+}
+
+// legalGrossWeight devolve o PBT/PBTC máximo (t) permitido pela Resolução CONTRAN 210 para o número de eixos
+func legalGrossWeight(axles int64) float64 {
+	switch {
+	case axles <= 0:
+		return 0
+	case axles == 2:
+		return 16
+	case axles == 3:
+		return 23
+	case axles == 4:
+		return 31.5
+	case axles == 5:
+		return 41.5
+	case axles == 6:
+		return 48.5
+	case axles == 7:
+		return 57
+	case axles == 8:
+		return 65.5
+	default:
+		return 74
+	}
+}
+
+func estimateGrossWeight(axles int64, cargo float64) float64 {
+	legal := legalGrossWeight(axles)
+	if cargo <= 0 {
+		// Sem carga conhecida assume o pior caso: composição no limite legal
+		return legal
+	}
+	gross := float64(axles)*tarePerAxle + cargo
+	if legal > 0 && gross > legal {
+		return legal
+	}
+	return gross
+}
+
+// parseCapacity interpreta a capacidade cadastrada como texto ("30", "30t", "30.000 kg")
+func parseCapacity(capacity string) float64 {
+	c := strings.ToLower(strings.TrimSpace(capacity))
+	isKg := strings.Contains(c, "kg")
+	var b strings.Builder
+	for _, r := range c {
+		if (r >= '0' && r <= '9') || r == ',' || r == '.' {
+			b.WriteRune(r)
+		}
+	}
+	number := b.String()
+	if strings.Contains(number, ",") || isKg {
+		// Formato brasileiro: ponto como separador de milhar e vírgula decimal
+		number = strings.Replace(strings.ReplaceAll(number, ".", ""), ",", ".", 1)
+	}
+	value, err := strconv.ParseFloat(number, 64)
+	if err != nil {
+		return 0
+	}
+	if isKg || value > 1000 {
+		return value / 1000
+	}
+	return value
+}
+
+// findRestrictionsOnRoute lista as restrições de altura, peso e comprimento no trajeto que o veículo não atende
+func (s *Service) findRestrictionsOnRoute(ctx context.Context, routeGeometry string, vehicle VehicleInfo) ([]RestrictionAlert, error) {
+	if !vehicle.hasDimensions() {
+		return nil, nil
+	}
+
+	geometry, err := routeGeometryFromPolyline(routeGeometry)
+	if err != nil {
+		return nil, err
+	}
+	if len(geometry.Points) < 2 {
+		return nil, nil
+	}
+	points := geometry.Points
+
+	restrictions, err := s.InterfaceService.GetRoadRestrictionsByBoundingBox(ctx, db.GetRoadRestrictionsByBoundingBoxParams{
+		Lat:   geometry.MinLat - restrictionBBoxMargin,
+		Lat_2: geometry.MaxLat + restrictionBBoxMargin,
+		Lng:   geometry.MinLng - restrictionBBoxMargin,
+		Lng_2: geometry.MaxLng + restrictionBBoxMargin,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var alerts []RestrictionAlert
+	for _, r := range restrictions {
+		violations := restrictionViolations(r, vehicle)
+		if len(violations) == 0 {
+			continue
+		}
+
+		pos := LatLng{Lat: r.Lat, Lng: r.Lng}
+		onRoute := false
+		for i := 0; i < len(points)-1; i++ {
+			if distancePointToSegment(pos, points[i], points[i+1]) <= float64(r.Radius) {
+				onRoute = true
+				break
+			}
+		}
+		if !onRoute {
+			continue
+		}
+
+		for _, v := range violations {
+			alerts = append(alerts, RestrictionAlert{
+				ID:           r.ID,
+				Name:         r.Name,
+				Type:         r.Type,
+				Road:         r.Road.String,
+				City:         r.City.String,
+				State:        r.State.String,
+				Location:     Location{Latitude: r.Lat, Longitude: r.Lng},
+				Restriction:  v.Restriction,
+				Limit:        v.Limit,
+				VehicleValue: v.VehicleValue,
+			})
+		}
+	}
+
+	return alerts, nil
+}
+
+func restrictionViolations(r db.RoadRestriction, vehicle VehicleInfo) []RestrictionAlert {
+	var out []RestrictionAlert
+	if r.MaxHeight.Valid && vehicle.Height > 0 && vehicle.Height > r.MaxHeight.Float64 {
+		out = append(out, RestrictionAlert{Restriction: RestrictionHeight, Limit: r.MaxHeight.Float64, VehicleValue: vehicle.Height})
+	}
+	if r.MaxWeight.Valid && vehicle.GrossWeight > 0 && vehicle.GrossWeight > r.MaxWeight.Float64 {
+		out = append(out, RestrictionAlert{Restriction: RestrictionWeight, Limit: r.MaxWeight.Float64, VehicleValue: vehicle.GrossWeight})
+	}
+	if r.MaxLength.Valid && vehicle.Length > 0 && vehicle.Length > r.MaxLength.Float64 {
+		out = append(out, RestrictionAlert{Restriction: RestrictionLength, Limit: r.MaxLength.Float64, VehicleValue: vehicle.Length})
+	}
+	return out
+}
+
+// preferCompliantRoutes aplica o modo "avoid": descarta as alternativas que passam por restrições e, se
+// todas violam, recalcula a principal com um desvio ao lado da restrição (detourRestrictions). Sem desvio
+// possível mantém a resposta para que as restrições sejam sinalizadas.
+func (s *Service) preferCompliantRoutes(ctx context.Context, resp OSRMResponse, req RouteRequest, vehicle VehicleInfo) OSRMResponse {
+	if !vehicle.hasDimensions() || !vehicle.avoid() || len(resp.Routes) == 0 {
+		return resp
+	}
+
+	var compliant []OSRMRoute
+	var firstAlerts []RestrictionAlert
+	for i, route := range resp.Routes {
+		alerts, err := s.findRestrictionsOnRoute(ctx, route.Geometry, vehicle)
+		if err != nil {
+			log.Printf("Erro ao verificar restrições de via: %v", err)
+			return resp
+		}
+		if len(alerts) == 0 {
+			compliant = append(compliant, route)
+		} else if i == 0 {
+			firstAlerts = alerts
+		}
+	}
+	if len(compliant) > 0 {
+		resp.Routes = compliant
+		return resp
+	}
+
+	if detour, ok := s.detourRestrictions(ctx, req, resp.Routes[0], firstAlerts, vehicle); ok {
+		resp.Routes = []OSRMRoute{detour}
+	}
+	return resp
+}
+
+// restrictionDetourOffsets são as distâncias (m) do ponto de desvio até a restrição, testadas dos dois lados da via
+var restrictionDetourOffsets = []float64{3000, 8000, 15000}
+
+// detourRestrictions recalcula a rota passando por um ponto ao lado da primeira restrição violada, em
+// distâncias crescentes e alternando o lado da via. Devolve a primeira rota que não viola nenhuma restrição,
+// com os trechos antes e depois do ponto de desvio unidos para manter um trecho por parada da requisição.
+func (s *Service) detourRestrictions(ctx context.Context, req RouteRequest, route OSRMRoute, alerts []RestrictionAlert, vehicle VehicleInfo) (OSRMRoute, bool) {
+	if len(alerts) == 0 || len(req.Coordinates) < 2 {
+		return OSRMRoute{}, false
+	}
+	geometry, err := routeGeometryFromPolyline(route.Geometry)
+	if err != nil || len(geometry.Points) < 2 {
+		return OSRMRoute{}, false
+	}
+
+	along := math.Inf(1)
+	for _, alert := range alerts {
+		if a := geometry.locate(LatLng{Lat: alert.Location.Latitude, Lng: alert.Location.Longitude}); a < along {
+			along = a
+		}
+	}
+	center := geometry.pointAt(along)
+	bearing := geometry.bearingAt(along)
+	leg := routeLegAt(route, along, geometry.Length())
+
+	for _, offset := range restrictionDetourOffsets {
+		for _, side := range []float64{90, 270} {
+			lat, lng := s.calculateDestination(center.Lat, center.Lng, math.Mod(bearing+side, 360), offset)
+			coordinates := make([]Location, 0, len(req.Coordinates)+1)
+			coordinates = append(coordinates, req.Coordinates[:leg+1]...)
+			coordinates = append(coordinates, Location{Latitude: lat, Longitude: lng})
+			coordinates = append(coordinates, req.Coordinates[leg+1:]...)
+
+			detourReq := req
+			detourReq.Coordinates = coordinates
+			resp, err := s.engineRoute(ctx, 15*time.Second, detourReq)
+			if err != nil {
+				continue
+			}
+			candidate := resp.Routes[0]
+			remaining, err := s.findRestrictionsOnRoute(ctx, candidate.Geometry, vehicle)
+			if err != nil || len(remaining) > 0 {
+				continue
+			}
+			return mergeDetourLegs(candidate, leg), true
+		}
+	}
+	return OSRMRoute{}, false
+}
+
+// routeLegAt devolve o trecho (entre paradas da requisição) que contém a posição along da geometria
+func routeLegAt(route OSRMRoute, along, geometryLength float64) int {
+	if len(route.Legs) == 0 || route.Distance <= 0 || geometryLength <= 0 {
+		return 0
+	}
+	// A geometria e os trechos do motor medem distâncias um pouco diferentes; compara em proporção
+	target := along / geometryLength * route.Distance
+	var covered float64
+	for i, leg := range route.Legs {
+		covered += leg.Distance
+		if target <= covered {
+			return i
+		}
+	}
+	return len(route.Legs) - 1
+}
+
+// mergeDetourLegs une os trechos antes e depois do ponto de desvio inserido após a parada leg
+func mergeDetourLegs(route OSRMRoute, leg int) OSRMRoute {
+	if leg+1 >= len(route.Legs) {
+		return route
+	}
+	before, after := route.Legs[leg], route.Legs[leg+1]
+	merged := OSRMLeg{Distance: before.Distance + after.Distance, Duration: before.Duration + after.Duration}
+	// Remove a chegada e a partida no ponto de desvio, que não é uma parada do usuário
+	for i, step := range before.Steps {
+		if i == len(before.Steps)-1 && step.Maneuver.Type == "arrive" {
+			continue
+		}
+		merged.Steps = append(merged.Steps, step)
+	}
+	for i, step := range after.Steps {
+		if i == 0 && step.Maneuver.Type == "depart" {
+			continue
+		}
+		merged.Steps = append(merged.Steps, step)
+	}
+
+	legs := make([]OSRMLeg, 0, len(route.Legs)-1)
+	legs = append(legs, route.Legs[:leg]...)
+	legs = append(legs, merged)
+	legs = append(legs, route.Legs[leg+2:]...)
+	route.Legs = legs
+	return route
+}
+
+// routeRestrictions é o atalho usado na montagem dos resumos: falhas de consulta não interrompem a rota
+func (s *Service) routeRestrictions(ctx context.Context, routeGeometry string, vehicle VehicleInfo) []RestrictionAlert {
+	alerts, err := s.findRestrictionsOnRoute(ctx, routeGeometry, vehicle)
+	if err != nil {
+		log.Printf("Erro ao verificar restrições de via: %v", err)
+		return nil
+	}
+	return alerts
+}
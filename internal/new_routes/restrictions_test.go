@@ -0,0 +1,157 @@
+package new_routes
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	db "geolocation/db/sqlc"
+	"geolocation/internal/routes"
+	"geolocation/internal/tractor_unit"
+	"geolocation/internal/trailer"
+)
+
+type tractorRepository struct {
+	tractor_unit.InterfaceRepository
+	units map[int64]db.TractorUnit
+}
+
+func (r tractorRepository) GetTractorUnitById(_ context.Context, id int64) (db.TractorUnit, error) {
+	unit, ok := r.units[id]
+	if !ok {
+		return db.TractorUnit{}, sql.ErrNoRows
+	}
+	return unit, nil
+}
+
+type trailerRepository struct {
+	trailer.InterfaceRepository
+	trailers map[int64]db.Trailer
+}
+
+func (r trailerRepository) GetTrailerById(_ context.Context, id int64) (db.Trailer, error) {
+	t, ok := r.trailers[id]
+	if !ok {
+		return db.Trailer{}, sql.ErrNoRows
+	}
+	return t, nil
+}
+
+func TestResolveVehicleInfo(t *testing.T) {
+	s := &Service{
+		TractorUnitRepository: tractorRepository{units: map[int64]db.TractorUnit{
+			1: {ID: 1, UserID: 7, Height: 3.9, Width: 2.6, Length: 7, Axles: 3, Capacity: "30.000 kg"},
+		}},
+		TrailerRepository: trailerRepository{trailers: map[int64]db.Trailer{
+			2: {ID: 2, UserID: 7, Axles: 3,
+				Height:       sql.NullFloat64{Float64: 4.4, Valid: true},
+				Width:        sql.NullFloat64{Float64: 2.5, Valid: true},
+				Length:       sql.NullFloat64{Float64: 15.5, Valid: true},
+				LoadCapacity: sql.NullFloat64{Float64: 20, Valid: true}},
+			3: {ID: 3, UserID: 8, Axles: 2},
+		}},
+	}
+	ctx := context.Background()
+
+	// Composição: a maior altura e largura, o comprimento somado e 6 eixos (18 t de tara) com a carga da carreta
+	got, err := s.resolveVehicleInfo(ctx, VehicleInfo{TractorUnitID: 1, TrailerID: 2}, 0, 7)
+	if err != nil {
+		t.Fatalf("resolveVehicleInfo() erro = %v", err)
+	}
+	if got.Height != 4.4 || got.Width != 2.6 || got.Length != 22.5 || got.GrossWeight != 38 {
+		t.Errorf("composição = %+v, want 4.4 x 2.6 x 22.5 m e 38 t", got)
+	}
+
+	// Valores informados prevalecem e a carga pesada para no PBTC legal de 6 eixos
+	got, err = s.resolveVehicleInfo(ctx, VehicleInfo{TractorUnitID: 1, TrailerID: 2, Height: 4.2, CargoWeight: 40}, 0, 7)
+	if err != nil {
+		t.Fatalf("resolveVehicleInfo() erro = %v", err)
+	}
+	if got.Height != 4.2 || got.GrossWeight != legalGrossWeight(6) {
+		t.Errorf("com valores informados = %+v, want altura 4.2 e %v t", got, legalGrossWeight(6))
+	}
+
+	// Só o cavalo: usa a capacidade em texto e os eixos informados na requisição
+	got, err = s.resolveVehicleInfo(ctx, VehicleInfo{TractorUnitID: 1}, 5, 7)
+	if err != nil {
+		t.Fatalf("resolveVehicleInfo() erro = %v", err)
+	}
+	if got.Length != 7 || got.GrossWeight != 41.5 {
+		t.Errorf("só o cavalo = %+v, want 7 m e 41.5 t", got)
+	}
+
+	for name, v := range map[string]VehicleInfo{
+		"carreta de outro usuário": {TrailerID: 3},
+		"cavalo inexistente":       {TractorUnitID: 9},
+	} {
+		if _, err := s.resolveVehicleInfo(ctx, v, 0, 7); err == nil {
+			t.Errorf("%s: resolveVehicleInfo() não retornou erro", name)
+		}
+	}
+	if _, err := s.resolveVehicleInfo(ctx, VehicleInfo{TractorUnitID: 1}, 0, 0); err == nil {
+		t.Error("resolveVehicleInfo() sem usuário usou o cavalo cadastrado")
+	}
+}
+
+func TestParseCapacity(t *testing.T) {
+	for capacity, want := range map[string]float64{
+		"30":        30,
+		"30t":       30,
+		" 27,5 t ":  27.5,
+		"30.000 kg": 30,
+		"45000":     45,
+		"":          0,
+		"sem dados": 0,
+	} {
+		if got := parseCapacity(capacity); got != want {
+			t.Errorf("parseCapacity(%q) = %v, want %v", capacity, got, want)
+		}
+	}
+}
+
+// restrictionRepository devolve um viaduto de 4 m de altura sobre a rota de -23.5 a -23.6
+type restrictionRepository struct {
+	routes.InterfaceRepository
+}
+
+func (restrictionRepository) GetRoadRestrictionsByBoundingBox(context.Context, db.GetRoadRestrictionsByBoundingBoxParams) ([]db.RoadRestriction, error) {
+	return []db.RoadRestriction{{
+		ID: 1, Name: "Viaduto", Type: "viaduto", Lat: -23.55, Lng: -46.6, Radius: 50,
+		MaxHeight: sql.NullFloat64{Float64: 4, Valid: true},
+		MaxWeight: sql.NullFloat64{Float64: 45, Valid: true},
+	}}, nil
+}
+
+func TestPreferCompliantRoutes(t *testing.T) {
+	s := &Service{InterfaceService: restrictionRepository{}}
+	ctx := context.Background()
+	underBridge := OSRMRoute{Distance: 11000, Geometry: encodePolyline([]LatLng{{Lat: -23.5, Lng: -46.6}, {Lat: -23.6, Lng: -46.6}})}
+	aside := OSRMRoute{Distance: 14000, Geometry: encodePolyline([]LatLng{{Lat: -23.5, Lng: -46.6}, {Lat: -23.55, Lng: -46.65}, {Lat: -23.6, Lng: -46.6}})}
+	resp := OSRMResponse{Code: "Ok", Routes: []OSRMRoute{underBridge, aside}}
+	tall := VehicleInfo{Height: 4.4, GrossWeight: 30}
+
+	alerts, err := s.findRestrictionsOnRoute(ctx, underBridge.Geometry, tall)
+	if err != nil {
+		t.Fatalf("findRestrictionsOnRoute() erro = %v", err)
+	}
+	if len(alerts) != 1 || alerts[0].Restriction != RestrictionHeight || alerts[0].Limit != 4 || alerts[0].VehicleValue != 4.4 {
+		t.Errorf("alertas = %+v, want só a altura", alerts)
+	}
+	if alerts, _ := s.findRestrictionsOnRoute(ctx, aside.Geometry, tall); len(alerts) != 0 {
+		t.Errorf("alertas fora do raio da restrição = %+v", alerts)
+	}
+
+	got := s.preferCompliantRoutes(ctx, resp, RouteRequest{}, tall)
+	if len(got.Routes) != 1 || got.Routes[0].Distance != aside.Distance {
+		t.Errorf("modo avoid = %d rotas, want só a que desvia do viaduto", len(got.Routes))
+	}
+
+	tall.RestrictionMode = RestrictionModeFlag
+	if got := s.preferCompliantRoutes(ctx, resp, RouteRequest{}, tall); len(got.Routes) != 2 {
+		t.Errorf("modo flag = %d rotas, want as 2 alternativas", len(got.Routes))
+	}
+	// Sem dimensões não há o que verificar
+	if got := s.preferCompliantRoutes(ctx, resp, RouteRequest{}, VehicleInfo{}); len(got.Routes) != 2 {
+		t.Errorf("sem dimensões = %d rotas, want 2", len(got.Routes))
+	}
+}
@@ -0,0 +1,440 @@
+package new_routes
+
+import (
+	"context"
+	"geolocation/internal/zonas_risco"
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// RouteTypeLowestRisk é o typeRoute que pede a alternativa de menor exposição ao risco
+	RouteTypeLowestRisk = "lowest_risk"
+
+	// riskNearMeters é a faixa em volta da zona em que a rota conta como "perto"
+	riskNearMeters = 1000.0
+	// riskSampleMeters é o passo da amostragem da rota ao medir distância dentro e perto das zonas
+	riskSampleMeters = 50.0
+	// riskNearWeight é o peso de passar perto da zona em relação a passar por dentro
+	riskNearWeight = 0.2
+	// riskAttentionWeight é o peso das zonas de atenção em relação às zonas de risco
+	riskAttentionWeight = 0.5
+	// riskMarginWeight é o peso da zona ativa só na margem da passagem prevista, e não no horário exato
+	riskMarginWeight = 0.5
+	// riskScoreScale é a exposição que leva o score a ~63; o score satura em 100
+	riskScoreScale = 10.0
+
+	RiskLevelNone     = "none"
+	RiskLevelLow      = "low"
+	RiskLevelMedium   = "medium"
+	RiskLevelHigh     = "high"
+	RiskLevelCritical = "critical"
+)
+
+// riskSeverityWeight dobra o peso a cada nível de severidade
+var riskSeverityWeight = map[int64]float64{
+	zonas_risco.SeverityLow:      1,
+	zonas_risco.SeverityMedium:   2,
+	zonas_risco.SeverityHigh:     4,
+	zonas_risco.SeverityCritical: 8,
+}
+
+// RiskExposure é a exposição da rota às zonas de risco e de atenção. Exposure soma, por zona, a média entre
+// km e minutos dentro (e perto, com peso menor), ponderada pela severidade e pelo horário da passagem; Score
+// normaliza a exposição de 0 a 100 para comparar alternativas.
+type RiskExposure struct {
+	Score          float64            `json:"score"`
+	Level          string             `json:"level"`
+	Exposure       float64            `json:"exposure"`
+	DistanceInside float64            `json:"distance_inside_m"`
+	DistanceNear   float64            `json:"distance_near_m"`
+	TimeInside     float64            `json:"time_inside_s"`
+	TimeNear       float64            `json:"time_near_s"`
+	Zones          []RiskExposureZone `json:"zones"` // Do maior para o menor contribuinte
+}
+
+// RiskExposureZone é a contribuição de uma zona para a exposição da rota
+type RiskExposureZone struct {
+	ZoneID         int64      `json:"zone_id"`
+	Name           string     `json:"name"`
+	Severity       int64      `json:"severity"`
+	Attention      bool       `json:"attention"`
+	Action         string     `json:"action,omitempty"`
+	DistanceInside float64    `json:"distance_inside_m"`
+	DistanceNear   float64    `json:"distance_near_m"`
+	TimeInside     float64    `json:"time_inside_s"`
+	TimeNear       float64    `json:"time_near_s"`
+	PassageTime    *time.Time `json:"passage_time,omitempty"` // Chegada prevista à zona ou à faixa em volta dela
+	Exposure       float64    `json:"exposure"`
+	Share          float64    `json:"share"` // Fração da exposição total da rota
+}
+
+// isLowestRiskRoute diz se o typeRoute pede a alternativa de menor risco
+func isLowestRiskRoute(typeRoute string) bool {
+	switch strings.ToLower(strings.TrimSpace(typeRoute)) {
+	case RouteTypeLowestRisk, "menor_risco", "safest", "segura":
+		return true
+	}
+	return false
+}
+
+// zoneExposure acumula a passagem da rota por uma zona
+type zoneExposure struct {
+	zone                         RiskZone
+	distanceInside, distanceNear float64
+	timeInside, timeNear         float64
+	firstAlong                   float64
+	touched                      bool
+}
+
+// routeRiskExposure mede a exposição da rota às zonas. Sem polyline, usa a linha reta entre from e to; o tempo
+// é distribuído ao longo da geometria na proporção da duração da rota.
+func (s *Service) routeRiskExposure(polyline string, from, to Location, duration float64, zones []RiskZone, departure *time.Time) *RiskExposure {
+	exposure := &RiskExposure{Level: RiskLevelNone, Zones: []RiskExposureZone{}}
+	if len(zones) == 0 {
+		return exposure
+	}
+
+	points := []Location{from, to}
+	if polyline != "" {
+		decoded, err := s.decodePolylineOSRM(polyline)
+		if err != nil {
+			log.Printf("Erro ao decodificar polyline para exposição ao risco: %v", err)
+		} else if len(decoded) >= 2 {
+			points = decoded
+		}
+	}
+
+	var length float64
+	for i := 0; i < len(points)-1; i++ {
+		length += s.haversineDistance(points[i].Latitude, points[i].Longitude, points[i+1].Latitude, points[i+1].Longitude)
+	}
+	if length == 0 {
+		return exposure
+	}
+	secondsPerMeter := duration / length
+
+	tracked := make([]zoneExposure, len(zones))
+	for i, zone := range zones {
+		tracked[i].zone = zone
+	}
+
+	var along float64
+	for i := 0; i < len(points)-1; i++ {
+		a, b := points[i], points[i+1]
+		segment := s.haversineDistance(a.Latitude, a.Longitude, b.Latitude, b.Longitude)
+		if segment == 0 {
+			continue
+		}
+
+		// Só amostra o trecho contra as zonas cujo círculo envolvente, somado à faixa de proximidade, alcança o trecho
+		var candidates []int
+		for j, zone := range zones {
+			if s.distancePointToLine(zone.Lat, zone.Lng, a.Latitude, a.Longitude, b.Latitude, b.Longitude) <= float64(zone.Radius)+riskNearMeters {
+				candidates = append(candidates, j)
+			}
+		}
+		if len(candidates) == 0 {
+			along += segment
+			continue
+		}
+
+		samples := int(math.Ceil(segment / riskSampleMeters))
+		step := segment / float64(samples)
+		for k := 0; k < samples; k++ {
+			p := interpolateLocation(a, b, (float64(k)+0.5)/float64(samples))
+			var insideAny, nearAny bool
+			for _, j := range candidates {
+				inside, near := s.riskZoneProximity(p, tracked[j].zone)
+				if !inside && !near {
+					continue
+				}
+				if !tracked[j].touched {
+					tracked[j].touched = true
+					tracked[j].firstAlong = along + float64(k)*step
+				}
+				if inside {
+					insideAny = true
+					tracked[j].distanceInside += step
+					tracked[j].timeInside += step * secondsPerMeter
+				} else {
+					nearAny = true
+					tracked[j].distanceNear += step
+					tracked[j].timeNear += step * secondsPerMeter
+				}
+			}
+			if insideAny {
+				exposure.DistanceInside += step
+				exposure.TimeInside += step * secondsPerMeter
+			} else if nearAny {
+				exposure.DistanceNear += step
+				exposure.TimeNear += step * secondsPerMeter
+			}
+		}
+		along += segment
+	}
+
+	start := time.Now()
+	if departure != nil {
+		start = *departure
+	}
+	for _, t := range tracked {
+		if !t.touched {
+			continue
+		}
+		passage := start.Add(time.Duration(t.firstAlong * secondsPerMeter * float64(time.Second)))
+		value := riskExposurePoints(t, passage)
+		if value <= 0 {
+			continue
+		}
+		exposure.Exposure += value
+		exposure.Zones = append(exposure.Zones, RiskExposureZone{
+			ZoneID:         t.zone.ID,
+			Name:           t.zone.Name,
+			Severity:       riskZoneSeverity(t.zone),
+			Attention:      t.zone.ZonasAtencao,
+			Action:         t.zone.Action,
+			DistanceInside: math.Round(t.distanceInside),
+			DistanceNear:   math.Round(t.distanceNear),
+			TimeInside:     math.Round(t.timeInside),
+			TimeNear:       math.Round(t.timeNear),
+			PassageTime:    &passage,
+			Exposure:       value,
+		})
+	}
+
+	sort.SliceStable(exposure.Zones, func(i, j int) bool {
+		return exposure.Zones[i].Exposure > exposure.Zones[j].Exposure
+	})
+	for i := range exposure.Zones {
+		exposure.Zones[i].Share = roundThousandths(exposure.Zones[i].Exposure / exposure.Exposure)
+		exposure.Zones[i].Exposure = roundThousandths(exposure.Zones[i].Exposure)
+	}
+
+	exposure.Score = math.Round(1000*(1-math.Exp(-exposure.Exposure/riskScoreScale))) / 10
+	exposure.Level = riskExposureLevel(exposure.Score)
+	exposure.Exposure = roundThousandths(exposure.Exposure)
+	exposure.DistanceInside = math.Round(exposure.DistanceInside)
+	exposure.DistanceNear = math.Round(exposure.DistanceNear)
+	exposure.TimeInside = math.Round(exposure.TimeInside)
+	exposure.TimeNear = math.Round(exposure.TimeNear)
+	return exposure
+}
+
+// riskZoneProximity diz se o ponto está dentro da zona ou na faixa de proximidade em volta dela
+func (s *Service) riskZoneProximity(p Location, zone RiskZone) (inside, near bool) {
+	if area := zone.shapeArea(); area != nil {
+		distance := area.DistanceTo(p.Latitude, p.Longitude)
+		return distance == 0, distance > 0 && distance <= riskNearMeters
+	}
+	distance := s.haversineDistance(p.Latitude, p.Longitude, zone.Lat, zone.Lng) - float64(zone.Radius)
+	return distance <= 0, distance > 0 && distance <= riskNearMeters
+}
+
+// riskExposurePoints pondera a passagem pela zona: média entre km e minutos (perto conta com riskNearWeight),
+// vezes o peso da severidade, da zona de atenção e do horário. Zona inativa na passagem e na margem não conta.
+func riskExposurePoints(t zoneExposure, passage time.Time) float64 {
+	timing := 1.0
+	if !t.zone.schedule.ActiveAt(passage) {
+		if !t.zone.schedule.ActiveBetween(passage.Add(-passageMargin), passage.Add(passageMargin)) {
+			return 0
+		}
+		timing = riskMarginWeight
+	}
+
+	weight := riskSeverityWeight[riskZoneSeverity(t.zone)] * timing
+	if t.zone.ZonasAtencao {
+		weight *= riskAttentionWeight
+	}
+
+	inside := (t.distanceInside/1000 + t.timeInside/60) / 2
+	near := (t.distanceNear/1000 + t.timeNear/60) / 2
+	return weight * (inside + riskNearWeight*near)
+}
+
+// riskZoneSeverity trata zonas sem severidade (cadastradas antes dela existir) como alta, o padrão do cadastro
+func riskZoneSeverity(zone RiskZone) int64 {
+	if _, ok := riskSeverityWeight[zone.Severity]; ok {
+		return zone.Severity
+	}
+	return zonas_risco.SeverityHigh
+}
+
+func riskExposureLevel(score float64) string {
+	switch {
+	case score == 0:
+		return RiskLevelNone
+	case score < 20:
+		return RiskLevelLow
+	case score < 50:
+		return RiskLevelMedium
+	case score < 80:
+		return RiskLevelHigh
+	}
+	return RiskLevelCritical
+}
+
+// lessRiskExposure ordena pela exposição e, no empate, pela duração
+func lessRiskExposure(a, b *RiskExposure, durationA, durationB float64) bool {
+	var exposureA, exposureB float64
+	if a != nil {
+		exposureA = a.Exposure
+	}
+	if b != nil {
+		exposureB = b.Exposure
+	}
+	if exposureA != exposureB {
+		return exposureA < exposureB
+	}
+	return durationA < durationB
+}
+
+// scoreSegmentRoutes calcula a exposição ao risco de cada resumo do trecho. Com typeRoute de menor risco, soma
+// as alternativas do motor às rotas já calculadas e ordena do menor para o maior risco.
+func (s *Service) scoreSegmentRoutes(ctx context.Context, summaries []RouteSummary, zones []RiskZone, originLat, originLon, destLat, destLon float64, originGeocode, destGeocode GeocodeResult, data FrontInfoCEPRequest) []RouteSummary {
+	origin := Location{Latitude: originLat, Longitude: originLon}
+	dest := Location{Latitude: destLat, Longitude: destLon}
+
+	lowestRisk := isLowestRiskRoute(data.TypeRoute)
+	if lowestRisk {
+		summaries = append(summaries, s.segmentRouteAlternatives(ctx, summaries, origin, dest, originGeocode, destGeocode, data)...)
+	}
+
+	for i := range summaries {
+		summaries[i].RiskExposure = s.routeRiskExposure(summaries[i].Polyline, origin, dest, summaries[i].Duration.Value, zones, data.DepartureTime)
+	}
+
+	if lowestRisk {
+		sort.SliceStable(summaries, func(i, j int) bool {
+			return lessRiskExposure(summaries[i].RiskExposure, summaries[j].RiskExposure, summaries[i].Duration.Value, summaries[j].Duration.Value)
+		})
+	}
+	return summaries
+}
+
+// segmentRouteAlternatives busca as alternativas do motor para o trecho, sem repetir rotas já calculadas
+func (s *Service) segmentRouteAlternatives(ctx context.Context, existing []RouteSummary, origin, dest Location, originGeocode, destGeocode GeocodeResult, data FrontInfoCEPRequest) []RouteSummary {
+	osrmResp, err := s.engineAlternatives(ctx, 10*time.Second, RouteRequest{
+		Coordinates: []Location{origin, dest},
+		Profile:     data.Type,
+		AllowUTurn:  true,
+	}, 3)
+	if err != nil {
+		log.Printf("Erro ao buscar alternativas para a rota de menor risco: %v", err)
+		return nil
+	}
+
+	seen := make(map[string]bool, len(existing))
+	for _, summary := range existing {
+		seen[summary.Polyline] = true
+	}
+
+	var alternatives []RouteSummary
+	for _, route := range osrmResp.Routes {
+		if route.Geometry == "" || seen[route.Geometry] {
+			continue
+		}
+		seen[route.Geometry] = true
+		tolls, _ := s.findTollsOnRoute(ctx, route, newTollVehicle(data.Type, data.Axles, data.VehicleInfo), data.DepartureTime)
+		alternatives = append(alternatives, s.createRouteSummary(ctx, route, "alternativa", originGeocode, destGeocode, data, tolls))
+	}
+	return alternatives
+}
+
+// scoreTotalRoute calcula a exposição ao risco da rota total
+func (s *Service) scoreTotalRoute(total *TotalSummary, zones []RiskZone, departure *time.Time) {
+	if total.TotalDistance.Value == 0 {
+		return
+	}
+	total.RiskExposure = s.routeRiskExposure(total.Polyline, total.LocationOrigin.Location, total.LocationDestination.Location, total.TotalDuration.Value, zones, departure)
+}
+
+// routeExposureZones carrega as zonas de risco e de atenção da organização, já decididas pelo horário de passagem,
+// para medir a exposição das rotas calculadas sem desvio. Sem organização ou com falha na busca, não há zonas.
+func (s *Service) routeExposureZones(ctx context.Context, organizationID int64, stops []Location, profile string, departure *time.Time) []RiskZone {
+	if organizationID <= 0 {
+		return nil
+	}
+	riskZones, err := s.getActiveRiskZones(ctx, organizationID)
+	if err != nil {
+		return nil
+	}
+	attentionZones, err := s.getRiskAtentions(ctx, organizationID)
+	if err != nil {
+		return nil
+	}
+	if len(riskZones) == 0 && len(attentionZones) == 0 {
+		return nil
+	}
+	riskZones, attentionZones = s.scheduleRiskZones(ctx, riskZones, attentionZones, stops, profile, departure)
+	return append(append([]RiskZone{}, riskZones...), attentionZones...)
+}
+
+// scoreRoutes mede a exposição ao risco dos trechos e da rota total dos cálculos sem desvio. Com typeRoute de menor
+// risco, soma as alternativas do motor e devolve a rota total menos exposta, com as demais em ordem de risco.
+func (s *Service) scoreRoutes(ctx context.Context, routes []DetailedRoute, geocodes [][2]GeocodeResult, totalRoute TotalSummary, stops []Location, addresses []string, data FrontInfoCEPRequest) (TotalSummary, []TotalSummary) {
+	zones := s.routeExposureZones(ctx, data.OrganizationID, stops, data.Type, data.DepartureTime)
+
+	for i := range routes {
+		origin, dest := routes[i].LocationOrigin.Location, routes[i].LocationDestination.Location
+		var originGeocode, destGeocode GeocodeResult
+		if i < len(geocodes) {
+			originGeocode, destGeocode = geocodes[i][0], geocodes[i][1]
+		}
+		routes[i].Summaries = s.scoreSegmentRoutes(ctx, routes[i].Summaries, zones, origin.Latitude, origin.Longitude, dest.Latitude, dest.Longitude, originGeocode, destGeocode, data)
+	}
+
+	if !isLowestRiskRoute(data.TypeRoute) {
+		s.scoreTotalRoute(&totalRoute, zones, data.DepartureTime)
+		return totalRoute, nil
+	}
+	totals := s.totalRouteAlternatives(ctx, totalRoute, stops, addresses, data)
+	for i := range totals {
+		s.scoreTotalRoute(&totals[i], zones, data.DepartureTime)
+	}
+	return rankTotalRoutesByRisk(totalRoute, totals)
+}
+
+// rankTotalRoutesByRisk ordena as rotas totais do menor para o maior risco e devolve a primeira como rota
+// total, mantendo as zonas de atenção detectadas para o percurso
+func rankTotalRoutesByRisk(totalRoute TotalSummary, totals []TotalSummary) (TotalSummary, []TotalSummary) {
+	if len(totals) == 0 {
+		return totalRoute, totals
+	}
+	sort.SliceStable(totals, func(i, j int) bool {
+		return lessRiskExposure(totals[i].RiskExposure, totals[j].RiskExposure, totals[i].TotalDuration.Value, totals[j].TotalDuration.Value)
+	})
+	best := totals[0]
+	best.AttentionZones = totalRoute.AttentionZones
+	return best, totals
+}
+
+// totalRouteAlternatives busca as alternativas do motor passando por todas as paradas, para comparar o risco
+// com a rota total calculada com desvios
+func (s *Service) totalRouteAlternatives(ctx context.Context, totalRoute TotalSummary, stops []Location, addresses []string, data FrontInfoCEPRequest) []TotalSummary {
+	totals := []TotalSummary{}
+	if totalRoute.TotalDistance.Value > 0 {
+		totals = append(totals, totalRoute)
+	}
+	if len(stops) < 2 {
+		return totals
+	}
+
+	osrmResp, err := s.engineAlternatives(ctx, 30*time.Second, RouteRequest{Coordinates: stops, Profile: data.Type, AllowUTurn: true}, 3)
+	if err != nil {
+		log.Printf("Erro ao buscar alternativas da rota total de menor risco: %v", err)
+		return totals
+	}
+	for _, route := range osrmResp.Routes {
+		if route.Geometry == "" || route.Geometry == totalRoute.Polyline {
+			continue
+		}
+		alternative := s.createTotalSummary(ctx, route, stops[0], stops[len(stops)-1], addresses, data)
+		alternative.RouteType = "alternativa"
+		totals = append(totals, alternative)
+	}
+	return totals
+}
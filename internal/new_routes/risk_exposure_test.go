@@ -0,0 +1,199 @@
+package new_routes
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"geolocation/internal/zonas_risco"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestRouteRiskExposure(t *testing.T) {
+	s := &Service{}
+	// Linha reta de ~11,1 km para leste sobre o equador, a 10 m/s: a zona crítica começa a ~7,4 km
+	from, to := Location{Latitude: 0, Longitude: 0}, Location{Latitude: 0, Longitude: 0.1}
+	const duration = 1113.2
+	// 08:00 em Brasília; a rota chega ao meio (e à zona central) pouco depois das 08:05
+	departure := time.Date(2026, 10, 16, 11, 0, 0, 0, time.UTC)
+
+	windows, err := zonas_risco.ParseTimeWindows(json.RawMessage(`[{"start":"08:30","end":"09:00"}]`))
+	if err != nil {
+		t.Fatalf("ParseTimeWindows() erro = %v", err)
+	}
+	expired := departure.Add(-time.Hour)
+
+	central := RiskZone{ID: 1, Name: "Centro", Lat: 0, Lng: 0.05, Radius: 1000, Severity: zonas_risco.SeverityHigh}
+	attention := central
+	attention.ZonasAtencao = true
+	margin := central
+	margin.schedule = zonas_risco.Schedule{Windows: windows}
+	inactive := central
+	inactive.schedule = zonas_risco.Schedule{ExpiresAt: &expired}
+	// A 1335 m da rota com 500 m de raio: a rota só passa na faixa de proximidade
+	beside := RiskZone{ID: 2, Name: "Ao lado", Lat: 0.012, Lng: 0.05, Radius: 500, Severity: zonas_risco.SeverityHigh}
+	critical := RiskZone{ID: 3, Name: "Crítica", Lat: 0, Lng: 0.08, Radius: 500, Severity: zonas_risco.SeverityCritical}
+	far := RiskZone{ID: 4, Name: "Longe", Lat: 0.5, Lng: 0.05, Radius: 1000}
+
+	// A amostragem a cada riskSampleMeters arredonda as bordas das zonas; as comparações aceitam 3%
+	near := func(got, want float64) bool { return math.Abs(got-want) <= 0.03*want+1 }
+
+	// Dentro da zona central: 2 km em 200 s; perto: outros 2 km em 200 s; severidade alta pesa 4
+	centralExposure := 4 * ((2+200.0/60)/2 + riskNearWeight*(2+200.0/60)/2)
+
+	tests := []struct {
+		name           string
+		zones          []RiskZone
+		wantExposure   float64
+		wantLevel      string
+		wantInside     float64
+		wantNear       float64
+		wantZoneIDs    []int64
+		wantPassageSec float64 // chegada à faixa da primeira zona, em segundos após a saída
+	}{
+		{name: "sem zonas", wantLevel: RiskLevelNone, wantZoneIDs: []int64{}},
+		{name: "zona longe da rota", zones: []RiskZone{far}, wantLevel: RiskLevelNone, wantZoneIDs: []int64{}},
+		{
+			name: "atravessa a zona", zones: []RiskZone{central},
+			wantExposure: centralExposure, wantLevel: RiskLevelHigh, wantInside: 2000, wantNear: 2000,
+			wantZoneIDs: []int64{1}, wantPassageSec: 356.6,
+		},
+		{
+			name: "zona de atenção pesa metade", zones: []RiskZone{attention},
+			wantExposure: centralExposure * riskAttentionWeight, wantLevel: RiskLevelMedium, wantInside: 2000, wantNear: 2000,
+			wantZoneIDs: []int64{1}, wantPassageSec: 356.6,
+		},
+		{
+			name: "ativa só na margem da passagem", zones: []RiskZone{margin},
+			wantExposure: centralExposure * riskMarginWeight, wantLevel: RiskLevelMedium, wantInside: 2000, wantNear: 2000,
+			wantZoneIDs: []int64{1}, wantPassageSec: 356.6,
+		},
+		{
+			name: "zona expirada não conta", zones: []RiskZone{inactive},
+			wantLevel: RiskLevelNone, wantInside: 2000, wantNear: 2000, wantZoneIDs: []int64{},
+		},
+		{
+			name: "só passa perto", zones: []RiskZone{beside},
+			wantExposure: 4 * riskNearWeight * (1.368 + 136.8/60) / 2, wantLevel: RiskLevelLow, wantNear: 1368,
+			wantZoneIDs: []int64{2}, wantPassageSec: 488.3,
+		},
+		{
+			name: "maior contribuinte primeiro", zones: []RiskZone{central, critical},
+			wantExposure: centralExposure + 8*((1+100.0/60)/2+riskNearWeight*(2+200.0/60)/2),
+			wantLevel:    RiskLevelCritical, wantInside: 3000, wantNear: 3840, wantZoneIDs: []int64{3, 1},
+			wantPassageSec: 740.3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := s.routeRiskExposure("", from, to, duration, tt.zones, &departure)
+			if !near(got.Exposure, tt.wantExposure) {
+				t.Errorf("exposure = %v, want %.3f", got.Exposure, tt.wantExposure)
+			}
+			if got.Level != tt.wantLevel {
+				t.Errorf("level = %q (score %v), want %q", got.Level, got.Score, tt.wantLevel)
+			}
+			if want := math.Round(1000*(1-math.Exp(-got.Exposure/riskScoreScale))) / 10; math.Abs(got.Score-want) > 0.1 {
+				t.Errorf("score = %v, want %v", got.Score, want)
+			}
+			if !near(got.DistanceInside, tt.wantInside) || !near(got.DistanceNear, tt.wantNear) {
+				t.Errorf("dentro/perto = %v/%v m, want %v/%v m", got.DistanceInside, got.DistanceNear, tt.wantInside, tt.wantNear)
+			}
+			if !near(got.TimeInside, tt.wantInside/10) || !near(got.TimeNear, tt.wantNear/10) {
+				t.Errorf("tempo dentro/perto = %v/%v s, want %v/%v s", got.TimeInside, got.TimeNear, tt.wantInside/10, tt.wantNear/10)
+			}
+
+			ids := make([]int64, 0, len(got.Zones))
+			var share float64
+			for _, zone := range got.Zones {
+				ids = append(ids, zone.ZoneID)
+				share += zone.Share
+			}
+			if len(ids) != len(tt.wantZoneIDs) {
+				t.Fatalf("zonas = %v, want %v", ids, tt.wantZoneIDs)
+			}
+			for i := range ids {
+				if ids[i] != tt.wantZoneIDs[i] {
+					t.Errorf("zonas = %v, want %v", ids, tt.wantZoneIDs)
+					break
+				}
+			}
+			if len(ids) == 0 {
+				return
+			}
+			if math.Abs(share-1) > 0.01 {
+				t.Errorf("soma das frações = %v, want 1", share)
+			}
+			// A primeira zona da lista é a maior contribuinte; wantPassageSec é a chegada à faixa dela
+			passage := got.Zones[0].PassageTime
+			if passage == nil || math.Abs(passage.Sub(departure).Seconds()-tt.wantPassageSec) > 6 {
+				t.Errorf("passage_time = %v, want saída + %.0f s", passage, tt.wantPassageSec)
+			}
+		})
+	}
+}
+
+// orgZonesService devolve as zonas só para a organização cadastrada
+type orgZonesService struct {
+	zonas_risco.InterfaceService
+	orgID int64
+	zones []zonas_risco.ZonaRiscoResponse
+}
+
+func (z *orgZonesService) GetAllZonasRiscoService(_ context.Context, org sql.NullInt64) ([]zonas_risco.ZonaRiscoResponse, error) {
+	if org.Int64 != z.orgID {
+		return nil, nil
+	}
+	return z.zones, nil
+}
+
+func TestScoreRoutesWithoutAvoidance(t *testing.T) {
+	s := &Service{
+		Engine: NewFakeEngine(),
+		RiskZonesRepository: &orgZonesService{orgID: 5, zones: []zonas_risco.ZonaRiscoResponse{
+			{ID: 1, Name: "Centro", Lat: 0, Lng: 0.05, Radius: 1000, Status: true, Severity: zonas_risco.SeverityHigh},
+			{ID: 2, Name: "Feira", Lat: 0, Lng: 0.08, Radius: 300, Status: true, ZonaAtencao: true, Severity: zonas_risco.SeverityLow},
+		}},
+	}
+	from, to := Location{Latitude: 0, Longitude: 0}, Location{Latitude: 0, Longitude: 0.1}
+
+	tests := []struct {
+		name      string
+		orgID     int64
+		wantLevel string
+		wantZones int
+	}{
+		{name: "zonas de risco e de atenção da organização", orgID: 5, wantLevel: RiskLevelHigh, wantZones: 2},
+		{name: "organização sem zonas", orgID: 6, wantLevel: RiskLevelNone},
+		{name: "sem organização", wantLevel: RiskLevelNone},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			routes := []DetailedRoute{{
+				LocationOrigin:      AddressInfo{Location: from},
+				LocationDestination: AddressInfo{Location: to},
+				Summaries:           []RouteSummary{{RouteType: "fastest", Duration: Duration{Value: 1113.2}}, {RouteType: "cheapest", Duration: Duration{Value: 1300}}},
+			}}
+			total := TotalSummary{LocationOrigin: AddressInfo{Location: from}, LocationDestination: AddressInfo{Location: to}, TotalDistance: Distance{Value: 11132}, TotalDuration: Duration{Value: 1113.2}}
+
+			total, totals := s.scoreRoutes(context.Background(), routes, nil, total, []Location{from, to}, nil, FrontInfoCEPRequest{OrganizationID: tt.orgID, Type: "Truck"})
+			if totals != nil {
+				t.Errorf("rotas totais = %d, want nenhuma fora do menor risco", len(totals))
+			}
+			for _, summary := range append(routes[0].Summaries, RouteSummary{RouteType: "total", RiskExposure: total.RiskExposure}) {
+				if summary.RiskExposure == nil {
+					t.Fatalf("rota %s sem exposição ao risco", summary.RouteType)
+				}
+				if summary.RiskExposure.Level != tt.wantLevel || len(summary.RiskExposure.Zones) != tt.wantZones {
+					t.Errorf("rota %s: nível %s com %d zonas, want %s com %d", summary.RouteType, summary.RiskExposure.Level, len(summary.RiskExposure.Zones), tt.wantLevel, tt.wantZones)
+				}
+			}
+			if routes[0].Summaries[0].RouteType != "fastest" {
+				t.Errorf("ordem dos perfis mudou fora do menor risco: %s primeiro", routes[0].Summaries[0].RouteType)
+			}
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package new_routes
+
+import (
+	"context"
+	"geolocation/internal/zonas_risco"
+	"log"
+	"math"
+	"time"
+)
+
+const (
+	// passageDetourFactor converte a distância em linha reta entre as paradas na distância por estrada
+	passageDetourFactor = 1.3
+	// passageSpeed é a velocidade média (m/s) usada para estimar a passagem, a mesma da estimativa direta
+	passageSpeed = 16.67
+	// passageMargin cobre o erro da estimativa: a zona vale se estiver ativa em qualquer momento da margem
+	passageMargin = 30 * time.Minute
+)
+
+// newZoneSchedule monta severidade, janelas, validade e fuso da zona; janelas inválidas deixam a zona sempre ativa
+func newZoneSchedule(dbZone zonas_risco.ZonaRiscoResponse) zonas_risco.Schedule {
+	windows, err := zonas_risco.ParseTimeWindows(dbZone.ActiveWindows)
+	if err != nil {
+		log.Printf("Janelas de horário inválidas na zona de risco %d, considerando sempre ativa: %v", dbZone.ID, err)
+		windows = nil
+	}
+	return zonas_risco.Schedule{
+		Severity:  dbZone.Severity,
+		Attention: dbZone.ZonaAtencao,
+		Windows:   windows,
+		ExpiresAt: dbZone.ExpiresAt,
+		Location:  zonas_risco.ScheduleLocation(dbZone.Cep, dbZone.Lat, dbZone.Lng),
+	}
+}
+
+// scheduleRiskZones decide, pelo horário previsto de passagem em cada zona, quais desviar e quais só alertar;
+// zonas expiradas ou fora das janelas de horário ficam de fora. Sem departure_time considera a saída agora.
+func (s *Service) scheduleRiskZones(ctx context.Context, riskZones, attentionZones []RiskZone, stops []Location, profile string, departure *time.Time) ([]RiskZone, []RiskZone) {
+	start := time.Now()
+	if departure != nil {
+		start = *departure
+	}
+
+	passageOffset := s.passageEstimator(ctx, stops, profile)
+	avoid := []RiskZone{}
+	warn := []RiskZone{}
+	for _, zones := range [][]RiskZone{riskZones, attentionZones} {
+		for _, zone := range zones {
+			passage := start.Add(passageOffset(zone))
+			zone.PassageTime = &passage
+			zone.Action = zone.schedule.Action(passage.Add(-passageMargin), passage.Add(passageMargin))
+
+			switch zone.Action {
+			case zonas_risco.ActionAvoid:
+				avoid = append(avoid, zone)
+			case zonas_risco.ActionWarn:
+				warn = append(warn, zone)
+			}
+		}
+	}
+	return avoid, warn
+}
+
+// passageEstimator calcula a rota direta entre as paradas (ainda sem desvios) e estima a passagem em cada zona
+// pela linha do tempo dessa rota. Se o motor falhar, usa a estimativa em linha reta.
+func (s *Service) passageEstimator(ctx context.Context, stops []Location, profile string) func(RiskZone) time.Duration {
+	straight := func(zone RiskZone) time.Duration { return s.estimatedPassageOffset(zone, stops) }
+	if len(stops) < 2 {
+		return straight
+	}
+
+	resp, err := s.engineRoute(ctx, 15*time.Second, RouteRequest{Coordinates: stops, Profile: profile})
+	if err != nil {
+		log.Printf("Erro ao calcular rota para o horário de passagem nas zonas, usando linha reta: %v", err)
+		return straight
+	}
+	geometry, err := routeGeometryFromPolyline(resp.Routes[0].Geometry)
+	if err != nil || len(geometry.Points) < 2 {
+		return straight
+	}
+	timeline := newRouteTimeline(resp.Routes[0], geometry.Length(), nil)
+
+	return func(zone RiskZone) time.Duration {
+		seconds := timeline.arrivalAtAlong(s.zonePassageAlong(geometry, zone)).DurationSeconds
+		return time.Duration(seconds * float64(time.Second))
+	}
+}
+
+// zonePassageAlong devolve a posição (m na geometria) em que a rota entra na zona ou, se não entra, o ponto
+// da rota mais próximo dela
+func (s *Service) zonePassageAlong(geometry *RouteGeometry, zone RiskZone) float64 {
+	best, along := math.Inf(1), 0.0
+	for i, p := range geometry.Points {
+		gap := s.riskZoneGap(p.Lat, p.Lng, zone)
+		if gap <= 0 {
+			return geometry.Cumulative[i]
+		}
+		if gap < best {
+			best, along = gap, geometry.Cumulative[i]
+		}
+	}
+	return along
+}
+
+// estimatedPassageOffset estima o tempo desde a saída até a zona sem a rota: distância ao longo das paradas até
+// o trecho mais próximo da zona, corrigida pela sinuosidade, na velocidade média
+func (s *Service) estimatedPassageOffset(zone RiskZone, stops []Location) time.Duration {
+	if len(stops) < 2 {
+		return 0
+	}
+
+	var along, cum float64
+	best := -1.0
+	for i := 0; i < len(stops)-1; i++ {
+		a, b := stops[i], stops[i+1]
+		segment := s.haversineDistance(a.Latitude, a.Longitude, b.Latitude, b.Longitude)
+		distance := s.distancePointToLine(zone.Lat, zone.Lng, a.Latitude, a.Longitude, b.Latitude, b.Longitude)
+		if best < 0 || distance < best {
+			best = distance
+			along = cum + min(s.haversineDistance(a.Latitude, a.Longitude, zone.Lat, zone.Lng), segment)
+		}
+		cum += segment
+	}
+
+	seconds := along * passageDetourFactor / passageSpeed
+	return time.Duration(seconds * float64(time.Second))
+}
@@ -0,0 +1,115 @@
+package new_routes
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+)
+
+// testCorridorZone é um corredor de 500 m de cada lado de uma linha de ~11 km sobre o equador; o círculo
+// que o envolve tem mais de 6 km de raio
+func testCorridorZone() RiskZone {
+	return RiskZone{
+		Lat:          0,
+		Lng:          0.05,
+		Radius:       6100,
+		Shape:        "corridor",
+		Geometry:     json.RawMessage(`{"type":"LineString","coordinates":[[0,0],[0.1,0]]}`),
+		BufferMeters: 500,
+	}
+}
+
+func TestPushToOutside(t *testing.T) {
+	s := &Service{}
+	circle := RiskZone{Lat: 0, Lng: 0, Radius: 1000}
+	corridor := testCorridorZone()
+
+	tests := []struct {
+		name    string
+		zone    RiskZone
+		p       Location
+		extra   float64
+		minGap  float64
+		maxMove float64
+	}{
+		{name: "círculo: do centro até a borda", zone: circle, p: Location{Latitude: 0.001, Longitude: 0}, extra: 200, minGap: 195, maxMove: 1100},
+		{name: "corredor: sai pela lateral, não pela ponta", zone: corridor, p: Location{Latitude: 0.001, Longitude: 0.03}, extra: 200, minGap: 200, maxMove: 1000},
+		{name: "corredor: ponto já afastado não muda", zone: corridor, p: Location{Latitude: 0.02, Longitude: 0.03}, extra: 200, minGap: 200, maxMove: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := s.pushToOutside(tt.p, tt.zone, tt.extra)
+			if gap := s.riskZoneGap(got.Latitude, got.Longitude, tt.zone); gap < tt.minGap {
+				t.Errorf("distância até a zona = %.0f m, want >= %.0f", gap, tt.minGap)
+			}
+			if move := s.haversineDistance(tt.p.Latitude, tt.p.Longitude, got.Latitude, got.Longitude); move > tt.maxMove+1 {
+				t.Errorf("deslocamento = %.0f m, want <= %.0f", move, tt.maxMove)
+			}
+		})
+	}
+}
+
+func TestPullTowardZone(t *testing.T) {
+	s := &Service{}
+	corridor := testCorridorZone()
+
+	tests := []struct {
+		name      string
+		zone      RiskZone
+		p         Location
+		clearance float64
+		wantSame  bool
+	}{
+		{name: "círculo não muda", zone: RiskZone{Lat: 0, Lng: 0, Radius: 1000}, p: Location{Latitude: 0.02, Longitude: 0}, clearance: 200, wantSame: true},
+		{name: "corredor: ponto do círculo envolvente se aproxima", zone: corridor, p: Location{Latitude: 0.055, Longitude: 0.05}, clearance: 200},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := s.pullTowardZone(tt.p, tt.zone, tt.clearance)
+			if tt.wantSame {
+				if got != tt.p {
+					t.Errorf("pullTowardZone() = %+v, want %+v", got, tt.p)
+				}
+				return
+			}
+			before := s.riskZoneGap(tt.p.Latitude, tt.p.Longitude, tt.zone)
+			after := s.riskZoneGap(got.Latitude, got.Longitude, tt.zone)
+			if after < tt.clearance {
+				t.Errorf("distância após aproximar = %.0f m, want >= %.0f", after, tt.clearance)
+			}
+			if after >= before/2 {
+				t.Errorf("distância após aproximar = %.0f m, antes %.0f m: esperava aproximar bem da forma", after, before)
+			}
+		})
+	}
+}
+
+func TestZonePassageAlong(t *testing.T) {
+	s := &Service{}
+	// rota reta para leste sobre o equador, um ponto a cada ~1113 m
+	geometry := &RouteGeometry{}
+	for i := 0; i <= 10; i++ {
+		geometry.Points = append(geometry.Points, LatLng{Lat: 0, Lng: float64(i) * 0.01})
+		geometry.Cumulative = append(geometry.Cumulative, float64(i)*1113.2)
+	}
+
+	tests := []struct {
+		name string
+		zone RiskZone
+		want float64
+	}{
+		{name: "entra no círculo", zone: RiskZone{Lat: 0, Lng: 0.05, Radius: 1500}, want: 4 * 1113.2},
+		{name: "não entra: ponto mais próximo", zone: RiskZone{Lat: 0.05, Lng: 0.07, Radius: 1000}, want: 7 * 1113.2},
+		{name: "entra no corredor pela borda", zone: testCorridorZone(), want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := s.zonePassageAlong(geometry, tt.zone); math.Abs(got-tt.want) > 0.01 {
+				t.Errorf("zonePassageAlong() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
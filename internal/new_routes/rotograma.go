@@ -0,0 +1,499 @@
+package new_routes
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	db "geolocation/db/sqlc"
+	"geolocation/internal/attachment"
+	"geolocation/pkg/pdf"
+	bucket "geolocation/pkg/s3"
+	"log"
+	"math"
+	"strings"
+	"time"
+)
+
+const (
+	// rotogramaAttachmentType identifica os PDFs de rotograma na tabela attachments
+	rotogramaAttachmentType = "rotograma"
+
+	rotogramaMargin    = 40.0
+	rotogramaMapHeight = 300.0
+	rotogramaLineGap   = 4.0
+)
+
+// RotogramaRequest gera o PDF da rota escolhida numa rota do histórico. Cavalo, carreta e motorista
+// são opcionais e só aparecem no cabeçalho.
+type RotogramaRequest struct {
+	RouteHistID   int64  `json:"route_hist_id" validate:"required,gt=0"`
+	RouteIndex    int64  `json:"route_index" validate:"gte=0"`
+	TractorUnitID int64  `json:"tractor_unit_id"`
+	TrailerID     int64  `json:"trailer_id"`
+	DriverName    string `json:"driver_name"`
+	Locale        string `json:"locale"` // idioma das instruções recalculadas; vazio usa o Accept-Language
+	UserID        int64  `json:"-"`
+}
+
+type RotogramaResponse struct {
+	AttachmentID int64     `json:"attachment_id"`
+	RouteHistID  int64     `json:"route_hist_id"`
+	RouteIndex   int64     `json:"route_index"`
+	FileName     string    `json:"file_name"`
+	URL          string    `json:"url"`
+	Size         int64     `json:"size"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// GenerateRotograma monta o PDF (cabeçalho, mapa da rota, paradas, instruções, pedágios, balanças,
+// zonas de atenção e restrições), envia ao S3 e registra em attachments para download posterior
+func (s *Service) GenerateRotograma(ctx context.Context, data RotogramaRequest) (RotogramaResponse, error) {
+	routeHist, err := s.routeHistForUser(ctx, data.RouteHistID, data.UserID)
+	if err != nil {
+		return RotogramaResponse{}, err
+	}
+
+	var output FinalOutput
+	if err := json.Unmarshal(routeHist.Response, &output); err != nil {
+		return RotogramaResponse{}, fmt.Errorf("erro ao ler rota: %w", err)
+	}
+	if data.RouteIndex < 0 || int(data.RouteIndex) >= len(output.Routes) {
+		return RotogramaResponse{}, errors.New("rota escolhida inválida")
+	}
+	route := s.rotogramaRoute(ctx, output.Summary, output.Routes[data.RouteIndex], data.Locale)
+
+	export, err := newRouteExport(output.Summary, route)
+	if err != nil {
+		return RotogramaResponse{}, err
+	}
+
+	content, err := renderRotograma(output.Summary, route, export, s.rotogramaVehicle(ctx, data, route))
+	if err != nil {
+		return RotogramaResponse{}, fmt.Errorf("erro ao gerar PDF do rotograma: %w", err)
+	}
+
+	fileName := fmt.Sprintf("rotograma-%d-%d.pdf", data.RouteHistID, data.RouteIndex)
+	url, err := bucket.UploadFileToS3(content, fmt.Sprintf("rotogramas/%s.pdf", attachment.GetUUID()), s.AttachmentBucket, "application/pdf")
+	if err != nil {
+		return RotogramaResponse{}, fmt.Errorf("erro ao enviar rotograma: %w", err)
+	}
+
+	stored, err := s.AttachmentRepository.CreateAttachments(ctx, db.CreateAttachmentsParams{
+		UserID:      data.UserID,
+		Description: sql.NullString{String: rotogramaKey(data.RouteHistID, data.RouteIndex), Valid: true},
+		Url:         url,
+		NameFile:    sql.NullString{String: fileName, Valid: true},
+		SizeFile:    sql.NullInt64{Int64: int64(len(content)), Valid: true},
+		Type:        rotogramaAttachmentType,
+	})
+	if err != nil {
+		return RotogramaResponse{}, fmt.Errorf("erro ao registrar rotograma: %w", err)
+	}
+
+	return newRotogramaResponse(stored, data.RouteHistID, data.RouteIndex), nil
+}
+
+// GetRotograma devolve o último rotograma gerado pelo usuário para a rota
+func (s *Service) GetRotograma(ctx context.Context, routeHistID, routeIndex, userID int64) (RotogramaResponse, error) {
+	stored, err := s.AttachmentRepository.GetLatestAttachmentByDescription(ctx, db.GetLatestAttachmentByDescriptionParams{
+		UserID:      userID,
+		Type:        rotogramaAttachmentType,
+		Description: sql.NullString{String: rotogramaKey(routeHistID, routeIndex), Valid: true},
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return RotogramaResponse{}, errors.New("rotograma ainda não gerado para esta rota")
+	}
+	if err != nil {
+		return RotogramaResponse{}, fmt.Errorf("erro ao buscar rotograma: %w", err)
+	}
+	return newRotogramaResponse(stored, routeHistID, routeIndex), nil
+}
+
+func rotogramaKey(routeHistID, routeIndex int64) string {
+	return fmt.Sprintf("route_hist:%d:%d", routeHistID, routeIndex)
+}
+
+func newRotogramaResponse(a db.Attachment, routeHistID, routeIndex int64) RotogramaResponse {
+	return RotogramaResponse{
+		AttachmentID: a.ID,
+		RouteHistID:  routeHistID,
+		RouteIndex:   routeIndex,
+		FileName:     a.NameFile.String,
+		URL:          a.Url,
+		Size:         a.SizeFile.Int64,
+		CreatedAt:    a.CreatedAt,
+	}
+}
+
+// rotogramaRoute completa a rota gravada sem polyline ou sem instruções (calculada sem include_polyline
+// ou include_route_map) recalculando o trajeto pela origem, paradas e destino. Como na reprecificação, usa as
+// exclusões do perfil da rota (route_type) e fica com a alternativa de distância mais próxima da gravada.
+func (s *Service) rotogramaRoute(ctx context.Context, summary Summary, route RouteOutput, locale string) RouteOutput {
+	if (route.Polyline != "" || route.Summary.Polyline != "") && len(route.Instructions) > 0 {
+		return route
+	}
+
+	resp, err := s.engineAlternatives(ctx, 30*time.Second, routeTypeRequest(summaryCoordinates(summary), route.Summary.RouteType), 3)
+	if err != nil || len(resp.Routes) == 0 {
+		log.Printf("Erro ao recalcular rota para o rotograma: %v", err)
+		return route
+	}
+	recalculated := closestRoute(resp.Routes, route.Summary.Distance.Value)
+	if route.Polyline == "" && route.Summary.Polyline == "" {
+		route.Polyline = recalculated.Geometry
+	}
+	if len(route.Instructions) == 0 {
+		route.Instructions = s.processOSRMStepsToInstructions(recalculated, normalizeLocale(locale))
+	}
+	return route
+}
+
+// rotogramaVehicle descreve a composição do usuário para o cabeçalho; falhas na busca só omitem o dado
+func (s *Service) rotogramaVehicle(ctx context.Context, data RotogramaRequest, route RouteOutput) []string {
+	var lines []string
+	if data.TractorUnitID > 0 && s.TractorUnitRepository != nil {
+		tractor, err := s.userTractorUnit(ctx, data.TractorUnitID, data.UserID)
+		if err != nil {
+			log.Printf("Erro ao buscar cavalo %d para o rotograma: %v", data.TractorUnitID, err)
+		} else {
+			lines = append(lines, fmt.Sprintf("Cavalo: %s - %s %s", tractor.LicensePlate, tractor.Brand, tractor.Model))
+		}
+	}
+	if data.TrailerID > 0 && s.TrailerRepository != nil {
+		trailer, err := s.userTrailer(ctx, data.TrailerID, data.UserID)
+		if err != nil {
+			log.Printf("Erro ao buscar carreta %d para o rotograma: %v", data.TrailerID, err)
+		} else {
+			lines = append(lines, fmt.Sprintf("Carreta: %s", trailer.LicensePlate))
+		}
+	}
+	if route.Costs != nil && route.Costs.Axles > 0 {
+		lines = append(lines, fmt.Sprintf("Eixos: %d", route.Costs.Axles))
+	}
+	if data.DriverName != "" {
+		lines = append(lines, fmt.Sprintf("Motorista: %s", data.DriverName))
+	}
+	return lines
+}
+
+// rotogramaWriter controla a posição vertical e quebra de página do PDF
+type rotogramaWriter struct {
+	doc *pdf.Document
+	y   float64
+}
+
+func (w *rotogramaWriter) width() float64 {
+	return w.doc.Width - 2*rotogramaMargin
+}
+
+func (w *rotogramaWriter) ensure(height float64) {
+	if w.doc.PageCount() == 0 || w.y+height > w.doc.Height-rotogramaMargin {
+		w.doc.AddPage()
+		w.y = rotogramaMargin
+	}
+}
+
+func (w *rotogramaWriter) heading(text string) {
+	w.ensure(40)
+	w.y += 18
+	w.doc.SetFillColor(0, 0, 0)
+	w.doc.Text(rotogramaMargin, w.y, 13, true, text)
+	w.y += 4
+	w.doc.SetStrokeColor(160, 160, 160)
+	w.doc.SetLineWidth(0.5)
+	w.doc.Line(rotogramaMargin, w.y, rotogramaMargin+w.width(), w.y)
+	w.y += 6
+}
+
+func (w *rotogramaWriter) paragraph(text string, size float64, bold bool) {
+	for _, line := range pdf.WrapText(text, size, bold, w.width()) {
+		w.ensure(size + rotogramaLineGap)
+		w.y += size
+		w.doc.Text(rotogramaMargin, w.y, size, bold, line)
+		w.y += rotogramaLineGap
+	}
+}
+
+// row escreve uma linha de tabela; cada coluna quebra o texto na sua largura (fração de width)
+func (w *rotogramaWriter) row(cols []string, fractions []float64, bold bool) {
+	const size = 9.0
+	wrapped := make([][]string, len(cols))
+	lines := 1
+	for i, col := range cols {
+		wrapped[i] = pdf.WrapText(col, size, bold, fractions[i]*w.width()-6)
+		if len(wrapped[i]) > lines {
+			lines = len(wrapped[i])
+		}
+	}
+	height := float64(lines)*(size+2) + 4
+	w.ensure(height)
+
+	x := rotogramaMargin
+	for i := range cols {
+		for j, line := range wrapped[i] {
+			w.doc.Text(x, w.y+size+float64(j)*(size+2), size, bold, line)
+		}
+		x += fractions[i] * w.width()
+	}
+	w.y += height
+	w.doc.SetStrokeColor(220, 220, 220)
+	w.doc.SetLineWidth(0.3)
+	w.doc.Line(rotogramaMargin, w.y-2, rotogramaMargin+w.width(), w.y-2)
+}
+
+func renderRotograma(summary Summary, route RouteOutput, export routeExport, vehicle []string) ([]byte, error) {
+	w := &rotogramaWriter{doc: pdf.New()}
+	w.ensure(0)
+
+	w.y += 20
+	w.doc.Text(rotogramaMargin, w.y, 20, true, "Rotograma")
+	w.y += 10
+	w.paragraph(fmt.Sprintf("Origem: %s", summary.LocationOrigin.Address), 10, false)
+	w.paragraph(fmt.Sprintf("Destino: %s", summary.LocationDestination.Address), 10, false)
+	details := fmt.Sprintf("Distância: %s | Duração: %s", rotogramaDistance(route.Summary.Distance.Value), route.Summary.Duration.Text)
+	if route.Summary.RouteType != "" {
+		details = fmt.Sprintf("Rota %s | %s", route.Summary.RouteType, details)
+	}
+	w.paragraph(details, 10, false)
+	w.paragraph(fmt.Sprintf("Combustível: R$ %.2f | Pedágios: R$ %.2f", route.Summary.TotalFuelCost, route.Summary.TotalTolls), 10, false)
+	for _, line := range vehicle {
+		w.paragraph(line, 10, false)
+	}
+	w.paragraph(fmt.Sprintf("Gerado em %s", time.Now().Format("02/01/2006 15:04")), 8, false)
+
+	w.heading("Visão geral da rota")
+	w.ensure(rotogramaMapHeight + 30)
+	drawRotogramaMap(w.doc, rotogramaMargin, w.y, w.width(), rotogramaMapHeight, export)
+	w.y += rotogramaMapHeight + 6
+	drawRotogramaLegend(w)
+
+	w.heading("Paradas planejadas")
+	stops := rotogramaStops(summary, route)
+	if len(stops) == 0 {
+		w.paragraph("Sem paradas planejadas.", 9, false)
+	} else {
+		w.row([]string{"Parada", "Local", "Km", "Previsão"}, []float64{0.2, 0.5, 0.1, 0.2}, true)
+		for _, stop := range stops {
+			w.row(stop, []float64{0.2, 0.5, 0.1, 0.2}, false)
+		}
+	}
+
+	w.heading("Instruções")
+	if len(route.Instructions) == 0 {
+		w.paragraph("Instruções indisponíveis para esta rota.", 9, false)
+	} else {
+		fractions := []float64{0.06, 0.64, 0.15, 0.15}
+		w.row([]string{"#", "Instrução", "Distância", "Acumulado"}, fractions, true)
+		var accumulated float64
+		for i, instruction := range route.Instructions {
+			step, total := "-", "-"
+			if instruction.Distance > 0 {
+				accumulated += instruction.Distance
+				step, total = rotogramaDistance(instruction.Distance), rotogramaDistance(accumulated)
+			}
+			w.row([]string{fmt.Sprintf("%d", i+1), instruction.Text, step, total}, fractions, false)
+		}
+	}
+
+	tolls := route.Tolls
+	if len(tolls) == 0 {
+		tolls = route.Summary.Tolls
+	}
+	w.heading("Pedágios")
+	if len(tolls) == 0 {
+		w.paragraph("Sem pedágios no trajeto.", 9, false)
+	} else {
+		fractions := []float64{0.3, 0.15, 0.13, 0.13, 0.29}
+		w.row([]string{"Praça", "Rodovia", "Dinheiro", "Tag", "Tags aceitas"}, fractions, true)
+		for _, toll := range tolls {
+			w.row([]string{
+				toll.Name,
+				strings.TrimSpace(toll.Road + " " + toll.State),
+				fmt.Sprintf("R$ %.2f", toll.CashCost),
+				fmt.Sprintf("R$ %.2f", toll.TagCost),
+				strings.Join(toll.TagPrimary, ", "),
+			}, fractions, false)
+		}
+	}
+
+	balancas := exportBalancas(route.Balances)
+	w.heading("Balanças")
+	if len(balancas) == 0 {
+		w.paragraph("Sem balanças no trajeto.", 9, false)
+	} else {
+		fractions := []float64{0.35, 0.25, 0.1, 0.3}
+		w.row([]string{"Balança", "Rodovia / km", "UF", "Sentido"}, fractions, true)
+		for _, b := range balancas {
+			w.row([]string{b.Nome, fmt.Sprintf("%s km %s", b.Rodovia, b.Km), b.Uf, b.Sentido}, fractions, false)
+		}
+	}
+
+	w.heading("Zonas de atenção e risco")
+	if len(export.Segments) == 0 && route.Summary.RiskInfo == nil {
+		w.paragraph("Nenhuma zona de atenção no trajeto.", 9, false)
+	} else {
+		fractions := []float64{0.5, 0.25, 0.25}
+		w.row([]string{"Zona", "Entrada (km)", "Saída (km)"}, fractions, true)
+		for _, segment := range export.Segments {
+			entry, _ := segment.Properties["entry_m"].(float64)
+			exit, _ := segment.Properties["exit_m"].(float64)
+			w.row([]string{segment.Name, fmt.Sprintf("%.1f", entry/1000), fmt.Sprintf("%.1f", exit/1000)}, fractions, false)
+		}
+		if risk := route.Summary.RiskInfo; risk != nil {
+			w.row([]string{risk.Zone.Name + " (risco)", fmt.Sprintf("%.1f", risk.EntryCum/1000), fmt.Sprintf("%.1f", risk.ExitCum/1000)}, fractions, false)
+		}
+	}
+
+	if len(route.Summary.Restrictions) > 0 {
+		w.heading("Restrições ao veículo")
+		fractions := []float64{0.45, 0.2, 0.35}
+		w.row([]string{"Local", "Restrição", "Limite / veículo"}, fractions, true)
+		for _, r := range route.Summary.Restrictions {
+			w.row([]string{strings.TrimSpace(r.Name + " " + r.Road), r.Restriction, fmt.Sprintf("%.2f / %.2f", r.Limit, r.VehicleValue)}, fractions, false)
+		}
+	}
+
+	return w.doc.Bytes()
+}
+
+// rotogramaStops junta as paradas pedidas pelo usuário e as pausas obrigatórias do motorista
+func rotogramaStops(summary Summary, route RouteOutput) [][]string {
+	var stops [][]string
+	var waypoints []GeocodeResult
+	if raw, err := json.Marshal(summary.AllStoppingPoints); err == nil {
+		_ = json.Unmarshal(raw, &waypoints)
+	}
+	for i, wp := range waypoints {
+		stops = append(stops, []string{fmt.Sprintf("Parada %d", i+1), wp.FormattedAddress, "-", "-"})
+	}
+
+	if itinerary := route.Summary.DriverItinerary; itinerary != nil {
+		for _, stop := range itinerary.Stops {
+			kind := "Pausa 30 min"
+			if stop.Type == DriverStopDailyRest {
+				kind = "Descanso diário"
+			}
+			place := stop.Name
+			if place == "" {
+				place = fmt.Sprintf("Na rota (%.5f, %.5f)", stop.Location.Latitude, stop.Location.Longitude)
+			}
+			eta := stop.Elapsed.Text
+			if stop.ETA != nil {
+				eta = stop.ETA.Format("02/01 15:04")
+			}
+			stops = append(stops, []string{kind, place, fmt.Sprintf("%.0f", stop.DistanceFromOrigin), eta})
+		}
+	}
+	return stops
+}
+
+func rotogramaDistance(meters float64) string {
+	if meters < 1000 {
+		return fmt.Sprintf("%.0f m", meters)
+	}
+	return fmt.Sprintf("%.1f km", meters/1000)
+}
+
+// Cores (RGB) dos elementos no mapa e na legenda
+var rotogramaColors = map[string][3]int{
+	exportKindRoute:       {30, 90, 200},
+	exportKindAttention:   {210, 30, 30},
+	exportKindOrigin:      {40, 160, 60},
+	exportKindDestination: {200, 40, 40},
+	exportKindToll:        {230, 180, 0},
+	exportKindBalanca:     {240, 120, 0},
+	exportKindGasStation:  {120, 120, 120},
+}
+
+// drawRotogramaMap desenha a rota em projeção equiretangular (longitude corrigida pela latitude média),
+// mantendo a proporção dentro da caixa
+func drawRotogramaMap(doc *pdf.Document, x, y, width, height float64, export routeExport) {
+	doc.SetStrokeColor(180, 180, 180)
+	doc.SetLineWidth(0.5)
+	doc.Rect(x, y, width, height, false)
+	if len(export.Line) < 2 {
+		return
+	}
+
+	minLat, maxLat := math.Inf(1), math.Inf(-1)
+	minLng, maxLng := math.Inf(1), math.Inf(-1)
+	for _, p := range export.Line {
+		minLat, maxLat = math.Min(minLat, p.Lat), math.Max(maxLat, p.Lat)
+		minLng, maxLng = math.Min(minLng, p.Lng), math.Max(maxLng, p.Lng)
+	}
+	cosLat := math.Cos((minLat + maxLat) / 2 * math.Pi / 180)
+	spanX := math.Max((maxLng-minLng)*cosLat, 1e-6)
+	spanY := math.Max(maxLat-minLat, 1e-6)
+	const padding = 15.0
+	scale := math.Min((width-2*padding)/spanX, (height-2*padding)/spanY)
+	offsetX := x + (width-spanX*scale)/2
+	offsetY := y + (height-spanY*scale)/2
+	project := func(lat, lng float64) [2]float64 {
+		return [2]float64{offsetX + (lng-minLng)*cosLat*scale, offsetY + (maxLat-lat)*scale}
+	}
+	projectLine := func(points []LatLng) [][2]float64 {
+		out := make([][2]float64, 0, len(points))
+		for _, p := range points {
+			out = append(out, project(p.Lat, p.Lng))
+		}
+		return out
+	}
+
+	c := rotogramaColors[exportKindRoute]
+	doc.SetStrokeColor(c[0], c[1], c[2])
+	doc.SetLineWidth(2)
+	doc.Polyline(projectLine(export.Line))
+
+	c = rotogramaColors[exportKindAttention]
+	doc.SetStrokeColor(c[0], c[1], c[2])
+	doc.SetLineWidth(4)
+	for _, segment := range export.Segments {
+		doc.Polyline(projectLine(segment.Points))
+	}
+
+	// Postos primeiro para que pedágios, balanças, origem e destino fiquem por cima
+	for _, kind := range []string{exportKindGasStation, exportKindToll, exportKindBalanca, exportKindOrigin, exportKindDestination} {
+		c := rotogramaColors[kind]
+		doc.SetFillColor(c[0], c[1], c[2])
+		radius := 3.0
+		if kind == exportKindOrigin || kind == exportKindDestination {
+			radius = 5
+		}
+		for _, point := range export.Points {
+			if point.Kind != kind {
+				continue
+			}
+			p := project(point.Location.Latitude, point.Location.Longitude)
+			doc.Circle(p[0], p[1], radius, true)
+		}
+	}
+	doc.SetFillColor(0, 0, 0)
+}
+
+func drawRotogramaLegend(w *rotogramaWriter) {
+	items := []struct {
+		kind  string
+		label string
+	}{
+		{exportKindRoute, "Rota"},
+		{exportKindAttention, "Zona de atenção"},
+		{exportKindOrigin, "Origem"},
+		{exportKindDestination, "Destino"},
+		{exportKindToll, "Pedágio"},
+		{exportKindBalanca, "Balança"},
+		{exportKindGasStation, "Posto"},
+	}
+	w.ensure(16)
+	x := rotogramaMargin
+	for _, item := range items {
+		c := rotogramaColors[item.kind]
+		w.doc.SetFillColor(c[0], c[1], c[2])
+		w.doc.Rect(x, w.y+2, 8, 8, true)
+		w.doc.SetFillColor(0, 0, 0)
+		w.doc.Text(x+11, w.y+9, 8, false, item.label)
+		x += 11 + pdf.TextWidth(item.label, 8, false) + 12
+	}
+	w.y += 16
+}
@@ -0,0 +1,66 @@
+package new_routes
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+// alternativesEngine devolve rotas fixas e guarda a última requisição de alternativas
+type alternativesEngine struct {
+	RoutingEngine
+	routes []OSRMRoute
+	req    RouteRequest
+}
+
+func (e *alternativesEngine) Alternatives(_ context.Context, req RouteRequest, _ int) (OSRMResponse, error) {
+	e.req = req
+	return OSRMResponse{Code: "Ok", Routes: e.routes}, nil
+}
+
+func TestRotogramaRouteRecalculatesRouteType(t *testing.T) {
+	summary := Summary{
+		LocationOrigin:      AddressInfo{Location: Location{Latitude: -23.55, Longitude: -46.63}},
+		LocationDestination: AddressInfo{Location: Location{Latitude: -22.9, Longitude: -47.06}},
+	}
+	engine := &alternativesEngine{routes: []OSRMRoute{
+		{Distance: 95000, Geometry: "curta"},
+		{Distance: 118000, Geometry: "sem_pedagio"},
+		{Distance: 160000, Geometry: "longa"},
+	}}
+	s := &Service{Engine: engine}
+
+	tests := []struct {
+		routeType    string
+		distance     float64
+		wantExclude  []string
+		wantUTurn    bool
+		wantPolyline string
+	}{
+		{routeType: "cheapest", distance: 120000, wantExclude: []string{"toll"}, wantPolyline: "sem_pedagio"},
+		{routeType: "efficient", distance: 150000, wantExclude: []string{"motorway"}, wantPolyline: "longa"},
+		{routeType: "fastest", distance: 96000, wantUTurn: true, wantPolyline: "curta"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.routeType, func(t *testing.T) {
+			route := RouteOutput{
+				Summary:      RouteSummary{RouteType: tt.routeType, Distance: Distance{Value: tt.distance}},
+				Instructions: []Instruction{{Text: "Siga em frente"}},
+			}
+			got := s.rotogramaRoute(context.Background(), summary, route, "pt-BR")
+			if !reflect.DeepEqual(engine.req.Exclude, tt.wantExclude) || engine.req.AllowUTurn != tt.wantUTurn {
+				t.Errorf("requisição com exclude %v e retorno %v, want %v e %v", engine.req.Exclude, engine.req.AllowUTurn, tt.wantExclude, tt.wantUTurn)
+			}
+			if len(engine.req.Coordinates) != 2 {
+				t.Errorf("coordenadas = %d, want origem e destino", len(engine.req.Coordinates))
+			}
+			if got.Polyline != tt.wantPolyline {
+				t.Errorf("polyline = %q, want %q", got.Polyline, tt.wantPolyline)
+			}
+			if len(got.Instructions) != 1 {
+				t.Errorf("instruções gravadas foram trocadas: %+v", got.Instructions)
+			}
+		})
+	}
+}
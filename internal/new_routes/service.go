@@ -8,11 +8,15 @@ import (
 	"fmt"
 	db "geolocation/db/sqlc"
 	"geolocation/internal/address"
+	"geolocation/internal/attachment"
 	"geolocation/internal/get_token"
 	"geolocation/internal/route_enterprise"
 	"geolocation/internal/routes"
+	"geolocation/internal/tractor_unit"
+	"geolocation/internal/trailer"
 	"geolocation/internal/zonas_risco"
 	cache "geolocation/pkg"
+	"geolocation/pkg/email"
 	"geolocation/validation"
 	"log"
 	"math"
@@ -45,6 +49,18 @@ type InterfaceService interface {
 	GetSimpleRoute(data SimpleRouteRequest) (SimpleRouteResponse, error)
 	GetCoordinatesFromAddress(ctx context.Context, street, number, city, state, cep string) (AddressCoordinatesResponse, error)
 	CalculateRoutesWithCEPOnly(ctx context.Context, frontInfo FrontInfoCEP, idPublicToken int64, idSimp int64, payloadSimp get_token.PayloadDTO) (FinalOutputPrecision, error)
+	PlanFleet(ctx context.Context, data FleetPlanRequest) (FleetPlanResponse, error)
+	CalculateIsochrones(ctx context.Context, data IsochroneRequest) (IsochroneResponse, error)
+	CalculateMatrix(ctx context.Context, data MatrixRequest) (MatrixResponse, error)
+	AnalyzeTags(ctx context.Context, data TagAnalysisDTO) (TagAnalysisResponse, error)
+	PlanRefuel(ctx context.Context, data RefuelPlanRequest) (RefuelPlanResponse, error)
+	ExportRoute(ctx context.Context, data RouteExportRequest) (RouteExportFile, error)
+	GenerateRotograma(ctx context.Context, data RotogramaRequest) (RotogramaResponse, error)
+	GetRotograma(ctx context.Context, routeHistID, routeIndex, userID int64) (RotogramaResponse, error)
+	RepriceRoute(ctx context.Context, data RepriceRequest) (RepriceResponse, error)
+	GetRouteVersions(ctx context.Context, data RepriceRequest) ([]RouteVersionResponse, error)
+	GetEmissionsReport(ctx context.Context, data EmissionsReportRequest) (EmissionsReport, error)
+	ExportEmissionsReport(ctx context.Context, data EmissionsReportRequest) (RouteExportFile, error)
 }
 
 type Service struct {
@@ -53,16 +69,63 @@ type Service struct {
 	GoogleMapsAPIKey         string
 	RiskZonesRepository      zonas_risco.InterfaceService
 	CEPRepository            address.InterfaceRepository
+	Engine                   RoutingEngine
+	TractorUnitRepository    tractor_unit.InterfaceRepository
+	TrailerRepository        trailer.InterfaceRepository
+	POIIndex                 *POIIndex
+	AttachmentRepository     attachment.InterfaceRepository
+	AttachmentBucket         string
+	SendEmail                *email.SendEmail
+	repricingInterval        time.Duration
+	repricingThreshold       float64
 }
 
-func NewRoutesNewService(interfaceService routes.InterfaceRepository, interfaceRouteEnterprise route_enterprise.InterfaceRepository, googleMapsAPIKey string, RiskZonesRepository zonas_risco.InterfaceService, CEPRepository address.InterfaceRepository) *Service {
-	return &Service{
+func NewRoutesNewService(interfaceService routes.InterfaceRepository, interfaceRouteEnterprise route_enterprise.InterfaceRepository, googleMapsAPIKey string, RiskZonesRepository zonas_risco.InterfaceService, CEPRepository address.InterfaceRepository, engine RoutingEngine, tractorUnitRepository tractor_unit.InterfaceRepository, trailerRepository trailer.InterfaceRepository, poiIndex *POIIndex, attachmentRepository attachment.InterfaceRepository, attachmentBucket string, sendEmail *email.SendEmail, repricingInterval, repricingThreshold string) *Service {
+	s := &Service{
 		InterfaceService:         interfaceService,
 		InterfaceRouteEnterprise: interfaceRouteEnterprise,
 		GoogleMapsAPIKey:         googleMapsAPIKey,
 		RiskZonesRepository:      RiskZonesRepository,
 		CEPRepository:            CEPRepository,
+		Engine:                   engine,
+		TractorUnitRepository:    tractorUnitRepository,
+		TrailerRepository:        trailerRepository,
+		POIIndex:                 poiIndex,
+		AttachmentRepository:     attachmentRepository,
+		AttachmentBucket:         attachmentBucket,
+		SendEmail:                sendEmail,
+		repricingInterval:        defaultRepricingInterval,
+		repricingThreshold:       defaultRepricingThreshold,
+	}
+	if repricingInterval != "" {
+		if d, err := time.ParseDuration(repricingInterval); err == nil && d > 0 {
+			s.repricingInterval = d
+		} else {
+			log.Printf("ROUTE_REPRICING_INTERVAL inválido (%q), usando %s", repricingInterval, defaultRepricingInterval)
+		}
+	}
+	if repricingThreshold != "" {
+		if t, err := strconv.ParseFloat(repricingThreshold, 64); err == nil && t > 0 {
+			s.repricingThreshold = t
+		} else {
+			log.Printf("ROUTE_REPRICING_THRESHOLD inválido (%q), usando %.0f%%", repricingThreshold, defaultRepricingThreshold)
+		}
 	}
+	return s
+}
+
+// engineRoute calcula uma única rota no motor configurado, limitada pelo timeout informado
+func (s *Service) engineRoute(ctx context.Context, timeout time.Duration, req RouteRequest) (OSRMResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	return s.Engine.Route(ctx, req)
+}
+
+// engineAlternatives calcula a rota principal e até n alternativas no motor configurado
+func (s *Service) engineAlternatives(ctx context.Context, timeout time.Duration, req RouteRequest, n int) (OSRMResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	return s.Engine.Alternatives(ctx, req, n)
 }
 
 type LocationPrecision struct {
@@ -74,9 +137,10 @@ type LocationPrecision struct {
 }
 
 type FuelCosts struct {
-	TotalFuelCost float64 `json:"total_fuel_cost"`
-	FuelInTheCity float64 `json:"fuel_in_the_city"`
-	FuelInTheHwy  float64 `json:"fuel_in_the_hwy"`
+	TotalFuelCost float64   `json:"total_fuel_cost"`
+	FuelInTheCity float64   `json:"fuel_in_the_city"`
+	FuelInTheHwy  float64   `json:"fuel_in_the_hwy"`
+	FuelSplit     FuelSplit `json:"fuel_split"`
 }
 
 type SummaryPrecision struct {
@@ -161,13 +225,19 @@ func (s *Service) CalculateRoutes(ctx context.Context, frontInfo FrontInfo, idPu
 		}
 	}
 
+	vehicle, err := s.resolveVehicleInfo(ctx, frontInfo.VehicleInfo, frontInfo.Axles, idSimp)
+	if err != nil {
+		return FinalOutput{}, err
+	}
+	frontInfo.VehicleInfo = vehicle
+
 	cacheKey := fmt.Sprintf("route:%s:%s:%s:axles:%d:type:%s",
 		strings.ToLower(frontInfo.Origin),
 		strings.ToLower(frontInfo.Destination),
 		strings.ToLower(strings.Join(frontInfo.Waypoints, ",")),
 		frontInfo.Axles,
 		strings.ToLower(frontInfo.Type),
-	)
+	) + frontInfo.cacheKey() + departureCacheKey(frontInfo.DepartureTime) + driverRulesCacheKey(frontInfo.DriverRules) + localeCacheKey(frontInfo.Locale)
 	cached, err := cache.Rdb.Get(ctx, cacheKey).Result()
 	if err == nil {
 		var cachedOutput FinalOutput
@@ -192,7 +262,7 @@ func (s *Service) CalculateRoutes(ctx context.Context, frontInfo FrontInfo, idPu
 			routeHistID, errSavedRoutes := s.savedRoutes(ctx, frontInfo.PublicOrPrivate,
 				cachedOutput.Summary.LocationOrigin.Address,
 				cachedOutput.Summary.LocationDestination.Address,
-				waypointsStr, idPublicToken, idSimp, responseJSON, requestJSON, frontInfo.Favorite)
+				waypointsStr, idPublicToken, idSimp, responseJSON, requestJSON, frontInfo.Favorite, frontInfo.TokenOrgID)
 			if errSavedRoutes != nil {
 				// Erro ao salvar rota/favorita (cache)
 			}
@@ -238,34 +308,11 @@ func (s *Service) CalculateRoutes(ctx context.Context, frontInfo FrontInfo, idPu
 		FuelUnit: "liter",
 	}
 
-	coords := fmt.Sprintf("%f,%f", origin.Location.Longitude, origin.Location.Latitude)
+	coordinates := []Location{origin.Location}
 	for _, wp := range waypointResults {
-		coords += fmt.Sprintf(";%f,%f", wp.Location.Longitude, wp.Location.Latitude)
-	}
-	coords += fmt.Sprintf(";%f,%f", destination.Location.Longitude, destination.Location.Latitude)
-	baseOSRMURL := "http://34.207.174.233:5000/route/v1/driving/" + neturl.PathEscape(coords)
-	client := http.Client{Timeout: 120 * time.Second}
-
-	osrmURLFast := baseOSRMURL + "?" + neturl.Values{
-		"alternatives":      {"3"},
-		"steps":             {"true"},
-		"overview":          {"full"},
-		"continue_straight": {"false"},
-	}.Encode()
-
-	osrmURLNoTolls := baseOSRMURL + "?" + neturl.Values{
-		"alternatives": {"3"},
-		"steps":        {"true"},
-		"overview":     {"full"},
-		"exclude":      {"toll"},
-	}.Encode()
-
-	osrmURLEfficient := baseOSRMURL + "?" + neturl.Values{
-		"alternatives": {"3"},
-		"steps":        {"true"},
-		"overview":     {"full"},
-		"exclude":      {"motorway"},
-	}.Encode()
+		coordinates = append(coordinates, wp.Location)
+	}
+	coordinates = append(coordinates, destination.Location)
 
 	type osrmResult struct {
 		resp     OSRMResponse
@@ -274,28 +321,22 @@ func (s *Service) CalculateRoutes(ctx context.Context, frontInfo FrontInfo, idPu
 	}
 	resultsCh := make(chan osrmResult, 3)
 
-	makeOSRMRequest := func(url, category, errMsg string) {
-		resp, err := client.Get(url)
-		if err != nil {
-			resultsCh <- osrmResult{err: fmt.Errorf("%s: %w", errMsg, err), category: category}
-			return
-		}
-		defer resp.Body.Close()
-		var osrmResp OSRMResponse
-		if err := json.NewDecoder(resp.Body).Decode(&osrmResp); err != nil {
-			resultsCh <- osrmResult{err: fmt.Errorf("erro ao decodificar resposta OSRM (%s): %w", category, err), category: category}
+	makeOSRMRequest := func(req RouteRequest, category, errMsg string) {
+		osrmResp, err := s.engineAlternatives(ctx, 120*time.Second, req, 3)
+		if errors.Is(err, ErrNoRoute) {
+			resultsCh <- osrmResult{err: fmt.Errorf("OSRM (%s) retornou erro ou nenhuma rota encontrada", category), category: category}
 			return
 		}
-		if osrmResp.Code != "Ok" || len(osrmResp.Routes) == 0 {
-			resultsCh <- osrmResult{err: fmt.Errorf("OSRM (%s) retornou erro ou nenhuma rota encontrada", category), category: category}
+		if err != nil {
+			resultsCh <- osrmResult{err: fmt.Errorf("%s: %w", errMsg, err), category: category}
 			return
 		}
-		resultsCh <- osrmResult{resp: osrmResp, category: category}
+		resultsCh <- osrmResult{resp: s.preferCompliantRoutes(ctx, osrmResp, req, frontInfo.VehicleInfo), category: category}
 	}
 
-	go makeOSRMRequest(osrmURLFast, "fatest", "erro na requisição OSRM (rota rápida)")
-	go makeOSRMRequest(osrmURLNoTolls, "cheapest", "erro na requisição OSRM (rota com menos pedágio)")
-	go makeOSRMRequest(osrmURLEfficient, "efficient", "erro na requisição OSRM (rota eficiente)")
+	go makeOSRMRequest(RouteRequest{Coordinates: coordinates, AllowUTurn: true}, "fatest", "erro na requisição OSRM (rota rápida)")
+	go makeOSRMRequest(RouteRequest{Coordinates: coordinates, Exclude: []string{"toll"}}, "cheapest", "erro na requisição OSRM (rota com menos pedágio)")
+	go makeOSRMRequest(RouteRequest{Coordinates: coordinates, Exclude: []string{"motorway"}}, "efficient", "erro na requisição OSRM (rota eficiente)")
 
 	var osrmRespFast, osrmRespNoTolls, osrmRespEfficient OSRMResponse
 	for i := 0; i < 3; i++ {
@@ -338,26 +379,20 @@ func (s *Service) CalculateRoutes(ctx context.Context, frontInfo FrontInfo, idPu
 	processRoutes := func(osrmResp OSRMResponse, routeCategory string) []RouteOutput {
 		var output []RouteOutput
 		for _, route := range osrmResp.Routes {
-			routeGasStations, err := s.findGasStations(dbCtx, route.Geometry)
+			routeGasStations, err := s.findGasStations(dbCtx, route, frontInfo.DepartureTime)
 			if err != nil {
 				log.Printf("Erro ao consultar postos de gasolina: %v", err)
 				routeGasStations = nil
 			}
 
-			balancas, err := s.InterfaceService.GetBalanca(ctx)
-			if err != nil {
-				log.Printf("Erro ao obter balanças: %v", err)
-				balancas = nil
-			}
-
 			distText, distVal := formatDistance(route.Distance)
 			durText, durVal := formatDuration(route.Duration)
 
 			var finalInstructions []Instruction
 			if len(route.Legs) > 0 {
 				for _, step := range route.Legs[0].Steps {
-					text := translateInstruction(step)
-					instructionLower := strings.ToLower(text)
+					text := translateInstruction(step, frontInfo.Locale)
+					instructionLower := strings.ToLower(translateInstruction(step, LocalePTBR))
 					var valueImg string
 					switch {
 					case strings.Contains(instructionLower, "direita") && (strings.Contains(instructionLower, "curva") || strings.Contains(instructionLower, "mantenha-se")):
@@ -381,13 +416,15 @@ func (s *Service) CalculateRoutes(ctx context.Context, frontInfo FrontInfo, idPu
 					}
 
 					finalInstructions = append(finalInstructions, Instruction{
-						Text: text,
-						Img:  valueImg,
+						Text:         text,
+						Img:          valueImg,
+						Distance:     step.Distance,
+						DistanceText: formatInstructionDistance(step.Distance, frontInfo.Locale),
 					})
 				}
 			}
 
-			rawTolls, err := s.findTollsOnRoute(dbCtx, route.Geometry, frontInfo.Type, float64(frontInfo.Axles))
+			rawTolls, err := s.findTollsOnRoute(dbCtx, route, newTollVehicle(frontInfo.Type, frontInfo.Axles, frontInfo.VehicleInfo), frontInfo.DepartureTime)
 			if err != nil {
 				log.Printf("Erro ao filtrar pedágios: %v", err)
 				rawTolls = nil
@@ -397,7 +434,7 @@ func (s *Service) CalculateRoutes(ctx context.Context, frontInfo FrontInfo, idPu
 				routeTolls = append(routeTolls, t)
 			}
 
-			routeBalancas, err := s.findBalancaOnRoute(route.Geometry, balancas)
+			routeBalancas, err := s.findBalancaOnRoute(ctx, route, frontInfo.DepartureTime)
 			if err != nil {
 				log.Printf("Erro ao filtrar balanças: %v", err)
 				routeBalancas = nil
@@ -413,15 +450,14 @@ func (s *Service) CalculateRoutes(ctx context.Context, frontInfo FrontInfo, idPu
 			routeType := routeCategory
 			var totalTollCost float64
 			for _, toll := range rawTolls {
-				totalTollCost += toll.CashCost
+				totalTollCost += toll.PaidCost
 			}
 
-			fuelCostCity := math.Round((frontInfo.Price / frontInfo.ConsumptionCity) * (float64(distVal) / 1000))
-			fuelCostHwy := math.Round((frontInfo.Price / frontInfo.ConsumptionHwy) * (float64(distVal) / 1000))
+			fuelSplit := s.routeFuelSplit(ctx, route, frontInfo.Type, frontInfo.Price, frontInfo.ConsumptionCity, frontInfo.ConsumptionHwy)
+			totalFuelCost := fuelSplit.TotalCost()
 
-			avgConsumption := (frontInfo.ConsumptionCity + frontInfo.ConsumptionHwy) / 2
-			totalKm := float64(distVal) / 1000
-			totalFuelCost := math.Round((frontInfo.Price / avgConsumption) * totalKm)
+			fuelCostCity := math.Round((fuelSplit.Price / frontInfo.ConsumptionCity) * (float64(distVal) / 1000))
+			fuelCostHwy := math.Round((fuelSplit.Price / frontInfo.ConsumptionHwy) * (float64(distVal) / 1000))
 
 			output = append(output, RouteOutput{
 				Summary: RouteSummary{
@@ -435,23 +471,24 @@ func (s *Service) CalculateRoutes(ctx context.Context, frontInfo FrontInfo, idPu
 						Text:  durText,
 						Value: durVal,
 					},
-					URL:           googleURL,
-					URLWaze:       wazeURL,
-					TotalFuelCost: totalFuelCost,
+					URL:             googleURL,
+					URLWaze:         wazeURL,
+					TotalFuelCost:   totalFuelCost,
+					FuelSplit:       &fuelSplit,
+					Emissions:       routeEmissions(fuelSplit, frontInfo.Type, frontInfo.Axles, frontInfo.VehicleInfo),
+					Restrictions:    s.routeRestrictions(dbCtx, route.Geometry, frontInfo.VehicleInfo),
+					DriverItinerary: s.driverItinerary(dbCtx, route, frontInfo.DriverRules, frontInfo.DepartureTime),
 				},
 				Costs: func() *Costs {
 					if frontInfo.RouteOptions.IncludeTollCosts {
-						return &Costs{
-							TagAndCash:      totalTollCost,
-							FuelInTheCity:   fuelCostCity,
-							FuelInTheHwy:    fuelCostHwy,
-							Tag:             (totalTollCost - (totalTollCost * 0.05)) * float64(frontInfo.Axles),
-							Cash:            totalTollCost * float64(frontInfo.Axles),
-							PrepaidCard:     totalTollCost * float64(frontInfo.Axles),
-							MaximumTollCost: totalTollCost * float64(frontInfo.Axles),
-							MinimumTollCost: totalTollCost * float64(frontInfo.Axles),
-							Axles:           int(frontInfo.Axles),
+						costs := &Costs{
+							FuelInTheCity: fuelCostCity,
+							FuelInTheHwy:  fuelCostHwy,
+							Axles:         int(frontInfo.Axles),
+							FuelSplit:     &fuelSplit,
 						}
+						costs.setTollCosts(rawTolls)
+						return costs
 					}
 					return nil
 				}(),
@@ -522,9 +559,8 @@ func (s *Service) CalculateRoutes(ctx context.Context, frontInfo FrontInfo, idPu
 		distText, distVal := formatDistance(route.Distance)
 		durText, durVal := formatDuration(route.Duration)
 
-		avgConsumption := (frontInfo.ConsumptionCity + frontInfo.ConsumptionHwy) / 2
-		totalKm := float64(distVal) / 1000
-		totalFuelCost := math.Round((frontInfo.Price / avgConsumption) * totalKm)
+		fuelSplit := s.routeFuelSplit(ctx, route, frontInfo.Type, frontInfo.Price, frontInfo.ConsumptionCity, frontInfo.ConsumptionHwy)
+		totalFuelCost := fuelSplit.TotalCost()
 
 		minimalRoute := RouteOutput{
 			Summary: RouteSummary{
@@ -535,6 +571,8 @@ func (s *Service) CalculateRoutes(ctx context.Context, frontInfo FrontInfo, idPu
 				URL:           googleURL,
 				URLWaze:       wazeURL,
 				TotalFuelCost: totalFuelCost,
+				FuelSplit:     &fuelSplit,
+				Emissions:     routeEmissions(fuelSplit, frontInfo.Type, frontInfo.Axles, frontInfo.VehicleInfo),
 			},
 		}
 
@@ -561,7 +599,7 @@ func (s *Service) CalculateRoutes(ctx context.Context, frontInfo FrontInfo, idPu
 					}
 					return stops
 				}(),
-				FuelPrice:      fuelPrice,
+				FuelPrice:      fuelPrice.withRoutePrice([]RouteOutput{minimalRoute}),
 				FuelEfficiency: fuelEfficiency,
 				RouteOptions:   frontInfo.RouteOptions,
 			},
@@ -574,7 +612,7 @@ func (s *Service) CalculateRoutes(ctx context.Context, frontInfo FrontInfo, idPu
 
 		result, errSavedRoutes := s.savedRoutes(ctx, frontInfo.PublicOrPrivate,
 			origin.FormattedAddress, destination.FormattedAddress,
-			waypointsStr, idPublicToken, idSimp, responseJSON, requestJSON, frontInfo.Favorite)
+			waypointsStr, idPublicToken, idSimp, responseJSON, requestJSON, frontInfo.Favorite, frontInfo.TokenOrgID)
 		if errSavedRoutes != nil {
 			return FinalOutput{}, errSavedRoutes
 		}
@@ -628,7 +666,7 @@ func (s *Service) CalculateRoutes(ctx context.Context, frontInfo FrontInfo, idPu
 				}
 				return stops
 			}(),
-			FuelPrice:      fuelPrice,
+			FuelPrice:      fuelPrice.withRoutePrice(combinedRoutes),
 			FuelEfficiency: fuelEfficiency,
 		},
 		Routes: combinedRoutes,
@@ -646,7 +684,7 @@ func (s *Service) CalculateRoutes(ctx context.Context, frontInfo FrontInfo, idPu
 
 	result, errSavedRoutes := s.savedRoutes(ctx, frontInfo.PublicOrPrivate,
 		origin.FormattedAddress, destination.FormattedAddress,
-		waypointsStr, idPublicToken, idSimp, responseJSON, requestJSON, frontInfo.Favorite)
+		waypointsStr, idPublicToken, idSimp, responseJSON, requestJSON, frontInfo.Favorite, frontInfo.TokenOrgID)
 	if errSavedRoutes != nil {
 		return FinalOutput{}, errSavedRoutes
 	}
@@ -683,13 +721,19 @@ func (s *Service) CalculateRoutesWithCEP(ctx context.Context, frontInfo FrontInf
 	}
 
 	waypointsStr := strings.ToLower(strings.Join(frontInfo.WaypointsCEP, ","))
+	vehicle, err := s.resolveVehicleInfo(ctx, frontInfo.VehicleInfo, frontInfo.Axles, idSimp)
+	if err != nil {
+		return FinalOutput{}, err
+	}
+	frontInfo.VehicleInfo = vehicle
+
 	cacheKey := fmt.Sprintf("route_v2:%s:%s:waypoints:%s:axles:%d:type:%s",
 		strings.ToLower(cepOrigin),
 		strings.ToLower(frontInfo.DestinationCEP),
 		waypointsStr,
 		frontInfo.Axles,
 		strings.ToLower(frontInfo.Type),
-	)
+	) + frontInfo.cacheKey() + departureCacheKey(frontInfo.DepartureTime) + driverRulesCacheKey(frontInfo.DriverRules) + localeCacheKey(frontInfo.Locale)
 	cached, err := cache.Rdb.Get(ctx, cacheKey).Result()
 	if err == nil {
 		var cachedOutput FinalOutput
@@ -709,7 +753,7 @@ func (s *Service) CalculateRoutesWithCEP(ctx context.Context, frontInfo FrontInf
 					routeHistID, errSavedRoutes := s.savedRoutes(ctx, frontInfo.PublicOrPrivate,
 						cachedOutput.Summary.LocationOrigin.Address,
 						cachedOutput.Summary.LocationDestination.Address,
-						waypointsStr, idPublicToken, idSimp, responseJSON, requestJSON, frontInfo.Favorite)
+						waypointsStr, idPublicToken, idSimp, responseJSON, requestJSON, frontInfo.Favorite, frontInfo.TokenOrgID)
 
 					if errSavedRoutes != nil {
 						// Erro ao salvar rota/favorita (cache)
@@ -792,34 +836,11 @@ func (s *Service) CalculateRoutesWithCEP(ctx context.Context, frontInfo FrontInf
 		FuelUnit: "liter",
 	}
 
-	coords := fmt.Sprintf("%f,%f", origin.Location.Longitude, origin.Location.Latitude)
+	coordinates := []Location{origin.Location}
 	for _, wp := range waypointResults {
-		coords += fmt.Sprintf(";%f,%f", wp.Location.Longitude, wp.Location.Latitude)
-	}
-	coords += fmt.Sprintf(";%f,%f", destination.Location.Longitude, destination.Location.Latitude)
-	baseOSRMURL := "http://34.207.174.233:5000/route/v1/driving/" + neturl.PathEscape(coords)
-	client := http.Client{Timeout: 120 * time.Second}
-
-	osrmURLFast := baseOSRMURL + "?" + neturl.Values{
-		"alternatives":      {"3"},
-		"steps":             {"true"},
-		"overview":          {"full"},
-		"continue_straight": {"false"},
-	}.Encode()
-
-	osrmURLNoTolls := baseOSRMURL + "?" + neturl.Values{
-		"alternatives": {"3"},
-		"steps":        {"true"},
-		"overview":     {"full"},
-		"exclude":      {"toll"},
-	}.Encode()
-
-	osrmURLEfficient := baseOSRMURL + "?" + neturl.Values{
-		"alternatives": {"3"},
-		"steps":        {"true"},
-		"overview":     {"full"},
-		"exclude":      {"motorway"},
-	}.Encode()
+		coordinates = append(coordinates, wp.Location)
+	}
+	coordinates = append(coordinates, destination.Location)
 
 	type osrmResult struct {
 		resp     OSRMResponse
@@ -828,28 +849,22 @@ func (s *Service) CalculateRoutesWithCEP(ctx context.Context, frontInfo FrontInf
 	}
 	resultsCh := make(chan osrmResult, 3)
 
-	makeOSRMRequest := func(url, category, errMsg string) {
-		resp, err := client.Get(url)
-		if err != nil {
-			resultsCh <- osrmResult{err: fmt.Errorf("%s: %w", errMsg, err), category: category}
-			return
-		}
-		defer resp.Body.Close()
-		var osrmResp OSRMResponse
-		if err := json.NewDecoder(resp.Body).Decode(&osrmResp); err != nil {
-			resultsCh <- osrmResult{err: fmt.Errorf("erro ao decodificar resposta OSRM (%s): %w", category, err), category: category}
+	makeOSRMRequest := func(req RouteRequest, category, errMsg string) {
+		osrmResp, err := s.engineAlternatives(ctx, 120*time.Second, req, 3)
+		if errors.Is(err, ErrNoRoute) {
+			resultsCh <- osrmResult{err: fmt.Errorf("OSRM (%s) retornou erro ou nenhuma rota encontrada", category), category: category}
 			return
 		}
-		if osrmResp.Code != "Ok" {
-			resultsCh <- osrmResult{err: fmt.Errorf("OSRM (%s) retornou erro ou nenhuma rota encontrada", category), category: category}
+		if err != nil {
+			resultsCh <- osrmResult{err: fmt.Errorf("%s: %w", errMsg, err), category: category}
 			return
 		}
-		resultsCh <- osrmResult{resp: osrmResp, category: category}
+		resultsCh <- osrmResult{resp: s.preferCompliantRoutes(ctx, osrmResp, req, frontInfo.VehicleInfo), category: category}
 	}
 
-	go makeOSRMRequest(osrmURLFast, "fatest", "erro na requisição OSRM (rota rápida)")
-	go makeOSRMRequest(osrmURLNoTolls, "cheapest", "erro na requisição OSRM (rota com menos pedágio)")
-	go makeOSRMRequest(osrmURLEfficient, "efficient", "erro na requisição OSRM (rota eficiente)")
+	go makeOSRMRequest(RouteRequest{Coordinates: coordinates, AllowUTurn: true}, "fatest", "erro na requisição OSRM (rota rápida)")
+	go makeOSRMRequest(RouteRequest{Coordinates: coordinates, Exclude: []string{"toll"}}, "cheapest", "erro na requisição OSRM (rota com menos pedágio)")
+	go makeOSRMRequest(RouteRequest{Coordinates: coordinates, Exclude: []string{"motorway"}}, "efficient", "erro na requisição OSRM (rota eficiente)")
 
 	var osrmRespFast, osrmRespNoTolls, osrmRespEfficient OSRMResponse
 	for i := 0; i < 3; i++ {
@@ -906,13 +921,7 @@ func (s *Service) CalculateRoutesWithCEP(ctx context.Context, frontInfo FrontInf
 	processRoutes := func(osrmResp OSRMResponse, routeCategory string) []RouteOutput {
 		var output []RouteOutput
 		for _, route := range osrmResp.Routes {
-			balancas, err := s.InterfaceService.GetBalanca(ctx)
-			if err != nil {
-				log.Printf("Erro ao obter balanças: %v", err)
-				balancas = nil
-			}
-
-			routeGasStations, err := s.findGasStations(dbCtx, osrmRespFast.Routes[0].Geometry)
+			routeGasStations, err := s.findGasStations(dbCtx, osrmRespFast.Routes[0], frontInfo.DepartureTime)
 			if err != nil {
 				log.Printf("Erro ao consultar postos de gasolina: %v", err)
 				routeGasStations = nil
@@ -924,8 +933,8 @@ func (s *Service) CalculateRoutesWithCEP(ctx context.Context, frontInfo FrontInf
 			var finalInstructions []Instruction
 			if len(route.Legs) > 0 {
 				for _, step := range route.Legs[0].Steps {
-					text := translateInstruction(step)
-					instructionLower := strings.ToLower(text)
+					text := translateInstruction(step, frontInfo.Locale)
+					instructionLower := strings.ToLower(translateInstruction(step, LocalePTBR))
 					var valueImg string
 					switch {
 					case strings.Contains(instructionLower, "direita") && (strings.Contains(instructionLower, "curva") || strings.Contains(instructionLower, "mantenha-se")):
@@ -949,13 +958,15 @@ func (s *Service) CalculateRoutesWithCEP(ctx context.Context, frontInfo FrontInf
 					}
 
 					finalInstructions = append(finalInstructions, Instruction{
-						Text: text,
-						Img:  valueImg,
+						Text:         text,
+						Img:          valueImg,
+						Distance:     step.Distance,
+						DistanceText: formatInstructionDistance(step.Distance, frontInfo.Locale),
 					})
 				}
 			}
 
-			rawTolls, err := s.findTollsOnRoute(dbCtx, route.Geometry, frontInfo.Type, float64(frontInfo.Axles))
+			rawTolls, err := s.findTollsOnRoute(dbCtx, route, newTollVehicle(frontInfo.Type, frontInfo.Axles, frontInfo.VehicleInfo), frontInfo.DepartureTime)
 			if err != nil {
 				log.Printf("Erro ao filtrar pedágios: %v", err)
 				rawTolls = nil
@@ -965,7 +976,7 @@ func (s *Service) CalculateRoutesWithCEP(ctx context.Context, frontInfo FrontInf
 				routeTolls = append(routeTolls, t)
 			}
 
-			routeBalancas, err := s.findBalancaOnRoute(route.Geometry, balancas)
+			routeBalancas, err := s.findBalancaOnRoute(ctx, route, frontInfo.DepartureTime)
 			if err != nil {
 				log.Printf("Erro ao filtrar balanças: %v", err)
 				routeBalancas = nil
@@ -981,15 +992,14 @@ func (s *Service) CalculateRoutesWithCEP(ctx context.Context, frontInfo FrontInf
 			routeType := routeCategory
 			var totalTollCost float64
 			for _, toll := range rawTolls {
-				totalTollCost += toll.CashCost
+				totalTollCost += toll.PaidCost
 			}
 
-			fuelCostCity := math.Round((frontInfo.Price / frontInfo.ConsumptionCity) * (float64(distVal) / 1000))
-			fuelCostHwy := math.Round((frontInfo.Price / frontInfo.ConsumptionHwy) * (float64(distVal) / 1000))
+			fuelSplit := s.routeFuelSplit(ctx, route, frontInfo.Type, frontInfo.Price, frontInfo.ConsumptionCity, frontInfo.ConsumptionHwy)
+			totalFuelCost := fuelSplit.TotalCost()
 
-			avgConsumption := (frontInfo.ConsumptionCity + frontInfo.ConsumptionHwy) / 2
-			totalKm := float64(distVal) / 1000
-			totalFuelCost := math.Round((frontInfo.Price / avgConsumption) * totalKm)
+			fuelCostCity := math.Round((fuelSplit.Price / frontInfo.ConsumptionCity) * (float64(distVal) / 1000))
+			fuelCostHwy := math.Round((fuelSplit.Price / frontInfo.ConsumptionHwy) * (float64(distVal) / 1000))
 
 			output = append(output, RouteOutput{
 				Summary: RouteSummary{
@@ -1003,23 +1013,24 @@ func (s *Service) CalculateRoutesWithCEP(ctx context.Context, frontInfo FrontInf
 						Text:  durText,
 						Value: durVal,
 					},
-					URL:           googleURL,
-					URLWaze:       wazeURL,
-					TotalFuelCost: totalFuelCost,
+					URL:             googleURL,
+					URLWaze:         wazeURL,
+					TotalFuelCost:   totalFuelCost,
+					FuelSplit:       &fuelSplit,
+					Emissions:       routeEmissions(fuelSplit, frontInfo.Type, frontInfo.Axles, frontInfo.VehicleInfo),
+					Restrictions:    s.routeRestrictions(dbCtx, route.Geometry, frontInfo.VehicleInfo),
+					DriverItinerary: s.driverItinerary(dbCtx, route, frontInfo.DriverRules, frontInfo.DepartureTime),
 				},
 				Costs: func() *Costs {
 					if frontInfo.RouteOptions.IncludeTollCosts {
-						return &Costs{
-							TagAndCash:      totalTollCost,
-							FuelInTheCity:   fuelCostCity,
-							FuelInTheHwy:    fuelCostHwy,
-							Tag:             (totalTollCost - (totalTollCost * 0.05)) * float64(frontInfo.Axles),
-							Cash:            totalTollCost * float64(frontInfo.Axles),
-							PrepaidCard:     totalTollCost * float64(frontInfo.Axles),
-							MaximumTollCost: totalTollCost * float64(frontInfo.Axles),
-							MinimumTollCost: totalTollCost * float64(frontInfo.Axles),
-							Axles:           int(frontInfo.Axles),
+						costs := &Costs{
+							FuelInTheCity: fuelCostCity,
+							FuelInTheHwy:  fuelCostHwy,
+							Axles:         int(frontInfo.Axles),
+							FuelSplit:     &fuelSplit,
 						}
+						costs.setTollCosts(rawTolls)
+						return costs
 					}
 					return nil
 				}(),
@@ -1090,9 +1101,8 @@ func (s *Service) CalculateRoutesWithCEP(ctx context.Context, frontInfo FrontInf
 		distText, distVal := formatDistance(route.Distance)
 		durText, durVal := formatDuration(route.Duration)
 
-		avgConsumption := (frontInfo.ConsumptionCity + frontInfo.ConsumptionHwy) / 2
-		totalKm := float64(distVal) / 1000
-		totalFuelCost := math.Round((frontInfo.Price / avgConsumption) * totalKm)
+		fuelSplit := s.routeFuelSplit(ctx, route, frontInfo.Type, frontInfo.Price, frontInfo.ConsumptionCity, frontInfo.ConsumptionHwy)
+		totalFuelCost := fuelSplit.TotalCost()
 
 		minimalRoute := RouteOutput{
 			Summary: RouteSummary{
@@ -1103,6 +1113,8 @@ func (s *Service) CalculateRoutesWithCEP(ctx context.Context, frontInfo FrontInf
 				URL:           googleURL,
 				URLWaze:       wazeURL,
 				TotalFuelCost: totalFuelCost,
+				FuelSplit:     &fuelSplit,
+				Emissions:     routeEmissions(fuelSplit, frontInfo.Type, frontInfo.Axles, frontInfo.VehicleInfo),
 			},
 		}
 
@@ -1129,7 +1141,7 @@ func (s *Service) CalculateRoutesWithCEP(ctx context.Context, frontInfo FrontInf
 					}
 					return stops
 				}(),
-				FuelPrice:      fuelPrice,
+				FuelPrice:      fuelPrice.withRoutePrice([]RouteOutput{minimalRoute}),
 				FuelEfficiency: fuelEfficiency,
 			},
 			Routes: []RouteOutput{minimalRoute},
@@ -1153,7 +1165,7 @@ func (s *Service) CalculateRoutesWithCEP(ctx context.Context, frontInfo FrontInf
 
 		result, errSavedRoutes := s.savedRoutes(ctx, frontInfo.PublicOrPrivate,
 			origin.FormattedAddress, destination.FormattedAddress,
-			waypointsStr, idPublicToken, idSimp, responseJSON, requestJSON, frontInfo.Favorite)
+			waypointsStr, idPublicToken, idSimp, responseJSON, requestJSON, frontInfo.Favorite, frontInfo.TokenOrgID)
 		if errSavedRoutes != nil {
 			return FinalOutput{}, errSavedRoutes
 		}
@@ -1207,7 +1219,7 @@ func (s *Service) CalculateRoutesWithCEP(ctx context.Context, frontInfo FrontInf
 				}
 				return stops
 			}(),
-			FuelPrice:      fuelPrice,
+			FuelPrice:      fuelPrice.withRoutePrice(combinedRoutes),
 			FuelEfficiency: fuelEfficiency,
 			RouteOptions:   frontInfo.RouteOptions,
 		},
@@ -1239,7 +1251,7 @@ func (s *Service) CalculateRoutesWithCEP(ctx context.Context, frontInfo FrontInf
 
 	result, errSavedRoutes := s.savedRoutes(ctx, frontInfo.PublicOrPrivate,
 		origin.FormattedAddress, destination.FormattedAddress,
-		waypointsStrResponse, idPublicToken, idSimp, responseJSON, requestJSON, frontInfo.Favorite)
+		waypointsStrResponse, idPublicToken, idSimp, responseJSON, requestJSON, frontInfo.Favorite, frontInfo.TokenOrgID)
 	if errSavedRoutes != nil {
 		return FinalOutput{}, errSavedRoutes
 	}
@@ -1272,10 +1284,26 @@ func (s *Service) CalculateDistancesBetweenPoints(ctx context.Context, data Fron
 		return Response{}, fmt.Errorf("é necessário pelo menos dois pontos para calcular distâncias")
 	}
 
-	client := http.Client{Timeout: 30 * time.Second}
+	vehicle, err := s.resolveVehicleInfo(ctx, data.VehicleInfo, data.Axles, 0)
+	if err != nil {
+		return Response{}, err
+	}
+	data.VehicleInfo = vehicle
+
+	var stopOrder []int
+	if data.Optimize {
+		ceps, order, err := s.optimizeCEPOrder(ctx, data)
+		if err != nil {
+			return Response{}, err
+		}
+		data.CEPs = ceps
+		stopOrder = order
+	}
+
 	var resultRoutes []DetailedRoute
 	var totalDistance float64
 	var totalDuration float64
+	var segmentGeocodes [][2]GeocodeResult
 
 	for i := 0; i < len(data.CEPs)-1; i++ {
 		originCEP := data.CEPs[i]
@@ -1295,11 +1323,10 @@ func (s *Service) CalculateDistancesBetweenPoints(ctx context.Context, data Fron
 		originGeocode, _ := s.getGeocodeAddress(ctx, originAddress)
 		destGeocode, _ := s.getGeocodeAddress(ctx, destAddress)
 
-		coords := fmt.Sprintf("%f,%f;%f,%f",
-			originLon, originLat,
-			destLon, destLat,
-		)
-		baseURL := "http://34.207.174.233:5000/route/v1/driving/" + neturl.PathEscape(coords)
+		coordinates := []Location{
+			{Latitude: originLat, Longitude: originLon},
+			{Latitude: destLat, Longitude: destLon},
+		}
 
 		type osrmResult struct {
 			resp     OSRMResponse
@@ -1308,71 +1335,31 @@ func (s *Service) CalculateDistancesBetweenPoints(ctx context.Context, data Fron
 		}
 		resultsCh := make(chan osrmResult, 3)
 
-		makeRequest := func(params neturl.Values, category string) {
-			fullURL := baseURL + "?" + params.Encode()
-			resp, err := client.Get(fullURL)
+		makeRequest := func(req RouteRequest, category string) {
+			osrmResp, err := s.engineAlternatives(ctx, 30*time.Second, req, 3)
 			if err != nil {
-				resultsCh <- osrmResult{err: err, category: category}
+				resultsCh <- osrmResult{err: fmt.Errorf("erro OSRM %s: %w", category, err), category: category}
 				return
 			}
-			defer resp.Body.Close()
-			var osrmResp OSRMResponse
-			if err := json.NewDecoder(resp.Body).Decode(&osrmResp); err != nil {
-				resultsCh <- osrmResult{err: err, category: category}
-				return
-			}
-			if osrmResp.Code != "Ok" {
-				resultsCh <- osrmResult{err: fmt.Errorf("erro OSRM %s", category), category: category}
-				return
-			}
-			resultsCh <- osrmResult{resp: osrmResp, category: category}
+			resultsCh <- osrmResult{resp: s.preferCompliantRoutes(ctx, osrmResp, req, data.VehicleInfo), category: category}
 		}
 
 		var routeTypes []string
-		if strings.TrimSpace(strings.ToLower(data.TypeRoute)) == "" {
-			go makeRequest(neturl.Values{
-				"alternatives":      {"3"},
-				"steps":             {"true"},
-				"overview":          {"full"},
-				"continue_straight": {"false"},
-			}, "fastest")
-			go makeRequest(neturl.Values{
-				"alternatives": {"3"},
-				"steps":        {"true"},
-				"overview":     {"full"},
-				"exclude":      {"toll"},
-			}, "cheapest")
-			go makeRequest(neturl.Values{
-				"alternatives": {"3"},
-				"steps":        {"true"},
-				"overview":     {"full"},
-				"exclude":      {"motorway"},
-			}, "efficient")
+		// Menor risco parte dos três perfis; a exposição de cada um decide a ordem em scoreRoutes
+		if strings.TrimSpace(strings.ToLower(data.TypeRoute)) == "" || isLowestRiskRoute(data.TypeRoute) {
+			go makeRequest(RouteRequest{Coordinates: coordinates, AllowUTurn: true}, "fastest")
+			go makeRequest(RouteRequest{Coordinates: coordinates, Exclude: []string{"toll"}}, "cheapest")
+			go makeRequest(RouteRequest{Coordinates: coordinates, Exclude: []string{"motorway"}}, "efficient")
 			routeTypes = []string{"fastest", "cheapest", "efficient"}
 		} else {
 			routeTypes = []string{strings.ToLower(data.TypeRoute)}
 			switch routeTypes[0] {
 			case "rapida", "fastest":
-				makeRequest(neturl.Values{
-					"alternatives":      {"3"},
-					"steps":             {"true"},
-					"overview":          {"full"},
-					"continue_straight": {"false"},
-				}, "fastest")
+				makeRequest(RouteRequest{Coordinates: coordinates, AllowUTurn: true}, "fastest")
 			case "barata", "cheapest":
-				makeRequest(neturl.Values{
-					"alternatives": {"3"},
-					"steps":        {"true"},
-					"overview":     {"full"},
-					"exclude":      {"toll"},
-				}, "cheapest")
+				makeRequest(RouteRequest{Coordinates: coordinates, Exclude: []string{"toll"}}, "cheapest")
 			case "eficiente", "efficient":
-				makeRequest(neturl.Values{
-					"alternatives": {"3"},
-					"steps":        {"true"},
-					"overview":     {"full"},
-					"exclude":      {"motorway"},
-				}, "efficient")
+				makeRequest(RouteRequest{Coordinates: coordinates, Exclude: []string{"motorway"}}, "efficient")
 			}
 		}
 
@@ -1387,9 +1374,8 @@ func (s *Service) CalculateDistancesBetweenPoints(ctx context.Context, data Fron
 			route := res.resp.Routes[0]
 			distText, distVal := formatDistance(route.Distance)
 			durText, durVal := formatDuration(route.Duration)
-			avgConsumption := (data.ConsumptionCity + data.ConsumptionHwy) / 2
-			totalKm := route.Distance / 1000
-			totalFuelCost := math.Round((data.Price / avgConsumption) * totalKm)
+			fuelSplit := s.routeFuelSplit(ctx, route, data.Type, data.Price, data.ConsumptionCity, data.ConsumptionHwy)
+			totalFuelCost := fuelSplit.TotalCost()
 
 			googleURL := fmt.Sprintf("https://www.google.com/maps/dir/?api=1&origin=%s&destination=%s",
 				neturl.QueryEscape(normalizeAddress(originGeocode.FormattedAddress)),
@@ -1402,7 +1388,7 @@ func (s *Service) CalculateDistancesBetweenPoints(ctx context.Context, data Fron
 				currentTimeMillis,
 			)
 
-			rawTolls, err := s.findTollsOnRoute(ctx, res.resp.Routes[0].Geometry, data.Type, float64(data.Axles))
+			rawTolls, err := s.findTollsOnRoute(ctx, res.resp.Routes[0], newTollVehicle(data.Type, data.Axles, data.VehicleInfo), data.DepartureTime)
 			if err != nil {
 				log.Printf("Erro ao filtrar pedágios: %v", err)
 				rawTolls = nil
@@ -1414,20 +1400,24 @@ func (s *Service) CalculateDistancesBetweenPoints(ctx context.Context, data Fron
 
 			var totalTollCost float64
 			for _, toll := range routeTolls {
-				totalTollCost += toll.CashCost
+				totalTollCost += toll.PaidCost
 			}
 
 			summaries = append(summaries, RouteSummary{
-				RouteType:     res.category,
-				HasTolls:      len(routeTolls) > 0,
-				Distance:      Distance{Text: distText, Value: distVal},
-				Duration:      Duration{Text: durText, Value: durVal},
-				URL:           googleURL,
-				URLWaze:       wazeURL,
-				TotalFuelCost: totalFuelCost,
-				Tolls:         routeTolls,
-				TotalTolls:    math.Round(totalTollCost*100) / 100,
-				Polyline:      res.resp.Routes[0].Geometry,
+				RouteType:       res.category,
+				HasTolls:        len(routeTolls) > 0,
+				Distance:        Distance{Text: distText, Value: distVal},
+				Duration:        Duration{Text: durText, Value: durVal},
+				URL:             googleURL,
+				URLWaze:         wazeURL,
+				TotalFuelCost:   totalFuelCost,
+				FuelSplit:       &fuelSplit,
+				Emissions:       routeEmissions(fuelSplit, data.Type, data.Axles, data.VehicleInfo),
+				Tolls:           routeTolls,
+				TotalTolls:      math.Round(totalTollCost*100) / 100,
+				Polyline:        res.resp.Routes[0].Geometry,
+				Restrictions:    s.routeRestrictions(ctx, res.resp.Routes[0].Geometry, data.VehicleInfo),
+				DriverItinerary: s.driverItinerary(ctx, res.resp.Routes[0], data.DriverRules, data.DepartureTime),
 			})
 
 			totalDistance += route.Distance
@@ -1445,10 +1435,11 @@ func (s *Service) CalculateDistancesBetweenPoints(ctx context.Context, data Fron
 			},
 			Summaries: summaries,
 		})
+		segmentGeocodes = append(segmentGeocodes, [2]GeocodeResult{originGeocode, destGeocode})
 	}
 
 	var totalRoute TotalSummary
-	var allCoords []string
+	var allCoords []Location
 	var waypoints []string
 	var originLocation, destinationLocation Location
 	for idx, cep := range data.CEPs {
@@ -1456,7 +1447,7 @@ func (s *Service) CalculateDistancesBetweenPoints(ctx context.Context, data Fron
 		if err != nil {
 			return Response{}, fmt.Errorf("erro ao buscar coordenadas para total_route no CEP %s: %w", cep, err)
 		}
-		allCoords = append(allCoords, fmt.Sprintf("%f,%f", coordLon, coordLat))
+		allCoords = append(allCoords, Location{Latitude: coordLat, Longitude: coordLon})
 
 		reverse, err := s.reverseGeocode(coordLat, coordLon)
 		if err != nil || reverse == "" {
@@ -1481,74 +1472,79 @@ func (s *Service) CalculateDistancesBetweenPoints(ctx context.Context, data Fron
 		}
 	}
 
-	coordsStr := strings.Join(allCoords, ";")
-	urlTotal := fmt.Sprintf("http://34.207.174.233:5000/route/v1/driving/%s?alternatives=0&steps=true&overview=full&continue_straight=false", neturl.PathEscape(coordsStr))
-
-	resp, err := client.Get(urlTotal)
+	osrmResp, err := s.engineRoute(ctx, 30*time.Second, RouteRequest{Coordinates: allCoords, AllowUTurn: true})
 	if err == nil {
-		defer resp.Body.Close()
-		var osrmResp OSRMResponse
-		if err := json.NewDecoder(resp.Body).Decode(&osrmResp); err == nil && len(osrmResp.Routes) > 0 {
-			route := osrmResp.Routes[0]
+		route := osrmResp.Routes[0]
 
-			distText, distVal := formatDistance(totalDistance)
-			durText, durVal := formatDuration(totalDuration)
+		distText, distVal := formatDistance(totalDistance)
+		durText, durVal := formatDuration(totalDuration)
 
-			avgConsumption := (data.ConsumptionCity + data.ConsumptionHwy) / 2
-			totalKm := route.Distance / 1000
-			totalFuelCost := math.Round((data.Price / avgConsumption) * totalKm)
+		fuelSplit := s.routeFuelSplit(ctx, route, data.Type, data.Price, data.ConsumptionCity, data.ConsumptionHwy)
+		totalFuelCost := fuelSplit.TotalCost()
 
-			tolls, _ := s.findTollsOnRoute(ctx, route.Geometry, data.Type, float64(data.Axles))
-			var totalTollCost float64
-			for _, toll := range tolls {
-				totalTollCost += toll.CashCost
-			}
+		tolls, _ := s.findTollsOnRoute(ctx, route, newTollVehicle(data.Type, data.Axles, data.VehicleInfo), data.DepartureTime)
+		var totalTollCost float64
+		for _, toll := range tolls {
+			totalTollCost += toll.PaidCost
+		}
 
-			originAddress := waypoints[0]
-			destAddress := waypoints[len(waypoints)-1]
-			waypointStr := ""
-			if len(waypoints) > 2 {
-				waypointStr = "&waypoints=" + neturl.QueryEscape(strings.Join(waypoints[1:len(waypoints)-1], "|"))
-			}
+		originAddress := waypoints[0]
+		destAddress := waypoints[len(waypoints)-1]
+		waypointStr := ""
+		if len(waypoints) > 2 {
+			waypointStr = "&waypoints=" + neturl.QueryEscape(strings.Join(waypoints[1:len(waypoints)-1], "|"))
+		}
 
-			googleURL := fmt.Sprintf("https://www.google.com/maps/dir/?api=1&origin=%s&destination=%s%s&travelmode=driving",
-				neturl.QueryEscape(originAddress),
-				neturl.QueryEscape(destAddress),
-				waypointStr,
-			)
+		googleURL := fmt.Sprintf("https://www.google.com/maps/dir/?api=1&origin=%s&destination=%s%s&travelmode=driving",
+			neturl.QueryEscape(originAddress),
+			neturl.QueryEscape(destAddress),
+			waypointStr,
+		)
 
-			currentTimeMillis := (time.Now().UnixNano() + int64(route.Duration*float64(time.Second))) / int64(time.Millisecond)
-			wazeURL := fmt.Sprintf("https://www.waze.com/pt-BR/live-map/directions/br?to=%s&from=%s&time=%d&reverse=yes",
-				neturl.QueryEscape(destAddress),
-				neturl.QueryEscape(originAddress),
-				currentTimeMillis,
-			)
+		currentTimeMillis := (time.Now().UnixNano() + int64(route.Duration*float64(time.Second))) / int64(time.Millisecond)
+		wazeURL := fmt.Sprintf("https://www.waze.com/pt-BR/live-map/directions/br?to=%s&from=%s&time=%d&reverse=yes",
+			neturl.QueryEscape(destAddress),
+			neturl.QueryEscape(originAddress),
+			currentTimeMillis,
+		)
 
-			totalRoute = TotalSummary{
-				LocationOrigin: AddressInfo{
-					Location: originLocation,
-					Address:  originAddress,
-				},
-				LocationDestination: AddressInfo{
-					Location: destinationLocation,
-					Address:  normalizeAddress(destAddress),
-				},
-				TotalDistance: Distance{Text: distText, Value: distVal},
-				TotalDuration: Duration{Text: durText, Value: durVal},
-				URL:           googleURL,
-				URLWaze:       wazeURL,
-				Tolls:         tolls,
-				TotalTolls:    math.Round(totalTollCost*100) / 100,
-				Polyline:      route.Geometry,
-				TotalFuelCost: totalFuelCost,
-			}
+		totalRoute = TotalSummary{
+			LocationOrigin: AddressInfo{
+				Location: originLocation,
+				Address:  originAddress,
+			},
+			LocationDestination: AddressInfo{
+				Location: destinationLocation,
+				Address:  normalizeAddress(destAddress),
+			},
+			TotalDistance:   Distance{Text: distText, Value: distVal},
+			TotalDuration:   Duration{Text: durText, Value: durVal},
+			URL:             googleURL,
+			URLWaze:         wazeURL,
+			Tolls:           tolls,
+			TotalTolls:      math.Round(totalTollCost*100) / 100,
+			Polyline:        route.Geometry,
+			TotalFuelCost:   totalFuelCost,
+			FuelSplit:       &fuelSplit,
+			Emissions:       routeEmissions(fuelSplit, data.Type, data.Axles, data.VehicleInfo),
+			Restrictions:    s.routeRestrictions(ctx, route.Geometry, data.VehicleInfo),
+			DriverItinerary: s.driverItinerary(ctx, route, data.DriverRules, data.DepartureTime),
 		}
 	}
 
-	return Response{
-		Routes:     resultRoutes,
-		TotalRoute: totalRoute,
-	}, nil
+	totalRoute, totalRoutes := s.scoreRoutes(ctx, resultRoutes, segmentGeocodes, totalRoute, allCoords, waypoints, data)
+
+	resp := Response{
+		Routes:      resultRoutes,
+		TotalRoute:  totalRoute,
+		TotalRoutes: totalRoutes,
+		StopOrder:   stopOrder,
+	}
+	if data.Optimize {
+		resp.OptimizedBy = data.criteria()
+	}
+	s.recordTotalRouteEmissions(ctx, totalRoute, data.TokenOrgID, data.Type)
+	return resp, nil
 }
 
 func (s *Service) CalculateDistancesFromOrigin(ctx context.Context, data FrontInfoCEPRequest) ([]DetailedRoute, error) {
@@ -1556,7 +1552,12 @@ func (s *Service) CalculateDistancesFromOrigin(ctx context.Context, data FrontIn
 		return nil, fmt.Errorf("é necessário pelo menos dois pontos para calcular distâncias")
 	}
 
-	client := http.Client{Timeout: 30 * time.Second}
+	vehicle, err := s.resolveVehicleInfo(ctx, data.VehicleInfo, data.Axles, 0)
+	if err != nil {
+		return nil, err
+	}
+	data.VehicleInfo = vehicle
+
 	originCEP := data.CEPs[0]
 
 	originLat, originLon, originAddressRaw, err := s.getCoordByCEP(ctx, originCEP)
@@ -1574,29 +1575,26 @@ func (s *Service) CalculateDistancesFromOrigin(ctx context.Context, data FrontIn
 		}
 		destGeocode, _ := s.getGeocodeAddress(ctx, destAddressRaw)
 
-		coords := fmt.Sprintf("%f,%f;%f,%f", originLon, originLat, destLon, destLat)
-		baseURL := fmt.Sprintf("http://34.207.174.233:5000/route/v1/driving/%s?alternatives=0&steps=true&overview=full&continue_straight=false", neturl.PathEscape(coords))
-
-		resp, err := client.Get(baseURL)
+		osrmResp, err := s.engineRoute(ctx, 30*time.Second, RouteRequest{
+			Coordinates: []Location{
+				{Latitude: originLat, Longitude: originLon},
+				{Latitude: destLat, Longitude: destLon},
+			},
+			AllowUTurn: true,
+		})
 		if err != nil {
 			continue
 		}
-		defer resp.Body.Close()
-
-		var osrmResp OSRMResponse
-		if err := json.NewDecoder(resp.Body).Decode(&osrmResp); err != nil || len(osrmResp.Routes) == 0 {
-			continue
-		}
 
 		route := osrmResp.Routes[0]
 		distText, distVal := formatDistance(route.Distance)
 		durText, durVal := formatDuration(route.Duration)
 
-		tolls, _ := s.findTollsOnRoute(ctx, route.Geometry, data.Type, float64(data.Axles))
+		tolls, _ := s.findTollsOnRoute(ctx, route, newTollVehicle(data.Type, data.Axles, data.VehicleInfo), data.DepartureTime)
 
 		var totalTollCost float64
 		for _, toll := range tolls {
-			totalTollCost += toll.CashCost
+			totalTollCost += toll.PaidCost
 		}
 
 		googleURL := fmt.Sprintf("https://www.google.com/maps/dir/?api=1&origin=%s&destination=%s&travelmode=driving",
@@ -1656,6 +1654,12 @@ func (s *Service) CalculateRoutesWithCoordinate(ctx context.Context, frontInfo F
 	}
 	waypointsStr := strings.ToLower(strings.Join(wpStrings, ","))
 
+	vehicle, err := s.resolveVehicleInfo(ctx, frontInfo.VehicleInfo, frontInfo.Axles, idSimp)
+	if err != nil {
+		return FinalOutput{}, err
+	}
+	frontInfo.VehicleInfo = vehicle
+
 	cacheKey := fmt.Sprintf("route:%s:%s:%s:%s:waypoints:%s:axles:%d:type:%s",
 		strings.ToLower(frontInfo.OriginLat),
 		strings.ToLower(frontInfo.OriginLng),
@@ -1664,7 +1668,7 @@ func (s *Service) CalculateRoutesWithCoordinate(ctx context.Context, frontInfo F
 		waypointsStr,
 		frontInfo.Axles,
 		strings.ToLower(frontInfo.Type),
-	)
+	) + frontInfo.cacheKey() + departureCacheKey(frontInfo.DepartureTime) + driverRulesCacheKey(frontInfo.DriverRules) + localeCacheKey(frontInfo.Locale)
 	cached, err := cache.Rdb.Get(ctx, cacheKey).Result()
 	if err == nil {
 		var cachedOutput FinalOutput
@@ -1683,7 +1687,7 @@ func (s *Service) CalculateRoutesWithCoordinate(ctx context.Context, frontInfo F
 				routeHistID, errSavedRoutes := s.savedRoutes(ctx, frontInfo.PublicOrPrivate,
 					cachedOutput.Summary.LocationOrigin.Address,
 					cachedOutput.Summary.LocationDestination.Address,
-					waypointsStr, idPublicToken, idSimp, responseJSON, requestJSON, frontInfo.Favorite)
+					waypointsStr, idPublicToken, idSimp, responseJSON, requestJSON, frontInfo.Favorite, frontInfo.TokenOrgID)
 
 				if errSavedRoutes != nil {
 					// Erro ao salvar rota/favorita (cache)
@@ -1761,34 +1765,11 @@ func (s *Service) CalculateRoutesWithCoordinate(ctx context.Context, frontInfo F
 		FuelUnit: "liter",
 	}
 
-	coords := fmt.Sprintf("%f,%f", origin.Location.Longitude, origin.Location.Latitude)
+	coordinates := []Location{origin.Location}
 	for _, wp := range waypointResults {
-		coords += fmt.Sprintf(";%f,%f", wp.Location.Longitude, wp.Location.Latitude)
-	}
-	coords += fmt.Sprintf(";%f,%f", destination.Location.Longitude, destination.Location.Latitude)
-	baseOSRMURL := "http://34.207.174.233:5000/route/v1/driving/" + neturl.PathEscape(coords)
-	client := http.Client{Timeout: 120 * time.Second}
-
-	osrmURLFast := baseOSRMURL + "?" + neturl.Values{
-		"alternatives":      {"3"},
-		"steps":             {"true"},
-		"overview":          {"full"},
-		"continue_straight": {"false"},
-	}.Encode()
-
-	osrmURLNoTolls := baseOSRMURL + "?" + neturl.Values{
-		"alternatives": {"3"},
-		"steps":        {"true"},
-		"overview":     {"full"},
-		"exclude":      {"toll"},
-	}.Encode()
-
-	osrmURLEfficient := baseOSRMURL + "?" + neturl.Values{
-		"alternatives": {"3"},
-		"steps":        {"true"},
-		"overview":     {"full"},
-		"exclude":      {"motorway"},
-	}.Encode()
+		coordinates = append(coordinates, wp.Location)
+	}
+	coordinates = append(coordinates, destination.Location)
 
 	type osrmResult struct {
 		resp     OSRMResponse
@@ -1797,28 +1778,22 @@ func (s *Service) CalculateRoutesWithCoordinate(ctx context.Context, frontInfo F
 	}
 	resultsCh := make(chan osrmResult, 3)
 
-	makeOSRMRequest := func(url, category, errMsg string) {
-		resp, err := client.Get(url)
-		if err != nil {
-			resultsCh <- osrmResult{err: fmt.Errorf("%s: %w", errMsg, err), category: category}
-			return
-		}
-		defer resp.Body.Close()
-		var osrmResp OSRMResponse
-		if err := json.NewDecoder(resp.Body).Decode(&osrmResp); err != nil {
-			resultsCh <- osrmResult{err: fmt.Errorf("erro ao decodificar resposta OSRM (%s): %w", category, err), category: category}
+	makeOSRMRequest := func(req RouteRequest, category, errMsg string) {
+		osrmResp, err := s.engineAlternatives(ctx, 120*time.Second, req, 3)
+		if errors.Is(err, ErrNoRoute) {
+			resultsCh <- osrmResult{err: fmt.Errorf("OSRM (%s) retornou erro ou nenhuma rota encontrada", category), category: category}
 			return
 		}
-		if osrmResp.Code != "Ok" || len(osrmResp.Routes) == 0 {
-			resultsCh <- osrmResult{err: fmt.Errorf("OSRM (%s) retornou erro ou nenhuma rota encontrada", category), category: category}
+		if err != nil {
+			resultsCh <- osrmResult{err: fmt.Errorf("%s: %w", errMsg, err), category: category}
 			return
 		}
-		resultsCh <- osrmResult{resp: osrmResp, category: category}
+		resultsCh <- osrmResult{resp: s.preferCompliantRoutes(ctx, osrmResp, req, frontInfo.VehicleInfo), category: category}
 	}
 
-	go makeOSRMRequest(osrmURLFast, "fatest", "erro na requisição OSRM (rota rápida)")
-	go makeOSRMRequest(osrmURLNoTolls, "cheapest", "erro na requisição OSRM (rota com menos pedágio)")
-	go makeOSRMRequest(osrmURLEfficient, "efficient", "erro na requisição OSRM (rota eficiente)")
+	go makeOSRMRequest(RouteRequest{Coordinates: coordinates, AllowUTurn: true}, "fatest", "erro na requisição OSRM (rota rápida)")
+	go makeOSRMRequest(RouteRequest{Coordinates: coordinates, Exclude: []string{"toll"}}, "cheapest", "erro na requisição OSRM (rota com menos pedágio)")
+	go makeOSRMRequest(RouteRequest{Coordinates: coordinates, Exclude: []string{"motorway"}}, "efficient", "erro na requisição OSRM (rota eficiente)")
 
 	var osrmRespFast, osrmRespNoTolls, osrmRespEfficient OSRMResponse
 	for i := 0; i < 3; i++ {
@@ -1869,13 +1844,7 @@ func (s *Service) CalculateRoutesWithCoordinate(ctx context.Context, frontInfo F
 	processRoutes := func(osrmResp OSRMResponse, routeCategory string) []RouteOutput {
 		var output []RouteOutput
 		for _, route := range osrmResp.Routes {
-			balancas, err := s.InterfaceService.GetBalanca(ctx)
-			if err != nil {
-				log.Printf("Erro ao obter balanças: %v", err)
-				balancas = nil
-			}
-
-			routeGasStations, err := s.findGasStations(dbCtx, osrmRespFast.Routes[0].Geometry)
+			routeGasStations, err := s.findGasStations(dbCtx, osrmRespFast.Routes[0], frontInfo.DepartureTime)
 			if err != nil {
 				log.Printf("Erro ao consultar postos de gasolina: %v", err)
 				routeGasStations = nil
@@ -1887,8 +1856,8 @@ func (s *Service) CalculateRoutesWithCoordinate(ctx context.Context, frontInfo F
 			var finalInstructions []Instruction
 			if len(route.Legs) > 0 {
 				for _, step := range route.Legs[0].Steps {
-					text := translateInstruction(step)
-					instructionLower := strings.ToLower(text)
+					text := translateInstruction(step, frontInfo.Locale)
+					instructionLower := strings.ToLower(translateInstruction(step, LocalePTBR))
 					var valueImg string
 					switch {
 					case strings.Contains(instructionLower, "direita") && (strings.Contains(instructionLower, "curva") || strings.Contains(instructionLower, "mantenha-se")):
@@ -1912,13 +1881,15 @@ func (s *Service) CalculateRoutesWithCoordinate(ctx context.Context, frontInfo F
 					}
 
 					finalInstructions = append(finalInstructions, Instruction{
-						Text: text,
-						Img:  valueImg,
+						Text:         text,
+						Img:          valueImg,
+						Distance:     step.Distance,
+						DistanceText: formatInstructionDistance(step.Distance, frontInfo.Locale),
 					})
 				}
 			}
 
-			rawTolls, err := s.findTollsOnRoute(dbCtx, route.Geometry, frontInfo.Type, float64(frontInfo.Axles))
+			rawTolls, err := s.findTollsOnRoute(dbCtx, route, newTollVehicle(frontInfo.Type, frontInfo.Axles, frontInfo.VehicleInfo), frontInfo.DepartureTime)
 			if err != nil {
 				log.Printf("Erro ao filtrar pedágios: %v", err)
 				rawTolls = nil
@@ -1928,7 +1899,7 @@ func (s *Service) CalculateRoutesWithCoordinate(ctx context.Context, frontInfo F
 				routeTolls = append(routeTolls, t)
 			}
 
-			routeBalancas, err := s.findBalancaOnRoute(route.Geometry, balancas)
+			routeBalancas, err := s.findBalancaOnRoute(ctx, route, frontInfo.DepartureTime)
 			if err != nil {
 				log.Printf("Erro ao filtrar balanças: %v", err)
 				routeBalancas = nil
@@ -1944,15 +1915,14 @@ func (s *Service) CalculateRoutesWithCoordinate(ctx context.Context, frontInfo F
 			routeType := routeCategory
 			var totalTollCost float64
 			for _, toll := range rawTolls {
-				totalTollCost += toll.CashCost
+				totalTollCost += toll.PaidCost
 			}
 
-			fuelCostCity := math.Round((frontInfo.Price / frontInfo.ConsumptionCity) * (float64(distVal) / 1000))
-			fuelCostHwy := math.Round((frontInfo.Price / frontInfo.ConsumptionHwy) * (float64(distVal) / 1000))
+			fuelSplit := s.routeFuelSplit(ctx, route, frontInfo.Type, frontInfo.Price, frontInfo.ConsumptionCity, frontInfo.ConsumptionHwy)
+			totalFuelCost := fuelSplit.TotalCost()
 
-			avgConsumption := (frontInfo.ConsumptionCity + frontInfo.ConsumptionHwy) / 2
-			totalKm := float64(distVal) / 1000
-			totalFuelCost := math.Round((frontInfo.Price / avgConsumption) * totalKm)
+			fuelCostCity := math.Round((fuelSplit.Price / frontInfo.ConsumptionCity) * (float64(distVal) / 1000))
+			fuelCostHwy := math.Round((fuelSplit.Price / frontInfo.ConsumptionHwy) * (float64(distVal) / 1000))
 
 			output = append(output, RouteOutput{
 				Summary: RouteSummary{
@@ -1966,23 +1936,24 @@ func (s *Service) CalculateRoutesWithCoordinate(ctx context.Context, frontInfo F
 						Text:  durText,
 						Value: durVal,
 					},
-					URL:           googleURL,
-					URLWaze:       wazeURL,
-					TotalFuelCost: totalFuelCost,
+					URL:             googleURL,
+					URLWaze:         wazeURL,
+					TotalFuelCost:   totalFuelCost,
+					FuelSplit:       &fuelSplit,
+					Emissions:       routeEmissions(fuelSplit, frontInfo.Type, frontInfo.Axles, frontInfo.VehicleInfo),
+					Restrictions:    s.routeRestrictions(dbCtx, route.Geometry, frontInfo.VehicleInfo),
+					DriverItinerary: s.driverItinerary(dbCtx, route, frontInfo.DriverRules, frontInfo.DepartureTime),
 				},
 				Costs: func() *Costs {
 					if frontInfo.RouteOptions.IncludeTollCosts {
-						return &Costs{
-							TagAndCash:      totalTollCost,
-							FuelInTheCity:   fuelCostCity,
-							FuelInTheHwy:    fuelCostHwy,
-							Tag:             (totalTollCost - (totalTollCost * 0.05)) * float64(frontInfo.Axles),
-							Cash:            totalTollCost * float64(frontInfo.Axles),
-							PrepaidCard:     totalTollCost * float64(frontInfo.Axles),
-							MaximumTollCost: totalTollCost * float64(frontInfo.Axles),
-							MinimumTollCost: totalTollCost * float64(frontInfo.Axles),
-							Axles:           int(frontInfo.Axles),
+						costs := &Costs{
+							FuelInTheCity: fuelCostCity,
+							FuelInTheHwy:  fuelCostHwy,
+							Axles:         int(frontInfo.Axles),
+							FuelSplit:     &fuelSplit,
 						}
+						costs.setTollCosts(rawTolls)
+						return costs
 					}
 					return nil
 				}(),
@@ -2053,9 +2024,8 @@ func (s *Service) CalculateRoutesWithCoordinate(ctx context.Context, frontInfo F
 		distText, distVal := formatDistance(route.Distance)
 		durText, durVal := formatDuration(route.Duration)
 
-		avgConsumption := (frontInfo.ConsumptionCity + frontInfo.ConsumptionHwy) / 2
-		totalKm := float64(distVal) / 1000
-		totalFuelCost := math.Round((frontInfo.Price / avgConsumption) * totalKm)
+		fuelSplit := s.routeFuelSplit(ctx, route, frontInfo.Type, frontInfo.Price, frontInfo.ConsumptionCity, frontInfo.ConsumptionHwy)
+		totalFuelCost := fuelSplit.TotalCost()
 
 		minimalRoute := RouteOutput{
 			Summary: RouteSummary{
@@ -2066,6 +2036,8 @@ func (s *Service) CalculateRoutesWithCoordinate(ctx context.Context, frontInfo F
 				URL:           googleURL,
 				URLWaze:       wazeURL,
 				TotalFuelCost: totalFuelCost,
+				FuelSplit:     &fuelSplit,
+				Emissions:     routeEmissions(fuelSplit, frontInfo.Type, frontInfo.Axles, frontInfo.VehicleInfo),
 			},
 		}
 
@@ -2092,7 +2064,7 @@ func (s *Service) CalculateRoutesWithCoordinate(ctx context.Context, frontInfo F
 					}
 					return stops
 				}(),
-				FuelPrice:      fuelPrice,
+				FuelPrice:      fuelPrice.withRoutePrice([]RouteOutput{minimalRoute}),
 				FuelEfficiency: fuelEfficiency,
 			},
 			Routes: []RouteOutput{minimalRoute},
@@ -2108,7 +2080,7 @@ func (s *Service) CalculateRoutesWithCoordinate(ctx context.Context, frontInfo F
 
 		result, errSavedRoutes := s.savedRoutes(ctx, frontInfo.PublicOrPrivate,
 			origin.FormattedAddress, destination.FormattedAddress,
-			waypointsStr, idPublicToken, idSimp, responseJSON, requestJSON, frontInfo.Favorite)
+			waypointsStr, idPublicToken, idSimp, responseJSON, requestJSON, frontInfo.Favorite, frontInfo.TokenOrgID)
 		if errSavedRoutes != nil {
 			return FinalOutput{}, errSavedRoutes
 		}
@@ -2162,7 +2134,7 @@ func (s *Service) CalculateRoutesWithCoordinate(ctx context.Context, frontInfo F
 				}
 				return stops
 			}(),
-			FuelPrice:      fuelPrice,
+			FuelPrice:      fuelPrice.withRoutePrice(combinedRoutes),
 			FuelEfficiency: fuelEfficiency,
 			RouteOptions:   frontInfo.RouteOptions,
 		},
@@ -2186,7 +2158,7 @@ func (s *Service) CalculateRoutesWithCoordinate(ctx context.Context, frontInfo F
 
 	result, errSavedRoutes := s.savedRoutes(ctx, frontInfo.PublicOrPrivate,
 		origin.FormattedAddress, destination.FormattedAddress,
-		waypointsStrResponse, idPublicToken, idSimp, responseJSON, requestJSON, frontInfo.Favorite)
+		waypointsStrResponse, idPublicToken, idSimp, responseJSON, requestJSON, frontInfo.Favorite, frontInfo.TokenOrgID)
 	if errSavedRoutes != nil {
 		return FinalOutput{}, errSavedRoutes
 	}
@@ -2224,30 +2196,18 @@ func (s *Service) RemoveFavoriteRouteService(ctx context.Context, id, idUser int
 }
 
 func (s *Service) GetSimpleRoute(data SimpleRouteRequest) (SimpleRouteResponse, error) {
-	coords := fmt.Sprintf("%f,%f;%f,%f", data.OriginLng, data.OriginLat, data.DestLng, data.DestLat)
-	baseOSRMURL := "http://34.207.174.233:5000/route/v1/driving/" + neturl.PathEscape(coords)
-	client := http.Client{Timeout: 120 * time.Second}
-
-	osrmURL := baseOSRMURL + "?" + neturl.Values{
-		"alternatives": {"false"},
-		"steps":        {"false"},
-		"overview":     {"full"},
-	}.Encode()
-
-	resp, err := client.Get(osrmURL)
+	osrmResp, err := s.engineRoute(context.Background(), 120*time.Second, RouteRequest{
+		Coordinates: []Location{
+			{Latitude: data.OriginLat, Longitude: data.OriginLng},
+			{Latitude: data.DestLat, Longitude: data.DestLng},
+		},
+	})
+	if errors.Is(err, ErrNoRoute) {
+		return SimpleRouteResponse{}, fmt.Errorf("OSRM retornou erro ou nenhuma rota encontrada")
+	}
 	if err != nil {
 		return SimpleRouteResponse{}, fmt.Errorf("erro na requisição OSRM: %w", err)
 	}
-	defer resp.Body.Close()
-
-	var osrmResp OSRMResponse
-	if err := json.NewDecoder(resp.Body).Decode(&osrmResp); err != nil {
-		return SimpleRouteResponse{}, fmt.Errorf("erro ao decodificar resposta OSRM: %w", err)
-	}
-
-	if osrmResp.Code != "Ok" || len(osrmResp.Routes) == 0 {
-		return SimpleRouteResponse{}, fmt.Errorf("OSRM retornou erro ou nenhuma rota encontrada")
-	}
 
 	distanceText, distanceValue := formatDistance(osrmResp.Routes[0].Distance)
 	durationText, durationValue := formatDuration(osrmResp.Routes[0].Duration)
@@ -2386,7 +2346,7 @@ func (s *Service) createDescriptiveAddress(lat, lng float64) string {
 	return fmt.Sprintf("Localização %.6f, %.6f - %s", lat, lng, region)
 }
 
-func (s *Service) savedRoutes(ctx context.Context, PublicOrPrivate, origin, destination, waypoints string, idPublicToken, IdUser int64, responseJSON, requestJSON json.RawMessage, favorite bool) (int64, error) {
+func (s *Service) savedRoutes(ctx context.Context, PublicOrPrivate, origin, destination, waypoints string, idPublicToken, IdUser int64, responseJSON, requestJSON json.RawMessage, favorite bool, organizationID int64) (int64, error) {
 	var idTokenHist int64
 	if strings.ToLower(PublicOrPrivate) == "public" {
 		idTokenHist = idPublicToken
@@ -2425,6 +2385,8 @@ func (s *Service) savedRoutes(ctx context.Context, PublicOrPrivate, origin, dest
 				return 0, err
 			}
 			routeHistID = newRouteHist.ID
+			// As emissões entram no relatório uma vez por histórico, não a cada repetição da rota
+			s.recordSavedRouteEmissions(ctx, routeHistID, IdUser, organizationID, responseJSON, requestJSON)
 		} else {
 			return 0, err
 		}
@@ -2536,63 +2498,55 @@ func (s *Service) getAllFreight(ctx context.Context, axles int64, kmValue float6
 	return finalResult, nil
 }
 
-func (s *Service) findTollsOnRoute(ctx context.Context, routeGeometry string, vehicle string, axes float64) ([]Toll, error) {
-	tollsDB, err := s.InterfaceService.GetTollsByLonAndLat(ctx)
+func (s *Service) findTollsOnRoute(ctx context.Context, route OSRMRoute, vehicle TollVehicle, departure *time.Time) ([]Toll, error) {
+	snap, err := s.POIIndex.current(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	pricing, err := s.tollPricingAt(ctx, snap, departure)
 	if err != nil {
 		return nil, err
 	}
+	category := vehicle.category()
 
 	resultTags, err := s.InterfaceService.GetTollTags(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	polyPoints, err := decodePolyline(routeGeometry)
+	geometry, err := routeGeometryFromPolyline(route.Geometry)
 	if err != nil {
 		return nil, err
 	}
-	if len(polyPoints) < 2 {
+	if len(geometry.Points) < 2 {
 		return nil, nil
 	}
+	timeline := newRouteTimeline(route, geometry.Length(), departure)
 
-	routeIsCrescente := polyPoints[0].Lat < polyPoints[len(polyPoints)-1].Lat
+	markers := snap.routeMarkers(geometry)
 	uniqueTolls := make(map[int64]bool)
 	var candidateTolls []Toll
+	var matchedTolls []db.Toll
 
-	for _, toll := range tollsDB {
-		latitude, _ := validation.ParseNullStringToFloat(toll.Latitude)
-		longitude, _ := validation.ParseNullStringToFloat(toll.Longitude)
-		tollPos := LatLng{Lat: latitude, Lng: longitude}
-		minDistance := math.MaxFloat64
-
-		for i := 0; i < len(polyPoints)-1; i++ {
-			d := distancePointToSegment(tollPos, polyPoints[i], polyPoints[i+1])
-			if d < minDistance {
-				minDistance = d
-			}
-		}
+	// As praças já vêm na ordem em que aparecem na rota
+	for _, m := range geometry.match(snap.tollGrid, snap.tollPositions, tollRouteTolerance) {
+		toll := snap.tolls[m.Index]
 
-		if minDistance > 50 {
+		// O sentido é avaliado no trecho em que a praça foi casada, não na rota inteira
+		direction := snap.travelDirection(geometry, m, snap.tollMarkers[m.Index], markers)
+		if !direction.accepts(toll.Sentido.String) {
 			continue
 		}
 
-		if toll.Sentido.String != "" {
-			if toll.Sentido.String == "Crescente" && !routeIsCrescente {
-				continue
-			}
-			if toll.Sentido.String == "Decrescente" && routeIsCrescente {
-				continue
-			}
-		}
-
 		imgConcession := getConcessionImage(toll.Concessionaria.String)
 		if !uniqueTolls[toll.ID] {
 			uniqueTolls[toll.ID] = true
 
 			candidateTolls = append(candidateTolls, Toll{
 				ID:            int(toll.ID),
-				Latitude:      latitude,
-				Longitude:     longitude,
+				Latitude:      snap.tollPositions[m.Index].Lat,
+				Longitude:     snap.tollPositions[m.Index].Lng,
 				Name:          validation.GetStringFromNull(toll.PracaDePedagio),
 				Concession:    toll.Concessionaria.String,
 				ConcessionImg: imgConcession,
@@ -2602,245 +2556,108 @@ func (s *Service) findTollsOnRoute(ctx context.Context, routeGeometry string, ve
 				Type:          "Pedágio",
 				FreeFlow:      toll.FreeFlow.Bool,
 				PayFreeFlow:   toll.PayFreeFlow.String,
+				KmMarker:      snap.tollMarkers[m.Index].km,
+				RouteKm:       math.Round(m.Along/100) / 10,
+				Direction:     direction.direction(),
+				DirectionConf: direction.Confidence,
+				// Chegada pela distância e duração acumuladas da própria rota
+				ArrivalResponse: timeline.arrivalAtAlong(m.Along),
 			})
+			matchedTolls = append(matchedTolls, toll)
 		}
 	}
 
 	for i := range candidateTolls {
-		var correspondingToll db.Toll
-		found := false
-		for _, t := range tollsDB {
-			if int(t.ID) == candidateTolls[i].ID {
-				correspondingToll = t
-				found = true
-				break
-			}
-		}
-		if !found {
-			continue
-		}
-
-		tarifaFloat := 0.0
-		if correspondingToll.Tarifa.Valid {
-			tf, _ := strconv.ParseFloat(correspondingToll.Tarifa.String, 64)
-			tarifaFloat = tf
-		}
-		totalToll, errPrice := PriceTollsFromVehicle(strings.ToLower(vehicle), tarifaFloat, axes)
-		if errPrice != nil {
-			continue
-		}
+		correspondingToll := matchedTolls[i]
 
-		var tags []string
+		cash := pricing.cashPrice(correspondingToll, category)
 		concession := validation.GetStringFromNull(correspondingToll.Concessionaria)
+
+		var tags, imgTags []string
 		for _, tagRecord := range resultTags {
-			acceptedList := strings.Split(tagRecord.DealershipAccepts, ",")
-			for _, accepted := range acceptedList {
-				if strings.TrimSpace(accepted) == concession {
-					tags = append(tags, tagRecord.Name)
-					break
-				}
+			if tagAccepts(tagRecord, concession) {
+				tags = append(tags, tagRecord.Name)
+				imgTags = append(imgTags, tagRecord.ImageUrl)
 			}
 		}
 
-		var imgTags []string
-		for _, tag := range tags {
-			var imgTag string
-			switch tag {
-			case "veloe":
-				imgTag = "https://tags-tolls.s3.us-east-1.amazonaws.com/veloe.png"
-			case "semParar":
-				imgTag = "https://tags-tolls.s3.us-east-1.amazonaws.com/semparar.png"
-			case "moveMais":
-				imgTag = "https://tags-tolls.s3.us-east-1.amazonaws.com/moveMais.png"
-			case "greenPass":
-				imgTag = "https://tags-tolls.s3.us-east-1.amazonaws.com/greenpass.png"
-			case "ecotaggy":
-				imgTag = "https://tags-tolls.s3.us-east-1.amazonaws.com/ecotaggy.png"
-			case "autoExpresso":
-				imgTag = "https://tags-tolls.s3.us-east-1.amazonaws.com/auto-expresso.png"
-			case "c6Taggy":
-				imgTag = "https://tags-tolls.s3.us-east-1.amazonaws.com/c6-tag.png"
-			case "dBTrans":
-				imgTag = "https://tags-tolls.s3.us-east-1.amazonaws.com/dbTrans.png"
-			case "taggy":
-				imgTag = "https://tags-tolls.s3.us-east-1.amazonaws.com/taggy.png"
-			case "conectCar":
-				imgTag = "https://tags-tolls.s3.us-east-1.amazonaws.com/conectcar.png"
-			default:
-				imgTag = ""
-			}
-			imgTags = append(imgTags, imgTag)
+		tagPrices := pricing.tagPrices(tags, concession, category.Code, cash)
+		tagCost := cash
+		for _, tp := range tagPrices {
+			tagCost = math.Min(tagCost, tp.Price)
 		}
 
-		candidateTolls[i].TagCost = math.Round(totalToll - (totalToll * 0.05))
-		candidateTolls[i].CashCost = totalToll
+		candidateTolls[i].Category = category.Code
+		candidateTolls[i].TagCost = tagCost
+		candidateTolls[i].CashCost = cash
+		candidateTolls[i].PaidCost = paidPrice(tagPrices, vehicle.Tag, cash)
 		candidateTolls[i].Currency = "BRL"
-		candidateTolls[i].PrepaidCardCost = math.Round(totalToll - (totalToll * 0.05))
+		// Cartões pré-pagos não têm desconto: pagam a tarifa cheia da categoria
+		candidateTolls[i].PrepaidCardCost = cash
 		candidateTolls[i].TagPrimary = tags
+		candidateTolls[i].TagPrices = tagPrices
 		candidateTolls[i].TagImg = imgTags
 	}
 
-	originStr := fmt.Sprintf("%.6f,%.6f", polyPoints[0].Lat, polyPoints[0].Lng)
-	arrivalMap, _ := s.calculateTollsArrivalTimes(originStr, candidateTolls)
-	for i := range candidateTolls {
-		if arr, ok := arrivalMap[int64(candidateTolls[i].ID)]; ok {
-			formattedTime := arr.Time.Round(time.Second).String()
-			candidateTolls[i].ArrivalResponse = ArrivalResponse{
-				Distance: arr.Distance,
-				Time:     formattedTime,
-			}
-		}
-	}
-
-	sort.Slice(candidateTolls, func(i, j int) bool {
-		di := haversineDistanceTolls(candidateTolls[i].Latitude, candidateTolls[i].Longitude, polyPoints[0].Lat, polyPoints[0].Lng)
-		dj := haversineDistanceTolls(candidateTolls[j].Latitude, candidateTolls[j].Longitude, polyPoints[0].Lat, polyPoints[0].Lng)
-		return di < dj
-	})
-
 	return candidateTolls, nil
 }
 
-func (s *Service) findBalancaOnRoute(routeGeometry string, balancas []db.Balanca) ([]db.Balanca, error) {
-	polyPoints, err := decodePolyline(routeGeometry)
+func (s *Service) findBalancaOnRoute(ctx context.Context, route OSRMRoute, departure *time.Time) ([]RouteBalanca, error) {
+	snap, err := s.POIIndex.current(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	geometry, err := routeGeometryFromPolyline(route.Geometry)
 	if err != nil {
 		return nil, err
 	}
-	if len(polyPoints) < 2 {
+	if len(geometry.Points) < 2 {
 		return nil, nil
 	}
+	timeline := newRouteTimeline(route, geometry.Length(), departure)
 
-	routeIsCrescente := polyPoints[0].Lat < polyPoints[len(polyPoints)-1].Lat
-	var foundBalancas []db.Balanca
+	markers := snap.routeMarkers(geometry)
+	var foundBalancas []RouteBalanca
 
-	for _, b := range balancas {
-		latitude, _ := validation.ParseStringToFloat(b.Lat)
-		longitude, _ := validation.ParseStringToFloat(b.Lng)
-		pos := LatLng{Lat: latitude, Lng: longitude}
-		minDistance := math.MaxFloat64
-
-		for i := 0; i < len(polyPoints)-1; i++ {
-			d := distancePointToSegment(pos, polyPoints[i], polyPoints[i+1])
-			if d < minDistance {
-				minDistance = d
-			}
-		}
+	for _, m := range geometry.match(snap.balancaGrid, snap.balancaPositions, 50) {
+		b := snap.balancas[m.Index]
 
-		if minDistance > 50 {
+		direction := snap.travelDirection(geometry, m, snap.balancaMarkers[m.Index], markers)
+		if !direction.accepts(b.Sentido) {
 			continue
 		}
 
-		if b.Sentido != "" {
-			if b.Sentido == "Crescente" && !routeIsCrescente {
-				continue
-			}
-			if b.Sentido == "Decrescente" && routeIsCrescente {
-				continue
-			}
-		}
-
-		foundBalancas = append(foundBalancas, b)
+		foundBalancas = append(foundBalancas, RouteBalanca{Balanca: b, Arrival: timeline.arrivalAtAlong(m.Along)})
 	}
 
 	return foundBalancas, nil
 }
 
-func (s *Service) findGasStations(ctx context.Context, routeGeometry string) ([]GasStation, error) {
-	points, err := decodePolyline(routeGeometry)
+func (s *Service) findGasStations(ctx context.Context, route OSRMRoute, departure *time.Time) ([]GasStation, error) {
+	snap, err := s.POIIndex.current(ctx)
 	if err != nil {
-		return nil, err
-	}
-	if len(points) == 0 {
-		return nil, nil
-	}
-
-	minLat, minLng := points[0].Lat, points[0].Lng
-	maxLat, maxLng := points[0].Lat, points[0].Lng
-	for _, p := range points[1:] {
-		if p.Lat < minLat {
-			minLat = p.Lat
-		}
-		if p.Lat > maxLat {
-			maxLat = p.Lat
-		}
-		if p.Lng < minLng {
-			minLng = p.Lng
-		}
-		if p.Lng > maxLng {
-			maxLng = p.Lng
-		}
+		return nil, fmt.Errorf("erro ao consultar postos: %w", err)
 	}
-	padding := 0.05
-	minLat -= padding
-	minLng -= padding
-	maxLat += padding
-	maxLng += padding
 
-	resultStations, err := s.InterfaceService.GetGasStationsByBoundingBox(ctx, db.GetGasStationsByBoundingBoxParams{
-		Column1: minLat,
-		Column2: maxLat,
-		Column3: minLng,
-		Column4: maxLng,
-	})
+	geometry, err := routeGeometryFromPolyline(route.Geometry)
 	if err != nil {
-		return nil, fmt.Errorf("erro ao consultar postos no banco: %w", err)
+		return nil, err
 	}
+	timeline := newRouteTimeline(route, geometry.Length(), departure)
 
 	tolerance := 150.0
 	var stations []GasStation
-	for _, stationRow := range resultStations {
-		gs := convertGasStation(db.GetGasStationRow(stationRow))
-		stationPos := LatLng{
-			Lat: gs.Location.Latitude,
-			Lng: gs.Location.Longitude,
-		}
-		minDistance := math.MaxFloat64
-		for i := 0; i < len(points)-1; i++ {
-			d := distancePointToSegment(stationPos, points[i], points[i+1])
-			if d < minDistance {
-				minDistance = d
-			}
-		}
-		if minDistance <= tolerance {
-			stations = append(stations, gs)
-		}
+	for _, m := range geometry.match(snap.stationGrid, snap.stationPositions, tolerance) {
+		station := snap.stations[m.Index]
+		arrival := timeline.arrivalAtAlong(m.Along)
+		station.Arrival = &arrival
+		stations = append(stations, station)
 	}
 
 	return stations, nil
 }
 
-func (s *Service) calculateTollsArrivalTimes(origin string, tolls []Toll) (map[int64]Arrival, error) {
-	parts := strings.Split(origin, ",")
-	if len(parts) != 2 {
-		return nil, fmt.Errorf("origem inválida: %s", origin)
-	}
-	originLat, err := strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
-	if err != nil {
-		return nil, fmt.Errorf("erro ao converter latitude da origem: %w", err)
-	}
-	originLng, err := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
-	if err != nil {
-		return nil, fmt.Errorf("erro ao converter longitude da origem: %w", err)
-	}
-
-	avgSpeed := 60.0
-
-	arrivalMap := make(map[int64]Arrival)
-	for _, toll := range tolls {
-		distanceMeters := haversineDistanceTolls(originLat, originLng, toll.Latitude, toll.Longitude)
-		distanceKm := distanceMeters / 1000.0
-		estimatedTimeHours := distanceKm / avgSpeed
-		estimatedDuration := time.Duration(estimatedTimeHours * float64(time.Hour))
-
-		arrivalMap[int64(toll.ID)] = Arrival{
-			Distance: fmt.Sprintf("%.2f km", distanceKm),
-			Time:     estimatedDuration,
-		}
-	}
-
-	return arrivalMap, nil
-}
-
 func (s *Service) getGeocodeAddress(ctx context.Context, address string) (GeocodeResult, error) {
 	// Implementar cache para evitar chamadas repetidas
 	cacheKey := fmt.Sprintf("geocode_nominatim:%s", address)
@@ -3177,48 +2994,29 @@ func (s *Service) CalculateRoutesWithCEPOnly(ctx context.Context, frontInfo Fron
 	}
 	destinationGeocode.Location = Location{Latitude: destLat, Longitude: destLon}
 
-	coords := fmt.Sprintf("%f,%f;%f,%f", originGeocode.Location.Longitude, originGeocode.Location.Latitude, destinationGeocode.Location.Longitude, destinationGeocode.Location.Latitude)
-	baseOSRMURL := "http://34.207.174.233:5000/route/v1/driving/" + neturl.PathEscape(coords)
-	client := http.Client{Timeout: 120 * time.Second}
-
-	osrmURLEfficient := baseOSRMURL + "?" + neturl.Values{
-		"alternatives": {"3"},
-		"steps":        {"false"},
-		"overview":     {"full"},
-	}.Encode()
-
-	resp, err := client.Get(osrmURLEfficient)
+	osrmResp, err := s.engineAlternatives(ctx, 120*time.Second, RouteRequest{
+		Coordinates: []Location{originGeocode.Location, destinationGeocode.Location},
+	}, 3)
+	if errors.Is(err, ErrNoRoute) {
+		return FinalOutputPrecision{}, fmt.Errorf("OSRM retornou erro ou nenhuma rota encontrada")
+	}
 	if err != nil {
 		return FinalOutputPrecision{}, fmt.Errorf("erro na requisição OSRM: %w", err)
 	}
-	defer resp.Body.Close()
-	var osrmResp OSRMResponse
-	if err := json.NewDecoder(resp.Body).Decode(&osrmResp); err != nil {
-		return FinalOutputPrecision{}, fmt.Errorf("erro ao decodificar resposta OSRM: %w", err)
-	}
-	if osrmResp.Code != "Ok" || len(osrmResp.Routes) == 0 {
-		return FinalOutputPrecision{}, fmt.Errorf("OSRM retornou erro ou nenhuma rota encontrada")
-	}
 
 	var routes []interface{}
 	for _, route := range osrmResp.Routes {
 		kmValue := route.Distance / 1000.0
 
-		var fuelCostCity, fuelCostHwy, totalFuelCost float64
+		fuelSplit := s.routeFuelSplit(ctx, route, frontInfo.Type, frontInfo.Price, frontInfo.ConsumptionCity, frontInfo.ConsumptionHwy)
+		totalFuelCost := fuelSplit.TotalCost()
+
+		var fuelCostCity, fuelCostHwy float64
 		if frontInfo.ConsumptionCity > 0 {
-			fuelCostCity = math.Round((frontInfo.Price / frontInfo.ConsumptionCity) * kmValue)
+			fuelCostCity = math.Round((fuelSplit.Price / frontInfo.ConsumptionCity) * kmValue)
 		}
 		if frontInfo.ConsumptionHwy > 0 {
-			fuelCostHwy = math.Round((frontInfo.Price / frontInfo.ConsumptionHwy) * kmValue)
-		}
-
-		if frontInfo.ConsumptionCity > 0 && frontInfo.ConsumptionHwy > 0 {
-			avgConsumption := (frontInfo.ConsumptionCity + frontInfo.ConsumptionHwy) / 2
-			totalFuelCost = math.Round((frontInfo.Price / avgConsumption) * kmValue)
-		} else if frontInfo.ConsumptionCity > 0 {
-			totalFuelCost = fuelCostCity
-		} else if frontInfo.ConsumptionHwy > 0 {
-			totalFuelCost = fuelCostHwy
+			fuelCostHwy = math.Round((fuelSplit.Price / frontInfo.ConsumptionHwy) * kmValue)
 		}
 
 		routes = append(routes, map[string]interface{}{
@@ -3231,7 +3029,9 @@ func (s *Service) CalculateRoutesWithCEPOnly(ctx context.Context, frontInfo Fron
 				TotalFuelCost: totalFuelCost,
 				FuelInTheCity: fuelCostCity,
 				FuelInTheHwy:  fuelCostHwy,
+				FuelSplit:     fuelSplit,
 			},
+			"emissions": routeEmissions(fuelSplit, frontInfo.Type, frontInfo.Axles, frontInfo.VehicleInfo),
 		})
 	}
 
@@ -3263,6 +3063,12 @@ func (s *Service) CalculateDistancesBetweenPointsWithRiskAvoidance(ctx context.C
 		return Response{}, fmt.Errorf("é necessário pelo menos dois pontos para calcular distâncias")
 	}
 
+	vehicle, err := s.resolveVehicleInfo(ctx, data.VehicleInfo, data.Axles, 0)
+	if err != nil {
+		return Response{}, err
+	}
+	data.VehicleInfo = vehicle
+
 	// Buscar zonas de risco uma única vez
 	riskZones, err := s.getActiveRiskZones(ctx, data.OrganizationID)
 	if err != nil {
@@ -3276,18 +3082,6 @@ func (s *Service) CalculateDistancesBetweenPointsWithRiskAvoidance(ctx context.C
 		return s.CalculateDistancesBetweenPoints(ctx, data)
 	}
 
-	// Pool de clientes HTTP reutilizáveis para melhor performance
-	clientPool := &http.Client{
-		Timeout: 10 * time.Second, // Timeout ainda mais agressivo
-		Transport: &http.Transport{
-			MaxIdleConns:        100,
-			MaxIdleConnsPerHost: 20, // Mais conexões por host
-			IdleConnTimeout:     30 * time.Second,
-			DisableCompression:  true,  // OSRM não usa compressão
-			DisableKeepAlives:   false, // Manter conexões ativas
-			MaxConnsPerHost:     50,    // Mais conexões simultâneas
-		},
-	}
 	var resultRoutes []DetailedRoute
 	var totalDistance float64
 	var totalDuration float64
@@ -3429,6 +3223,17 @@ func (s *Service) CalculateDistancesBetweenPointsWithRiskAvoidance(ctx context.C
 		}{result.lat, result.lon, result.address, result.geocode}
 	}
 
+	// Decidir pelo horário previsto de passagem quais zonas desviar, quais só alertar e quais ignorar
+	stops := make([]Location, 0, len(data.CEPs))
+	for _, cep := range data.CEPs {
+		if c, ok := cepCoordinates[cep]; ok {
+			stops = append(stops, Location{Latitude: c.lat, Longitude: c.lon})
+		}
+	}
+	riskZones, riskAtentions = s.scheduleRiskZones(ctx, riskZones, riskAtentions, stops, data.Type, data.DepartureTime)
+	// Zonas consideradas na exposição ao risco das rotas: as desviadas e as que só geram alerta
+	exposureZones := append(append([]RiskZone{}, riskZones...), riskAtentions...)
+
 	// Processar segmentos em paralelo quando possível
 	type segmentResult struct {
 		index   int
@@ -3467,16 +3272,19 @@ func (s *Service) CalculateDistancesBetweenPointsWithRiskAvoidance(ctx context.C
 				}
 
 				// Sem zonas de risco, usar rota direta (mesmo que tenha zonas de atenção)
-				summaries := s.calculateDirectRoute(ctx, *clientPool, originData.lat, originData.lon, destData.lat, destData.lon, originData.geocode, destData.geocode, data)
+				summaries := s.calculateDirectRoute(ctx, originData.lat, originData.lon, destData.lat, destData.lon, originData.geocode, destData.geocode, data)
 
 				if len(summaries) == 0 {
 					fb := s.createDirectEstimateSummary(originData.lat, originData.lon, destData.lat, destData.lon, originData.geocode, destData.geocode, data)
 					summaries = []RouteSummary{fb}
 				}
 
+				// Exposição ao risco de cada rota do trecho; com typeRoute de menor risco, a menos exposta vem primeiro
+				summaries = s.scoreSegmentRoutes(ctx, summaries, exposureZones, originData.lat, originData.lon, destData.lat, destData.lon, originData.geocode, destData.geocode, data)
+
 				// Adicionar informações de zonas de atenção aos summaries
 				if hasAttention && len(summaries) > 0 {
-					attentionInfo := s.createAttentionZoneInfo(attentionOffs, originData.lat, originData.lon, destData.lat, destData.lon)
+					attentionInfo := s.createAttentionZoneInfo(attentionOffs, originData.lat, originData.lon, destData.lat, destData.lon, newRouteTimeline(OSRMRoute{Distance: summaries[0].Distance.Value, Duration: summaries[0].Duration.Value}, 0, data.DepartureTime))
 					summaries[0].AttentionZones = &attentionInfo
 				}
 
@@ -3536,10 +3344,10 @@ func (s *Service) CalculateDistancesBetweenPointsWithRiskAvoidance(ctx context.C
 				var summaries []RouteSummary
 				if hasRisk {
 					// Se há zonas de risco, calcular rota alternativa
-					summaries = s.calculateAlternativeRouteWithAvoidance(ctx, *clientPool, riskZones, originData.lat, originData.lon, destData.lat, destData.lon, originData.geocode, destData.geocode, data)
+					summaries = s.calculateAlternativeRouteWithAvoidance(ctx, riskZones, originData.lat, originData.lon, destData.lat, destData.lon, originData.geocode, destData.geocode, data)
 				} else {
 					// Se não há zonas de risco, usar rota direta (mesmo que tenha zonas de atenção)
-					summaries = s.calculateDirectRoute(ctx, *clientPool, originData.lat, originData.lon, destData.lat, destData.lon, originData.geocode, destData.geocode, data)
+					summaries = s.calculateDirectRoute(ctx, originData.lat, originData.lon, destData.lat, destData.lon, originData.geocode, destData.geocode, data)
 				}
 
 				if len(summaries) == 0 {
@@ -3547,9 +3355,12 @@ func (s *Service) CalculateDistancesBetweenPointsWithRiskAvoidance(ctx context.C
 					summaries = []RouteSummary{fb}
 				}
 
+				// Exposição ao risco de cada rota do trecho; com typeRoute de menor risco, a menos exposta vem primeiro
+				summaries = s.scoreSegmentRoutes(ctx, summaries, exposureZones, originData.lat, originData.lon, destData.lat, destData.lon, originData.geocode, destData.geocode, data)
+
 				// Adicionar informações de zonas de atenção aos summaries
 				if hasAttention && len(summaries) > 0 {
-					attentionInfo := s.createAttentionZoneInfo(attentionOffs, originData.lat, originData.lon, destData.lat, destData.lon)
+					attentionInfo := s.createAttentionZoneInfo(attentionOffs, originData.lat, originData.lon, destData.lat, destData.lon, newRouteTimeline(OSRMRoute{Distance: summaries[0].Distance.Value, Duration: summaries[0].Duration.Value}, 0, data.DepartureTime))
 					summaries[0].AttentionZones = &attentionInfo
 				}
 
@@ -3591,11 +3402,32 @@ func (s *Service) CalculateDistancesBetweenPointsWithRiskAvoidance(ctx context.C
 	}
 
 	// Calcular rota total com desvios
-	totalRoute := s.calculateTotalRouteWithAvoidance(ctx, *clientPool, riskZones, riskAtentions, data.CEPs, totalDistance, totalDuration, data)
+	totalRoute := s.calculateTotalRouteWithAvoidance(ctx, riskZones, riskAtentions, data.CEPs, totalDistance, totalDuration, data)
+
+	// Exposição ao risco da rota total; com typeRoute de menor risco, compara com as alternativas do motor
+	var totalRoutes []TotalSummary
+	if isLowestRiskRoute(data.TypeRoute) {
+		addresses := make([]string, 0, len(stops))
+		for _, cep := range data.CEPs {
+			if c, ok := cepCoordinates[cep]; ok {
+				addresses = append(addresses, c.address)
+			}
+		}
+		totalRoutes = s.totalRouteAlternatives(ctx, totalRoute, stops, addresses, data)
+		for i := range totalRoutes {
+			s.scoreTotalRoute(&totalRoutes[i], exposureZones, data.DepartureTime)
+		}
+		totalRoute, totalRoutes = rankTotalRoutesByRisk(totalRoute, totalRoutes)
+	} else {
+		s.scoreTotalRoute(&totalRoute, exposureZones, data.DepartureTime)
+	}
+
+	s.recordTotalRouteEmissions(ctx, totalRoute, data.TokenOrgID, data.Type)
 
 	return Response{
-		Routes:     resultRoutes,
-		TotalRoute: totalRoute,
+		Routes:      resultRoutes,
+		TotalRoute:  totalRoute,
+		TotalRoutes: totalRoutes,
 	}, nil
 }
 
@@ -3606,6 +3438,12 @@ func (s *Service) CalculateDistancesBetweenPointsWithRiskAvoidanceFromCoordinate
 		return Response{}, fmt.Errorf("é necessário pelo menos dois pontos para calcular distâncias")
 	}
 
+	vehicle, err := s.resolveVehicleInfo(ctx, data.VehicleInfo, data.Axles, 0)
+	if err != nil {
+		return Response{}, err
+	}
+	data.VehicleInfo = vehicle
+
 	// Buscar zonas de risco uma única vez
 	riskZones, err := s.getActiveRiskZones(ctx, data.OrganizationID)
 	if err != nil {
@@ -3622,25 +3460,6 @@ func (s *Service) CalculateDistancesBetweenPointsWithRiskAvoidanceFromCoordinate
 		riskAtentions = []RiskZone{}
 	}
 
-	// Buscar balanças disponíveis
-	balancas, err := s.InterfaceService.GetBalanca(ctx)
-	if err != nil {
-		log.Printf("Erro ao obter balanças: %v", err)
-		balancas = nil
-	}
-
-	// Pool de clientes HTTP reutilizáveis para melhor performance
-	clientPool := &http.Client{
-		Timeout: 10 * time.Second, // Timeout ainda mais agressivo
-		Transport: &http.Transport{
-			MaxIdleConns:        100,
-			MaxIdleConnsPerHost: 20, // Mais conexões por host
-			IdleConnTimeout:     30 * time.Second,
-			DisableCompression:  true,  // OSRM não usa compressão
-			DisableKeepAlives:   false, // Manter conexões ativas
-			MaxConnsPerHost:     50,    // Mais conexões simultâneas
-		},
-	}
 	var resultRoutes []DetailedRoute
 	var totalDistance float64
 	var totalDuration float64
@@ -3734,6 +3553,17 @@ func (s *Service) CalculateDistancesBetweenPointsWithRiskAvoidanceFromCoordinate
 		}{result.lat, result.lon, result.address, result.geocode}
 	}
 
+	// Decidir pelo horário previsto de passagem quais zonas desviar, quais só alertar e quais ignorar
+	stops := make([]Location, 0, len(data.Coordinates))
+	for _, coord := range data.Coordinates {
+		if c, ok := coordinateData[fmt.Sprintf("%s,%s", coord.Lat, coord.Lng)]; ok {
+			stops = append(stops, Location{Latitude: c.lat, Longitude: c.lon})
+		}
+	}
+	riskZones, riskAtentions = s.scheduleRiskZones(ctx, riskZones, riskAtentions, stops, data.Type, data.DepartureTime)
+	// Zonas consideradas na exposição ao risco das rotas: as desviadas e as que só geram alerta
+	exposureZones := append(append([]RiskZone{}, riskZones...), riskAtentions...)
+
 	// Processar segmentos em paralelo quando possível
 	type segmentResult struct {
 		index   int
@@ -3808,16 +3638,19 @@ func (s *Service) CalculateDistancesBetweenPointsWithRiskAvoidanceFromCoordinate
 				}
 
 				// Sem zonas de risco, usar rota direta (mesmo que tenha zonas de atenção)
-				summaries := s.calculateDirectRoute(ctx, *clientPool, originData.lat, originData.lon, destData.lat, destData.lon, originData.geocode, destData.geocode, s.convertCoordinatesToCEPRequest(data))
+				summaries := s.calculateDirectRoute(ctx, originData.lat, originData.lon, destData.lat, destData.lon, originData.geocode, destData.geocode, s.convertCoordinatesToCEPRequest(data))
 
 				if len(summaries) == 0 {
 					fb := s.createDirectEstimateSummary(originData.lat, originData.lon, destData.lat, destData.lon, originData.geocode, destData.geocode, s.convertCoordinatesToCEPRequest(data))
 					summaries = []RouteSummary{fb}
 				}
 
+				// Exposição ao risco de cada rota do trecho; com typeRoute de menor risco, a menos exposta vem primeiro
+				summaries = s.scoreSegmentRoutes(ctx, summaries, exposureZones, originData.lat, originData.lon, destData.lat, destData.lon, originData.geocode, destData.geocode, s.convertCoordinatesToCEPRequest(data))
+
 				// Adicionar informações de zonas de atenção aos summaries
 				if hasAttention && len(summaries) > 0 {
-					attentionInfo := s.createAttentionZoneInfo(attentionOffs, originData.lat, originData.lon, destData.lat, destData.lon)
+					attentionInfo := s.createAttentionZoneInfo(attentionOffs, originData.lat, originData.lon, destData.lat, destData.lon, newRouteTimeline(OSRMRoute{Distance: summaries[0].Distance.Value, Duration: summaries[0].Duration.Value}, 0, data.DepartureTime))
 					summaries[0].AttentionZones = &attentionInfo
 				}
 
@@ -3913,10 +3746,10 @@ func (s *Service) CalculateDistancesBetweenPointsWithRiskAvoidanceFromCoordinate
 				var summaries []RouteSummary
 				if hasRisk {
 					// Se há zonas de risco, calcular rota alternativa
-					summaries = s.calculateAlternativeRouteWithAvoidance(ctx, *clientPool, riskZones, originData.lat, originData.lon, destData.lat, destData.lon, originData.geocode, destData.geocode, s.convertCoordinatesToCEPRequest(data))
+					summaries = s.calculateAlternativeRouteWithAvoidance(ctx, riskZones, originData.lat, originData.lon, destData.lat, destData.lon, originData.geocode, destData.geocode, s.convertCoordinatesToCEPRequest(data))
 				} else {
 					// Se não há zonas de risco, usar rota direta (mesmo que tenha zonas de atenção)
-					summaries = s.calculateDirectRoute(ctx, *clientPool, originData.lat, originData.lon, destData.lat, destData.lon, originData.geocode, destData.geocode, s.convertCoordinatesToCEPRequest(data))
+					summaries = s.calculateDirectRoute(ctx, originData.lat, originData.lon, destData.lat, destData.lon, originData.geocode, destData.geocode, s.convertCoordinatesToCEPRequest(data))
 				}
 
 				if len(summaries) == 0 {
@@ -3924,9 +3757,12 @@ func (s *Service) CalculateDistancesBetweenPointsWithRiskAvoidanceFromCoordinate
 					summaries = []RouteSummary{fb}
 				}
 
+				// Exposição ao risco de cada rota do trecho; com typeRoute de menor risco, a menos exposta vem primeiro
+				summaries = s.scoreSegmentRoutes(ctx, summaries, exposureZones, originData.lat, originData.lon, destData.lat, destData.lon, originData.geocode, destData.geocode, s.convertCoordinatesToCEPRequest(data))
+
 				// Adicionar informações de zonas de atenção aos summaries
 				if hasAttention && len(summaries) > 0 {
-					attentionInfo := s.createAttentionZoneInfo(attentionOffs, originData.lat, originData.lon, destData.lat, destData.lon)
+					attentionInfo := s.createAttentionZoneInfo(attentionOffs, originData.lat, originData.lon, destData.lat, destData.lon, newRouteTimeline(OSRMRoute{Distance: summaries[0].Distance.Value, Duration: summaries[0].Duration.Value}, 0, data.DepartureTime))
 					summaries[0].AttentionZones = &attentionInfo
 				}
 
@@ -3968,12 +3804,21 @@ func (s *Service) CalculateDistancesBetweenPointsWithRiskAvoidanceFromCoordinate
 	}
 
 	// Calcular rota total com desvios - agora retorna múltiplas opções
-	totalRoute, allTotalRoutes := s.calculateTotalRouteWithAvoidanceFromCoordinates(ctx, *clientPool, riskZones, riskAtentions, data.Coordinates, totalDistance, totalDuration, data)
+	totalRoute, allTotalRoutes := s.calculateTotalRouteWithAvoidanceFromCoordinates(ctx, riskZones, riskAtentions, data.Coordinates, totalDistance, totalDuration, data)
+
+	// Exposição ao risco de cada opção de rota total; com typeRoute de menor risco, a menos exposta vira a rota total
+	s.scoreTotalRoute(&totalRoute, exposureZones, data.DepartureTime)
+	for i := range allTotalRoutes {
+		s.scoreTotalRoute(&allTotalRoutes[i], exposureZones, data.DepartureTime)
+	}
+	if isLowestRiskRoute(data.TypeRoute) {
+		totalRoute, allTotalRoutes = rankTotalRoutesByRisk(totalRoute, allTotalRoutes)
+	}
 
 	// Filtrar balanças para a rota total se disponível
 	var routeBalancas interface{}
-	if balancas != nil && totalRoute.Polyline != "" {
-		filteredBalancas, err := s.findBalancaOnRoute(totalRoute.Polyline, balancas)
+	if totalRoute.Polyline != "" {
+		filteredBalancas, err := s.findBalancaOnRoute(ctx, totalRoute.routeForTimeline(), data.DepartureTime)
 		if err != nil {
 			log.Printf("Erro ao filtrar balanças: %v", err)
 			routeBalancas = nil
@@ -3988,7 +3833,7 @@ func (s *Service) CalculateDistancesBetweenPointsWithRiskAvoidanceFromCoordinate
 	// Atualizar todas as rotas com as balanças filtradas
 	for i := range allTotalRoutes {
 		if allTotalRoutes[i].Polyline != "" {
-			filteredBalancas, err := s.findBalancaOnRoute(allTotalRoutes[i].Polyline, balancas)
+			filteredBalancas, err := s.findBalancaOnRoute(ctx, allTotalRoutes[i].routeForTimeline(), data.DepartureTime)
 			if err == nil {
 				allTotalRoutes[i].Balances = filteredBalancas
 			}
@@ -4002,6 +3847,8 @@ func (s *Service) CalculateDistancesBetweenPointsWithRiskAvoidanceFromCoordinate
 		TotalRoutes: allTotalRoutes, // Sempre inclui todas as rotas disponíveis
 	}
 
+	s.recordTotalRouteEmissions(ctx, totalRoute, data.TokenOrgID, data.Type)
+
 	return response, nil
 }
 
@@ -4024,18 +3871,61 @@ func (s *Service) convertCoordinatesToCEPRequest(data FrontInfoCoordinatesReques
 		RouteOptions:    data.RouteOptions,
 		Waypoints:       data.Waypoints,
 		OrganizationID:  data.OrganizationID,
+		VehicleInfo:     data.VehicleInfo,
+		DepartureTime:   data.DepartureTime,
+		Locale:          data.Locale,
+		DriverRules:     data.DriverRules,
+	}
+}
+
+// convertV2ToCEPRequest adapta a requisição V2 para as funções que recebem FrontInfoCEPRequest
+func (s *Service) convertV2ToCEPRequest(data FrontInfoCEPRequestV2) FrontInfoCEPRequest {
+	return FrontInfoCEPRequest{
+		CEPs:            data.CEPs,
+		ConsumptionCity: data.ConsumptionCity,
+		ConsumptionHwy:  data.ConsumptionHwy,
+		Price:           data.Price,
+		Axles:           data.Axles,
+		Type:            data.Type,
+		TypeRoute:       data.TypeRoute,
+		RouteOptions:    data.RouteOptions,
+		Waypoints:       data.Waypoints,
+		OrganizationID:  data.OrganizationID,
+		VehicleInfo:     data.VehicleInfo,
+		DepartureTime:   data.DepartureTime,
+		Locale:          data.Locale,
+		DriverRules:     data.DriverRules,
 	}
 }
 
 // CalculateDistancesBetweenPointsFromCoordinates função auxiliar para fallback
 func (s *Service) CalculateDistancesBetweenPointsFromCoordinates(ctx context.Context, data FrontInfoCoordinatesRequest) (Response, error) {
+	var stopOrder []int
+	if data.Optimize {
+		coords, order, err := s.optimizeCoordinateOrder(ctx, data)
+		if err != nil {
+			return Response{}, err
+		}
+		data.Coordinates = coords
+		data.Optimize = false
+		stopOrder = order
+	}
+
 	// Converter para CEPs fictícios e usar a função existente
 	cepData := s.convertCoordinatesToCEPRequest(data)
-	return s.CalculateDistancesBetweenPoints(ctx, cepData)
+	resp, err := s.CalculateDistancesBetweenPoints(ctx, cepData)
+	if err != nil {
+		return Response{}, err
+	}
+	if stopOrder != nil {
+		resp.StopOrder = stopOrder
+		resp.OptimizedBy = data.criteria()
+	}
+	return resp, nil
 }
 
 // calculateTotalRouteWithAvoidanceFromCoordinates calcula rota total com desvios para coordenadas
-func (s *Service) calculateTotalRouteWithAvoidanceFromCoordinates(ctx context.Context, client http.Client, riskZones []RiskZone, attentionZones []RiskZone, coordinates []Coordinate, totalDistance, totalDuration float64, data FrontInfoCoordinatesRequest) (TotalSummary, []TotalSummary) {
+func (s *Service) calculateTotalRouteWithAvoidanceFromCoordinates(ctx context.Context, riskZones []RiskZone, attentionZones []RiskZone, coordinates []Coordinate, totalDistance, totalDuration float64, data FrontInfoCoordinatesRequest) (TotalSummary, []TotalSummary) {
 
 	// ------------------------------
 	// 1) Monta lista base de coords e endereços
@@ -4101,39 +3991,11 @@ func (s *Service) calculateTotalRouteWithAvoidanceFromCoordinates(ctx context.Co
 
 		// helper para calcular a polyline do segmento COM os via-points atuais
 		routeForSegment := func() (OSRMRoute, bool) {
-			coords := fmt.Sprintf("%f,%f", lon1, lat1)
-			for _, wp := range segWps {
-				coords += fmt.Sprintf(";%f,%f", wp.Longitude, wp.Latitude)
-			}
-			coords += fmt.Sprintf(";%f,%f", lon2, lat2)
-
-			profile := "driving"
-			if data.Type != "" {
-				profile = data.Type // ex: "car", "truck"
-			}
-
-			u := fmt.Sprintf("http://34.207.174.233:5000/route/v1/%s/%s?alternatives=0&steps=true&overview=full&continue_straight=false",
-				profile, neturl.PathEscape(coords))
+			coords := append([]Location{{Latitude: lat1, Longitude: lon1}}, segWps...)
+			coords = append(coords, Location{Latitude: lat2, Longitude: lon2})
 
-			// Cliente com timeout reduzido para melhor performance
-			fastClient := http.Client{Timeout: 15 * time.Second}
-			resp, err := fastClient.Get(u)
-			if err != nil || resp.StatusCode != 200 {
-				u = fmt.Sprintf("http://34.207.174.233:5000/route/v1/driving/%s?alternatives=0&steps=true&overview=full&continue_straight=false",
-					neturl.PathEscape(coords))
-				resp, err = fastClient.Get(u)
-			}
-
-			if err != nil || resp == nil {
-				log.Printf("ERRO: requisição OSRM falhou nas duas tentativas - err=%v", err)
-				return OSRMRoute{}, false
-			}
-
-			if resp.Body != nil {
-				defer resp.Body.Close()
-			}
-			var r OSRMResponse
-			if json.NewDecoder(resp.Body).Decode(&r) != nil || len(r.Routes) == 0 {
+			r, err := s.engineRoute(ctx, 15*time.Second, RouteRequest{Coordinates: coords, Profile: data.Type, AllowUTurn: true})
+			if err != nil {
 				return OSRMRoute{}, false
 			}
 			return r.Routes[0], true
@@ -4157,11 +4019,15 @@ func (s *Service) calculateTotalRouteWithAvoidanceFromCoordinates(ctx context.Co
 				injected := false
 
 				if nx != 0 || ny != 0 {
-					baseDist := float64(off.Zone.Radius) + 200.0 // raio + buffer
 					mid := Location{
 						Latitude:  (off.Entry.Latitude + off.Exit.Latitude) / 2,
 						Longitude: (off.Entry.Longitude + off.Exit.Longitude) / 2,
 					}
+					baseDist := float64(off.Zone.Radius) + 200.0 // raio + buffer
+					if area := off.Zone.shapeArea(); area != nil {
+						// nas formas, até a borda do lado do desvio (o círculo envolvente de um corredor é enorme)
+						baseDist = math.Max(area.Extent(zonas_risco.Point{Lat: mid.Latitude, Lng: mid.Longitude}, nx, ny), 0) + 200.0
+					}
 					buildSeq := func(scale float64) []Location {
 						d := baseDist * scale
 						p1 := s.offsetByNormal(off.Before5km, latRef, nx, ny, d)
@@ -4173,35 +4039,16 @@ func (s *Service) calculateTotalRouteWithAvoidanceFromCoordinates(ctx context.Co
 					for _, sc := range []float64{1.0, 1.3, 1.6, 2.0} {
 						cand := buildSeq(sc)
 						// Snap GARANTINDO ficar fora da zona
-						cand = s.snapOutsideMany(client, cand, off.Zone)
+						cand = s.snapOutsideMany(cand, off.Zone, vehicleProfile(data.Type))
 
 						// Testa rota do segmento com segWps + cand
 						segTest := append(append([]Location{}, segWps...), cand...)
 						if r2, ok2 := func() (OSRMRoute, bool) {
-							coords := fmt.Sprintf("%f,%f", lon1, lat1)
-							for _, wp := range segTest {
-								coords += fmt.Sprintf(";%f,%f", wp.Longitude, wp.Latitude)
-							}
-							coords += fmt.Sprintf(";%f,%f", lon2, lat2)
-
-							profile := "driving"
-							if data.Type != "" {
-								profile = data.Type // ex: "car", "truck"
-							}
-
-							u := fmt.Sprintf("http://34.207.174.233:5000/route/v1/%s/%s?alternatives=0&steps=true&overview=full&continue_straight=false",
-								profile, neturl.PathEscape(coords))
+							coords := append([]Location{{Latitude: lat1, Longitude: lon1}}, segTest...)
+							coords = append(coords, Location{Latitude: lat2, Longitude: lon2})
 
-							resp, err := client.Get(u)
-							if err != nil || resp.StatusCode != 200 {
-								u = fmt.Sprintf("http://34.207.174.233:5000/route/v1/driving/%s?alternatives=0&steps=true&overview=full&continue_straight=false",
-									neturl.PathEscape(coords))
-								resp, err = client.Get(u)
-							}
-
-							defer resp.Body.Close()
-							var r OSRMResponse
-							if json.NewDecoder(resp.Body).Decode(&r) != nil || len(r.Routes) == 0 {
+							r, err := s.engineRoute(ctx, 15*time.Second, RouteRequest{Coordinates: coords, Profile: data.Type, AllowUTurn: true})
+							if err != nil {
 								return OSRMRoute{}, false
 							}
 							return r.Routes[0], true
@@ -4224,34 +4071,15 @@ func (s *Service) calculateTotalRouteWithAvoidanceFromCoordinates(ctx context.Co
 				if !injected {
 					// menor arco
 					seq := s.assembleLateralDetour(off.Entry, off.Exit, off.Zone, 2, 200, 80, false)
-					seq = s.snapOutsideMany(client, seq, off.Zone)
+					seq = s.snapOutsideMany(seq, off.Zone, vehicleProfile(data.Type))
 					tryInject := func(cand []Location) bool {
 						test := append(append([]Location{}, segWps...), cand...)
 						if r2, ok2 := func() (OSRMRoute, bool) {
-							coords := fmt.Sprintf("%f,%f", lon1, lat1)
-							for _, wp := range test {
-								coords += fmt.Sprintf(";%f,%f", wp.Longitude, wp.Latitude)
-							}
-							coords += fmt.Sprintf(";%f,%f", lon2, lat2)
-
-							profile := "driving"
-							if data.Type != "" {
-								profile = data.Type // ex: "car", "truck"
-							}
-
-							u := fmt.Sprintf("http://34.207.174.233:5000/route/v1/%s/%s?alternatives=0&steps=true&overview=full&continue_straight=false",
-								profile, neturl.PathEscape(coords))
-
-							resp, err := client.Get(u)
-							if err != nil || resp.StatusCode != 200 {
-								u = fmt.Sprintf("http://34.207.174.233:5000/route/v1/driving/%s?alternatives=0&steps=true&overview=full&continue_straight=false",
-									neturl.PathEscape(coords))
-								resp, err = client.Get(u)
-							}
+							coords := append([]Location{{Latitude: lat1, Longitude: lon1}}, test...)
+							coords = append(coords, Location{Latitude: lat2, Longitude: lon2})
 
-							defer resp.Body.Close()
-							var r OSRMResponse
-							if json.NewDecoder(resp.Body).Decode(&r) != nil || len(r.Routes) == 0 {
+							r, err := s.engineRoute(ctx, 15*time.Second, RouteRequest{Coordinates: coords, Profile: data.Type, AllowUTurn: true})
+							if err != nil {
 								return OSRMRoute{}, false
 							}
 							return r.Routes[0], true
@@ -4271,17 +4099,17 @@ func (s *Service) calculateTotalRouteWithAvoidanceFromCoordinates(ctx context.Co
 					if !tryInject(seq) {
 						// arco oposto
 						seq2 := s.assembleLateralDetour(off.Entry, off.Exit, off.Zone, 2, 200, 80, true)
-						seq2 = s.snapOutsideMany(client, seq2, off.Zone)
+						seq2 = s.snapOutsideMany(seq2, off.Zone, vehicleProfile(data.Type))
 						if !tryInject(seq2) {
 							// A/B padrão
 							wpA, wpB := s.computeBypassWaypoints(lat1, lon1, lat2, lon2, off.Zone)
-							ab := s.snapOutsideMany(client, []Location{wpA, wpB}, off.Zone)
+							ab := s.snapOutsideMany([]Location{wpA, wpB}, off.Zone, vehicleProfile(data.Type))
 							if !tryInject(ab) {
 								// A/B escalado
 								for _, sc := range []float64{1.5, 2.0, 3.0} {
-									wpA2 := s.pushAwayFromCenter(wpA, off.Zone, float64(off.Zone.Radius)*(sc-1)+500)
-									wpB2 := s.pushAwayFromCenter(wpB, off.Zone, float64(off.Zone.Radius)*(sc-1)+500)
-									ab2 := s.snapOutsideMany(client, []Location{wpA2, wpB2}, off.Zone)
+									wpA2 := s.scaledBypassPoint(wpA, off.Zone, sc)
+									wpB2 := s.scaledBypassPoint(wpB, off.Zone, sc)
+									ab2 := s.snapOutsideMany([]Location{wpA2, wpB2}, off.Zone, vehicleProfile(data.Type))
 									if tryInject(ab2) {
 										break
 									}
@@ -4313,46 +4141,7 @@ func (s *Service) calculateTotalRouteWithAvoidanceFromCoordinates(ctx context.Co
 	// ------------------------------
 	var totalRoute TotalSummary
 
-	coordsStr := strings.Join(newCoords, ";")
-	baseOSRMURL := "http://34.207.174.233:5000/route/v1/driving/" + neturl.PathEscape(coordsStr)
-
-	// URLs para diferentes tipos de rota (EXATAMENTE igual ao CalculateRoutes)
-	osrmURLFast := baseOSRMURL + "?" + neturl.Values{
-		"alternatives":      {"3"},
-		"steps":             {"true"},
-		"overview":          {"full"},
-		"continue_straight": {"false"},
-	}.Encode()
-
-	osrmURLNoTolls := baseOSRMURL + "?" + neturl.Values{
-		"alternatives": {"3"},
-		"steps":        {"true"},
-		"overview":     {"full"},
-		"exclude":      {"toll"},
-	}.Encode()
-
-	osrmURLEfficient := baseOSRMURL + "?" + neturl.Values{
-		"alternatives": {"3"},
-		"steps":        {"true"},
-		"overview":     {"full"},
-		"exclude":      {"motorway"},
-	}.Encode()
-
-	// URLs adicionais para mais variações de rota
-	osrmURLFastNoFerry := baseOSRMURL + "?" + neturl.Values{
-		"alternatives":      {"3"},
-		"steps":             {"true"},
-		"overview":          {"full"},
-		"continue_straight": {"false"},
-		"exclude":           {"ferry"},
-	}.Encode()
-
-	osrmURLBalanced := baseOSRMURL + "?" + neturl.Values{
-		"alternatives": {"3"},
-		"steps":        {"true"},
-		"overview":     {"full"},
-		"exclude":      {"ferry,toll"},
-	}.Encode()
+	totalCoords := parseOSRMCoordinates(strings.Join(newCoords, ";"))
 
 	// Estrutura para resultados paralelos
 	type osrmTotalResult struct {
@@ -4363,31 +4152,25 @@ func (s *Service) calculateTotalRouteWithAvoidanceFromCoordinates(ctx context.Co
 	totalResultsCh := make(chan osrmTotalResult, 5) // Aumenta para 5 requisições
 
 	// Função para fazer requisições paralelas
-	makeTotalOSRMRequest := func(url, category, errMsg string) {
-		resp, err := client.Get(url)
-		if err != nil {
-			totalResultsCh <- osrmTotalResult{err: fmt.Errorf("%s: %w", errMsg, err), category: category}
-			return
-		}
-		defer resp.Body.Close()
-		var osrmResp OSRMResponse
-		if err := json.NewDecoder(resp.Body).Decode(&osrmResp); err != nil {
-			totalResultsCh <- osrmTotalResult{err: fmt.Errorf("erro ao decodificar resposta OSRM (%s): %w", category, err), category: category}
+	makeTotalOSRMRequest := func(req RouteRequest, category, errMsg string) {
+		osrmResp, err := s.engineAlternatives(ctx, 10*time.Second, req, 3)
+		if errors.Is(err, ErrNoRoute) {
+			totalResultsCh <- osrmTotalResult{err: fmt.Errorf("OSRM (%s) retornou erro ou nenhuma rota encontrada", category), category: category}
 			return
 		}
-		if osrmResp.Code != "Ok" || len(osrmResp.Routes) == 0 {
-			totalResultsCh <- osrmTotalResult{err: fmt.Errorf("OSRM (%s) retornou erro ou nenhuma rota encontrada", category), category: category}
+		if err != nil {
+			totalResultsCh <- osrmTotalResult{err: fmt.Errorf("%s: %w", errMsg, err), category: category}
 			return
 		}
 		totalResultsCh <- osrmTotalResult{resp: osrmResp, category: category}
 	}
 
-	// Lança requisições paralelas - agora com mais variações
-	go makeTotalOSRMRequest(osrmURLFast, "fastest", "erro na requisição OSRM total (rota rápida)")
-	go makeTotalOSRMRequest(osrmURLNoTolls, "cheapest", "erro na requisição OSRM total (rota com menos pedágio)")
-	go makeTotalOSRMRequest(osrmURLEfficient, "efficient", "erro na requisição OSRM total (rota eficiente)")
-	go makeTotalOSRMRequest(osrmURLFastNoFerry, "fastest_no_ferry", "erro na requisição OSRM total (rota rápida sem ferry)")
-	go makeTotalOSRMRequest(osrmURLBalanced, "balanced", "erro na requisição OSRM total (rota balanceada)")
+	// Lança requisições paralelas (mesmas variações do CalculateRoutes + ferry/balanceada)
+	go makeTotalOSRMRequest(RouteRequest{Coordinates: totalCoords, AllowUTurn: true}, "fastest", "erro na requisição OSRM total (rota rápida)")
+	go makeTotalOSRMRequest(RouteRequest{Coordinates: totalCoords, Exclude: []string{"toll"}}, "cheapest", "erro na requisição OSRM total (rota com menos pedágio)")
+	go makeTotalOSRMRequest(RouteRequest{Coordinates: totalCoords, Exclude: []string{"motorway"}}, "efficient", "erro na requisição OSRM total (rota eficiente)")
+	go makeTotalOSRMRequest(RouteRequest{Coordinates: totalCoords, AllowUTurn: true, Exclude: []string{"ferry"}}, "fastest_no_ferry", "erro na requisição OSRM total (rota rápida sem ferry)")
+	go makeTotalOSRMRequest(RouteRequest{Coordinates: totalCoords, Exclude: []string{"ferry", "toll"}}, "balanced", "erro na requisição OSRM total (rota balanceada)")
 
 	var osrmTotalRespFast, osrmTotalRespNoTolls, osrmTotalRespEfficient, osrmTotalRespFastNoFerry, osrmTotalRespBalanced OSRMResponse
 
@@ -4432,7 +4215,7 @@ func (s *Service) calculateTotalRouteWithAvoidanceFromCoordinates(ctx context.Co
 				}
 			}
 
-			routeSummary := s.createTotalSummaryWithURLWaypoints(route, originLocation, destinationLocation, waypoints, waypointsForURL, s.convertCoordinatesToCEPRequest(data))
+			routeSummary := s.createTotalSummaryWithURLWaypoints(ctx, route, originLocation, destinationLocation, waypoints, waypointsForURL, s.convertCoordinatesToCEPRequest(data))
 
 			// Usa os valores da rota OSRM (que são mais precisos para a rota total)
 			// Se a rota OSRM retornar valores válidos, usa eles; senão usa os acumulados como fallback
@@ -4443,10 +4226,11 @@ func (s *Service) calculateTotalRouteWithAvoidanceFromCoordinates(ctx context.Co
 				routeSummary.TotalDistance = Distance{Text: distText, Value: distVal}
 				routeSummary.TotalDuration = Duration{Text: durText, Value: durVal}
 
-				// Recalcula custo de combustível com a distância correta
-				avgConsumption := (data.ConsumptionCity + data.ConsumptionHwy) / 2
-				totalKm := route.Distance / 1000
-				routeSummary.TotalFuelCost = math.Round((data.Price / avgConsumption) * totalKm)
+				// Recalcula custo de combustível com a distância correta, separando cidade e rodovia
+				fuelSplit := s.routeFuelSplit(ctx, route, data.Type, data.Price, data.ConsumptionCity, data.ConsumptionHwy)
+				routeSummary.TotalFuelCost = fuelSplit.TotalCost()
+				routeSummary.FuelSplit = &fuelSplit
+				routeSummary.Emissions = routeEmissions(fuelSplit, data.Type, data.Axles, data.VehicleInfo)
 			} else {
 				// Fallback para valores acumulados se OSRM retornar valores inválidos
 				distText, distVal := formatDistance(totalDistance)
@@ -4509,18 +4293,10 @@ func (s *Service) calculateTotalRouteWithAvoidanceFromCoordinates(ctx context.Co
 	if totalRoute.TotalDistance.Value == 0 {
 		// tenta rota total padrão para obter polyline
 		baseCoords := strings.Join(allCoords, ";")
-		urlBase := fmt.Sprintf(
-			"http://34.207.174.233:5000/route/v1/driving/%s?alternatives=0&steps=true&overview=full&continue_straight=false",
-			neturl.PathEscape(baseCoords),
-		)
 
-		if resp, err := client.Get(urlBase); err == nil {
-			defer resp.Body.Close()
-			var osrmResp OSRMResponse
-			if err := json.NewDecoder(resp.Body).Decode(&osrmResp); err == nil && len(osrmResp.Routes) > 0 {
-				route := osrmResp.Routes[0]
-				totalRoute = s.createTotalSummary(route, originLocation, destinationLocation, waypoints, s.convertCoordinatesToCEPRequest(data))
-			}
+		if osrmResp, err := s.engineRoute(ctx, 10*time.Second, RouteRequest{Coordinates: parseOSRMCoordinates(baseCoords), AllowUTurn: true}); err == nil {
+			route := osrmResp.Routes[0]
+			totalRoute = s.createTotalSummary(ctx, route, originLocation, destinationLocation, waypoints, s.convertCoordinatesToCEPRequest(data))
 		}
 	}
 
@@ -4535,23 +4311,15 @@ func (s *Service) calculateTotalRouteWithAvoidanceFromCoordinates(ctx context.Co
 
 		// Buscar pedágios para a rota base (sem desvios)
 		baseCoords := strings.Join(allCoords, ";")
-		urlBase := fmt.Sprintf(
-			"http://34.207.174.233:5000/route/v1/driving/%s?alternatives=0&steps=true&overview=full&continue_straight=false",
-			neturl.PathEscape(baseCoords),
-		)
 
 		var tolls []Toll
 		var totalTollCost float64
 		var osrmRoute OSRMRoute
-		if resp, err := client.Get(urlBase); err == nil {
-			defer resp.Body.Close()
-			var osrmResp OSRMResponse
-			if err := json.NewDecoder(resp.Body).Decode(&osrmResp); err == nil && len(osrmResp.Routes) > 0 {
-				osrmRoute = osrmResp.Routes[0]
-				tolls, _ = s.findTollsOnRoute(ctx, osrmRoute.Geometry, data.Type, float64(data.Axles))
-				for _, toll := range tolls {
-					totalTollCost += toll.CashCost
-				}
+		if osrmResp, err := s.engineRoute(ctx, 10*time.Second, RouteRequest{Coordinates: parseOSRMCoordinates(baseCoords), AllowUTurn: true}); err == nil {
+			osrmRoute = osrmResp.Routes[0]
+			tolls, _ = s.findTollsOnRoute(ctx, osrmRoute, newTollVehicle(data.Type, data.Axles, data.VehicleInfo), data.DepartureTime)
+			for _, toll := range tolls {
+				totalTollCost += toll.PaidCost
 			}
 		}
 
@@ -4593,7 +4361,7 @@ func (s *Service) calculateTotalRouteWithAvoidanceFromCoordinates(ctx context.Co
 			TotalTolls:    math.Round(totalTollCost*100) / 100,
 			Polyline:      "",
 			TotalFuelCost: totalFuelCost,
-			Instructions:  s.processOSRMStepsToInstructions(osrmRoute),
+			Instructions:  s.processOSRMStepsToInstructions(osrmRoute, data.Locale),
 		}
 	}
 
@@ -4605,7 +4373,7 @@ func (s *Service) calculateTotalRouteWithAvoidanceFromCoordinates(ctx context.Co
 		attentionOffs, hasAttention := s.CheckRouteForAllAttentionZones(attentionZones, originLocation.Latitude, originLocation.Longitude, destinationLocation.Latitude, destinationLocation.Longitude)
 
 		if hasAttention {
-			attentionInfo := s.createAttentionZoneInfo(attentionOffs, originLocation.Latitude, originLocation.Longitude, destinationLocation.Latitude, destinationLocation.Longitude)
+			attentionInfo := s.createAttentionZoneInfo(attentionOffs, originLocation.Latitude, originLocation.Longitude, destinationLocation.Latitude, destinationLocation.Longitude, newRouteTimeline(totalRoute.routeForTimeline(), 0, data.DepartureTime))
 			totalRoute.AttentionZones = &attentionInfo
 		}
 	}
@@ -4632,15 +4400,7 @@ func (s *Service) getActiveRiskZones(ctx context.Context, id int64) ([]RiskZone,
 	var riskZones []RiskZone
 	for _, dbZone := range dbZones {
 		if !dbZone.ZonaAtencao {
-			riskZones = append(riskZones, RiskZone{
-				ID:     dbZone.ID,
-				Name:   dbZone.Name,
-				Cep:    dbZone.Cep,
-				Lat:    dbZone.Lat,
-				Lng:    dbZone.Lng,
-				Radius: dbZone.Radius,
-				Status: dbZone.Status,
-			})
+			riskZones = append(riskZones, newRiskZone(dbZone))
 		}
 	}
 
@@ -4666,15 +4426,7 @@ func (s *Service) getRiskAtentions(ctx context.Context, id int64) ([]RiskZone, e
 	var riskZones []RiskZone
 	for _, dbZone := range dbZones {
 		if dbZone.ZonaAtencao {
-			riskZones = append(riskZones, RiskZone{
-				ID:     dbZone.ID,
-				Name:   dbZone.Name,
-				Cep:    dbZone.Cep,
-				Lat:    dbZone.Lat,
-				Lng:    dbZone.Lng,
-				Radius: dbZone.Radius,
-				Status: dbZone.Status,
-			})
+			riskZones = append(riskZones, newRiskZone(dbZone))
 		}
 	}
 
@@ -4689,27 +4441,13 @@ func (s *Service) CheckRouteForRiskZones(riskZones []RiskZone, originLat, origin
 	}
 
 	// Primeiro, calcular a rota real com OSRM para verificar todos os pontos
-	client := http.Client{Timeout: 15 * time.Second}
-	coords := fmt.Sprintf("%f,%f;%f,%f", originLon, originLat, destLon, destLat)
-	url := fmt.Sprintf("http://34.207.174.233:5000/route/v1/driving/%s?overview=full&steps=true", neturl.PathEscape(coords))
-
-	resp, err := client.Get(url)
+	osrmResp, err := s.engineRoute(context.Background(), 15*time.Second, RouteRequest{
+		Coordinates: []Location{{Latitude: originLat, Longitude: originLon}, {Latitude: destLat, Longitude: destLon}},
+	})
 	if err != nil {
 		// Fallback para verificação de linha reta
 		return s.checkRouteForRiskZonesFallback(riskZones, originLat, originLon, destLat, destLon), LocationHisk{}
 	}
-	defer resp.Body.Close()
-
-	var osrmResp OSRMResponse
-	if err := json.NewDecoder(resp.Body).Decode(&osrmResp); err != nil {
-		// Fallback para verificação de linha reta
-		return s.checkRouteForRiskZonesFallback(riskZones, originLat, originLon, destLat, destLon), LocationHisk{}
-	}
-
-	if len(osrmResp.Routes) == 0 {
-		// Fallback para verificação de linha reta
-		return s.checkRouteForRiskZonesFallback(riskZones, originLat, originLon, destLat, destLon), LocationHisk{}
-	}
 
 	route := osrmResp.Routes[0]
 
@@ -4717,7 +4455,48 @@ func (s *Service) CheckRouteForRiskZones(riskZones []RiskZone, originLat, origin
 	return s.checkRouteGeometryForRiskZones(riskZones, route.Geometry, originLat, originLon, destLat, destLon)
 }
 
+// newRiskZone converte a zona cadastrada e já prepara a geometria de polígonos e corredores
+func newRiskZone(dbZone zonas_risco.ZonaRiscoResponse) RiskZone {
+	zone := RiskZone{
+		ID:           dbZone.ID,
+		Name:         dbZone.Name,
+		Cep:          dbZone.Cep,
+		Lat:          dbZone.Lat,
+		Lng:          dbZone.Lng,
+		Radius:       dbZone.Radius,
+		Status:       dbZone.Status,
+		ZonasAtencao: dbZone.ZonaAtencao,
+		Shape:        dbZone.Shape,
+		Geometry:     dbZone.Geometry,
+		BufferMeters: dbZone.BufferMeters,
+		Severity:     dbZone.Severity,
+	}
+	zone.area = zone.shapeArea()
+	zone.schedule = newZoneSchedule(dbZone)
+	return zone
+}
+
+// shapeArea devolve a forma da zona, ou nil para zonas circulares (e geometrias inválidas, que caem no círculo)
+func (z RiskZone) shapeArea() *zonas_risco.Area {
+	if z.area != nil {
+		return z.area
+	}
+	if z.Shape == "" || z.Shape == zonas_risco.ShapeCircle || len(z.Geometry) == 0 {
+		return nil
+	}
+	area, err := zonas_risco.NewArea(z.Shape, z.Geometry, float64(z.BufferMeters))
+	if err != nil {
+		log.Printf("Geometria inválida na zona de risco %d, usando o círculo: %v", z.ID, err)
+		return nil
+	}
+	return area
+}
+
 func (s *Service) isPointInRiskZone(lat, lng float64, zone RiskZone) bool {
+	if area := zone.shapeArea(); area != nil {
+		return area.Contains(lat, lng)
+	}
+
 	distance := s.haversineDistance(lat, lng, zone.Lat, zone.Lng)
 	isInside := distance <= float64(zone.Radius)
 
@@ -4725,6 +4504,12 @@ func (s *Service) isPointInRiskZone(lat, lng float64, zone RiskZone) bool {
 }
 
 func (s *Service) doesRouteCrossRiskZone(originLat, originLon, destLat, destLon float64, zone RiskZone) bool {
+	if area := zone.shapeArea(); area != nil {
+		from := zonas_risco.Point{Lat: originLat, Lng: originLon}
+		to := zonas_risco.Point{Lat: destLat, Lng: destLon}
+		return len(area.Crossings(from, to)) > 0 || area.Contains(originLat, originLon) || area.Contains(destLat, destLon)
+	}
+
 	// Distância do centro da zona até a linha da rota
 	distanceToRoute := s.distancePointToLine(zone.Lat, zone.Lng, originLat, originLon, destLat, destLon)
 
@@ -4793,7 +4578,7 @@ func (s *Service) haversineDistance(lat1, lng1, lat2, lng2 float64) float64 {
 }
 
 // calculateAlternativeRouteWithAvoidance calcula rota alternativa evitando zonas de risco
-func (s *Service) calculateAlternativeRouteWithAvoidance(ctx context.Context, client http.Client, riskZones []RiskZone, originLat, originLon, destLat, destLon float64, originGeocode, destGeocode GeocodeResult, data FrontInfoCEPRequest) []RouteSummary {
+func (s *Service) calculateAlternativeRouteWithAvoidance(ctx context.Context, riskZones []RiskZone, originLat, originLon, destLat, destLon float64, originGeocode, destGeocode GeocodeResult, data FrontInfoCEPRequest) []RouteSummary {
 
 	const arcExtraBuffer = 200.0
 	const arcPoints = 2
@@ -4801,36 +4586,14 @@ func (s *Service) calculateAlternativeRouteWithAvoidance(ctx context.Context, cl
 
 	// -------- util: calcula rota OSRM (ignora risco) com a sequência atual de via-points
 	routeRaw := func(wps []Location, tag string) (OSRMRoute, bool) {
-		coords := fmt.Sprintf("%f,%f", originLon, originLat)
-		for _, wp := range wps {
-			coords += fmt.Sprintf(";%f,%f", wp.Longitude, wp.Latitude)
-		}
-		coords += fmt.Sprintf(";%f,%f", destLon, destLat)
-
-		profile := "driving"
-		if data.Type != "" {
-			profile = data.Type // ex: "car", "truck"
-		}
-
-		u := fmt.Sprintf("http://34.207.174.233:5000/route/v1/%s/%s?alternatives=0&steps=true&overview=full&continue_straight=false",
-			profile, neturl.PathEscape(coords))
-
-		// Cliente com timeout reduzido para melhor performance
-		fastClient := http.Client{Timeout: 15 * time.Second}
-		resp, err := fastClient.Get(u)
-		if err != nil || resp.StatusCode != 200 {
-			u = fmt.Sprintf("http://34.207.174.233:5000/route/v1/driving/%s?alternatives=0&steps=true&overview=full&continue_straight=false",
-				neturl.PathEscape(coords))
-			resp, err = fastClient.Get(u)
-		}
-
-		defer resp.Body.Close()
+		coords := append([]Location{{Latitude: originLat, Longitude: originLon}}, wps...)
+		coords = append(coords, Location{Latitude: destLat, Longitude: destLon})
 
-		var osrmResp OSRMResponse
-		if json.NewDecoder(resp.Body).Decode(&osrmResp) != nil || len(osrmResp.Routes) == 0 {
+		r, err := s.engineRoute(ctx, 15*time.Second, RouteRequest{Coordinates: coords, Profile: data.Type, AllowUTurn: true})
+		if err != nil {
 			return OSRMRoute{}, false
 		}
-		return osrmResp.Routes[0], true
+		return r.Routes[0], true
 	}
 
 	// -------- util: valida a rota resultante contra TODAS as zonas (não apenas a atual)
@@ -4852,7 +4615,7 @@ func (s *Service) calculateAlternativeRouteWithAvoidance(ctx context.Context, cl
 		out := make([]Location, len(pts))
 		copy(out, pts)
 		for i := range out {
-			if lat, lon, ok := s.osrmNearestSnap(client, out[i].Latitude, out[i].Longitude); ok {
+			if lat, lon, ok := s.osrmNearestSnap(out[i].Latitude, out[i].Longitude, vehicleProfile(data.Type)); ok {
 				out[i].Latitude, out[i].Longitude = lat, lon
 			}
 		}
@@ -4866,14 +4629,14 @@ func (s *Service) calculateAlternativeRouteWithAvoidance(ctx context.Context, cl
 		for i := range out {
 			p := out[i]
 			for step := 0; step < 6; step++ {
-				if lat, lon, ok := s.osrmNearestSnap(client, p.Latitude, p.Longitude); ok {
-					if s.haversineDistance(lat, lon, zone.Lat, zone.Lng) > float64(zone.Radius)+5 {
+				if lat, lon, ok := s.osrmNearestSnap(p.Latitude, p.Longitude, vehicleProfile(data.Type)); ok {
+					if s.riskZoneGap(lat, lon, zone) > 5 {
 						out[i] = Location{Latitude: lat, Longitude: lon}
 						break
 					}
 				}
 				// se falhou snap ou ainda ficou dentro, empurra mais e tenta de novo
-				p = s.pushAwayFromCenter(p, zone, 120.0)
+				p = s.pushToOutside(p, zone, float64(step+1)*120.0)
 			}
 		}
 		return out
@@ -4902,13 +4665,13 @@ func (s *Service) calculateAlternativeRouteWithAvoidance(ctx context.Context, cl
 
 	// 0) se não tem risco → direta
 	if hasRisk, _ := s.CheckRouteForRiskZones(riskZones, originLat, originLon, destLat, destLon); !hasRisk {
-		return s.calculateDirectRoute(ctx, client, originLat, originLon, destLat, destLon, originGeocode, destGeocode, data)
+		return s.calculateDirectRoute(ctx, originLat, originLon, destLat, destLon, originGeocode, destGeocode, data)
 	}
 
 	// 1) rota base (sem via-points) e util p/ coletar cruzamentos
 	_, ok := routeRaw(nil, "init")
 	if !ok {
-		return s.calculateDirectRoute(ctx, client, originLat, originLon, destLat, destLon, originGeocode, destGeocode, data)
+		return s.calculateDirectRoute(ctx, originLat, originLon, destLat, destLon, originGeocode, destGeocode, data)
 	}
 	collectCrossings := func(geometry string) []RiskOffsets {
 		var offs []RiskOffsets
@@ -4930,8 +4693,8 @@ func (s *Service) calculateAlternativeRouteWithAvoidance(ctx context.Context, cl
 
 			userWps = snapMany("user", userWps)
 			if r, ok := tryRoute(userWps, "user"); ok {
-				tolls, _ := s.findTollsOnRoute(ctx, r.Geometry, data.Type, float64(data.Axles))
-				sum := s.createRouteSummary(r, "desvio_usuario", originGeocode, destGeocode, data, tolls)
+				tolls, _ := s.findTollsOnRoute(ctx, r, newTollVehicle(data.Type, data.Axles, data.VehicleInfo), data.DepartureTime)
+				sum := s.createRouteSummary(ctx, r, "desvio_usuario", originGeocode, destGeocode, data, tolls)
 				return []RouteSummary{sum}
 			}
 
@@ -4953,8 +4716,8 @@ func (s *Service) calculateAlternativeRouteWithAvoidance(ctx context.Context, cl
 		if len(crossings) == 0 {
 			// tenta finalizar (testa globalmente dentro de tryRoute)
 			if rFinal, ok := tryRoute(accumWps, "final"); ok {
-				tolls, _ := s.findTollsOnRoute(ctx, rFinal.Geometry, data.Type, float64(data.Axles))
-				sum := s.createRouteSummary(rFinal, "desvio_multi_zonas", originGeocode, destGeocode, data, tolls)
+				tolls, _ := s.findTollsOnRoute(ctx, rFinal, newTollVehicle(data.Type, data.Axles, data.VehicleInfo), data.DepartureTime)
+				sum := s.createRouteSummary(ctx, rFinal, "desvio_multi_zonas", originGeocode, destGeocode, data, tolls)
 				if len(detourPoints) > 0 {
 					sum.Detour = &DetourPlan{Source: "multi_zonas", Points: detourPoints}
 				}
@@ -5019,8 +4782,8 @@ func (s *Service) calculateAlternativeRouteWithAvoidance(ctx context.Context, cl
 		scales := []float64{1.5, 2.0, 3.0}
 		scaledOK := false
 		for _, sc := range scales {
-			wpA2 := s.pushAwayFromCenter(wpA, off.Zone, float64(off.Zone.Radius)*(sc-1)+500)
-			wpB2 := s.pushAwayFromCenter(wpB, off.Zone, float64(off.Zone.Radius)*(sc-1)+500)
+			wpA2 := s.scaledBypassPoint(wpA, off.Zone, sc)
+			wpB2 := s.scaledBypassPoint(wpB, off.Zone, sc)
 			abScaled := snapOutsideMany(fmt.Sprintf("ab_scale_%.1f", sc), []Location{wpA2, wpB2}, off.Zone)
 			if r2, ok := routeRaw(append(append([]Location{}, accumWps...), abScaled...), fmt.Sprintf("iter_%d_ab_scale_%.1f", iter, sc)); ok {
 				if hasThis, _ := s.checkRouteGeometryForRiskZones([]RiskZone{off.Zone}, r2.Geometry, originLat, originLon, destLat, destLon); !hasThis {
@@ -5103,8 +4866,8 @@ func (s *Service) calculateAlternativeRouteWithAvoidance(ctx context.Context, cl
 
 	// tenta "best_effort" já com possíveis guards
 	if r, ok := tryRoute(accumWps, "best_effort"); ok {
-		tolls, _ := s.findTollsOnRoute(ctx, r.Geometry, data.Type, float64(data.Axles))
-		sum := s.createRouteSummary(r, "desvio_multi_zonas_best_effort", originGeocode, destGeocode, data, tolls)
+		tolls, _ := s.findTollsOnRoute(ctx, r, newTollVehicle(data.Type, data.Axles, data.VehicleInfo), data.DepartureTime)
+		sum := s.createRouteSummary(ctx, r, "desvio_multi_zonas_best_effort", originGeocode, destGeocode, data, tolls)
 		if len(detourPoints) > 0 {
 			sum.Detour = &DetourPlan{Source: "multi_zonas", Points: detourPoints}
 		}
@@ -5177,11 +4940,18 @@ func (s *Service) computeBypassWaypoints(originLat, originLon, destLat, destLon
 	midX := ax + tx*vx
 	midY := ay + tx*vy
 
-	// dois pontos a lados opostos do círculo, fora da borda
-	ax2 := midX + nx*safe
-	ay2 := midY + ny*safe
-	bx2 := midX - nx*safe
-	by2 := midY - ny*safe
+	// dois pontos a lados opostos do círculo, fora da borda; nas formas, fora da borda de cada lado
+	safeA, safeB := safe, safe
+	if area := zone.shapeArea(); area != nil {
+		midLat, midLon := s.unprojectFromMeters(latRef, midX, midY)
+		mid := zonas_risco.Point{Lat: midLat, Lng: midLon}
+		safeA = math.Max(area.Extent(mid, nx, ny), 0) + buffer
+		safeB = math.Max(area.Extent(mid, -nx, -ny), 0) + buffer
+	}
+	ax2 := midX + nx*safeA
+	ay2 := midY + ny*safeA
+	bx2 := midX - nx*safeB
+	by2 := midY - ny*safeB
 
 	// volta pra lat/lon
 	aLatOff, aLonOff := s.unprojectFromMeters(latRef, ax2, ay2)
@@ -5202,18 +4972,12 @@ func (s *Service) computeBypassWaypoints(originLat, originLon, destLat, destLon
 // computeBypassFromRouteGeometry tenta pegar pontos de tangência usando a polyline real da rota
 func (s *Service) computeBypassFromRouteGeometry(originLat, originLon, destLat, destLon float64, zone RiskZone) (Location, Location, bool) {
 	// Consulta uma rota OSRM simples entre origem e destino
-	coords := fmt.Sprintf("%f,%f;%f,%f", originLon, originLat, destLon, destLat)
-	osrmURL := fmt.Sprintf("http://34.207.174.233:5000/route/v1/driving/%s?alternatives=0&steps=true&overview=full", neturl.PathEscape(coords))
-	client := http.Client{Timeout: 15 * time.Second}
-	resp, err := client.Get(osrmURL)
+	osrmResp, err := s.engineRoute(context.Background(), 15*time.Second, RouteRequest{
+		Coordinates: []Location{{Latitude: originLat, Longitude: originLon}, {Latitude: destLat, Longitude: destLon}},
+	})
 	if err != nil {
 		return Location{}, Location{}, false
 	}
-	defer resp.Body.Close()
-	var osrmResp OSRMResponse
-	if err := json.NewDecoder(resp.Body).Decode(&osrmResp); err != nil || len(osrmResp.Routes) == 0 {
-		return Location{}, Location{}, false
-	}
 	// Decodificar polyline e encontrar primeiro e último pontos onde a rota toca a borda da zona (entrada e saída)
 	points, err := s.decodePolylineOSRM(osrmResp.Routes[0].Geometry)
 	if err != nil || len(points) < 3 {
@@ -5221,7 +4985,7 @@ func (s *Service) computeBypassFromRouteGeometry(originLat, originLon, destLat,
 	}
 	// Varre pontos para encontrar o primeiro que entra no círculo e o primeiro que sai
 	inCircle := func(p Location) bool {
-		return s.haversineDistance(p.Latitude, p.Longitude, zone.Lat, zone.Lng) <= float64(zone.Radius)
+		return s.isPointInRiskZone(p.Latitude, p.Longitude, zone)
 	}
 	var idxEnter, idxExit int = -1, -1
 	wasIn := false
@@ -5241,9 +5005,9 @@ func (s *Service) computeBypassFromRouteGeometry(originLat, originLon, destLat,
 	if idxEnter >= 0 && idxExit > idxEnter {
 		wpA := points[idxEnter]
 		wpB := points[idxExit]
-		// Empurra levemente para fora do círculo (50m) para assegurar que o roteador contorne
-		wpA = s.pushAwayFromCenter(wpA, zone, 50)
-		wpB = s.pushAwayFromCenter(wpB, zone, 50)
+		// Empurra levemente para fora da zona (50m) para assegurar que o roteador contorne
+		wpA = s.pushToOutside(wpA, zone, 50)
+		wpB = s.pushToOutside(wpB, zone, 50)
 		return wpA, wpB, true
 	}
 	return Location{}, Location{}, false
@@ -5304,11 +5068,9 @@ func (s *Service) checkRouteForRiskZonesFallback(riskZones []RiskZone, originLat
 
 // Checa rota OSRM real e retorna TODAS as zonas de atenção cruzadas (ordenadas). Bool indica se há pelo menos uma.
 func (s *Service) CheckRouteForAllAttentionZones(attentionZones []RiskZone, originLat, originLon, destLat, destLon float64) ([]RiskOffsets, bool) {
-	client := http.Client{Timeout: 15 * time.Second}
-	coords := fmt.Sprintf("%f,%f;%f,%f", originLon, originLat, destLon, destLat)
-	url := fmt.Sprintf("http://34.207.174.233:5000/route/v1/driving/%s?overview=full&steps=true", neturl.PathEscape(coords))
-
-	resp, err := client.Get(url)
+	osrmResp, err := s.engineRoute(context.Background(), 15*time.Second, RouteRequest{
+		Coordinates: []Location{{Latitude: originLat, Longitude: originLon}, {Latitude: destLat, Longitude: destLon}},
+	})
 	if err != nil {
 		// fallback: mantém seu comportamento antigo (linha reta), mas sem offsets detalhados
 		if s.checkRouteForAttentionZonesFallback(attentionZones, originLat, originLon, destLat, destLon) {
@@ -5317,26 +5079,6 @@ func (s *Service) CheckRouteForAllAttentionZones(attentionZones []RiskZone, orig
 		}
 		return nil, false
 	}
-	defer resp.Body.Close()
-
-	var osrmResp OSRMResponse
-	if err := json.NewDecoder(resp.Body).Decode(&osrmResp); err != nil {
-		// fallback: mantém seu comportamento antigo (linha reta), mas sem offsets detalhados
-		if s.checkRouteForAttentionZonesFallback(attentionZones, originLat, originLon, destLat, destLon) {
-			// sem offsets nesse fallback
-			return nil, true
-		}
-		return nil, false
-	}
-
-	if len(osrmResp.Routes) == 0 {
-		// fallback: mantém seu comportamento antigo (linha reta), mas sem offsets detalhados
-		if s.checkRouteForAttentionZonesFallback(attentionZones, originLat, originLon, destLat, destLon) {
-			// sem offsets nesse fallback
-			return nil, true
-		}
-		return nil, false
-	}
 
 	route := osrmResp.Routes[0]
 
@@ -5493,6 +5235,10 @@ func (s *Service) calculatePointAfterDistance(lat, lon, destLat, destLon float64
 
 // calculateEntryPoint calcula o ponto de entrada em uma zona
 func (s *Service) calculateEntryPoint(point1, point2 Location, zone RiskZone) Location {
+	if crossings := s.zoneCrossings(point1, point2, zone); len(crossings) > 0 {
+		return interpolateLocation(point1, point2, crossings[0])
+	}
+
 	// Calcular o ponto médio entre os dois pontos
 	midLat := (point1.Latitude + point2.Latitude) / 2
 	midLon := (point1.Longitude + point2.Longitude) / 2
@@ -5508,6 +5254,10 @@ func (s *Service) calculateEntryPoint(point1, point2 Location, zone RiskZone) Lo
 
 // calculateExitPoint calcula o ponto de saída de uma zona
 func (s *Service) calculateExitPoint(point1, point2 Location, zone RiskZone) Location {
+	if crossings := s.zoneCrossings(point1, point2, zone); len(crossings) > 0 {
+		return interpolateLocation(point1, point2, crossings[len(crossings)-1])
+	}
+
 	// Calcular o ponto médio entre os dois pontos
 	midLat := (point1.Latitude + point2.Latitude) / 2
 	midLon := (point1.Longitude + point2.Longitude) / 2
@@ -5521,6 +5271,25 @@ func (s *Service) calculateExitPoint(point1, point2 Location, zone RiskZone) Loc
 	return Location{Latitude: exitLat, Longitude: exitLon}
 }
 
+// zoneCrossings devolve os pontos (t em [0,1]) em que o segmento cruza a borda de uma zona em polígono ou corredor
+func (s *Service) zoneCrossings(point1, point2 Location, zone RiskZone) []float64 {
+	area := zone.shapeArea()
+	if area == nil {
+		return nil
+	}
+	return area.Crossings(
+		zonas_risco.Point{Lat: point1.Latitude, Lng: point1.Longitude},
+		zonas_risco.Point{Lat: point2.Latitude, Lng: point2.Longitude},
+	)
+}
+
+func interpolateLocation(a, b Location, t float64) Location {
+	return Location{
+		Latitude:  a.Latitude + (b.Latitude-a.Latitude)*t,
+		Longitude: a.Longitude + (b.Longitude-a.Longitude)*t,
+	}
+}
+
 // calculateBearing calcula o rumo entre dois pontos
 func (s *Service) calculateBearing(lat1, lon1, lat2, lon2 float64) float64 {
 	lat1Rad := lat1 * math.Pi / 180
@@ -5684,7 +5453,7 @@ func (s *Service) normalizeString(str string) string {
 }
 
 // createAttentionZoneInfo cria informações sobre zonas de atenção encontradas na rota
-func (s *Service) createAttentionZoneInfo(attentionOffs []RiskOffsets, originLat, originLon, destLat, destLon float64) AttentionZoneInfo {
+func (s *Service) createAttentionZoneInfo(attentionOffs []RiskOffsets, originLat, originLon, destLat, destLon float64, timeline RouteTimeline) AttentionZoneInfo {
 	if len(attentionOffs) == 0 {
 		return AttentionZoneInfo{
 			HasAttentionZones: false,
@@ -5726,16 +5495,21 @@ func (s *Service) createAttentionZoneInfo(attentionOffs []RiskOffsets, originLat
 			streetName = offset.Zone.Name
 		}
 
+		entryArrival := timeline.arrivalAtDistance(offset.EntryCum)
+		exitArrival := timeline.arrivalAtDistance(offset.ExitCum)
+
 		// Criar evento de entrada
 		entryEvent := AttentionZoneEvent{
 			Type:          "entry",
 			ZoneName:      offset.Zone.Name,
 			ZoneID:        offset.Zone.ID,
+			Severity:      offset.Zone.Severity,
 			Distance:      offset.EntryCum,
 			Coordinates:   offset.Entry,
 			Message:       fmt.Sprintf("Entrando na zona de atenção: %s", offset.Zone.Name),
 			DetectionType: detectionType,
 			StreetName:    streetName,
+			Arrival:       &entryArrival,
 		}
 		events = append(events, entryEvent)
 
@@ -5744,11 +5518,13 @@ func (s *Service) createAttentionZoneInfo(attentionOffs []RiskOffsets, originLat
 			Type:          "exit",
 			ZoneName:      offset.Zone.Name,
 			ZoneID:        offset.Zone.ID,
+			Severity:      offset.Zone.Severity,
 			Distance:      offset.ExitCum,
 			Coordinates:   offset.Exit,
 			Message:       fmt.Sprintf("Saindo da zona de atenção: %s", offset.Zone.Name),
 			DetectionType: detectionType,
 			StreetName:    streetName,
+			Arrival:       &exitArrival,
 		}
 		events = append(events, exitEvent)
 	}
@@ -5833,13 +5609,12 @@ func (s *Service) decodePolylineOSRM(encoded string) ([]Location, error) {
 }
 
 // createRouteSummary cria um resumo de rota
-func (s *Service) createRouteSummary(route OSRMRoute, routeType string, originGeocode, destGeocode GeocodeResult, data FrontInfoCEPRequest, tolls []Toll) RouteSummary {
+func (s *Service) createRouteSummary(ctx context.Context, route OSRMRoute, routeType string, originGeocode, destGeocode GeocodeResult, data FrontInfoCEPRequest, tolls []Toll) RouteSummary {
 	distText, distVal := formatDistance(route.Distance)
 	durText, durVal := formatDuration(route.Duration)
 
-	avgConsumption := (data.ConsumptionCity + data.ConsumptionHwy) / 2
-	totalKm := route.Distance / 1000
-	totalFuelCost := math.Round((data.Price / avgConsumption) * totalKm)
+	fuelSplit := s.routeFuelSplit(ctx, route, data.Type, data.Price, data.ConsumptionCity, data.ConsumptionHwy)
+	totalFuelCost := fuelSplit.TotalCost()
 
 	googleURL := fmt.Sprintf("https://www.google.com/maps/dir/?api=1&origin=%s&destination=%s",
 		neturl.QueryEscape(normalizeAddress(originGeocode.FormattedAddress)),
@@ -5855,21 +5630,25 @@ func (s *Service) createRouteSummary(route OSRMRoute, routeType string, originGe
 
 	var totalTollCost float64
 	for _, toll := range tolls {
-		totalTollCost += toll.CashCost
+		totalTollCost += toll.PaidCost
 	}
 
 	summary := RouteSummary{
-		RouteType:     routeType,
-		HasTolls:      len(tolls) > 0,
-		Distance:      Distance{Text: distText, Value: distVal},
-		Duration:      Duration{Text: durText, Value: durVal},
-		URL:           googleURL,
-		URLWaze:       wazeURL,
-		TotalFuelCost: totalFuelCost,
-		Tolls:         tolls,
-		TotalTolls:    math.Round(totalTollCost*100) / 100,
-		Polyline:      route.Geometry,
-		Instructions:  s.processOSRMStepsToInstructions(route),
+		RouteType:       routeType,
+		HasTolls:        len(tolls) > 0,
+		Distance:        Distance{Text: distText, Value: distVal},
+		Duration:        Duration{Text: durText, Value: durVal},
+		URL:             googleURL,
+		URLWaze:         wazeURL,
+		TotalFuelCost:   totalFuelCost,
+		FuelSplit:       &fuelSplit,
+		Emissions:       routeEmissions(fuelSplit, data.Type, data.Axles, data.VehicleInfo),
+		Tolls:           tolls,
+		TotalTolls:      math.Round(totalTollCost*100) / 100,
+		Polyline:        route.Geometry,
+		Instructions:    s.processOSRMStepsToInstructions(route, data.Locale),
+		Restrictions:    s.routeRestrictions(context.Background(), route.Geometry, data.VehicleInfo),
+		DriverItinerary: s.driverItinerary(context.Background(), route, data.DriverRules, data.DepartureTime),
 	}
 
 	// 🔹 Tenta atualizar a duração com o Google Directions API
@@ -5943,7 +5722,7 @@ func (s *Service) createRouteSummaryWithRiskWarning(originLat, originLon, destLa
 }
 
 // calculateTotalRouteWithAvoidance calcula rota total com desvios
-func (s *Service) calculateTotalRouteWithAvoidance(ctx context.Context, client http.Client, riskZones []RiskZone, attentionZones []RiskZone, ceps []string, totalDistance, totalDuration float64, data FrontInfoCEPRequest) TotalSummary {
+func (s *Service) calculateTotalRouteWithAvoidance(ctx context.Context, riskZones []RiskZone, attentionZones []RiskZone, ceps []string, totalDistance, totalDuration float64, data FrontInfoCEPRequest) TotalSummary {
 
 	// ------------------------------
 	// 1) Monta lista base de coords e endereços
@@ -6006,33 +5785,11 @@ func (s *Service) calculateTotalRouteWithAvoidance(ctx context.Context, client h
 
 		// helper para calcular a polyline do segmento COM os via-points atuais
 		routeForSegment := func() (OSRMRoute, bool) {
-			coords := fmt.Sprintf("%f,%f", lon1, lat1)
-			for _, wp := range segWps {
-				coords += fmt.Sprintf(";%f,%f", wp.Longitude, wp.Latitude)
-			}
-			coords += fmt.Sprintf(";%f,%f", lon2, lat2)
-
-			profile := "driving"
-			if data.Type != "" {
-				profile = data.Type // ex: "car", "truck"
-			}
-
-			u := fmt.Sprintf("http://34.207.174.233:5000/route/v1/%s/%s?alternatives=0&steps=true&overview=full&continue_straight=false",
-				profile, neturl.PathEscape(coords))
-
-			// Cliente com timeout reduzido para melhor performance
-			fastClient := http.Client{Timeout: 15 * time.Second}
-			resp, err := fastClient.Get(u)
-			if err != nil || resp.StatusCode != 200 {
-				u = fmt.Sprintf("http://34.207.174.233:5000/route/v1/driving/%s?alternatives=0&steps=true&overview=full&continue_straight=false",
-					neturl.PathEscape(coords))
-				resp, err = fastClient.Get(u)
-			}
-
-			defer resp.Body.Close()
-			var r OSRMResponse
-			if json.NewDecoder(resp.Body).Decode(&r) != nil || len(r.Routes) == 0 {
+			coords := append([]Location{{Latitude: lat1, Longitude: lon1}}, segWps...)
+			coords = append(coords, Location{Latitude: lat2, Longitude: lon2})
 
+			r, err := s.engineRoute(ctx, 15*time.Second, RouteRequest{Coordinates: coords, Profile: data.Type, AllowUTurn: true})
+			if err != nil {
 				return OSRMRoute{}, false
 			}
 			return r.Routes[0], true
@@ -6056,11 +5813,15 @@ func (s *Service) calculateTotalRouteWithAvoidance(ctx context.Context, client h
 				injected := false
 
 				if nx != 0 || ny != 0 {
-					baseDist := float64(off.Zone.Radius) + 200.0 // raio + buffer
 					mid := Location{
 						Latitude:  (off.Entry.Latitude + off.Exit.Latitude) / 2,
 						Longitude: (off.Entry.Longitude + off.Exit.Longitude) / 2,
 					}
+					baseDist := float64(off.Zone.Radius) + 200.0 // raio + buffer
+					if area := off.Zone.shapeArea(); area != nil {
+						// nas formas, até a borda do lado do desvio (o círculo envolvente de um corredor é enorme)
+						baseDist = math.Max(area.Extent(zonas_risco.Point{Lat: mid.Latitude, Lng: mid.Longitude}, nx, ny), 0) + 200.0
+					}
 					buildSeq := func(scale float64) []Location {
 						d := baseDist * scale
 						p1 := s.offsetByNormal(off.Before5km, latRef, nx, ny, d)
@@ -6072,35 +5833,16 @@ func (s *Service) calculateTotalRouteWithAvoidance(ctx context.Context, client h
 					for _, sc := range []float64{1.0, 1.3, 1.6, 2.0} {
 						cand := buildSeq(sc)
 						// Snap GARANTINDO ficar fora da zona
-						cand = s.snapOutsideMany(client, cand, off.Zone)
+						cand = s.snapOutsideMany(cand, off.Zone, vehicleProfile(data.Type))
 
 						// Testa rota do segmento com segWps + cand
 						segTest := append(append([]Location{}, segWps...), cand...)
 						if r2, ok2 := func() (OSRMRoute, bool) {
-							coords := fmt.Sprintf("%f,%f", lon1, lat1)
-							for _, wp := range segTest {
-								coords += fmt.Sprintf(";%f,%f", wp.Longitude, wp.Latitude)
-							}
-							coords += fmt.Sprintf(";%f,%f", lon2, lat2)
-
-							profile := "driving"
-							if data.Type != "" {
-								profile = data.Type // ex: "car", "truck"
-							}
-
-							u := fmt.Sprintf("http://34.207.174.233:5000/route/v1/%s/%s?alternatives=0&steps=true&overview=full&continue_straight=false",
-								profile, neturl.PathEscape(coords))
-
-							resp, err := client.Get(u)
-							if err != nil || resp.StatusCode != 200 {
-								u = fmt.Sprintf("http://34.207.174.233:5000/route/v1/driving/%s?alternatives=0&steps=true&overview=full&continue_straight=false",
-									neturl.PathEscape(coords))
-								resp, err = client.Get(u)
-							}
+							coords := append([]Location{{Latitude: lat1, Longitude: lon1}}, segTest...)
+							coords = append(coords, Location{Latitude: lat2, Longitude: lon2})
 
-							defer resp.Body.Close()
-							var r OSRMResponse
-							if json.NewDecoder(resp.Body).Decode(&r) != nil || len(r.Routes) == 0 {
+							r, err := s.engineRoute(ctx, 15*time.Second, RouteRequest{Coordinates: coords, Profile: data.Type, AllowUTurn: true})
+							if err != nil {
 								return OSRMRoute{}, false
 							}
 							return r.Routes[0], true
@@ -6123,34 +5865,15 @@ func (s *Service) calculateTotalRouteWithAvoidance(ctx context.Context, client h
 				if !injected {
 					// menor arco
 					seq := s.assembleLateralDetour(off.Entry, off.Exit, off.Zone, 2, 200, 80, false)
-					seq = s.snapOutsideMany(client, seq, off.Zone)
+					seq = s.snapOutsideMany(seq, off.Zone, vehicleProfile(data.Type))
 					tryInject := func(cand []Location) bool {
 						test := append(append([]Location{}, segWps...), cand...)
 						if r2, ok2 := func() (OSRMRoute, bool) {
-							coords := fmt.Sprintf("%f,%f", lon1, lat1)
-							for _, wp := range test {
-								coords += fmt.Sprintf(";%f,%f", wp.Longitude, wp.Latitude)
-							}
-							coords += fmt.Sprintf(";%f,%f", lon2, lat2)
-
-							profile := "driving"
-							if data.Type != "" {
-								profile = data.Type // ex: "car", "truck"
-							}
+							coords := append([]Location{{Latitude: lat1, Longitude: lon1}}, test...)
+							coords = append(coords, Location{Latitude: lat2, Longitude: lon2})
 
-							u := fmt.Sprintf("http://34.207.174.233:5000/route/v1/%s/%s?alternatives=0&steps=true&overview=full&continue_straight=false",
-								profile, neturl.PathEscape(coords))
-
-							resp, err := client.Get(u)
-							if err != nil || resp.StatusCode != 200 {
-								u = fmt.Sprintf("http://34.207.174.233:5000/route/v1/driving/%s?alternatives=0&steps=true&overview=full&continue_straight=false",
-									neturl.PathEscape(coords))
-								resp, err = client.Get(u)
-							}
-
-							defer resp.Body.Close()
-							var r OSRMResponse
-							if json.NewDecoder(resp.Body).Decode(&r) != nil || len(r.Routes) == 0 {
+							r, err := s.engineRoute(ctx, 15*time.Second, RouteRequest{Coordinates: coords, Profile: data.Type, AllowUTurn: true})
+							if err != nil {
 								return OSRMRoute{}, false
 							}
 							return r.Routes[0], true
@@ -6170,17 +5893,17 @@ func (s *Service) calculateTotalRouteWithAvoidance(ctx context.Context, client h
 					if !tryInject(seq) {
 						// arco oposto
 						seq2 := s.assembleLateralDetour(off.Entry, off.Exit, off.Zone, 2, 200, 80, true)
-						seq2 = s.snapOutsideMany(client, seq2, off.Zone)
+						seq2 = s.snapOutsideMany(seq2, off.Zone, vehicleProfile(data.Type))
 						if !tryInject(seq2) {
 							// A/B padrão
 							wpA, wpB := s.computeBypassWaypoints(lat1, lon1, lat2, lon2, off.Zone)
-							ab := s.snapOutsideMany(client, []Location{wpA, wpB}, off.Zone)
+							ab := s.snapOutsideMany([]Location{wpA, wpB}, off.Zone, vehicleProfile(data.Type))
 							if !tryInject(ab) {
 								// A/B escalado
 								for _, sc := range []float64{1.5, 2.0, 3.0} {
-									wpA2 := s.pushAwayFromCenter(wpA, off.Zone, float64(off.Zone.Radius)*(sc-1)+500)
-									wpB2 := s.pushAwayFromCenter(wpB, off.Zone, float64(off.Zone.Radius)*(sc-1)+500)
-									ab2 := s.snapOutsideMany(client, []Location{wpA2, wpB2}, off.Zone)
+									wpA2 := s.scaledBypassPoint(wpA, off.Zone, sc)
+									wpB2 := s.scaledBypassPoint(wpB, off.Zone, sc)
+									ab2 := s.snapOutsideMany([]Location{wpA2, wpB2}, off.Zone, vehicleProfile(data.Type))
 									if tryInject(ab2) {
 										break
 									}
@@ -6213,170 +5936,149 @@ func (s *Service) calculateTotalRouteWithAvoidance(ctx context.Context, client h
 	var totalRoute TotalSummary
 
 	coordsStr := strings.Join(newCoords, ";")
-	urlTotal := fmt.Sprintf(
-		"http://34.207.174.233:5000/route/v1/driving/%s?alternatives=0&steps=true&overview=full&continue_straight=false",
-		neturl.PathEscape(coordsStr),
-	)
 
-	if resp, err := client.Get(urlTotal); err == nil {
-		defer resp.Body.Close()
-		var osrmResp OSRMResponse
-		if err := json.NewDecoder(resp.Body).Decode(&osrmResp); err == nil && len(osrmResp.Routes) > 0 {
-			route := osrmResp.Routes[0]
+	if osrmResp, err := s.engineRoute(ctx, 10*time.Second, RouteRequest{Coordinates: parseOSRMCoordinates(coordsStr), AllowUTurn: true}); err == nil {
+		route := osrmResp.Routes[0]
 
-			// ---------- GUARD SWEEP NA ROTA TOTAL (INÍCIO E CHEGADA) ----------
-			startWindow := 2200.0 // m
-			endWindow := 2200.0   // m
-
-			// helpers para injetar/remover
-			injectFront := func(seq []Location) { // imediatamente após a origem
-				if len(newCoords) >= 1 {
-					head := newCoords[0]
-					tail := append([]string{}, newCoords[1:]...)
-					newCoords = []string{head}
-					for _, p := range seq {
-						newCoords = append(newCoords, fmt.Sprintf("%f,%f", p.Longitude, p.Latitude))
-					}
-					newCoords = append(newCoords, tail...)
+		// ---------- GUARD SWEEP NA ROTA TOTAL (INÍCIO E CHEGADA) ----------
+		startWindow := 2200.0 // m
+		endWindow := 2200.0   // m
+
+		// helpers para injetar/remover
+		injectFront := func(seq []Location) { // imediatamente após a origem
+			if len(newCoords) >= 1 {
+				head := newCoords[0]
+				tail := append([]string{}, newCoords[1:]...)
+				newCoords = []string{head}
+				for _, p := range seq {
+					newCoords = append(newCoords, fmt.Sprintf("%f,%f", p.Longitude, p.Latitude))
 				}
+				newCoords = append(newCoords, tail...)
 			}
-			removeFront := func(n int) {
-				if len(newCoords) >= 1+n {
-					head := newCoords[0]
-					newCoords = append([]string{head}, newCoords[1+n:]...)
-				}
+		}
+		removeFront := func(n int) {
+			if len(newCoords) >= 1+n {
+				head := newCoords[0]
+				newCoords = append([]string{head}, newCoords[1+n:]...)
 			}
-			injectBeforeDest := func(seq []Location) { // imediatamente antes do destino
-				if len(newCoords) >= 2 {
-					destTail := newCoords[len(newCoords)-1]
-					newCoords = newCoords[:len(newCoords)-1]
-					for _, p := range seq {
-						newCoords = append(newCoords, fmt.Sprintf("%f,%f", p.Longitude, p.Latitude))
-					}
-					newCoords = append(newCoords, destTail)
+		}
+		injectBeforeDest := func(seq []Location) { // imediatamente antes do destino
+			if len(newCoords) >= 2 {
+				destTail := newCoords[len(newCoords)-1]
+				newCoords = newCoords[:len(newCoords)-1]
+				for _, p := range seq {
+					newCoords = append(newCoords, fmt.Sprintf("%f,%f", p.Longitude, p.Latitude))
 				}
+				newCoords = append(newCoords, destTail)
 			}
-			removeBeforeDest := func(n int) {
-				if len(newCoords) >= 1+n {
-					destTail := newCoords[len(newCoords)-1]
-					newCoords = append(newCoords[:len(newCoords)-1-n], destTail)
-				}
+		}
+		removeBeforeDest := func(n int) {
+			if len(newCoords) >= 1+n {
+				destTail := newCoords[len(newCoords)-1]
+				newCoords = append(newCoords[:len(newCoords)-1-n], destTail)
 			}
+		}
 
-			// 1) Departure guard sweep
-			for range []int{0} {
-				cross := s.detectAllCrossingsFromGeometry(route.Geometry, riskZones, 1000)
-				if len(cross) == 0 || cross[0].EntryCum > startWindow {
-					break
-				}
-				first := cross[0]
-				origin := originLocation
-				for b := 80.0; b <= 500.0; b += 10.0 {
-					guard := s.computeArrivalGuardPoint(first.Zone, origin, b)
-					guard = s.snapOutsideMany(client, []Location{guard}, first.Zone)[0]
-					injectFront([]Location{guard})
-
-					coordsStr = strings.Join(newCoords, ";")
-					urlTotal = fmt.Sprintf("http://34.207.174.233:5000/route/v1/driving/%s?alternatives=0&steps=true&overview=full&continue_straight=false", neturl.PathEscape(coordsStr))
-					if resp2, err2 := client.Get(urlTotal); err2 == nil {
-						defer resp2.Body.Close()
-						var osrm2 OSRMResponse
-						if json.NewDecoder(resp2.Body).Decode(&osrm2) == nil && len(osrm2.Routes) > 0 {
-							route = osrm2.Routes[0]
-							if has, _ := s.checkRouteGeometryForRiskZones(riskZones, route.Geometry, originLocation.Latitude, originLocation.Longitude, destinationLocation.Latitude, destinationLocation.Longitude); !has {
-								break
-							}
-						}
-					}
-					removeFront(1)
-				}
-
+		// 1) Departure guard sweep
+		for range []int{0} {
+			cross := s.detectAllCrossingsFromGeometry(route.Geometry, riskZones, 1000)
+			if len(cross) == 0 || cross[0].EntryCum > startWindow {
+				break
 			}
+			first := cross[0]
+			origin := originLocation
+			for b := 80.0; b <= 500.0; b += 10.0 {
+				guard := s.computeArrivalGuardPoint(first.Zone, origin, b)
+				guard = s.snapOutsideMany([]Location{guard}, first.Zone, vehicleProfile(data.Type))[0]
+				injectFront([]Location{guard})
 
-			// 2) Arrival guard sweep
-			for range []int{0} {
-				cross := s.detectAllCrossingsFromGeometry(route.Geometry, riskZones, 1000)
-				if len(cross) == 0 {
-					break
-				}
-				last := cross[len(cross)-1]
-				if route.Distance-last.EntryCum > endWindow {
-					break // últimos cruzamentos estão longe do destino
-				}
-				for b := 80.0; b <= 500.0; b += 10.0 {
-					guard := s.computeArrivalGuardPoint(last.Zone, destinationLocation, b)
-					guard = s.snapOutsideMany(client, []Location{guard}, last.Zone)[0]
-					injectBeforeDest([]Location{guard})
-
-					coordsStr = strings.Join(newCoords, ";")
-					urlTotal = fmt.Sprintf("http://34.207.174.233:5000/route/v1/driving/%s?alternatives=0&steps=true&overview=full&continue_straight=false", neturl.PathEscape(coordsStr))
-					if resp2, err2 := client.Get(urlTotal); err2 == nil {
-						defer resp2.Body.Close()
-						var osrm2 OSRMResponse
-						if json.NewDecoder(resp2.Body).Decode(&osrm2) == nil && len(osrm2.Routes) > 0 {
-							route = osrm2.Routes[0]
-							if has, _ := s.checkRouteGeometryForRiskZones(riskZones, route.Geometry, originLocation.Latitude, originLocation.Longitude, destinationLocation.Latitude, destinationLocation.Longitude); !has {
-								break
-							}
-						}
+				coordsStr = strings.Join(newCoords, ";")
+				if osrm2, err2 := s.engineRoute(ctx, 10*time.Second, RouteRequest{Coordinates: parseOSRMCoordinates(coordsStr), AllowUTurn: true}); err2 == nil {
+					route = osrm2.Routes[0]
+					if has, _ := s.checkRouteGeometryForRiskZones(riskZones, route.Geometry, originLocation.Latitude, originLocation.Longitude, destinationLocation.Latitude, destinationLocation.Longitude); !has {
+						break
 					}
-					removeBeforeDest(1)
 				}
-
+				removeFront(1)
 			}
-			// ---------- fim do guard sweep ----------
 
-			// Monta waypoints p/ URL do Google (endereços originais + via:lat,lng dos desvios)
-			waypointsForURL := make([]string, 0, len(waypoints)+len(extraWaypointsForURL))
-			if len(waypoints) > 2 {
-				// Insere "via:" antes dos pontos intermediários de endereço
-				waypointsForURL = append(waypointsForURL, extraWaypointsForURL...)
-				waypointsForURL = append(waypointsForURL, waypoints[1:len(waypoints)-1]...)
-			} else {
-				waypointsForURL = append(waypointsForURL, extraWaypointsForURL...)
-			}
+		}
 
-			if len(waypointsForURL) == 0 {
-				waypointsForURL = []string{
-					fmt.Sprintf("%f,%f", originLocation.Latitude, originLocation.Longitude),
-					fmt.Sprintf("%f,%f", destinationLocation.Latitude, destinationLocation.Longitude),
+		// 2) Arrival guard sweep
+		for range []int{0} {
+			cross := s.detectAllCrossingsFromGeometry(route.Geometry, riskZones, 1000)
+			if len(cross) == 0 {
+				break
+			}
+			last := cross[len(cross)-1]
+			if route.Distance-last.EntryCum > endWindow {
+				break // últimos cruzamentos estão longe do destino
+			}
+			for b := 80.0; b <= 500.0; b += 10.0 {
+				guard := s.computeArrivalGuardPoint(last.Zone, destinationLocation, b)
+				guard = s.snapOutsideMany([]Location{guard}, last.Zone, vehicleProfile(data.Type))[0]
+				injectBeforeDest([]Location{guard})
+
+				coordsStr = strings.Join(newCoords, ";")
+				if osrm2, err2 := s.engineRoute(ctx, 10*time.Second, RouteRequest{Coordinates: parseOSRMCoordinates(coordsStr), AllowUTurn: true}); err2 == nil {
+					route = osrm2.Routes[0]
+					if has, _ := s.checkRouteGeometryForRiskZones(riskZones, route.Geometry, originLocation.Latitude, originLocation.Longitude, destinationLocation.Latitude, destinationLocation.Longitude); !has {
+						break
+					}
 				}
+				removeBeforeDest(1)
 			}
-			totalRoute = s.createTotalSummaryWithURLWaypoints(route, originLocation, destinationLocation, waypoints, waypointsForURL, data)
 
-			// Usa os valores da rota OSRM (que são mais precisos para a rota total)
-			// Se a rota OSRM retornar valores válidos, usa eles; senão usa os acumulados como fallback
-			if route.Distance > 0 && route.Duration > 0 {
-				// Usa valores da rota OSRM (mais precisos)
-				distText, distVal := formatDistance(route.Distance)
-				durText, durVal := formatDuration(route.Duration)
-				totalRoute.TotalDistance = Distance{Text: distText, Value: distVal}
-				totalRoute.TotalDuration = Duration{Text: durText, Value: durVal}
+		}
+		// ---------- fim do guard sweep ----------
 
-				// Recalcula custo de combustível com a distância correta
-				avgConsumption := (data.ConsumptionCity + data.ConsumptionHwy) / 2
-				totalKm := route.Distance / 1000
-				totalRoute.TotalFuelCost = math.Round((data.Price / avgConsumption) * totalKm)
-			} else {
-				// Fallback para valores acumulados se OSRM retornar valores inválidos
-				distText, distVal := formatDistance(totalDistance)
-				durText, durVal := formatDuration(totalDuration)
-				totalRoute.TotalDistance = Distance{Text: distText, Value: distVal}
-				totalRoute.TotalDuration = Duration{Text: durText, Value: durVal}
+		// Monta waypoints p/ URL do Google (endereços originais + via:lat,lng dos desvios)
+		waypointsForURL := make([]string, 0, len(waypoints)+len(extraWaypointsForURL))
+		if len(waypoints) > 2 {
+			// Insere "via:" antes dos pontos intermediários de endereço
+			waypointsForURL = append(waypointsForURL, extraWaypointsForURL...)
+			waypointsForURL = append(waypointsForURL, waypoints[1:len(waypoints)-1]...)
+		} else {
+			waypointsForURL = append(waypointsForURL, extraWaypointsForURL...)
+		}
 
-				// Recalcula custo de combustível com a distância correta
-				avgConsumption := (data.ConsumptionCity + data.ConsumptionHwy) / 2
-				totalKm := totalDistance / 1000
-				totalRoute.TotalFuelCost = math.Round((data.Price / avgConsumption) * totalKm)
+		if len(waypointsForURL) == 0 {
+			waypointsForURL = []string{
+				fmt.Sprintf("%f,%f", originLocation.Latitude, originLocation.Longitude),
+				fmt.Sprintf("%f,%f", destinationLocation.Latitude, destinationLocation.Longitude),
 			}
+		}
+		totalRoute = s.createTotalSummaryWithURLWaypoints(ctx, route, originLocation, destinationLocation, waypoints, waypointsForURL, data)
 
-			// Caso precise expor os pontos do desvio, habilite:
-			// totalRoute.DetourPoints = detourPtsTotal
-
+		// Usa os valores da rota OSRM (que são mais precisos para a rota total)
+		// Se a rota OSRM retornar valores válidos, usa eles; senão usa os acumulados como fallback
+		if route.Distance > 0 && route.Duration > 0 {
+			// Usa valores da rota OSRM (mais precisos)
+			distText, distVal := formatDistance(route.Distance)
+			durText, durVal := formatDuration(route.Duration)
+			totalRoute.TotalDistance = Distance{Text: distText, Value: distVal}
+			totalRoute.TotalDuration = Duration{Text: durText, Value: durVal}
+
+			// Recalcula custo de combustível com a distância correta, separando cidade e rodovia
+			fuelSplit := s.routeFuelSplit(ctx, route, data.Type, data.Price, data.ConsumptionCity, data.ConsumptionHwy)
+			totalRoute.TotalFuelCost = fuelSplit.TotalCost()
+			totalRoute.FuelSplit = &fuelSplit
+			totalRoute.Emissions = routeEmissions(fuelSplit, data.Type, data.Axles, data.VehicleInfo)
 		} else {
+			// Fallback para valores acumulados se OSRM retornar valores inválidos
+			distText, distVal := formatDistance(totalDistance)
+			durText, durVal := formatDuration(totalDuration)
+			totalRoute.TotalDistance = Distance{Text: distText, Value: distVal}
+			totalRoute.TotalDuration = Duration{Text: durText, Value: durVal}
 
+			// Recalcula custo de combustível com a distância correta
+			avgConsumption := (data.ConsumptionCity + data.ConsumptionHwy) / 2
+			totalKm := totalDistance / 1000
+			totalRoute.TotalFuelCost = math.Round((data.Price / avgConsumption) * totalKm)
 		}
-	} else {
+
+		// Caso precise expor os pontos do desvio, habilite:
+		// totalRoute.DetourPoints = detourPtsTotal
 
 	}
 
@@ -6386,22 +6088,10 @@ func (s *Service) calculateTotalRouteWithAvoidance(ctx context.Context, client h
 	if totalRoute.TotalDistance.Value == 0 {
 		// tenta rota total padrão para obter polyline
 		baseCoords := strings.Join(allCoords, ";")
-		urlBase := fmt.Sprintf(
-			"http://34.207.174.233:5000/route/v1/driving/%s?alternatives=0&steps=true&overview=full&continue_straight=false",
-			neturl.PathEscape(baseCoords),
-		)
-
-		if resp, err := client.Get(urlBase); err == nil {
-			defer resp.Body.Close()
-			var osrmResp OSRMResponse
-			if err := json.NewDecoder(resp.Body).Decode(&osrmResp); err == nil && len(osrmResp.Routes) > 0 {
-				route := osrmResp.Routes[0]
-				totalRoute = s.createTotalSummary(route, originLocation, destinationLocation, waypoints, data)
-			} else {
-
-			}
-		} else {
 
+		if osrmResp, err := s.engineRoute(ctx, 10*time.Second, RouteRequest{Coordinates: parseOSRMCoordinates(baseCoords), AllowUTurn: true}); err == nil {
+			route := osrmResp.Routes[0]
+			totalRoute = s.createTotalSummary(ctx, route, originLocation, destinationLocation, waypoints, data)
 		}
 	}
 
@@ -6416,23 +6106,15 @@ func (s *Service) calculateTotalRouteWithAvoidance(ctx context.Context, client h
 
 		// Buscar pedágios para a rota base (sem desvios)
 		baseCoords := strings.Join(allCoords, ";")
-		urlBase := fmt.Sprintf(
-			"http://34.207.174.233:5000/route/v1/driving/%s?alternatives=0&steps=true&overview=full&continue_straight=false",
-			neturl.PathEscape(baseCoords),
-		)
 
 		var tolls []Toll
 		var totalTollCost float64
 		var osrmRoute OSRMRoute
-		if resp, err := client.Get(urlBase); err == nil {
-			defer resp.Body.Close()
-			var osrmResp OSRMResponse
-			if err := json.NewDecoder(resp.Body).Decode(&osrmResp); err == nil && len(osrmResp.Routes) > 0 {
-				osrmRoute = osrmResp.Routes[0]
-				tolls, _ = s.findTollsOnRoute(ctx, osrmRoute.Geometry, data.Type, float64(data.Axles))
-				for _, toll := range tolls {
-					totalTollCost += toll.CashCost
-				}
+		if osrmResp, err := s.engineRoute(ctx, 10*time.Second, RouteRequest{Coordinates: parseOSRMCoordinates(baseCoords), AllowUTurn: true}); err == nil {
+			osrmRoute = osrmResp.Routes[0]
+			tolls, _ = s.findTollsOnRoute(ctx, osrmRoute, newTollVehicle(data.Type, data.Axles, data.VehicleInfo), data.DepartureTime)
+			for _, toll := range tolls {
+				totalTollCost += toll.PaidCost
 			}
 		}
 
@@ -6474,7 +6156,7 @@ func (s *Service) calculateTotalRouteWithAvoidance(ctx context.Context, client h
 			TotalTolls:    math.Round(totalTollCost*100) / 100,
 			Polyline:      "",
 			TotalFuelCost: totalFuelCost,
-			Instructions:  s.processOSRMStepsToInstructions(osrmRoute),
+			Instructions:  s.processOSRMStepsToInstructions(osrmRoute, data.Locale),
 		}
 	}
 
@@ -6486,7 +6168,7 @@ func (s *Service) calculateTotalRouteWithAvoidance(ctx context.Context, client h
 		attentionOffs, hasAttention := s.CheckRouteForAllAttentionZones(attentionZones, originLocation.Latitude, originLocation.Longitude, destinationLocation.Latitude, destinationLocation.Longitude)
 
 		if hasAttention {
-			attentionInfo := s.createAttentionZoneInfo(attentionOffs, originLocation.Latitude, originLocation.Longitude, destinationLocation.Latitude, destinationLocation.Longitude)
+			attentionInfo := s.createAttentionZoneInfo(attentionOffs, originLocation.Latitude, originLocation.Longitude, destinationLocation.Latitude, destinationLocation.Longitude, newRouteTimeline(totalRoute.routeForTimeline(), 0, data.DepartureTime))
 			totalRoute.AttentionZones = &attentionInfo
 		}
 	}
@@ -6494,43 +6176,29 @@ func (s *Service) calculateTotalRouteWithAvoidance(ctx context.Context, client h
 	return totalRoute
 }
 
-type osrmNearestResp struct {
-	Waypoints []struct {
-		Location [2]float64 `json:"location"`
-	} `json:"waypoints"`
-}
-
-func (s *Service) snapToRoad(lat, lon float64) (float64, float64, bool) {
-	urlStr := fmt.Sprintf("http://34.207.174.233:5000/nearest/v1/driving/%f,%f?number=1", lon, lat)
-	client := http.Client{Timeout: 5 * time.Second}
+func (s *Service) snapToRoad(lat, lon float64, profile string) (float64, float64, bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
 
-	resp, err := client.Get(urlStr)
+	loc, err := s.Engine.Nearest(ctx, Location{Latitude: lat, Longitude: lon}, profile)
 	if err != nil {
 		return 0, 0, false
 	}
-	defer resp.Body.Close()
-
-	var nr osrmNearestResp
-	if err := json.NewDecoder(resp.Body).Decode(&nr); err != nil || len(nr.Waypoints) == 0 {
-		return 0, 0, false
-	}
-	loc := nr.Waypoints[0].Location
-	return loc[1], loc[0], true
+	return loc.Latitude, loc.Longitude, true
 }
 
 // cria um resumo total da rota
-func (s *Service) createTotalSummary(route OSRMRoute, originLocation, destinationLocation Location, waypoints []string, data FrontInfoCEPRequest) TotalSummary {
+func (s *Service) createTotalSummary(ctx context.Context, route OSRMRoute, originLocation, destinationLocation Location, waypoints []string, data FrontInfoCEPRequest) TotalSummary {
 	distText, distVal := formatDistance(route.Distance)
 	durText, durVal := formatDuration(route.Duration)
 
-	avgConsumption := (data.ConsumptionCity + data.ConsumptionHwy) / 2
-	totalKm := route.Distance / 1000
-	totalFuelCost := math.Round((data.Price / avgConsumption) * totalKm)
+	fuelSplit := s.routeFuelSplit(ctx, route, data.Type, data.Price, data.ConsumptionCity, data.ConsumptionHwy)
+	totalFuelCost := fuelSplit.TotalCost()
 
-	tolls, _ := s.findTollsOnRoute(context.Background(), route.Geometry, data.Type, float64(data.Axles))
+	tolls, _ := s.findTollsOnRoute(context.Background(), route, newTollVehicle(data.Type, data.Axles, data.VehicleInfo), data.DepartureTime)
 	var totalTollCost float64
 	for _, toll := range tolls {
-		totalTollCost += toll.CashCost
+		totalTollCost += toll.PaidCost
 	}
 
 	// fallback: se não vierem waypoints, usa coordenadas puras
@@ -6572,15 +6240,19 @@ func (s *Service) createTotalSummary(route OSRMRoute, originLocation, destinatio
 			Location: destinationLocation,
 			Address:  normalizeAddress(destAddress),
 		},
-		TotalDistance: Distance{Text: distText, Value: distVal},
-		TotalDuration: Duration{Text: durText, Value: durVal},
-		URL:           googleURL,
-		URLWaze:       wazeURL,
-		Tolls:         tolls,
-		TotalTolls:    math.Round(totalTollCost*100) / 100,
-		Polyline:      route.Geometry,
-		TotalFuelCost: totalFuelCost,
-		Instructions:  s.processOSRMStepsToInstructions(route),
+		TotalDistance:   Distance{Text: distText, Value: distVal},
+		TotalDuration:   Duration{Text: durText, Value: durVal},
+		URL:             googleURL,
+		URLWaze:         wazeURL,
+		Tolls:           tolls,
+		TotalTolls:      math.Round(totalTollCost*100) / 100,
+		Polyline:        route.Geometry,
+		TotalFuelCost:   totalFuelCost,
+		FuelSplit:       &fuelSplit,
+		Emissions:       routeEmissions(fuelSplit, data.Type, data.Axles, data.VehicleInfo),
+		Instructions:    s.processOSRMStepsToInstructions(route, data.Locale),
+		Restrictions:    s.routeRestrictions(context.Background(), route.Geometry, data.VehicleInfo),
+		DriverItinerary: s.driverItinerary(context.Background(), route, data.DriverRules, data.DepartureTime),
 	}
 
 	// 🔹 Atualiza a duração com o Google Directions API (tempo real)
@@ -6621,18 +6293,17 @@ func (s *Service) createTotalSummary(route OSRMRoute, originLocation, destinatio
 }
 
 // cria um resumo total da rota com waypoints para URL separados
-func (s *Service) createTotalSummaryWithURLWaypoints(route OSRMRoute, originLocation, destinationLocation Location, waypoints []string, waypointsForURL []string, data FrontInfoCEPRequest) TotalSummary {
+func (s *Service) createTotalSummaryWithURLWaypoints(ctx context.Context, route OSRMRoute, originLocation, destinationLocation Location, waypoints []string, waypointsForURL []string, data FrontInfoCEPRequest) TotalSummary {
 	distText, distVal := formatDistance(route.Distance)
 	durText, durVal := formatDuration(route.Duration)
 
-	avgConsumption := (data.ConsumptionCity + data.ConsumptionHwy) / 2
-	totalKm := route.Distance / 1000
-	totalFuelCost := math.Round((data.Price / avgConsumption) * totalKm)
+	fuelSplit := s.routeFuelSplit(ctx, route, data.Type, data.Price, data.ConsumptionCity, data.ConsumptionHwy)
+	totalFuelCost := fuelSplit.TotalCost()
 
-	tolls, _ := s.findTollsOnRoute(context.Background(), route.Geometry, data.Type, float64(data.Axles))
+	tolls, _ := s.findTollsOnRoute(context.Background(), route, newTollVehicle(data.Type, data.Axles, data.VehicleInfo), data.DepartureTime)
 	var totalTollCost float64
 	for _, toll := range tolls {
-		totalTollCost += toll.CashCost
+		totalTollCost += toll.PaidCost
 	}
 
 	// fallback: se não vierem waypoints, usa coordenadas puras
@@ -6676,15 +6347,19 @@ func (s *Service) createTotalSummaryWithURLWaypoints(route OSRMRoute, originLoca
 			Location: destinationLocation,
 			Address:  normalizeAddress(destAddress),
 		},
-		TotalDistance: Distance{Text: distText, Value: distVal},
-		TotalDuration: Duration{Text: durText, Value: durVal},
-		URL:           googleURL,
-		URLWaze:       wazeURL,
-		Tolls:         tolls,
-		TotalTolls:    math.Round(totalTollCost*100) / 100,
-		Polyline:      route.Geometry,
-		TotalFuelCost: totalFuelCost,
-		Instructions:  s.processOSRMStepsToInstructions(route),
+		TotalDistance:   Distance{Text: distText, Value: distVal},
+		TotalDuration:   Duration{Text: durText, Value: durVal},
+		URL:             googleURL,
+		URLWaze:         wazeURL,
+		Tolls:           tolls,
+		TotalTolls:      math.Round(totalTollCost*100) / 100,
+		Polyline:        route.Geometry,
+		TotalFuelCost:   totalFuelCost,
+		FuelSplit:       &fuelSplit,
+		Emissions:       routeEmissions(fuelSplit, data.Type, data.Axles, data.VehicleInfo),
+		Instructions:    s.processOSRMStepsToInstructions(route, data.Locale),
+		Restrictions:    s.routeRestrictions(context.Background(), route.Geometry, data.VehicleInfo),
+		DriverItinerary: s.driverItinerary(context.Background(), route, data.DriverRules, data.DepartureTime),
 	}
 
 	// Tenta obter duração mais precisa do Google Directions API
@@ -6727,28 +6402,16 @@ func (s *Service) createTotalSummaryWithURLWaypoints(route OSRMRoute, originLoca
 	return summary
 }
 
-func (s *Service) osrmNearestSnap(client http.Client, lat, lon float64) (float64, float64, bool) {
-	u := fmt.Sprintf("http://34.207.174.233:5000/nearest/v1/driving/%.6f,%.6f?number=1", lon, lat)
-
+func (s *Service) osrmNearestSnap(lat, lon float64, profile string) (float64, float64, bool) {
 	// Usar timeout reduzido para melhor performance
-	fastClient := http.Client{Timeout: 5 * time.Second}
-	resp, err := fastClient.Get(u)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	loc, err := s.Engine.Nearest(ctx, Location{Latitude: lat, Longitude: lon}, profile)
 	if err != nil {
 		return lat, lon, false
 	}
-	defer resp.Body.Close()
-	var r struct {
-		Code      string `json:"code"`
-		Waypoints []struct {
-			Location []float64 `json:"location"`
-		} `json:"waypoints"`
-	}
-	if json.NewDecoder(resp.Body).Decode(&r) != nil || len(r.Waypoints) == 0 {
-		return lat, lon, false
-	}
-	sLon := r.Waypoints[0].Location[0]
-	sLat := r.Waypoints[0].Location[1]
-	return sLat, sLon, true
+	return loc.Latitude, loc.Longitude, true
 }
 
 // NEWS - DEVOLVER PONTOS ANTES DA ROTA
@@ -6778,13 +6441,13 @@ func (s *Service) computeRiskOffsetsFromGeometry(geometry string, zone RiskZone,
 		exitT      float64
 	)
 
-	inside := s.haversineDistance(points[0].Latitude, points[0].Longitude, zone.Lat, zone.Lng) <= float64(zone.Radius)
+	inside := s.isPointInRiskZone(points[0].Latitude, points[0].Longitude, zone)
 
 	for i := 0; i < len(points)-1; i++ {
-		ts := s.segmentCircleIntersectionsMeters(points[i], points[i+1], zone, latRef)
+		ts := s.segmentZoneIntersectionsMeters(points[i], points[i+1], zone, latRef)
 		if len(ts) == 0 {
 			// Atualiza estado "inside" usando extremo B
-			inside = s.haversineDistance(points[i+1].Latitude, points[i+1].Longitude, zone.Lat, zone.Lng) <= float64(zone.Radius)
+			inside = s.isPointInRiskZone(points[i+1].Latitude, points[i+1].Longitude, zone)
 			continue
 		}
 
@@ -6836,7 +6499,7 @@ func (s *Service) computeRiskOffsetsFromGeometry(geometry string, zone RiskZone,
 		}
 
 		// Atualiza estado para o fim do segmento
-		inside = s.haversineDistance(points[i+1].Latitude, points[i+1].Longitude, zone.Lat, zone.Lng) <= float64(zone.Radius)
+		inside = s.isPointInRiskZone(points[i+1].Latitude, points[i+1].Longitude, zone)
 	}
 
 	// Se não achou par completo, aborta
@@ -6897,6 +6560,15 @@ func pointIsValid(seg int, t float64) bool {
 	return seg >= 0 && t >= 0 && t <= 1
 }
 
+// Interseções t em [0,1] entre o segmento AB e a borda da zona: polígonos e corredores usam a própria forma,
+// as demais zonas o círculo
+func (s *Service) segmentZoneIntersectionsMeters(a, b Location, zone RiskZone, latRef float64) []float64 {
+	if zone.shapeArea() != nil {
+		return s.zoneCrossings(a, b, zone)
+	}
+	return s.segmentCircleIntersectionsMeters(a, b, zone, latRef)
+}
+
 // Interseções t em [0,1] entre o segmento AB e o círculo (centro=zone, raio=zone.Radius), tudo em METROS
 func (s *Service) segmentCircleIntersectionsMeters(a, b Location, zone RiskZone, latRef float64) []float64 {
 	ax, ay := s.projectToMeters(latRef, a.Latitude, a.Longitude)
@@ -7094,8 +6766,8 @@ func (s *Service) buildArcWaypointsDir(entry, exit Location, zone RiskZone, n in
 // monta via-points: [entryOut, arc(midpoints...), exitOut]
 func (s *Service) assembleLateralDetour(entry, exit Location, zone RiskZone, arcPoints int, arcExtraBuffer float64, entryExitPush float64, useLongArc bool) []Location {
 	// âncoras  (ligeiro empurrão para fora)
-	entryOut := s.pushAwayFromCenter(entry, zone, entryExitPush)
-	exitOut := s.pushAwayFromCenter(exit, zone, entryExitPush)
+	entryOut := s.pushToOutside(entry, zone, entryExitPush)
+	exitOut := s.pushToOutside(exit, zone, entryExitPush)
 
 	// arcada (mesmo raio+buffer que você já usa)
 	var arc []Location
@@ -7105,6 +6777,11 @@ func (s *Service) assembleLateralDetour(entry, exit Location, zone RiskZone, arc
 		arc = s.buildArcWaypointsDir(entry, exit, zone, arcPoints, arcExtraBuffer, false)
 	}
 
+	// o arco segue o círculo que envolve a zona; nas formas aproxima cada ponto da borda real
+	for i := range arc {
+		arc[i] = s.pullTowardZone(arc[i], zone, arcExtraBuffer)
+	}
+
 	wps := make([]Location, 0, 2+len(arc))
 	wps = append(wps, entryOut)
 	wps = append(wps, arc...)
@@ -7112,14 +6789,78 @@ func (s *Service) assembleLateralDetour(entry, exit Location, zone RiskZone, arc
 	return wps
 }
 
+// pushToOutside afasta o ponto do centro até ficar a pelo menos extra metros da borda da zona. Nas formas
+// (um corredor comprido tem o centro longe da borda mais próxima) procura, em 16 direções, o menor
+// deslocamento que deixa o ponto a extra metros da própria forma.
 func (s *Service) pushToOutside(p Location, zone RiskZone, extra float64) Location {
-	d := s.haversineDistance(p.Latitude, p.Longitude, zone.Lat, zone.Lng)
-	target := float64(zone.Radius) + extra
-	delta := target - d
-	if delta < 50 {
-		delta = 50
+	area := zone.shapeArea()
+	if area == nil {
+		d := s.haversineDistance(p.Latitude, p.Longitude, zone.Lat, zone.Lng)
+		target := float64(zone.Radius) + extra
+		delta := target - d
+		if delta < 50 {
+			delta = 50
+		}
+		return s.pushAwayFromCenter(p, zone, delta)
+	}
+
+	if area.DistanceTo(p.Latitude, p.Longitude) >= extra {
+		return p
+	}
+	step := math.Max(extra, area.Radius/50)
+	limit := 2*area.Radius + extra
+	best, bestMove := p, math.Inf(1)
+	for k := 0; k < 16; k++ {
+		bearing := float64(k) * 22.5
+		for move := step; move <= limit && move < bestMove; move += step {
+			lat, lng := s.calculateDestination(p.Latitude, p.Longitude, bearing, move)
+			if area.DistanceTo(lat, lng) >= extra {
+				best, bestMove = Location{Latitude: lat, Longitude: lng}, move
+				break
+			}
+		}
+	}
+	return best
+}
+
+// pullTowardZone aproxima do centro um ponto montado sobre o círculo envolvente, parando antes de ficar a
+// menos de clearance metros da forma. Zonas circulares não mudam.
+func (s *Service) pullTowardZone(p Location, zone RiskZone, clearance float64) Location {
+	area := zone.shapeArea()
+	if area == nil {
+		return p
+	}
+	const steps = 20
+	best := p
+	for i := 1; i < steps; i++ {
+		t := float64(i) / steps
+		q := Location{
+			Latitude:  p.Latitude + (zone.Lat-p.Latitude)*t,
+			Longitude: p.Longitude + (zone.Lng-p.Longitude)*t,
+		}
+		if area.DistanceTo(q.Latitude, q.Longitude) < clearance {
+			break
+		}
+		best = q
+	}
+	return best
+}
+
+// riskZoneGap devolve a distância (m) do ponto até a borda da zona; zero ou negativo quando está dentro
+func (s *Service) riskZoneGap(lat, lng float64, zone RiskZone) float64 {
+	if area := zone.shapeArea(); area != nil {
+		return area.DistanceTo(lat, lng)
+	}
+	return s.haversineDistance(lat, lng, zone.Lat, zone.Lng) - float64(zone.Radius)
+}
+
+// scaledBypassPoint afasta mais o ponto A/B quando o desvio padrão não bastou: no círculo, proporcional ao
+// raio; nas formas, pela distância até a borda em vez do círculo que envolve a forma inteira
+func (s *Service) scaledBypassPoint(p Location, zone RiskZone, scale float64) Location {
+	if zone.shapeArea() == nil {
+		return s.pushAwayFromCenter(p, zone, float64(zone.Radius)*(scale-1)+500)
 	}
-	return s.pushAwayFromCenter(p, zone, delta)
+	return s.pushToOutside(p, zone, 1000*scale)
 }
 
 func (s *Service) awayNormalForSegment(before, after Location, zone RiskZone) (latRef, nx, ny float64) {
@@ -7154,18 +6895,18 @@ func (s *Service) offsetByNormal(p Location, latRef, nx, ny, dist float64) Locat
 
 // news
 // Snap que GARANTE ficar fora da zona. Se o snap cair dentro, empurra p/ fora e tenta de novo.
-func (s *Service) snapOutsideMany(client http.Client, pts []Location, zone RiskZone) []Location {
+func (s *Service) snapOutsideMany(pts []Location, zone RiskZone, profile string) []Location {
 	out := make([]Location, len(pts))
 	copy(out, pts)
 	for i := range out {
 		p := out[i]
 		for step := 0; step < 6; step++ {
-			lat, lon, ok := s.osrmNearestSnap(client, p.Latitude, p.Longitude)
-			if ok && s.haversineDistance(lat, lon, zone.Lat, zone.Lng) > float64(zone.Radius)+5 {
+			lat, lon, ok := s.osrmNearestSnap(p.Latitude, p.Longitude, profile)
+			if ok && s.riskZoneGap(lat, lon, zone) > 5 {
 				out[i] = Location{Latitude: lat, Longitude: lon}
 				break
 			}
-			p = s.pushAwayFromCenter(p, zone, 120.0)
+			p = s.pushToOutside(p, zone, float64(step+1)*120.0)
 		}
 	}
 	return out
@@ -7188,11 +6929,9 @@ func (s *Service) detectAllCrossingsFromGeometry(geometry string, zones []RiskZo
 
 // Checa rota OSRM real e retorna TODAS as zonas cruzadas (ordenadas). Bool indica se há pelo menos uma.
 func (s *Service) CheckRouteForAllRiskZones(riskZones []RiskZone, originLat, originLon, destLat, destLon float64) ([]RiskOffsets, bool) {
-	client := http.Client{Timeout: 15 * time.Second}
-	coords := fmt.Sprintf("%f,%f;%f,%f", originLon, originLat, destLon, destLat)
-	url := fmt.Sprintf("http://34.207.174.233:5000/route/v1/driving/%s?overview=full&steps=true", neturl.PathEscape(coords))
-
-	resp, err := client.Get(url)
+	osrmResp, err := s.engineRoute(context.Background(), 15*time.Second, RouteRequest{
+		Coordinates: []Location{{Latitude: originLat, Longitude: originLon}, {Latitude: destLat, Longitude: destLon}},
+	})
 	if err != nil {
 		// fallback: mantém seu comportamento antigo (linha reta), mas sem offsets detalhados
 		if s.checkRouteForRiskZonesFallback(riskZones, originLat, originLon, destLat, destLon) {
@@ -7201,15 +6940,6 @@ func (s *Service) CheckRouteForAllRiskZones(riskZones []RiskZone, originLat, ori
 		}
 		return nil, false
 	}
-	defer resp.Body.Close()
-
-	var osrmResp OSRMResponse
-	if json.NewDecoder(resp.Body).Decode(&osrmResp) != nil || len(osrmResp.Routes) == 0 {
-		if s.checkRouteForRiskZonesFallback(riskZones, originLat, originLon, destLat, destLon) {
-			return nil, true
-		}
-		return nil, false
-	}
 
 	route := osrmResp.Routes[0]
 	offs := s.detectAllCrossingsFromGeometry(route.Geometry, riskZones, 1000)
@@ -7255,22 +6985,15 @@ func (s *Service) createDirectEstimateSummary(originLat, originLon, destLat, des
 	// Tentar calcular pedágios mesmo no fallback usando uma rota OSRM simples
 	var tolls []Toll
 	var totalTollCost float64
-	coords := fmt.Sprintf("%f,%f;%f,%f", originLon, originLat, destLon, destLat)
-	baseURL := "http://34.207.174.233:5000/route/v1/driving/" + neturl.PathEscape(coords) +
-		"?alternatives=0&steps=true&overview=full&continue_straight=false"
-
-	client := http.Client{Timeout: 5 * time.Second}
-	if resp, err := client.Get(baseURL); err == nil && resp != nil {
-		defer resp.Body.Close()
-		if resp.StatusCode >= 200 && resp.StatusCode < 300 {
-			var osrmResp OSRMResponse
-			if json.NewDecoder(resp.Body).Decode(&osrmResp) == nil && len(osrmResp.Routes) > 0 {
-				route := osrmResp.Routes[0]
-				tolls, _ = s.findTollsOnRoute(context.Background(), route.Geometry, data.Type, float64(data.Axles))
-				for _, toll := range tolls {
-					totalTollCost += toll.CashCost
-				}
-			}
+	osrmResp, err := s.engineRoute(context.Background(), 5*time.Second, RouteRequest{
+		Coordinates: []Location{{Latitude: originLat, Longitude: originLon}, {Latitude: destLat, Longitude: destLon}},
+		AllowUTurn:  true,
+	})
+	if err == nil {
+		route := osrmResp.Routes[0]
+		tolls, _ = s.findTollsOnRoute(context.Background(), route, newTollVehicle(data.Type, data.Axles, data.VehicleInfo), data.DepartureTime)
+		for _, toll := range tolls {
+			totalTollCost += toll.PaidCost
 		}
 	}
 
@@ -7286,15 +7009,15 @@ func (s *Service) createDirectEstimateSummary(originLat, originLon, destLat, des
 	// Instruções básicas para rota direta estimada
 	basicInstructions := []Instruction{
 		{
-			Text: "Inicie sua viagem",
+			Text: message(data.Locale, "estimated_depart"),
 			Img:  "https://plates-routes.s3.us-east-1.amazonaws.com/reto.png",
 		},
 		{
-			Text: "Siga em direção ao destino - rota estimada",
+			Text: message(data.Locale, "estimated_follow"),
 			Img:  "https://plates-routes.s3.us-east-1.amazonaws.com/reto.png",
 		},
 		{
-			Text: "Chegue ao seu destino",
+			Text: message(data.Locale, "estimated_arrive"),
 			Img:  "https://plates-routes.s3.us-east-1.amazonaws.com/reto.png",
 		},
 	}
@@ -7314,26 +7037,18 @@ func (s *Service) createDirectEstimateSummary(originLat, originLon, destLat, des
 	}
 }
 
-func (s *Service) calculateDirectRoute(ctx context.Context, client http.Client, originLat, originLon, destLat, destLon float64, originGeocode, destGeocode GeocodeResult, data FrontInfoCEPRequest) []RouteSummary {
-
-	coords := fmt.Sprintf("%f,%f;%f,%f", originLon, originLat, destLon, destLat)
-	baseURL := "http://34.207.174.233:5000/route/v1/driving/" + neturl.PathEscape(coords) +
-		"?alternatives=1&steps=true&overview=full&continue_straight=false"
+func (s *Service) calculateDirectRoute(ctx context.Context, originLat, originLon, destLat, destLon float64, originGeocode, destGeocode GeocodeResult, data FrontInfoCEPRequest) []RouteSummary {
 
-	resp, err := client.Get(baseURL)
-	if err == nil && resp != nil {
-		defer resp.Body.Close()
-		if resp.StatusCode >= 200 && resp.StatusCode < 300 {
-			var osrmResp OSRMResponse
-			if json.NewDecoder(resp.Body).Decode(&osrmResp) == nil && len(osrmResp.Routes) > 0 {
-				route := osrmResp.Routes[0]
-				tolls, _ := s.findTollsOnRoute(ctx, route.Geometry, data.Type, float64(data.Axles))
-				return []RouteSummary{
-					s.createRouteSummary(route, "rota_direta_com_aviso", originGeocode, destGeocode, data, tolls),
-				}
-			}
+	osrmResp, err := s.engineAlternatives(ctx, 10*time.Second, RouteRequest{
+		Coordinates: []Location{{Latitude: originLat, Longitude: originLon}, {Latitude: destLat, Longitude: destLon}},
+		AllowUTurn:  true,
+	}, 1)
+	if err == nil {
+		route := osrmResp.Routes[0]
+		tolls, _ := s.findTollsOnRoute(ctx, route, newTollVehicle(data.Type, data.Axles, data.VehicleInfo), data.DepartureTime)
+		return []RouteSummary{
+			s.createRouteSummary(ctx, route, "rota_direta_com_aviso", originGeocode, destGeocode, data, tolls),
 		}
-	} else {
 	}
 
 	// Fallback local (nunca devolve erro ao front)
@@ -7451,15 +7166,17 @@ func (s *Service) GetCoordinatesFromAddress(ctx context.Context, street, number,
 }
 
 // processOSRMStepsToInstructions converte os steps do OSRM em instruções traduzidas
-func (s *Service) processOSRMStepsToInstructions(route OSRMRoute) []Instruction {
+func (s *Service) processOSRMStepsToInstructions(route OSRMRoute, locale string) []Instruction {
 	var instructions []Instruction
 
 	// Processa os steps de todas as legs da rota
 	for _, leg := range route.Legs {
 		for _, step := range leg.Steps {
 			instruction := Instruction{
-				Text: translateInstruction(step),
-				Img:  selectImage(translateInstruction(step)),
+				Text:         translateInstruction(step, locale),
+				Img:          instructionImage(step),
+				Distance:     step.Distance,
+				DistanceText: formatInstructionDistance(step.Distance, locale),
 			}
 			instructions = append(instructions, instruction)
 		}
@@ -7473,10 +7190,16 @@ func (s *Service) CalculateDistancesBetweenPointsV2(ctx context.Context, data Fr
 		return Response{}, fmt.Errorf("é necessário pelo menos dois pontos para calcular distâncias")
 	}
 
-	client := http.Client{Timeout: 30 * time.Second}
+	vehicle, err := s.resolveVehicleInfo(ctx, data.VehicleInfo, data.Axles, 0)
+	if err != nil {
+		return Response{}, err
+	}
+	data.VehicleInfo = vehicle
+
 	var resultRoutes []DetailedRoute
 	var totalDistance float64
 	var totalDuration float64
+	var segmentGeocodes [][2]GeocodeResult
 
 	for i := 0; i < len(data.CEPs)-1; i++ {
 		originCEP := data.CEPs[i]
@@ -7494,8 +7217,10 @@ func (s *Service) CalculateDistancesBetweenPointsV2(ctx context.Context, data Fr
 		originGeocode, _ := s.getGeocodeAddress(ctx, originAddress)
 		destGeocode, _ := s.getGeocodeAddress(ctx, destAddress)
 
-		coords := fmt.Sprintf("%f,%f;%f,%f", originLon, originLat, destLon, destLat)
-		baseURL := "http://34.207.174.233:5000/route/v1/driving/" + neturl.PathEscape(coords)
+		coordinates := []Location{
+			{Latitude: originLat, Longitude: originLon},
+			{Latitude: destLat, Longitude: destLon},
+		}
 
 		type osrmResult struct {
 			resp     OSRMResponse
@@ -7504,71 +7229,31 @@ func (s *Service) CalculateDistancesBetweenPointsV2(ctx context.Context, data Fr
 		}
 		resultsCh := make(chan osrmResult, 3)
 
-		makeRequest := func(params neturl.Values, category string) {
-			fullURL := baseURL + "?" + params.Encode()
-			resp, err := client.Get(fullURL)
+		makeRequest := func(req RouteRequest, category string) {
+			osrmResp, err := s.engineAlternatives(ctx, 30*time.Second, req, 3)
 			if err != nil {
-				resultsCh <- osrmResult{err: err, category: category}
-				return
-			}
-			defer resp.Body.Close()
-			var osrmResp OSRMResponse
-			if err := json.NewDecoder(resp.Body).Decode(&osrmResp); err != nil {
-				resultsCh <- osrmResult{err: err, category: category}
+				resultsCh <- osrmResult{err: fmt.Errorf("erro OSRM %s: %w", category, err), category: category}
 				return
 			}
-			if osrmResp.Code != "Ok" {
-				resultsCh <- osrmResult{err: fmt.Errorf("erro OSRM %s", category), category: category}
-				return
-			}
-			resultsCh <- osrmResult{resp: osrmResp, category: category}
+			resultsCh <- osrmResult{resp: s.preferCompliantRoutes(ctx, osrmResp, req, data.VehicleInfo), category: category}
 		}
 
 		var routeTypes []string
-		if strings.TrimSpace(strings.ToLower(data.TypeRoute)) == "" {
-			go makeRequest(neturl.Values{
-				"alternatives":      {"3"},
-				"steps":             {"true"},
-				"overview":          {"full"},
-				"continue_straight": {"false"},
-			}, "fastest")
-			go makeRequest(neturl.Values{
-				"alternatives": {"3"},
-				"steps":        {"true"},
-				"overview":     {"full"},
-				"exclude":      {"toll"},
-			}, "cheapest")
-			go makeRequest(neturl.Values{
-				"alternatives": {"3"},
-				"steps":        {"true"},
-				"overview":     {"full"},
-				"exclude":      {"motorway"},
-			}, "efficient")
+		// Menor risco parte dos três perfis; a exposição de cada um decide a ordem em scoreRoutes
+		if strings.TrimSpace(strings.ToLower(data.TypeRoute)) == "" || isLowestRiskRoute(data.TypeRoute) {
+			go makeRequest(RouteRequest{Coordinates: coordinates, AllowUTurn: true}, "fastest")
+			go makeRequest(RouteRequest{Coordinates: coordinates, Exclude: []string{"toll"}}, "cheapest")
+			go makeRequest(RouteRequest{Coordinates: coordinates, Exclude: []string{"motorway"}}, "efficient")
 			routeTypes = []string{"fastest", "cheapest", "efficient"}
 		} else {
 			routeTypes = []string{strings.ToLower(data.TypeRoute)}
 			switch routeTypes[0] {
 			case "rapida", "fastest":
-				makeRequest(neturl.Values{
-					"alternatives":      {"3"},
-					"steps":             {"true"},
-					"overview":          {"full"},
-					"continue_straight": {"false"},
-				}, "fastest")
+				makeRequest(RouteRequest{Coordinates: coordinates, AllowUTurn: true}, "fastest")
 			case "barata", "cheapest":
-				makeRequest(neturl.Values{
-					"alternatives": {"3"},
-					"steps":        {"true"},
-					"overview":     {"full"},
-					"exclude":      {"toll"},
-				}, "cheapest")
+				makeRequest(RouteRequest{Coordinates: coordinates, Exclude: []string{"toll"}}, "cheapest")
 			case "eficiente", "efficient":
-				makeRequest(neturl.Values{
-					"alternatives": {"3"},
-					"steps":        {"true"},
-					"overview":     {"full"},
-					"exclude":      {"motorway"},
-				}, "efficient")
+				makeRequest(RouteRequest{Coordinates: coordinates, Exclude: []string{"motorway"}}, "efficient")
 			}
 		}
 
@@ -7583,9 +7268,8 @@ func (s *Service) CalculateDistancesBetweenPointsV2(ctx context.Context, data Fr
 			route := res.resp.Routes[0]
 			distText, distVal := formatDistance(route.Distance)
 			durText, durVal := formatDuration(route.Duration)
-			avgConsumption := (data.ConsumptionCity + data.ConsumptionHwy) / 2
-			totalKm := route.Distance / 1000
-			totalFuelCost := math.Round((data.Price / avgConsumption) * totalKm)
+			fuelSplit := s.routeFuelSplit(ctx, route, data.Type, data.Price, data.ConsumptionCity, data.ConsumptionHwy)
+			totalFuelCost := fuelSplit.TotalCost()
 
 			googleURL := fmt.Sprintf("https://www.google.com/maps/dir/?api=1&origin=%s&destination=%s",
 				neturl.QueryEscape(normalizeAddress(originGeocode.FormattedAddress)),
@@ -7598,7 +7282,7 @@ func (s *Service) CalculateDistancesBetweenPointsV2(ctx context.Context, data Fr
 				currentTimeMillis,
 			)
 
-			rawTolls, err := s.findTollsOnRoute(ctx, res.resp.Routes[0].Geometry, data.Type, float64(data.Axles))
+			rawTolls, err := s.findTollsOnRoute(ctx, res.resp.Routes[0], newTollVehicle(data.Type, data.Axles, data.VehicleInfo), data.DepartureTime)
 			if err != nil {
 				log.Printf("Erro ao filtrar pedágios: %v", err)
 				rawTolls = nil
@@ -7610,20 +7294,24 @@ func (s *Service) CalculateDistancesBetweenPointsV2(ctx context.Context, data Fr
 
 			var totalTollCost float64
 			for _, toll := range routeTolls {
-				totalTollCost += toll.CashCost
+				totalTollCost += toll.PaidCost
 			}
 
 			summaries = append(summaries, RouteSummary{
-				RouteType:     res.category,
-				HasTolls:      len(routeTolls) > 0,
-				Distance:      Distance{Text: distText, Value: distVal},
-				Duration:      Duration{Text: durText, Value: durVal},
-				URL:           googleURL,
-				URLWaze:       wazeURL,
-				TotalFuelCost: totalFuelCost,
-				Tolls:         routeTolls,
-				TotalTolls:    math.Round(totalTollCost*100) / 100,
-				Polyline:      res.resp.Routes[0].Geometry,
+				RouteType:       res.category,
+				HasTolls:        len(routeTolls) > 0,
+				Distance:        Distance{Text: distText, Value: distVal},
+				Duration:        Duration{Text: durText, Value: durVal},
+				URL:             googleURL,
+				URLWaze:         wazeURL,
+				TotalFuelCost:   totalFuelCost,
+				FuelSplit:       &fuelSplit,
+				Emissions:       routeEmissions(fuelSplit, data.Type, data.Axles, data.VehicleInfo),
+				Tolls:           routeTolls,
+				TotalTolls:      math.Round(totalTollCost*100) / 100,
+				Polyline:        res.resp.Routes[0].Geometry,
+				Restrictions:    s.routeRestrictions(ctx, res.resp.Routes[0].Geometry, data.VehicleInfo),
+				DriverItinerary: s.driverItinerary(ctx, res.resp.Routes[0], data.DriverRules, data.DepartureTime),
 			})
 
 			totalDistance += route.Distance
@@ -7641,10 +7329,11 @@ func (s *Service) CalculateDistancesBetweenPointsV2(ctx context.Context, data Fr
 			},
 			Summaries: summaries,
 		})
+		segmentGeocodes = append(segmentGeocodes, [2]GeocodeResult{originGeocode, destGeocode})
 	}
 
 	var totalRoute TotalSummary
-	var allCoords []string
+	var allCoords []Location
 	var waypoints []string
 	var originLocation, destinationLocation Location
 	for idx, cep := range data.CEPs {
@@ -7652,7 +7341,7 @@ func (s *Service) CalculateDistancesBetweenPointsV2(ctx context.Context, data Fr
 		if err != nil {
 			return Response{}, fmt.Errorf("erro ao buscar coordenadas para total_route no CEP %s: %w", cep, err)
 		}
-		allCoords = append(allCoords, fmt.Sprintf("%f,%f", coordLon, coordLat))
+		allCoords = append(allCoords, Location{Latitude: coordLat, Longitude: coordLon})
 
 		reverse, err := s.reverseGeocode(coordLat, coordLon)
 		if err != nil || reverse == "" {
@@ -7675,72 +7364,71 @@ func (s *Service) CalculateDistancesBetweenPointsV2(ctx context.Context, data Fr
 		}
 	}
 
-	coordsStr := strings.Join(allCoords, ";")
-	urlTotal := fmt.Sprintf("http://34.207.174.233:5000/route/v1/driving/%s?alternatives=0&steps=true&overview=full&continue_straight=false", neturl.PathEscape(coordsStr))
-
-	resp, err := client.Get(urlTotal)
+	osrmResp, err := s.engineRoute(ctx, 30*time.Second, RouteRequest{Coordinates: allCoords, AllowUTurn: true})
 	if err == nil {
-		defer resp.Body.Close()
-		var osrmResp OSRMResponse
-		if err := json.NewDecoder(resp.Body).Decode(&osrmResp); err == nil && len(osrmResp.Routes) > 0 {
-			route := osrmResp.Routes[0]
+		route := osrmResp.Routes[0]
 
-			distText, distVal := formatDistance(totalDistance)
-			durText, durVal := formatDuration(totalDuration)
+		distText, distVal := formatDistance(totalDistance)
+		durText, durVal := formatDuration(totalDuration)
 
-			avgConsumption := (data.ConsumptionCity + data.ConsumptionHwy) / 2
-			totalKm := route.Distance / 1000
-			totalFuelCost := math.Round((data.Price / avgConsumption) * totalKm)
+		fuelSplit := s.routeFuelSplit(ctx, route, data.Type, data.Price, data.ConsumptionCity, data.ConsumptionHwy)
+		totalFuelCost := fuelSplit.TotalCost()
 
-			tolls, _ := s.findTollsOnRoute(ctx, route.Geometry, data.Type, float64(data.Axles))
-			var totalTollCost float64
-			for _, toll := range tolls {
-				totalTollCost += toll.CashCost
-			}
+		tolls, _ := s.findTollsOnRoute(ctx, route, newTollVehicle(data.Type, data.Axles, data.VehicleInfo), data.DepartureTime)
+		var totalTollCost float64
+		for _, toll := range tolls {
+			totalTollCost += toll.PaidCost
+		}
 
-			originAddress := waypoints[0]
-			destAddress := waypoints[len(waypoints)-1]
-			waypointStr := ""
-			if len(waypoints) > 2 {
-				waypointStr = "&waypoints=" + neturl.QueryEscape(strings.Join(waypoints[1:len(waypoints)-1], "|"))
-			}
+		originAddress := waypoints[0]
+		destAddress := waypoints[len(waypoints)-1]
+		waypointStr := ""
+		if len(waypoints) > 2 {
+			waypointStr = "&waypoints=" + neturl.QueryEscape(strings.Join(waypoints[1:len(waypoints)-1], "|"))
+		}
 
-			googleURL := fmt.Sprintf("https://www.google.com/maps/dir/?api=1&origin=%s&destination=%s%s&travelmode=driving",
-				neturl.QueryEscape(originAddress),
-				neturl.QueryEscape(destAddress),
-				waypointStr,
-			)
+		googleURL := fmt.Sprintf("https://www.google.com/maps/dir/?api=1&origin=%s&destination=%s%s&travelmode=driving",
+			neturl.QueryEscape(originAddress),
+			neturl.QueryEscape(destAddress),
+			waypointStr,
+		)
 
-			currentTimeMillis := (time.Now().UnixNano() + int64(route.Duration*float64(time.Second))) / int64(time.Millisecond)
-			wazeURL := fmt.Sprintf("https://www.waze.com/pt-BR/live-map/directions/br?to=%s&from=%s&time=%d&reverse=yes",
-				neturl.QueryEscape(destAddress),
-				neturl.QueryEscape(originAddress),
-				currentTimeMillis,
-			)
+		currentTimeMillis := (time.Now().UnixNano() + int64(route.Duration*float64(time.Second))) / int64(time.Millisecond)
+		wazeURL := fmt.Sprintf("https://www.waze.com/pt-BR/live-map/directions/br?to=%s&from=%s&time=%d&reverse=yes",
+			neturl.QueryEscape(destAddress),
+			neturl.QueryEscape(originAddress),
+			currentTimeMillis,
+		)
 
-			totalRoute = TotalSummary{
-				LocationOrigin: AddressInfo{
-					Location: originLocation,
-					Address:  originAddress,
-				},
-				LocationDestination: AddressInfo{
-					Location: destinationLocation,
-					Address:  normalizeAddress(destAddress),
-				},
-				TotalDistance: Distance{Text: distText, Value: distVal},
-				TotalDuration: Duration{Text: durText, Value: durVal},
-				URL:           googleURL,
-				URLWaze:       wazeURL,
-				Tolls:         tolls,
-				TotalTolls:    math.Round(totalTollCost*100) / 100,
-				Polyline:      route.Geometry,
-				TotalFuelCost: totalFuelCost,
-			}
+		totalRoute = TotalSummary{
+			LocationOrigin: AddressInfo{
+				Location: originLocation,
+				Address:  originAddress,
+			},
+			LocationDestination: AddressInfo{
+				Location: destinationLocation,
+				Address:  normalizeAddress(destAddress),
+			},
+			TotalDistance:   Distance{Text: distText, Value: distVal},
+			TotalDuration:   Duration{Text: durText, Value: durVal},
+			URL:             googleURL,
+			URLWaze:         wazeURL,
+			Tolls:           tolls,
+			TotalTolls:      math.Round(totalTollCost*100) / 100,
+			Polyline:        route.Geometry,
+			TotalFuelCost:   totalFuelCost,
+			FuelSplit:       &fuelSplit,
+			Emissions:       routeEmissions(fuelSplit, data.Type, data.Axles, data.VehicleInfo),
+			Restrictions:    s.routeRestrictions(ctx, route.Geometry, data.VehicleInfo),
+			DriverItinerary: s.driverItinerary(ctx, route, data.DriverRules, data.DepartureTime),
 		}
 	}
 
+	totalRoute, totalRoutes := s.scoreRoutes(ctx, resultRoutes, segmentGeocodes, totalRoute, allCoords, waypoints, s.convertV2ToCEPRequest(data))
+
 	return Response{
-		Routes:     resultRoutes,
-		TotalRoute: totalRoute,
+		Routes:      resultRoutes,
+		TotalRoute:  totalRoute,
+		TotalRoutes: totalRoutes,
 	}, nil
 }
@@ -0,0 +1,278 @@
+package new_routes
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"time"
+
+	db "geolocation/db/sqlc"
+)
+
+const (
+	// defaultTagAnalysisDays é o período analisado quando nenhuma data é informada
+	defaultTagAnalysisDays = 30
+	// maxTagAnalysisDays limita o período para não carregar o histórico inteiro do usuário
+	maxTagAnalysisDays = 366
+)
+
+// TagAnalysisRequest pede a análise de tags para uma rota salva (route_hist_id) ou para as rotas do usuário no período
+type TagAnalysisRequest struct {
+	RouteHistID int64  `json:"route_hist_id"`
+	RouteIndex  int64  `json:"route_index"`
+	StartDate   string `json:"start_date"`
+	EndDate     string `json:"end_date"`
+	Vehicles    int64  `json:"vehicles"`
+	Axles       int64  `json:"axles"`
+	Type        string `json:"type"`
+	VehicleInfo
+}
+
+type TagAnalysisDTO struct {
+	Request TagAnalysisRequest
+	UserID  int64
+}
+
+// TagProviderAnalysis é a cobertura e o custo estimado das passagens com uma tag
+type TagProviderAnalysis struct {
+	Tag                  string   `json:"tag"`
+	ImageURL             string   `json:"image_url"`
+	CoveredPassages      int      `json:"covered_passages"`
+	Coverage             float64  `json:"coverage_percent"`
+	CoverageAmount       float64  `json:"coverage_amount_percent"`
+	TollCost             float64  `json:"toll_cost"`
+	MonthlyFee           float64  `json:"monthly_fee"`
+	TotalCost            float64  `json:"total_cost"`
+	Savings              float64  `json:"savings"`
+	UncoveredConcessions []string `json:"uncovered_concessions"`
+}
+
+type TagAnalysisResponse struct {
+	StartDate   string                `json:"start_date,omitempty"`
+	EndDate     string                `json:"end_date,omitempty"`
+	Routes      int                   `json:"routes"`
+	Passages    int                   `json:"passages"`
+	CashCost    float64               `json:"cash_cost"`
+	Recommended string                `json:"recommended"`
+	Providers   []TagProviderAnalysis `json:"providers"`
+}
+
+// tagAnalysisPassage é uma passagem por praça com o valor em dinheiro e a categoria usada no preço
+type tagAnalysisPassage struct {
+	concession string
+	category   int
+	cash       float64
+}
+
+// AnalyzeTags estima, para cada fornecedor de tag, quantas passagens seriam cobertas e o custo total
+// (tarifa com desconto nas praças aceitas, dinheiro nas demais e mensalidade por veículo) nas rotas
+// escolhidas pelo usuário. As regras de desconto vêm de toll_tag_discounts, vigentes hoje.
+func (s *Service) AnalyzeTags(ctx context.Context, data TagAnalysisDTO) (TagAnalysisResponse, error) {
+	request := data.Request
+	var response TagAnalysisResponse
+	var routes []RouteOutput
+	months := 0.0
+
+	if request.RouteHistID > 0 {
+		routeHist, err := s.routeHistForUser(ctx, request.RouteHistID, data.UserID)
+		if err != nil {
+			return TagAnalysisResponse{}, err
+		}
+		route, err := chosenRoute(routeHist.Response, request.RouteIndex)
+		if err != nil {
+			return TagAnalysisResponse{}, err
+		}
+		routes = append(routes, route)
+	} else {
+		start, end, err := tagAnalysisPeriod(request.StartDate, request.EndDate)
+		if err != nil {
+			return TagAnalysisResponse{}, err
+		}
+		rows, err := s.InterfaceService.GetUserRouteHistByPeriod(ctx, db.GetUserRouteHistByPeriodParams{
+			UserID:    data.UserID,
+			StartDate: start,
+			EndDate:   end,
+		})
+		if err != nil {
+			return TagAnalysisResponse{}, fmt.Errorf("erro ao buscar rotas do período: %w", err)
+		}
+		for _, row := range rows {
+			route, err := chosenRoute(row.Response, row.RouteChoose)
+			if err != nil {
+				log.Printf("Rota %d ignorada na análise de tags: %v", row.ID, err)
+				continue
+			}
+			routes = append(routes, route)
+		}
+		response.StartDate = start.Format("2006-01-02")
+		response.EndDate = end.AddDate(0, 0, -1).Format("2006-01-02")
+		// A mensalidade só entra na análise por período, proporcional aos meses cobertos
+		months = math.Ceil(end.Sub(start).Hours() / 24 / 30)
+	}
+
+	passages, err := s.tagAnalysisPassages(ctx, routes, request)
+	if err != nil {
+		return TagAnalysisResponse{}, err
+	}
+
+	snap, err := s.POIIndex.current(ctx)
+	if err != nil {
+		return TagAnalysisResponse{}, err
+	}
+	// A análise projeta o custo com as tarifas de hoje
+	pricing, err := s.tollPricingAt(ctx, snap, nil)
+	if err != nil {
+		return TagAnalysisResponse{}, err
+	}
+	tagRecords, err := s.InterfaceService.GetTollTags(ctx)
+	if err != nil {
+		return TagAnalysisResponse{}, fmt.Errorf("erro ao buscar tags: %w", err)
+	}
+
+	vehicles := request.Vehicles
+	if vehicles <= 0 {
+		vehicles = 1
+	}
+
+	for _, p := range passages {
+		response.CashCost += p.cash
+	}
+	response.Routes = len(routes)
+	response.Passages = len(passages)
+	response.CashCost = roundCents(response.CashCost)
+	response.Providers = make([]TagProviderAnalysis, 0, len(tagRecords))
+
+	for _, tagRecord := range tagRecords {
+		analysis := TagProviderAnalysis{
+			Tag:                  tagRecord.Name,
+			ImageURL:             tagRecord.ImageUrl,
+			MonthlyFee:           roundCents(tagRecord.MonthlyFee * months * float64(vehicles)),
+			UncoveredConcessions: []string{},
+		}
+		uncovered := make(map[string]bool)
+		coveredAmount := 0.0
+		for _, p := range passages {
+			if !tagAccepts(tagRecord, p.concession) {
+				analysis.TollCost += p.cash
+				if !uncovered[p.concession] {
+					uncovered[p.concession] = true
+					analysis.UncoveredConcessions = append(analysis.UncoveredConcessions, p.concession)
+				}
+				continue
+			}
+			discount := pricing.discountFor(tagRecord.Name, p.concession, p.category)
+			analysis.TollCost += p.cash * (1 - discount/100)
+			analysis.CoveredPassages++
+			coveredAmount += p.cash
+		}
+		if len(passages) > 0 {
+			analysis.Coverage = roundCents(float64(analysis.CoveredPassages) / float64(len(passages)) * 100)
+		}
+		if response.CashCost > 0 {
+			analysis.CoverageAmount = roundCents(coveredAmount / response.CashCost * 100)
+		}
+		analysis.TollCost = roundCents(analysis.TollCost)
+		analysis.TotalCost = roundCents(analysis.TollCost + analysis.MonthlyFee)
+		analysis.Savings = roundCents(response.CashCost - analysis.TotalCost)
+		sort.Strings(analysis.UncoveredConcessions)
+		response.Providers = append(response.Providers, analysis)
+	}
+
+	// Menor custo total primeiro; no empate, a de maior cobertura
+	sort.SliceStable(response.Providers, func(i, j int) bool {
+		if response.Providers[i].TotalCost == response.Providers[j].TotalCost {
+			return response.Providers[i].Coverage > response.Providers[j].Coverage
+		}
+		return response.Providers[i].TotalCost < response.Providers[j].TotalCost
+	})
+	if len(response.Providers) > 0 && response.Providers[0].CoveredPassages > 0 {
+		response.Recommended = response.Providers[0].Tag
+	}
+	return response, nil
+}
+
+// tagAnalysisPassages lista as passagens das rotas. Com o veículo informado, o valor em dinheiro é
+// recalculado pelas tarifas vigentes; sem ele, vale o valor e a categoria gravados na rota.
+func (s *Service) tagAnalysisPassages(ctx context.Context, routes []RouteOutput, request TagAnalysisRequest) ([]tagAnalysisPassage, error) {
+	var passages []tagAnalysisPassage
+	if request.Type == "" {
+		for _, route := range routes {
+			for _, toll := range route.Tolls {
+				passages = append(passages, tagAnalysisPassage{concession: toll.Concession, category: toll.Category, cash: toll.CashCost})
+			}
+		}
+		return passages, nil
+	}
+
+	snap, err := s.POIIndex.current(ctx)
+	if err != nil {
+		return nil, err
+	}
+	pricing, err := s.tollPricingAt(ctx, snap, nil)
+	if err != nil {
+		return nil, err
+	}
+	tollsByID := make(map[int64]db.Toll, len(snap.tolls))
+	for _, t := range snap.tolls {
+		tollsByID[t.ID] = t
+	}
+
+	category := newTollVehicle(request.Type, request.Axles, request.VehicleInfo).category()
+	for _, route := range routes {
+		for _, toll := range route.Tolls {
+			cash := toll.CashCost
+			if dbToll, ok := tollsByID[int64(toll.ID)]; ok {
+				cash = pricing.cashPrice(dbToll, category)
+			}
+			passages = append(passages, tagAnalysisPassage{concession: toll.Concession, category: category.Code, cash: cash})
+		}
+	}
+	return passages, nil
+}
+
+// chosenRoute lê a rota escolhida na resposta gravada em route_hist
+func chosenRoute(response json.RawMessage, index int64) (RouteOutput, error) {
+	var output FinalOutput
+	if err := json.Unmarshal(response, &output); err != nil {
+		return RouteOutput{}, fmt.Errorf("erro ao ler rota: %w", err)
+	}
+	if index < 0 || int(index) >= len(output.Routes) {
+		return RouteOutput{}, errors.New("rota escolhida inválida")
+	}
+	return output.Routes[index], nil
+}
+
+// tagAnalysisPeriod interpreta as datas (AAAA-MM-DD) e devolve o intervalo [início, fim + 1 dia)
+func tagAnalysisPeriod(startDate, endDate string) (time.Time, time.Time, error) {
+	end := time.Now().Truncate(24*time.Hour).AddDate(0, 0, 1)
+	if endDate != "" {
+		parsed, err := time.Parse("2006-01-02", endDate)
+		if err != nil {
+			return time.Time{}, time.Time{}, errors.New("end_date inválida, use AAAA-MM-DD")
+		}
+		end = parsed.AddDate(0, 0, 1)
+	}
+	start := end.AddDate(0, 0, -defaultTagAnalysisDays)
+	if startDate != "" {
+		parsed, err := time.Parse("2006-01-02", startDate)
+		if err != nil {
+			return time.Time{}, time.Time{}, errors.New("start_date inválida, use AAAA-MM-DD")
+		}
+		start = parsed
+	}
+	if !start.Before(end) {
+		return time.Time{}, time.Time{}, errors.New("start_date deve ser anterior a end_date")
+	}
+	if end.Sub(start) > maxTagAnalysisDays*24*time.Hour {
+		return time.Time{}, time.Time{}, fmt.Errorf("o período máximo é de %d dias", maxTagAnalysisDays)
+	}
+	return start, end, nil
+}
+
+func roundCents(v float64) float64 {
+	return math.Round(v*100) / 100
+}
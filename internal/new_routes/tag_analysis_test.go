@@ -0,0 +1,167 @@
+package new_routes
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	db "geolocation/db/sqlc"
+	"geolocation/internal/routes"
+)
+
+// tagHistRepository guarda as rotas do usuário e as tags com as regras de desconto vigentes
+type tagHistRepository struct {
+	routes.InterfaceRepository
+	hist   map[int64]db.RouteHist
+	period []db.GetUserRouteHistByPeriodRow
+	params db.GetUserRouteHistByPeriodParams
+}
+
+func (r *tagHistRepository) GetRouteHistByID(_ context.Context, id int64) (db.RouteHist, error) {
+	hist, ok := r.hist[id]
+	if !ok {
+		return db.RouteHist{}, sql.ErrNoRows
+	}
+	return hist, nil
+}
+
+func (r *tagHistRepository) GetUserRouteHistByPeriod(_ context.Context, arg db.GetUserRouteHistByPeriodParams) ([]db.GetUserRouteHistByPeriodRow, error) {
+	r.params = arg
+	return r.period, nil
+}
+
+func (r *tagHistRepository) GetTollTags(context.Context) ([]db.TollTag, error) {
+	return []db.TollTag{
+		{Name: "SemParar", DealershipAccepts: "CCR, Arteris", MonthlyFee: 20},
+		{Name: "ConectCar", DealershipAccepts: "CCR", MonthlyFee: 0},
+		{Name: "Veloe", DealershipAccepts: "Ecovias"},
+	}, nil
+}
+
+func (r *tagHistRepository) GetEffectiveTollTariffs(context.Context, time.Time) ([]db.TollTariff, error) {
+	return nil, nil
+}
+
+func (r *tagHistRepository) GetEffectiveTollTagDiscounts(context.Context, time.Time) ([]db.GetEffectiveTollTagDiscountsRow, error) {
+	return []db.GetEffectiveTollTagDiscountsRow{
+		{TagName: "SemParar", DiscountPercent: 5},
+		{TagName: "ConectCar", Concessionaria: sql.NullString{String: "CCR", Valid: true}, DiscountPercent: 10},
+	}, nil
+}
+
+func tagAnalysisRoute(t *testing.T, tolls ...Toll) json.RawMessage {
+	t.Helper()
+	raw, err := json.Marshal(FinalOutput{Routes: []RouteOutput{{}, {Tolls: tolls}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return raw
+}
+
+func TestAnalyzeTagsByPeriod(t *testing.T) {
+	ccr := Toll{Concession: "CCR", Category: TollCategoryAuto, CashCost: 10}
+	arteris := Toll{Concession: "Arteris", Category: TollCategoryAuto, CashCost: 20}
+	repo := &tagHistRepository{period: []db.GetUserRouteHistByPeriodRow{
+		{ID: 1, Response: tagAnalysisRoute(t, ccr, arteris), RouteChoose: 1},
+		{ID: 2, Response: tagAnalysisRoute(t, ccr), RouteChoose: 1},
+		// Rota escolhida fora da resposta gravada: é ignorada, não derruba a análise
+		{ID: 3, Response: tagAnalysisRoute(t, ccr), RouteChoose: 5},
+	}}
+	s := &Service{InterfaceService: repo, POIIndex: &POIIndex{snapshot: &poiSnapshot{}}}
+
+	got, err := s.AnalyzeTags(context.Background(), TagAnalysisDTO{
+		UserID:  7,
+		Request: TagAnalysisRequest{StartDate: "2026-09-01", EndDate: "2026-09-30", Vehicles: 2},
+	})
+	if err != nil {
+		t.Fatalf("AnalyzeTags() erro = %v", err)
+	}
+
+	wantParams := db.GetUserRouteHistByPeriodParams{
+		UserID:    7,
+		StartDate: time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC),
+		EndDate:   time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC),
+	}
+	if repo.params != wantParams {
+		t.Errorf("período consultado = %+v, want %+v", repo.params, wantParams)
+	}
+	if got.StartDate != "2026-09-01" || got.EndDate != "2026-09-30" || got.Routes != 2 || got.Passages != 3 || got.CashCost != 40 {
+		t.Errorf("resumo = %+v", got)
+	}
+
+	// ConectCar: 2 passagens CCR com 10% (18) + Arteris em dinheiro (20), sem mensalidade.
+	// SemParar cobre tudo com 5% (38), mas a mensalidade de 1 mês para 2 veículos soma 40.
+	want := []TagProviderAnalysis{
+		{Tag: "ConectCar", CoveredPassages: 2, Coverage: 66.67, CoverageAmount: 50, TollCost: 38, TotalCost: 38, Savings: 2, UncoveredConcessions: []string{"Arteris"}},
+		{Tag: "Veloe", TollCost: 40, TotalCost: 40, UncoveredConcessions: []string{"Arteris", "CCR"}},
+		{Tag: "SemParar", CoveredPassages: 3, Coverage: 100, CoverageAmount: 100, TollCost: 38, MonthlyFee: 40, TotalCost: 78, Savings: -38, UncoveredConcessions: []string{}},
+	}
+	if !reflect.DeepEqual(got.Providers, want) {
+		t.Errorf("fornecedores =\n%+v\nwant\n%+v", got.Providers, want)
+	}
+	if got.Recommended != "ConectCar" {
+		t.Errorf("recomendada = %q, want ConectCar", got.Recommended)
+	}
+}
+
+func TestAnalyzeTagsSavedRoute(t *testing.T) {
+	repo := &tagHistRepository{hist: map[int64]db.RouteHist{
+		10: {ID: 10, IDUser: 7, Response: tagAnalysisRoute(t, Toll{Concession: "Rota do Oeste", CashCost: 15})},
+		11: {ID: 11, IDUser: 8, Response: tagAnalysisRoute(t)},
+	}}
+	s := &Service{InterfaceService: repo, POIIndex: &POIIndex{snapshot: &poiSnapshot{}}}
+	ctx := context.Background()
+
+	got, err := s.AnalyzeTags(ctx, TagAnalysisDTO{UserID: 7, Request: TagAnalysisRequest{RouteHistID: 10, RouteIndex: 1}})
+	if err != nil {
+		t.Fatalf("AnalyzeTags() erro = %v", err)
+	}
+	// Rota salva não tem período: sem datas e sem mensalidade
+	if got.StartDate != "" || got.Routes != 1 || got.CashCost != 15 {
+		t.Errorf("resumo = %+v", got)
+	}
+	for _, p := range got.Providers {
+		if p.MonthlyFee != 0 || p.TotalCost != 15 {
+			t.Errorf("%s: mensalidade %v, total %v, want 0 e 15", p.Tag, p.MonthlyFee, p.TotalCost)
+		}
+	}
+	// Nenhuma tag cobre a praça: não há o que recomendar
+	if got.Recommended != "" {
+		t.Errorf("recomendada = %q, want vazia", got.Recommended)
+	}
+
+	if _, err := s.AnalyzeTags(ctx, TagAnalysisDTO{UserID: 7, Request: TagAnalysisRequest{RouteHistID: 11}}); err == nil {
+		t.Error("AnalyzeTags() analisou a rota privada de outro usuário")
+	}
+	if _, err := s.AnalyzeTags(ctx, TagAnalysisDTO{UserID: 7, Request: TagAnalysisRequest{RouteHistID: 10, RouteIndex: 2}}); err == nil {
+		t.Error("AnalyzeTags() aceitou índice de rota inexistente")
+	}
+}
+
+func TestTagAnalysisPeriod(t *testing.T) {
+	start, end, err := tagAnalysisPeriod("", "2026-03-10")
+	if err != nil {
+		t.Fatalf("tagAnalysisPeriod() erro = %v", err)
+	}
+	// Sem início, o período são os 30 dias que terminam no fim informado, inclusive
+	if got := start.Format("2006-01-02") + " " + end.Format("2006-01-02"); got != "2026-02-09 2026-03-11" {
+		t.Errorf("período padrão = %s", got)
+	}
+
+	if _, _, err := tagAnalysisPeriod("2025-03-10", "2026-03-10"); err != nil {
+		t.Errorf("período de %d dias rejeitado: %v", maxTagAnalysisDays, err)
+	}
+	for _, invalid := range [][2]string{
+		{"2025-03-09", "2026-03-10"},
+		{"2026-03-11", "2026-03-10"},
+		{"10/03/2026", ""},
+		{"", "2026-3-10"},
+	} {
+		if _, _, err := tagAnalysisPeriod(invalid[0], invalid[1]); err == nil {
+			t.Errorf("tagAnalysisPeriod(%q, %q) não retornou erro", invalid[0], invalid[1])
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package new_routes
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// RouteTimeline converte posições ao longo da rota escolhida em distância e tempo de chegada,
+// usando as distâncias e durações acumuladas dos passos (legs/steps) devolvidos pelo motor
+type RouteTimeline struct {
+	distances      []float64
+	durations      []float64
+	geometryLength float64
+	departure      *time.Time
+}
+
+// newRouteTimeline monta a linha do tempo da rota. geometryLength é o comprimento da polyline medido
+// em RouteGeometry, usado para converter posições na geometria para a distância do motor.
+func newRouteTimeline(route OSRMRoute, geometryLength float64, departure *time.Time) RouteTimeline {
+	t := RouteTimeline{distances: []float64{0}, durations: []float64{0}, geometryLength: geometryLength, departure: departure}
+
+	var dist, dur float64
+	for _, leg := range route.Legs {
+		if len(leg.Steps) == 0 {
+			dist += leg.Distance
+			dur += leg.Duration
+			t.distances = append(t.distances, dist)
+			t.durations = append(t.durations, dur)
+			continue
+		}
+		for _, step := range leg.Steps {
+			if step.Distance == 0 && step.Duration == 0 {
+				continue
+			}
+			dist += step.Distance
+			dur += step.Duration
+			t.distances = append(t.distances, dist)
+			t.durations = append(t.durations, dur)
+		}
+	}
+
+	// Sem passos detalhados, o tempo é proporcional à distância da rota
+	if dist == 0 && route.Distance > 0 {
+		t.distances = append(t.distances, route.Distance)
+		t.durations = append(t.durations, route.Duration)
+	}
+	return t
+}
+
+func (t RouteTimeline) totalDistance() float64 {
+	return t.distances[len(t.distances)-1]
+}
+
+// arrivalAtAlong calcula a chegada a um ponto casado na geometria (posição along em metros)
+func (t RouteTimeline) arrivalAtAlong(along float64) ArrivalResponse {
+	dist := along
+	if t.geometryLength > 0 && t.totalDistance() > 0 {
+		dist = along * t.totalDistance() / t.geometryLength
+	}
+	return t.arrivalAtDistance(dist)
+}
+
+// arrivalAtDistance calcula a chegada a um ponto a dist metros da origem, interpolando entre os passos
+func (t RouteTimeline) arrivalAtDistance(dist float64) ArrivalResponse {
+	total := t.totalDistance()
+	if dist > total {
+		dist = total
+	}
+	if dist < 0 {
+		dist = 0
+	}
+
+	var seconds float64
+	i := sort.SearchFloat64s(t.distances, dist)
+	switch {
+	case i == 0:
+		seconds = 0
+	case i >= len(t.distances):
+		seconds = t.durations[len(t.durations)-1]
+	default:
+		span := t.distances[i] - t.distances[i-1]
+		seconds = t.durations[i-1]
+		if span > 0 {
+			seconds += (dist - t.distances[i-1]) / span * (t.durations[i] - t.durations[i-1])
+		}
+	}
+
+	elapsed := time.Duration(seconds * float64(time.Second))
+	arrival := ArrivalResponse{
+		Distance:        fmt.Sprintf("%.2f km", dist/1000),
+		Time:            elapsed.Round(time.Second).String(),
+		DistanceMeters:  dist,
+		DurationSeconds: seconds,
+	}
+	if t.departure != nil {
+		eta := t.departure.Add(elapsed)
+		arrival.ETA = &eta
+	}
+	return arrival
+}
+
+// distanceAtDuration é o inverso de arrivalAtDistance: a distância (m) percorrida após seconds de direção
+func (t RouteTimeline) distanceAtDuration(seconds float64) float64 {
+	i := sort.SearchFloat64s(t.durations, seconds)
+	switch {
+	case i == 0:
+		return 0
+	case i >= len(t.durations):
+		return t.totalDistance()
+	}
+	span := t.durations[i] - t.durations[i-1]
+	if span <= 0 {
+		return t.distances[i]
+	}
+	return t.distances[i-1] + (seconds-t.durations[i-1])/span*(t.distances[i]-t.distances[i-1])
+}
+
+// routeForTimeline representa a rota total como OSRMRoute, sem passos: a linha do tempo fica proporcional
+func (t TotalSummary) routeForTimeline() OSRMRoute {
+	return OSRMRoute{Geometry: t.Polyline, Distance: t.TotalDistance.Value, Duration: t.TotalDuration.Value}
+}
+
+// departureCacheKey compõe a parte da chave de cache referente ao horário de saída; vazio quando não informado
+func departureCacheKey(departure *time.Time) string {
+	if departure == nil {
+		return ""
+	}
+	return ":departure:" + strconv.FormatInt(departure.Unix(), 10)
+}
@@ -0,0 +1,104 @@
+package new_routes
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// timelineTestRoute tem um trecho rápido (1 km em 100 s), um lento (1 km em 300 s), um passo vazio
+// e uma segunda perna sem passos (2 km em 200 s)
+func timelineTestRoute() OSRMRoute {
+	return OSRMRoute{
+		Distance: 4000,
+		Duration: 600,
+		Legs: []OSRMLeg{
+			{Distance: 2000, Duration: 400, Steps: []OSRMStep{
+				{Distance: 1000, Duration: 100},
+				{Distance: 1000, Duration: 300},
+				{},
+			}},
+			{Distance: 2000, Duration: 200},
+		},
+	}
+}
+
+func TestRouteTimelineArrivalAtDistance(t *testing.T) {
+	departure := time.Date(2026, 10, 16, 8, 0, 0, 0, time.UTC)
+	timeline := newRouteTimeline(timelineTestRoute(), 0, &departure)
+	noSteps := newRouteTimeline(OSRMRoute{Distance: 1000, Duration: 50}, 0, nil)
+
+	tests := []struct {
+		name         string
+		timeline     RouteTimeline
+		dist         float64
+		wantDist     float64
+		wantDuration float64
+	}{
+		{name: "origem", timeline: timeline, dist: 0, wantDist: 0, wantDuration: 0},
+		{name: "meio do trecho rápido", timeline: timeline, dist: 500, wantDist: 500, wantDuration: 50},
+		{name: "fim de um passo", timeline: timeline, dist: 1000, wantDist: 1000, wantDuration: 100},
+		{name: "meio do trecho lento", timeline: timeline, dist: 1500, wantDist: 1500, wantDuration: 250},
+		{name: "perna sem passos", timeline: timeline, dist: 3000, wantDist: 3000, wantDuration: 500},
+		{name: "além do destino", timeline: timeline, dist: 5000, wantDist: 4000, wantDuration: 600},
+		{name: "antes da origem", timeline: timeline, dist: -10, wantDist: 0, wantDuration: 0},
+		{name: "rota sem pernas é proporcional", timeline: noSteps, dist: 500, wantDist: 500, wantDuration: 25},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.timeline.arrivalAtDistance(tt.dist)
+			if got.DistanceMeters != tt.wantDist || math.Abs(got.DurationSeconds-tt.wantDuration) > 1e-9 {
+				t.Errorf("arrivalAtDistance(%v) = %v m/%v s, want %v m/%v s", tt.dist, got.DistanceMeters, got.DurationSeconds, tt.wantDist, tt.wantDuration)
+			}
+			if tt.timeline.departure == nil {
+				if got.ETA != nil {
+					t.Errorf("eta = %v, want nil sem saída", got.ETA)
+				}
+				return
+			}
+			wantETA := departure.Add(time.Duration(tt.wantDuration * float64(time.Second)))
+			if got.ETA == nil || !got.ETA.Equal(wantETA) {
+				t.Errorf("eta = %v, want %v", got.ETA, wantETA)
+			}
+		})
+	}
+}
+
+func TestRouteTimelineArrivalAtAlong(t *testing.T) {
+	// A geometria mede metade da distância do motor: along é escalado antes de interpolar
+	timeline := newRouteTimeline(timelineTestRoute(), 2000, nil)
+	if got := timeline.arrivalAtAlong(1000); got.DistanceMeters != 2000 || got.DurationSeconds != 400 {
+		t.Errorf("arrivalAtAlong(1000) = %v m/%v s, want 2000 m/400 s", got.DistanceMeters, got.DurationSeconds)
+	}
+}
+
+func TestRouteTimelineDistanceAtDuration(t *testing.T) {
+	timeline := newRouteTimeline(timelineTestRoute(), 0, nil)
+
+	tests := []struct {
+		name    string
+		seconds float64
+		want    float64
+	}{
+		{name: "partida", seconds: 0, want: 0},
+		{name: "antes da partida", seconds: -5, want: 0},
+		{name: "trecho rápido", seconds: 50, want: 500},
+		{name: "trecho lento", seconds: 250, want: 1500},
+		{name: "perna sem passos", seconds: 500, want: 3000},
+		{name: "após a chegada", seconds: 700, want: 4000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := timeline.distanceAtDuration(tt.seconds); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("distanceAtDuration(%v) = %v, want %v", tt.seconds, got, tt.want)
+			}
+			if tt.seconds > 0 && tt.seconds < 600 {
+				if back := timeline.arrivalAtDistance(tt.want).DurationSeconds; math.Abs(back-tt.seconds) > 1e-9 {
+					t.Errorf("arrivalAtDistance(%v) = %v s, want %v s", tt.want, back, tt.seconds)
+				}
+			}
+		})
+	}
+}
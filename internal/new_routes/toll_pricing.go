@@ -0,0 +1,323 @@
+package new_routes
+
+import (
+	"context"
+	"fmt"
+	db "geolocation/db/sqlc"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Categorias de cobrança da ANTT. A tarifa básica cadastrada em tolls.tarifa é a da categoria 1;
+// as demais são obtidas pelo multiplicador da categoria quando a praça não tem tarifa própria.
+const (
+	TollCategoryAuto            = 1
+	TollCategoryCommercial2     = 2
+	TollCategoryAutoTrailer3    = 3
+	TollCategoryCommercial3     = 4
+	TollCategoryAutoTrailer4    = 5
+	TollCategoryCommercial4     = 6
+	TollCategoryCommercial5     = 7
+	TollCategoryCommercial6     = 8
+	TollCategoryMotorcycle      = 9
+	TollCategoryCommercialExtra = 10
+)
+
+// TollCategory é a categoria ANTT da composição e o multiplicador sobre a tarifa básica
+type TollCategory struct {
+	Code        int     `json:"code"`
+	Multiplier  float64 `json:"multiplier"`
+	Description string  `json:"description"`
+}
+
+// TollVehicle reúne o que define a cobrança do pedágio: tipo, eixos, rodagem e eixos suspensos
+type TollVehicle struct {
+	Type           string
+	Axles          int64
+	SuspendedAxles int64
+	SingleWheels   bool
+	Category       int64
+	Tag            string
+}
+
+// TollTagPrice é o valor da praça pago com uma tag específica
+type TollTagPrice struct {
+	Tag      string  `json:"tag"`
+	Price    float64 `json:"price"`
+	Discount float64 `json:"discount_percent"`
+}
+
+// maxCachedPricingDays limita os dias de vigência guardados por carga do índice
+const maxCachedPricingDays = 32
+
+// tollPricingLocation é o fuso em que as datas de vigência das tarifas são lidas (Brasília)
+var tollPricingLocation = time.FixedZone("BRT", -3*60*60)
+
+// tollPricing guarda as tarifas e regras de desconto vigentes na data de referência
+type tollPricing struct {
+	tariffs   map[int64]map[int]float64
+	discounts []db.GetEffectiveTollTagDiscountsRow
+}
+
+func newTollVehicle(vehicleType string, axles int64, info VehicleInfo) TollVehicle {
+	return TollVehicle{
+		Type:           vehicleType,
+		Axles:          axles,
+		SuspendedAxles: info.SuspendedAxles,
+		SingleWheels:   info.SingleWheels,
+		Category:       info.TollCategory,
+		Tag:            info.TollTag,
+	}
+}
+
+// category enquadra o veículo na tabela da ANTT. Veículos comerciais (rodagem dupla) pagam por eixo
+// e eixos suspensos não são cobrados (Lei 13.103/2015, art. 17); veículos leves seguem as categorias 1, 3 e 5.
+func (v TollVehicle) category() TollCategory {
+	if v.Category > 0 {
+		return tollCategoryByCode(int(v.Category))
+	}
+
+	vehicleType := strings.ToLower(strings.TrimSpace(v.Type))
+	if vehicleType == "motorcycle" {
+		return tollCategoryByCode(TollCategoryMotorcycle)
+	}
+
+	axles := v.Axles
+	if axles < 2 {
+		axles = 2
+	}
+
+	light := vehicleType == "auto" || v.SingleWheels
+	if light {
+		switch {
+		case axles == 2:
+			return tollCategoryByCode(TollCategoryAuto)
+		case axles == 3:
+			return tollCategoryByCode(TollCategoryAutoTrailer3)
+		default:
+			return tollCategoryByCode(TollCategoryAutoTrailer4)
+		}
+	}
+
+	charged := axles - v.SuspendedAxles
+	if charged < 2 {
+		charged = 2
+	}
+	switch charged {
+	case 2:
+		return tollCategoryByCode(TollCategoryCommercial2)
+	case 3:
+		return tollCategoryByCode(TollCategoryCommercial3)
+	case 4:
+		return tollCategoryByCode(TollCategoryCommercial4)
+	case 5:
+		return tollCategoryByCode(TollCategoryCommercial5)
+	case 6:
+		return tollCategoryByCode(TollCategoryCommercial6)
+	default:
+		return TollCategory{
+			Code:        TollCategoryCommercialExtra,
+			Multiplier:  float64(charged),
+			Description: fmt.Sprintf("Veículo comercial com %d eixos, rodagem dupla", charged),
+		}
+	}
+}
+
+func tollCategoryByCode(code int) TollCategory {
+	switch code {
+	case TollCategoryAuto:
+		return TollCategory{Code: code, Multiplier: 1, Description: "Automóvel, caminhonete e furgão, 2 eixos, rodagem simples"}
+	case TollCategoryCommercial2:
+		return TollCategory{Code: code, Multiplier: 2, Description: "Caminhão leve, ônibus, caminhão-trator e furgão, 2 eixos, rodagem dupla"}
+	case TollCategoryAutoTrailer3:
+		return TollCategory{Code: code, Multiplier: 1.5, Description: "Automóvel e caminhonete com semirreboque, 3 eixos, rodagem simples"}
+	case TollCategoryCommercial3:
+		return TollCategory{Code: code, Multiplier: 3, Description: "Caminhão, caminhão-trator com semirreboque e ônibus, 3 eixos, rodagem dupla"}
+	case TollCategoryAutoTrailer4:
+		return TollCategory{Code: code, Multiplier: 2, Description: "Automóvel e caminhonete com reboque, 4 eixos, rodagem simples"}
+	case TollCategoryCommercial4:
+		return TollCategory{Code: code, Multiplier: 4, Description: "Caminhão com reboque e caminhão-trator com semirreboque, 4 eixos, rodagem dupla"}
+	case TollCategoryCommercial5:
+		return TollCategory{Code: code, Multiplier: 5, Description: "Caminhão com reboque e caminhão-trator com semirreboque, 5 eixos, rodagem dupla"}
+	case TollCategoryCommercial6:
+		return TollCategory{Code: code, Multiplier: 6, Description: "Caminhão com reboque e caminhão-trator com semirreboque, 6 eixos, rodagem dupla"}
+	case TollCategoryMotorcycle:
+		return TollCategory{Code: code, Multiplier: 0.5, Description: "Motocicletas, motonetas e bicicletas motorizadas"}
+	default:
+		return tollCategoryByCode(TollCategoryAuto)
+	}
+}
+
+// tollPricingAt devolve as tarifas vigentes no dia da saída (hoje, sem saída informada), guardadas na carga atual
+// do índice para não consultar o banco a cada rota
+func (s *Service) tollPricingAt(ctx context.Context, snap *poiSnapshot, departure *time.Time) (tollPricing, error) {
+	refDate := time.Now()
+	if departure != nil {
+		refDate = *departure
+	}
+	refDate = refDate.In(tollPricingLocation)
+	day := refDate.Format("2006-01-02")
+
+	snap.pricingMu.Lock()
+	pricing, ok := snap.pricing[day]
+	snap.pricingMu.Unlock()
+	if ok {
+		return pricing, nil
+	}
+
+	pricing, err := s.loadTollPricing(ctx, time.Date(refDate.Year(), refDate.Month(), refDate.Day(), 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		return tollPricing{}, err
+	}
+	snap.pricingMu.Lock()
+	if snap.pricing == nil || len(snap.pricing) >= maxCachedPricingDays {
+		snap.pricing = make(map[string]tollPricing)
+	}
+	snap.pricing[day] = pricing
+	snap.pricingMu.Unlock()
+	return pricing, nil
+}
+
+// loadTollPricing carrega as tarifas por praça e as regras de desconto das tags vigentes na data informada
+func (s *Service) loadTollPricing(ctx context.Context, refDate time.Time) (tollPricing, error) {
+	tariffs, err := s.InterfaceService.GetEffectiveTollTariffs(ctx, refDate)
+	if err != nil {
+		return tollPricing{}, fmt.Errorf("erro ao buscar tarifas de pedágio: %w", err)
+	}
+	discounts, err := s.InterfaceService.GetEffectiveTollTagDiscounts(ctx, refDate)
+	if err != nil {
+		return tollPricing{}, fmt.Errorf("erro ao buscar descontos das tags: %w", err)
+	}
+
+	pricing := tollPricing{tariffs: make(map[int64]map[int]float64), discounts: discounts}
+	for _, t := range tariffs {
+		byCategory, ok := pricing.tariffs[t.TollID]
+		if !ok {
+			byCategory = make(map[int]float64)
+			pricing.tariffs[t.TollID] = byCategory
+		}
+		// As linhas vêm da vigência mais recente para a mais antiga; mantém a primeira
+		if _, exists := byCategory[int(t.Category)]; !exists {
+			byCategory[int(t.Category)] = t.Amount
+		}
+	}
+	return pricing, nil
+}
+
+// cashPrice devolve a tarifa da praça para a categoria: a tarifa própria da categoria, se cadastrada,
+// senão a tarifa básica vigente (ou a de tolls.tarifa) multiplicada pelo multiplicador da categoria
+func (p tollPricing) cashPrice(toll db.Toll, category TollCategory) float64 {
+	if byCategory, ok := p.tariffs[toll.ID]; ok {
+		if amount, ok := byCategory[category.Code]; ok {
+			return math.Round(amount*100) / 100
+		}
+		if base, ok := byCategory[TollCategoryAuto]; ok {
+			return math.Round(base*category.Multiplier*100) / 100
+		}
+	}
+	if !toll.Tarifa.Valid {
+		return 0
+	}
+	base, err := strconv.ParseFloat(toll.Tarifa.String, 64)
+	if err != nil {
+		return 0
+	}
+	return math.Round(base*category.Multiplier*100) / 100
+}
+
+// discountFor escolhe a regra mais específica da tag: concessionária e categoria, só concessionária,
+// só categoria e por fim a regra geral
+func (p tollPricing) discountFor(tag, concession string, category int) float64 {
+	bestScore, discount := -1, 0.0
+	for _, d := range p.discounts {
+		if !strings.EqualFold(d.TagName, tag) {
+			continue
+		}
+		score := 0
+		if d.Concessionaria.Valid && d.Concessionaria.String != "" {
+			if !strings.EqualFold(strings.TrimSpace(d.Concessionaria.String), strings.TrimSpace(concession)) {
+				continue
+			}
+			score += 2
+		}
+		if d.Category.Valid {
+			if int(d.Category.Int32) != category {
+				continue
+			}
+			score++
+		}
+		if score > bestScore {
+			bestScore, discount = score, d.DiscountPercent
+		}
+	}
+	return discount
+}
+
+// tagPrices calcula o valor com cada tag aceita na praça
+func (p tollPricing) tagPrices(tags []string, concession string, category int, cash float64) []TollTagPrice {
+	prices := make([]TollTagPrice, 0, len(tags))
+	for _, tag := range tags {
+		discount := p.discountFor(tag, concession, category)
+		prices = append(prices, TollTagPrice{
+			Tag:      tag,
+			Price:    math.Round(cash*(1-discount/100)*100) / 100,
+			Discount: discount,
+		})
+	}
+	return prices
+}
+
+// paidPrice é o valor efetivamente pago: com a tag do veículo, quando aceita na praça, ou em dinheiro
+func paidPrice(tagPrices []TollTagPrice, vehicleTag string, cash float64) float64 {
+	if vehicleTag == "" {
+		return cash
+	}
+	for _, tp := range tagPrices {
+		if strings.EqualFold(tp.Tag, vehicleTag) {
+			return tp.Price
+		}
+	}
+	return cash
+}
+
+// acceptedTags lista as tags aceitas pela concessionária
+func acceptedTags(tagRecords []db.TollTag, concession string) []string {
+	var tags []string
+	for _, tagRecord := range tagRecords {
+		if tagAccepts(tagRecord, concession) {
+			tags = append(tags, tagRecord.Name)
+		}
+	}
+	return tags
+}
+
+// tagAccepts indica se a tag é aceita pela concessionária
+func tagAccepts(tagRecord db.TollTag, concession string) bool {
+	for _, accepted := range strings.Split(tagRecord.DealershipAccepts, ",") {
+		if strings.TrimSpace(accepted) == concession {
+			return true
+		}
+	}
+	return false
+}
+
+// setTollCosts preenche os custos de pedágio somando os valores das praças, que já vêm precificados pela
+// categoria ANTT do veículo: não há multiplicador por eixo nem desconto fixo de tag. O mínimo é o total com a
+// tag de maior desconto em cada praça e o máximo, o total em dinheiro.
+func (c *Costs) setTollCosts(tolls []Toll) {
+	var tag, cash, prepaid, paid float64
+	for _, toll := range tolls {
+		tag += toll.TagCost
+		cash += toll.CashCost
+		prepaid += toll.PrepaidCardCost
+		paid += toll.PaidCost
+	}
+	c.TagAndCash = roundCents(paid)
+	c.Tag = roundCents(tag)
+	c.Cash = roundCents(cash)
+	c.PrepaidCard = roundCents(prepaid)
+	c.MinimumTollCost = roundCents(math.Min(tag, paid))
+	c.MaximumTollCost = roundCents(math.Max(cash, paid))
+}
@@ -0,0 +1,142 @@
+package new_routes
+
+import (
+	"context"
+	"database/sql"
+	db "geolocation/db/sqlc"
+	"geolocation/internal/routes"
+	"testing"
+	"time"
+)
+
+func TestTollVehicleCategory(t *testing.T) {
+	tests := []struct {
+		name           string
+		vehicle        TollVehicle
+		wantCode       int
+		wantMultiplier float64
+	}{
+		{name: "categoria informada vale mais", vehicle: TollVehicle{Type: "truck", Axles: 6, Category: TollCategoryAuto}, wantCode: TollCategoryAuto, wantMultiplier: 1},
+		{name: "motocicleta", vehicle: TollVehicle{Type: " Motorcycle ", Axles: 2}, wantCode: TollCategoryMotorcycle, wantMultiplier: 0.5},
+		{name: "automóvel", vehicle: TollVehicle{Type: "auto", Axles: 2}, wantCode: TollCategoryAuto, wantMultiplier: 1},
+		{name: "automóvel sem eixos conta 2", vehicle: TollVehicle{Type: "auto"}, wantCode: TollCategoryAuto, wantMultiplier: 1},
+		{name: "automóvel com semirreboque", vehicle: TollVehicle{Type: "auto", Axles: 3}, wantCode: TollCategoryAutoTrailer3, wantMultiplier: 1.5},
+		{name: "automóvel com reboque", vehicle: TollVehicle{Type: "auto", Axles: 4}, wantCode: TollCategoryAutoTrailer4, wantMultiplier: 2},
+		{name: "rodagem simples é leve", vehicle: TollVehicle{Type: "truck", Axles: 3, SingleWheels: true}, wantCode: TollCategoryAutoTrailer3, wantMultiplier: 1.5},
+		{name: "caminhão de 2 eixos", vehicle: TollVehicle{Type: "truck", Axles: 2}, wantCode: TollCategoryCommercial2, wantMultiplier: 2},
+		{name: "carreta de 5 eixos", vehicle: TollVehicle{Type: "truck", Axles: 5}, wantCode: TollCategoryCommercial5, wantMultiplier: 5},
+		{name: "eixos suspensos não pagam", vehicle: TollVehicle{Type: "truck", Axles: 6, SuspendedAxles: 2}, wantCode: TollCategoryCommercial4, wantMultiplier: 4},
+		{name: "suspensos não descem de 2 eixos", vehicle: TollVehicle{Type: "truck", Axles: 3, SuspendedAxles: 3}, wantCode: TollCategoryCommercial2, wantMultiplier: 2},
+		{name: "mais de 6 eixos paga por eixo", vehicle: TollVehicle{Type: "truck", Axles: 9}, wantCode: TollCategoryCommercialExtra, wantMultiplier: 9},
+		{name: "código desconhecido cai em automóvel", vehicle: TollVehicle{Category: 42}, wantCode: TollCategoryAuto, wantMultiplier: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.vehicle.category()
+			if got.Code != tt.wantCode || got.Multiplier != tt.wantMultiplier {
+				t.Errorf("category() = %d (x%v), want %d (x%v)", got.Code, got.Multiplier, tt.wantCode, tt.wantMultiplier)
+			}
+			if got.Description == "" {
+				t.Error("category() sem descrição")
+			}
+		})
+	}
+}
+
+func TestTollPricingDiscountFor(t *testing.T) {
+	rule := func(tag, concession string, category int32, discount float64) db.GetEffectiveTollTagDiscountsRow {
+		return db.GetEffectiveTollTagDiscountsRow{
+			TagName:         tag,
+			Concessionaria:  sql.NullString{String: concession, Valid: concession != ""},
+			Category:        sql.NullInt32{Int32: category, Valid: category > 0},
+			DiscountPercent: discount,
+		}
+	}
+	pricing := tollPricing{discounts: []db.GetEffectiveTollTagDiscountsRow{
+		rule("SemParar", "", 0, 5),
+		rule("SemParar", "", TollCategoryCommercial5, 7),
+		rule("SemParar", "CCR", 0, 10),
+		rule("SemParar", "CCR", TollCategoryCommercial5, 15),
+		rule("ConectCar", "Arteris", 0, 3),
+	}}
+
+	tests := []struct {
+		name       string
+		tag        string
+		concession string
+		category   int
+		want       float64
+	}{
+		{name: "concessionária e categoria", tag: "SemParar", concession: "CCR", category: TollCategoryCommercial5, want: 15},
+		{name: "só concessionária", tag: "SemParar", concession: "CCR", category: TollCategoryAuto, want: 10},
+		{name: "só categoria", tag: "SemParar", concession: "Ecorodovias", category: TollCategoryCommercial5, want: 7},
+		{name: "regra geral", tag: "SemParar", concession: "Ecorodovias", category: TollCategoryAuto, want: 5},
+		{name: "tag e concessionária sem diferenciar caixa", tag: "semparar", concession: " ccr ", category: TollCategoryAuto, want: 10},
+		{name: "sem regra para a concessionária", tag: "ConectCar", concession: "CCR", category: TollCategoryAuto, want: 0},
+		{name: "tag desconhecida", tag: "Veloe", concession: "CCR", category: TollCategoryAuto, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := pricing.discountFor(tt.tag, tt.concession, tt.category); got != tt.want {
+				t.Errorf("discountFor(%q, %q, %d) = %v, want %v", tt.tag, tt.concession, tt.category, got, tt.want)
+			}
+		})
+	}
+}
+
+// tariffRepository devolve a tarifa da praça 1 conforme a vigência: reajuste de 10 para 12 em 01/11/2026
+type tariffRepository struct {
+	routes.InterfaceRepository
+	refDates []string
+}
+
+func (r *tariffRepository) GetEffectiveTollTariffs(_ context.Context, refDate time.Time) ([]db.TollTariff, error) {
+	r.refDates = append(r.refDates, refDate.Format("2006-01-02"))
+	amount := 10.0
+	if !refDate.Before(time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)) {
+		amount = 12
+	}
+	return []db.TollTariff{{TollID: 1, Category: TollCategoryAuto, Amount: amount}}, nil
+}
+
+func (r *tariffRepository) GetEffectiveTollTagDiscounts(context.Context, time.Time) ([]db.GetEffectiveTollTagDiscountsRow, error) {
+	return nil, nil
+}
+
+func TestTollPricingAtDeparture(t *testing.T) {
+	repo := &tariffRepository{}
+	s := &Service{InterfaceService: repo}
+	snap := &poiSnapshot{}
+	toll := db.Toll{ID: 1}
+	auto := tollCategoryByCode(TollCategoryAuto)
+
+	// 23h30 de 31/10 em Brasília já é 01/11 em UTC: vale a tarifa de 31/10
+	lastDay := time.Date(2026, 11, 1, 2, 30, 0, 0, time.UTC)
+	firstDay := time.Date(2026, 11, 1, 9, 0, 0, 0, tollPricingLocation)
+	sameDayLater := firstDay.Add(10 * time.Hour)
+
+	for _, step := range []struct {
+		departure *time.Time
+		want      float64
+	}{
+		{departure: &lastDay, want: 10},
+		{departure: &firstDay, want: 12},
+		{departure: &sameDayLater, want: 12},
+		{departure: &lastDay, want: 10},
+	} {
+		pricing, err := s.tollPricingAt(context.Background(), snap, step.departure)
+		if err != nil {
+			t.Fatalf("tollPricingAt(%v) erro = %v", step.departure, err)
+		}
+		if got := pricing.cashPrice(toll, auto); got != step.want {
+			t.Errorf("tarifa na saída %v = %v, want %v", step.departure, got, step.want)
+		}
+	}
+
+	// Cada dia de vigência vai ao banco uma única vez
+	if want := []string{"2026-10-31", "2026-11-01"}; len(repo.refDates) != len(want) || repo.refDates[0] != want[0] || repo.refDates[1] != want[1] {
+		t.Errorf("consultas ao banco = %v, want %v", repo.refDates, want)
+	}
+}
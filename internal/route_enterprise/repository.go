@@ -10,6 +10,9 @@ type InterfaceRepository interface {
 	CreateRouteEnterprise(ctx context.Context, arg db.CreateRouteEnterpriseParams) (db.RouteEnterprise, error)
 	DeleteRouteEnterprise(ctx context.Context, arg db.DeleteRouteEnterpriseParams) error
 	GetOrganizationByTenant(ctx context.Context, arg db.GetOrganizationByTenantParams) (sql.NullString, error)
+	GetRouteEnterpriseByID(ctx context.Context, arg db.GetRouteEnterpriseByIDParams) (db.RouteEnterprise, error)
+	GetRouteEnterprisesForRepricing(ctx context.Context, arg db.GetRouteEnterprisesForRepricingParams) ([]db.GetRouteEnterprisesForRepricingRow, error)
+	UpdateRouteEnterpriseResponse(ctx context.Context, arg db.UpdateRouteEnterpriseResponseParams) error
 }
 
 type Repository struct {
@@ -40,3 +43,15 @@ func (r *Repository) DeleteRouteEnterprise(ctx context.Context, arg db.DeleteRou
 func (r *Repository) GetOrganizationByTenant(ctx context.Context, arg db.GetOrganizationByTenantParams) (sql.NullString, error) {
 	return r.Queries.GetOrganizationByTenant(ctx, arg)
 }
+
+func (r *Repository) GetRouteEnterpriseByID(ctx context.Context, arg db.GetRouteEnterpriseByIDParams) (db.RouteEnterprise, error) {
+	return r.Queries.GetRouteEnterpriseByID(ctx, arg)
+}
+
+func (r *Repository) GetRouteEnterprisesForRepricing(ctx context.Context, arg db.GetRouteEnterprisesForRepricingParams) ([]db.GetRouteEnterprisesForRepricingRow, error) {
+	return r.Queries.GetRouteEnterprisesForRepricing(ctx, arg)
+}
+
+func (r *Repository) UpdateRouteEnterpriseResponse(ctx context.Context, arg db.UpdateRouteEnterpriseResponseParams) error {
+	return r.Queries.UpdateRouteEnterpriseResponse(ctx, arg)
+}
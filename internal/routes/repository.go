@@ -3,8 +3,10 @@ package routes
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	db "geolocation/db/sqlc"
 	"strconv"
+	"time"
 )
 
 type InterfaceRepository interface {
@@ -26,8 +28,31 @@ type InterfaceRepository interface {
 	UpdateNumberOfRequestRequest(ctx context.Context, arg db.UpdateNumberOfRequestParams) error
 	GetFavoriteByUserId(ctx context.Context, arg int64) ([]db.FavoriteRoute, error)
 	RemoveFavorite(ctx context.Context, arg db.RemoveFavoriteParams) error
+	GetFavoriteRouteByID(ctx context.Context, arg db.GetFavoriteRouteByIDParams) (db.FavoriteRoute, error)
 	FindAddressByCEP(ctx context.Context, arg string) (db.FindAddressByCEPRow, error)
 	FindAddressByCEPNew(ctx context.Context, argStr string) (db.FindAddressByCEPNewRow, error)
+	GetRoadRestrictionsByBoundingBox(ctx context.Context, arg db.GetRoadRestrictionsByBoundingBoxParams) ([]db.RoadRestriction, error)
+	GetTruckById(ctx context.Context, arg int64) (db.Truck, error)
+	GetEffectiveTollTariffs(ctx context.Context, refDate time.Time) ([]db.TollTariff, error)
+	GetEffectiveTollTagDiscounts(ctx context.Context, refDate time.Time) ([]db.GetEffectiveTollTagDiscountsRow, error)
+	GetTollPricingVersion(ctx context.Context, refDate time.Time) (string, error)
+	GetAllGasStations(ctx context.Context) ([]db.GetAllGasStationsRow, error)
+	GetPOIFingerprint(ctx context.Context) (db.GetPOIFingerprintRow, error)
+	GetCityStates(ctx context.Context) ([]db.GetCityStatesRow, error)
+	GetAdvertisementByIdForUser(ctx context.Context, arg db.GetAdvertisementByIdForUserParams) (db.Advertisement, error)
+	GetRouteHistByID(ctx context.Context, id int64) (db.RouteHist, error)
+	GetUserRouteHistByPeriod(ctx context.Context, arg db.GetUserRouteHistByPeriodParams) ([]db.GetUserRouteHistByPeriodRow, error)
+	GetLatestFuelPrices(ctx context.Context, arg db.GetLatestFuelPricesParams) ([]db.FuelPrice, error)
+	GetLatestFuelPriceAverages(ctx context.Context) ([]db.GetLatestFuelPriceAveragesRow, error)
+	GetFavoriteRoutesForRepricing(ctx context.Context, arg db.GetFavoriteRoutesForRepricingParams) ([]db.GetFavoriteRoutesForRepricingRow, error)
+	UpdateFavoriteRouteResponse(ctx context.Context, arg db.UpdateFavoriteRouteResponseParams) error
+	GetSavedRouteRequestByRoute(ctx context.Context, arg db.GetSavedRouteRequestByRouteParams) (json.RawMessage, error)
+	CreateRouteVersion(ctx context.Context, arg db.CreateRouteVersionParams) (db.RouteVersion, error)
+	GetRouteVersions(ctx context.Context, arg db.GetRouteVersionsParams) ([]db.RouteVersion, error)
+	UpdateRouteVersionNotified(ctx context.Context, id int64) error
+	CreateRouteEmission(ctx context.Context, arg db.CreateRouteEmissionParams) error
+	GetRouteEmissionsReportByOrganization(ctx context.Context, arg db.GetRouteEmissionsReportByOrganizationParams) ([]db.GetRouteEmissionsReportByOrganizationRow, error)
+	GetRouteEmissionsReportByUser(ctx context.Context, arg db.GetRouteEmissionsReportByUserParams) ([]db.GetRouteEmissionsReportByUserRow, error)
 }
 
 type Repository struct {
@@ -101,6 +126,9 @@ func (r *Repository) GetFavoriteByUserId(ctx context.Context, arg int64) ([]db.F
 func (r *Repository) RemoveFavorite(ctx context.Context, arg db.RemoveFavoriteParams) error {
 	return r.Queries.RemoveFavorite(ctx, arg)
 }
+func (r *Repository) GetFavoriteRouteByID(ctx context.Context, arg db.GetFavoriteRouteByIDParams) (db.FavoriteRoute, error) {
+	return r.Queries.GetFavoriteRouteByID(ctx, arg)
+}
 func (r *Repository) FindAddressByCEP(ctx context.Context, arg string) (db.FindAddressByCEPRow, error) {
 	return r.Queries.FindAddressByCEP(ctx, arg)
 }
@@ -111,3 +139,69 @@ func (r *Repository) FindAddressByCEPNew(ctx context.Context, argStr string) (db
 	}
 	return r.Queries.FindAddressByCEPNew(ctx, arg)
 }
+func (r *Repository) GetRoadRestrictionsByBoundingBox(ctx context.Context, arg db.GetRoadRestrictionsByBoundingBoxParams) ([]db.RoadRestriction, error) {
+	return r.Queries.GetRoadRestrictionsByBoundingBox(ctx, arg)
+}
+func (r *Repository) GetTruckById(ctx context.Context, arg int64) (db.Truck, error) {
+	return r.Queries.GetTruckById(ctx, arg)
+}
+func (r *Repository) GetEffectiveTollTariffs(ctx context.Context, refDate time.Time) ([]db.TollTariff, error) {
+	return r.Queries.GetEffectiveTollTariffs(ctx, refDate)
+}
+func (r *Repository) GetEffectiveTollTagDiscounts(ctx context.Context, refDate time.Time) ([]db.GetEffectiveTollTagDiscountsRow, error) {
+	return r.Queries.GetEffectiveTollTagDiscounts(ctx, refDate)
+}
+func (r *Repository) GetTollPricingVersion(ctx context.Context, refDate time.Time) (string, error) {
+	return r.Queries.GetTollPricingVersion(ctx, refDate)
+}
+func (r *Repository) GetAllGasStations(ctx context.Context) ([]db.GetAllGasStationsRow, error) {
+	return r.Queries.GetAllGasStations(ctx)
+}
+func (r *Repository) GetPOIFingerprint(ctx context.Context) (db.GetPOIFingerprintRow, error) {
+	return r.Queries.GetPOIFingerprint(ctx)
+}
+func (r *Repository) GetCityStates(ctx context.Context) ([]db.GetCityStatesRow, error) {
+	return r.Queries.GetCityStates(ctx)
+}
+func (r *Repository) GetAdvertisementByIdForUser(ctx context.Context, arg db.GetAdvertisementByIdForUserParams) (db.Advertisement, error) {
+	return r.Queries.GetAdvertisementByIdForUser(ctx, arg)
+}
+func (r *Repository) GetRouteHistByID(ctx context.Context, id int64) (db.RouteHist, error) {
+	return r.Queries.GetRouteHistByID(ctx, id)
+}
+func (r *Repository) GetUserRouteHistByPeriod(ctx context.Context, arg db.GetUserRouteHistByPeriodParams) ([]db.GetUserRouteHistByPeriodRow, error) {
+	return r.Queries.GetUserRouteHistByPeriod(ctx, arg)
+}
+func (r *Repository) GetLatestFuelPrices(ctx context.Context, arg db.GetLatestFuelPricesParams) ([]db.FuelPrice, error) {
+	return r.Queries.GetLatestFuelPrices(ctx, arg)
+}
+func (r *Repository) GetLatestFuelPriceAverages(ctx context.Context) ([]db.GetLatestFuelPriceAveragesRow, error) {
+	return r.Queries.GetLatestFuelPriceAverages(ctx)
+}
+func (r *Repository) GetFavoriteRoutesForRepricing(ctx context.Context, arg db.GetFavoriteRoutesForRepricingParams) ([]db.GetFavoriteRoutesForRepricingRow, error) {
+	return r.Queries.GetFavoriteRoutesForRepricing(ctx, arg)
+}
+func (r *Repository) UpdateFavoriteRouteResponse(ctx context.Context, arg db.UpdateFavoriteRouteResponseParams) error {
+	return r.Queries.UpdateFavoriteRouteResponse(ctx, arg)
+}
+func (r *Repository) GetSavedRouteRequestByRoute(ctx context.Context, arg db.GetSavedRouteRequestByRouteParams) (json.RawMessage, error) {
+	return r.Queries.GetSavedRouteRequestByRoute(ctx, arg)
+}
+func (r *Repository) CreateRouteVersion(ctx context.Context, arg db.CreateRouteVersionParams) (db.RouteVersion, error) {
+	return r.Queries.CreateRouteVersion(ctx, arg)
+}
+func (r *Repository) GetRouteVersions(ctx context.Context, arg db.GetRouteVersionsParams) ([]db.RouteVersion, error) {
+	return r.Queries.GetRouteVersions(ctx, arg)
+}
+func (r *Repository) UpdateRouteVersionNotified(ctx context.Context, id int64) error {
+	return r.Queries.UpdateRouteVersionNotified(ctx, id)
+}
+func (r *Repository) CreateRouteEmission(ctx context.Context, arg db.CreateRouteEmissionParams) error {
+	return r.Queries.CreateRouteEmission(ctx, arg)
+}
+func (r *Repository) GetRouteEmissionsReportByOrganization(ctx context.Context, arg db.GetRouteEmissionsReportByOrganizationParams) ([]db.GetRouteEmissionsReportByOrganizationRow, error) {
+	return r.Queries.GetRouteEmissionsReportByOrganization(ctx, arg)
+}
+func (r *Repository) GetRouteEmissionsReportByUser(ctx context.Context, arg db.GetRouteEmissionsReportByUserParams) ([]db.GetRouteEmissionsReportByUserRow, error) {
+	return r.Queries.GetRouteEmissionsReportByUser(ctx, arg)
+}
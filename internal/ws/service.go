@@ -5,10 +5,12 @@ import (
 	"database/sql"
 	"encoding/json"
 	"errors"
+	"log"
 	"time"
 
 	db "geolocation/db/sqlc"
 	"geolocation/internal/advertisement"
+	"geolocation/internal/free_flow"
 	"geolocation/internal/get_token"
 	new_routes "geolocation/internal/new_routes"
 )
@@ -41,17 +43,20 @@ type Service struct {
 	InterfaceService       InterfaceRepository
 	ServiceRoutes          new_routes.InterfaceService
 	InterfaceAdvertisement advertisement.InterfaceRepository
+	ServiceFreeFlow        free_flow.InterfaceService
 }
 
 func NewWsService(
 	interfaceService InterfaceRepository,
 	InterfaceAdvertisement advertisement.InterfaceRepository,
 	ServiceRoutes new_routes.InterfaceService,
+	ServiceFreeFlow free_flow.InterfaceService,
 ) *Service {
 	return &Service{
 		InterfaceService:       interfaceService,
 		InterfaceAdvertisement: InterfaceAdvertisement,
 		ServiceRoutes:          ServiceRoutes,
+		ServiceFreeFlow:        ServiceFreeFlow,
 	}
 }
 
@@ -407,6 +412,12 @@ func (s *Service) FreightLocationDetailsService(
 			if err != nil {
 				return FreightLocationDetailsResponse{}, err
 			}
+
+			// Primeira posição do frete: a viagem começou, registra as passagens free flow da rota escolhida
+			err = s.ServiceFreeFlow.RegisterFreightPassagesService(ctx, data.AdvertisementId, userId, time.Now())
+			if err != nil {
+				log.Printf("Erro ao registrar passagens free flow do frete %d: %v", data.AdvertisementId, err)
+			}
 		}
 	}
 
@@ -0,0 +1,334 @@
+package zonas_risco
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	db "geolocation/db/sqlc"
+	"geolocation/internal/address"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+const (
+	BulkFormatCSV     = "csv"
+	BulkFormatGeoJSON = "geojson"
+	BulkFormatKML     = "kml"
+
+	ImportActionCreate  = "create"
+	ImportActionUpdate  = "update"
+	ImportActionInvalid = "invalid"
+
+	maxImportZones = 5000
+	// MaxImportFileSize limita o arquivo enviado na importação (bytes)
+	MaxImportFileSize = 20 << 20
+)
+
+var cepDigits = regexp.MustCompile(`^\d{8}$`)
+
+// ErrInvalidImportFile marca os problemas do próprio arquivo (formato, conteúdo ilegível, vazio ou grande demais),
+// que são erros de quem enviou e não do servidor
+var ErrInvalidImportFile = errors.New("arquivo de importação inválido")
+
+func invalidImportFile(err error) error {
+	return fmt.Errorf("%w: %w", ErrInvalidImportFile, err)
+}
+
+// ImportZonasRiscoRequest é o arquivo enviado para importação. Sem Format, o formato vem da extensão do arquivo.
+type ImportZonasRiscoRequest struct {
+	OrgID    int64
+	Format   string
+	FileName string
+	Content  []byte
+	DryRun   bool
+}
+
+// ImportZonaRiscoRow é o resultado de cada zona do arquivo: Line é a linha do CSV ou a posição da
+// feature/placemark, Zone é a prévia do que será gravado
+type ImportZonaRiscoRow struct {
+	Line       int                `json:"line"`
+	ExternalID string             `json:"external_id,omitempty"`
+	Name       string             `json:"name"`
+	Action     string             `json:"action"`
+	ZoneID     int64              `json:"zone_id,omitempty"`
+	Errors     []string           `json:"errors,omitempty"`
+	Zone       *ZonaRiscoResponse `json:"zone,omitempty"`
+}
+
+// ImportZonasRiscoResponse resume a importação. Applied só é true quando as zonas foram gravadas: em dry_run
+// ou com alguma linha inválida nada é gravado.
+type ImportZonasRiscoResponse struct {
+	DryRun  bool                 `json:"dry_run"`
+	Applied bool                 `json:"applied"`
+	Total   int                  `json:"total"`
+	Created int                  `json:"created"`
+	Updated int                  `json:"updated"`
+	Invalid int                  `json:"invalid"`
+	Rows    []ImportZonaRiscoRow `json:"rows"`
+}
+
+// ExportFile é o arquivo exportado, pronto para ser devolvido como anexo
+type ExportFile struct {
+	FileName    string
+	ContentType string
+	Content     []byte
+}
+
+// importRecord é uma zona lida do arquivo, antes da validação
+type importRecord struct {
+	line   int
+	data   CreateZonaRiscoRequest
+	errors []string
+}
+
+// ImportZonasRiscoService valida todas as zonas do arquivo e, fora do dry_run e sem erros, grava numa única
+// transação. Zonas com external_id já cadastrado na organização são atualizadas; as demais são criadas.
+func (s *Service) ImportZonasRiscoService(ctx context.Context, data ImportZonasRiscoRequest) (ImportZonasRiscoResponse, error) {
+	format, err := importFormat(data.Format, data.FileName)
+	if err != nil {
+		return ImportZonasRiscoResponse{}, invalidImportFile(err)
+	}
+	if len(data.Content) > MaxImportFileSize {
+		return ImportZonasRiscoResponse{}, invalidImportFile(fmt.Errorf("o limite é de %d MB", MaxImportFileSize>>20))
+	}
+
+	var records []importRecord
+	switch format {
+	case BulkFormatCSV:
+		records, err = parseCSVZones(data.Content)
+	case BulkFormatGeoJSON:
+		records, err = parseGeoJSONZones(data.Content)
+	case BulkFormatKML:
+		records, err = parseKMLZones(data.Content)
+	}
+	if err != nil {
+		return ImportZonasRiscoResponse{}, invalidImportFile(err)
+	}
+	if len(records) == 0 {
+		return ImportZonasRiscoResponse{}, invalidImportFile(errors.New("nenhuma zona encontrada no arquivo"))
+	}
+	if len(records) > maxImportZones {
+		return ImportZonasRiscoResponse{}, invalidImportFile(fmt.Errorf("o arquivo tem %d zonas; o limite por importação é %d", len(records), maxImportZones))
+	}
+
+	resp := ImportZonasRiscoResponse{DryRun: data.DryRun, Total: len(records), Rows: []ImportZonaRiscoRow{}}
+	var creates []db.CreateZonaRiscoParams
+	var updates []db.UpdateZonaRiscoParams
+	seen := make(map[string]int)
+	ceps := make(map[string][2]float64)
+
+	for _, record := range records {
+		record.data.OrgID = data.OrgID
+		record.data.ExternalID = strings.TrimSpace(record.data.ExternalID)
+		row := ImportZonaRiscoRow{Line: record.line, ExternalID: record.data.ExternalID, Name: record.data.Name}
+
+		errs := record.errors
+		if record.data.ExternalID != "" {
+			if first, ok := seen[record.data.ExternalID]; ok {
+				errs = append(errs, fmt.Sprintf("external_id repetido no arquivo (também na posição %d)", first))
+			} else {
+				seen[record.data.ExternalID] = record.line
+			}
+		}
+		if len(errs) == 0 {
+			if err := s.resolveImportLocation(ctx, &record.data, ceps); err != nil {
+				errs = append(errs, err.Error())
+			}
+		}
+
+		var arg db.CreateZonaRiscoParams
+		if len(errs) == 0 {
+			arg, err = createZonaRiscoParams(record.data)
+			if err != nil {
+				errs = append(errs, err.Error())
+			}
+		}
+		if len(errs) > 0 {
+			row.Action = ImportActionInvalid
+			row.Errors = errs
+			resp.Invalid++
+			resp.Rows = append(resp.Rows, row)
+			continue
+		}
+
+		row.Action = ImportActionCreate
+		if arg.ExternalID.Valid {
+			existing, err := s.InterfaceService.GetZonaRiscoByExternalID(ctx, db.GetZonaRiscoByExternalIDParams{
+				OrganizationID: arg.OrganizationID,
+				ExternalID:     arg.ExternalID,
+			})
+			switch {
+			case err == nil:
+				row.Action = ImportActionUpdate
+				row.ZoneID = existing.ID
+			case !errors.Is(err, sql.ErrNoRows):
+				return ImportZonasRiscoResponse{}, fmt.Errorf("erro ao buscar zona pelo external_id %q: %w", row.ExternalID, err)
+			}
+		}
+
+		if row.Action == ImportActionUpdate {
+			updates = append(updates, updateZonaRiscoParams(row.ZoneID, arg))
+			resp.Updated++
+		} else {
+			creates = append(creates, arg)
+			resp.Created++
+		}
+
+		preview := ZonaRiscoResponse{}
+		preview.ParseFromDb(zonaFromParams(row.ZoneID, arg))
+		row.Zone = &preview
+		resp.Rows = append(resp.Rows, row)
+	}
+
+	if data.DryRun || resp.Invalid > 0 {
+		return resp, nil
+	}
+
+	if err := s.InterfaceService.ImportZonasRisco(ctx, creates, updates); err != nil {
+		return ImportZonasRiscoResponse{}, err
+	}
+	resp.Applied = true
+	return resp, nil
+}
+
+// ExportZonasRiscoService exporta as zonas ativas da organização em CSV, GeoJSON ou KML, nos mesmos
+// formatos aceitos pela importação
+func (s *Service) ExportZonasRiscoService(ctx context.Context, organizationID int64, format string) (ExportFile, error) {
+	format, err := importFormat(format, "")
+	if err != nil {
+		return ExportFile{}, err
+	}
+
+	zones, err := s.InterfaceService.GetAllZonasRisco(ctx, sql.NullInt64{Int64: organizationID, Valid: true})
+	if err != nil {
+		return ExportFile{}, fmt.Errorf("erro ao buscar zonas de risco: %w", err)
+	}
+
+	file := ExportFile{FileName: fmt.Sprintf("zonas-risco-%d.%s", organizationID, format)}
+	switch format {
+	case BulkFormatCSV:
+		file.ContentType = "text/csv; charset=utf-8"
+		file.Content, err = exportCSVZones(zones)
+	case BulkFormatGeoJSON:
+		file.ContentType = "application/geo+json"
+		file.Content, err = exportGeoJSONZones(zones)
+	case BulkFormatKML:
+		file.ContentType = "application/vnd.google-earth.kml+xml"
+		file.Content, err = exportKMLZones(zones)
+	}
+	if err != nil {
+		return ExportFile{}, fmt.Errorf("erro ao gerar arquivo %s: %w", format, err)
+	}
+	return file, nil
+}
+
+// importFormat normaliza o formato informado ou deduz pela extensão do arquivo
+func importFormat(format, fileName string) (string, error) {
+	format = strings.ToLower(strings.TrimSpace(format))
+	if format == "" {
+		format = strings.TrimPrefix(strings.ToLower(filepath.Ext(fileName)), ".")
+	}
+	switch format {
+	case BulkFormatCSV, BulkFormatKML:
+		return format, nil
+	case BulkFormatGeoJSON, "json":
+		return BulkFormatGeoJSON, nil
+	}
+	return "", fmt.Errorf("formato %q não suportado: use csv, geojson ou kml", format)
+}
+
+// resolveImportLocation busca as coordenadas pelo CEP das zonas circulares enviadas sem lat/lng
+func (s *Service) resolveImportLocation(ctx context.Context, data *CreateZonaRiscoRequest, ceps map[string][2]float64) error {
+	if data.Shape != "" && data.Shape != ShapeCircle || len(data.Geometry) > 0 && data.Shape == "" {
+		return nil
+	}
+	if data.Lat != 0 || data.Lng != 0 {
+		return nil
+	}
+
+	cep := strings.NewReplacer("-", "", ".", "", " ", "").Replace(data.Cep)
+	if cep == "" {
+		return errors.New("informe lat/lng ou o CEP da zona")
+	}
+	if !cepDigits.MatchString(cep) {
+		return fmt.Errorf("CEP inválido: %s", data.Cep)
+	}
+
+	coords, ok := ceps[cep]
+	if !ok {
+		lat, lng, err := s.cepCoordinates(ctx, cep)
+		if err != nil {
+			return err
+		}
+		coords = [2]float64{lat, lng}
+		ceps[cep] = coords
+	}
+	data.Cep = cep
+	data.Lat, data.Lng = coords[0], coords[1]
+	return nil
+}
+
+// cepCoordinates consulta a base de endereços e, sem resultado, a BrasilAPI
+func (s *Service) cepCoordinates(ctx context.Context, cep string) (float64, float64, error) {
+	if s.CEPRepository != nil {
+		row, err := s.CEPRepository.FindAddressGroupedByCEPRepository(ctx, cep)
+		if err == nil && row.Latitude.Valid && row.Longitude.Valid && (row.Latitude.Float64 != 0 || row.Longitude.Float64 != 0) {
+			return row.Latitude.Float64, row.Longitude.Float64, nil
+		}
+	}
+
+	info, err := address.FindCEPByAPIBrasil(ctx, cep)
+	if err != nil {
+		return 0, 0, fmt.Errorf("CEP %s não encontrado: %w", cep, err)
+	}
+	if info.Latitude == 0 && info.Longitude == 0 {
+		return 0, 0, fmt.Errorf("CEP %s sem coordenadas: informe lat/lng", cep)
+	}
+	return info.Latitude, info.Longitude, nil
+}
+
+func updateZonaRiscoParams(id int64, arg db.CreateZonaRiscoParams) db.UpdateZonaRiscoParams {
+	return db.UpdateZonaRiscoParams{
+		ID:             id,
+		Name:           arg.Name,
+		Cep:            arg.Cep,
+		Lat:            arg.Lat,
+		Lng:            arg.Lng,
+		Radius:         arg.Radius,
+		Type:           arg.Type,
+		OrganizationID: arg.OrganizationID,
+		ZonaAtencao:    arg.ZonaAtencao,
+		Shape:          arg.Shape,
+		Geometry:       arg.Geometry,
+		BufferMeters:   arg.BufferMeters,
+		Severity:       arg.Severity,
+		ActiveWindows:  arg.ActiveWindows,
+		ExpiresAt:      arg.ExpiresAt,
+		ExternalID:     arg.ExternalID,
+	}
+}
+
+// zonaFromParams monta a zona como ficará gravada, para a prévia da importação
+func zonaFromParams(id int64, arg db.CreateZonaRiscoParams) db.ZonasRisco {
+	return db.ZonasRisco{
+		ID:             id,
+		Name:           arg.Name,
+		Cep:            arg.Cep,
+		Lat:            arg.Lat,
+		Lng:            arg.Lng,
+		Radius:         arg.Radius,
+		Type:           arg.Type,
+		OrganizationID: arg.OrganizationID,
+		ZonaAtencao:    arg.ZonaAtencao,
+		Status:         true,
+		Shape:          arg.Shape,
+		Geometry:       arg.Geometry,
+		BufferMeters:   arg.BufferMeters,
+		Severity:       arg.Severity,
+		ActiveWindows:  arg.ActiveWindows,
+		ExpiresAt:      arg.ExpiresAt,
+		ExternalID:     arg.ExternalID,
+	}
+}
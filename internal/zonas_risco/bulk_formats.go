@@ -0,0 +1,664 @@
+package zonas_risco
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	db "geolocation/db/sqlc"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+// importColumns mapeia os nomes aceitos nas colunas do CSV e nas propriedades do GeoJSON/KML para o campo da zona
+var importColumns = map[string]string{
+	"external_id": "external_id", "id_externo": "external_id", "codigo": "external_id", "código": "external_id",
+	"name": "name", "nome": "name",
+	"cep": "cep",
+	"lat": "lat", "latitude": "lat",
+	"lng": "lng", "lon": "lng", "long": "lng", "longitude": "lng",
+	"radius": "radius", "raio": "radius",
+	"type": "type", "tipo": "type",
+	"zona_atencao": "zona_atencao", "zona_atenção": "zona_atencao", "atencao": "zona_atencao", "atenção": "zona_atencao",
+	"severity": "severity", "severidade": "severity",
+	"shape": "shape", "forma": "shape",
+	"buffer_meters": "buffer_meters", "buffer": "buffer_meters",
+	"geometry": "geometry", "geometria": "geometry",
+	"active_windows": "active_windows", "janelas": "active_windows",
+	"expires_at": "expires_at", "expira_em": "expires_at", "validade": "expires_at",
+}
+
+// exportColumns é a ordem das colunas do CSV exportado, que pode ser reimportado sem alterações
+var exportColumns = []string{
+	"external_id", "name", "cep", "lat", "lng", "radius", "type", "zona_atencao", "severity",
+	"shape", "buffer_meters", "geometry", "active_windows", "expires_at",
+}
+
+// importColumn normaliza o nome da coluna/propriedade; vazio quando não é um campo conhecido
+func importColumn(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	name = strings.ReplaceAll(name, " ", "_")
+	return importColumns[name]
+}
+
+// newImportRecord converte os campos já normalizados numa zona, acumulando os erros de cada campo
+func newImportRecord(line int, fields map[string]string) importRecord {
+	record := importRecord{line: line}
+	get := func(key string) string { return strings.TrimSpace(fields[key]) }
+	fail := func(format string, args ...interface{}) {
+		record.errors = append(record.errors, fmt.Sprintf(format, args...))
+	}
+	number := func(key string) float64 {
+		value := get(key)
+		if value == "" {
+			return 0
+		}
+		parsed, err := strconv.ParseFloat(strings.Replace(value, ",", ".", 1), 64)
+		if err != nil {
+			fail("%s inválido: %s", key, value)
+		}
+		return parsed
+	}
+	integer := func(key string) int64 {
+		return int64(number(key))
+	}
+
+	data := CreateZonaRiscoRequest{
+		ExternalID:   get("external_id"),
+		Name:         get("name"),
+		Cep:          get("cep"),
+		Lat:          number("lat"),
+		Lng:          number("lng"),
+		Radius:       integer("radius"),
+		Type:         integer("type"),
+		Severity:     integer("severity"),
+		Shape:        strings.ToLower(get("shape")),
+		BufferMeters: integer("buffer_meters"),
+	}
+	if data.Name == "" {
+		fail("nome obrigatório")
+	}
+
+	if value := get("zona_atencao"); value != "" {
+		attention, err := parseImportBool(value)
+		if err != nil {
+			fail("zona_atencao inválido: %s", value)
+		}
+		data.ZonaAtencao = attention
+	}
+	if value := get("geometry"); value != "" {
+		data.Geometry = json.RawMessage(value)
+	}
+	if value := get("active_windows"); value != "" {
+		data.ActiveWindows = json.RawMessage(value)
+	}
+	if value := get("expires_at"); value != "" {
+		expiresAt, err := parseImportDate(value)
+		if err != nil {
+			fail("expires_at inválido: %s", value)
+		}
+		data.ExpiresAt = expiresAt
+	}
+
+	circle := data.Shape == ShapeCircle || data.Shape == "" && len(data.Geometry) == 0
+	if circle && data.Radius <= 0 {
+		fail("raio obrigatório para zonas circulares")
+	}
+
+	record.data = data
+	return record
+}
+
+func parseImportBool(value string) (bool, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "true", "1", "sim", "s", "yes", "y", "verdadeiro":
+		return true, nil
+	case "false", "0", "não", "nao", "n", "no", "falso":
+		return false, nil
+	}
+	return false, errors.New("valor booleano inválido")
+}
+
+// parseImportDate aceita data e hora RFC 3339 ou só a data (AAAA-MM-DD ou DD/MM/AAAA); só a data vale
+// até o fim do dia no fuso das zonas
+func parseImportDate(value string) (*time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return &t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02T15:04:05", value, scheduleLocation); err == nil {
+		return &t, nil
+	}
+	for _, layout := range []string{"2006-01-02", "02/01/2006"} {
+		if t, err := time.ParseInLocation(layout, value, scheduleLocation); err == nil {
+			end := t.AddDate(0, 0, 1)
+			return &end, nil
+		}
+	}
+	return nil, errors.New("data inválida")
+}
+
+// parseCSVZones lê o CSV com cabeçalho (vírgula ou ponto e vírgula, UTF-8 ou Latin-1). Cada linha é uma zona
+// circular por lat/lng ou CEP e raio, ou uma zona com a geometria GeoJSON na coluna geometry.
+func parseCSVZones(content []byte) ([]importRecord, error) {
+	if !utf8.Valid(content) {
+		decoded, err := charmap.ISO8859_1.NewDecoder().Bytes(content)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao converter o arquivo para UTF-8: %w", err)
+		}
+		content = decoded
+	}
+	content = bytes.TrimPrefix(content, []byte("\xef\xbb\xbf"))
+
+	firstLine := content
+	if i := bytes.IndexByte(content, '\n'); i >= 0 {
+		firstLine = content[:i]
+	}
+	reader := csv.NewReader(bytes.NewReader(content))
+	reader.Comma = ','
+	if bytes.Count(firstLine, []byte(";")) > bytes.Count(firstLine, []byte(",")) {
+		reader.Comma = ';'
+	}
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler CSV: %w", err)
+	}
+	if len(rows) == 0 {
+		return nil, errors.New("CSV vazio")
+	}
+
+	columns := make([]string, len(rows[0]))
+	var hasName bool
+	for i, name := range rows[0] {
+		columns[i] = importColumn(name)
+		hasName = hasName || columns[i] == "name"
+	}
+	if !hasName {
+		return nil, errors.New("cabeçalho do CSV sem a coluna name (ou nome)")
+	}
+
+	var records []importRecord
+	for i, row := range rows[1:] {
+		fields := make(map[string]string)
+		for j, value := range row {
+			if j < len(columns) && columns[j] != "" && strings.TrimSpace(value) != "" {
+				fields[columns[j]] = value
+			}
+		}
+		if len(fields) == 0 {
+			continue
+		}
+		// a linha 1 é o cabeçalho
+		records = append(records, newImportRecord(i+2, fields))
+	}
+	return records, nil
+}
+
+type geoJSONFeatureCollection struct {
+	Type     string           `json:"type"`
+	Features []geoJSONFeature `json:"features"`
+}
+
+type geoJSONFeature struct {
+	Type       string                 `json:"type"`
+	ID         interface{}            `json:"id,omitempty"`
+	Geometry   json.RawMessage        `json:"geometry"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+// parseGeoJSONZones lê uma FeatureCollection (ou uma Feature): Point vira zona circular com o raio da
+// propriedade radius, Polygon/MultiPolygon vira polígono e LineString vira corredor com buffer_meters
+func parseGeoJSONZones(content []byte) ([]importRecord, error) {
+	var document struct {
+		geoJSONFeature
+		Features []geoJSONFeature `json:"features"`
+	}
+	if err := json.Unmarshal(content, &document); err != nil {
+		return nil, fmt.Errorf("GeoJSON inválido: %w", err)
+	}
+
+	features := document.Features
+	switch document.Type {
+	case "FeatureCollection":
+	case "Feature":
+		features = []geoJSONFeature{document.geoJSONFeature}
+	default:
+		return nil, errors.New("o GeoJSON deve ser uma FeatureCollection ou uma Feature")
+	}
+
+	records := make([]importRecord, 0, len(features))
+	for i, feature := range features {
+		fields := make(map[string]string)
+		for key, value := range feature.Properties {
+			if column := importColumn(key); column != "" && value != nil {
+				fields[column] = propertyText(value)
+			}
+		}
+		if fields["external_id"] == "" && feature.ID != nil {
+			fields["external_id"] = propertyText(feature.ID)
+		}
+
+		var geometryErr error
+		if len(feature.Geometry) > 0 && string(feature.Geometry) != "null" {
+			geometryErr = applyGeoJSONGeometry(fields, feature.Geometry)
+		}
+		record := newImportRecord(i+1, fields)
+		if geometryErr != nil {
+			record.errors = append(record.errors, geometryErr.Error())
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// applyGeoJSONGeometry leva a geometria da feature para os campos: o ponto vira lat/lng e as demais a geometria
+func applyGeoJSONGeometry(fields map[string]string, raw json.RawMessage) error {
+	var geometry struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal(raw, &geometry); err != nil {
+		return fmt.Errorf("geometria inválida: %w", err)
+	}
+
+	switch geometry.Type {
+	case "Point":
+		var point struct {
+			Coordinates []float64 `json:"coordinates"`
+		}
+		if err := json.Unmarshal(raw, &point); err != nil || len(point.Coordinates) < 2 {
+			return errors.New("ponto GeoJSON deve ter [lng, lat]")
+		}
+		fields["lng"] = strconv.FormatFloat(point.Coordinates[0], 'f', -1, 64)
+		fields["lat"] = strconv.FormatFloat(point.Coordinates[1], 'f', -1, 64)
+	case "Polygon", "MultiPolygon", "LineString":
+		fields["geometry"] = string(raw)
+	default:
+		return fmt.Errorf("tipo de geometria %q não suportado: use Point, Polygon, MultiPolygon ou LineString", geometry.Type)
+	}
+	return nil
+}
+
+// propertyText converte o valor da propriedade em texto; listas e objetos (como active_windows) voltam a JSON
+func propertyText(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return ""
+	}
+	return string(encoded)
+}
+
+type kmlFile struct {
+	XMLName  xml.Name    `xml:"kml"`
+	Xmlns    string      `xml:"xmlns,attr"`
+	Document kmlDocument `xml:"Document"`
+}
+
+type kmlDocument struct {
+	Name       string         `xml:"name"`
+	Placemarks []kmlPlacemark `xml:"Placemark"`
+}
+
+type kmlPlacemark struct {
+	Name          string            `xml:"name"`
+	ExtendedData  *kmlExtendedData  `xml:"ExtendedData,omitempty"`
+	Point         *kmlCoordinates   `xml:"Point,omitempty"`
+	LineString    *kmlCoordinates   `xml:"LineString,omitempty"`
+	Polygon       []kmlPolygon      `xml:"Polygon,omitempty"`
+	MultiGeometry *kmlMultiGeometry `xml:"MultiGeometry,omitempty"`
+}
+
+type kmlExtendedData struct {
+	Data       []kmlData `xml:"Data"`
+	SchemaData []struct {
+		SimpleData []struct {
+			Name  string `xml:"name,attr"`
+			Value string `xml:",chardata"`
+		} `xml:"SimpleData"`
+	} `xml:"SchemaData,omitempty"`
+}
+
+type kmlData struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value"`
+}
+
+type kmlCoordinates struct {
+	Coordinates string `xml:"coordinates"`
+}
+
+type kmlPolygon struct {
+	Outer kmlBoundary   `xml:"outerBoundaryIs"`
+	Inner []kmlBoundary `xml:"innerBoundaryIs,omitempty"`
+}
+
+type kmlBoundary struct {
+	Coordinates string `xml:"LinearRing>coordinates"`
+}
+
+type kmlMultiGeometry struct {
+	Point      []kmlCoordinates `xml:"Point"`
+	LineString []kmlCoordinates `xml:"LineString"`
+	Polygon    []kmlPolygon     `xml:"Polygon"`
+}
+
+// parseKMLZones lê os Placemarks do KML (em qualquer pasta, como no export do Google My Maps). Os campos da
+// zona vêm do ExtendedData; Point vira zona circular, Polygon vira polígono e LineString vira corredor.
+func parseKMLZones(content []byte) ([]importRecord, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(content))
+	var records []importRecord
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("KML inválido: %w", err)
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok || start.Name.Local != "Placemark" {
+			continue
+		}
+
+		var placemark kmlPlacemark
+		if err := decoder.DecodeElement(&placemark, &start); err != nil {
+			return nil, fmt.Errorf("KML inválido: %w", err)
+		}
+
+		fields := map[string]string{"name": strings.TrimSpace(placemark.Name)}
+		if placemark.ExtendedData != nil {
+			for _, data := range placemark.ExtendedData.Data {
+				if column := importColumn(data.Name); column != "" && strings.TrimSpace(data.Value) != "" {
+					fields[column] = data.Value
+				}
+			}
+			for _, schema := range placemark.ExtendedData.SchemaData {
+				for _, data := range schema.SimpleData {
+					if column := importColumn(data.Name); column != "" && strings.TrimSpace(data.Value) != "" {
+						fields[column] = data.Value
+					}
+				}
+			}
+		}
+		if fields["external_id"] == "" {
+			for _, attr := range start.Attr {
+				if attr.Name.Local == "id" {
+					fields["external_id"] = attr.Value
+				}
+			}
+		}
+
+		geometryErr := applyKMLGeometry(fields, placemark)
+		record := newImportRecord(len(records)+1, fields)
+		if geometryErr != nil {
+			record.errors = append(record.errors, geometryErr.Error())
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// applyKMLGeometry converte a geometria do Placemark para lat/lng ou para a geometria GeoJSON equivalente
+func applyKMLGeometry(fields map[string]string, placemark kmlPlacemark) error {
+	points := make([]kmlCoordinates, 0, 1)
+	lines := make([]kmlCoordinates, 0, 1)
+	polygons := placemark.Polygon
+	if placemark.Point != nil {
+		points = append(points, *placemark.Point)
+	}
+	if placemark.LineString != nil {
+		lines = append(lines, *placemark.LineString)
+	}
+	if placemark.MultiGeometry != nil {
+		points = append(points, placemark.MultiGeometry.Point...)
+		lines = append(lines, placemark.MultiGeometry.LineString...)
+		polygons = append(polygons, placemark.MultiGeometry.Polygon...)
+	}
+
+	var geometry map[string]interface{}
+	switch {
+	case len(polygons) > 0:
+		var all [][][][]float64
+		for _, polygon := range polygons {
+			outer, err := parseKMLCoordinates(polygon.Outer.Coordinates)
+			if err != nil {
+				return err
+			}
+			rings := [][][]float64{outer}
+			for _, inner := range polygon.Inner {
+				ring, err := parseKMLCoordinates(inner.Coordinates)
+				if err != nil {
+					return err
+				}
+				rings = append(rings, ring)
+			}
+			all = append(all, rings)
+		}
+		geometry = map[string]interface{}{"type": "MultiPolygon", "coordinates": all}
+		if len(all) == 1 {
+			geometry = map[string]interface{}{"type": "Polygon", "coordinates": all[0]}
+		}
+	case len(lines) > 0:
+		if len(lines) > 1 {
+			return errors.New("o Placemark deve ter uma única LineString")
+		}
+		line, err := parseKMLCoordinates(lines[0].Coordinates)
+		if err != nil {
+			return err
+		}
+		geometry = map[string]interface{}{"type": "LineString", "coordinates": line}
+	case len(points) > 0:
+		point, err := parseKMLCoordinates(points[0].Coordinates)
+		if err != nil || len(point) != 1 {
+			return errors.New("ponto do KML inválido")
+		}
+		fields["lng"] = strconv.FormatFloat(point[0][0], 'f', -1, 64)
+		fields["lat"] = strconv.FormatFloat(point[0][1], 'f', -1, 64)
+		return nil
+	default:
+		return nil
+	}
+
+	encoded, err := json.Marshal(geometry)
+	if err != nil {
+		return err
+	}
+	fields["geometry"] = string(encoded)
+	return nil
+}
+
+// parseKMLCoordinates lê a lista "lng,lat[,alt]" separada por espaços
+func parseKMLCoordinates(text string) ([][]float64, error) {
+	var positions [][]float64
+	for _, tuple := range strings.Fields(text) {
+		parts := strings.Split(tuple, ",")
+		if len(parts) < 2 {
+			return nil, fmt.Errorf("coordenada do KML inválida: %s", tuple)
+		}
+		lng, errLng := strconv.ParseFloat(parts[0], 64)
+		lat, errLat := strconv.ParseFloat(parts[1], 64)
+		if errLng != nil || errLat != nil {
+			return nil, fmt.Errorf("coordenada do KML inválida: %s", tuple)
+		}
+		positions = append(positions, []float64{lng, lat})
+	}
+	if len(positions) == 0 {
+		return nil, errors.New("geometria do KML sem coordenadas")
+	}
+	return positions, nil
+}
+
+// exportFields devolve os campos da zona como texto, na chave das colunas de exportação
+func exportFields(zone db.ZonasRisco) map[string]string {
+	fields := map[string]string{
+		"external_id":   zone.ExternalID.String,
+		"name":          zone.Name,
+		"cep":           zone.Cep,
+		"lat":           strconv.FormatFloat(zone.Lat, 'f', -1, 64),
+		"lng":           strconv.FormatFloat(zone.Lng, 'f', -1, 64),
+		"radius":        strconv.FormatInt(zone.Radius, 10),
+		"zona_atencao":  strconv.FormatBool(zone.ZonaAtencao),
+		"severity":      strconv.FormatInt(zone.Severity, 10),
+		"shape":         zone.Shape,
+		"buffer_meters": strconv.FormatInt(zone.BufferMeters, 10),
+	}
+	if zone.Type.Valid {
+		fields["type"] = strconv.FormatInt(zone.Type.Int64, 10)
+	}
+	if zone.Geometry.Valid {
+		fields["geometry"] = string(zone.Geometry.RawMessage)
+	}
+	if zone.ActiveWindows.Valid {
+		fields["active_windows"] = string(zone.ActiveWindows.RawMessage)
+	}
+	if zone.ExpiresAt.Valid {
+		fields["expires_at"] = zone.ExpiresAt.Time.Format(time.RFC3339)
+	}
+	return fields
+}
+
+func exportCSVZones(zones []db.ZonasRisco) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	if err := writer.Write(exportColumns); err != nil {
+		return nil, err
+	}
+	for _, zone := range zones {
+		fields := exportFields(zone)
+		row := make([]string, len(exportColumns))
+		for i, column := range exportColumns {
+			row[i] = fields[column]
+		}
+		if err := writer.Write(row); err != nil {
+			return nil, err
+		}
+	}
+	writer.Flush()
+	return buf.Bytes(), writer.Error()
+}
+
+// exportGeometry devolve a geometria GeoJSON da zona: a gravada para polígonos e corredores e o centro para círculos
+func exportGeometry(zone db.ZonasRisco) (json.RawMessage, error) {
+	if zone.Shape != ShapeCircle && zone.Geometry.Valid {
+		var feature Geometry
+		if err := json.Unmarshal(zone.Geometry.RawMessage, &feature); err == nil && feature.Type == "Feature" && feature.Geometry != nil {
+			return json.Marshal(feature.Geometry)
+		}
+		return zone.Geometry.RawMessage, nil
+	}
+	return json.Marshal(map[string]interface{}{"type": "Point", "coordinates": []float64{zone.Lng, zone.Lat}})
+}
+
+func exportGeoJSONZones(zones []db.ZonasRisco) ([]byte, error) {
+	collection := geoJSONFeatureCollection{Type: "FeatureCollection", Features: []geoJSONFeature{}}
+	for _, zone := range zones {
+		geometry, err := exportGeometry(zone)
+		if err != nil {
+			return nil, err
+		}
+
+		properties := map[string]interface{}{
+			"zone_id":       zone.ID,
+			"name":          zone.Name,
+			"cep":           zone.Cep,
+			"radius":        zone.Radius,
+			"zona_atencao":  zone.ZonaAtencao,
+			"severity":      zone.Severity,
+			"shape":         zone.Shape,
+			"buffer_meters": zone.BufferMeters,
+		}
+		if zone.Type.Valid {
+			properties["type"] = zone.Type.Int64
+		}
+		if zone.ActiveWindows.Valid {
+			properties["active_windows"] = json.RawMessage(zone.ActiveWindows.RawMessage)
+		}
+		if zone.ExpiresAt.Valid {
+			properties["expires_at"] = zone.ExpiresAt.Time.Format(time.RFC3339)
+		}
+
+		feature := geoJSONFeature{Type: "Feature", Geometry: geometry, Properties: properties}
+		if zone.ExternalID.Valid {
+			feature.ID = zone.ExternalID.String
+			properties["external_id"] = zone.ExternalID.String
+		}
+		collection.Features = append(collection.Features, feature)
+	}
+	return json.MarshalIndent(collection, "", "  ")
+}
+
+func exportKMLZones(zones []db.ZonasRisco) ([]byte, error) {
+	doc := kmlDocument{Name: "Zonas de risco"}
+	for _, zone := range zones {
+		fields := exportFields(zone)
+		placemark := kmlPlacemark{Name: zone.Name, ExtendedData: &kmlExtendedData{}}
+		for _, column := range exportColumns {
+			if column == "name" || column == "geometry" || fields[column] == "" {
+				continue
+			}
+			placemark.ExtendedData.Data = append(placemark.ExtendedData.Data, kmlData{Name: column, Value: fields[column]})
+		}
+
+		var area *Area
+		if zone.Shape != ShapeCircle && zone.Geometry.Valid {
+			area, _ = NewArea(zone.Shape, zone.Geometry.RawMessage, float64(zone.BufferMeters))
+		}
+		switch {
+		case area == nil:
+			placemark.Point = &kmlCoordinates{Coordinates: kmlPositions([]Point{{Lat: zone.Lat, Lng: zone.Lng}})}
+		case area.Line != nil:
+			placemark.LineString = &kmlCoordinates{Coordinates: kmlPositions(area.Line)}
+		default:
+			var polygons []kmlPolygon
+			for _, polygon := range area.Polygons {
+				p := kmlPolygon{Outer: kmlBoundary{Coordinates: kmlRing(polygon[0])}}
+				for _, hole := range polygon[1:] {
+					p.Inner = append(p.Inner, kmlBoundary{Coordinates: kmlRing(hole)})
+				}
+				polygons = append(polygons, p)
+			}
+			if len(polygons) == 1 {
+				placemark.Polygon = polygons
+			} else {
+				placemark.MultiGeometry = &kmlMultiGeometry{Polygon: polygons}
+			}
+		}
+		doc.Placemarks = append(doc.Placemarks, placemark)
+	}
+
+	body, err := xml.MarshalIndent(kmlFile{Xmlns: "http://www.opengis.net/kml/2.2", Document: doc}, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), body...), nil
+}
+
+// kmlRing fecha o anel repetindo o primeiro ponto, como exige o KML
+func kmlRing(ring []Point) string {
+	return kmlPositions(append(append([]Point{}, ring...), ring[0]))
+}
+
+// kmlPositions monta a lista "lng,lat,0" separada por espaços
+func kmlPositions(points []Point) string {
+	parts := make([]string, 0, len(points))
+	for _, p := range points {
+		parts = append(parts, strconv.FormatFloat(p.Lng, 'f', 6, 64)+","+strconv.FormatFloat(p.Lat, 'f', 6, 64)+",0")
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,206 @@
+package zonas_risco
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// importSummary resume o que os testes comparam em cada zona lida
+type importSummary struct {
+	Line       int
+	ExternalID string
+	Name       string
+	Cep        string
+	Lat, Lng   float64
+	Radius     int64
+	Shape      string
+	Geometry   string
+	Attention  bool
+	Errors     int
+}
+
+func summarizeImport(records []importRecord) []importSummary {
+	out := make([]importSummary, 0, len(records))
+	for _, r := range records {
+		out = append(out, importSummary{
+			Line:       r.line,
+			ExternalID: r.data.ExternalID,
+			Name:       r.data.Name,
+			Cep:        r.data.Cep,
+			Lat:        r.data.Lat,
+			Lng:        r.data.Lng,
+			Radius:     r.data.Radius,
+			Shape:      r.data.Shape,
+			Geometry:   string(r.data.Geometry),
+			Attention:  r.data.ZonaAtencao,
+			Errors:     len(r.errors),
+		})
+	}
+	return out
+}
+
+func TestParseCSVZones(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []importSummary
+		wantErr bool
+	}{
+		{
+			name:    "vírgula e colunas em inglês",
+			content: "external_id,name,lat,lng,radius\nZ1,Centro,-23.5,-46.6,500\n",
+			want:    []importSummary{{Line: 2, ExternalID: "Z1", Name: "Centro", Lat: -23.5, Lng: -46.6, Radius: 500}},
+		},
+		{
+			name:    "ponto e vírgula, BOM e colunas em português",
+			content: "\xef\xbb\xbfcodigo;nome;cep;raio;atenção\nA;Bairro;01310-100;300;sim\n;;;;\nB;Outro;;200;não\n",
+			want: []importSummary{
+				{Line: 2, ExternalID: "A", Name: "Bairro", Cep: "01310-100", Radius: 300, Attention: true},
+				{Line: 4, ExternalID: "B", Name: "Outro", Radius: 200},
+			},
+		},
+		{
+			name:    "Latin-1",
+			content: "nome,raio,lat,lng\nS\xe3o Paulo,100,1,2\n",
+			want:    []importSummary{{Line: 2, Name: "São Paulo", Radius: 100, Lat: 1, Lng: 2}},
+		},
+		{
+			name:    "geometria na coluna geometry",
+			content: "name;shape;geometry\nArea;polygon;{\"type\":\"Polygon\",\"coordinates\":[[[0,0],[1,0],[1,1],[0,0]]]}\n",
+			want: []importSummary{{Line: 2, Name: "Area", Shape: ShapePolygon,
+				Geometry: `{"type":"Polygon","coordinates":[[[0,0],[1,0],[1,1],[0,0]]]}`}},
+		},
+		{
+			name:    "linhas inválidas viram erros da zona",
+			content: "name,lat,radius\n,abc,0\n",
+			want:    []importSummary{{Line: 2, Errors: 3}},
+		},
+		{name: "sem coluna name", content: "lat,lng,radius\n1,2,3\n", wantErr: true},
+		{name: "vazio", content: "", wantErr: true},
+		{name: "só cabeçalho", content: "name,radius\n", want: []importSummary{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			records, err := parseCSVZones([]byte(tt.content))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseCSVZones() erro = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got := summarizeImport(records); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseCSVZones() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseKMLZones(t *testing.T) {
+	const header = `<?xml version="1.0" encoding="UTF-8"?><kml xmlns="http://www.opengis.net/kml/2.2"><Document>`
+	const footer = `</Document></kml>`
+
+	tests := []struct {
+		name    string
+		content string
+		want    []importSummary
+		wantErr bool
+	}{
+		{
+			name: "ponto com ExtendedData dentro de pasta",
+			content: header + `<Folder><Placemark><name>Centro</name><ExtendedData>` +
+				`<Data name="codigo"><value>Z1</value></Data><Data name="raio"><value>500</value></Data>` +
+				`</ExtendedData><Point><coordinates>-46.6,-23.5,0</coordinates></Point></Placemark></Folder>` + footer,
+			want: []importSummary{{Line: 1, ExternalID: "Z1", Name: "Centro", Lat: -23.5, Lng: -46.6, Radius: 500}},
+		},
+		{
+			name: "polígono com SchemaData e id do Placemark",
+			content: header + `<Placemark id="P1"><name>Area</name><ExtendedData><SchemaData>` +
+				`<SimpleData name="severity">4</SimpleData></SchemaData></ExtendedData>` +
+				`<Polygon><outerBoundaryIs><LinearRing><coordinates>0,0 1,0 1,1 0,0</coordinates></LinearRing></outerBoundaryIs></Polygon>` +
+				`</Placemark>` + footer,
+			want: []importSummary{{Line: 1, ExternalID: "P1", Name: "Area",
+				Geometry: `{"coordinates":[[[0,0],[1,0],[1,1],[0,0]]],"type":"Polygon"}`}},
+		},
+		{
+			name: "corredor",
+			content: header + `<Placemark><name>BR</name><ExtendedData><Data name="buffer"><value>300</value></Data></ExtendedData>` +
+				`<LineString><coordinates>0,0 1,1</coordinates></LineString></Placemark>` + footer,
+			want: []importSummary{{Line: 1, Name: "BR", Geometry: `{"coordinates":[[0,0],[1,1]],"type":"LineString"}`}},
+		},
+		{
+			name: "coordenada inválida vira erro da zona",
+			content: header + `<Placemark><name>X</name><ExtendedData><Data name="raio"><value>100</value></Data></ExtendedData>` +
+				`<Point><coordinates>abc</coordinates></Point></Placemark>` + footer,
+			want: []importSummary{{Line: 1, Name: "X", Radius: 100, Errors: 1}},
+		},
+		{name: "sem Placemarks", content: header + footer, want: []importSummary{}},
+		{name: "XML inválido", content: header + `<Placemark><name>X</Placemark>`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			records, err := parseKMLZones([]byte(tt.content))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseKMLZones() erro = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got := summarizeImport(records); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseKMLZones() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestImportFormat(t *testing.T) {
+	tests := []struct {
+		name     string
+		format   string
+		fileName string
+		want     string
+		wantErr  bool
+	}{
+		{name: "pela extensão", fileName: "zonas.CSV", want: BulkFormatCSV},
+		{name: "json é geojson", fileName: "zonas.json", want: BulkFormatGeoJSON},
+		{name: "informado vale mais que a extensão", format: " KML ", fileName: "zonas.csv", want: BulkFormatKML},
+		{name: "não suportado", fileName: "zonas.xlsx", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := importFormat(tt.format, tt.fileName)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("importFormat() erro = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("importFormat() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestImportZonasRiscoServiceInvalidFile(t *testing.T) {
+	s := &Service{}
+	tests := []struct {
+		name string
+		data ImportZonasRiscoRequest
+	}{
+		{name: "formato não suportado", data: ImportZonasRiscoRequest{FileName: "zonas.xlsx", Content: []byte("x")}},
+		{name: "arquivo vazio", data: ImportZonasRiscoRequest{FileName: "zonas.csv"}},
+		{name: "sem zonas", data: ImportZonasRiscoRequest{FileName: "zonas.csv", Content: []byte("name,radius\n")}},
+		{name: "KML ilegível", data: ImportZonasRiscoRequest{FileName: "zonas.kml", Content: []byte("<kml><Placemark>")}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := s.ImportZonasRiscoService(context.Background(), tt.data)
+			if !errors.Is(err, ErrInvalidImportFile) {
+				t.Errorf("ImportZonasRiscoService() erro = %v, want ErrInvalidImportFile", err)
+			}
+		})
+	}
+}
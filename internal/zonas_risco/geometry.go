@@ -0,0 +1,430 @@
+package zonas_risco
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+const (
+	ShapeCircle   = "circle"
+	ShapePolygon  = "polygon"
+	ShapeCorridor = "corridor"
+
+	metersPerDegree = 111320.0
+	earthRadius     = 6371000.0
+	// maxGeometryVertices limita o tamanho das geometrias aceitas (bairros e trechos de rodovia ficam bem abaixo)
+	maxGeometryVertices = 5000
+	// maxCorridorBuffer é a meia-largura máxima (m) de um corredor
+	maxCorridorBuffer = 5000
+)
+
+// Geometry é uma geometria GeoJSON (RFC 7946, coordenadas [lng, lat]): Polygon ou MultiPolygon para áreas
+// e LineString para corredores. Também aceita uma Feature com a geometria dentro.
+type Geometry struct {
+	Type        string          `json:"type"`
+	Coordinates json.RawMessage `json:"coordinates,omitempty"`
+	Geometry    *Geometry       `json:"geometry,omitempty"`
+}
+
+type Point struct {
+	Lat float64
+	Lng float64
+}
+
+// Area é a forma da zona pronta para os testes geométricos. Center e Radius formam o círculo que envolve
+// a forma inteira, usado para filtrar rapidamente e para montar desvios.
+type Area struct {
+	Shape    string
+	Polygons [][][]Point // cada polígono é uma lista de anéis: o primeiro é o contorno, os demais são buracos
+	Line     []Point
+	Buffer   float64 // meia-largura do corredor em metros
+	Center   Point
+	Radius   float64
+}
+
+// NewArea interpreta a geometria GeoJSON da zona. Sem shape, a forma vem do tipo da geometria:
+// Polygon/MultiPolygon viram polygon e LineString vira corridor (exige buffer em metros).
+func NewArea(shape string, raw json.RawMessage, buffer float64) (*Area, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, errors.New("geometria obrigatória para zonas em polígono ou corredor")
+	}
+	var geometry Geometry
+	if err := json.Unmarshal(raw, &geometry); err != nil {
+		return nil, fmt.Errorf("geometria GeoJSON inválida: %w", err)
+	}
+	if strings.EqualFold(geometry.Type, "Feature") {
+		if geometry.Geometry == nil {
+			return nil, errors.New("feature GeoJSON sem geometria")
+		}
+		geometry = *geometry.Geometry
+	}
+
+	area := &Area{Shape: strings.ToLower(shape), Buffer: buffer}
+	switch geometry.Type {
+	case "Polygon":
+		var rings [][][]float64
+		if err := json.Unmarshal(geometry.Coordinates, &rings); err != nil {
+			return nil, fmt.Errorf("coordenadas do Polygon inválidas: %w", err)
+		}
+		polygon, err := parseRings(rings)
+		if err != nil {
+			return nil, err
+		}
+		area.Polygons = [][][]Point{polygon}
+	case "MultiPolygon":
+		var polygons [][][][]float64
+		if err := json.Unmarshal(geometry.Coordinates, &polygons); err != nil {
+			return nil, fmt.Errorf("coordenadas do MultiPolygon inválidas: %w", err)
+		}
+		for _, rings := range polygons {
+			polygon, err := parseRings(rings)
+			if err != nil {
+				return nil, err
+			}
+			area.Polygons = append(area.Polygons, polygon)
+		}
+		if len(area.Polygons) == 0 {
+			return nil, errors.New("MultiPolygon sem polígonos")
+		}
+	case "LineString":
+		var coordinates [][]float64
+		if err := json.Unmarshal(geometry.Coordinates, &coordinates); err != nil {
+			return nil, fmt.Errorf("coordenadas do LineString inválidas: %w", err)
+		}
+		line, err := parsePositions(coordinates)
+		if err != nil {
+			return nil, err
+		}
+		if len(line) < 2 {
+			return nil, errors.New("o corredor precisa de pelo menos dois pontos")
+		}
+		area.Line = line
+	default:
+		return nil, fmt.Errorf("tipo de geometria %q não suportado: use Polygon, MultiPolygon ou LineString", geometry.Type)
+	}
+
+	if area.Shape == "" {
+		area.Shape = ShapePolygon
+		if area.Line != nil {
+			area.Shape = ShapeCorridor
+		}
+	}
+	switch area.Shape {
+	case ShapePolygon:
+		if area.Line != nil {
+			return nil, errors.New("zona em polígono exige geometria Polygon ou MultiPolygon")
+		}
+	case ShapeCorridor:
+		if area.Line == nil {
+			return nil, errors.New("zona em corredor exige geometria LineString")
+		}
+		if area.Buffer <= 0 || area.Buffer > maxCorridorBuffer {
+			return nil, fmt.Errorf("buffer_meters do corredor deve estar entre 1 e %d", maxCorridorBuffer)
+		}
+	default:
+		return nil, fmt.Errorf("shape %q inválido: use circle, polygon ou corridor", shape)
+	}
+	switch count := area.vertexCount(); {
+	case count == 0:
+		return nil, errors.New("geometria sem vértices")
+	case count > maxGeometryVertices:
+		return nil, fmt.Errorf("geometria com mais de %d vértices", maxGeometryVertices)
+	}
+
+	area.boundingCircle()
+	return area, nil
+}
+
+func parseRings(rings [][][]float64) ([][]Point, error) {
+	if len(rings) == 0 {
+		return nil, errors.New("polígono sem anéis")
+	}
+	polygon := make([][]Point, 0, len(rings))
+	for _, ring := range rings {
+		points, err := parsePositions(ring)
+		if err != nil {
+			return nil, err
+		}
+		// o GeoJSON repete o primeiro ponto no fim; aceita também o anel aberto
+		if len(points) > 1 && points[0] == points[len(points)-1] {
+			points = points[:len(points)-1]
+		}
+		if len(points) < 3 {
+			return nil, errors.New("cada anel do polígono precisa de pelo menos três pontos distintos")
+		}
+		polygon = append(polygon, points)
+	}
+	return polygon, nil
+}
+
+func parsePositions(positions [][]float64) ([]Point, error) {
+	points := make([]Point, 0, len(positions))
+	for _, position := range positions {
+		if len(position) < 2 {
+			return nil, errors.New("posição GeoJSON deve ter [lng, lat]")
+		}
+		p := Point{Lat: position[1], Lng: position[0]}
+		if p.Lat < -90 || p.Lat > 90 || p.Lng < -180 || p.Lng > 180 {
+			return nil, fmt.Errorf("coordenada fora do intervalo: [%v, %v]", position[0], position[1])
+		}
+		points = append(points, p)
+	}
+	return points, nil
+}
+
+func (a *Area) vertexCount() int {
+	count := len(a.Line)
+	for _, polygon := range a.Polygons {
+		for _, ring := range polygon {
+			count += len(ring)
+		}
+	}
+	return count
+}
+
+func (a *Area) vertices() []Point {
+	if a.Line != nil {
+		return a.Line
+	}
+	var points []Point
+	for _, polygon := range a.Polygons {
+		points = append(points, polygon[0]...)
+	}
+	return points
+}
+
+// boundingCircle centraliza no meio da caixa envolvente e usa a maior distância até um vértice (mais o buffer)
+func (a *Area) boundingCircle() {
+	points := a.vertices()
+	minLat, maxLat := points[0].Lat, points[0].Lat
+	minLng, maxLng := points[0].Lng, points[0].Lng
+	for _, p := range points[1:] {
+		minLat, maxLat = math.Min(minLat, p.Lat), math.Max(maxLat, p.Lat)
+		minLng, maxLng = math.Min(minLng, p.Lng), math.Max(maxLng, p.Lng)
+	}
+	a.Center = Point{Lat: (minLat + maxLat) / 2, Lng: (minLng + maxLng) / 2}
+
+	var radius float64
+	for _, p := range points {
+		radius = math.Max(radius, haversine(a.Center, p))
+	}
+	a.Radius = math.Ceil(radius + a.Buffer)
+}
+
+// Contains diz se o ponto está dentro da forma: dentro do contorno e fora dos buracos, ou a até Buffer
+// metros da linha do corredor
+func (a *Area) Contains(lat, lng float64) bool {
+	p := Point{Lat: lat, Lng: lng}
+	if haversine(a.Center, p) > a.Radius {
+		return false
+	}
+	if a.Line != nil {
+		return a.distanceToLine(p) <= a.Buffer
+	}
+	for _, polygon := range a.Polygons {
+		inside := false
+		for _, ring := range polygon {
+			if a.ringContains(ring, p) {
+				inside = !inside
+			}
+		}
+		if inside {
+			return true
+		}
+	}
+	return false
+}
+
+// Crossings devolve, em ordem, os parâmetros t em [0, 1] do segmento AB em que ele cruza a borda da forma.
+// Os candidatos (interseções com arestas, com as laterais do corredor e com os círculos das pontas) só valem
+// quando o ponto de fato muda de dentro para fora ou vice-versa.
+func (a *Area) Crossings(from, to Point) []float64 {
+	ax, ay := a.project(from)
+	bx, by := a.project(to)
+	length := math.Hypot(bx-ax, by-ay)
+	cx, cy := a.project(a.Center)
+	if length == 0 || pointSegmentDistance(cx, cy, ax, ay, bx, by) > a.Radius {
+		return nil
+	}
+
+	var candidates []float64
+	if a.Line != nil {
+		for i := 0; i < len(a.Line)-1; i++ {
+			px, py := a.project(a.Line[i])
+			qx, qy := a.project(a.Line[i+1])
+			candidates = append(candidates, segmentCircle(ax, ay, bx, by, px, py, a.Buffer)...)
+			candidates = append(candidates, segmentCircle(ax, ay, bx, by, qx, qy, a.Buffer)...)
+			dx, dy := qx-px, qy-py
+			segLen := math.Hypot(dx, dy)
+			if segLen == 0 {
+				continue
+			}
+			nx, ny := -dy/segLen*a.Buffer, dx/segLen*a.Buffer
+			for _, side := range []float64{1, -1} {
+				if t, ok := segmentSegment(ax, ay, bx, by, px+side*nx, py+side*ny, qx+side*nx, qy+side*ny); ok {
+					candidates = append(candidates, t)
+				}
+			}
+		}
+	} else {
+		for _, polygon := range a.Polygons {
+			for _, ring := range polygon {
+				for i := range ring {
+					px, py := a.project(ring[i])
+					qx, qy := a.project(ring[(i+1)%len(ring)])
+					if t, ok := segmentSegment(ax, ay, bx, by, px, py, qx, qy); ok {
+						candidates = append(candidates, t)
+					}
+				}
+			}
+		}
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	sort.Float64s(candidates)
+	eps := math.Max(0.01/length, 1e-9) // 1 cm de cada lado da borda
+	at := func(t float64) bool {
+		t = math.Min(math.Max(t, 0), 1)
+		return a.Contains(from.Lat+(to.Lat-from.Lat)*t, from.Lng+(to.Lng-from.Lng)*t)
+	}
+	var crossings []float64
+	for _, t := range candidates {
+		if len(crossings) > 0 && t-crossings[len(crossings)-1] <= eps {
+			continue
+		}
+		if at(t-eps) != at(t+eps) {
+			crossings = append(crossings, t)
+		}
+	}
+	return crossings
+}
+
+// DistanceTo devolve a distância (m) do ponto até a forma; zero quando está dentro
+func (a *Area) DistanceTo(lat, lng float64) float64 {
+	if a.Contains(lat, lng) {
+		return 0
+	}
+	p := Point{Lat: lat, Lng: lng}
+	if a.Line != nil {
+		return math.Max(a.distanceToLine(p)-a.Buffer, 0)
+	}
+	px, py := a.project(p)
+	best := math.Inf(1)
+	for _, polygon := range a.Polygons {
+		for _, ring := range polygon {
+			for i := range ring {
+				ax, ay := a.project(ring[i])
+				bx, by := a.project(ring[(i+1)%len(ring)])
+				best = math.Min(best, pointSegmentDistance(px, py, ax, ay, bx, by))
+			}
+		}
+	}
+	return best
+}
+
+// Extent devolve até onde (m) a forma vai a partir de from na direção (east, north): a maior projeção dos
+// vértices nessa direção mais o buffer do corredor. Negativo quando a forma fica toda do lado oposto.
+func (a *Area) Extent(from Point, east, north float64) float64 {
+	mag := math.Hypot(east, north)
+	if mag == 0 {
+		return 0
+	}
+	fx, fy := a.project(from)
+	best := math.Inf(-1)
+	for _, p := range a.vertices() {
+		px, py := a.project(p)
+		best = math.Max(best, ((px-fx)*east+(py-fy)*north)/mag)
+	}
+	return best + a.Buffer
+}
+
+func (a *Area) distanceToLine(p Point) float64 {
+	px, py := a.project(p)
+	best := math.Inf(1)
+	for i := 0; i < len(a.Line)-1; i++ {
+		ax, ay := a.project(a.Line[i])
+		bx, by := a.project(a.Line[i+1])
+		best = math.Min(best, pointSegmentDistance(px, py, ax, ay, bx, by))
+	}
+	return best
+}
+
+// ringContains aplica o teste do raio (par/ímpar) no anel
+func (a *Area) ringContains(ring []Point, p Point) bool {
+	inside := false
+	for i, j := 0, len(ring)-1; i < len(ring); j, i = i, i+1 {
+		pi, pj := ring[i], ring[j]
+		if (pi.Lat > p.Lat) != (pj.Lat > p.Lat) &&
+			p.Lng < (pj.Lng-pi.Lng)*(p.Lat-pi.Lat)/(pj.Lat-pi.Lat)+pi.Lng {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+// project converte para metros numa projeção equirretangular centrada na zona
+func (a *Area) project(p Point) (float64, float64) {
+	return p.Lng * metersPerDegree * math.Cos(a.Center.Lat*math.Pi/180), p.Lat * metersPerDegree
+}
+
+func pointSegmentDistance(px, py, ax, ay, bx, by float64) float64 {
+	vx, vy := bx-ax, by-ay
+	den := vx*vx + vy*vy
+	if den == 0 {
+		return math.Hypot(px-ax, py-ay)
+	}
+	t := math.Min(math.Max(((px-ax)*vx+(py-ay)*vy)/den, 0), 1)
+	return math.Hypot(px-(ax+t*vx), py-(ay+t*vy))
+}
+
+// segmentSegment devolve o parâmetro t em AB da interseção com o segmento PQ
+func segmentSegment(ax, ay, bx, by, px, py, qx, qy float64) (float64, bool) {
+	rx, ry := bx-ax, by-ay
+	sx, sy := qx-px, qy-py
+	den := rx*sy - ry*sx
+	if den == 0 {
+		return 0, false
+	}
+	t := ((px-ax)*sy - (py-ay)*sx) / den
+	u := ((px-ax)*ry - (py-ay)*rx) / den
+	if t < 0 || t > 1 || u < 0 || u > 1 {
+		return 0, false
+	}
+	return t, true
+}
+
+// segmentCircle devolve os parâmetros t em AB das interseções com o círculo de centro C e raio r
+func segmentCircle(ax, ay, bx, by, cx, cy, r float64) []float64 {
+	dx, dy := bx-ax, by-ay
+	fx, fy := ax-cx, ay-cy
+	A := dx*dx + dy*dy
+	B := 2 * (fx*dx + fy*dy)
+	C := fx*fx + fy*fy - r*r
+	D := B*B - 4*A*C
+	if A == 0 || D < 0 {
+		return nil
+	}
+	sqrtD := math.Sqrt(D)
+	var ts []float64
+	for _, t := range []float64{(-B - sqrtD) / (2 * A), (-B + sqrtD) / (2 * A)} {
+		if t >= 0 && t <= 1 {
+			ts = append(ts, t)
+		}
+	}
+	return ts
+}
+
+func haversine(a, b Point) float64 {
+	lat1 := a.Lat * math.Pi / 180
+	lat2 := b.Lat * math.Pi / 180
+	dLat := (b.Lat - a.Lat) * math.Pi / 180
+	dLng := (b.Lng - a.Lng) * math.Pi / 180
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * earthRadius * math.Atan2(math.Sqrt(h), math.Sqrt(1-h))
+}
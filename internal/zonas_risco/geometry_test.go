@@ -0,0 +1,179 @@
+package zonas_risco
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+)
+
+// ~1113 m por 0,01 grau no equador
+const (
+	squareGeoJSON   = `{"type":"Polygon","coordinates":[[[-0.01,-0.01],[0.01,-0.01],[0.01,0.01],[-0.01,0.01],[-0.01,-0.01]]]}`
+	holedGeoJSON    = `{"type":"Polygon","coordinates":[[[-0.01,-0.01],[0.01,-0.01],[0.01,0.01],[-0.01,0.01],[-0.01,-0.01]],[[-0.004,-0.004],[0.004,-0.004],[0.004,0.004],[-0.004,0.004],[-0.004,-0.004]]]}`
+	corridorGeoJSON = `{"type":"LineString","coordinates":[[0,0],[0.1,0]]}`
+)
+
+func testArea(t *testing.T, shape, geometry string, buffer float64) *Area {
+	t.Helper()
+	area, err := NewArea(shape, json.RawMessage(geometry), buffer)
+	if err != nil {
+		t.Fatalf("NewArea() erro = %v", err)
+	}
+	return area
+}
+
+func TestNewArea(t *testing.T) {
+	tests := []struct {
+		name      string
+		shape     string
+		geometry  string
+		buffer    float64
+		wantShape string
+		wantErr   bool
+	}{
+		{name: "polígono pelo tipo", geometry: squareGeoJSON, wantShape: ShapePolygon},
+		{name: "corredor pelo tipo", geometry: corridorGeoJSON, buffer: 500, wantShape: ShapeCorridor},
+		{name: "feature", geometry: `{"type":"Feature","geometry":` + squareGeoJSON + `}`, wantShape: ShapePolygon},
+		{name: "corredor sem buffer", geometry: corridorGeoJSON, wantErr: true},
+		{name: "polígono com LineString", shape: ShapePolygon, geometry: corridorGeoJSON, buffer: 500, wantErr: true},
+		{name: "MultiPolygon vazio", geometry: `{"type":"MultiPolygon","coordinates":[]}`, wantErr: true},
+		{name: "MultiPolygon sem coordenadas", geometry: `{"type":"MultiPolygon","coordinates":null}`, wantErr: true},
+		{name: "Polygon sem anéis", geometry: `{"type":"Polygon","coordinates":[]}`, wantErr: true},
+		{name: "LineString vazio", geometry: `{"type":"LineString","coordinates":[]}`, buffer: 500, wantErr: true},
+		{name: "MultiPolygon com um polígono", geometry: `{"type":"MultiPolygon","coordinates":[[[[0,0],[1,0],[1,1],[0,0]]]]}`, wantShape: ShapePolygon},
+		{name: "anel com dois pontos", geometry: `{"type":"Polygon","coordinates":[[[0,0],[1,1],[0,0]]]}`, wantErr: true},
+		{name: "tipo não suportado", geometry: `{"type":"Point","coordinates":[0,0]}`, wantErr: true},
+		{name: "sem geometria", geometry: `null`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			area, err := NewArea(tt.shape, json.RawMessage(tt.geometry), tt.buffer)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewArea() erro = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && area.Shape != tt.wantShape {
+				t.Errorf("Shape = %q, want %q", area.Shape, tt.wantShape)
+			}
+		})
+	}
+}
+
+func TestAreaContains(t *testing.T) {
+	square := testArea(t, "", squareGeoJSON, 0)
+	holed := testArea(t, "", holedGeoJSON, 0)
+	corridor := testArea(t, "", corridorGeoJSON, 500)
+
+	tests := []struct {
+		name     string
+		area     *Area
+		lat, lng float64
+		want     bool
+	}{
+		{name: "centro do quadrado", area: square, lat: 0, lng: 0, want: true},
+		{name: "fora do quadrado", area: square, lat: 0, lng: 0.02, want: false},
+		{name: "dentro do buraco", area: holed, lat: 0, lng: 0, want: false},
+		{name: "entre contorno e buraco", area: holed, lat: 0.007, lng: 0.007, want: true},
+		{name: "perto da linha do corredor", area: corridor, lat: 0.003, lng: 0.05, want: true},
+		{name: "além do buffer do corredor", area: corridor, lat: 0.006, lng: 0.05, want: false},
+		{name: "ponta arredondada do corredor", area: corridor, lat: 0, lng: 0.104, want: true},
+		{name: "além da ponta do corredor", area: corridor, lat: 0, lng: 0.106, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.area.Contains(tt.lat, tt.lng); got != tt.want {
+				t.Errorf("Contains(%v, %v) = %v, want %v", tt.lat, tt.lng, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAreaCrossings(t *testing.T) {
+	square := testArea(t, "", squareGeoJSON, 0)
+	holed := testArea(t, "", holedGeoJSON, 0)
+	corridor := testArea(t, "", corridorGeoJSON, 500)
+	edge := 500 / metersPerDegree
+
+	tests := []struct {
+		name     string
+		area     *Area
+		from, to Point
+		want     []float64
+	}{
+		{name: "atravessa o quadrado", area: square, from: Point{Lng: -0.02}, to: Point{Lng: 0.02}, want: []float64{0.25, 0.75}},
+		{name: "atravessa o buraco", area: holed, from: Point{Lng: -0.02}, to: Point{Lng: 0.02}, want: []float64{0.25, 0.4, 0.6, 0.75}},
+		{name: "só sai do quadrado", area: square, from: Point{}, to: Point{Lng: 0.02}, want: []float64{0.5}},
+		{name: "passa ao lado", area: square, from: Point{Lat: 0.02, Lng: -0.02}, to: Point{Lat: 0.02, Lng: 0.02}, want: nil},
+		{name: "cruza o corredor", area: corridor, from: Point{Lat: -0.01, Lng: 0.05}, to: Point{Lat: 0.01, Lng: 0.05},
+			want: []float64{(0.01 - edge) / 0.02, (0.01 + edge) / 0.02}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.area.Crossings(tt.from, tt.to)
+			if len(got) != len(tt.want) {
+				t.Fatalf("Crossings() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if math.Abs(got[i]-tt.want[i]) > 1e-3 {
+					t.Errorf("Crossings()[%d] = %v, want %v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestAreaDistanceTo(t *testing.T) {
+	square := testArea(t, "", squareGeoJSON, 0)
+	holed := testArea(t, "", holedGeoJSON, 0)
+	corridor := testArea(t, "", corridorGeoJSON, 500)
+
+	tests := []struct {
+		name     string
+		area     *Area
+		lat, lng float64
+		want     float64
+	}{
+		{name: "dentro", area: square, lat: 0, lng: 0, want: 0},
+		{name: "a leste do quadrado", area: square, lat: 0, lng: 0.02, want: 0.01 * metersPerDegree},
+		{name: "no buraco conta até a borda do buraco", area: holed, lat: 0, lng: 0.002, want: 0.002 * metersPerDegree},
+		{name: "ao lado do corredor", area: corridor, lat: 0.01, lng: 0.05, want: 0.01*metersPerDegree - 500},
+		{name: "dentro do corredor", area: corridor, lat: 0.001, lng: 0.05, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.area.DistanceTo(tt.lat, tt.lng); math.Abs(got-tt.want) > 1 {
+				t.Errorf("DistanceTo(%v, %v) = %v, want %v", tt.lat, tt.lng, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAreaExtent(t *testing.T) {
+	square := testArea(t, "", squareGeoJSON, 0)
+	corridor := testArea(t, "", corridorGeoJSON, 500)
+
+	tests := []struct {
+		name        string
+		area        *Area
+		from        Point
+		east, north float64
+		want        float64
+	}{
+		{name: "do centro para leste", area: square, from: Point{}, east: 1, want: 0.01 * metersPerDegree},
+		{name: "de fora, apontando para longe", area: square, from: Point{Lng: 0.02}, east: 1, want: -0.01 * metersPerDegree},
+		{name: "direção não normalizada", area: square, from: Point{}, north: 10, want: 0.01 * metersPerDegree},
+		{name: "corredor soma o buffer", area: corridor, from: Point{Lng: 0.05}, north: 1, want: 500},
+		{name: "sem direção", area: square, from: Point{}, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.area.Extent(tt.from, tt.east, tt.north); math.Abs(got-tt.want) > 1 {
+				t.Errorf("Extent() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
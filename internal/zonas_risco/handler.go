@@ -2,9 +2,13 @@ package zonas_risco
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
+	"geolocation/internal/get_token"
 	"geolocation/validation"
+	"io"
 	"net/http"
+	"strconv"
 
 	"github.com/labstack/echo/v4"
 )
@@ -131,6 +135,119 @@ func (h *Handler) GetAllZonasRiscoHandler(c echo.Context) error {
 	return c.JSON(http.StatusOK, result)
 }
 
+// ImportZonasRiscoHandler godoc
+// @Summary Importar Zonas de Risco em lote
+// @Description Importa as zonas de risco da organização a partir de CSV, GeoJSON ou KML (ex.: export do Google My Maps).
+// @Description
+// @Description - CSV: cabeçalho com name e, por linha, lat/lng ou cep e radius; colunas opcionais external_id, type, zona_atencao,
+// @Description severity, shape, buffer_meters, geometry (GeoJSON), active_windows (JSON) e expires_at
+// @Description - GeoJSON/KML: Point (zona circular com radius), Polygon/MultiPolygon (polígono) ou LineString (corredor com buffer_meters),
+// @Description com os mesmos campos nas propriedades / ExtendedData
+// @Description - external_id já cadastrado na organização atualiza a zona; sem ele a zona é criada
+// @Description - dry_run=true só valida e devolve a prévia; com qualquer linha inválida nada é gravado
+// @Description - a organização é a do token
+// @Tags ZonasRisco
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "Arquivo CSV, GeoJSON ou KML"
+// @Param format formData string false "csv, geojson ou kml (padrão: extensão do arquivo)"
+// @Param dry_run formData bool false "Apenas validar e pré-visualizar"
+// @Success 200 {object} ImportZonasRiscoResponse "Resultado da importação"
+// @Failure 400 {object} ImportZonasRiscoResponse "Arquivo com zonas inválidas, vazio ou em formato não suportado"
+// @Failure 403 {string} string "Token sem organização"
+// @Failure 413 {string} string "Arquivo maior que o limite (20 MB)"
+// @Failure 500 {string} string "Erro Interno do Servidor"
+// @Router /zonas-risco/import [post]
+func (h *Handler) ImportZonasRiscoHandler(c echo.Context) error {
+	orgID := get_token.GetPayloadToken(c).UserOrgId
+	if orgID == 0 {
+		return c.JSON(http.StatusForbidden, "token sem organização")
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, "arquivo obrigatório no campo file")
+	}
+	tooLarge := fmt.Sprintf("arquivo maior que o limite de %d MB", MaxImportFileSize>>20)
+	if fileHeader.Size > MaxImportFileSize {
+		return c.JSON(http.StatusRequestEntityTooLarge, tooLarge)
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+	defer file.Close()
+	content, err := io.ReadAll(io.LimitReader(file, MaxImportFileSize+1))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+	if len(content) > MaxImportFileSize {
+		return c.JSON(http.StatusRequestEntityTooLarge, tooLarge)
+	}
+
+	var dryRun bool
+	if value := c.FormValue("dry_run"); value != "" {
+		dryRun, err = strconv.ParseBool(value)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, "dry_run inválido")
+		}
+	}
+
+	result, err := h.InterfaceService.ImportZonasRiscoService(c.Request().Context(), ImportZonasRiscoRequest{
+		OrgID:    orgID,
+		Format:   c.FormValue("format"),
+		FileName: fileHeader.Filename,
+		Content:  content,
+		DryRun:   dryRun,
+	})
+	if errors.Is(err, ErrInvalidImportFile) {
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+	if result.Invalid > 0 && !result.DryRun {
+		return c.JSON(http.StatusBadRequest, result)
+	}
+	return c.JSON(http.StatusOK, result)
+}
+
+// ExportZonasRiscoHandler godoc
+// @Summary Exportar Zonas de Risco
+// @Description Exporta as zonas de risco ativas da organização do token em CSV, GeoJSON ou KML, prontas para reimportação
+// @Tags ZonasRisco
+// @Produce text/csv
+// @Produce application/geo+json
+// @Produce application/vnd.google-earth.kml+xml
+// @Param format query string false "csv (padrão), geojson ou kml"
+// @Success 200 {file} file "Arquivo exportado"
+// @Failure 400 {string} string "Requisição Inválida"
+// @Failure 403 {string} string "Token sem organização"
+// @Failure 500 {string} string "Erro Interno do Servidor"
+// @Router /zonas-risco/export [get]
+func (h *Handler) ExportZonasRiscoHandler(c echo.Context) error {
+	orgID := get_token.GetPayloadToken(c).UserOrgId
+	if orgID == 0 {
+		return c.JSON(http.StatusForbidden, "token sem organização")
+	}
+
+	format := c.QueryParam("format")
+	if format == "" {
+		format = BulkFormatCSV
+	}
+	if _, err := importFormat(format, ""); err != nil {
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+
+	file, err := h.InterfaceService.ExportZonasRiscoService(c.Request().Context(), orgID, format)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", file.FileName))
+	return c.Blob(http.StatusOK, file.ContentType, file.Content)
+}
+
 // Função utilitária para parsear o ID do path
 func parseIDParam(c echo.Context, param string) (int64, error) {
 	idStr := c.Param(param)
@@ -0,0 +1,87 @@
+package zonas_risco
+
+import (
+	"bytes"
+	"context"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+// orgService registra a organização que chega ao serviço; os demais métodos não são usados
+type orgService struct {
+	InterfaceService
+	orgID int64
+}
+
+func (s *orgService) ImportZonasRiscoService(_ context.Context, data ImportZonasRiscoRequest) (ImportZonasRiscoResponse, error) {
+	s.orgID = data.OrgID
+	return ImportZonasRiscoResponse{DryRun: data.DryRun}, nil
+}
+
+func (s *orgService) ExportZonasRiscoService(_ context.Context, organizationID int64, _ string) (ExportFile, error) {
+	s.orgID = organizationID
+	return ExportFile{FileName: "zonas.csv", ContentType: "text/csv", Content: []byte("name\n")}, nil
+}
+
+func TestBulkHandlersUseTokenOrganization(t *testing.T) {
+	importRequest := func() *http.Request {
+		var body bytes.Buffer
+		form := multipart.NewWriter(&body)
+		part, _ := form.CreateFormFile("file", "zonas.csv")
+		part.Write([]byte("name,lat,lng,radius\nCentro,-23.5,-46.6,500\n"))
+		form.WriteField("dry_run", "true")
+		form.Close()
+		req := httptest.NewRequest(http.MethodPost, "/zonas-risco/import", &body)
+		req.Header.Set(echo.HeaderContentType, form.FormDataContentType())
+		return req
+	}
+
+	tests := []struct {
+		name       string
+		request    func() *http.Request
+		handler    func(*Handler) echo.HandlerFunc
+		tokenOrgID int64
+		wantStatus int
+	}{
+		{name: "importa na organização do token", request: importRequest, handler: func(h *Handler) echo.HandlerFunc { return h.ImportZonasRiscoHandler }, tokenOrgID: 9, wantStatus: http.StatusOK},
+		{name: "importação sem organização no token", request: importRequest, handler: func(h *Handler) echo.HandlerFunc { return h.ImportZonasRiscoHandler }, wantStatus: http.StatusForbidden},
+		{
+			name: "exporta a organização do token",
+			request: func() *http.Request {
+				return httptest.NewRequest(http.MethodGet, "/zonas-risco/export?format=csv", nil)
+			},
+			handler:    func(h *Handler) echo.HandlerFunc { return h.ExportZonasRiscoHandler },
+			tokenOrgID: 9, wantStatus: http.StatusOK,
+		},
+		{
+			name:       "exportação sem organização no token",
+			request:    func() *http.Request { return httptest.NewRequest(http.MethodGet, "/zonas-risco/export", nil) },
+			handler:    func(h *Handler) echo.HandlerFunc { return h.ExportZonasRiscoHandler },
+			wantStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &orgService{}
+			rec := httptest.NewRecorder()
+			c := echo.New().NewContext(tt.request(), rec)
+			if tt.tokenOrgID > 0 {
+				c.Set("token_user_org_id", tt.tokenOrgID)
+			}
+			if err := tt.handler(NewZonasRiscoHandler(service))(c); err != nil {
+				t.Fatalf("handler erro = %v", err)
+			}
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (%s)", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if service.orgID != tt.tokenOrgID {
+				t.Errorf("organização no serviço = %d, want %d", service.orgID, tt.tokenOrgID)
+			}
+		})
+	}
+}
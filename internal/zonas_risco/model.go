@@ -1,7 +1,9 @@
 package zonas_risco
 
 import (
+	"encoding/json"
 	db "geolocation/db/sqlc"
+	"time"
 )
 
 type CreateZonaRiscoRequest struct {
@@ -13,20 +15,20 @@ type CreateZonaRiscoRequest struct {
 	Type        int64   `json:"type"`
 	OrgID       int64   `json:"organization_id"`
 	ZonaAtencao bool    `json:"zona_atencao"`
+	// Shape é circle (padrão), polygon ou corridor; polygon e corridor usam Geometry em GeoJSON
+	Shape        string          `json:"shape"`
+	Geometry     json.RawMessage `json:"geometry"`
+	BufferMeters int64           `json:"buffer_meters"`
+	// Severity vai de 1 (baixa) a 4 (crítica), padrão 3; ActiveWindows limita a zona a horários no fuso
+	// local dela (ex.: [{"days":[5,6],"start":"22:00","end":"05:00"}]) e ExpiresAt encerra a zona na data
+	Severity      int64           `json:"severity"`
+	ActiveWindows json.RawMessage `json:"active_windows"`
+	ExpiresAt     *time.Time      `json:"expires_at"`
+	// ExternalID é o identificador da zona no sistema do cliente, usado para atualizar na importação em lote
+	ExternalID string `json:"external_id"`
 }
 
 type UpdateZonaRiscoRequest struct {
-	ID     int64   `json:"id"`
-	Name   string  `json:"name"`
-	Cep    string  `json:"cep"`
-	Lat    float64 `json:"lat"`
-	Lng    float64 `json:"lng"`
-	Radius int64   `json:"radius"`
-	Type   int64   `json:"type"`
-	OrgID  int64   `json:"organization_id"`
-}
-
-type ZonaRiscoResponse struct {
 	ID          int64   `json:"id"`
 	Name        string  `json:"name"`
 	Cep         string  `json:"cep"`
@@ -34,9 +36,39 @@ type ZonaRiscoResponse struct {
 	Lng         float64 `json:"lng"`
 	Radius      int64   `json:"radius"`
 	Type        int64   `json:"type"`
-	Status      bool    `json:"status"`
+	OrgID       int64   `json:"organization_id"`
 	ZonaAtencao bool    `json:"zona_atencao"`
-	OrgID       int64   `json:"org_id"`
+	// Shape é circle (padrão), polygon ou corridor; polygon e corridor usam Geometry em GeoJSON
+	Shape        string          `json:"shape"`
+	Geometry     json.RawMessage `json:"geometry"`
+	BufferMeters int64           `json:"buffer_meters"`
+	// Severity vai de 1 (baixa) a 4 (crítica), padrão 3; ActiveWindows limita a zona a horários no fuso
+	// local dela (ex.: [{"days":[5,6],"start":"22:00","end":"05:00"}]) e ExpiresAt encerra a zona na data
+	Severity      int64           `json:"severity"`
+	ActiveWindows json.RawMessage `json:"active_windows"`
+	ExpiresAt     *time.Time      `json:"expires_at"`
+	// ExternalID é o identificador da zona no sistema do cliente, usado para atualizar na importação em lote
+	ExternalID string `json:"external_id"`
+}
+
+type ZonaRiscoResponse struct {
+	ID            int64           `json:"id"`
+	Name          string          `json:"name"`
+	Cep           string          `json:"cep"`
+	Lat           float64         `json:"lat"`
+	Lng           float64         `json:"lng"`
+	Radius        int64           `json:"radius"`
+	Type          int64           `json:"type"`
+	Status        bool            `json:"status"`
+	ZonaAtencao   bool            `json:"zona_atencao"`
+	OrgID         int64           `json:"org_id"`
+	Shape         string          `json:"shape"`
+	Geometry      json.RawMessage `json:"geometry,omitempty"`
+	BufferMeters  int64           `json:"buffer_meters"`
+	Severity      int64           `json:"severity"`
+	ActiveWindows json.RawMessage `json:"active_windows,omitempty"`
+	ExpiresAt     *time.Time      `json:"expires_at,omitempty"`
+	ExternalID    string          `json:"external_id,omitempty"`
 }
 
 func (r *ZonaRiscoResponse) ParseFromDb(result db.ZonasRisco) {
@@ -50,4 +82,17 @@ func (r *ZonaRiscoResponse) ParseFromDb(result db.ZonasRisco) {
 	r.Status = result.Status
 	r.ZonaAtencao = result.ZonaAtencao
 	r.OrgID = result.OrganizationID.Int64
+	r.Shape = result.Shape
+	if result.Geometry.Valid {
+		r.Geometry = result.Geometry.RawMessage
+	}
+	r.BufferMeters = result.BufferMeters
+	r.Severity = result.Severity
+	if result.ActiveWindows.Valid {
+		r.ActiveWindows = result.ActiveWindows.RawMessage
+	}
+	if result.ExpiresAt.Valid {
+		r.ExpiresAt = &result.ExpiresAt.Time
+	}
+	r.ExternalID = result.ExternalID.String
 }
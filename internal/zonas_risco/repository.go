@@ -3,6 +3,7 @@ package zonas_risco
 import (
 	"context"
 	"database/sql"
+	"fmt"
 	db "geolocation/db/sqlc"
 )
 
@@ -12,6 +13,8 @@ type InterfaceRepository interface {
 	DeleteZonaRisco(ctx context.Context, id int64) error
 	GetZonaRiscoById(ctx context.Context, id int64) (db.ZonasRisco, error)
 	GetAllZonasRisco(ctx context.Context, organization_id sql.NullInt64) ([]db.ZonasRisco, error)
+	GetZonaRiscoByExternalID(ctx context.Context, arg db.GetZonaRiscoByExternalIDParams) (db.ZonasRisco, error)
+	ImportZonasRisco(ctx context.Context, creates []db.CreateZonaRiscoParams, updates []db.UpdateZonaRiscoParams) error
 }
 
 type Repository struct {
@@ -50,3 +53,30 @@ func (r *Repository) GetZonaRiscoById(ctx context.Context, id int64) (db.ZonasRi
 func (r *Repository) GetAllZonasRisco(ctx context.Context, organization_id sql.NullInt64) ([]db.ZonasRisco, error) {
 	return r.Queries.GetAllZonasRisco(ctx, organization_id)
 }
+
+func (r *Repository) GetZonaRiscoByExternalID(ctx context.Context, arg db.GetZonaRiscoByExternalIDParams) (db.ZonasRisco, error) {
+	return r.Queries.GetZonaRiscoByExternalID(ctx, arg)
+}
+
+// ImportZonasRisco grava a importação em lote numa única transação: ou entram todas as zonas ou nenhuma
+func (r *Repository) ImportZonasRisco(ctx context.Context, creates []db.CreateZonaRiscoParams, updates []db.UpdateZonaRiscoParams) error {
+	tx, err := r.Conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("erro ao iniciar transação: %w", err)
+	}
+	defer tx.Rollback()
+
+	q := r.Queries.WithTx(tx)
+	for _, arg := range updates {
+		if _, err := q.UpdateZonaRisco(ctx, arg); err != nil {
+			return fmt.Errorf("erro ao atualizar zona de risco %d: %w", arg.ID, err)
+		}
+	}
+	for _, arg := range creates {
+		if _, err := q.CreateZonaRisco(ctx, arg); err != nil {
+			return fmt.Errorf("erro ao criar zona de risco %q: %w", arg.Name, err)
+		}
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,219 @@
+package zonas_risco
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	SeverityLow      = 1
+	SeverityMedium   = 2
+	SeverityHigh     = 3
+	SeverityCritical = 4
+
+	// ActionAvoid desvia da zona, ActionWarn só alerta na rota e ActionIgnore desconsidera a zona na passagem
+	ActionAvoid  = "avoid"
+	ActionWarn   = "warn"
+	ActionIgnore = "ignore"
+
+	maxTimeWindows = 20
+)
+
+// Fusos das janelas de horário (sem horário de verão desde 2019). scheduleLocation (Brasília) é o padrão.
+var (
+	scheduleLocation = time.FixedZone("BRT", -3*60*60)
+	amazonLocation   = time.FixedZone("AMT", -4*60*60) // AM, RR, RO, MT e MS
+	acreLocation     = time.FixedZone("ACT", -5*60*60) // AC
+)
+
+// ScheduleLocation devolve o fuso em que as janelas de horário da zona são lidas: pelo estado do CEP quando
+// informado; sem CEP, por faixas de coordenadas, aproximadas perto das divisas
+func ScheduleLocation(cep string, lat, lng float64) *time.Location {
+	digits := strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, cep)
+	if len(digits) == 8 {
+		prefix, _ := strconv.Atoi(digits[:5])
+		switch {
+		case prefix >= 69900 && prefix <= 69999: // AC
+			return acreLocation
+		case prefix >= 69000 && prefix <= 69899, // AM e RR
+			prefix >= 76800 && prefix <= 76999, // RO
+			prefix >= 78000 && prefix <= 78899, // MT
+			prefix >= 79000 && prefix <= 79999: // MS
+			return amazonLocation
+		}
+		return scheduleLocation
+	}
+
+	switch {
+	case lat == 0 && lng == 0:
+		return scheduleLocation
+	case lat >= -11.2 && lat <= -7.1 && lng >= -74.0 && lng <= -66.6: // AC
+		return acreLocation
+	case lat > -9.8 && lng > -56.5: // PA e AP
+		return scheduleLocation
+	case lat > -9.8: // AM, RR e norte de RO
+		return amazonLocation
+	case lat >= -24.1 && lng < -52.0 && (lat > -22.5 || lng < -54.0): // RO, MT e MS
+		return amazonLocation
+	}
+	return scheduleLocation
+}
+
+// TimeWindow é um período em que a zona está ativa. Days usa 0 = domingo até 6 = sábado (vazio = todos os
+// dias) e se refere ao dia em que a janela começa; quando End é menor que Start a janela vira a noite
+// (ex.: 22:00 às 05:00).
+type TimeWindow struct {
+	Days  []int  `json:"days,omitempty"`
+	Start string `json:"start"`
+	End   string `json:"end"`
+
+	start, end int // minutos desde 00:00
+}
+
+// ParseTimeWindows valida as janelas em JSON; nulo ou lista vazia significa zona ativa o tempo todo
+func ParseTimeWindows(raw json.RawMessage) ([]TimeWindow, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+	var windows []TimeWindow
+	if err := json.Unmarshal(raw, &windows); err != nil {
+		return nil, fmt.Errorf("active_windows inválido: %w", err)
+	}
+	if len(windows) > maxTimeWindows {
+		return nil, fmt.Errorf("no máximo %d janelas de horário por zona", maxTimeWindows)
+	}
+	for i := range windows {
+		if err := windows[i].parse(); err != nil {
+			return nil, err
+		}
+	}
+	return windows, nil
+}
+
+func (w *TimeWindow) parse() error {
+	var err error
+	if w.start, err = parseClock(w.Start); err != nil {
+		return err
+	}
+	if w.end, err = parseClock(w.End); err != nil {
+		return err
+	}
+	if w.start == w.end {
+		return fmt.Errorf("janela de horário vazia: %s às %s", w.Start, w.End)
+	}
+	for _, day := range w.Days {
+		if day < 0 || day > 6 {
+			return fmt.Errorf("dia da semana inválido: %d (use 0 = domingo até 6 = sábado)", day)
+		}
+	}
+	return nil
+}
+
+// parseClock aceita HH:MM, com 24:00 para o fim do dia
+func parseClock(value string) (int, error) {
+	var hour, minute int
+	if _, err := fmt.Sscanf(value, "%d:%d", &hour, &minute); err != nil {
+		return 0, fmt.Errorf("horário inválido %q: use HH:MM", value)
+	}
+	if hour < 0 || minute < 0 || minute > 59 || hour > 24 || hour == 24 && minute > 0 {
+		return 0, fmt.Errorf("horário inválido %q: use HH:MM", value)
+	}
+	return hour*60 + minute, nil
+}
+
+func (w TimeWindow) hasDay(day time.Weekday) bool {
+	if len(w.Days) == 0 {
+		return true
+	}
+	for _, d := range w.Days {
+		if time.Weekday(d) == day {
+			return true
+		}
+	}
+	return false
+}
+
+// contains diz se o instante cai na janela, já no fuso das zonas
+func (w TimeWindow) contains(t time.Time) bool {
+	minutes := t.Hour()*60 + t.Minute()
+	if w.start < w.end {
+		return w.hasDay(t.Weekday()) && minutes >= w.start && minutes < w.end
+	}
+	// janela que vira a noite: a parte depois da meia-noite pertence ao dia anterior
+	if minutes >= w.start {
+		return w.hasDay(t.Weekday())
+	}
+	return minutes < w.end && w.hasDay(t.AddDate(0, 0, -1).Weekday())
+}
+
+// Schedule reúne severidade, janelas e validade da zona para decidir o que fazer na passagem
+type Schedule struct {
+	Severity  int64
+	Attention bool
+	Windows   []TimeWindow
+	ExpiresAt *time.Time
+	Location  *time.Location // fuso das janelas; nil usa o horário de Brasília
+}
+
+// ActiveAt diz se a zona vale no instante: ainda não expirou e está dentro de alguma janela
+func (s Schedule) ActiveAt(t time.Time) bool {
+	if s.ExpiresAt != nil && !t.Before(*s.ExpiresAt) {
+		return false
+	}
+	if len(s.Windows) == 0 {
+		return true
+	}
+	location := s.Location
+	if location == nil {
+		location = scheduleLocation
+	}
+	local := t.In(location)
+	for _, w := range s.Windows {
+		if w.contains(local) {
+			return true
+		}
+	}
+	return false
+}
+
+// ActiveBetween diz se a zona fica ativa em algum momento do intervalo, amostrando de 5 em 5 minutos
+func (s Schedule) ActiveBetween(from, to time.Time) bool {
+	for t := from; !t.After(to); t = t.Add(5 * time.Minute) {
+		if s.ActiveAt(t) {
+			return true
+		}
+	}
+	return s.ActiveAt(to)
+}
+
+// Action decide o tratamento da zona para uma passagem entre from e to: fora da validade ou das janelas é
+// ignorada; zonas de atenção e de severidade baixa ou média só geram alerta; alta e crítica são desviadas.
+func (s Schedule) Action(from, to time.Time) string {
+	if !s.ActiveBetween(from, to) {
+		return ActionIgnore
+	}
+	if s.Attention || s.Severity < SeverityHigh {
+		return ActionWarn
+	}
+	return ActionAvoid
+}
+
+// ValidateSeverity aplica o padrão (alta) e rejeita níveis fora de 1 a 4
+func ValidateSeverity(severity int64) (int64, error) {
+	if severity == 0 {
+		return SeverityHigh, nil
+	}
+	if severity < SeverityLow || severity > SeverityCritical {
+		return 0, errors.New("severity deve ser 1 (baixa), 2 (média), 3 (alta) ou 4 (crítica)")
+	}
+	return severity, nil
+}
@@ -0,0 +1,116 @@
+package zonas_risco
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func testWindow(t *testing.T, days []int, start, end string) TimeWindow {
+	t.Helper()
+	w := TimeWindow{Days: days, Start: start, End: end}
+	if err := w.parse(); err != nil {
+		t.Fatalf("parse() erro = %v", err)
+	}
+	return w
+}
+
+func TestTimeWindowContains(t *testing.T) {
+	// 2026-10-16 é uma sexta-feira
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2026, 10, day, hour, minute, 0, 0, scheduleLocation)
+	}
+	daytime := testWindow(t, nil, "08:00", "18:00")
+	overnight := testWindow(t, []int{5}, "22:00", "05:00") // começa na sexta
+	weekend := testWindow(t, []int{0, 6}, "00:00", "24:00")
+
+	tests := []struct {
+		name   string
+		window TimeWindow
+		at     time.Time
+		want   bool
+	}{
+		{name: "dentro do horário", window: daytime, at: at(16, 12, 0), want: true},
+		{name: "início incluso", window: daytime, at: at(16, 8, 0), want: true},
+		{name: "fim excluso", window: daytime, at: at(16, 18, 0), want: false},
+		{name: "antes do início", window: daytime, at: at(16, 7, 59), want: false},
+		{name: "noite, antes da meia-noite", window: overnight, at: at(16, 23, 0), want: true},
+		{name: "noite, depois da meia-noite conta a sexta", window: overnight, at: at(17, 4, 59), want: true},
+		{name: "noite, fim excluso", window: overnight, at: at(17, 5, 0), want: false},
+		{name: "noite, outro dia", window: overnight, at: at(15, 23, 0), want: false},
+		{name: "madrugada de sexta é da quinta", window: overnight, at: at(16, 1, 0), want: false},
+		{name: "dia inteiro no sábado", window: weekend, at: at(17, 23, 59), want: true},
+		{name: "dia inteiro não vale na sexta", window: weekend, at: at(16, 12, 0), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.window.contains(tt.at); got != tt.want {
+				t.Errorf("contains(%v) = %v, want %v", tt.at, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScheduleLocation(t *testing.T) {
+	tests := []struct {
+		name     string
+		cep      string
+		lat, lng float64
+		want     *time.Location
+	}{
+		{name: "CEP de São Paulo", cep: "01310-100", want: scheduleLocation},
+		{name: "CEP de Manaus", cep: "69005-000", want: amazonLocation},
+		{name: "CEP de Boa Vista", cep: "69301-000", want: amazonLocation},
+		{name: "CEP de Porto Velho", cep: "76801-000", want: amazonLocation},
+		{name: "CEP de Cuiabá", cep: "78005000", want: amazonLocation},
+		{name: "CEP de Campo Grande", cep: "79002-000", want: amazonLocation},
+		{name: "CEP de Rio Branco", cep: "69900-000", want: acreLocation},
+		{name: "CEP vale mais que a coordenada", cep: "01310-100", lat: -15.6, lng: -56.1, want: scheduleLocation},
+		{name: "coordenada de Cuiabá", lat: -15.6, lng: -56.1, want: amazonLocation},
+		{name: "coordenada de Rio Branco", lat: -9.97, lng: -67.81, want: acreLocation},
+		{name: "coordenada de Manaus", lat: -3.1, lng: -60.02, want: amazonLocation},
+		{name: "coordenada de Belém", lat: -1.45, lng: -48.5, want: scheduleLocation},
+		{name: "coordenada de Goiânia", lat: -16.68, lng: -49.25, want: scheduleLocation},
+		{name: "coordenada de Foz do Iguaçu", lat: -25.5, lng: -54.58, want: scheduleLocation},
+		{name: "sem CEP nem coordenada", want: scheduleLocation},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ScheduleLocation(tt.cep, tt.lat, tt.lng); got != tt.want {
+				t.Errorf("ScheduleLocation(%q, %v, %v) = %v, want %v", tt.cep, tt.lat, tt.lng, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScheduleActiveAt(t *testing.T) {
+	windows, err := ParseTimeWindows(json.RawMessage(`[{"start":"22:00","end":"05:00"}]`))
+	if err != nil {
+		t.Fatalf("ParseTimeWindows() erro = %v", err)
+	}
+	expires := time.Date(2026, 10, 20, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		schedule Schedule
+		at       time.Time
+		want     bool
+	}{
+		{name: "sem janelas", schedule: Schedule{}, at: time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC), want: true},
+		{name: "expirada", schedule: Schedule{ExpiresAt: &expires}, at: expires, want: false},
+		// 01:30 UTC = 22:30 em Brasília, mas 21:30 em Cuiabá
+		{name: "Brasília por padrão", schedule: Schedule{Windows: windows}, at: time.Date(2026, 10, 17, 1, 30, 0, 0, time.UTC), want: true},
+		{name: "fuso da zona", schedule: Schedule{Windows: windows, Location: amazonLocation}, at: time.Date(2026, 10, 17, 1, 30, 0, 0, time.UTC), want: false},
+		{name: "fuso do Acre", schedule: Schedule{Windows: windows, Location: acreLocation}, at: time.Date(2026, 10, 17, 3, 30, 0, 0, time.UTC), want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.schedule.ActiveAt(tt.at); got != tt.want {
+				t.Errorf("ActiveAt(%v) = %v, want %v", tt.at, got, tt.want)
+			}
+		})
+	}
+}
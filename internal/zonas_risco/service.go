@@ -3,8 +3,15 @@ package zonas_risco
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	db "geolocation/db/sqlc"
+	"geolocation/internal/address"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/sqlc-dev/pqtype"
 )
 
 type InterfaceService interface {
@@ -13,29 +20,23 @@ type InterfaceService interface {
 	DeleteZonaRiscoService(ctx context.Context, id int64) error
 	GetZonaRiscoByIdService(ctx context.Context, id int64) (ZonaRiscoResponse, error)
 	GetAllZonasRiscoService(ctx context.Context, organization_id sql.NullInt64) ([]ZonaRiscoResponse, error)
+	ImportZonasRiscoService(ctx context.Context, data ImportZonasRiscoRequest) (ImportZonasRiscoResponse, error)
+	ExportZonasRiscoService(ctx context.Context, organizationID int64, format string) (ExportFile, error)
 }
 
 type Service struct {
 	InterfaceService InterfaceRepository
+	CEPRepository    address.InterfaceRepository
 }
 
-func NewZonasRiscoService(InterfaceService InterfaceRepository) *Service {
-	return &Service{InterfaceService}
+func NewZonasRiscoService(InterfaceService InterfaceRepository, CEPRepository address.InterfaceRepository) *Service {
+	return &Service{InterfaceService, CEPRepository}
 }
 
 func (s *Service) CreateZonaRiscoService(ctx context.Context, data CreateZonaRiscoRequest) (ZonaRiscoResponse, error) {
-	arg := db.CreateZonaRiscoParams{
-		Name:   data.Name,
-		Cep:    data.Cep,
-		Lat:    data.Lat,
-		Lng:    data.Lng,
-		Radius: data.Radius,
-		OrganizationID: sql.NullInt64{
-			Int64: data.OrgID,
-			Valid: true,
-		},
-		Type:        sql.NullInt64{Int64: data.Type, Valid: true},
-		ZonaAtencao: data.ZonaAtencao,
+	arg, err := createZonaRiscoParams(data)
+	if err != nil {
+		return ZonaRiscoResponse{}, err
 	}
 
 	result, err := s.InterfaceService.CreateZonaRisco(ctx, arg)
@@ -48,18 +49,35 @@ func (s *Service) CreateZonaRiscoService(ctx context.Context, data CreateZonaRis
 }
 
 func (s *Service) UpdateZonaRiscoService(ctx context.Context, data UpdateZonaRiscoRequest) (ZonaRiscoResponse, error) {
+	shape, err := parseShape(data.Shape, data.Geometry, data.BufferMeters, data.Lat, data.Lng, data.Radius)
+	if err != nil {
+		return ZonaRiscoResponse{}, err
+	}
+	schedule, err := parseSchedule(data.Severity, data.ActiveWindows, data.ExpiresAt)
+	if err != nil {
+		return ZonaRiscoResponse{}, err
+	}
+
 	arg := db.UpdateZonaRiscoParams{
 		ID:     data.ID,
 		Name:   data.Name,
 		Cep:    data.Cep,
-		Lat:    data.Lat,
-		Lng:    data.Lng,
-		Radius: data.Radius,
+		Lat:    shape.Lat,
+		Lng:    shape.Lng,
+		Radius: shape.Radius,
 		OrganizationID: sql.NullInt64{
 			Int64: data.OrgID,
 			Valid: true,
 		},
-		Type: sql.NullInt64{Int64: data.Type, Valid: true},
+		Type:          sql.NullInt64{Int64: data.Type, Valid: true},
+		ZonaAtencao:   data.ZonaAtencao,
+		Shape:         shape.Shape,
+		Geometry:      shape.Geometry,
+		BufferMeters:  shape.BufferMeters,
+		Severity:      schedule.Severity,
+		ActiveWindows: schedule.ActiveWindows,
+		ExpiresAt:     schedule.ExpiresAt,
+		ExternalID:    externalID(data.ExternalID),
 	}
 	result, err := s.InterfaceService.UpdateZonaRisco(ctx, arg)
 	if err != nil {
@@ -107,3 +125,101 @@ func (s *Service) GetAllZonasRiscoService(ctx context.Context, organization_id s
 	}
 	return respList, nil
 }
+
+// createZonaRiscoParams valida forma, severidade e janelas da zona e monta os parâmetros de gravação
+func createZonaRiscoParams(data CreateZonaRiscoRequest) (db.CreateZonaRiscoParams, error) {
+	shape, err := parseShape(data.Shape, data.Geometry, data.BufferMeters, data.Lat, data.Lng, data.Radius)
+	if err != nil {
+		return db.CreateZonaRiscoParams{}, err
+	}
+	schedule, err := parseSchedule(data.Severity, data.ActiveWindows, data.ExpiresAt)
+	if err != nil {
+		return db.CreateZonaRiscoParams{}, err
+	}
+
+	return db.CreateZonaRiscoParams{
+		Name:   data.Name,
+		Cep:    data.Cep,
+		Lat:    shape.Lat,
+		Lng:    shape.Lng,
+		Radius: shape.Radius,
+		OrganizationID: sql.NullInt64{
+			Int64: data.OrgID,
+			Valid: true,
+		},
+		Type:          sql.NullInt64{Int64: data.Type, Valid: true},
+		ZonaAtencao:   data.ZonaAtencao,
+		Shape:         shape.Shape,
+		Geometry:      shape.Geometry,
+		BufferMeters:  shape.BufferMeters,
+		Severity:      schedule.Severity,
+		ActiveWindows: schedule.ActiveWindows,
+		ExpiresAt:     schedule.ExpiresAt,
+		ExternalID:    externalID(data.ExternalID),
+	}, nil
+}
+
+func externalID(value string) sql.NullString {
+	value = strings.TrimSpace(value)
+	return sql.NullString{String: value, Valid: value != ""}
+}
+
+type zoneShape struct {
+	Shape        string
+	Geometry     pqtype.NullRawMessage
+	BufferMeters int64
+	Lat          float64
+	Lng          float64
+	Radius       int64
+}
+
+// parseShape valida a forma da zona. Polígonos e corredores gravam em lat, lng e radius o círculo que
+// envolve a geometria, para que as consultas e desvios baseados em círculo continuem cobrindo a zona toda.
+func parseShape(shape string, geometry json.RawMessage, buffer int64, lat, lng float64, radius int64) (zoneShape, error) {
+	if shape == "" && len(geometry) == 0 || shape == ShapeCircle {
+		return zoneShape{Shape: ShapeCircle, Lat: lat, Lng: lng, Radius: radius}, nil
+	}
+
+	area, err := NewArea(shape, geometry, float64(buffer))
+	if err != nil {
+		return zoneShape{}, err
+	}
+	if area.Shape == ShapePolygon {
+		buffer = 0
+	}
+	return zoneShape{
+		Shape:        area.Shape,
+		Geometry:     pqtype.NullRawMessage{RawMessage: geometry, Valid: true},
+		BufferMeters: buffer,
+		Lat:          area.Center.Lat,
+		Lng:          area.Center.Lng,
+		Radius:       int64(math.Ceil(area.Radius)),
+	}, nil
+}
+
+type zoneSchedule struct {
+	Severity      int64
+	ActiveWindows pqtype.NullRawMessage
+	ExpiresAt     sql.NullTime
+}
+
+// parseSchedule valida severidade, janelas de horário e validade da zona
+func parseSchedule(severity int64, windows json.RawMessage, expiresAt *time.Time) (zoneSchedule, error) {
+	severity, err := ValidateSeverity(severity)
+	if err != nil {
+		return zoneSchedule{}, err
+	}
+	parsed, err := ParseTimeWindows(windows)
+	if err != nil {
+		return zoneSchedule{}, err
+	}
+
+	schedule := zoneSchedule{Severity: severity}
+	if len(parsed) > 0 {
+		schedule.ActiveWindows = pqtype.NullRawMessage{RawMessage: windows, Valid: true}
+	}
+	if expiresAt != nil {
+		schedule.ExpiresAt = sql.NullTime{Time: *expiresAt, Valid: true}
+	}
+	return schedule, nil
+}
@@ -13,4 +13,27 @@ type EmailPlaceHolder struct {
 	PasswordProvider string
 	AccessKey        string
 	Link             string
+	FreeFlowPassages []FreeFlowPassagePlaceHolder
+	RouteOrigin      string
+	RouteDestination string
+	RouteCostChanges []RouteCostChangePlaceHolder
+}
+
+type FreeFlowPassagePlaceHolder struct {
+	TollName       string
+	Concessionaria string
+	Road           string
+	Amount         string
+	PassedAt       string
+	DueAt          string
+	Link           string
+}
+
+type RouteCostChangePlaceHolder struct {
+	RouteType string
+	OldCost   string
+	NewCost   string
+	Change    string
+	Distance  string
+	Duration  string
 }
@@ -0,0 +1,243 @@
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"strings"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Document gera PDFs simples sem dependências externas: páginas A4, fontes Helvetica padrão
+// (texto em WinAnsi, cobre os acentos do português), linhas, retângulos e círculos.
+// As coordenadas são em pontos (1/72 pol.) a partir do canto superior esquerdo da página.
+type Document struct {
+	Width  float64
+	Height float64
+	pages  []*bytes.Buffer
+}
+
+const (
+	A4Width  = 595.28
+	A4Height = 841.89
+
+	// kappa aproxima um quarto de círculo com uma curva de Bézier
+	kappa = 0.5522847498
+)
+
+var winAnsi = encoding.ReplaceUnsupported(charmap.Windows1252.NewEncoder())
+
+func New() *Document {
+	return &Document{Width: A4Width, Height: A4Height}
+}
+
+// AddPage inicia uma nova página; os comandos seguintes são desenhados nela
+func (d *Document) AddPage() {
+	d.pages = append(d.pages, &bytes.Buffer{})
+}
+
+func (d *Document) PageCount() int {
+	return len(d.pages)
+}
+
+func (d *Document) page() *bytes.Buffer {
+	if len(d.pages) == 0 {
+		d.AddPage()
+	}
+	return d.pages[len(d.pages)-1]
+}
+
+func (d *Document) write(format string, args ...interface{}) {
+	fmt.Fprintf(d.page(), format, args...)
+}
+
+// Text escreve uma linha com a base em y
+func (d *Document) Text(x, y, size float64, bold bool, text string) {
+	font := "F1"
+	if bold {
+		font = "F2"
+	}
+	d.write("BT /%s %.2f Tf %.2f %.2f Td (%s) Tj ET\n", font, size, x, d.Height-y, escape(text))
+}
+
+// TextWidth mede a largura do texto na fonte Helvetica
+func TextWidth(text string, size float64, bold bool) float64 {
+	widths := helveticaWidths
+	if bold {
+		widths = helveticaBoldWidths
+	}
+	var total int
+	for _, r := range text {
+		total += glyphWidth(widths, r)
+	}
+	return float64(total) * size / 1000
+}
+
+// WrapText quebra o texto em linhas que cabem em width, respeitando as palavras
+func WrapText(text string, size float64, bold bool, width float64) []string {
+	var lines []string
+	var current string
+	for _, word := range strings.Fields(text) {
+		candidate := word
+		if current != "" {
+			candidate = current + " " + word
+		}
+		if current != "" && TextWidth(candidate, size, bold) > width {
+			lines = append(lines, current)
+			current = word
+			continue
+		}
+		current = candidate
+	}
+	if current != "" {
+		lines = append(lines, current)
+	}
+	return lines
+}
+
+// SetFillColor e SetStrokeColor recebem RGB de 0 a 255
+func (d *Document) SetFillColor(r, g, b int) {
+	d.write("%.3f %.3f %.3f rg\n", float64(r)/255, float64(g)/255, float64(b)/255)
+}
+
+func (d *Document) SetStrokeColor(r, g, b int) {
+	d.write("%.3f %.3f %.3f RG\n", float64(r)/255, float64(g)/255, float64(b)/255)
+}
+
+func (d *Document) SetLineWidth(width float64) {
+	d.write("%.2f w\n", width)
+}
+
+func (d *Document) Line(x1, y1, x2, y2 float64) {
+	d.write("%.2f %.2f m %.2f %.2f l S\n", x1, d.Height-y1, x2, d.Height-y2)
+}
+
+// Rect desenha um retângulo a partir do canto superior esquerdo; fill preenche em vez de contornar
+func (d *Document) Rect(x, y, w, h float64, fill bool) {
+	op := "S"
+	if fill {
+		op = "f"
+	}
+	d.write("%.2f %.2f %.2f %.2f re %s\n", x, d.Height-y-h, w, h, op)
+}
+
+// Polyline liga os pontos (x, y) em sequência
+func (d *Document) Polyline(points [][2]float64) {
+	if len(points) < 2 {
+		return
+	}
+	page := d.page()
+	fmt.Fprintf(page, "%.2f %.2f m", points[0][0], d.Height-points[0][1])
+	for _, p := range points[1:] {
+		fmt.Fprintf(page, " %.2f %.2f l", p[0], d.Height-p[1])
+	}
+	page.WriteString(" S\n")
+}
+
+// Circle desenha um círculo de raio r centrado em (x, y)
+func (d *Document) Circle(x, y, r float64, fill bool) {
+	cy := d.Height - y
+	k := r * kappa
+	d.write("%.2f %.2f m ", x+r, cy)
+	d.write("%.2f %.2f %.2f %.2f %.2f %.2f c ", x+r, cy+k, x+k, cy+r, x, cy+r)
+	d.write("%.2f %.2f %.2f %.2f %.2f %.2f c ", x-k, cy+r, x-r, cy+k, x-r, cy)
+	d.write("%.2f %.2f %.2f %.2f %.2f %.2f c ", x-r, cy-k, x-k, cy-r, x, cy-r)
+	d.write("%.2f %.2f %.2f %.2f %.2f %.2f c ", x+k, cy-r, x+r, cy-k, x+r, cy)
+	if fill {
+		d.write("f\n")
+		return
+	}
+	d.write("S\n")
+}
+
+// Bytes monta o arquivo: catálogo, árvore de páginas, as duas fontes e um stream comprimido por página
+func (d *Document) Bytes() ([]byte, error) {
+	if len(d.pages) == 0 {
+		d.AddPage()
+	}
+
+	var out bytes.Buffer
+	var offsets []int
+	object := func(body string) {
+		offsets = append(offsets, out.Len())
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	out.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	// Objetos 1 a 4 fixos; cada página ocupa dois objetos (página e conteúdo) a partir do 5
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 5+i*2)
+	}
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+
+	for i, page := range d.pages {
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.2f %.2f] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+			d.Width, d.Height, 6+i*2))
+
+		var compressed bytes.Buffer
+		w := zlib.NewWriter(&compressed)
+		if _, err := w.Write(page.Bytes()); err != nil {
+			return nil, err
+		}
+		if err := w.Close(); err != nil {
+			return nil, err
+		}
+		object(fmt.Sprintf("<< /Length %d /Filter /FlateDecode >>\nstream\n%s\nendstream", compressed.Len(), compressed.String()))
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+	return out.Bytes(), nil
+}
+
+// escape converte para WinAnsi (caracteres fora dela viram "?") e protege os delimitadores de string do PDF
+func escape(text string) string {
+	encoded, err := winAnsi.String(text)
+	if err != nil {
+		encoded = text
+	}
+	replacer := strings.NewReplacer(`\`, `\\`, `(`, `\(`, `)`, `\)`, "\r", "", "\n", " ")
+	return replacer.Replace(encoded)
+}
+
+// glyphWidth usa a tabela ASCII; letras acentuadas têm a largura da letra base
+func glyphWidth(widths [95]int, r rune) int {
+	if r >= 32 && r <= 126 {
+		return widths[r-32]
+	}
+	if base := []rune(norm.NFD.String(string(r))); base[0] >= 32 && base[0] <= 126 {
+		return widths[base[0]-32]
+	}
+	return 556
+}
+
+// Larguras dos caracteres 32 a 126 (métricas AFM padrão, em milésimos do tamanho da fonte)
+var helveticaWidths = [95]int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+}
+
+var helveticaBoldWidths = [95]int{
+	278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 333, 333, 584, 584, 584, 611,
+	975, 722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 333, 278, 333, 584, 556,
+	333, 556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, 611, 611,
+	611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, 389, 280, 389, 584,
+}
@@ -0,0 +1,95 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"testing"
+)
+
+func TestBytesProducesValidPDF(t *testing.T) {
+	tests := []struct {
+		name  string
+		pages int
+		draw  func(d *Document)
+	}{
+		{name: "documento vazio ganha uma página", pages: 1, draw: func(d *Document) {}},
+		{name: "texto e formas", pages: 1, draw: func(d *Document) {
+			d.AddPage()
+			d.Text(40, 40, 12, true, "Rotograma (São Paulo) \\ teste")
+			d.Line(0, 0, 100, 100)
+			d.Rect(10, 10, 50, 20, true)
+			d.Circle(30, 30, 5, false)
+			d.Polyline([][2]float64{{0, 0}, {10, 10}, {20, 5}})
+		}},
+		{name: "várias páginas", pages: 3, draw: func(d *Document) {
+			for i := 0; i < 3; i++ {
+				d.AddPage()
+				d.Text(40, 40, 10, false, fmt.Sprintf("página %d", i+1))
+			}
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := New()
+			tt.draw(d)
+			out, err := d.Bytes()
+			if err != nil {
+				t.Fatalf("Bytes() erro = %v", err)
+			}
+
+			if !bytes.HasPrefix(out, []byte("%PDF-")) {
+				t.Errorf("arquivo não começa com %%PDF-: %q", out[:10])
+			}
+			if !bytes.HasSuffix(bytes.TrimRight(out, "\r\n"), []byte("%%EOF")) {
+				t.Errorf("arquivo não termina com %%%%EOF")
+			}
+			if got := bytes.Count(out, []byte("/Type /Page ")); got != tt.pages {
+				t.Errorf("páginas = %d, want %d", got, tt.pages)
+			}
+
+			// startxref aponta para a tabela xref e cada entrada aponta para o início do objeto
+			m := regexp.MustCompile(`startxref\n(\d+)\n`).FindSubmatch(out)
+			if m == nil {
+				t.Fatal("startxref ausente")
+			}
+			xref, _ := strconv.Atoi(string(m[1]))
+			if !bytes.HasPrefix(out[xref:], []byte("xref\n")) {
+				t.Fatalf("startxref %d não aponta para a tabela xref", xref)
+			}
+			entries := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllSubmatch(out[xref:], -1)
+			if want := 4 + 2*tt.pages; len(entries) != want {
+				t.Fatalf("entradas xref = %d, want %d", len(entries), want)
+			}
+			for i, entry := range entries {
+				offset, _ := strconv.Atoi(string(entry[1]))
+				if want := fmt.Sprintf("%d 0 obj\n", i+1); !bytes.HasPrefix(out[offset:], []byte(want)) {
+					t.Errorf("entrada %d aponta para %q, want %q", i+1, out[offset:offset+len(want)], want)
+				}
+			}
+		})
+	}
+}
+
+func TestEscape(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{name: "ascii", in: "Rota 1", want: "Rota 1"},
+		{name: "delimitadores", in: `a(b)c\d`, want: `a\(b\)c\\d`},
+		{name: "quebra de linha", in: "a\r\nb", want: "a b"},
+		{name: "acentos em WinAnsi", in: "São", want: "S\xe3o"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := escape(tt.in); got != tt.want {
+				t.Errorf("escape(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
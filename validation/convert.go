@@ -7,6 +7,9 @@ import (
 	"strconv"
 	"strings"
 	"time"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
 )
 
 func ParseStringToInt64(strUserID string) (int64, error) {
@@ -54,6 +57,18 @@ func RemoveHTMLTags(s string) string {
 	return re.ReplaceAllString(s, "")
 }
 
+// NormalizeName deixa nomes (municípios, estados) comparáveis: sem acentos, em maiúsculas e com espaços simples
+func NormalizeName(name string) string {
+	var result strings.Builder
+	for _, r := range norm.NFD.String(name) {
+		if unicode.Is(unicode.Mn, r) {
+			continue
+		}
+		result.WriteRune(r)
+	}
+	return strings.Join(strings.Fields(strings.ToUpper(result.String())), " ")
+}
+
 func FormatActiveDuration(start time.Time) string {
 	now := time.Now()
 	years := now.Year() - start.Year()